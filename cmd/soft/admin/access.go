@@ -0,0 +1,111 @@
+package admin
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/soft-serve/cmd"
+	"github.com/charmbracelet/soft-serve/pkg/backend"
+	"github.com/spf13/cobra"
+)
+
+var (
+	accessCmd = &cobra.Command{
+		Use:   "access",
+		Short: "Inspect the access of users and keys",
+	}
+
+	accessReportJSON bool
+	accessReportCSV  bool
+
+	accessReportCmd = &cobra.Command{
+		Use:   "report",
+		Short: "Report who has access to what",
+		Long: `Report the access of anonymous users, of each user, and of each unregistered
+key granted access to a repository.
+
+Each identity is reported with its access level to repositories it has no
+grant on, its key fingerprints, and the repositories it owns, collaborates
+on, or was granted access to with a key. Use --json to write one JSON object
+per identity and line, and --csv to write one row per identity and one per
+grant. The report is written as it's read, identity by identity.`,
+		Args:               cobra.NoArgs,
+		PersistentPreRunE:  cmd.InitBackendContext,
+		PersistentPostRunE: cmd.CloseDBContext,
+		RunE: func(c *cobra.Command, _ []string) error {
+			ctx := c.Context()
+			be := backend.FromContext(ctx)
+			out := bufio.NewWriter(c.OutOrStdout())
+			defer out.Flush() // nolint: errcheck
+
+			var write func(backend.AccessReportEntry) error
+			switch {
+			case accessReportJSON:
+				enc := json.NewEncoder(out)
+				write = func(e backend.AccessReportEntry) error {
+					if err := enc.Encode(e); err != nil {
+						return err
+					}
+					return out.Flush()
+				}
+			case accessReportCSV:
+				w := csv.NewWriter(out)
+				if err := w.Write([]string{"type", "name", "admin", "access", "keys", "repo", "repo_access", "source", "key"}); err != nil {
+					return err
+				}
+				write = func(e backend.AccessReportEntry) error {
+					id := []string{e.Type, e.Name, strconv.FormatBool(e.Admin), e.Access.String(), strings.Join(e.Keys, " ")}
+					if err := w.Write(append(id, "", "", "", "")); err != nil {
+						return err
+					}
+					for _, g := range e.Grants {
+						if err := w.Write(append(id, g.Repo, g.Access.String(), g.Source, g.Key)); err != nil {
+							return err
+						}
+					}
+					w.Flush()
+					if err := w.Error(); err != nil {
+						return err
+					}
+					return out.Flush()
+				}
+			default:
+				write = func(e backend.AccessReportEntry) error {
+					admin := ""
+					if e.Admin {
+						admin = " (admin)"
+					}
+					fmt.Fprintf(out, "%s %s%s: %s\n", e.Type, e.Name, admin, e.Access)
+					for _, k := range e.Keys {
+						fmt.Fprintf(out, "  key %s\n", k)
+					}
+					for _, g := range e.Grants {
+						fmt.Fprintf(out, "  %s\t%s\t%s", g.Repo, g.Access, g.Source)
+						if g.Key != "" {
+							fmt.Fprintf(out, " %s", g.Key)
+						}
+						fmt.Fprintln(out)
+					}
+					return out.Flush()
+				}
+			}
+
+			if err := be.AccessReport(ctx, write); err != nil {
+				return fmt.Errorf("access report: %w", err)
+			}
+
+			return nil
+		},
+	}
+)
+
+func init() {
+	accessReportCmd.Flags().BoolVar(&accessReportJSON, "json", false, "write the report as JSON lines")
+	accessReportCmd.Flags().BoolVar(&accessReportCSV, "csv", false, "write the report as CSV")
+	accessReportCmd.MarkFlagsMutuallyExclusive("json", "csv")
+	accessCmd.AddCommand(accessReportCmd)
+}
@@ -70,7 +70,19 @@ var (
 
 func init() {
 	Command.AddCommand(
+		accessCmd,
+		applyCmd,
+		auditCmd,
+		configCmd,
+		dbCmd,
+		hostkeyCmd,
+		lockdownCmd,
+		recomputeSizesCmd,
+		renameDefaultBranchCmd,
+		revokeKeyCmd,
+		scanOrphansCmd,
 		syncHooksCmd,
+		verifyBackupCmd,
 		migrateCmd,
 		rollbackCmd,
 	)
@@ -0,0 +1,89 @@
+package admin
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/soft-serve/cmd"
+	"github.com/charmbracelet/soft-serve/pkg/backend"
+	"github.com/spf13/cobra"
+)
+
+var (
+	applyDryRun bool
+	applyPrune  bool
+
+	applyCmd = &cobra.Command{
+		Use:   "apply MANIFEST",
+		Short: "Sync repositories and collaborators from a manifest",
+		Long: `Sync repositories and collaborators from a YAML manifest.
+
+The manifest lists the desired repositories and their collaborators:
+
+  repos:
+    - name: icecream
+      description: "Ice Cream"
+      private: true
+      collaborators:
+        alice: read-write
+        bob: read-only
+
+Repositories and collaborators missing from the server are created, and
+existing ones are updated to match the manifest. Repositories and collaborators
+that aren't in the manifest are only deleted with --prune.
+
+Use --dry-run to show the plan without changing anything.`,
+		Args:               cobra.ExactArgs(1),
+		PersistentPreRunE:  cmd.InitBackendContext,
+		PersistentPostRunE: cmd.CloseDBContext,
+		RunE: func(c *cobra.Command, args []string) error {
+			ctx := c.Context()
+			out := c.OutOrStdout()
+			be := backend.FromContext(ctx)
+			m, err := backend.ParseManifest(args[0])
+			if err != nil {
+				return err
+			}
+
+			changes, err := be.Plan(ctx, m)
+			if err != nil {
+				return fmt.Errorf("plan: %w", err)
+			}
+
+			if len(changes) == 0 {
+				fmt.Fprintln(out, "No changes.")
+				return nil
+			}
+
+			var failed int
+			for _, ch := range changes {
+				switch {
+				case ch.Delete && !applyPrune:
+					fmt.Fprintf(out, "%s (skipped, use --prune)\n", ch)
+					continue
+				case applyDryRun:
+					fmt.Fprintf(out, "%s (dry run)\n", ch)
+					continue
+				}
+
+				if err := be.ApplyChange(ctx, ch); err != nil {
+					failed++
+					c.PrintErrf("failed to %s: %v\n", ch, err)
+					continue
+				}
+
+				fmt.Fprintf(out, "%s\n", ch)
+			}
+
+			if failed > 0 {
+				return fmt.Errorf("failed to apply %d change(s)", failed)
+			}
+
+			return nil
+		},
+	}
+)
+
+func init() {
+	applyCmd.Flags().BoolVar(&applyDryRun, "dry-run", false, "show the changes without applying them")
+	applyCmd.Flags().BoolVar(&applyPrune, "prune", false, "delete repositories and collaborators that aren't in the manifest")
+}
@@ -0,0 +1,200 @@
+package admin
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/soft-serve/cmd"
+	"github.com/charmbracelet/soft-serve/pkg/backend"
+	"github.com/spf13/cobra"
+)
+
+var (
+	auditCmd = &cobra.Command{
+		Use:   "audit",
+		Short: "Query, rotate, and verify the audit log",
+	}
+
+	auditQuery      backend.AuditQuery
+	auditQuerySince string
+	auditQueryUntil string
+	auditQueryJSON  bool
+
+	auditQueryCmd = &cobra.Command{
+		Use:   "query",
+		Short: "Query the audit log",
+		Long: `List the audit events by an actor, with an action, on a target repository, or
+in a time range, oldest first, across the rotated segments and the database.
+
+Times are either RFC 3339 timestamps, dates, or durations before now like 12h
+or 7d. Segments outside of the time range aren't read. Use --json to write one
+JSON object per event and line.`,
+		Args:               cobra.NoArgs,
+		PersistentPreRunE:  cmd.InitBackendContext,
+		PersistentPostRunE: cmd.CloseDBContext,
+		RunE: func(c *cobra.Command, _ []string) error {
+			ctx := c.Context()
+			now := time.Now()
+			q := auditQuery
+			var err error
+			if q.Since, err = parseAuditTime(auditQuerySince, now); err != nil {
+				return fmt.Errorf("invalid --since: %w", err)
+			}
+			if q.Until, err = parseAuditTime(auditQueryUntil, now); err != nil {
+				return fmt.Errorf("invalid --until: %w", err)
+			}
+
+			records, err := backend.FromContext(ctx).QueryAuditLog(ctx, q)
+			if err != nil {
+				return fmt.Errorf("query audit log: %w", err)
+			}
+
+			out := bufio.NewWriter(c.OutOrStdout())
+			defer out.Flush() // nolint: errcheck
+			enc := json.NewEncoder(out)
+			for _, r := range records {
+				if auditQueryJSON {
+					if err := enc.Encode(r); err != nil {
+						return err
+					}
+					continue
+				}
+
+				fmt.Fprintf(out, "%s\t%s\t%s\t%s\t%s\n", r.CreatedAt.Format(time.RFC3339),
+					orDash(r.Actor), r.Action, orDash(r.Target), r.Details)
+			}
+
+			return nil
+		},
+	}
+
+	auditRotateCmd = &cobra.Command{
+		Use:   "rotate",
+		Short: "Rotate the audit log now",
+		Long: `Move the audit events past their retention, or over audit.max_events, from
+the database to a new segment, ship the segments not shipped yet, and remove
+the segments past their retention. The prune job does the same.`,
+		Args:               cobra.NoArgs,
+		PersistentPreRunE:  cmd.InitBackendContext,
+		PersistentPostRunE: cmd.CloseDBContext,
+		RunE: func(c *cobra.Command, _ []string) error {
+			ctx := c.Context()
+			out := c.OutOrStdout()
+			rot, err := backend.FromContext(ctx).RotateAuditLog(ctx)
+			if err != nil {
+				return fmt.Errorf("rotate audit log: %w", err)
+			}
+
+			if rot.Segment != "" {
+				fmt.Fprintf(out, "Rotated %d audit event(s) into %s.\n", rot.Events, rot.Segment)
+			} else {
+				fmt.Fprintln(out, "No audit events to rotate.")
+			}
+			fmt.Fprintf(out, "Shipped %d segment(s) and removed %d segment(s).\n", rot.Shipped, rot.Removed)
+			return nil
+		},
+	}
+
+	auditVerifyJSON bool
+
+	auditVerifyCmd = &cobra.Command{
+		Use:   "verify",
+		Short: "Verify the hash chain of the audit log",
+		Long: `Check that the events of the rotated segments and the database form an
+unbroken hash chain, and that the segment files weren't changed since they were
+rotated. Every problem is reported, and the command fails if there is any.
+
+The hash of the latest event is printed, keep it somewhere else to detect
+the latest events being deleted. Use --json to write the report as a JSON
+object.`,
+		Args:               cobra.NoArgs,
+		PersistentPreRunE:  cmd.InitBackendContext,
+		PersistentPostRunE: cmd.CloseDBContext,
+		RunE: func(c *cobra.Command, _ []string) error {
+			ctx := c.Context()
+			out := c.OutOrStdout()
+			report, err := backend.FromContext(ctx).VerifyAuditLog(ctx)
+			if err != nil {
+				return fmt.Errorf("verify audit log: %w", err)
+			}
+
+			if auditVerifyJSON {
+				if err := json.NewEncoder(out).Encode(struct {
+					backend.AuditLogReport
+					Intact bool `json:"intact"`
+				}{report, report.Intact()}); err != nil {
+					return err
+				}
+			} else {
+				for _, p := range report.Problems {
+					fmt.Fprintln(out, p)
+				}
+				fmt.Fprintf(out, "events: %d chained, %d unchained, in %d segment(s) and the database\n",
+					report.Events, report.Unchained, report.Segments)
+				fmt.Fprintf(out, "head: %s\n", orDash(report.Head))
+				if report.Intact() {
+					fmt.Fprintln(out, "The audit log is intact.")
+				}
+			}
+
+			if !report.Intact() {
+				return errors.New("the audit log was tampered with")
+			}
+
+			return nil
+		},
+	}
+)
+
+func init() {
+	auditQueryCmd.Flags().StringVar(&auditQuery.Actor, "actor", "", "only list the events by this user")
+	auditQueryCmd.Flags().StringVar(&auditQuery.Action, "action", "", "only list the events with this action")
+	auditQueryCmd.Flags().StringVar(&auditQuery.Target, "target", "", "only list the events on this repository")
+	auditQueryCmd.Flags().StringVar(&auditQuerySince, "since", "", "only list the events recorded at or after this time")
+	auditQueryCmd.Flags().StringVar(&auditQueryUntil, "until", "", "only list the events recorded before this time")
+	auditQueryCmd.Flags().IntVar(&auditQuery.Limit, "limit", 0, "only list this many of the latest matching events")
+	auditQueryCmd.Flags().BoolVar(&auditQueryJSON, "json", false, "write the events as JSON")
+	auditVerifyCmd.Flags().BoolVar(&auditVerifyJSON, "json", false, "write the report as JSON")
+	auditCmd.AddCommand(auditQueryCmd, auditRotateCmd, auditVerifyCmd)
+}
+
+// parseAuditTime parses an RFC 3339 timestamp, a date, or a duration before
+// now like 12h or 7d. An empty string is the zero time.
+func parseAuditTime(s string, now time.Time) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+
+	for _, layout := range []string{time.RFC3339, time.DateTime, time.DateOnly} {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return time.Time{}, fmt.Errorf("%q is not a time or a duration", s)
+		}
+		return now.AddDate(0, 0, -n), nil
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return time.Time{}, fmt.Errorf("%q is not a time or a duration", s)
+	}
+
+	return now.Add(-d), nil
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,57 @@
+package admin
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/soft-serve/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+var (
+	configCmd = &cobra.Command{
+		Use:   "config",
+		Short: "Manage the server configuration",
+	}
+
+	configValidateCmd = &cobra.Command{
+		Use:   "validate [FILE]",
+		Short: "Validate the server configuration",
+		Long: `Validate the server configuration without starting the server.
+
+If FILE is not specified, the config file in the data directory is used.
+Environment variables are applied on top of the file, just like the server
+does. The command exits with a nonzero status if any problems are found.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(c *cobra.Command, args []string) error {
+			cfg := config.DefaultConfig()
+			path := cfg.ConfigPath()
+			if len(args) > 0 {
+				path = args[0]
+			}
+
+			if err := cfg.ParseFileAt(path); err != nil {
+				return fmt.Errorf("%s: %w", path, err)
+			}
+
+			if err := cfg.ParseEnv(); err != nil {
+				return fmt.Errorf("%s: %w", path, err)
+			}
+
+			problems := cfg.Check()
+			for _, p := range problems {
+				c.PrintErrf("%s: %v\n", path, p)
+			}
+
+			if len(problems) > 0 {
+				return fmt.Errorf("%s: found %d problem(s)", path, len(problems))
+			}
+
+			fmt.Fprintf(c.OutOrStdout(), "%s: configuration is valid\n", path)
+			return nil
+		},
+	}
+)
+
+func init() {
+	configCmd.AddCommand(configValidateCmd)
+}
@@ -0,0 +1,97 @@
+package admin
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/soft-serve/cmd"
+	"github.com/charmbracelet/soft-serve/pkg/backend"
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/dustin/go-humanize"
+	"github.com/spf13/cobra"
+)
+
+var (
+	dbCmd = &cobra.Command{
+		Use:   "db",
+		Short: "Maintain the database",
+	}
+
+	dbStatsCmd = &cobra.Command{
+		Use:                "stats",
+		Short:              "Show the database size and the number of records per table",
+		Args:               cobra.NoArgs,
+		PersistentPreRunE:  cmd.InitBackendContext,
+		PersistentPostRunE: cmd.CloseDBContext,
+		RunE: func(c *cobra.Command, _ []string) error {
+			ctx := c.Context()
+			out := c.OutOrStdout()
+			stats, err := db.FromContext(ctx).Stats(ctx)
+			if err != nil {
+				return fmt.Errorf("database stats: %w", err)
+			}
+
+			fmt.Fprintf(out, "size\t%s\n", humanize.IBytes(uint64(stats.Size)))
+			if stats.Free > 0 {
+				fmt.Fprintf(out, "free\t%s\n", humanize.IBytes(uint64(stats.Free)))
+			}
+			for _, t := range stats.Tables {
+				fmt.Fprintf(out, "%s\t%d\n", t.Name, t.Rows)
+			}
+
+			return nil
+		},
+	}
+
+	dbCompactNoPrune bool
+
+	dbCompactCmd = &cobra.Command{
+		Use:   "compact",
+		Short: "Prune expired records and reclaim unused database space",
+		Long: `Delete the audit and clone events and the mirror syncs that are past
+their configured retention, and the repository renames past their grace
+period, then vacuum the database to reclaim the space of deleted records.
+
+It's safe to run while the server is serving. With sqlite, the database is
+rebuilt, which blocks writes while it runs, and the server's queries wait up
+to the sqlite busy timeout. With postgres, nothing is blocked. Use --no-prune
+to only vacuum.`,
+		Args:               cobra.NoArgs,
+		PersistentPreRunE:  cmd.InitBackendContext,
+		PersistentPostRunE: cmd.CloseDBContext,
+		RunE: func(c *cobra.Command, _ []string) error {
+			ctx := c.Context()
+			out := c.OutOrStdout()
+			dbx := db.FromContext(ctx)
+			if !dbCompactNoPrune {
+				p, err := backend.FromContext(ctx).PruneRecords(ctx)
+				if err != nil {
+					return fmt.Errorf("prune: %w", err)
+				}
+				fmt.Fprintf(out, "Pruned %d audit event(s), %d clone event(s), %d mirror sync(s), and %d repository rename(s).\n", p.AuditEvents, p.CloneEvents, p.MirrorSyncs, p.RepoRenames)
+			}
+
+			before, err := dbx.Stats(ctx)
+			if err != nil {
+				return fmt.Errorf("database stats: %w", err)
+			}
+
+			if err := dbx.Compact(ctx); err != nil {
+				return fmt.Errorf("compact: %w", err)
+			}
+
+			after, err := dbx.Stats(ctx)
+			if err != nil {
+				return fmt.Errorf("database stats: %w", err)
+			}
+
+			fmt.Fprintf(out, "Compacted the database from %s to %s.\n",
+				humanize.IBytes(uint64(before.Size)), humanize.IBytes(uint64(after.Size)))
+			return nil
+		},
+	}
+)
+
+func init() {
+	dbCompactCmd.Flags().BoolVar(&dbCompactNoPrune, "no-prune", false, "don't delete expired records")
+	dbCmd.AddCommand(dbCompactCmd, dbStatsCmd)
+}
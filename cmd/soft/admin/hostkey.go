@@ -0,0 +1,90 @@
+package admin
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/soft-serve/pkg/config"
+	"github.com/charmbracelet/soft-serve/pkg/ssh"
+	"github.com/spf13/cobra"
+)
+
+var (
+	hostkeyRotateGrace time.Duration
+
+	hostkeyCmd = &cobra.Command{
+		Use:   "hostkey",
+		Short: "Manage the SSH host key",
+	}
+
+	hostkeyShowCmd = &cobra.Command{
+		Use:   "show",
+		Short: "Show the SSH host key fingerprints",
+		Args:  cobra.NoArgs,
+		RunE: func(c *cobra.Command, _ []string) error {
+			cfg := config.FromContext(c.Context())
+			keys, err := ssh.HostKeys(cfg)
+			if err != nil {
+				return fmt.Errorf("host key: %w", err)
+			}
+
+			out := c.OutOrStdout()
+			for _, k := range keys {
+				if k.Retired() {
+					fmt.Fprintf(out, "retired\t%s\t%s\taccepted until %s\n", k.Signer.PublicKey().Type(), k.Fingerprint(), k.Expires.Format(time.RFC3339))
+				} else {
+					fmt.Fprintf(out, "current\t%s\t%s\n", k.Signer.PublicKey().Type(), k.Fingerprint())
+				}
+			}
+
+			return nil
+		},
+	}
+
+	hostkeyRotateCmd = &cobra.Command{
+		Use:   "rotate",
+		Short: "Generate a new SSH host key",
+		Long: `Generate a new SSH host key and retire the current one.
+
+The retired key keeps being served for the grace period while the new key is
+announced to clients, so OpenSSH clients can learn it without known_hosts
+errors. Once the grace period is over the new key is served instead. Restart
+the server to start the rotation.`,
+		Args: cobra.NoArgs,
+		RunE: func(c *cobra.Command, _ []string) error {
+			if hostkeyRotateGrace < 0 {
+				return fmt.Errorf("invalid grace period: %s", hostkeyRotateGrace)
+			}
+
+			cfg := config.FromContext(c.Context())
+			old, _ := ssh.HostKeys(cfg)
+			key, err := ssh.RotateHostKey(cfg, hostkeyRotateGrace)
+			if err != nil {
+				return fmt.Errorf("rotate host key: %w", err)
+			}
+
+			out := c.OutOrStdout()
+			fmt.Fprintf(out, "New host key: %s %s\n", key.Signer.PublicKey().Type(), key.Fingerprint())
+			if len(old) > 0 {
+				if hostkeyRotateGrace > 0 {
+					expires := time.Now().Add(hostkeyRotateGrace)
+					fmt.Fprintf(out, "Retired host key: %s %s, accepted until %s\n", old[0].Signer.PublicKey().Type(), old[0].Fingerprint(), expires.Format(time.RFC3339))
+				} else {
+					fmt.Fprintf(out, "Removed host key: %s %s\n", old[0].Signer.PublicKey().Type(), old[0].Fingerprint())
+				}
+			}
+			fmt.Fprintln(out, "Restart the server to apply the new host key.")
+
+			return nil
+		},
+	}
+)
+
+func init() {
+	hostkeyRotateCmd.Flags().DurationVarP(&hostkeyRotateGrace, "grace", "g", 7*24*time.Hour, "how long the retired host key is accepted")
+
+	hostkeyCmd.AddCommand(
+		hostkeyShowCmd,
+		hostkeyRotateCmd,
+	)
+}
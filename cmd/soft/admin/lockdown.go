@@ -0,0 +1,106 @@
+package admin
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/soft-serve/cmd"
+	"github.com/charmbracelet/soft-serve/pkg/backend"
+	"github.com/spf13/cobra"
+)
+
+var (
+	lockdownLift   bool
+	lockdownStatus bool
+
+	lockdownCmd = &cobra.Command{
+		Use:   "lockdown",
+		Short: "Deny all non-admin access to the server",
+		Long: `Put the server in lockdown, denying all non-admin access.
+
+In lockdown, only admins can authenticate and access repositories, and the SSH
+sessions of non-admins are terminated. Use --lift to lift the lockdown, and
+--status to show whether the server is in lockdown.`,
+		Args:               cobra.NoArgs,
+		PersistentPreRunE:  cmd.InitBackendContext,
+		PersistentPostRunE: cmd.CloseDBContext,
+		RunE: func(c *cobra.Command, _ []string) error {
+			ctx := c.Context()
+			out := c.OutOrStdout()
+			be := backend.FromContext(ctx)
+			if lockdownStatus {
+				if be.Lockdown(ctx) {
+					fmt.Fprintln(out, "Lockdown is enabled.")
+				} else {
+					fmt.Fprintln(out, "Lockdown is disabled.")
+				}
+				return nil
+			}
+
+			if err := be.SetLockdown(ctx, !lockdownLift); err != nil {
+				return err
+			}
+
+			if lockdownLift {
+				fmt.Fprintln(out, "Lockdown lifted.")
+			} else {
+				fmt.Fprintln(out, "Lockdown enabled, only admins have access.")
+			}
+
+			return nil
+		},
+	}
+
+	revokeKeyRestore bool
+
+	revokeKeyCmd = &cobra.Command{
+		Use:   "revoke-key [FINGERPRINT...]",
+		Short: "Revoke SSH public keys",
+		Long: `Revoke SSH public keys by their SHA256 fingerprint, as printed by
+"ssh-keygen -l".
+
+Revoked keys, including admin keys, can't authenticate, and their SSH sessions
+are terminated. Use --restore to restore revoked keys. Without fingerprints,
+the revoked keys are listed.`,
+		PersistentPreRunE:  cmd.InitBackendContext,
+		PersistentPostRunE: cmd.CloseDBContext,
+		RunE: func(c *cobra.Command, args []string) error {
+			ctx := c.Context()
+			out := c.OutOrStdout()
+			be := backend.FromContext(ctx)
+			if len(args) == 0 {
+				keys, err := be.RevokedKeys(ctx)
+				if err != nil {
+					return err
+				}
+
+				for _, k := range keys {
+					fmt.Fprintf(out, "%s\t%s\n", k.Fingerprint, k.CreatedAt.UTC().Format("2006-01-02 15:04:05"))
+				}
+				return nil
+			}
+
+			if revokeKeyRestore {
+				if err := be.RestoreKeys(ctx, args...); err != nil {
+					return err
+				}
+
+				fmt.Fprintf(out, "Restored %d key(s).\n", len(args))
+				return nil
+			}
+
+			if err := be.RevokeKeys(ctx, args...); err != nil {
+				return err
+			}
+
+			fmt.Fprintf(out, "Revoked %d key(s).\n", len(args))
+			return nil
+		},
+	}
+)
+
+func init() {
+	lockdownCmd.Flags().BoolVar(&lockdownLift, "lift", false, "lift the lockdown")
+	lockdownCmd.Flags().BoolVar(&lockdownStatus, "status", false, "show whether the server is in lockdown")
+	lockdownCmd.MarkFlagsMutuallyExclusive("lift", "status")
+	revokeKeyCmd.Flags().BoolVar(&revokeKeyRestore, "restore", false, "restore the revoked keys")
+}
@@ -0,0 +1,58 @@
+package admin
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/soft-serve/cmd"
+	"github.com/charmbracelet/soft-serve/pkg/backend"
+	"github.com/dustin/go-humanize"
+	"github.com/spf13/cobra"
+)
+
+var recomputeSizesCmd = &cobra.Command{
+	Use:   "recompute-sizes [REPOSITORY...]",
+	Short: "Recompute the cached sizes of repositories",
+	Long: `Recompute the cached sizes of repositories on disk, all of them by default.
+
+Sizes are recomputed after each push, and by the repo_sizes job once they're
+older than repo_sizes.max_age. Use this after changing repositories on disk,
+like after a git gc.`,
+	PersistentPreRunE:  cmd.InitBackendContext,
+	PersistentPostRunE: cmd.CloseDBContext,
+	RunE: func(c *cobra.Command, args []string) error {
+		ctx := c.Context()
+		out := c.OutOrStdout()
+		be := backend.FromContext(ctx)
+		names := args
+		if len(names) == 0 {
+			repos, err := be.Repositories(ctx)
+			if err != nil {
+				return err
+			}
+			for _, r := range repos {
+				names = append(names, r.Name())
+			}
+		}
+
+		var failed int
+		var total int64
+		for _, name := range names {
+			size, err := be.RecomputeRepoSize(ctx, name)
+			if err != nil {
+				c.PrintErrf("failed to compute the size of %s: %v\n", name, err)
+				failed++
+				continue
+			}
+
+			total += size
+			fmt.Fprintf(out, "%s\t%s\n", name, humanize.Bytes(uint64(size)))
+		}
+
+		fmt.Fprintf(out, "Recomputed the size of %d repositories, %s in total.\n", len(names)-failed, humanize.Bytes(uint64(total)))
+		if failed > 0 {
+			return fmt.Errorf("failed to compute the size of %d repositories", failed)
+		}
+
+		return nil
+	},
+}
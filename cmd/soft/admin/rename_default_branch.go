@@ -0,0 +1,86 @@
+package admin
+
+import (
+	"errors"
+	"fmt"
+	"path"
+
+	"github.com/charmbracelet/soft-serve/cmd"
+	"github.com/charmbracelet/soft-serve/git"
+	"github.com/charmbracelet/soft-serve/pkg/backend"
+	"github.com/spf13/cobra"
+)
+
+var (
+	renameDefaultBranchFrom      string
+	renameDefaultBranchTo        string
+	renameDefaultBranchRepos     string
+	renameDefaultBranchDeleteOld bool
+)
+
+var renameDefaultBranchCmd = &cobra.Command{
+	Use:   "rename-default-branch",
+	Short: "Rename the default branch of repositories",
+	Long: `Rename the default branch of repositories, like from master to main.
+
+For each repository whose default branch is the --from branch, the --to branch
+is created at its tip and becomes the default branch. The old branch is kept
+unless --delete-old is set. Repositories without the --from branch, or with
+another default branch, are skipped. Pushes to the old branch are told about
+the new one.`,
+	Args:               cobra.NoArgs,
+	PersistentPreRunE:  cmd.InitBackendContext,
+	PersistentPostRunE: cmd.CloseDBContext,
+	RunE: func(c *cobra.Command, _ []string) error {
+		ctx := c.Context()
+		out := c.OutOrStdout()
+		be := backend.FromContext(ctx)
+		from, to := renameDefaultBranchFrom, renameDefaultBranchTo
+		pattern := renameDefaultBranchRepos
+		if from == "" || to == "" {
+			return fmt.Errorf("both --from and --to are required")
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid repository pattern %q: %w", pattern, err)
+		}
+
+		repos, err := be.Repositories(ctx)
+		if err != nil {
+			return err
+		}
+
+		var renamed, skipped, failed int
+		for _, r := range repos {
+			if ok, _ := path.Match(pattern, r.Name()); pattern != "" && !ok {
+				continue
+			}
+
+			err := be.RenameDefaultBranch(ctx, r.Name(), from, to, renameDefaultBranchDeleteOld)
+			switch {
+			case err == nil:
+				renamed++
+				fmt.Fprintf(out, "%s\trenamed %s to %s\n", r.Name(), from, to)
+			case errors.Is(err, git.ErrReferenceNotExist), errors.Is(err, backend.ErrNotDefaultBranch):
+				skipped++
+				fmt.Fprintf(out, "%s\tskipped: %v\n", r.Name(), err)
+			default:
+				failed++
+				c.PrintErrf("failed to rename the default branch of %s: %v\n", r.Name(), err)
+			}
+		}
+
+		fmt.Fprintf(out, "Renamed the default branch of %d repositories, skipped %d.\n", renamed, skipped)
+		if failed > 0 {
+			return fmt.Errorf("failed to rename the default branch of %d repositories", failed)
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	renameDefaultBranchCmd.Flags().StringVar(&renameDefaultBranchFrom, "from", "", "the current default branch")
+	renameDefaultBranchCmd.Flags().StringVar(&renameDefaultBranchTo, "to", "", "the new default branch")
+	renameDefaultBranchCmd.Flags().StringVar(&renameDefaultBranchRepos, "repos", "", "only rename the default branch of repositories matching this pattern, like 'team/*'")
+	renameDefaultBranchCmd.Flags().BoolVar(&renameDefaultBranchDeleteOld, "delete-old", false, "delete the old branch")
+}
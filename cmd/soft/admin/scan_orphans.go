@@ -0,0 +1,91 @@
+package admin
+
+import (
+	"bufio"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/soft-serve/cmd"
+	"github.com/charmbracelet/soft-serve/pkg/backend"
+	"github.com/spf13/cobra"
+)
+
+var (
+	scanOrphansFix bool
+	scanOrphansYes bool
+
+	scanOrphansCmd = &cobra.Command{
+		Use:   "scan-orphans",
+		Short: "Find repositories that are out of sync with the database",
+		Long: `Find repository directories with no database metadata, and database
+metadata with no repository directory.
+
+Use --fix to register orphaned directories and delete dangling metadata.`,
+		Args:               cobra.NoArgs,
+		PersistentPreRunE:  cmd.InitBackendContext,
+		PersistentPostRunE: cmd.CloseDBContext,
+		RunE: func(c *cobra.Command, _ []string) error {
+			ctx := c.Context()
+			out := c.OutOrStdout()
+			be := backend.FromContext(ctx)
+			report, err := be.ScanOrphans(ctx)
+			if err != nil {
+				return fmt.Errorf("scan orphans: %w", err)
+			}
+
+			if report.Empty() {
+				fmt.Fprintln(out, "No orphans found.")
+				return nil
+			}
+
+			for _, name := range report.Directories {
+				fmt.Fprintf(out, "directory without metadata: %s\n", name)
+			}
+			for _, name := range report.Metadata {
+				fmt.Fprintf(out, "metadata without directory: %s\n", name)
+			}
+
+			if !scanOrphansFix {
+				return nil
+			}
+
+			if !scanOrphansYes {
+				fmt.Fprint(out, "Register orphaned directories and delete dangling metadata? [y/N] ")
+				answer, _ := bufio.NewReader(c.InOrStdin()).ReadString('\n')
+				if a := strings.ToLower(strings.TrimSpace(answer)); a != "y" && a != "yes" {
+					fmt.Fprintln(out, "Aborted.")
+					return nil
+				}
+			}
+
+			var failed int
+			for _, name := range report.Directories {
+				if err := be.RegisterRepository(ctx, name); err != nil {
+					c.PrintErrf("failed to register %s: %v\n", name, err)
+					failed++
+					continue
+				}
+				fmt.Fprintf(out, "registered %s\n", name)
+			}
+			for _, name := range report.Metadata {
+				if err := be.DeleteRepositoryMetadata(ctx, name); err != nil {
+					c.PrintErrf("failed to delete metadata for %s: %v\n", name, err)
+					failed++
+					continue
+				}
+				fmt.Fprintf(out, "deleted metadata for %s\n", name)
+			}
+
+			if failed > 0 {
+				return fmt.Errorf("failed to fix %d orphan(s)", failed)
+			}
+
+			return nil
+		},
+	}
+)
+
+func init() {
+	scanOrphansCmd.Flags().BoolVar(&scanOrphansFix, "fix", false, "register orphaned directories and delete dangling metadata")
+	scanOrphansCmd.Flags().BoolVarP(&scanOrphansYes, "yes", "y", false, "don't ask for confirmation when fixing")
+}
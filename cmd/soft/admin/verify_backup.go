@@ -0,0 +1,74 @@
+package admin
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/charmbracelet/soft-serve/cmd"
+	"github.com/charmbracelet/soft-serve/pkg/backend"
+	"github.com/spf13/cobra"
+)
+
+var (
+	verifyBackupJSON bool
+
+	verifyBackupCmd = &cobra.Command{
+		Use:   "verify-backup BACKUP REPOSITORY",
+		Short: "Compare a repository against a backup",
+		Long: `Compare the refs and the reachable objects of a repository against a backup,
+either a git bundle file or a mirror clone.
+
+Bundles are restored in a temporary repository first, which checks they're
+complete. Every ref that differs is reported, with the number of objects
+missing from either side. Use --json to write the report as a JSON object.
+The command fails when the backup doesn't match the repository.`,
+		Args:               cobra.ExactArgs(2),
+		PersistentPreRunE:  cmd.InitBackendContext,
+		PersistentPostRunE: cmd.CloseDBContext,
+		RunE: func(c *cobra.Command, args []string) error {
+			ctx := c.Context()
+			out := c.OutOrStdout()
+			be := backend.FromContext(ctx)
+			report, err := be.VerifyBackup(ctx, args[1], args[0])
+			if err != nil {
+				return fmt.Errorf("verify backup: %w", err)
+			}
+
+			if verifyBackupJSON {
+				if err := json.NewEncoder(out).Encode(struct {
+					backend.BackupReport
+					Match bool `json:"match"`
+				}{report, report.Match()}); err != nil {
+					return err
+				}
+			} else {
+				for _, r := range report.Refs {
+					switch {
+					case r.Backup == "":
+						fmt.Fprintf(out, "ref %s: missing from backup\n", r.Ref)
+					case r.Repo == "":
+						fmt.Fprintf(out, "ref %s: missing from repository\n", r.Ref)
+					default:
+						fmt.Fprintf(out, "ref %s: %s in repository, %s in backup\n", r.Ref, r.Repo, r.Backup)
+					}
+				}
+				fmt.Fprintf(out, "objects: %d in repository, %d in backup, %d missing from backup, %d missing from repository\n",
+					report.Objects, report.BackupObjects, report.MissingObjects, report.ExtraObjects)
+				if report.Match() {
+					fmt.Fprintf(out, "%s backup %s matches %s.\n", report.Type, args[0], args[1])
+				}
+			}
+
+			if !report.Match() {
+				return errors.New("backup doesn't match the repository")
+			}
+
+			return nil
+		},
+	}
+)
+
+func init() {
+	verifyBackupCmd.Flags().BoolVar(&verifyBackupJSON, "json", false, "write the report as JSON")
+}
@@ -11,6 +11,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/log"
 	"github.com/charmbracelet/soft-serve/cmd"
@@ -58,68 +59,22 @@ var (
 	// starts, so we don't need to do it again.
 	// The --config flag is now deprecated.
 	hooksRunE = func(cmd *cobra.Command, args []string) error {
-		ctx := cmd.Context()
-		hks := backend.FromContext(ctx)
-		cfg := config.FromContext(ctx)
-
-		// This is set in the server before invoking git-receive-pack/git-upload-pack
-		repoName := os.Getenv("SOFT_SERVE_REPO_NAME")
-
-		stdin := cmd.InOrStdin()
-		stdout := cmd.OutOrStdout()
-		stderr := cmd.ErrOrStderr()
-
-		cmdName := cmd.Name()
-		customHookPath := filepath.Join(cfg.DataPath, "hooks", cmdName)
-
-		var buf bytes.Buffer
-		opts := make([]hooks.HookArg, 0)
-
-		switch cmdName {
-		case hooks.PreReceiveHook, hooks.PostReceiveHook:
-			scanner := bufio.NewScanner(stdin)
-			for scanner.Scan() {
-				buf.Write(scanner.Bytes())
-				fields := strings.Fields(scanner.Text())
-				if len(fields) != 3 {
-					return fmt.Errorf("invalid hook input: %s", scanner.Text())
-				}
-				opts = append(opts, hooks.HookArg{
-					OldSha:  fields[0],
-					NewSha:  fields[1],
-					RefName: fields[2],
-				})
-			}
-
-			switch cmdName {
-			case hooks.PreReceiveHook:
-				hks.PreReceive(ctx, stdout, stderr, repoName, opts)
-			case hooks.PostReceiveHook:
-				hks.PostReceive(ctx, stdout, stderr, repoName, opts)
-			}
-		case hooks.UpdateHook:
-			if len(args) != 3 {
-				return fmt.Errorf("invalid update hook input: %s", args)
-			}
-
-			hks.Update(ctx, stdout, stderr, repoName, hooks.HookArg{
-				RefName: args[0],
-				OldSha:  args[1],
-				NewSha:  args[2],
-			})
-		case hooks.PostUpdateHook:
-			hks.PostUpdate(ctx, stdout, stderr, repoName, args...)
+		start := time.Now()
+		e, err := runHooks(cmd, args)
+		e.Hook = cmd.Name()
+		e.Repo = os.Getenv("SOFT_SERVE_REPO_NAME")
+		e.Start = start
+		e.Duration = time.Since(start)
+		if err != nil {
+			e.Error = err.Error()
 		}
 
-		// Custom hooks
-		if stat, err := os.Stat(customHookPath); err == nil && !stat.IsDir() && stat.Mode()&0o111 != 0 {
-			// If the custom hook is executable, run it
-			if err := runCommand(ctx, &buf, stdout, stderr, customHookPath, args...); err != nil {
-				return fmt.Errorf("failed to run custom hook: %w", err)
-			}
+		// The server records the execution once git is done.
+		if err := hooks.WriteReport(e); err != nil {
+			log.FromContext(cmd.Context()).Error("failed to report hook execution", "err", err)
 		}
 
-		return nil
+		return err
 	}
 
 	preReceiveCmd = &cobra.Command{
@@ -158,10 +113,113 @@ func init() {
 	)
 }
 
-func runCommand(ctx context.Context, in io.Reader, out io.Writer, err io.Writer, name string, args ...string) error {
+// runHooks runs the built-in and custom hooks, and returns the outcome of
+// the execution.
+func runHooks(cmd *cobra.Command, args []string) (hooks.Execution, error) {
+	ctx := cmd.Context()
+	hks := backend.FromContext(ctx)
+	cfg := config.FromContext(ctx)
+
+	// This is set in the server before invoking git-receive-pack/git-upload-pack
+	repoName := os.Getenv("SOFT_SERVE_REPO_NAME")
+
+	stdin := cmd.InOrStdin()
+	stdout := cmd.OutOrStdout()
+	stderr := cmd.ErrOrStderr()
+
+	cmdName := cmd.Name()
+	customHookPath := filepath.Join(cfg.DataPath, "hooks", cmdName)
+
+	var buf bytes.Buffer
+	opts := make([]hooks.HookArg, 0)
+	errored := hooks.Execution{Outcome: hooks.OutcomeErrored}
+
+	switch cmdName {
+	case hooks.PreReceiveHook, hooks.PostReceiveHook:
+		scanner := bufio.NewScanner(stdin)
+		for scanner.Scan() {
+			buf.Write(scanner.Bytes())
+			fields := strings.Fields(scanner.Text())
+			if len(fields) != 3 {
+				return errored, fmt.Errorf("invalid hook input: %s", scanner.Text())
+			}
+			opts = append(opts, hooks.HookArg{
+				OldSha:  fields[0],
+				NewSha:  fields[1],
+				RefName: fields[2],
+			})
+		}
+
+		switch cmdName {
+		case hooks.PreReceiveHook:
+			// The built-in pre-receive hook only fails on policy checks.
+			if err := hks.PreReceive(ctx, stdout, stderr, repoName, opts); err != nil {
+				return hooks.Execution{Outcome: hooks.OutcomeRejected}, err
+			}
+		case hooks.PostReceiveHook:
+			hks.PostReceive(ctx, stdout, stderr, repoName, opts)
+			// The server runs the post-receive exec command with them once
+			// git is done.
+			errored.Updates = opts
+		}
+	case hooks.UpdateHook:
+		if len(args) != 3 {
+			return errored, fmt.Errorf("invalid update hook input: %s", args)
+		}
+
+		hks.Update(ctx, stdout, stderr, repoName, hooks.HookArg{
+			RefName: args[0],
+			OldSha:  args[1],
+			NewSha:  args[2],
+		})
+	case hooks.PostUpdateHook:
+		hks.PostUpdate(ctx, stdout, stderr, repoName, args...)
+	}
+
+	// Custom hooks
+	if stat, err := os.Stat(customHookPath); err == nil && !stat.IsDir() && stat.Mode()&0o111 != 0 {
+		// If the custom hook is executable, run it
+		timeout := time.Duration(cfg.Timeouts.Hook) * time.Second
+		if err := runCommand(ctx, timeout, &buf, stdout, stderr, customHookPath, args...); err != nil {
+			var exitErr *exec.ExitError
+			switch {
+			case errors.Is(err, context.DeadlineExceeded):
+				errored.TimedOut = true
+				return errored, fmt.Errorf("custom hook timed out after %s", timeout)
+			case errors.As(err, &exitErr) && (cmdName == hooks.PreReceiveHook || cmdName == hooks.UpdateHook):
+				// Only these hooks can reject a push, a non-zero exit status
+				// is how they do it.
+				return hooks.Execution{Outcome: hooks.OutcomeRejected}, fmt.Errorf("failed to run custom hook: %w", err)
+			default:
+				return errored, fmt.Errorf("failed to run custom hook: %w", err)
+			}
+		}
+	}
+
+	return hooks.Execution{Outcome: hooks.OutcomeAccepted, Updates: errored.Updates}, nil
+}
+
+// runCommand runs a command, killing it after timeout. A zero timeout means
+// no timeout. It returns context.DeadlineExceeded if the command timed out.
+func runCommand(ctx context.Context, timeout time.Duration, in io.Reader, out io.Writer, err io.Writer, name string, args ...string) error {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
 	cmd := exec.CommandContext(ctx, name, args...)
 	cmd.Stdin = in
 	cmd.Stdout = out
 	cmd.Stderr = err
-	return cmd.Run()
+	// Don't wait forever on children of the hook holding its output open.
+	cmd.WaitDelay = time.Second
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return err
+	}
+
+	return nil
 }
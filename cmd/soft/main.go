@@ -117,10 +117,17 @@ func main() {
 	}
 
 	ctx = log.WithContext(ctx, logger)
+	ctx = logr.WithSampler(ctx, logr.NewSampler(cfg.Log.SampleRate, cfg.Log.RateLimit))
 	if f != nil {
 		defer f.Close() // nolint: errcheck
 	}
 
+	syslog, err := logr.NewSyslog(cfg.Log.Syslog)
+	if err != nil {
+		log.Errorf("failed to create syslog writer: %v", err)
+	}
+	ctx = logr.WithSyslog(ctx, syslog)
+
 	// Set global logger
 	log.SetDefault(logger)
 
@@ -135,7 +142,9 @@ func main() {
 		log.Warn("couldn't set automaxprocs", "error", err)
 	}
 
-	if err := rootCmd.ExecuteContext(ctx); err != nil {
+	err = rootCmd.ExecuteContext(ctx)
+	syslog.Close() // nolint: errcheck
+	if err != nil {
 		os.Exit(1)
 	}
 }
@@ -12,6 +12,7 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/charmbracelet/log"
 	"github.com/charmbracelet/soft-serve/cmd"
 	"github.com/charmbracelet/soft-serve/pkg/backend"
 	"github.com/charmbracelet/soft-serve/pkg/config"
@@ -88,6 +89,18 @@ var (
 
 			signal.Notify(done, os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
 
+			// Reload runtime settings on SIGHUP.
+			hup := make(chan os.Signal, 1)
+			signal.Notify(hup, syscall.SIGHUP)
+			defer signal.Stop(hup)
+			go func() {
+				for range hup {
+					if err := s.Reload(); err != nil {
+						log.FromContext(ctx).Error("reload configuration", "err", err)
+					}
+				}
+			}()
+
 			// This endpoint is added for testing purposes
 			// It allows us to stop the server from the test suite.
 			// This is needed since Windows doesn't support signals.
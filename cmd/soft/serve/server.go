@@ -13,9 +13,13 @@ import (
 	"github.com/charmbracelet/soft-serve/pkg/cron"
 	"github.com/charmbracelet/soft-serve/pkg/daemon"
 	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/geoblock"
 	"github.com/charmbracelet/soft-serve/pkg/jobs"
+	logr "github.com/charmbracelet/soft-serve/pkg/log"
+	"github.com/charmbracelet/soft-serve/pkg/profiling"
 	sshsrv "github.com/charmbracelet/soft-serve/pkg/ssh"
 	"github.com/charmbracelet/soft-serve/pkg/stats"
+	"github.com/charmbracelet/soft-serve/pkg/tracing"
 	"github.com/charmbracelet/soft-serve/pkg/web"
 	"github.com/charmbracelet/ssh"
 	"golang.org/x/sync/errgroup"
@@ -23,14 +27,19 @@ import (
 
 // Server is the Soft Serve server.
 type Server struct {
-	SSHServer   *sshsrv.SSHServer
-	GitDaemon   *daemon.GitDaemon
-	HTTPServer  *web.HTTPServer
-	StatsServer *stats.StatsServer
-	Cron        *cron.Scheduler
-	Config      *config.Config
-	Backend     *backend.Backend
-	DB          *db.DB
+	SSHServer       *sshsrv.SSHServer
+	GitDaemon       *daemon.GitDaemon
+	HTTPServer      *web.HTTPServer
+	StatsServer     *stats.StatsServer
+	ProfilingServer *profiling.ProfilingServer
+	Cron            *cron.Scheduler
+	Config          *config.Config
+	Backend         *backend.Backend
+	DB              *db.DB
+	Geoblock        *geoblock.Blocker
+
+	// shutdownTracing flushes the pending spans and stops tracing.
+	shutdownTracing func(context.Context) error
 
 	logger *log.Logger
 	ctx    context.Context
@@ -54,27 +63,40 @@ func NewServer(ctx context.Context) (*Server, error) {
 		ctx:     ctx,
 	}
 
-	// Add cron jobs.
+	// Add cron jobs. Jobs write to the repositories, so only the primary runs
+	// them.
 	sched := cron.NewScheduler(ctx)
-	for n, j := range jobs.List() {
-		id, err := sched.AddFunc(j.Runner.Spec(ctx), j.Runner.Func(ctx))
-		if err != nil {
-			logger.Warn("error adding cron job", "job", n, "err", err)
-		}
+	if !cfg.Replication.IsReplica() {
+		for n, j := range jobs.List() {
+			id, err := sched.AddFunc(j.Runner.Spec(ctx), j.Runner.Func(ctx))
+			if err != nil {
+				logger.Warn("error adding cron job", "job", n, "err", err)
+			}
 
-		j.ID = id
+			j.ID = id
+		}
 	}
 
 	srv.Cron = sched
 
+	srv.Geoblock, err = geoblock.New(ctx, cfg.Geoblock)
+	if err != nil {
+		return nil, fmt.Errorf("create geoblock: %w", err)
+	}
+
+	ctx = geoblock.WithContext(ctx, srv.Geoblock)
+	srv.ctx = ctx
+
 	srv.SSHServer, err = sshsrv.NewSSHServer(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("create ssh server: %w", err)
 	}
 
-	srv.GitDaemon, err = daemon.NewGitDaemon(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("create git daemon: %w", err)
+	if cfg.Git.Enabled {
+		srv.GitDaemon, err = daemon.NewGitDaemon(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("create git daemon: %w", err)
+		}
 	}
 
 	srv.HTTPServer, err = web.NewHTTPServer(ctx)
@@ -87,19 +109,33 @@ func NewServer(ctx context.Context) (*Server, error) {
 		return nil, fmt.Errorf("create stats server: %w", err)
 	}
 
+	srv.shutdownTracing, err = tracing.Init(ctx, cfg.Tracing)
+	if err != nil {
+		return nil, fmt.Errorf("init tracing: %w", err)
+	}
+
+	if cfg.Profiling.Enabled {
+		srv.ProfilingServer, err = profiling.NewProfilingServer(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("create profiling server: %w", err)
+		}
+	}
+
 	return srv, nil
 }
 
 // Start starts the SSH server.
 func (s *Server) Start() error {
 	errg, _ := errgroup.WithContext(s.ctx)
-	errg.Go(func() error {
-		s.logger.Print("Starting Git daemon", "addr", s.Config.Git.ListenAddr)
-		if err := s.GitDaemon.Start(); !errors.Is(err, daemon.ErrServerClosed) {
-			return err
-		}
-		return nil
-	})
+	if s.GitDaemon != nil {
+		errg.Go(func() error {
+			s.logger.Print("Starting Git daemon", "addr", s.Config.Git.ListenAddr)
+			if err := s.GitDaemon.Start(); !errors.Is(err, daemon.ErrServerClosed) {
+				return err
+			}
+			return nil
+		})
+	}
 	errg.Go(func() error {
 		s.logger.Print("Starting HTTP server", "addr", s.Config.HTTP.ListenAddr)
 		if err := s.HTTPServer.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
@@ -115,12 +151,26 @@ func (s *Server) Start() error {
 		return nil
 	})
 	errg.Go(func() error {
-		s.logger.Print("Starting Stats server", "addr", s.Config.Stats.ListenAddr)
+		if s.Config.Stats.HasExporter(config.StatsExporterPrometheus) {
+			s.logger.Print("Starting Stats server", "addr", s.Config.Stats.ListenAddr)
+		}
+		if s.Config.Stats.HasExporter(config.StatsExporterOTLP) {
+			s.logger.Print("Exporting metrics over OTLP", "endpoint", s.Config.Stats.OTLP.Endpoint, "protocol", s.Config.Stats.OTLP.Protocol)
+		}
 		if err := s.StatsServer.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
 			return err
 		}
 		return nil
 	})
+	if s.ProfilingServer != nil {
+		errg.Go(func() error {
+			s.logger.Print("Starting Profiling server", "addr", s.Config.Profiling.ListenAddr)
+			if err := s.ProfilingServer.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+				return err
+			}
+			return nil
+		})
+	}
 	errg.Go(func() error {
 		s.Cron.Start()
 		return nil
@@ -131,9 +181,11 @@ func (s *Server) Start() error {
 // Shutdown lets the server gracefully shutdown.
 func (s *Server) Shutdown(ctx context.Context) error {
 	errg, ctx := errgroup.WithContext(ctx)
-	errg.Go(func() error {
-		return s.GitDaemon.Shutdown(ctx)
-	})
+	if s.GitDaemon != nil {
+		errg.Go(func() error {
+			return s.GitDaemon.Shutdown(ctx)
+		})
+	}
 	errg.Go(func() error {
 		return s.HTTPServer.Shutdown(ctx)
 	})
@@ -143,6 +195,11 @@ func (s *Server) Shutdown(ctx context.Context) error {
 	errg.Go(func() error {
 		return s.StatsServer.Shutdown(ctx)
 	})
+	if s.ProfilingServer != nil {
+		errg.Go(func() error {
+			return s.ProfilingServer.Shutdown(ctx)
+		})
+	}
 	errg.Go(func() error {
 		for _, j := range jobs.List() {
 			s.Cron.Remove(j.ID)
@@ -150,17 +207,57 @@ func (s *Server) Shutdown(ctx context.Context) error {
 		s.Cron.Stop()
 		return nil
 	})
+	if s.shutdownTracing != nil {
+		errg.Go(func() error {
+			return s.shutdownTracing(ctx)
+		})
+	}
 	// defer s.DB.Close() // nolint: errcheck
 	return errg.Wait()
 }
 
+// Reload re-reads the configuration file and environment and applies the
+// settings that can change at runtime.
+func (s *Server) Reload() error {
+	cfg := config.DefaultConfig()
+	if cfg.Exist() {
+		if err := cfg.ParseFile(); err != nil {
+			return fmt.Errorf("parse config file: %w", err)
+		}
+	}
+
+	if err := cfg.ParseEnv(); err != nil {
+		return fmt.Errorf("parse environment variables: %w", err)
+	}
+
+	if err := s.SSHServer.SetSources(cfg.SSH.Sources); err != nil {
+		return fmt.Errorf("reload ssh sources: %w", err)
+	}
+
+	if err := s.Geoblock.Set(cfg.Geoblock); err != nil {
+		return fmt.Errorf("reload geoblock: %w", err)
+	}
+
+	if sampler := logr.SamplerFromContext(s.ctx); sampler != nil {
+		sampler.Set(cfg.Log.SampleRate, cfg.Log.RateLimit)
+	}
+
+	s.logger.Info("reloaded configuration")
+	return nil
+}
+
 // Close closes the SSH server.
 func (s *Server) Close() error {
 	var errg errgroup.Group
-	errg.Go(s.GitDaemon.Close)
+	if s.GitDaemon != nil {
+		errg.Go(s.GitDaemon.Close)
+	}
 	errg.Go(s.HTTPServer.Close)
 	errg.Go(s.SSHServer.Close)
 	errg.Go(s.StatsServer.Close)
+	if s.ProfilingServer != nil {
+		errg.Go(s.ProfilingServer.Close)
+	}
 	errg.Go(func() error {
 		s.Cron.Stop()
 		return nil
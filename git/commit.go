@@ -1,14 +1,31 @@
 package git
 
 import (
+	"fmt"
 	"regexp"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/aymanbagabas/git-module"
 )
 
-// ZeroID is the zero hash.
+// ZeroID is the zero hash of SHA-1 repositories.
 const ZeroID = git.EmptyID
 
+// ZeroIDSHA256 is the zero hash of SHA-256 repositories.
+const ZeroIDSHA256 = ZeroID + "000000000000000000000000"
+
+// ZeroIDOf returns the zero hash of the object format the hash h is in, like
+// a commit ID of the repository.
+func ZeroIDOf(h string) string {
+	if len(h) == len(ZeroIDSHA256) {
+		return ZeroIDSHA256
+	}
+
+	return ZeroID
+}
+
 // IsZeroHash returns whether the hash is a zero hash.
 func IsZeroHash(h string) bool {
 	pattern := regexp.MustCompile(`^0{40,}$`)
@@ -31,3 +48,63 @@ func (cl Commits) Swap(i, j int) { cl[i], cl[j] = cl[j], cl[i] }
 func (cl Commits) Less(i, j int) bool {
 	return cl[i].Author.When.After(cl[j].Author.When)
 }
+
+// CommitInfo is the metadata of a commit. Unlike Commit, it's read from git
+// log instead of the commit object, so it works with every object format.
+type CommitInfo struct {
+	ID        string
+	Message   string
+	Author    Signature
+	Committer Signature
+}
+
+// Signature is the author or committer of a commit.
+type Signature = git.Signature
+
+// Summary returns the first line of the commit message.
+func (c CommitInfo) Summary() string {
+	return strings.Split(c.Message, "\n")[0]
+}
+
+// commitInfoFields is the number of fields of the commitInfoFormat.
+const commitInfoFields = 8
+
+// commitInfoFormat is the git log format of CommitInfo, with NUL separated
+// fields.
+const commitInfoFormat = "--format=%H%x00%an%x00%ae%x00%at%x00%cn%x00%ce%x00%ct%x00%B"
+
+// parseCommitInfos parses the output of git log -z with the commitInfoFormat.
+func parseCommitInfos(out []byte) ([]CommitInfo, error) {
+	// Every commit ends with a NUL, like every field.
+	s := strings.TrimSuffix(string(out), "\x00")
+	if s == "" {
+		return []CommitInfo{}, nil
+	}
+
+	fields := strings.Split(s, "\x00")
+	if len(fields)%commitInfoFields != 0 {
+		return nil, fmt.Errorf("unexpected git log output with %d fields", len(fields))
+	}
+
+	commits := make([]CommitInfo, 0, len(fields)/commitInfoFields)
+	for i := 0; i < len(fields); i += commitInfoFields {
+		f := fields[i : i+commitInfoFields]
+		authored, err := strconv.ParseInt(f[3], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid author date %q: %w", f[3], err)
+		}
+		committed, err := strconv.ParseInt(f[6], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid committer date %q: %w", f[6], err)
+		}
+
+		commits = append(commits, CommitInfo{
+			ID:        f[0],
+			Message:   f[7],
+			Author:    Signature{Name: f[1], Email: f[2], When: time.Unix(authored, 0)},
+			Committer: Signature{Name: f[4], Email: f[5], When: time.Unix(committed, 0)},
+		})
+	}
+
+	return commits, nil
+}
@@ -0,0 +1,41 @@
+package git
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestZeroIDOf(t *testing.T) {
+	is := is.New(t)
+	is.Equal(ZeroIDOf(strings.Repeat("a", 40)), ZeroID)
+	is.Equal(ZeroIDOf(strings.Repeat("a", 64)), ZeroIDSHA256)
+	is.Equal(len(ZeroIDSHA256), 64)
+	is.True(IsZeroHash(ZeroIDSHA256))
+}
+
+func TestParseCommitInfos(t *testing.T) {
+	is := is.New(t)
+	sha256 := strings.Repeat("b", 64)
+	out := sha256 + "\x00Author\x00author@example.com\x001700000000\x00Committer\x00committer@example.com\x001700000060\x00second\n\nbody\n\x00" +
+		ZeroID + "\x00A\x00a@example.com\x001600000000\x00C\x00c@example.com\x001600000000\x00first\n\x00"
+
+	commits, err := parseCommitInfos([]byte(out))
+	is.NoErr(err)
+	is.Equal(len(commits), 2)
+	is.Equal(commits[0].ID, sha256)
+	is.Equal(commits[0].Summary(), "second")
+	is.Equal(commits[0].Message, "second\n\nbody\n")
+	is.Equal(commits[0].Author.Email, "author@example.com")
+	is.Equal(commits[0].Committer.Name, "Committer")
+	is.Equal(commits[0].Committer.When.Unix(), int64(1700000060))
+	is.Equal(commits[1].ID, ZeroID)
+
+	commits, err = parseCommitInfos(nil)
+	is.NoErr(err)
+	is.Equal(len(commits), 0)
+
+	_, err = parseCommitInfos([]byte("abc\x00def\x00"))
+	is.True(err != nil)
+}
@@ -0,0 +1,49 @@
+package git
+
+import (
+	"errors"
+
+	"github.com/aymanbagabas/git-module"
+)
+
+// ErrNoMergeBase is returned when two commits have no common ancestor.
+var ErrNoMergeBase = git.ErrNoMergeBase
+
+// DiffLineType is the type of a line in a diff.
+type DiffLineType = git.DiffLineType
+
+const (
+	// DiffLinePlain is an unchanged context line.
+	DiffLinePlain = git.DiffLinePlain
+	// DiffLineAdd is an added line.
+	DiffLineAdd = git.DiffLineAdd
+	// DiffLineDelete is a deleted line.
+	DiffLineDelete = git.DiffLineDelete
+	// DiffLineSection is the "@@" header line of a hunk.
+	DiffLineSection = git.DiffLineSection
+)
+
+// CompareDiff returns the diff from the base commit to the head commit.
+// Large diffs are truncated to DiffMaxFiles files of DiffMaxFileLines lines.
+func (r *Repository) CompareDiff(base, head string) (*Diff, error) {
+	diff, err := r.Repository.Diff(head, DiffMaxFiles, DiffMaxFileLines, DiffMaxLineChars, git.DiffOptions{
+		Base: base,
+		CommandOptions: git.CommandOptions{
+			Envs: []string{"GIT_CONFIG_GLOBAL=/dev/null"},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return toDiff(diff), nil
+}
+
+// MergeBase returns the best common ancestor of two commits, or
+// ErrNoMergeBase if they have none.
+func (r *Repository) MergeBase(base, head string) (string, error) {
+	mb, err := r.Repository.MergeBase(base, head)
+	if errors.Is(err, git.ErrNoMergeBase) {
+		return "", ErrNoMergeBase
+	}
+	return mb, err
+}
@@ -1,6 +1,7 @@
 package git
 
 import (
+	"fmt"
 	"path/filepath"
 	"strings"
 
@@ -99,7 +100,7 @@ func (r *Repository) References() ([]*Reference, error) {
 
 // LsTree returns the tree for the given reference.
 func (r *Repository) LsTree(ref string) (*Tree, error) {
-	tree, err := r.Repository.LsTree(ref)
+	tree, err := r.Repository.LsTree(ref, lsTreeOptions)
 	if err != nil {
 		return nil, err
 	}
@@ -176,6 +177,17 @@ func (r *Repository) CommitsByPage(ref *Reference, page, size int) (Commits, err
 	return commits, nil
 }
 
+// CommitInfos returns the metadata of up to max commits of the revision range
+// rev, newest first. Unlike CommitsByPage, it supports every object format.
+func (r *Repository) CommitInfos(rev string, max int) ([]CommitInfo, error) {
+	out, err := NewCommand("log", "-z", commitInfoFormat, fmt.Sprintf("--max-count=%d", max), rev, "--").RunInDir(r.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseCommitInfos(out)
+}
+
 // SymbolicRef returns or updates the symbolic reference for the given name.
 // Both name and ref can be empty.
 func (r *Repository) SymbolicRef(name string, ref string, opts ...git.SymbolicRefOptions) (string, error) {
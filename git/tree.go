@@ -18,6 +18,15 @@ type Tree struct {
 	Repository *Repository
 }
 
+// lsTreeOptions are the options of the ls-tree commands listing trees. The
+// git module parses object IDs of 40 characters, so the IDs of SHA-256
+// repositories are abbreviated to 40 characters, which git still resolves.
+var lsTreeOptions = git.LsTreeOptions{
+	CommandOptions: git.CommandOptions{
+		Args: []string{"--abbrev=40"},
+	},
+}
+
 // TreeEntry is a wrapper around git.TreeEntry with helper methods.
 type TreeEntry struct {
 	*git.TreeEntry
@@ -82,7 +91,7 @@ func (f *File) Path() string {
 
 // SubTree returns the sub-tree at the given path.
 func (t *Tree) SubTree(path string) (*Tree, error) {
-	tree, err := t.Subtree(path)
+	tree, err := t.Subtree(path, lsTreeOptions)
 	if err != nil {
 		return nil, err
 	}
@@ -95,7 +104,7 @@ func (t *Tree) SubTree(path string) (*Tree, error) {
 
 // Entries returns the entries in the tree.
 func (t *Tree) Entries() (Entries, error) {
-	entries, err := t.Tree.Entries()
+	entries, err := t.Tree.Entries(lsTreeOptions)
 	if err != nil {
 		return nil, err
 	}
@@ -111,7 +120,7 @@ func (t *Tree) Entries() (Entries, error) {
 
 // TreeEntry returns the TreeEntry for the file path.
 func (t *Tree) TreeEntry(path string) (*TreeEntry, error) {
-	entry, err := t.Tree.TreeEntry(path)
+	entry, err := t.Tree.TreeEntry(path, lsTreeOptions)
 	if err != nil {
 		return nil, err
 	}
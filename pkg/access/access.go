@@ -15,6 +15,10 @@ const (
 	// ReadOnlyAccess allows read-only access to the repo.
 	ReadOnlyAccess
 
+	// TriageAccess allows read-only access to the repo, and managing its
+	// issues.
+	TriageAccess
+
 	// ReadWriteAccess allows read and write access to the repo.
 	ReadWriteAccess
 
@@ -29,6 +33,8 @@ func (a AccessLevel) String() string {
 		return "no-access"
 	case ReadOnlyAccess:
 		return "read-only"
+	case TriageAccess:
+		return "triage"
 	case ReadWriteAccess:
 		return "read-write"
 	case AdminAccess:
@@ -45,6 +51,8 @@ func ParseAccessLevel(s string) AccessLevel {
 		return NoAccess
 	case "read-only":
 		return ReadOnlyAccess
+	case "triage":
+		return TriageAccess
 	case "read-write":
 		return ReadWriteAccess
 	case "admin-access":
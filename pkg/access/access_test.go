@@ -11,6 +11,7 @@ func TestParseAccessLevel(t *testing.T) {
 		{"foo", -1},
 		{AdminAccess.String(), AdminAccess},
 		{ReadOnlyAccess.String(), ReadOnlyAccess},
+		{TriageAccess.String(), TriageAccess},
 		{ReadWriteAccess.String(), ReadWriteAccess},
 		{NoAccess.String(), NoAccess},
 	}
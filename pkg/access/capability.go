@@ -0,0 +1,37 @@
+package access
+
+// Capability is an operation on a repository that's allowed from a
+// configurable access level.
+type Capability string
+
+const (
+	// CapabilityManageIssues allows closing the issues opened by others.
+	CapabilityManageIssues Capability = "manage-issues"
+
+	// CapabilityPostStatuses allows posting commit statuses.
+	CapabilityPostStatuses Capability = "post-statuses"
+
+	// CapabilityManageReleases allows creating and deleting releases.
+	CapabilityManageReleases Capability = "manage-releases"
+
+	// CapabilityMerge allows merging branches on the server.
+	CapabilityMerge Capability = "merge"
+)
+
+// DefaultCapabilities are the access levels each capability requires by
+// default. Reading always requires ReadOnlyAccess, pushing ReadWriteAccess,
+// and managing the repository AdminAccess.
+var DefaultCapabilities = map[Capability]AccessLevel{
+	CapabilityManageIssues:   TriageAccess,
+	CapabilityPostStatuses:   ReadWriteAccess,
+	CapabilityManageReleases: ReadWriteAccess,
+	CapabilityMerge:          ReadWriteAccess,
+}
+
+// ParseCapability parses a capability string. It returns false if the
+// capability doesn't exist.
+func ParseCapability(s string) (Capability, bool) {
+	c := Capability(s)
+	_, ok := DefaultCapabilities[c]
+	return c, ok
+}
@@ -18,3 +18,32 @@ func FromContext(ctx context.Context) AccessLevel {
 func WithContext(ctx context.Context, ac AccessLevel) context.Context {
 	return context.WithValue(ctx, ContextKey, ac)
 }
+
+// MaxContextKey is the context key for the maximum access level, like the
+// one of the SSH listener a client connected to.
+var MaxContextKey = &struct{ string }{"max-access"}
+
+// MaxFromContext returns the maximum access level from the context, -1 if
+// there's no maximum.
+func MaxFromContext(ctx context.Context) AccessLevel {
+	if ac, ok := ctx.Value(MaxContextKey).(AccessLevel); ok {
+		return ac
+	}
+
+	return -1
+}
+
+// WithMax returns a new context with the maximum access level.
+func WithMax(ctx context.Context, ac AccessLevel) context.Context {
+	return context.WithValue(ctx, MaxContextKey, ac)
+}
+
+// Limit returns the access level limited to the maximum access level of the
+// context.
+func Limit(ctx context.Context, ac AccessLevel) AccessLevel {
+	if m := MaxFromContext(ctx); m >= 0 && ac > m {
+		return m
+	}
+
+	return ac
+}
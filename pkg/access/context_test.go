@@ -18,3 +18,18 @@ func TestBadFromContext(t *testing.T) {
 		t.Errorf("FromContext(ctx) => %d, want %d", ac, -1)
 	}
 }
+
+func TestLimit(t *testing.T) {
+	ctx := context.TODO()
+	if ac := Limit(ctx, AdminAccess); ac != AdminAccess {
+		t.Errorf("Limit(ctx, AdminAccess) => %d, want %d", ac, AdminAccess)
+	}
+
+	ctx = WithMax(ctx, ReadOnlyAccess)
+	if ac := Limit(ctx, AdminAccess); ac != ReadOnlyAccess {
+		t.Errorf("Limit(ctx, AdminAccess) => %d, want %d", ac, ReadOnlyAccess)
+	}
+	if ac := Limit(ctx, NoAccess); ac != NoAccess {
+		t.Errorf("Limit(ctx, NoAccess) => %d, want %d", ac, NoAccess)
+	}
+}
@@ -0,0 +1,41 @@
+package backend
+
+import (
+	"github.com/charmbracelet/soft-serve/pkg/access"
+	"github.com/charmbracelet/soft-serve/pkg/config"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var accessErrorCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "soft_serve",
+	Subsystem: "backend",
+	Name:      "access_errors_total",
+	Help:      "The total number of access checks that failed because of a backend error, by the policy applied",
+}, []string{"policy"})
+
+// onBackendError returns the configured behavior of access checks on backend
+// errors.
+func (d *Backend) onBackendError() string {
+	if d.cfg != nil && d.cfg.Access.OnBackendError == config.FailOpenRead {
+		return config.FailOpenRead
+	}
+
+	return config.FailClosed
+}
+
+// accessLevelOnError returns the access level to use when an access check
+// failed because of a backend error, following the configured policy. The
+// error is logged apart from regular denials, since the access level of the
+// user is unknown.
+func (d *Backend) accessLevelOnError(repo string, err error) access.AccessLevel {
+	policy := d.onBackendError()
+	accessErrorCounter.WithLabelValues(policy).Inc()
+	d.logger.Error("backend error during access check, applying policy", "repo", repo, "policy", policy, "err", err)
+
+	if policy == config.FailOpenRead {
+		return access.ReadOnlyAccess
+	}
+
+	return access.NoAccess
+}
@@ -0,0 +1,51 @@
+package backend
+
+import (
+	"context"
+	"testing"
+
+	"github.com/charmbracelet/soft-serve/pkg/access"
+	"github.com/charmbracelet/soft-serve/pkg/config"
+	"github.com/charmbracelet/soft-serve/pkg/sshutils"
+)
+
+func TestAccessLevelOnBackendError(t *testing.T) {
+	pk, _, err := sshutils.ParseAuthorizedKey("ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAINMwLvyV3ouVrTysUYGoJdl5Vgn5BACKov+n9PlzfPwH")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, c := range []struct {
+		policy string
+		want   access.AccessLevel
+		denied bool
+	}{
+		{"", access.NoAccess, true},
+		{config.FailClosed, access.NoAccess, true},
+		{config.FailOpenRead, access.ReadOnlyAccess, false},
+	} {
+		t.Run(c.policy, func(t *testing.T) {
+			d := newRetryBackend(t, 0, 0)
+			d.cfg.Access.OnBackendError = c.policy
+
+			// Every query fails once the database is closed.
+			if err := d.db.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			ctx := context.Background()
+			if got := d.AccessLevelByPublicKey(ctx, "repo1", pk); got != c.want {
+				t.Errorf("expected public key access %s, got %s", c.want, got)
+			}
+			if got := d.AccessLevelForUser(ctx, "repo1", nil); got != c.want {
+				t.Errorf("expected anonymous access %s, got %s", c.want, got)
+			}
+			if got := d.AccessLevel(ctx, "repo1", "user1"); got != c.want {
+				t.Errorf("expected user access %s, got %s", c.want, got)
+			}
+			if got := d.KeyDenied(ctx, pk); got != c.denied {
+				t.Errorf("expected key denied %t, got %t", c.denied, got)
+			}
+		})
+	}
+}
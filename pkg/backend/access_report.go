@@ -0,0 +1,197 @@
+package backend
+
+import (
+	"context"
+	"sort"
+
+	"github.com/charmbracelet/soft-serve/pkg/access"
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/db/models"
+	gossh "golang.org/x/crypto/ssh"
+)
+
+// Identity types of an access report.
+const (
+	AccessReportAnonymous = "anonymous"
+	AccessReportUser      = "user"
+	AccessReportKey       = "key"
+)
+
+// Sources of the repository grants of an access report.
+const (
+	AccessGrantOwner        = "owner"
+	AccessGrantCollaborator = "collaborator"
+	AccessGrantKey          = "key"
+)
+
+// AccessReportEntry is the access of an identity, a user, an unregistered
+// public key, or anonymous users, to the server.
+type AccessReportEntry struct {
+	// Type is the type of the identity.
+	Type string `json:"type"`
+	// Name is the username of users, and the SHA256 fingerprint of keys.
+	Name string `json:"name"`
+	// Admin is whether the identity is an admin.
+	Admin bool `json:"admin"`
+	// Access is the access level to repositories the identity has no grant
+	// on.
+	Access access.AccessLevel `json:"access"`
+	// Keys are the SHA256 fingerprints of the public keys of users.
+	Keys []string `json:"keys,omitempty"`
+	// Grants are the repositories the identity was granted access to.
+	Grants []AccessReportGrant `json:"grants"`
+}
+
+// AccessReportGrant is the access granted to an identity on a repository.
+type AccessReportGrant struct {
+	// Repo is the name of the repository.
+	Repo string `json:"repo"`
+	// Access is the granted access level.
+	Access access.AccessLevel `json:"access"`
+	// Source is how the access was granted.
+	Source string `json:"source"`
+	// Key is the fingerprint of the key granted access, for key grants.
+	Key string `json:"key,omitempty"`
+}
+
+// AccessReport calls fn with the access of anonymous users, of each user,
+// and of each unregistered key granted access to a repository, in that
+// order. The entries are read one identity at a time so that reports of
+// large servers can be written as they are read. It stops at the first
+// error fn returns.
+func (d *Backend) AccessReport(ctx context.Context, fn func(AccessReportEntry) error) error {
+	anon, err := d.anonAccess(ctx)
+	if err != nil {
+		return db.WrapError(err)
+	}
+
+	locked, err := d.lockdown(ctx)
+	if err != nil {
+		return db.WrapError(err)
+	}
+
+	// Without a grant, users can read public repositories, unless the
+	// server is in lockdown or in strict mode.
+	userAccess := access.ReadOnlyAccess
+	if locked || d.cfg.Access.Strict {
+		anon, userAccess = access.NoAccess, access.NoAccess
+	}
+
+	var users []models.User
+	var repos []models.Repo
+	var keys []models.RepoKey
+	if err := d.retryTx(ctx, "access_report", func(tx *db.Tx) error {
+		var err error
+		if users, err = d.store.GetAllUsers(ctx, tx); err != nil {
+			return err
+		}
+		if repos, err = d.store.GetAllRepos(ctx, tx); err != nil {
+			return err
+		}
+		keys, err = d.store.GetAllRepoKeys(ctx, tx)
+		return err
+	}); err != nil {
+		return db.WrapError(err)
+	}
+
+	sort.Slice(users, func(i, j int) bool { return users[i].Username < users[j].Username })
+
+	repoNames := make(map[int64]string, len(repos))
+	owned := map[int64][]string{}
+	for _, r := range repos {
+		repoNames[r.ID] = r.Name
+		if r.UserID.Valid {
+			owned[r.UserID.Int64] = append(owned[r.UserID.Int64], r.Name)
+		}
+	}
+
+	keyGrants := map[string][]AccessReportGrant{}
+	for _, k := range keys {
+		keyGrants[k.Fingerprint] = append(keyGrants[k.Fingerprint], AccessReportGrant{
+			Repo:   repoNames[k.RepoID],
+			Access: k.AccessLevel,
+			Source: AccessGrantKey,
+			Key:    k.Fingerprint,
+		})
+	}
+
+	if err := fn(AccessReportEntry{
+		Type:   AccessReportAnonymous,
+		Name:   AccessReportAnonymous,
+		Access: anon,
+		Grants: []AccessReportGrant{},
+	}); err != nil {
+		return err
+	}
+
+	registered := map[string]bool{}
+	for _, u := range users {
+		var pks []gossh.PublicKey
+		var collabs []models.Collab
+		if err := d.retryTx(ctx, "access_report_user", func(tx *db.Tx) error {
+			var err error
+			if pks, err = d.store.ListPublicKeysByUserID(ctx, tx, u.ID); err != nil {
+				return err
+			}
+			collabs, err = d.store.ListCollabsByUserID(ctx, tx, u.ID)
+			return err
+		}); err != nil {
+			return db.WrapError(err)
+		}
+
+		e := AccessReportEntry{
+			Type:   AccessReportUser,
+			Name:   u.Username,
+			Admin:  u.Admin,
+			Access: userAccess,
+			Grants: []AccessReportGrant{},
+		}
+		if u.Admin {
+			e.Access = access.AdminAccess
+		}
+
+		for _, name := range owned[u.ID] {
+			e.Grants = append(e.Grants, AccessReportGrant{
+				Repo:   name,
+				Access: d.cfg.Access.CreatorAccessLevel(),
+				Source: AccessGrantOwner,
+			})
+		}
+		for _, c := range collabs {
+			e.Grants = append(e.Grants, AccessReportGrant{
+				Repo:   repoNames[c.RepoID],
+				Access: c.AccessLevel,
+				Source: AccessGrantCollaborator,
+			})
+		}
+		for _, pk := range pks {
+			fp := gossh.FingerprintSHA256(pk)
+			registered[fp] = true
+			e.Keys = append(e.Keys, fp)
+			e.Grants = append(e.Grants, keyGrants[fp]...)
+		}
+
+		if err := fn(e); err != nil {
+			return err
+		}
+	}
+
+	// Keys are ordered by fingerprint.
+	for _, k := range keys {
+		if registered[k.Fingerprint] {
+			continue
+		}
+		registered[k.Fingerprint] = true
+
+		if err := fn(AccessReportEntry{
+			Type:   AccessReportKey,
+			Name:   k.Fingerprint,
+			Access: anon,
+			Grants: keyGrants[k.Fingerprint],
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,99 @@
+package backend
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/soft-serve/git"
+	"github.com/charmbracelet/soft-serve/pkg/proto"
+)
+
+// alternatesPath returns the path of the alternates file of a repository.
+func alternatesPath(rp string) string {
+	return filepath.Join(rp, "objects", "info", "alternates")
+}
+
+// readAlternates returns the object stores listed in the alternates file of
+// a repository. Relative paths are resolved against the objects directory of
+// the repository, like git does.
+func readAlternates(rp string) ([]string, error) {
+	f, err := os.Open(alternatesPath(rp))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close() // nolint: errcheck
+
+	var alts []string
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		// Paths with special characters are C-quoted.
+		if strings.HasPrefix(line, `"`) {
+			if uq, err := strconv.Unquote(line); err == nil {
+				line = uq
+			}
+		}
+
+		if !filepath.IsAbs(line) {
+			line = filepath.Join(rp, "objects", line)
+		}
+
+		alts = append(alts, filepath.Clean(line))
+	}
+
+	return alts, s.Err()
+}
+
+// checkAlternates validates the alternate object stores of an imported
+// repository. Local clones copy the alternates of the source repository,
+// which might not resolve on this server. A repository with alternates that
+// don't exist, or that miss objects, is rejected. Valid alternates are kept,
+// unless dissociate is set, in which case their objects are copied into the
+// repository and the alternates are removed.
+func (d *Backend) checkAlternates(ctx context.Context, rp string, dissociate bool) error {
+	alts, err := readAlternates(rp)
+	if err != nil || len(alts) == 0 {
+		return err
+	}
+
+	var broken []string
+	for _, alt := range alts {
+		if fi, err := os.Stat(alt); err != nil || !fi.IsDir() {
+			broken = append(broken, alt)
+		}
+	}
+
+	if len(broken) > 0 {
+		return fmt.Errorf("%w: %s doesn't exist", proto.ErrBrokenAlternates, strings.Join(broken, ", "))
+	}
+
+	// The alternates exist, make sure they have every object still.
+	if _, err := git.NewCommand("fsck", "--connectivity-only", "--no-dangling", "--no-progress").WithContext(ctx).RunInDir(rp); err != nil {
+		return fmt.Errorf("%w: missing objects: %v", proto.ErrBrokenAlternates, err)
+	}
+
+	if !dissociate {
+		d.logger.Warn("imported repository borrows objects from alternates", "path", rp, "alternates", alts)
+		return nil
+	}
+
+	d.logger.Info("dissociating imported repository from alternates", "path", rp, "alternates", alts)
+	if _, err := git.NewCommand("repack", "-a", "-d", "-q").WithContext(ctx).RunInDir(rp); err != nil {
+		return fmt.Errorf("copy alternate objects: %w", err)
+	}
+
+	return os.Remove(alternatesPath(rp))
+}
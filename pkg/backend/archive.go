@@ -0,0 +1,80 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/charmbracelet/soft-serve/git"
+	"github.com/charmbracelet/soft-serve/pkg/proto"
+)
+
+// TreeArchive is a tar archive of a tree of a repository, or of one of its
+// paths.
+type TreeArchive struct {
+	// Commit is the commit the tree is taken from.
+	Commit string
+	// Path is the path of the tree in the commit, empty for the whole tree.
+	Path string
+
+	dir string
+}
+
+// TreeArchive resolves the tar archive of a path of a revision. The files in
+// the archive keep their path in the repository. It returns
+// proto.ErrCommitNotFound if the revision doesn't exist and
+// proto.ErrFileNotFound if the path doesn't exist in it. Repositories with a
+// denied path in their history can't be archived.
+func (d *Backend) TreeArchive(ctx context.Context, repo string, rev string, subpath string) (TreeArchive, error) {
+	var a TreeArchive
+	if strings.ContainsAny(subpath, "\n\r") {
+		return a, proto.ErrFileNotFound
+	}
+	if subpath = strings.Trim(path.Clean("/"+subpath), "/"); subpath == "." {
+		subpath = ""
+	}
+
+	r, err := d.Repository(ctx, repo)
+	if err != nil {
+		return a, err
+	}
+
+	if err := d.CheckDeniedPaths(ctx, r.Name()); err != nil {
+		return a, err
+	}
+
+	rr, err := r.Open()
+	if err != nil {
+		return a, err
+	}
+
+	commit, err := resolveCommit(ctx, rr.Path, rev)
+	if err != nil {
+		return a, err
+	}
+
+	if subpath != "" {
+		if _, err := git.NewCommand("cat-file", "-e", commit+":"+subpath).WithContext(ctx).RunInDir(rr.Path); err != nil {
+			return a, fmt.Errorf("%w: %s", proto.ErrFileNotFound, subpath)
+		}
+	}
+
+	return TreeArchive{Commit: commit, Path: subpath, dir: rr.Path}, nil
+}
+
+// Stream writes the tar archive to w.
+func (a TreeArchive) Stream(ctx context.Context, w io.Writer) error {
+	args := []string{"archive", "--format=tar", a.Commit}
+	if a.Path != "" {
+		args = append(args, "--", a.Path)
+	}
+
+	// Archives of large trees can take a while, they're bound by the
+	// request context instead.
+	return git.NewCommand(args...).
+		WithContext(ctx).
+		WithTimeout(-1).
+		RunInDirWithOptions(a.dir, git.RunInDirOptions{Stdout: w})
+}
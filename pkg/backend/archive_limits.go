@@ -0,0 +1,80 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/soft-serve/pkg/proto"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	uploadArchiveActiveGauge = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: "soft_serve",
+		Subsystem: "git",
+		Name:      "upload_archive_active",
+		Help:      "The number of running git-upload-archive operations",
+	})
+
+	uploadArchiveLimitedCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "soft_serve",
+		Subsystem: "git",
+		Name:      "upload_archive_limited_total",
+		Help:      "The total number of git-upload-archive operations rejected by the archive limits",
+	}, []string{"limit"})
+)
+
+// archiveLimiter limits the concurrent git-upload-archive operations, of
+// each client IP address and on the whole server.
+type archiveLimiter struct {
+	perIP cloneLimiter
+	// global holds the slots of the server under a single key.
+	global cloneLimiter
+}
+
+// AcquireUploadArchive takes a git-upload-archive slot of the client at
+// addr, a host:port or an IP address. Operations over a limit wait up to the
+// queue timeout for a slot. The returned function releases the slot and must
+// be called once the operation is over. It returns proto.ErrArchiveLimit if
+// the client, or the server, is over its limit.
+func (d *Backend) AcquireUploadArchive(ctx context.Context, addr string) (func(), error) {
+	ip := addrIP(addr)
+	cfg := d.cfg.ArchiveLimits
+	timeout := time.Duration(cfg.QueueTimeout) * time.Second
+	start := time.Now()
+
+	// Take the slot of the address first, so a single client can't queue up
+	// for all the server slots.
+	releaseIP, ok := d.archiveLimits.perIP.acquire(ctx, ip, cfg.PerIP, timeout)
+	if !ok {
+		uploadArchiveLimitedCounter.WithLabelValues("per_ip").Inc()
+		d.logger.Info("archive limit reached", "ip", ip, "limit", cfg.PerIP)
+		return nil, fmt.Errorf("%w from your address, at most %d at a time, try again later", proto.ErrArchiveLimit, cfg.PerIP)
+	}
+
+	// The queue timeout covers the wait for both slots.
+	remaining := timeout - time.Since(start)
+	if timeout > 0 && remaining <= 0 {
+		remaining = time.Nanosecond
+	}
+	releaseGlobal, ok := d.archiveLimits.global.acquire(ctx, "", cfg.MaxConcurrent, remaining)
+	if !ok {
+		releaseIP()
+		uploadArchiveLimitedCounter.WithLabelValues("global").Inc()
+		d.logger.Info("server archive limit reached", "ip", ip, "limit", cfg.MaxConcurrent)
+		return nil, fmt.Errorf("%w on the server, try again later", proto.ErrArchiveLimit)
+	}
+
+	uploadArchiveActiveGauge.Inc()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			uploadArchiveActiveGauge.Dec()
+			releaseGlobal()
+			releaseIP()
+		})
+	}, nil
+}
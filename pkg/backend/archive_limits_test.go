@@ -0,0 +1,59 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/charmbracelet/log"
+	"github.com/charmbracelet/soft-serve/pkg/config"
+	"github.com/charmbracelet/soft-serve/pkg/proto"
+)
+
+func TestAcquireUploadArchive(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.ArchiveLimits.MaxConcurrent = 3
+	cfg.ArchiveLimits.PerIP = 2
+	d := &Backend{cfg: cfg, logger: log.New(nil)}
+	ctx := context.Background()
+
+	var releases []func()
+	for i := 0; i < 2; i++ {
+		release, err := d.AcquireUploadArchive(ctx, "192.0.2.1:1234")
+		if err != nil {
+			t.Fatalf("archive %d should be allowed: %v", i+1, err)
+		}
+		releases = append(releases, release)
+	}
+
+	// Over the limit of the address.
+	if _, err := d.AcquireUploadArchive(ctx, "192.0.2.1:1235"); !errors.Is(err, proto.ErrArchiveLimit) {
+		t.Fatalf("expected the per-IP limit, got %v", err)
+	}
+
+	// Over the limit of the server.
+	release, err := d.AcquireUploadArchive(ctx, "192.0.2.2:1234")
+	if err != nil {
+		t.Fatalf("archive from another address should be allowed: %v", err)
+	}
+	releases = append(releases, release)
+	if _, err := d.AcquireUploadArchive(ctx, "192.0.2.3:1234"); !errors.Is(err, proto.ErrArchiveLimit) {
+		t.Fatalf("expected the server limit, got %v", err)
+	}
+
+	// A rejected archive doesn't hold the slot of its address.
+	releases[2]()
+	releases[2]()
+	release, err = d.AcquireUploadArchive(ctx, "192.0.2.3:1234")
+	if err != nil {
+		t.Fatalf("archive should be allowed once a slot is free: %v", err)
+	}
+	release()
+
+	for _, release := range releases {
+		release()
+	}
+	if n := len(d.archiveLimits.perIP.ips) + len(d.archiveLimits.global.ips); n != 0 {
+		t.Fatalf("expected every slot to be released, %d addresses left", n)
+	}
+}
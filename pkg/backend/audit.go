@@ -0,0 +1,190 @@
+package backend
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/db/models"
+	logr "github.com/charmbracelet/soft-serve/pkg/log"
+	"github.com/charmbracelet/soft-serve/pkg/proto"
+)
+
+// Audit log actions.
+const (
+	// AuditActionPushCertificate is a verified signed push. The details are
+	// the push certificate.
+	AuditActionPushCertificate = "push_certificate"
+	// AuditActionLockdownEnabled is the server entering lockdown.
+	AuditActionLockdownEnabled = "lockdown_enabled"
+	// AuditActionLockdownLifted is the server lifting the lockdown.
+	AuditActionLockdownLifted = "lockdown_lifted"
+	// AuditActionKeyRevoked is a revoked public key. The details are the key
+	// fingerprint.
+	AuditActionKeyRevoked = "key_revoked"
+	// AuditActionKeyRestored is a restored public key. The details are the
+	// key fingerprint.
+	AuditActionKeyRestored = "key_restored"
+	// AuditActionSessionTerminated is an SSH session terminated because of a
+	// lockdown or a revoked key. The details are the key fingerprint, the
+	// user, and the remote address.
+	AuditActionSessionTerminated = "session_terminated"
+	// AuditActionBranchPruned is a merged branch deleted by pruning. The
+	// details are the branch, its commit, and the base branch.
+	AuditActionBranchPruned = "branch_pruned"
+	// AuditActionTermsSet is a change of the server terms. The details are
+	// the new terms version, empty if the terms were removed.
+	AuditActionTermsSet = "terms_set"
+	// AuditActionProtectedTag is an attempt to move or delete a protected
+	// tag. The details are the tag ref, its old and new commits, and whether
+	// the attempt was rejected, warned about, or made by an admin.
+	AuditActionProtectedTag = "protected_tag"
+	// AuditActionInviteCreated is a new invite. The details are the invite
+	// ID, the invited username, and whether the invite is for an admin.
+	AuditActionInviteCreated = "invite_created"
+	// AuditActionInviteRevoked is a revoked invite. The details are the
+	// invite ID and the invited username.
+	AuditActionInviteRevoked = "invite_revoked"
+	// AuditActionInviteUsed is a public key registered with an invite. The
+	// details are the invite ID and the key fingerprint.
+	AuditActionInviteUsed = "invite_used"
+	// AuditActionRepoKeyGranted is a public key granted access to a
+	// repository. The details are the key fingerprint and the access level.
+	AuditActionRepoKeyGranted = "repo_key_granted"
+	// AuditActionRepoKeyRemoved is a public key losing its access to a
+	// repository. The details are the key fingerprint.
+	AuditActionRepoKeyRemoved = "repo_key_removed"
+	// AuditActionDeployTokenCreated is a deploy token created for a
+	// repository. The details are the token name, its access level, and the
+	// fingerprint of the key it's mapped to, if any.
+	AuditActionDeployTokenCreated = "deploy_token_created"
+	// AuditActionDeployTokenRevoked is a deploy token revoked. The details
+	// are the token name.
+	AuditActionDeployTokenRevoked = "deploy_token_revoked"
+	// AuditActionReleaseCreated is a release created for a tag. The details
+	// are the tag.
+	AuditActionReleaseCreated = "release_created"
+	// AuditActionReleaseDeleted is a deleted release. The details are the
+	// tag.
+	AuditActionReleaseDeleted = "release_deleted"
+	// AuditActionReleaseAssetUploaded is an asset uploaded to a release. The
+	// details are the tag, the asset name, and its SHA-256 digest.
+	AuditActionReleaseAssetUploaded = "release_asset_uploaded"
+	// AuditActionReleaseAssetDeleted is a deleted release asset. The details
+	// are the tag and the asset name.
+	AuditActionReleaseAssetDeleted = "release_asset_deleted"
+	// AuditActionDefaultBranchRenamed is a renamed default branch. The
+	// details are the old and new branches, the commit, and whether the old
+	// branch was deleted.
+	AuditActionDefaultBranchRenamed = "default_branch_renamed"
+	// AuditActionIssueCreated is an issue opened in a repository. The details
+	// are the issue number.
+	AuditActionIssueCreated = "issue_created"
+	// AuditActionIssueClosed is a closed issue. The details are the issue
+	// number.
+	AuditActionIssueClosed = "issue_closed"
+	// AuditActionReviewProposed is a change pushed for review. The details
+	// are the review number, the target branch, and the commit.
+	AuditActionReviewProposed = "review_proposed"
+	// AuditActionMerged is a branch merged into another one on the server.
+	// The details are the source, the target branch, and the new commit.
+	AuditActionMerged = "merged"
+	// AuditActionForcePushed is a ref update that isn't a fast-forward. The
+	// details are the ref, the old, and the new commit.
+	AuditActionForcePushed = "force_pushed"
+)
+
+// recordAuditEvent records an event in the audit log, and sends it to syslog
+// if configured. The repository and the user are optional, the user defaults
+// to the user of the context.
+func (d *Backend) recordAuditEvent(ctx context.Context, action string, repo proto.Repository, user proto.User, details string) error {
+	var repoID, userID int64
+	var repoName, username string
+	if repo != nil {
+		repoID = repo.ID()
+		repoName = repo.Name()
+	}
+	if user == nil {
+		user = proto.UserFromContext(ctx)
+	}
+	if user != nil {
+		userID = user.ID()
+		username = user.Username()
+	}
+
+	d.syslog.Log(logr.SeverityNotice, "audit", action,
+		"repo", repoName,
+		"username", username,
+		"details", details,
+	)
+
+	event := models.AuditEvent{
+		Action:    action,
+		RepoID:    sql.NullInt64{Int64: repoID},
+		UserID:    sql.NullInt64{Int64: userID},
+		Details:   details,
+		Actor:     username,
+		Target:    repoName,
+		CreatedAt: time.Now().UTC().Truncate(time.Second),
+	}
+
+	// Events are chained in the order they're committed. The chain is locked
+	// in the database since hooks and admin commands record events from
+	// other processes.
+	d.auditMu.Lock()
+	defer d.auditMu.Unlock()
+	return db.WrapError(d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		if err := d.store.LockAuditChain(ctx, tx); err != nil {
+			return err
+		}
+
+		prev, err := d.lastAuditHash(ctx, tx)
+		if err != nil {
+			return err
+		}
+
+		event.PrevHash = prev
+		event.Hash = auditChainHash(prev, event.CreatedAt, action, username, repoName, details)
+		return d.store.CreateAuditEvent(ctx, tx, event)
+	}))
+}
+
+// RecordSessionTerminated records a terminated SSH session in the audit log.
+func (d *Backend) RecordSessionTerminated(ctx context.Context, user proto.User, details string) error {
+	return d.recordAuditEvent(ctx, AuditActionSessionTerminated, nil, user, details)
+}
+
+// AuditEvents returns up to limit latest audit events of a repository with
+// the given action, newest first.
+func (d *Backend) AuditEvents(ctx context.Context, repo string, action string, limit int) ([]models.AuditEvent, error) {
+	r, err := d.Repository(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	var events []models.AuditEvent
+	if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		events, err = d.store.GetAuditEventsByRepoID(ctx, tx, r.ID(), action, limit)
+		return err
+	}); err != nil {
+		return nil, db.WrapError(err)
+	}
+
+	return events, nil
+}
+
+// RecentAuditEvents returns up to limit latest audit events of the server,
+// newest first.
+func (d *Backend) RecentAuditEvents(ctx context.Context, limit int) ([]models.AuditEvent, error) {
+	var events []models.AuditEvent
+	if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		var err error
+		events, err = d.store.GetAuditEvents(ctx, tx, limit)
+		return err
+	}); err != nil {
+		return nil, db.WrapError(err)
+	}
+
+	return events, nil
+}
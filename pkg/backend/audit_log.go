@@ -0,0 +1,578 @@
+package backend
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/db/models"
+)
+
+// AuditRecord is an event of the audit log, either in the database or in a
+// rotated segment.
+type AuditRecord struct {
+	ID      int64  `json:"id"`
+	Action  string `json:"action"`
+	Actor   string `json:"actor,omitempty"`
+	Target  string `json:"target,omitempty"`
+	Details string `json:"details,omitempty"`
+	// CreatedAt is when the event was recorded, in UTC.
+	CreatedAt time.Time `json:"created_at"`
+	// PrevHash is the hash of the previous record.
+	PrevHash string `json:"prev_hash,omitempty"`
+	// Hash chains the record to the previous one. It's empty for events
+	// recorded before the audit log was chained.
+	Hash string `json:"hash,omitempty"`
+	// Segment is the name of the segment of a rotated record.
+	Segment string `json:"segment,omitempty"`
+}
+
+func auditRecordFromEvent(e models.AuditEvent) AuditRecord {
+	r := AuditRecord{
+		ID:        e.ID,
+		Action:    e.Action,
+		Actor:     e.Actor,
+		Target:    e.Target,
+		Details:   e.Details,
+		CreatedAt: e.CreatedAt.UTC(),
+		PrevHash:  e.PrevHash,
+		Hash:      e.Hash,
+	}
+	// Events recorded before the audit log was chained only have the
+	// current names of their user and repository.
+	if r.Hash == "" {
+		r.Actor = e.Username.String
+		r.Target = e.RepoName.String
+	}
+
+	return r
+}
+
+// chainHash returns the hash of the record contents and the previous hash.
+func (r AuditRecord) chainHash() string {
+	return auditChainHash(r.PrevHash, r.CreatedAt, r.Action, r.Actor, r.Target, r.Details)
+}
+
+// auditChainHash returns the hash chaining an audit event to the previous
+// one.
+func auditChainHash(prev string, createdAt time.Time, action, actor, target, details string) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\n%s\n%s\n%s\n%s\n%s", prev, createdAt.UTC().Format(time.DateTime),
+		action, actor, target, details)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// lastAuditHash returns the hash of the latest chained audit event, in the
+// database or in the rotated segments.
+func (d *Backend) lastAuditHash(ctx context.Context, tx *db.Tx) (string, error) {
+	hash, err := d.store.GetLastAuditEventHash(ctx, tx)
+	if err != nil || hash != "" {
+		return hash, err
+	}
+
+	segments, err := d.store.GetAuditSegments(ctx, tx)
+	if err != nil {
+		return "", err
+	}
+	for i := len(segments) - 1; i >= 0; i-- {
+		if segments[i].Hash != "" {
+			return segments[i].Hash, nil
+		}
+	}
+
+	return "", nil
+}
+
+// AuditRotation is the result of rotating the audit log.
+type AuditRotation struct {
+	// Events is the number of events rotated out of the database.
+	Events int64
+	// Segment is the path of the new segment, empty if no events were
+	// rotated.
+	Segment string
+	// Shipped is the number of segments shipped to external storage.
+	Shipped int
+	// Removed is the number of segments removed past their retention.
+	Removed int
+}
+
+// RotateAuditLog moves the audit events past their retention, or over the
+// maximum number of events, from the database to a new segment. It then
+// ships the segments not shipped yet, and removes the ones past their
+// retention. Shipping failures are logged and retried on the next rotation.
+func (d *Backend) RotateAuditLog(ctx context.Context) (AuditRotation, error) {
+	var rot AuditRotation
+	name, n, err := d.rotateAuditEvents(ctx)
+	if err != nil {
+		return rot, err
+	}
+
+	rot.Events = n
+	if name != "" {
+		rot.Segment = filepath.Join(d.cfg.Audit.SegmentsPath, name)
+	}
+
+	rot.Shipped, err = d.shipAuditSegments(ctx)
+	if err != nil {
+		return rot, err
+	}
+
+	rot.Removed, err = d.removeAuditSegments(ctx)
+	return rot, err
+}
+
+// rotateAuditEvents writes the audit events to rotate to a segment, and
+// deletes them from the database in the same transaction. Recording events
+// waits for the rotation, in other processes too since both lock the audit
+// chain, so no event is lost.
+func (d *Backend) rotateAuditEvents(ctx context.Context) (string, int64, error) {
+	d.auditMu.Lock()
+	defer d.auditMu.Unlock()
+
+	var name string
+	var n int64
+	now := time.Now()
+	if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		if err := d.store.LockAuditChain(ctx, tx); err != nil {
+			return err
+		}
+
+		var cutoff int64
+		if days := d.cfg.DB.Retention.AuditEvents; days > 0 {
+			id, err := d.store.GetLastAuditEventIDBefore(ctx, tx, now.AddDate(0, 0, -days))
+			if err != nil {
+				return err
+			}
+			cutoff = id
+		}
+
+		if keep := d.cfg.Audit.MaxEvents; keep > 0 {
+			id, err := d.store.GetLastAuditEventIDBeyond(ctx, tx, keep)
+			if err != nil {
+				return err
+			}
+			cutoff = max(cutoff, id)
+		}
+
+		if cutoff == 0 {
+			return nil
+		}
+
+		events, err := d.store.GetAuditEventsUpTo(ctx, tx, cutoff)
+		if err != nil || len(events) == 0 {
+			return err
+		}
+
+		seg, err := d.writeAuditSegment(events)
+		if err != nil {
+			return fmt.Errorf("write audit log segment: %w", err)
+		}
+
+		name = seg.Name
+		if err := d.store.CreateAuditSegment(ctx, tx, seg); err != nil {
+			return err
+		}
+
+		n, err = d.store.DeleteAuditEventsUpTo(ctx, tx, cutoff)
+		if err != nil {
+			return err
+		}
+
+		if n != int64(len(events)) {
+			return fmt.Errorf("audit events were recorded while rotating them, rotate again")
+		}
+
+		return nil
+	}); err != nil {
+		if name != "" {
+			os.Remove(filepath.Join(d.cfg.Audit.SegmentsPath, name)) // nolint: errcheck
+		}
+		return "", 0, db.WrapError(err)
+	}
+
+	if n > 0 {
+		d.logger.Info("rotated audit log", "segment", name, "events", n)
+	}
+
+	return name, n, nil
+}
+
+// writeAuditSegment writes events to a new gzipped JSON lines segment file
+// and returns the segment.
+func (d *Backend) writeAuditSegment(events []models.AuditEvent) (models.AuditSegment, error) {
+	first, last := events[0], events[len(events)-1]
+	seg := models.AuditSegment{
+		Name:     fmt.Sprintf("audit-%010d-%010d.jsonl.gz", first.ID, last.ID),
+		FirstID:  first.ID,
+		LastID:   last.ID,
+		FirstAt:  first.CreatedAt,
+		LastAt:   last.CreatedAt,
+		Events:   int64(len(events)),
+		PrevHash: first.PrevHash,
+	}
+
+	dir := d.cfg.Audit.SegmentsPath
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return seg, err
+	}
+
+	f, err := os.CreateTemp(dir, seg.Name+".*.tmp")
+	if err != nil {
+		return seg, err
+	}
+	defer os.Remove(f.Name()) // nolint: errcheck
+	defer f.Close()           // nolint: errcheck
+
+	sum := sha256.New()
+	zw := gzip.NewWriter(io.MultiWriter(f, sum))
+	enc := json.NewEncoder(zw)
+	for _, e := range events {
+		if e.CreatedAt.Before(seg.FirstAt) {
+			seg.FirstAt = e.CreatedAt
+		}
+		if e.CreatedAt.After(seg.LastAt) {
+			seg.LastAt = e.CreatedAt
+		}
+		if e.Hash != "" {
+			seg.Hash = e.Hash
+		}
+		if err := enc.Encode(auditRecordFromEvent(e)); err != nil {
+			return seg, err
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return seg, err
+	}
+	if err := f.Sync(); err != nil {
+		return seg, err
+	}
+	if err := f.Close(); err != nil {
+		return seg, err
+	}
+	if err := os.Rename(f.Name(), filepath.Join(dir, seg.Name)); err != nil {
+		return seg, err
+	}
+
+	seg.Checksum = hex.EncodeToString(sum.Sum(nil))
+	return seg, nil
+}
+
+// readAuditSegment returns the records of a segment and the checksum of its
+// file.
+func (d *Backend) readAuditSegment(seg models.AuditSegment) ([]AuditRecord, string, error) {
+	f, err := os.Open(filepath.Join(d.cfg.Audit.SegmentsPath, seg.Name))
+	if err != nil {
+		return nil, "", err
+	}
+	defer f.Close() // nolint: errcheck
+
+	sum := sha256.New()
+	r := io.TeeReader(f, sum)
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var records []AuditRecord
+	dec := json.NewDecoder(zr)
+	for {
+		var rec AuditRecord
+		if err := dec.Decode(&rec); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, "", err
+		}
+		rec.Segment = seg.Name
+		records = append(records, rec)
+	}
+
+	if _, err := io.Copy(io.Discard, r); err != nil {
+		return nil, "", err
+	}
+
+	return records, hex.EncodeToString(sum.Sum(nil)), nil
+}
+
+// shipAuditSegments runs the ship command on the segments not shipped yet,
+// and returns the number of shipped segments.
+func (d *Backend) shipAuditSegments(ctx context.Context) (int, error) {
+	if d.cfg.Audit.ShipCommand == "" {
+		return 0, nil
+	}
+
+	var segments []models.AuditSegment
+	if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		var err error
+		segments, err = d.store.GetAuditSegments(ctx, tx)
+		return err
+	}); err != nil {
+		return 0, db.WrapError(err)
+	}
+
+	var shipped int
+	for _, seg := range segments {
+		if seg.ShippedAt.Valid {
+			continue
+		}
+
+		if err := d.runAuditShipCommand(ctx, filepath.Join(d.cfg.Audit.SegmentsPath, seg.Name)); err != nil {
+			d.logger.Error("error shipping audit log segment", "segment", seg.Name, "err", err)
+			continue
+		}
+
+		if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+			return d.store.SetAuditSegmentShipped(ctx, tx, seg.ID)
+		}); err != nil {
+			return shipped, db.WrapError(err)
+		}
+		shipped++
+	}
+
+	return shipped, nil
+}
+
+// runAuditShipCommand runs the ship command with the path of a segment.
+func (d *Backend) runAuditShipCommand(ctx context.Context, path string) error {
+	if timeout := time.Duration(d.cfg.Timeouts.Hook) * time.Second; timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	var out bytes.Buffer
+	cmd := exec.CommandContext(ctx, d.cfg.Audit.ShipCommand, path)
+	cmd.Env = append(os.Environ(), d.cfg.Environ()...)
+	cmd.Env = append(cmd.Env, "SOFT_SERVE_AUDIT_SEGMENT="+path)
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	cmd.WaitDelay = time.Second
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(out.String()); msg != "" {
+			return fmt.Errorf("%w: %s", err, msg)
+		}
+		return err
+	}
+
+	return nil
+}
+
+// removeAuditSegments removes the segments past their retention, once
+// shipped if there is a ship command, and returns the number of removed
+// segments.
+func (d *Backend) removeAuditSegments(ctx context.Context) (int, error) {
+	days := d.cfg.Audit.SegmentRetention
+	if days <= 0 {
+		return 0, nil
+	}
+
+	before := time.Now().AddDate(0, 0, -days)
+	var removed int
+	if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		segments, err := d.store.GetAuditSegments(ctx, tx)
+		if err != nil {
+			return err
+		}
+
+		for _, seg := range segments {
+			if !seg.LastAt.Before(before) || (d.cfg.Audit.ShipCommand != "" && !seg.ShippedAt.Valid) {
+				continue
+			}
+
+			if err := os.Remove(filepath.Join(d.cfg.Audit.SegmentsPath, seg.Name)); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return err
+			}
+			if err := d.store.DeleteAuditSegment(ctx, tx, seg.ID); err != nil {
+				return err
+			}
+			removed++
+		}
+
+		return nil
+	}); err != nil {
+		return removed, db.WrapError(err)
+	}
+
+	return removed, nil
+}
+
+// AuditQuery filters the audit log. Empty values and zero times match every
+// record.
+type AuditQuery struct {
+	// Actor is the username of the user who did the action.
+	Actor string
+	// Action is the audit action, e.g. AuditActionKeyRevoked.
+	Action string
+	// Target is the name of the repository the action was done on.
+	Target string
+	// Since and Until are the time range the record was recorded in, Until
+	// excluded.
+	Since time.Time
+	Until time.Time
+	// Limit is the maximum number of records returned, the latest ones. A
+	// value of 0 returns all of them.
+	Limit int
+}
+
+func (q AuditQuery) match(r AuditRecord) bool {
+	return (q.Actor == "" || r.Actor == q.Actor) &&
+		(q.Action == "" || r.Action == q.Action) &&
+		(q.Target == "" || r.Target == q.Target) &&
+		(q.Since.IsZero() || !r.CreatedAt.Before(q.Since)) &&
+		(q.Until.IsZero() || r.CreatedAt.Before(q.Until))
+}
+
+// QueryAuditLog returns the audit records matching a query, oldest first,
+// across the rotated segments and the database. Segments outside of the
+// time range of the query aren't read.
+func (d *Backend) QueryAuditLog(ctx context.Context, q AuditQuery) ([]AuditRecord, error) {
+	var segments []models.AuditSegment
+	var events []models.AuditEvent
+	if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		var err error
+		segments, err = d.store.GetAuditSegments(ctx, tx)
+		if err != nil {
+			return err
+		}
+
+		events, err = d.store.FindAuditEvents(ctx, tx, q.Actor, q.Action, q.Target, q.Since, q.Until)
+		return err
+	}); err != nil {
+		return nil, db.WrapError(err)
+	}
+
+	records := []AuditRecord{}
+	for _, seg := range segments {
+		if (!q.Since.IsZero() && seg.LastAt.Before(q.Since)) || (!q.Until.IsZero() && !seg.FirstAt.Before(q.Until)) {
+			continue
+		}
+
+		segRecords, _, err := d.readAuditSegment(seg)
+		if err != nil {
+			return nil, fmt.Errorf("read audit log segment %s: %w", seg.Name, err)
+		}
+		for _, r := range segRecords {
+			if q.match(r) {
+				records = append(records, r)
+			}
+		}
+	}
+
+	for _, e := range events {
+		records = append(records, auditRecordFromEvent(e))
+	}
+
+	if q.Limit > 0 && len(records) > q.Limit {
+		records = records[len(records)-q.Limit:]
+	}
+
+	return records, nil
+}
+
+// AuditLogReport is the result of verifying the audit log.
+type AuditLogReport struct {
+	// Segments is the number of rotated segments.
+	Segments int `json:"segments"`
+	// Events is the number of chained records verified.
+	Events int `json:"events"`
+	// Unchained is the number of records recorded before the audit log was
+	// chained.
+	Unchained int `json:"unchained"`
+	// Head is the hash of the latest record. Keep it somewhere else to
+	// detect the latest records being deleted.
+	Head string `json:"head"`
+	// Problems are the records and segments that don't match the chain.
+	Problems []string `json:"problems"`
+}
+
+// Intact returns true if the audit log has no problems.
+func (r AuditLogReport) Intact() bool {
+	return len(r.Problems) == 0
+}
+
+// VerifyAuditLog checks that the records of the rotated segments and the
+// database form an unbroken hash chain, and that the segments files weren't
+// changed since they were rotated. The chain starts at the oldest segment
+// kept.
+func (d *Backend) VerifyAuditLog(ctx context.Context) (AuditLogReport, error) {
+	report := AuditLogReport{Problems: []string{}}
+	var segments []models.AuditSegment
+	var events []models.AuditEvent
+	if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		var err error
+		segments, err = d.store.GetAuditSegments(ctx, tx)
+		if err != nil {
+			return err
+		}
+
+		events, err = d.store.GetAuditEventsUpTo(ctx, tx, 0)
+		return err
+	}); err != nil {
+		return report, db.WrapError(err)
+	}
+
+	var lastID int64
+	var started bool
+	check := func(r AuditRecord) {
+		switch {
+		case r.Hash == "" && !started:
+			report.Unchained++
+			return
+		case r.Hash == "":
+			report.Problems = append(report.Problems, fmt.Sprintf("event %d: isn't chained", r.ID))
+			return
+		}
+
+		if started && r.PrevHash != report.Head {
+			report.Problems = append(report.Problems, fmt.Sprintf("event %d: previous hash doesn't match the event before it", r.ID))
+		}
+		if r.ID <= lastID {
+			report.Problems = append(report.Problems, fmt.Sprintf("event %d: out of order", r.ID))
+		}
+		if r.chainHash() != r.Hash {
+			report.Problems = append(report.Problems, fmt.Sprintf("event %d: hash doesn't match its contents", r.ID))
+		}
+
+		started = true
+		report.Head = r.Hash
+		lastID = r.ID
+		report.Events++
+	}
+
+	for _, seg := range segments {
+		report.Segments++
+		records, sum, err := d.readAuditSegment(seg)
+		if err != nil {
+			report.Problems = append(report.Problems, fmt.Sprintf("segment %s: %v", seg.Name, err))
+			continue
+		}
+
+		if sum != seg.Checksum {
+			report.Problems = append(report.Problems, fmt.Sprintf("segment %s: checksum doesn't match", seg.Name))
+		}
+		if int64(len(records)) != seg.Events {
+			report.Problems = append(report.Problems, fmt.Sprintf("segment %s: has %d events, expected %d", seg.Name, len(records), seg.Events))
+		}
+		for _, r := range records {
+			check(r)
+		}
+	}
+
+	for _, e := range events {
+		check(auditRecordFromEvent(e))
+	}
+
+	return report, nil
+}
@@ -0,0 +1,75 @@
+package backend
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAuditChainHash(t *testing.T) {
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	r := AuditRecord{
+		Action:    AuditActionRepoKeyGranted,
+		Actor:     "admin",
+		Target:    "repo1",
+		Details:   "SHA256:abc read-only",
+		CreatedAt: at,
+	}
+	hash := r.chainHash()
+	if len(hash) != 64 {
+		t.Fatalf("expected a SHA-256 hex digest, got %q", hash)
+	}
+
+	// The time zone and sub-second precision aren't stored.
+	same := r
+	same.CreatedAt = at.Add(500 * time.Millisecond).In(time.FixedZone("CET", 3600))
+	if same.chainHash() != hash {
+		t.Fatalf("expected the hash to ignore the time zone and sub-seconds")
+	}
+
+	for name, change := range map[string]func(*AuditRecord){
+		"prev hash": func(r *AuditRecord) { r.PrevHash = hash },
+		"time":      func(r *AuditRecord) { r.CreatedAt = at.Add(time.Second) },
+		"action":    func(r *AuditRecord) { r.Action = AuditActionRepoKeyRemoved },
+		"actor":     func(r *AuditRecord) { r.Actor = "user1" },
+		"target":    func(r *AuditRecord) { r.Target = "repo2" },
+		"details":   func(r *AuditRecord) { r.Details = "SHA256:abc admin-access" },
+		"shifted":   func(r *AuditRecord) { r.Actor, r.Target = "admin\nrepo1", "" },
+	} {
+		t.Run(name, func(t *testing.T) {
+			changed := r
+			change(&changed)
+			if changed.chainHash() == hash {
+				t.Fatalf("expected changing the %s to change the hash", name)
+			}
+		})
+	}
+}
+
+func TestAuditQueryMatch(t *testing.T) {
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	r := AuditRecord{Action: AuditActionLockdownEnabled, Actor: "admin", CreatedAt: at}
+	cases := []struct {
+		name  string
+		query AuditQuery
+		match bool
+	}{
+		{"empty", AuditQuery{}, true},
+		{"actor", AuditQuery{Actor: "admin"}, true},
+		{"other actor", AuditQuery{Actor: "user1"}, false},
+		{"action", AuditQuery{Action: AuditActionLockdownEnabled}, true},
+		{"other action", AuditQuery{Action: AuditActionLockdownLifted}, false},
+		{"no target", AuditQuery{Target: "repo1"}, false},
+		{"since is included", AuditQuery{Since: at}, true},
+		{"after since", AuditQuery{Since: at.Add(time.Second)}, false},
+		{"until is excluded", AuditQuery{Until: at}, false},
+		{"before until", AuditQuery{Since: at.Add(-time.Hour), Until: at.Add(time.Second)}, true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := c.query.match(r); got != c.match {
+				t.Fatalf("expected match %v, got %v", c.match, got)
+			}
+		})
+	}
+}
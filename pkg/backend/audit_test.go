@@ -0,0 +1,137 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/soft-serve/pkg/config"
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/db/migrate"
+	"github.com/charmbracelet/soft-serve/pkg/db/models"
+	"github.com/charmbracelet/soft-serve/pkg/store/database"
+	"golang.org/x/sync/errgroup"
+)
+
+// sharedBackends returns two backends with their own connections to the
+// same database, like the server and a hook process.
+func sharedBackends(t *testing.T) (context.Context, []*Backend) {
+	t.Helper()
+
+	cfg := config.DefaultConfig()
+	ctx := config.WithContext(context.TODO(), cfg)
+	dsn := filepath.Join(t.TempDir(), "soft-serve.db") + "?_pragma=busy_timeout(5000)&_pragma=foreign_keys(1)"
+
+	backends := make([]*Backend, 2)
+	for i := range backends {
+		dbx, err := db.Open(ctx, "sqlite", dsn)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { dbx.Close() }) // nolint: errcheck
+		if i == 0 {
+			if err := migrate.Migrate(ctx, dbx); err != nil {
+				t.Fatal(err)
+			}
+		}
+		backends[i] = New(ctx, cfg, dbx, database.New(ctx, dbx))
+	}
+
+	return ctx, backends
+}
+
+// checkAuditChain checks the audit events form a single chain.
+func checkAuditChain(t *testing.T, ctx context.Context, be *Backend, n int) {
+	t.Helper()
+
+	var events []models.AuditEvent
+	if err := be.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		var err error
+		events, err = be.store.GetAuditEventsUpTo(ctx, tx, 0)
+		return err
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(events) != n {
+		t.Fatalf("expected %d events, got %d", n, len(events))
+	}
+	var prev string
+	for _, e := range events {
+		if e.PrevHash != prev {
+			t.Fatalf("event %d chains to %q, expected %q", e.ID, e.PrevHash, prev)
+		}
+		prev = e.Hash
+	}
+}
+
+func TestRecordAuditEventSharedDB(t *testing.T) {
+	ctx, backends := sharedBackends(t)
+
+	const n = 20
+	var g errgroup.Group
+	for i, be := range backends {
+		i, be := i, be
+		g.Go(func() error {
+			for j := 0; j < n; j++ {
+				if err := be.recordAuditEvent(ctx, AuditActionLockdownEnabled, nil, nil, fmt.Sprintf("%d %d", i, j)); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		t.Fatal(err)
+	}
+
+	checkAuditChain(t, ctx, backends[0], 2*n)
+}
+
+func TestRecordAuditEventWaitsForChainLock(t *testing.T) {
+	ctx, backends := sharedBackends(t)
+	be, other := backends[0], backends[1]
+
+	// The other backend appends to the chain while this one records an
+	// event, which waits for it.
+	done := make(chan error, 1)
+	if err := other.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		if err := other.store.LockAuditChain(ctx, tx); err != nil {
+			return err
+		}
+
+		prev, err := other.lastAuditHash(ctx, tx)
+		if err != nil {
+			return err
+		}
+
+		go func() {
+			done <- be.recordAuditEvent(ctx, AuditActionLockdownEnabled, nil, nil, "waiting")
+		}()
+
+		time.Sleep(200 * time.Millisecond)
+		select {
+		case err := <-done:
+			return fmt.Errorf("recorded an event while the chain was locked: %v", err)
+		default:
+		}
+
+		at := time.Now().UTC().Truncate(time.Second)
+		return other.store.CreateAuditEvent(ctx, tx, models.AuditEvent{
+			Action:    AuditActionLockdownLifted,
+			PrevHash:  prev,
+			Hash:      auditChainHash(prev, at, AuditActionLockdownLifted, "", "", ""),
+			CreatedAt: at,
+		})
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+
+	checkAuditChain(t, ctx, be, 2)
+}
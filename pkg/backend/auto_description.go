@@ -0,0 +1,96 @@
+package backend
+
+import (
+	"context"
+	"strings"
+
+	"github.com/charmbracelet/soft-serve/git"
+	"github.com/charmbracelet/soft-serve/pkg/config"
+	"github.com/charmbracelet/soft-serve/pkg/hooks"
+)
+
+// autoDescribe sets the description of a repository without one from its
+// first commit or a file, as configured. It only runs on the initial push,
+// that is when every ref of the repository was created by the push. It's
+// meant to be called from the post-receive hook.
+func (d *Backend) autoDescribe(ctx context.Context, repo string, args []hooks.HookArg) error {
+	cfg := d.cfg.AutoDescription
+	if cfg.Source == "" {
+		return nil
+	}
+
+	desc, err := d.Description(ctx, repo)
+	if err != nil || desc != "" {
+		return err
+	}
+
+	r, err := d.Repository(ctx, repo)
+	if err != nil {
+		return err
+	}
+
+	rr, err := r.Open()
+	if err != nil {
+		return err
+	}
+
+	created, err := initialPushRefs(ctx, rr, args)
+	if err != nil || len(created) == 0 {
+		return err
+	}
+
+	// Prefer the default branch, the client might not have pushed it.
+	rev := created[0].NewSha
+	if head, err := rr.HEAD(); err == nil {
+		rev = head.ID
+	}
+
+	switch cfg.Source {
+	case config.AutoDescriptionCommit:
+		roots, err := git.NewCommand("rev-list", "--max-parents=0", rev).WithContext(ctx).RunInDir(rr.Path)
+		if err != nil {
+			return err
+		}
+
+		// rev-list lists the newest first, the last root is the first commit.
+		fields := strings.Fields(string(roots))
+		if len(fields) == 0 {
+			return nil
+		}
+
+		out, err := git.NewCommand("log", "-1", "--format=%s", fields[len(fields)-1]).WithContext(ctx).RunInDir(rr.Path)
+		if err != nil {
+			return err
+		}
+
+		desc = string(out)
+	case config.AutoDescriptionFile:
+		out, err := git.NewCommand("show", rev+":"+cfg.File).WithContext(ctx).RunInDir(rr.Path)
+		if err != nil {
+			// The file doesn't exist in this push.
+			d.logger.Debug("no description file", "repo", repo, "file", cfg.File, "err", err)
+			return nil
+		}
+
+		desc = string(out)
+	}
+
+	desc = firstLine(desc)
+	if desc == "" {
+		return nil
+	}
+
+	d.logger.Debug("setting automatic description", "repo", repo, "source", cfg.Source)
+	return d.SetDescription(ctx, repo, desc)
+}
+
+// firstLine returns the first non-empty line of s, trimmed.
+func firstLine(s string) string {
+	for _, line := range strings.Split(s, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return line
+		}
+	}
+
+	return ""
+}
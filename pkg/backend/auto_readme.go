@@ -0,0 +1,98 @@
+package backend
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/soft-serve/git"
+	"github.com/charmbracelet/soft-serve/pkg/proto"
+)
+
+// readmeCommitMessage is the message of the commit adding a generated README.
+const readmeCommitMessage = "Add README"
+
+// generateReadme returns a README in Markdown for a repository, with its
+// project name, or name, its description, and the commands to clone it.
+func generateReadme(name string, projectName string, description string, urls []CloneURL) string {
+	title := projectName
+	if title == "" {
+		title = name
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n", title)
+	if description = strings.TrimSpace(description); description != "" {
+		fmt.Fprintf(&b, "\n%s\n", description)
+	}
+
+	if len(urls) > 0 {
+		b.WriteString("\n## Clone\n\n```sh\n")
+		for _, u := range urls {
+			b.WriteString(u.Command + "\n")
+		}
+		b.WriteString("```\n")
+	}
+
+	return b.String()
+}
+
+// commitReadme commits a generated README to the default branch of a new
+// repository. It does nothing if the repository has any ref, like the
+// branches of its template, so it never overwrites existing content.
+func (d *Backend) commitReadme(ctx context.Context, r proto.Repository, user proto.User) error {
+	rr, err := r.Open()
+	if err != nil {
+		return err
+	}
+
+	refs, err := git.NewCommand("for-each-ref", "--count=1").WithContext(ctx).RunInDir(rr.Path)
+	if err != nil {
+		return err
+	}
+	if len(bytes.TrimSpace(refs)) > 0 {
+		return nil
+	}
+
+	head, err := git.NewCommand("symbolic-ref", git.HEAD).WithContext(ctx).RunInDir(rr.Path)
+	if err != nil {
+		return err
+	}
+	branch := strings.TrimSpace(string(head))
+
+	ci, err := d.CloneInstructions(ctx, r.Name())
+	if err != nil {
+		return err
+	}
+	readme := generateReadme(r.Name(), r.ProjectName(), r.Description(), ci.URLs)
+
+	var blob bytes.Buffer
+	if err := git.NewCommand("hash-object", "-w", "--stdin").WithContext(ctx).
+		RunInDirWithOptions(rr.Path, git.RunInDirOptions{Stdin: strings.NewReader(readme), Stdout: &blob}); err != nil {
+		return fmt.Errorf("hash-object: %w", err)
+	}
+
+	var tree bytes.Buffer
+	entry := fmt.Sprintf("100644 blob %s\tREADME.md\n", strings.TrimSpace(blob.String()))
+	if err := git.NewCommand("mktree").WithContext(ctx).
+		RunInDirWithOptions(rr.Path, git.RunInDirOptions{Stdin: strings.NewReader(entry), Stdout: &tree}); err != nil {
+		return fmt.Errorf("mktree: %w", err)
+	}
+
+	commit, err := git.NewCommand("commit-tree", strings.TrimSpace(tree.String()), "-m", readmeCommitMessage).
+		AddEnvs(d.commitIdentity(user)...).
+		WithContext(ctx).RunInDir(rr.Path)
+	if err != nil {
+		return fmt.Errorf("commit-tree: %w", err)
+	}
+
+	// The empty old value makes sure the branch still doesn't exist.
+	if _, err := git.NewCommand("update-ref", branch, strings.TrimSpace(string(commit)), "").WithContext(ctx).RunInDir(rr.Path); err != nil {
+		return fmt.Errorf("update %s: %w", branch, err)
+	}
+
+	d.logger.Info("committed generated readme", "repo", r.Name(), "branch", branch)
+
+	return nil
+}
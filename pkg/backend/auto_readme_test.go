@@ -0,0 +1,40 @@
+package backend
+
+import (
+	"testing"
+)
+
+func TestGenerateReadme(t *testing.T) {
+	urls := []CloneURL{
+		{Protocol: "ssh", Command: "git clone ssh://localhost:23231/repo1.git"},
+		{Protocol: "http", Command: "git clone http://localhost:23232/repo1.git"},
+	}
+
+	cases := []struct {
+		name        string
+		projectName string
+		description string
+		urls        []CloneURL
+		want        string
+	}{
+		{
+			name: "repo1",
+			want: "# repo1\n",
+		},
+		{
+			name:        "repo1",
+			projectName: "Repo One",
+			description: " my repo\n",
+			urls:        urls,
+			want: "# Repo One\n\nmy repo\n\n## Clone\n\n```sh\n" +
+				"git clone ssh://localhost:23231/repo1.git\n" +
+				"git clone http://localhost:23232/repo1.git\n```\n",
+		},
+	}
+
+	for _, c := range cases {
+		if got := generateReadme(c.name, c.projectName, c.description, c.urls); got != c.want {
+			t.Errorf("generateReadme(%q, %q, %q) = %q, want %q", c.name, c.projectName, c.description, got, c.want)
+		}
+	}
+}
@@ -2,10 +2,12 @@ package backend
 
 import (
 	"context"
+	"sync"
 
 	"github.com/charmbracelet/log"
 	"github.com/charmbracelet/soft-serve/pkg/config"
 	"github.com/charmbracelet/soft-serve/pkg/db"
+	logr "github.com/charmbracelet/soft-serve/pkg/log"
 	"github.com/charmbracelet/soft-serve/pkg/store"
 	"github.com/charmbracelet/soft-serve/pkg/task"
 )
@@ -18,8 +20,18 @@ type Backend struct {
 	db      *db.DB
 	store   store.Store
 	logger  *log.Logger
+	syslog  *logr.Syslog
 	cache   *cache
 	manager *task.Manager
+
+	createLimiter repoCreateLimiter
+	cloneLimiter  cloneLimiter
+	archiveLimits archiveLimiter
+	deniedPaths   deniedPathsCache
+	repoStats     repoStatsCache
+	compare       compareCache
+	repoLocks     repoLocks
+	auditMu       sync.Mutex
 }
 
 // New returns a new Soft Serve backend.
@@ -31,8 +43,10 @@ func New(ctx context.Context, cfg *config.Config, db *db.DB, st store.Store) *Ba
 		db:      db,
 		store:   st,
 		logger:  logger,
+		syslog:  logr.SyslogFromContext(ctx),
 		manager: task.NewManager(ctx),
 	}
+	b.cloneLimiter.gauge = uploadPackConcurrentGauge
 
 	// TODO: implement a proper caching interface
 	cache := newCache(b, 1000)
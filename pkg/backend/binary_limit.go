@@ -0,0 +1,226 @@
+package backend
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/soft-serve/git"
+	"github.com/charmbracelet/soft-serve/pkg/hooks"
+	"github.com/dustin/go-humanize"
+)
+
+// Repository setting keys for the binary size limit.
+const (
+	settingMaxPushBinarySize = "max_push_binary_size"
+	settingBinaryLimitExempt = "binary_limit_exempt"
+	settingBinaryLimitWarn   = "binary_limit_warn_only"
+)
+
+// maxReportedBinaryFiles is the number of binary files reported to the
+// pusher, largest first.
+const maxReportedBinaryFiles = 10
+
+// BinaryLimit is the budget of binary files a single push can add to a
+// repository.
+type BinaryLimit struct {
+	// MaxBytes is the maximum total size of the binary files added or changed
+	// by the commits of a push. Zero means no limit.
+	MaxBytes int64
+	// Exempt are the path patterns not counted against the limit. Patterns
+	// use the same syntax as path.Match and also match everything under a
+	// directory.
+	Exempt []string
+	// WarnOnly reports pushes exceeding the limit without rejecting them.
+	WarnOnly bool
+}
+
+// BinaryLimit returns the binary size limit of a repository.
+func (d *Backend) BinaryLimit(ctx context.Context, repo string) (BinaryLimit, error) {
+	var l BinaryLimit
+	settings, err := d.RepoSettings(ctx, repo)
+	if err != nil {
+		return l, err
+	}
+
+	l.MaxBytes, _ = strconv.ParseInt(settings[settingMaxPushBinarySize], 10, 64)
+	if v := settings[settingBinaryLimitExempt]; v != "" {
+		l.Exempt = strings.Split(v, ",")
+	}
+	l.WarnOnly, _ = strconv.ParseBool(settings[settingBinaryLimitWarn])
+
+	return l, nil
+}
+
+// SetBinaryLimit sets the binary size limit of a repository. A zero size
+// disables the limit.
+func (d *Backend) SetBinaryLimit(ctx context.Context, repo string, l BinaryLimit) error {
+	if l.MaxBytes < 0 {
+		return fmt.Errorf("binary size limit cannot be negative")
+	}
+
+	exempt, err := cleanExemptPaths(l.Exempt)
+	if err != nil {
+		return err
+	}
+
+	settings := map[string]string{
+		settingMaxPushBinarySize: "",
+		settingBinaryLimitExempt: strings.Join(exempt, ","),
+		settingBinaryLimitWarn:   "",
+	}
+	if l.MaxBytes > 0 {
+		settings[settingMaxPushBinarySize] = strconv.FormatInt(l.MaxBytes, 10)
+	}
+	if l.WarnOnly {
+		settings[settingBinaryLimitWarn] = "true"
+	}
+
+	return d.SetRepoSettings(ctx, repo, settings)
+}
+
+// binaryFile is a binary file added or changed by a commit.
+type binaryFile struct {
+	Commit string
+	Path   string
+	Blob   string
+	Size   int64
+}
+
+// binaryFiles parses the output of git log with both --raw and --numstat,
+// with each commit starting with a NUL and its id, and returns the binary
+// files the commits add or change. Files are binary when git shows no line
+// counts for them, following its heuristics and the binary attribute.
+func binaryFiles(out []byte, exempt []string) []binaryFile {
+	var files []binaryFile
+	var commit string
+	blobs := map[string]string{}
+	s := bufio.NewScanner(bytes.NewReader(out))
+	s.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for s.Scan() {
+		line := s.Text()
+		switch {
+		case strings.HasPrefix(line, "\x00"):
+			commit = strings.TrimPrefix(line, "\x00")
+			clear(blobs)
+		case strings.HasPrefix(line, ":"):
+			meta, p, ok := strings.Cut(line, "\t")
+			if !ok {
+				continue
+			}
+			// The new blob is the fourth field, zero for deleted files.
+			if fields := strings.Fields(meta); len(fields) >= 4 && !git.IsZeroHash(fields[3]) {
+				blobs[p] = fields[3]
+			}
+		case strings.HasPrefix(line, "-\t-\t"):
+			p := strings.TrimPrefix(line, "-\t-\t")
+			blob, ok := blobs[p]
+			if !ok || matchExemptPath(exempt, p) {
+				continue
+			}
+			files = append(files, binaryFile{Commit: commit, Path: p, Blob: blob})
+		}
+	}
+
+	return files
+}
+
+// checkBinaryLimit returns an error if the binary files added or changed by
+// the pushed commits exceed the binary size limit of the repository. Each
+// binary blob counts once. It's meant to be called from the pre-receive hook.
+func (d *Backend) checkBinaryLimit(ctx context.Context, stderr io.Writer, repo string, args []hooks.HookArg) error {
+	l, err := d.BinaryLimit(ctx, repo)
+	if err != nil || l.MaxBytes == 0 {
+		return err
+	}
+
+	rp, err := d.Repository(ctx, repo)
+	if err != nil {
+		return err
+	}
+
+	rr, err := rp.Open()
+	if err != nil {
+		return err
+	}
+
+	revs := []string{
+		"-c", "core.quotePath=false", "log", "--raw", "--numstat", "--no-renames", "--no-abbrev", "-r",
+		"--format=%x00%H",
+	}
+	n := len(revs)
+	for _, arg := range args {
+		if !git.IsZeroHash(arg.NewSha) {
+			revs = append(revs, arg.NewSha)
+		}
+	}
+	if len(revs) == n {
+		return nil
+	}
+
+	// Only the commits the push introduces count.
+	revs = append(revs, "--not", "--all")
+	out, err := git.NewCommand(revs...).WithContext(ctx).RunInDir(rr.Path)
+	if err != nil {
+		return err
+	}
+
+	var files []binaryFile
+	seen := map[string]bool{}
+	var input strings.Builder
+	for _, f := range binaryFiles(out, l.Exempt) {
+		if !seen[f.Blob] {
+			seen[f.Blob] = true
+			files = append(files, f)
+			input.WriteString(f.Blob + "\n")
+		}
+	}
+	if len(files) == 0 {
+		return nil
+	}
+
+	var sizes bytes.Buffer
+	if err := git.NewCommand("cat-file", "--batch-check=%(objectsize)").WithContext(ctx).
+		RunInDirWithOptions(rr.Path, git.RunInDirOptions{Stdin: strings.NewReader(input.String()), Stdout: &sizes}); err != nil {
+		return err
+	}
+
+	var total int64
+	for i, line := range strings.Split(strings.TrimSpace(sizes.String()), "\n") {
+		if i >= len(files) {
+			break
+		}
+		files[i].Size, _ = strconv.ParseInt(line, 10, 64)
+		total += files[i].Size
+	}
+
+	if total <= l.MaxBytes {
+		return nil
+	}
+
+	prefix := "error"
+	if l.WarnOnly {
+		prefix = "warning"
+	}
+	sort.SliceStable(files, func(i, j int) bool { return files[i].Size > files[j].Size })
+	for i, f := range files {
+		if i == maxReportedBinaryFiles {
+			fmt.Fprintf(stderr, "%s: and %d more\n", prefix, len(files)-i) // nolint: errcheck
+			break
+		}
+		fmt.Fprintf(stderr, "%s: binary file %s (%s) in commit %s\n", prefix, f.Path, humanize.IBytes(uint64(f.Size)), f.Commit) // nolint: errcheck
+	}
+
+	msg := fmt.Sprintf("push adds %s of binary files, the limit is %s", humanize.IBytes(uint64(total)), humanize.IBytes(uint64(l.MaxBytes)))
+	if l.WarnOnly {
+		warnPolicy(ctx, stderr, policyBinaryLimit, msg)
+		return nil
+	}
+
+	return fmt.Errorf("%s; track large binary files with Git LFS instead, or ask an admin to exempt their paths", msg)
+}
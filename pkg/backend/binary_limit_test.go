@@ -0,0 +1,38 @@
+package backend
+
+import (
+	"testing"
+)
+
+func TestBinaryFiles(t *testing.T) {
+	const zero = "0000000000000000000000000000000000000000"
+	const text = "3594e94c04db171e2767224db355f514b13715c5"
+	const bin = "efd2687e91a7d9f9dbb6cba674ea8c5fe214a5a9"
+	const asset = "d5d0b8b4c4c9e936890870f6799cfbb5ba984470"
+	out := []byte("\x00c1\n\n" +
+		":100644 100644 " + text + " " + text + " M\ta.txt\n" +
+		":000000 100644 " + zero + " " + bin + " A\tbin/app\n" +
+		":000000 100644 " + zero + " " + asset + " A\tassets/logo.png\n" +
+		":100644 000000 " + bin + " " + zero + " D\told.bin\n" +
+		"1\t0\ta.txt\n" +
+		"-\t-\tbin/app\n" +
+		"-\t-\tassets/logo.png\n" +
+		"-\t-\told.bin\n" +
+		"\x00c2\n\n" +
+		":100644 100644 " + bin + " " + asset + " M\tbin/app\n" +
+		"-\t-\tbin/app\n")
+
+	got := binaryFiles(out, []string{"assets"})
+	want := []binaryFile{
+		{Commit: "c1", Path: "bin/app", Blob: bin},
+		{Commit: "c2", Path: "bin/app", Blob: asset},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %+v, got %+v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected %+v, got %+v", want[i], got[i])
+		}
+	}
+}
@@ -0,0 +1,244 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/soft-serve/git"
+	"github.com/charmbracelet/soft-serve/pkg/hooks"
+	"github.com/charmbracelet/soft-serve/pkg/proto"
+	"github.com/charmbracelet/soft-serve/pkg/webhook"
+)
+
+// settingBranchRenames is the repository setting holding the renamed default
+// branches, as comma-separated old=new pairs.
+const settingBranchRenames = "branch_renames"
+
+// ErrNotDefaultBranch is returned when renaming the default branch of a
+// repository from a branch that isn't its default branch.
+var ErrNotDefaultBranch = errors.New("not the default branch")
+
+// BranchRenames returns the renamed default branches of a repository, the
+// new names by old name.
+func (d *Backend) BranchRenames(ctx context.Context, repo string) (map[string]string, error) {
+	settings, err := d.RepoSettings(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseBranchRenames(settings[settingBranchRenames]), nil
+}
+
+// RenameDefaultBranch renames the default branch of a repository from one
+// branch to another. The new branch is created at the tip of the old one, and
+// HEAD points to it. The old branch is deleted if deleteOld is set. The
+// rename is recorded so pushes to the old branch are told about the new
+// one, and in the audit log.
+//
+// It returns git.ErrReferenceNotExist if the repository has no such branch,
+// and ErrNotDefaultBranch if it isn't the default branch.
+func (d *Backend) RenameDefaultBranch(ctx context.Context, repo string, from string, to string, deleteOld bool) error {
+	if err := d.checkWritable(); err != nil {
+		return err
+	}
+
+	if from == to {
+		return fmt.Errorf("the old and new branch names are the same")
+	}
+
+	// Renames are stored as comma-separated old=new pairs.
+	if strings.ContainsAny(from+to, ",=") {
+		return fmt.Errorf("branch names containing ',' or '=' can't be renamed")
+	}
+
+	unlock, err := d.LockRepository(ctx, repo, "rename-default-branch")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	r, err := d.Repository(ctx, repo)
+	if err != nil {
+		return err
+	}
+
+	rr, err := r.Open()
+	if err != nil {
+		return err
+	}
+
+	if _, err := git.NewCommand("check-ref-format", "--branch", to).WithContext(ctx).RunInDir(rr.Path); err != nil {
+		return fmt.Errorf("invalid branch name %q", to)
+	}
+
+	out, err := git.NewCommand("rev-parse", "--verify", "--quiet", git.RefsHeads+from).WithContext(ctx).RunInDir(rr.Path)
+	if err != nil {
+		return fmt.Errorf("%w: %q", git.ErrReferenceNotExist, from)
+	}
+	commit := strings.TrimSpace(string(out))
+
+	head, err := rr.HEAD()
+	if err != nil {
+		return err
+	}
+	if head.Name().Short() != from {
+		return fmt.Errorf("%w: %q is the default branch", ErrNotDefaultBranch, head.Name().Short())
+	}
+
+	// Only create the new branch if it doesn't exist, a branch with the same
+	// name must already point to the same commit.
+	oldTo := git.ZeroID
+	if out, err := git.NewCommand("rev-parse", "--verify", "--quiet", git.RefsHeads+to).WithContext(ctx).RunInDir(rr.Path); err == nil {
+		oldTo = strings.TrimSpace(string(out))
+		if oldTo != commit {
+			return fmt.Errorf("branch %q already exists at a different commit", to)
+		}
+	} else if _, err := git.NewCommand("update-ref", git.RefsHeads+to, commit, "").WithContext(ctx).RunInDir(rr.Path); err != nil {
+		return fmt.Errorf("create branch %q: %w", to, err)
+	}
+
+	if _, err := git.NewCommand("symbolic-ref", git.HEAD, git.RefsHeads+to).WithContext(ctx).RunInDir(rr.Path); err != nil {
+		return fmt.Errorf("update HEAD: %w", err)
+	}
+
+	if deleteOld {
+		if _, err := git.NewCommand("update-ref", "-d", git.RefsHeads+from, commit).WithContext(ctx).RunInDir(rr.Path); err != nil {
+			return fmt.Errorf("delete branch %q: %w", from, err)
+		}
+	}
+
+	if err := d.recordBranchRename(ctx, repo, from, to); err != nil {
+		return err
+	}
+
+	d.logger.Info("renamed default branch", "repo", repo, "from", from, "to", to, "commit", commit, "deleted", deleteOld)
+	user := proto.UserFromContext(ctx)
+	details := fmt.Sprintf("%s %s %s", from, to, commit)
+	if deleteOld {
+		details += " deleted"
+	}
+	if err := d.recordAuditEvent(ctx, AuditActionDefaultBranchRenamed, r, user, details); err != nil {
+		d.logger.Error("error recording default branch rename", "repo", repo, "err", err)
+	}
+
+	d.sendBranchRenameEvents(ctx, user, r, from, to, commit, oldTo, deleteOld)
+
+	return nil
+}
+
+// recordBranchRename records that a default branch was renamed from one
+// branch to another. Branches renamed to the old name now point to the new
+// one, and the new name stops being an old name.
+func (d *Backend) recordBranchRename(ctx context.Context, repo string, from string, to string) error {
+	renames, err := d.BranchRenames(ctx, repo)
+	if err != nil {
+		return err
+	}
+
+	for old, name := range renames {
+		if name == from {
+			renames[old] = to
+		}
+	}
+	delete(renames, to)
+	renames[from] = to
+
+	return d.SetRepoSettings(ctx, repo, map[string]string{
+		settingBranchRenames: formatBranchRenames(renames),
+	})
+}
+
+// sendBranchRenameEvents sends the webhooks of a default branch rename: the
+// new branch, the old branch deletion, and the default branch change.
+// Webhooks need a sender, so renames from the server command line, without a
+// user, don't send any.
+func (d *Backend) sendBranchRenameEvents(ctx context.Context, user proto.User, r proto.Repository, from string, to string, commit string, oldTo string, deleted bool) {
+	if user == nil {
+		return
+	}
+
+	var events []webhook.EventPayload
+	if oldTo == git.ZeroID {
+		wh, err := webhook.NewBranchTagEvent(ctx, user, r, git.RefsHeads+to, git.ZeroIDOf(commit), commit)
+		if err != nil {
+			d.logger.Error("error creating branch_tag webhook", "err", err)
+		} else {
+			events = append(events, wh)
+		}
+	}
+	if deleted {
+		wh, err := webhook.NewBranchTagEvent(ctx, user, r, git.RefsHeads+from, commit, git.ZeroIDOf(commit))
+		if err != nil {
+			d.logger.Error("error creating branch_tag webhook", "err", err)
+		} else {
+			events = append(events, wh)
+		}
+	}
+	wh, err := webhook.NewRepositoryEvent(ctx, user, r, webhook.RepositoryEventActionDefaultBranchChange)
+	if err != nil {
+		d.logger.Error("error creating repository webhook", "err", err)
+	} else {
+		events = append(events, wh)
+	}
+
+	for _, e := range events {
+		if err := webhook.SendEvent(ctx, e); err != nil {
+			d.logger.Error("error sending webhook", "event", e.Event(), "err", err)
+		}
+	}
+}
+
+// writeBranchRenameHints tells clients pushing to a renamed default branch
+// about the new name.
+func (d *Backend) writeBranchRenameHints(ctx context.Context, stderr io.Writer, repo string, args []hooks.HookArg) {
+	renames, err := d.BranchRenames(ctx, repo)
+	if err != nil {
+		d.logger.Error("error getting branch renames", "repo", repo, "err", err)
+		return
+	}
+
+	for _, arg := range args {
+		if !strings.HasPrefix(arg.RefName, git.RefsHeads) || git.IsZeroHash(arg.NewSha) {
+			continue
+		}
+
+		from := strings.TrimPrefix(arg.RefName, git.RefsHeads)
+		to, ok := renames[from]
+		if !ok {
+			continue
+		}
+
+		fmt.Fprintf(stderr, "hint: the default branch %q was renamed to %q, update your clone with:\n", from, to)                // nolint: errcheck
+		fmt.Fprintf(stderr, "hint:   git branch -m %s %s && git fetch origin && git branch -u origin/%s %s\n", from, to, to, to) // nolint: errcheck
+	}
+}
+
+// parseBranchRenames parses comma-separated old=new branch renames.
+func parseBranchRenames(v string) map[string]string {
+	renames := make(map[string]string)
+	for _, p := range strings.Split(v, ",") {
+		from, to, ok := strings.Cut(p, "=")
+		if !ok || from == "" || to == "" {
+			continue
+		}
+		renames[from] = to
+	}
+
+	return renames
+}
+
+// formatBranchRenames formats branch renames as comma-separated old=new
+// pairs, sorted by old name.
+func formatBranchRenames(renames map[string]string) string {
+	pairs := make([]string, 0, len(renames))
+	for from, to := range renames {
+		pairs = append(pairs, from+"="+to)
+	}
+	sort.Strings(pairs)
+
+	return strings.Join(pairs, ",")
+}
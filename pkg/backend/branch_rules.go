@@ -0,0 +1,84 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/charmbracelet/soft-serve/git"
+)
+
+// branchPatterns returns the comma-separated branch name patterns stored in
+// the given repository setting.
+func (d *Backend) branchPatterns(ctx context.Context, repo string, key string) ([]string, error) {
+	settings, err := d.RepoSettings(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	v := settings[key]
+	if v == "" {
+		return nil, nil
+	}
+
+	return strings.Split(v, ","), nil
+}
+
+// setBranchPatterns validates and stores branch name patterns in the given
+// repository setting. No patterns removes the setting.
+func (d *Backend) setBranchPatterns(ctx context.Context, repo string, key string, patterns []string) error {
+	for _, p := range patterns {
+		if p == "" || strings.Contains(p, ",") {
+			return fmt.Errorf("invalid branch pattern %q", p)
+		}
+		if _, err := path.Match(p, ""); err != nil {
+			return fmt.Errorf("invalid branch pattern %q: %w", p, err)
+		}
+	}
+
+	return d.SetRepoSettings(ctx, repo, map[string]string{
+		key: strings.Join(patterns, ","),
+	})
+}
+
+// protectedBranchSettings are the repository settings holding the patterns of
+// branches with protection rules.
+var protectedBranchSettings = []string{
+	settingLinearHistoryBranches,
+	settingSignedPushBranches,
+	settingRequiredStatusBranches,
+}
+
+// protectedBranchPatterns returns the branch name patterns of a repository
+// that have at least one protection rule.
+func (d *Backend) protectedBranchPatterns(ctx context.Context, repo string) ([]string, error) {
+	var patterns []string
+	for _, key := range protectedBranchSettings {
+		p, err := d.branchPatterns(ctx, repo, key)
+		if err != nil {
+			return nil, err
+		}
+
+		patterns = append(patterns, p...)
+	}
+
+	return patterns, nil
+}
+
+// matchBranch returns the branch name of ref and whether it matches one of
+// the patterns. Refs outside of refs/heads never match.
+func matchBranch(patterns []string, ref string) (string, bool) {
+	if !strings.HasPrefix(ref, git.RefsHeads) {
+		return "", false
+	}
+
+	branch := strings.TrimPrefix(ref, git.RefsHeads)
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, branch); ok {
+			return branch, true
+		}
+	}
+
+	return branch, false
+}
@@ -0,0 +1,165 @@
+package backend
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/soft-serve/git"
+	"github.com/charmbracelet/soft-serve/pkg/utils"
+)
+
+// settingCloneProtocol is the repository setting key of the protocol shown
+// first in the clone instructions.
+const settingCloneProtocol = "clone_protocol"
+
+// CloneURL is the URL and the command to clone a repository over a protocol.
+type CloneURL struct {
+	Protocol string `json:"protocol"`
+	URL      string `json:"url"`
+	Command  string `json:"command"`
+}
+
+// CloneInstructions tell how to clone a repository.
+type CloneInstructions struct {
+	// URLs are the clone URLs of the protocols the repository can be read
+	// over, the default protocol first.
+	URLs []CloneURL `json:"urls"`
+	// Submodules is whether the repository has submodules.
+	Submodules bool `json:"submodules"`
+	// LFS is whether the repository tracks files with Git LFS.
+	LFS bool `json:"lfs"`
+	// Notes are the steps to take before or after cloning.
+	Notes []string `json:"notes"`
+}
+
+// RepoURL returns the URL of a repository served at the public URL of a
+// protocol. SSH URLs use the scp-like syntax on the default port.
+func RepoURL(publicURL, name string) string {
+	name = utils.SanitizeRepo(name) + ".git"
+	url, err := url.Parse(publicURL)
+	if err == nil {
+		switch url.Scheme {
+		case "ssh":
+			port := url.Port()
+			if port == "" || port == "22" {
+				return fmt.Sprintf("git@%s:%s", url.Hostname(), name)
+			}
+			return fmt.Sprintf("ssh://%s:%s/%s", url.Hostname(), url.Port(), name)
+		}
+	}
+
+	return fmt.Sprintf("%s/%s", publicURL, name)
+}
+
+// CloneProtocol returns the protocol shown first in the clone instructions
+// of a repository, empty for the server default.
+func (d *Backend) CloneProtocol(ctx context.Context, repo string) (string, error) {
+	settings, err := d.RepoSettings(ctx, repo)
+	if err != nil {
+		return "", err
+	}
+
+	return settings[settingCloneProtocol], nil
+}
+
+// SetCloneProtocol sets the protocol shown first in the clone instructions of
+// a repository. An empty protocol resets it to the server default, SSH.
+func (d *Backend) SetCloneProtocol(ctx context.Context, repo string, protocol string) error {
+	switch protocol {
+	case "", ProtocolSSH, ProtocolHTTP, ProtocolGit:
+	default:
+		return fmt.Errorf("invalid clone protocol %q, expected %s, %s, or %s", protocol, ProtocolSSH, ProtocolHTTP, ProtocolGit)
+	}
+
+	return d.SetRepoSettings(ctx, repo, map[string]string{settingCloneProtocol: protocol})
+}
+
+// CloneInstructions returns how to clone a repository over the protocols it
+// can be read over, using the public URLs of the server. The Git daemon
+// protocol is only included for public repositories it exports.
+func (d *Backend) CloneInstructions(ctx context.Context, repo string) (CloneInstructions, error) {
+	ci := CloneInstructions{URLs: []CloneURL{}, Notes: []string{}}
+	r, err := d.Repository(ctx, repo)
+	if err != nil {
+		return ci, err
+	}
+
+	settings, err := d.RepoSettings(ctx, repo)
+	if err != nil {
+		return ci, err
+	}
+
+	p, err := d.Protocols(ctx, repo)
+	if err != nil {
+		return ci, err
+	}
+
+	export, _ := d.DaemonExport(ctx, repo)
+	publicURLs := map[string]string{
+		ProtocolSSH:  d.cfg.SSH.PublicURL,
+		ProtocolHTTP: d.cfg.HTTP.PublicURL,
+	}
+	if d.cfg.Git.Enabled && export && !r.IsPrivate() {
+		publicURLs[ProtocolGit] = d.cfg.Git.PublicURL
+	}
+
+	if rr, err := r.Open(); err == nil {
+		ci.Submodules, ci.LFS = repoUsesSubmodulesAndLFS(ctx, rr.Path)
+	}
+
+	clone := "git clone "
+	if ci.Submodules {
+		clone += "--recurse-submodules "
+	}
+
+	protocols := []string{ProtocolSSH, ProtocolHTTP, ProtocolGit}
+	if def := settings[settingCloneProtocol]; def != "" {
+		protocols = append([]string{def}, slices.DeleteFunc(protocols, func(p string) bool { return p == def })...)
+	}
+	for _, protocol := range protocols {
+		publicURL := publicURLs[protocol]
+		if publicURL == "" || (len(p.Read) > 0 && !slices.Contains(p.Read, protocol)) {
+			continue
+		}
+
+		u := RepoURL(publicURL, r.Name())
+		ci.URLs = append(ci.URLs, CloneURL{Protocol: protocol, URL: u, Command: clone + u})
+	}
+
+	if ci.LFS {
+		if d.cfg.LFS.Enabled {
+			ci.Notes = append(ci.Notes, "This repository uses Git LFS, run `git lfs install` once before cloning to download the LFS files.")
+		} else {
+			ci.Notes = append(ci.Notes, "This repository tracks files with Git LFS, but this server doesn't serve them.")
+		}
+	}
+	if ci.Submodules {
+		ci.Notes = append(ci.Notes, "This repository has submodules, in existing clones run `git submodule update --init --recursive` to fetch them.")
+	}
+
+	return ci, nil
+}
+
+// repoUsesSubmodulesAndLFS returns whether the HEAD of a repository has
+// submodules and files tracked with Git LFS. Empty repositories have
+// neither.
+func repoUsesSubmodulesAndLFS(ctx context.Context, path string) (submodules bool, lfs bool) {
+	if _, err := git.NewCommand("cat-file", "-e", git.HEAD+":.gitmodules").WithContext(ctx).RunInDir(path); err == nil {
+		submodules = true
+	}
+
+	if out, err := git.NewCommand("cat-file", "blob", git.HEAD+":.gitattributes").WithContext(ctx).RunInDir(path); err == nil {
+		for _, line := range bytes.Split(out, []byte("\n")) {
+			if slices.Contains(strings.Fields(string(line)), "filter=lfs") {
+				lfs = true
+				break
+			}
+		}
+	}
+
+	return submodules, lfs
+}
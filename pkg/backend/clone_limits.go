@@ -0,0 +1,191 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/soft-serve/pkg/config"
+	"github.com/charmbracelet/soft-serve/pkg/proto"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	uploadPackConcurrentGauge = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "soft_serve",
+		Subsystem: "git",
+		Name:      "upload_pack_concurrent",
+		Help:      "The number of concurrent git-upload-pack operations per client IP address",
+	}, []string{"ip"})
+
+	uploadPackLimitedCounter = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: "soft_serve",
+		Subsystem: "git",
+		Name:      "upload_pack_limited_total",
+		Help:      "The total number of git-upload-pack operations rejected by the per-IP clone limit",
+	})
+)
+
+// cloneLimiter limits the concurrent operations, like git-upload-pack, of
+// each client IP address.
+type cloneLimiter struct {
+	mu  sync.Mutex
+	ips map[string]*ipSlots
+	// gauge reports the running operations of each address, if set.
+	gauge *prometheus.GaugeVec
+}
+
+// ipSlots are the operation slots of a client IP address.
+type ipSlots struct {
+	// slots holds a token per running operation when the address is
+	// limited.
+	slots   chan struct{}
+	active  int
+	waiting int
+}
+
+// acquire waits up to timeout for a slot of ip, limit being the number of
+// slots. A limit of 0 only tracks the operation. It returns a function
+// releasing the slot, or false if no slot freed up in time.
+func (l *cloneLimiter) acquire(ctx context.Context, ip string, limit int, timeout time.Duration) (func(), bool) {
+	l.mu.Lock()
+	if l.ips == nil {
+		l.ips = map[string]*ipSlots{}
+	}
+	s, ok := l.ips[ip]
+	if !ok {
+		s = &ipSlots{}
+		if limit > 0 {
+			s.slots = make(chan struct{}, limit)
+		}
+		l.ips[ip] = s
+	}
+	s.waiting++
+	l.mu.Unlock()
+
+	if s.slots != nil && !l.wait(ctx, s, timeout) {
+		l.done(ip, s, false)
+		return nil, false
+	}
+
+	l.mu.Lock()
+	s.waiting--
+	s.active++
+	l.report(ip, s.active)
+	l.mu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			l.done(ip, s, true)
+			if s.slots != nil {
+				<-s.slots
+			}
+		})
+	}, true
+}
+
+// wait waits up to timeout for a free slot in s. A zero timeout doesn't
+// wait.
+func (l *cloneLimiter) wait(ctx context.Context, s *ipSlots, timeout time.Duration) bool {
+	if timeout <= 0 {
+		select {
+		case s.slots <- struct{}{}:
+			return true
+		default:
+			return false
+		}
+	}
+
+	t := time.NewTimer(timeout)
+	defer t.Stop()
+	select {
+	case s.slots <- struct{}{}:
+		return true
+	case <-t.C:
+		return false
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// done removes an operation, running or waiting, from the slots of ip and
+// forgets the address once it has none left.
+func (l *cloneLimiter) done(ip string, s *ipSlots, active bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if active {
+		s.active--
+		l.report(ip, s.active)
+	} else {
+		s.waiting--
+	}
+
+	if s.active == 0 && s.waiting == 0 && l.ips[ip] == s {
+		delete(l.ips, ip)
+	}
+}
+
+// report reports the running operations of ip in the gauge.
+func (l *cloneLimiter) report(ip string, active int) {
+	switch {
+	case l.gauge == nil:
+	case active > 0:
+		l.gauge.WithLabelValues(ip).Set(float64(active))
+	default:
+		l.gauge.DeleteLabelValues(ip)
+	}
+}
+
+// AcquireUploadPack takes a git-upload-pack slot of the client at addr, a
+// host:port or an IP address. Clients from the clone limits allowlist are not
+// limited, but are still counted in the concurrency metric. The returned
+// function releases the slot and must be called once the operation is over.
+// It returns proto.ErrCloneLimit if the client is over the limit.
+func (d *Backend) AcquireUploadPack(ctx context.Context, addr string) (func(), error) {
+	ip := addrIP(addr)
+	cfg := d.cfg.CloneLimits
+	limit := cfg.PerIP
+	if limit > 0 && d.cloneLimitExempt(ip) {
+		limit = 0
+	}
+
+	release, ok := d.cloneLimiter.acquire(ctx, ip, limit, time.Duration(cfg.QueueTimeout)*time.Second)
+	if !ok {
+		uploadPackLimitedCounter.Inc()
+		d.logger.Info("clone limit reached", "ip", ip, "limit", limit)
+		return nil, fmt.Errorf("%w from your address, at most %d at a time, try again later", proto.ErrCloneLimit, limit)
+	}
+
+	return release, nil
+}
+
+// cloneLimitExempt returns whether ip is in the clone limits allowlist.
+func (d *Backend) cloneLimitExempt(ip string) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+
+	for _, a := range d.cfg.CloneLimits.Allowlist {
+		n, err := config.ParseIPNet(a)
+		if err == nil && n.Contains(parsed) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// addrIP returns the IP address of a host:port address, or addr itself if it
+// has no port.
+func addrIP(addr string) string {
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+
+	return addr
+}
@@ -0,0 +1,65 @@
+package backend
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestCloneLimiter(t *testing.T) {
+	var l cloneLimiter
+	ctx := context.Background()
+
+	var releases []func()
+	for i := 0; i < 2; i++ {
+		release, ok := l.acquire(ctx, "192.0.2.1", 2, 0)
+		if !ok {
+			t.Fatalf("operation %d should be allowed", i+1)
+		}
+		releases = append(releases, release)
+	}
+
+	if _, ok := l.acquire(ctx, "192.0.2.1", 2, 0); ok {
+		t.Fatal("operation over the limit should be rejected")
+	}
+
+	// Other addresses have their own limit.
+	release, ok := l.acquire(ctx, "192.0.2.2", 2, 0)
+	if !ok {
+		t.Fatal("operation from another address should be allowed")
+	}
+	release()
+
+	// Queued operations get the slot of a finished one.
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		releases[0]()
+	}()
+	queued, ok := l.acquire(ctx, "192.0.2.1", 2, 5*time.Second)
+	if !ok {
+		t.Fatal("queued operation should be allowed once a slot is free")
+	}
+
+	// Queued operations give up after the timeout.
+	if _, ok := l.acquire(ctx, "192.0.2.1", 2, 10*time.Millisecond); ok {
+		t.Fatal("queued operation should time out")
+	}
+
+	// Releasing twice frees a single slot.
+	queued()
+	queued()
+	releases[1]()
+	if len(l.ips) != 0 {
+		t.Fatalf("expected addresses without operations to be forgotten, got %d", len(l.ips))
+	}
+
+	// No limit only tracks the operations.
+	for i := 0; i < 10; i++ {
+		if _, ok := l.acquire(ctx, "192.0.2.3", 0, 0); !ok {
+			t.Fatal("operation without a limit should be allowed")
+		}
+	}
+	if got := l.ips["192.0.2.3"].active; got != 10 {
+		t.Fatalf("expected 10 active operations, got %d", got)
+	}
+}
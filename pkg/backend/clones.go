@@ -0,0 +1,92 @@
+package backend
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/db/models"
+	"github.com/charmbracelet/soft-serve/pkg/proto"
+)
+
+// settingCloneTracking is the repository setting key to enable clone tracking.
+const settingCloneTracking = "clone_tracking"
+
+// CloneStats are the clone statistics of a repository.
+type CloneStats struct {
+	// Count is the total number of clones and fetches.
+	Count int64
+	// Recent are the latest clone events, newest first.
+	Recent []models.CloneEvent
+}
+
+// CloneTracking returns whether clone tracking is enabled for a repository.
+// Clone tracking is opt-in.
+func (d *Backend) CloneTracking(ctx context.Context, repo string) (bool, error) {
+	settings, err := d.RepoSettings(ctx, repo)
+	if err != nil {
+		return false, err
+	}
+
+	enabled, _ := strconv.ParseBool(settings[settingCloneTracking])
+	return enabled, nil
+}
+
+// SetCloneTracking enables or disables clone tracking for a repository.
+// Existing clone events are kept when tracking is disabled.
+func (d *Backend) SetCloneTracking(ctx context.Context, repo string, enabled bool) error {
+	var value string
+	if enabled {
+		value = "true"
+	}
+
+	return d.SetRepoSettings(ctx, repo, map[string]string{settingCloneTracking: value})
+}
+
+// RecordClone records a git-upload-pack request to a repository by the given
+// user over the given protocol. It does nothing if clone tracking is disabled
+// for the repository. A nil user means an anonymous clone.
+func (d *Backend) RecordClone(ctx context.Context, repo string, user proto.User, protocol string) error {
+	r, err := d.Repository(ctx, repo)
+	if err != nil {
+		return err
+	}
+
+	enabled, err := d.CloneTracking(ctx, r.Name())
+	if err != nil || !enabled {
+		return err
+	}
+
+	var userID int64
+	if user != nil {
+		userID = user.ID()
+	}
+
+	return db.WrapError(d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		return d.store.CreateCloneEvent(ctx, tx, r.ID(), userID, protocol)
+	}))
+}
+
+// CloneStats returns the clone statistics of a repository with up to limit
+// recent clone events.
+func (d *Backend) CloneStats(ctx context.Context, repo string, limit int) (CloneStats, error) {
+	var stats CloneStats
+	r, err := d.Repository(ctx, repo)
+	if err != nil {
+		return stats, err
+	}
+
+	if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		stats.Count, err = d.store.GetCloneCountByRepoID(ctx, tx, r.ID())
+		if err != nil {
+			return err
+		}
+
+		stats.Recent, err = d.store.GetRecentCloneEventsByRepoID(ctx, tx, r.ID(), limit)
+		return err
+	}); err != nil {
+		return stats, db.WrapError(err)
+	}
+
+	return stats, nil
+}
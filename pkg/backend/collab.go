@@ -80,7 +80,7 @@ func (d *Backend) IsCollaborator(ctx context.Context, repo string, username stri
 
 	repo = utils.SanitizeRepo(repo)
 	var m models.Collab
-	if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+	if err := d.retryTx(ctx, "is_collaborator", func(tx *db.Tx) error {
 		var err error
 		m, err = d.store.GetCollabByUsernameAndRepo(ctx, tx, username, repo)
 		return err
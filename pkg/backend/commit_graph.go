@@ -0,0 +1,131 @@
+package backend
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/soft-serve/git"
+)
+
+// Commit-graph states of a repository.
+const (
+	// CommitGraphMissing is a repository without a commit-graph.
+	CommitGraphMissing = "missing"
+	// CommitGraphStale is a repository whose refs changed since its
+	// commit-graph was written.
+	CommitGraphStale = "stale"
+	// CommitGraphUpToDate is a repository whose commit-graph covers all its
+	// refs.
+	CommitGraphUpToDate = "up-to-date"
+)
+
+// commitGraphRefsFile is the file, relative to a repository, with the
+// fingerprint of the refs its commit-graph was last written for.
+var commitGraphRefsFile = filepath.Join("info", "commit-graph-refs")
+
+// CommitGraphState returns the commit-graph state of a repository.
+func (d *Backend) CommitGraphState(ctx context.Context, repo string) (string, error) {
+	rr, err := d.openRepo(ctx, repo)
+	if err != nil {
+		return "", err
+	}
+
+	state, _, err := commitGraphState(ctx, rr.Path)
+	return state, err
+}
+
+// WriteCommitGraph writes the commit-graph of a repository if it's missing or
+// stale. Writes are incremental, only the commits added since the last write
+// go to a new layer. It's a no-op if commit-graphs are disabled, or if
+// another process is writing the commit-graph, which git guards with a lock
+// file.
+func (d *Backend) WriteCommitGraph(ctx context.Context, repo string) error {
+	if !d.cfg.CommitGraph.Enabled {
+		return nil
+	}
+
+	rr, err := d.openRepo(ctx, repo)
+	if err != nil {
+		return err
+	}
+
+	state, refs, err := commitGraphState(ctx, rr.Path)
+	if err != nil || state == CommitGraphUpToDate {
+		return err
+	}
+
+	// Empty repositories don't have commits to write.
+	if refs == "" {
+		return nil
+	}
+
+	if _, err := git.NewCommand("commit-graph", "write", "--reachable", "--split", "--changed-paths").
+		WithContext(ctx).RunInDir(rr.Path); err != nil {
+		if strings.Contains(err.Error(), ".lock': File exists") {
+			d.logger.Debug("commit-graph is being written by another process", "repo", repo)
+			return nil
+		}
+		return err
+	}
+
+	// Record the refs read before writing. If they changed in the meantime,
+	// the commit-graph is seen as stale and written again next time.
+	d.logger.Debug("wrote commit-graph", "repo", repo, "was", state)
+	fp := filepath.Join(rr.Path, commitGraphRefsFile)
+	if err := os.MkdirAll(filepath.Dir(fp), os.ModePerm); err != nil {
+		return err
+	}
+
+	return os.WriteFile(fp, []byte(refsFingerprint(refs)+"\n"), 0o644) // nolint: gosec
+}
+
+// commitGraphState returns the commit-graph state of the repository at path,
+// and its refs.
+func commitGraphState(ctx context.Context, path string) (string, string, error) {
+	out, err := git.NewCommand("for-each-ref", "--format=%(objectname) %(refname)").WithContext(ctx).RunInDir(path)
+	if err != nil {
+		return "", "", err
+	}
+	refs := string(out)
+
+	if !hasCommitGraph(path) {
+		return CommitGraphMissing, refs, nil
+	}
+
+	written, err := readOneline(filepath.Join(path, commitGraphRefsFile))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return "", refs, err
+	}
+
+	if written != refsFingerprint(refs) {
+		return CommitGraphStale, refs, nil
+	}
+
+	return CommitGraphUpToDate, refs, nil
+}
+
+// hasCommitGraph returns whether the repository at path has a commit-graph,
+// either a single file or a chain of incremental layers.
+func hasCommitGraph(path string) bool {
+	for _, f := range []string{
+		filepath.Join(path, "objects", "info", "commit-graph"),
+		filepath.Join(path, "objects", "info", "commit-graphs", "commit-graph-chain"),
+	} {
+		if _, err := os.Stat(f); err == nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// refsFingerprint returns a fingerprint of the refs listed by for-each-ref.
+func refsFingerprint(refs string) string {
+	sum := sha256.Sum256([]byte(refs))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,302 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/soft-serve/git"
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/db/models"
+	"github.com/charmbracelet/soft-serve/pkg/hooks"
+	"github.com/charmbracelet/soft-serve/pkg/proto"
+)
+
+// Commit status states.
+const (
+	CommitStatePending = "pending"
+	CommitStateSuccess = "success"
+	CommitStateFailure = "failure"
+	CommitStateError   = "error"
+)
+
+// Repository setting keys for required commit statuses.
+const (
+	settingRequiredStatusBranches = "required_status_branches"
+	settingRequiredStatusContexts = "required_status_contexts"
+)
+
+// commitStatusContextMaxLen is the maximum length of a commit status context.
+const commitStatusContextMaxLen = 255
+
+// commitStatusDescriptionMaxLen is the maximum length of a commit status
+// description.
+const commitStatusDescriptionMaxLen = 1024
+
+// ErrInvalidCommitStatus is returned when a commit status is invalid.
+var ErrInvalidCommitStatus = errors.New("invalid commit status")
+
+// CommitStatus is a commit status to report.
+type CommitStatus struct {
+	// Context identifies the system reporting the status, e.g. "ci/build".
+	Context string
+	// State is one of pending, success, failure, or error.
+	State string
+	// Description is a short description of the status.
+	Description string
+	// TargetURL is a link to the details of the status, e.g. the CI job.
+	TargetURL string
+}
+
+// Validate returns an error if the commit status is invalid.
+func (s CommitStatus) Validate() error {
+	switch {
+	case s.Context == "" || utf8.RuneCountInString(s.Context) > commitStatusContextMaxLen ||
+		strings.ContainsAny(s.Context, ",\n\r"):
+		return fmt.Errorf("%w: invalid context %q", ErrInvalidCommitStatus, s.Context)
+	case !isCommitState(s.State):
+		return fmt.Errorf("%w: invalid state %q, expected one of pending, success, failure, or error", ErrInvalidCommitStatus, s.State)
+	case utf8.RuneCountInString(s.Description) > commitStatusDescriptionMaxLen:
+		return fmt.Errorf("%w: description is too long", ErrInvalidCommitStatus)
+	}
+
+	if s.TargetURL != "" {
+		u, err := url.Parse(s.TargetURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("%w: invalid target URL %q", ErrInvalidCommitStatus, s.TargetURL)
+		}
+	}
+
+	return nil
+}
+
+func isCommitState(state string) bool {
+	switch state {
+	case CommitStatePending, CommitStateSuccess, CommitStateFailure, CommitStateError:
+		return true
+	}
+	return false
+}
+
+// CombinedCommitState returns the overall state of commit statuses: failure
+// if any status failed or errored, pending if any is pending, and success if
+// all succeeded. No statuses have an empty state.
+func CombinedCommitState(statuses []models.CommitStatus) string {
+	var state string
+	for _, s := range statuses {
+		switch s.State {
+		case CommitStateFailure, CommitStateError:
+			return CommitStateFailure
+		case CommitStatePending:
+			state = CommitStatePending
+		case CommitStateSuccess:
+			if state == "" {
+				state = CommitStateSuccess
+			}
+		}
+	}
+
+	return state
+}
+
+// ResolveCommit returns the full hash of the commit a revision points to.
+func (d *Backend) ResolveCommit(ctx context.Context, repo string, rev string) (string, error) {
+	r, err := d.Repository(ctx, repo)
+	if err != nil {
+		return "", err
+	}
+
+	rr, err := r.Open()
+	if err != nil {
+		return "", err
+	}
+
+	return resolveCommit(ctx, rr.Path, rev)
+}
+
+func resolveCommit(ctx context.Context, path string, rev string) (string, error) {
+	if rev == "" || strings.HasPrefix(rev, "-") || strings.ContainsAny(rev, " \t\n\r") {
+		return "", proto.ErrCommitNotFound
+	}
+
+	out, err := git.NewCommand("rev-parse", "--verify", "--quiet", rev+"^{commit}").WithContext(ctx).RunInDir(path)
+	if err != nil {
+		return "", proto.ErrCommitNotFound
+	}
+
+	return strings.TrimSpace(string(out)), nil
+}
+
+// SetCommitStatus reports the status of a commit for the status context,
+// replacing the previous status of the context. It returns the full hash of
+// the commit.
+func (d *Backend) SetCommitStatus(ctx context.Context, repo string, user proto.User, rev string, status CommitStatus) (string, error) {
+	if err := status.Validate(); err != nil {
+		return "", err
+	}
+
+	r, err := d.Repository(ctx, repo)
+	if err != nil {
+		return "", err
+	}
+
+	rr, err := r.Open()
+	if err != nil {
+		return "", err
+	}
+
+	sha, err := resolveCommit(ctx, rr.Path, rev)
+	if err != nil {
+		return "", err
+	}
+
+	var userID int64
+	if user != nil {
+		userID = user.ID()
+	}
+
+	if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		return d.store.SetCommitStatus(ctx, tx, r.ID(), userID, models.CommitStatus{
+			CommitSHA:   sha,
+			Context:     status.Context,
+			State:       status.State,
+			Description: status.Description,
+			TargetURL:   status.TargetURL,
+		})
+	}); err != nil {
+		return "", db.WrapError(err)
+	}
+
+	return sha, nil
+}
+
+// CommitStatuses returns the latest status of each context of a commit,
+// latest first. sha must be a full commit hash.
+func (d *Backend) CommitStatuses(ctx context.Context, repo string, sha string) ([]models.CommitStatus, error) {
+	r, err := d.Repository(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	var statuses []models.CommitStatus
+	if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		statuses, err = d.store.GetCommitStatuses(ctx, tx, r.ID(), sha)
+		return err
+	}); err != nil {
+		return nil, db.WrapError(err)
+	}
+
+	return statuses, nil
+}
+
+// RequiredStatuses is the required commit statuses rule of a repository.
+type RequiredStatuses struct {
+	// Branches are the branch name patterns that only accept commits with
+	// passing statuses. Patterns use the same syntax as path.Match.
+	Branches []string
+	// Contexts are the status contexts that must succeed. With no contexts,
+	// the commit needs at least one status and all of them must succeed.
+	Contexts []string
+}
+
+// RequiredStatuses returns the required commit statuses rule of a
+// repository.
+func (d *Backend) RequiredStatuses(ctx context.Context, repo string) (RequiredStatuses, error) {
+	var rs RequiredStatuses
+	settings, err := d.RepoSettings(ctx, repo)
+	if err != nil {
+		return rs, err
+	}
+
+	rs.Branches, err = d.branchPatterns(ctx, repo, settingRequiredStatusBranches)
+	if err != nil {
+		return rs, err
+	}
+
+	if v := settings[settingRequiredStatusContexts]; v != "" {
+		rs.Contexts = strings.Split(v, ",")
+	}
+
+	return rs, nil
+}
+
+// SetRequiredStatuses sets the required commit statuses rule of a
+// repository. No branches disables the rule.
+func (d *Backend) SetRequiredStatuses(ctx context.Context, repo string, rs RequiredStatuses) error {
+	for _, c := range rs.Contexts {
+		if err := (CommitStatus{Context: c, State: CommitStateSuccess}).Validate(); err != nil {
+			return err
+		}
+	}
+
+	if err := d.setBranchPatterns(ctx, repo, settingRequiredStatusBranches, rs.Branches); err != nil {
+		return err
+	}
+
+	return d.SetRepoSettings(ctx, repo, map[string]string{
+		settingRequiredStatusContexts: strings.Join(rs.Contexts, ","),
+	})
+}
+
+// checkRequiredStatuses returns an error if the push updates a branch
+// requiring passing statuses to a commit without them. Deleting the branch is
+// allowed. It's meant to be called from the pre-receive hook.
+func (d *Backend) checkRequiredStatuses(ctx context.Context, repo string, args []hooks.HookArg) error {
+	rs, err := d.RequiredStatuses(ctx, repo)
+	if err != nil || len(rs.Branches) == 0 {
+		return err
+	}
+
+	for _, arg := range args {
+		branch, ok := matchBranch(rs.Branches, arg.RefName)
+		if !ok || git.IsZeroHash(arg.NewSha) {
+			continue
+		}
+
+		statuses, err := d.CommitStatuses(ctx, repo, arg.NewSha)
+		if err != nil {
+			return err
+		}
+
+		if missing := missingStatuses(rs.Contexts, statuses); len(missing) > 0 {
+			return fmt.Errorf("commit %s can't be pushed to %s, the branch requires passing statuses: %s",
+				arg.NewSha, branch, strings.Join(missing, ", "))
+		}
+	}
+
+	return nil
+}
+
+// missingStatuses returns a description of the required statuses that didn't
+// succeed. With no required contexts, every status must succeed.
+func missingStatuses(contexts []string, statuses []models.CommitStatus) []string {
+	states := map[string]string{}
+	for _, s := range statuses {
+		states[s.Context] = s.State
+	}
+
+	if len(contexts) == 0 {
+		if len(statuses) == 0 {
+			return []string{"no statuses reported"}
+		}
+		for _, s := range statuses {
+			contexts = append(contexts, s.Context)
+		}
+	}
+
+	var missing []string
+	for _, c := range contexts {
+		switch state := states[c]; state {
+		case CommitStateSuccess:
+		case "":
+			missing = append(missing, c+" is missing")
+		default:
+			missing = append(missing, c+" is "+state)
+		}
+	}
+
+	return missing
+}
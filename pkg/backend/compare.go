@@ -0,0 +1,133 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/charmbracelet/soft-serve/git"
+	"github.com/charmbracelet/soft-serve/pkg/proto"
+	lru "github.com/hashicorp/golang-lru/v2"
+)
+
+// compareCacheSize is the number of diffs kept by the compare cache.
+const compareCacheSize = 64
+
+// Comparison is the diff between two revisions of a repository.
+type Comparison struct {
+	// Base is the full hash of the base commit.
+	Base string
+	// Head is the full hash of the head commit.
+	Head string
+	// MergeBase is the full hash of the common ancestor the diff starts from
+	// when comparing from the merge base, and empty otherwise.
+	MergeBase string
+	// Diff is the diff from the base, or the merge base, to the head.
+	Diff *git.Diff
+}
+
+// compareCache caches the diffs between two commits of each repository.
+// Commits are immutable, so entries never go stale and are only evicted
+// when the cache is full.
+type compareCache struct {
+	once  sync.Once
+	diffs *lru.Cache[string, *git.Diff]
+}
+
+func (c *compareCache) cache() *lru.Cache[string, *git.Diff] {
+	c.once.Do(func() {
+		c.diffs, _ = lru.New[string, *git.Diff](compareCacheSize)
+	})
+	return c.diffs
+}
+
+func (c *compareCache) get(repo string, from string, to string) (*git.Diff, bool) {
+	return c.cache().Get(repo + ":" + from + ":" + to)
+}
+
+func (c *compareCache) set(repo string, from string, to string, diff *git.Diff) {
+	c.cache().Add(repo+":"+from+":"+to, diff)
+}
+
+// Compare returns the diff between the base and head revisions of a
+// repository. With fromMergeBase, like "base...head", the diff starts from
+// the common ancestor of both revisions instead of the base, showing only
+// the changes of the head. It returns proto.ErrCommitNotFound if either
+// revision doesn't resolve to a commit, and proto.ErrDeniedPath if the
+// history of the repository has a denied path. The revisions can be commits
+// no ref points to anymore, so the files at denied paths are also left out of
+// the diff.
+//
+// Large diffs are truncated, see git.Repository.CompareDiff.
+func (d *Backend) Compare(ctx context.Context, repo string, base string, head string, fromMergeBase bool) (Comparison, error) {
+	var cmp Comparison
+	r, err := d.Repository(ctx, repo)
+	if err != nil {
+		return cmp, err
+	}
+
+	if err := d.CheckDeniedPaths(ctx, r.Name()); err != nil {
+		return cmp, err
+	}
+
+	denied, err := d.DeniedPaths(ctx, r.Name())
+	if err != nil {
+		return cmp, err
+	}
+
+	rr, err := r.Open()
+	if err != nil {
+		return cmp, err
+	}
+
+	cmp.Base, err = resolveCommit(ctx, rr.Path, base)
+	if err != nil {
+		return cmp, err
+	}
+
+	cmp.Head, err = resolveCommit(ctx, rr.Path, head)
+	if err != nil {
+		return cmp, err
+	}
+
+	from := cmp.Base
+	if fromMergeBase {
+		cmp.MergeBase, err = rr.MergeBase(cmp.Base, cmp.Head)
+		if errors.Is(err, git.ErrNoMergeBase) {
+			return cmp, proto.ErrNoMergeBase
+		} else if err != nil {
+			return cmp, err
+		}
+		from = cmp.MergeBase
+	}
+
+	diff, ok := d.compare.get(r.Name(), from, cmp.Head)
+	if !ok {
+		diff, err = rr.CompareDiff(from, cmp.Head)
+		if err != nil {
+			return cmp, err
+		}
+
+		d.compare.set(r.Name(), from, cmp.Head, diff)
+	}
+
+	cmp.Diff = withoutDeniedPaths(diff, denied)
+	return cmp, nil
+}
+
+// withoutDeniedPaths returns the diff without the files at denied paths, or
+// the diff itself if it has none. Cached diffs are shared, so they're never
+// modified.
+func withoutDeniedPaths(diff *git.Diff, denied []string) *git.Diff {
+	files := make([]*git.DiffFile, 0, len(diff.Files))
+	for _, f := range diff.Files {
+		if !IsDeniedPath(denied, f.Name) && !IsDeniedPath(denied, f.OldName()) {
+			files = append(files, f)
+		}
+	}
+	if len(files) == len(diff.Files) {
+		return diff
+	}
+
+	return &git.Diff{Diff: diff.Diff, Files: files}
+}
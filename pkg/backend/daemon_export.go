@@ -0,0 +1,33 @@
+package backend
+
+import (
+	"context"
+	"strconv"
+)
+
+// settingDaemonExport is the repository setting key to serve a repository
+// over the git:// protocol.
+const settingDaemonExport = "git_daemon_export"
+
+// DaemonExport returns whether a repository is exported over the git://
+// protocol. Repositories aren't exported by default.
+func (d *Backend) DaemonExport(ctx context.Context, repo string) (bool, error) {
+	settings, err := d.RepoSettings(ctx, repo)
+	if err != nil {
+		return false, err
+	}
+
+	export, _ := strconv.ParseBool(settings[settingDaemonExport])
+	return export, nil
+}
+
+// SetDaemonExport sets whether a repository is exported over the git://
+// protocol. Private repositories are never served, even if exported.
+func (d *Backend) SetDaemonExport(ctx context.Context, repo string, export bool) error {
+	var value string
+	if export {
+		value = "true"
+	}
+
+	return d.SetRepoSettings(ctx, repo, map[string]string{settingDaemonExport: value})
+}
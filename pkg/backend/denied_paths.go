@@ -0,0 +1,198 @@
+package backend
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/charmbracelet/soft-serve/git"
+	"github.com/charmbracelet/soft-serve/pkg/hooks"
+	"github.com/charmbracelet/soft-serve/pkg/proto"
+)
+
+// settingDeniedPaths is the repository setting key for the comma-separated
+// paths that must not be served.
+const settingDeniedPaths = "denied_paths"
+
+// deniedPathsCache caches the result of the denied paths history check of
+// each repository until its refs or denied paths change.
+type deniedPathsCache struct {
+	mu      sync.Mutex
+	entries map[string]deniedPathsEntry
+}
+
+// deniedPathsEntry is a cached denied paths check result.
+type deniedPathsEntry struct {
+	// key identifies the refs and denied paths the check ran against.
+	key string
+	// commit is a commit touching a denied path, empty if there's none.
+	commit string
+}
+
+func (c *deniedPathsCache) get(repo string, key string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[repo]
+	if !ok || e.key != key {
+		return "", false
+	}
+
+	return e.commit, true
+}
+
+func (c *deniedPathsCache) set(repo string, key string, commit string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries == nil {
+		c.entries = map[string]deniedPathsEntry{}
+	}
+	c.entries[repo] = deniedPathsEntry{key: key, commit: commit}
+}
+
+// DeniedPaths returns the paths that must not be present in the history of a
+// repository for it to be served.
+func (d *Backend) DeniedPaths(ctx context.Context, repo string) ([]string, error) {
+	settings, err := d.RepoSettings(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	v := settings[settingDeniedPaths]
+	if v == "" {
+		return nil, nil
+	}
+
+	return strings.Split(v, ","), nil
+}
+
+// IsDeniedPath returns whether p, a path relative to the repository root, is
+// one of the denied paths or is under one of them. It's meant for serving
+// single files, which can come from commits no ref points to anymore.
+func IsDeniedPath(denied []string, p string) bool {
+	p = path.Clean(strings.Trim(p, "/"))
+	for _, d := range denied {
+		if p == d || strings.HasPrefix(p, d+"/") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// SetDeniedPaths sets the paths that must not be present in the history of a
+// repository for it to be served. Paths are relative to the repository root
+// and match a file or a directory. No paths removes the setting.
+func (d *Backend) SetDeniedPaths(ctx context.Context, repo string, paths []string) error {
+	cleaned := make([]string, 0, len(paths))
+	for _, p := range paths {
+		c := path.Clean(strings.Trim(p, "/"))
+		if p == "" || c == "." || c == ".." || strings.HasPrefix(c, "../") || strings.Contains(c, ",") {
+			return fmt.Errorf("invalid path %q", p)
+		}
+		cleaned = append(cleaned, c)
+	}
+
+	return d.SetRepoSettings(ctx, repo, map[string]string{
+		settingDeniedPaths: strings.Join(cleaned, ","),
+	})
+}
+
+// CheckDeniedPaths returns proto.ErrDeniedPath if a commit reachable from any
+// ref of the repository touches one of its denied paths. It's meant to be
+// called before serving clones, fetches, and archives.
+//
+// Walking the whole history is expensive on large repositories, so the
+// result is cached until the refs or the denied paths change.
+func (d *Backend) CheckDeniedPaths(ctx context.Context, repo string) error {
+	paths, err := d.DeniedPaths(ctx, repo)
+	if err != nil || len(paths) == 0 {
+		return err
+	}
+
+	r, err := d.Repository(ctx, repo)
+	if err != nil {
+		return err
+	}
+
+	rr, err := r.Open()
+	if err != nil {
+		return err
+	}
+
+	refs, err := git.NewCommand("for-each-ref", "--format=%(objectname) %(refname)").WithContext(ctx).RunInDir(rr.Path)
+	if err != nil {
+		return err
+	}
+
+	sum := sha256.Sum256(append(refs, strings.Join(paths, ",")...))
+	key := hex.EncodeToString(sum[:])
+	commit, ok := d.deniedPaths.get(r.Name(), key)
+	if !ok {
+		args := []string{"rev-list", "--all", "--max-count=1", "--"}
+		for _, p := range paths {
+			args = append(args, ":(literal)"+p)
+		}
+
+		out, err := git.NewCommand(args...).WithContext(ctx).RunInDir(rr.Path)
+		if err != nil {
+			return err
+		}
+
+		commit = strings.TrimSpace(string(out))
+		d.deniedPaths.set(r.Name(), key, commit)
+	}
+
+	if commit != "" {
+		d.logger.Warn("refusing to serve repository with a denied path in its history", "repo", r.Name(), "commit", commit)
+		return proto.ErrDeniedPath
+	}
+
+	return nil
+}
+
+// checkDeniedPaths returns an error if the pushed commits touch one of the
+// denied paths of the repository. It's meant to be called from the
+// pre-receive hook.
+func (d *Backend) checkDeniedPaths(ctx context.Context, repo string, args []hooks.HookArg) error {
+	paths, err := d.DeniedPaths(ctx, repo)
+	if err != nil || len(paths) == 0 {
+		return err
+	}
+
+	r, err := d.Repository(ctx, repo)
+	if err != nil {
+		return err
+	}
+
+	rr, err := r.Open()
+	if err != nil {
+		return err
+	}
+
+	for _, arg := range args {
+		if git.IsZeroHash(arg.NewSha) {
+			continue
+		}
+
+		// Only check the commits the push introduces.
+		revs := []string{"rev-list", "--max-count=1", arg.NewSha, "--not", "--all", "--"}
+		for _, p := range paths {
+			revs = append(revs, ":(literal)"+p)
+		}
+
+		out, err := git.NewCommand(revs...).WithContext(ctx).RunInDir(rr.Path)
+		if err != nil {
+			return err
+		}
+
+		if commit := strings.TrimSpace(string(out)); commit != "" {
+			return fmt.Errorf("commit %s touches a denied path, remove it from the history of %s", commit, arg.RefName)
+		}
+	}
+
+	return nil
+}
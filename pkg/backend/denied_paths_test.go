@@ -0,0 +1,47 @@
+package backend
+
+import (
+	"testing"
+
+	gitm "github.com/aymanbagabas/git-module"
+	"github.com/charmbracelet/soft-serve/git"
+)
+
+func TestIsDeniedPath(t *testing.T) {
+	denied := []string{"secrets", "config/prod.env"}
+	cases := map[string]bool{
+		"secrets":          true,
+		"secrets/key.txt":  true,
+		"/secrets/a/b.txt": true,
+		"config/prod.env":  true,
+		"secrets.txt":      false,
+		"config":           false,
+		"config/dev.env":   false,
+		"README.md":        false,
+	}
+
+	for p, want := range cases {
+		if got := IsDeniedPath(denied, p); got != want {
+			t.Errorf("IsDeniedPath(%q) = %v, want %v", p, got, want)
+		}
+	}
+}
+
+func TestWithoutDeniedPaths(t *testing.T) {
+	file := func(name string) *git.DiffFile {
+		return &git.DiffFile{DiffFile: &gitm.DiffFile{Name: name}}
+	}
+	diff := &git.Diff{Diff: &gitm.Diff{}, Files: []*git.DiffFile{file("README.md"), file("secrets/key.txt")}}
+
+	got := withoutDeniedPaths(diff, []string{"secrets"})
+	if len(got.Files) != 1 || got.Files[0].Name != "README.md" {
+		t.Errorf("withoutDeniedPaths() files = %v, want only README.md", got.Files)
+	}
+	// Cached diffs are shared, they must be left as is.
+	if len(diff.Files) != 2 {
+		t.Errorf("withoutDeniedPaths() modified the diff, it has %d files", len(diff.Files))
+	}
+	if got := withoutDeniedPaths(diff, []string{"docs"}); got != diff {
+		t.Errorf("withoutDeniedPaths() = %p, want the diff itself %p", got, diff)
+	}
+}
@@ -0,0 +1,265 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/soft-serve/pkg/access"
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/db/models"
+	"github.com/charmbracelet/soft-serve/pkg/proto"
+	"github.com/charmbracelet/soft-serve/pkg/sshutils"
+	gossh "golang.org/x/crypto/ssh"
+)
+
+// DeployTokenPrefix is the prefix of deploy tokens, telling them apart from
+// user access tokens.
+const DeployTokenPrefix = "ssd_"
+
+// deployTokenTouchInterval is how often the last use of a deploy token is
+// recorded.
+const deployTokenTouchInterval = time.Minute
+
+// DeployToken is a token granting access to a single repository, without a
+// user. It's used over HTTP, and over SSH with the key it's mapped to.
+type DeployToken struct {
+	// Name is the name of the token, unique in its repository.
+	Name string
+	// AccessLevel is the access level of the token to the repository.
+	AccessLevel access.AccessLevel
+	// PublicKey is the SSH key the token is mapped to, if any.
+	PublicKey gossh.PublicKey
+	// ExpiresAt is when the token expires, zero if it doesn't.
+	ExpiresAt time.Time
+	// LastUsedAt is when the token was last used, zero if it never was.
+	LastUsedAt time.Time
+	// CreatedAt is when the token was created.
+	CreatedAt time.Time
+}
+
+// IsDeployToken returns whether a token looks like a deploy token.
+func IsDeployToken(token string) bool {
+	return strings.HasPrefix(token, DeployTokenPrefix)
+}
+
+// deployTokenExpired returns whether a deploy token is expired.
+func deployTokenExpired(m models.DeployToken) bool {
+	return m.ExpiresAt.Valid && !m.ExpiresAt.Time.After(time.Now())
+}
+
+// CreateDeployToken creates a deploy token granting read-only or read-write
+// access to a repository, and returns the token. Only its hash is stored. The
+// token can be mapped to an SSH key, which then has the access of the token
+// until it's revoked or expires. A zero expiry means the token doesn't
+// expire.
+func (d *Backend) CreateDeployToken(ctx context.Context, repo string, name string, level access.AccessLevel, pk gossh.PublicKey, expiresAt time.Time) (string, error) {
+	if level != access.ReadOnlyAccess && level != access.ReadWriteAccess {
+		return "", fmt.Errorf("%w: deploy tokens must be read-only or read-write", access.ErrInvalidAccessLevel)
+	}
+
+	name = strings.TrimSpace(name)
+	if name == "" || strings.ContainsAny(name, " \t\n") {
+		return "", fmt.Errorf("invalid deploy token name %q", name)
+	}
+
+	r, err := d.Repository(ctx, repo)
+	if err != nil {
+		return "", err
+	}
+
+	var fp, ak string
+	if pk != nil {
+		fp, ak = gossh.FingerprintSHA256(pk), sshutils.MarshalAuthorizedKey(pk)
+	}
+
+	token := DeployTokenPrefix + strings.TrimPrefix(GenerateToken(), "ss_")
+	if err := db.WrapError(d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		return d.store.CreateDeployToken(ctx, tx, r.Name(), name, HashToken(token), level, fp, ak, expiresAt)
+	})); err != nil {
+		if errors.Is(err, db.ErrDuplicateKey) {
+			return "", proto.ErrDeployTokenExist
+		}
+		return "", err
+	}
+
+	details := fmt.Sprintf("%s %s", name, level)
+	if fp != "" {
+		details += " " + fp
+	}
+	if err := d.recordAuditEvent(ctx, AuditActionDeployTokenCreated, r, nil, details); err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// RevokeDeployToken deletes the named deploy token of a repository. It
+// returns proto.ErrDeployTokenNotFound if there's no such token.
+func (d *Backend) RevokeDeployToken(ctx context.Context, repo string, name string) error {
+	r, err := d.Repository(ctx, repo)
+	if err != nil {
+		return err
+	}
+
+	if err := db.WrapError(d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		return d.store.DeleteDeployToken(ctx, tx, r.Name(), name)
+	})); err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return proto.ErrDeployTokenNotFound
+		}
+		return err
+	}
+
+	return d.recordAuditEvent(ctx, AuditActionDeployTokenRevoked, r, nil, name)
+}
+
+// DeployTokens returns the deploy tokens of a repository, oldest first.
+func (d *Backend) DeployTokens(ctx context.Context, repo string) ([]DeployToken, error) {
+	r, err := d.Repository(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	var ms []models.DeployToken
+	if err := d.retryTx(ctx, "deploy_tokens", func(tx *db.Tx) error {
+		var err error
+		ms, err = d.store.GetDeployTokens(ctx, tx, r.Name())
+		return err
+	}); err != nil {
+		return nil, db.WrapError(err)
+	}
+
+	tokens := make([]DeployToken, 0, len(ms))
+	for _, m := range ms {
+		t := DeployToken{
+			Name:        m.Name,
+			AccessLevel: m.AccessLevel,
+			CreatedAt:   m.CreatedAt,
+		}
+		if m.PublicKey != "" {
+			pk, _, err := sshutils.ParseAuthorizedKey(m.PublicKey)
+			if err != nil {
+				d.logger.Error("invalid deploy token key", "repo", r.Name(), "token", m.Name, "err", err)
+			}
+			t.PublicKey = pk
+		}
+		if m.ExpiresAt.Valid {
+			t.ExpiresAt = m.ExpiresAt.Time
+		}
+		if m.LastUsedAt.Valid {
+			t.LastUsedAt = m.LastUsedAt.Time
+		}
+
+		tokens = append(tokens, t)
+	}
+
+	return tokens, nil
+}
+
+// DeployTokenAccessLevel returns the access level a deploy token grants to a
+// repository. It returns proto.ErrTokenNotFound if the token doesn't belong
+// to the repository, and proto.ErrTokenExpired if it expired. Deploy tokens
+// have no access in lockdown.
+func (d *Backend) DeployTokenAccessLevel(ctx context.Context, repo string, token string) (access.AccessLevel, error) {
+	r, err := d.Repository(ctx, repo)
+	if err != nil {
+		if errors.Is(err, proto.ErrRepoNotFound) {
+			return access.NoAccess, proto.ErrTokenNotFound
+		}
+		return -1, err
+	}
+
+	var m models.DeployToken
+	if err := d.retryTx(ctx, "deploy_token_by_token", func(tx *db.Tx) error {
+		var err error
+		m, err = d.store.GetDeployTokenByToken(ctx, tx, HashToken(token))
+		return err
+	}); err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return access.NoAccess, proto.ErrTokenNotFound
+		}
+		return -1, db.WrapError(err)
+	}
+
+	if m.RepoID != r.ID() {
+		return access.NoAccess, proto.ErrTokenNotFound
+	}
+	if deployTokenExpired(m) {
+		return access.NoAccess, proto.ErrTokenExpired
+	}
+
+	locked, err := d.lockdown(ctx)
+	if err != nil {
+		return -1, err
+	}
+	if locked {
+		return access.NoAccess, nil
+	}
+
+	d.touchDeployToken(ctx, m)
+
+	return m.AccessLevel, nil
+}
+
+// deployKeyAccessLevel returns the highest access level the deploy tokens
+// mapped to the public key grant on the repository.
+func (d *Backend) deployKeyAccessLevel(ctx context.Context, r proto.Repository, pk gossh.PublicKey) (access.AccessLevel, error) {
+	level := access.NoAccess
+	if pk == nil {
+		return level, nil
+	}
+
+	ms, err := d.deployTokensByKey(ctx, pk)
+	if err != nil {
+		return -1, err
+	}
+
+	for _, m := range ms {
+		if m.RepoID == r.ID() {
+			d.touchDeployToken(ctx, m)
+			level = max(level, m.AccessLevel)
+		}
+	}
+
+	return level, nil
+}
+
+// deployTokensByKey returns the unexpired deploy tokens mapped to a public
+// key.
+func (d *Backend) deployTokensByKey(ctx context.Context, pk gossh.PublicKey) ([]models.DeployToken, error) {
+	var ms []models.DeployToken
+	if err := d.retryTx(ctx, "deploy_tokens_by_fingerprint", func(tx *db.Tx) error {
+		var err error
+		ms, err = d.store.GetDeployTokensByFingerprint(ctx, tx, gossh.FingerprintSHA256(pk))
+		return err
+	}); err != nil {
+		return nil, db.WrapError(err)
+	}
+
+	tokens := ms[:0]
+	for _, m := range ms {
+		if !deployTokenExpired(m) {
+			tokens = append(tokens, m)
+		}
+	}
+
+	return tokens, nil
+}
+
+// touchDeployToken records the use of a deploy token, at most once per
+// deployTokenTouchInterval. Failures are only logged.
+func (d *Backend) touchDeployToken(ctx context.Context, m models.DeployToken) {
+	now := time.Now()
+	if m.LastUsedAt.Valid && now.Sub(m.LastUsedAt.Time) < deployTokenTouchInterval {
+		return
+	}
+
+	if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		return d.store.TouchDeployToken(ctx, tx, m.ID, now, now.Add(-deployTokenTouchInterval))
+	}); err != nil {
+		d.logger.Error("failed to record deploy token use", "token", m.Name, "err", err)
+	}
+}
@@ -0,0 +1,295 @@
+package backend
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/soft-serve/git"
+	"github.com/charmbracelet/soft-serve/pkg/hooks"
+)
+
+// Repository setting keys for file mode restrictions.
+const (
+	settingDeniedFileModes   = "denied_file_modes"
+	settingFileModesExempt   = "file_modes_exempt"
+	settingFileModesWarnOnly = "file_modes_warn_only"
+)
+
+// maxReportedFileModeEntries is the number of entries with denied modes
+// reported to the pusher.
+const maxReportedFileModeEntries = 10
+
+// FileMode is a kind of tree entry that can be denied in a repository.
+type FileMode string
+
+// File modes that can be denied.
+const (
+	// FileModeSymlink is a symbolic link.
+	FileModeSymlink FileMode = "symlink"
+	// FileModeSubmodule is a gitlink, the commit of a submodule.
+	FileModeSubmodule FileMode = "submodule"
+	// FileModeExecutable is a file with the executable bit set.
+	FileModeExecutable FileMode = "executable"
+)
+
+// FileModes are the file modes that can be denied.
+var FileModes = []FileMode{FileModeSymlink, FileModeSubmodule, FileModeExecutable}
+
+// fileModeOf returns the file mode of a git tree entry mode, like 120000.
+func fileModeOf(mode string) (FileMode, bool) {
+	switch mode {
+	case "120000":
+		return FileModeSymlink, true
+	case "160000":
+		return FileModeSubmodule, true
+	case "100755":
+		return FileModeExecutable, true
+	}
+
+	return "", false
+}
+
+// FileModeRules are the tree entry modes pushes must not introduce to a
+// repository.
+type FileModeRules struct {
+	// Denied are the file modes pushes must not introduce.
+	Denied []FileMode
+	// Exempt are the path patterns allowed to use denied modes. Patterns use
+	// the same syntax as path.Match and also match everything under a
+	// directory.
+	Exempt []string
+	// WarnOnly reports denied file modes without rejecting the push.
+	WarnOnly bool
+}
+
+// exempt returns whether a path is exempt from the rules.
+func (r FileModeRules) exempt(p string) bool {
+	return matchExemptPath(r.Exempt, p)
+}
+
+// matchExemptPath returns whether a path, or a directory it's under, matches
+// one of the exempt path patterns.
+func matchExemptPath(patterns []string, p string) bool {
+	for ; p != "." && p != "/"; p = path.Dir(p) {
+		for _, e := range patterns {
+			if ok, _ := path.Match(e, p); ok {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// cleanExemptPaths validates exempt path patterns and returns them cleaned.
+func cleanExemptPaths(patterns []string) ([]string, error) {
+	exempt := make([]string, 0, len(patterns))
+	for _, e := range patterns {
+		c := path.Clean(strings.Trim(e, "/"))
+		if e == "" || c == "." || strings.HasPrefix(c, "../") || strings.Contains(c, ",") {
+			return nil, fmt.Errorf("invalid exempt path %q", e)
+		}
+		if _, err := path.Match(c, ""); err != nil {
+			return nil, fmt.Errorf("invalid exempt path %q: %w", e, err)
+		}
+		exempt = append(exempt, c)
+	}
+
+	return exempt, nil
+}
+
+// denies returns whether the rules deny a file mode.
+func (r FileModeRules) denies(m FileMode) bool {
+	for _, d := range r.Denied {
+		if d == m {
+			return true
+		}
+	}
+
+	return false
+}
+
+// FileModeRules returns the file mode rules of a repository.
+func (d *Backend) FileModeRules(ctx context.Context, repo string) (FileModeRules, error) {
+	var r FileModeRules
+	settings, err := d.RepoSettings(ctx, repo)
+	if err != nil {
+		return r, err
+	}
+
+	if v := settings[settingDeniedFileModes]; v != "" {
+		for _, m := range strings.Split(v, ",") {
+			r.Denied = append(r.Denied, FileMode(m))
+		}
+	}
+	if v := settings[settingFileModesExempt]; v != "" {
+		r.Exempt = strings.Split(v, ",")
+	}
+	r.WarnOnly, _ = strconv.ParseBool(settings[settingFileModesWarnOnly])
+
+	return r, nil
+}
+
+// SetFileModeRules sets the file mode rules of a repository. No denied modes
+// disables the rules.
+func (d *Backend) SetFileModeRules(ctx context.Context, repo string, r FileModeRules) error {
+	denied := make([]string, 0, len(r.Denied))
+	for _, m := range r.Denied {
+		valid := false
+		for _, v := range FileModes {
+			valid = valid || m == v
+		}
+		if !valid {
+			return fmt.Errorf("invalid file mode %q, must be one of symlink, submodule, or executable", m)
+		}
+		denied = append(denied, string(m))
+	}
+
+	exempt, err := cleanExemptPaths(r.Exempt)
+	if err != nil {
+		return err
+	}
+
+	var warn string
+	if r.WarnOnly {
+		warn = "true"
+	}
+
+	return d.SetRepoSettings(ctx, repo, map[string]string{
+		settingDeniedFileModes:   strings.Join(denied, ","),
+		settingFileModesExempt:   strings.Join(exempt, ","),
+		settingFileModesWarnOnly: warn,
+	})
+}
+
+// fileModeEntry is a tree entry introduced by a commit with a denied mode.
+type fileModeEntry struct {
+	Commit string
+	Path   string
+	Mode   FileMode
+}
+
+// deniedFileModeEntries parses the raw output of git log, with each commit
+// starting with a NUL and its id, and returns the entries introducing a mode
+// the rules deny. Combined diffs of merge commits are supported.
+func deniedFileModeEntries(out []byte, r FileModeRules) []fileModeEntry {
+	var entries []fileModeEntry
+	var commit string
+	s := bufio.NewScanner(bytes.NewReader(out))
+	s.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for s.Scan() {
+		line := strings.TrimPrefix(s.Text(), "\x00")
+		if !strings.HasPrefix(line, ":") {
+			if line != "" {
+				commit = line
+			}
+			continue
+		}
+
+		meta, p, ok := strings.Cut(line, "\t")
+		if !ok {
+			continue
+		}
+
+		// Raw lines have a colon per parent, then the modes of the
+		// parents followed by the mode of the commit.
+		parents := len(meta) - len(strings.TrimLeft(meta, ":"))
+		fields := strings.Fields(strings.TrimLeft(meta, ":"))
+		if len(fields) <= parents {
+			continue
+		}
+
+		mode, ok := fileModeOf(fields[parents])
+		if !ok || !r.denies(mode) || r.exempt(p) {
+			continue
+		}
+
+		introduced := false
+		for _, m := range fields[:parents] {
+			introduced = introduced || m != fields[parents]
+		}
+		if introduced {
+			entries = append(entries, fileModeEntry{Commit: commit, Path: p, Mode: mode})
+		}
+	}
+
+	return entries
+}
+
+// checkFileModes returns an error if the pushed commits introduce a tree
+// entry with a denied mode, like a symlink, to the repository. Entries that
+// already had the mode are allowed. It's meant to be called from the
+// pre-receive hook.
+func (d *Backend) checkFileModes(ctx context.Context, stderr io.Writer, repo string, args []hooks.HookArg) error {
+	r, err := d.FileModeRules(ctx, repo)
+	if err != nil || len(r.Denied) == 0 {
+		return err
+	}
+
+	rp, err := d.Repository(ctx, repo)
+	if err != nil {
+		return err
+	}
+
+	rr, err := rp.Open()
+	if err != nil {
+		return err
+	}
+
+	var entries []fileModeEntry
+	seen := map[string]bool{}
+	reported := map[fileModeEntry]bool{}
+	for _, arg := range args {
+		if git.IsZeroHash(arg.NewSha) || seen[arg.NewSha] {
+			continue
+		}
+		seen[arg.NewSha] = true
+
+		// Only check the commits the push introduces. Merge commits are only
+		// checked for the entries they don't take from a parent.
+		out, err := git.NewCommand(
+			"-c", "core.quotePath=false", "log", "--raw", "--no-renames", "--no-abbrev", "-r",
+			"--diff-merges=dense-combined", "--format=%x00%H",
+			arg.NewSha, "--not", "--all",
+		).WithContext(ctx).RunInDir(rr.Path)
+		if err != nil {
+			return err
+		}
+
+		for _, e := range deniedFileModeEntries(out, r) {
+			if !reported[e] {
+				reported[e] = true
+				entries = append(entries, e)
+			}
+		}
+	}
+
+	if len(entries) == 0 {
+		return nil
+	}
+
+	prefix := "error"
+	if r.WarnOnly {
+		prefix = "warning"
+	}
+	for i, e := range entries {
+		if i == maxReportedFileModeEntries {
+			fmt.Fprintf(stderr, "%s: and %d more\n", prefix, len(entries)-i) // nolint: errcheck
+			break
+		}
+		fmt.Fprintf(stderr, "%s: %s %s is not allowed, added in commit %s\n", prefix, e.Mode, e.Path, e.Commit) // nolint: errcheck
+	}
+
+	if r.WarnOnly {
+		notePolicyWarning(ctx, policyFileModes)
+		return nil
+	}
+
+	return fmt.Errorf("push introduces %d entries with denied file modes; remove them or ask an admin to exempt their paths", len(entries))
+}
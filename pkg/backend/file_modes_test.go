@@ -0,0 +1,57 @@
+package backend
+
+import (
+	"testing"
+)
+
+func TestDeniedFileModeEntries(t *testing.T) {
+	const zero = "0000000000000000000000000000000000000000"
+	const blob = "3594e94c04db171e2767224db355f514b13715c5"
+	out := []byte("\x00c1\n\n" +
+		":000000 120000 " + zero + " " + blob + " A\tlink\n" +
+		":000000 160000 " + zero + " " + blob + " A\tvendor/lib\n" +
+		":100644 100755 " + blob + " " + blob + " M\trun.sh\n" +
+		":100755 100755 " + blob + " " + blob + " M\tbuild.sh\n" +
+		":000000 120000 " + zero + " " + blob + " A\tscripts/link\n" +
+		"\x00c2\n\n" +
+		"::120000 000000 120000 " + blob + " " + zero + " " + blob + " MA\tmerged\n" +
+		"::120000 120000 120000 " + blob + " " + blob + " " + blob + " MM\tkept\n")
+
+	r := FileModeRules{
+		Denied: []FileMode{FileModeSymlink, FileModeExecutable},
+		Exempt: []string{"scripts"},
+	}
+	got := deniedFileModeEntries(out, r)
+	want := []fileModeEntry{
+		{Commit: "c1", Path: "link", Mode: FileModeSymlink},
+		{Commit: "c1", Path: "run.sh", Mode: FileModeExecutable},
+		{Commit: "c2", Path: "merged", Mode: FileModeSymlink},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %+v, got %+v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected %+v, got %+v", want[i], got[i])
+		}
+	}
+}
+
+func TestFileModeRulesExempt(t *testing.T) {
+	r := FileModeRules{Exempt: []string{"scripts", "*.sh", "tools/*/bin"}}
+	cases := map[string]bool{
+		"scripts":            true,
+		"scripts/build":      true,
+		"scripts2/build":     false,
+		"run.sh":             true,
+		"src/run.sh":         false,
+		"tools/go/bin":       true,
+		"tools/go/bin/gofmt": true,
+		"tools/go/lib/gofmt": false,
+	}
+	for p, want := range cases {
+		if got := r.exempt(p); got != want {
+			t.Errorf("expected exempt(%q) to be %t", p, want)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/charmbracelet/soft-serve/git"
+	"github.com/charmbracelet/soft-serve/pkg/hooks"
+	"github.com/charmbracelet/soft-serve/pkg/proto"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var forcePushCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "soft_serve",
+	Subsystem: "git",
+	Name:      "force_pushes_total",
+	Help:      "The total number of ref updates that weren't fast-forwards",
+}, []string{"repo"})
+
+// forcedUpdates returns the refs of the updates that aren't fast-forwards,
+// where the old commit isn't an ancestor of the new one. Created and deleted
+// refs are never forced.
+func forcedUpdates(ctx context.Context, dir string, args []hooks.HookArg) map[string]bool {
+	forced := map[string]bool{}
+	for _, arg := range args {
+		if git.IsZeroHash(arg.OldSha) || git.IsZeroHash(arg.NewSha) {
+			continue
+		}
+		if !isAncestor(ctx, dir, arg.OldSha, arg.NewSha) {
+			forced[arg.RefName] = true
+		}
+	}
+
+	return forced
+}
+
+// recordForcePushes records the forced updates of a push in the audit log,
+// and returns their refs. It's meant to be called from the post-receive hook.
+func (d *Backend) recordForcePushes(ctx context.Context, repo string, user proto.User, args []hooks.HookArg) (map[string]bool, error) {
+	r, err := d.Repository(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	rr, err := r.Open()
+	if err != nil {
+		return nil, err
+	}
+
+	forced := forcedUpdates(ctx, rr.Path, args)
+	for _, arg := range args {
+		if !forced[arg.RefName] {
+			continue
+		}
+
+		details := fmt.Sprintf("%s %s %s", arg.RefName, arg.OldSha, arg.NewSha)
+		if err := d.recordAuditEvent(ctx, AuditActionForcePushed, r, user, details); err != nil {
+			return forced, err
+		}
+	}
+
+	return forced, nil
+}
+
+// countForcePushes counts the forced updates of a post-receive hook execution.
+// Hooks run in their own process, so they're counted after the fact from the
+// execution report.
+func (d *Backend) countForcePushes(ctx context.Context, e hooks.Execution) {
+	if e.Hook != hooks.PostReceiveHook || len(e.Updates) == 0 {
+		return
+	}
+
+	r, err := d.Repository(ctx, e.Repo)
+	if err != nil {
+		d.logger.Error("error finding repository", "repo", e.Repo, "err", err)
+		return
+	}
+
+	rr, err := r.Open()
+	if err != nil {
+		d.logger.Error("error opening repository", "repo", e.Repo, "err", err)
+		return
+	}
+
+	if n := len(forcedUpdates(ctx, rr.Path, e.Updates)); n > 0 {
+		forcePushCounter.WithLabelValues(e.Repo).Add(float64(n))
+	}
+}
@@ -0,0 +1,268 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/charmbracelet/soft-serve/git"
+	"github.com/charmbracelet/soft-serve/pkg/access"
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/db/models"
+	"github.com/charmbracelet/soft-serve/pkg/hooks"
+	"github.com/charmbracelet/soft-serve/pkg/proto"
+	"github.com/robfig/cron/v3"
+)
+
+// SkipFreezeOption is the push option admins can use to push during a freeze
+// window, e.g. `git push -o skip-freeze`.
+const SkipFreezeOption = "skip-freeze"
+
+// maxFreezeExtensions is the number of back-to-back schedule times followed
+// to find when a freeze lifts. A freeze still going on after that many, like
+// one matching every minute, lifts at an unknown time.
+const maxFreezeExtensions = 1 << 16
+
+// FreezeWindow is a schedule during which pushes to some refs of a
+// repository are denied, like a change freeze on release branches on
+// Fridays.
+type FreezeWindow struct {
+	// Name identifies the window in the repository.
+	Name string
+	// Refs are the patterns of the frozen refs, with the same syntax as
+	// path.Match. Patterns starting with refs/ match full ref names, the
+	// others match branch names. No patterns freeze every ref.
+	Refs []string
+	// Schedule is a cron expression of the times the window starts, with an
+	// optional CRON_TZ= prefix, e.g. "* * * * FRI" for every minute of
+	// Fridays.
+	Schedule string
+	// Duration is how long the window lasts from each time of the schedule.
+	Duration time.Duration
+	// WarnOnly reports pushes during the window without rejecting them.
+	WarnOnly bool
+
+	schedule cron.Schedule
+}
+
+// FrozenUntil returns whether t is in the window and, if so, when the freeze
+// lifts. Back-to-back schedule times extend the freeze. The returned time is
+// zero if the freeze doesn't lift in the foreseeable future.
+func (w FreezeWindow) FrozenUntil(t time.Time) (time.Time, bool) {
+	sched := w.schedule
+	if sched == nil {
+		var err error
+		sched, err = cron.ParseStandard(w.Schedule)
+		if err != nil {
+			return time.Time{}, false
+		}
+	}
+
+	// The last start of the window within its duration before t.
+	start := sched.Next(t.Add(-w.Duration))
+	if start.IsZero() || start.After(t) {
+		return time.Time{}, false
+	}
+
+	end := start.Add(w.Duration)
+	for i := 0; i < maxFreezeExtensions; i++ {
+		next := sched.Next(end.Add(-time.Second))
+		if next.IsZero() || next.After(end) {
+			return end, true
+		}
+		end = next.Add(w.Duration)
+	}
+
+	return time.Time{}, true
+}
+
+// matchRef returns whether ref is frozen by the window.
+func (w FreezeWindow) matchRef(ref string) bool {
+	if len(w.Refs) == 0 {
+		return true
+	}
+
+	for _, p := range w.Refs {
+		name := ref
+		if !strings.HasPrefix(p, "refs/") {
+			if !strings.HasPrefix(ref, git.RefsHeads) {
+				continue
+			}
+			name = strings.TrimPrefix(ref, git.RefsHeads)
+		}
+		if ok, _ := path.Match(p, name); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// newFreezeWindow returns the freeze window of a stored one.
+func newFreezeWindow(m models.FreezeWindow) FreezeWindow {
+	w := FreezeWindow{
+		Name:     m.Name,
+		Schedule: m.Schedule,
+		Duration: time.Duration(m.Duration) * time.Second,
+		WarnOnly: m.WarnOnly,
+	}
+	if m.Refs != "" {
+		w.Refs = strings.Split(m.Refs, ",")
+	}
+
+	w.schedule, _ = cron.ParseStandard(m.Schedule)
+	return w
+}
+
+// FreezeWindows returns the freeze windows of a repository, by name.
+func (d *Backend) FreezeWindows(ctx context.Context, repo string) ([]FreezeWindow, error) {
+	r, err := d.Repository(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	var ms []models.FreezeWindow
+	if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		var err error
+		ms, err = d.store.GetFreezeWindowsByRepoID(ctx, tx, r.ID())
+		return err
+	}); err != nil {
+		return nil, db.WrapError(err)
+	}
+
+	windows := make([]FreezeWindow, 0, len(ms))
+	for _, m := range ms {
+		windows = append(windows, newFreezeWindow(m))
+	}
+
+	return windows, nil
+}
+
+// AddFreezeWindow adds a freeze window to a repository.
+func (d *Backend) AddFreezeWindow(ctx context.Context, repo string, w FreezeWindow) error {
+	if err := validateFreezeWindowName(w.Name); err != nil {
+		return err
+	}
+
+	for _, p := range w.Refs {
+		if p == "" || strings.Contains(p, ",") {
+			return fmt.Errorf("invalid ref pattern %q", p)
+		}
+		if _, err := path.Match(p, ""); err != nil {
+			return fmt.Errorf("invalid ref pattern %q: %w", p, err)
+		}
+	}
+
+	if _, err := cron.ParseStandard(w.Schedule); err != nil {
+		return fmt.Errorf("invalid schedule %q: %w", w.Schedule, err)
+	}
+
+	if w.Duration < time.Minute || w.Duration%time.Second != 0 {
+		return fmt.Errorf("freeze window duration must be at least a minute, in whole seconds")
+	}
+
+	r, err := d.Repository(ctx, repo)
+	if err != nil {
+		return err
+	}
+
+	if err := db.WrapError(
+		d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+			return d.store.CreateFreezeWindow(ctx, tx, r.ID(), w.Name, strings.Join(w.Refs, ","),
+				w.Schedule, int64(w.Duration/time.Second), w.WarnOnly)
+		}),
+	); err != nil {
+		if errors.Is(err, db.ErrDuplicateKey) {
+			return proto.ErrFreezeWindowExist
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// RemoveFreezeWindow removes a freeze window from a repository.
+func (d *Backend) RemoveFreezeWindow(ctx context.Context, repo string, name string) error {
+	r, err := d.Repository(ctx, repo)
+	if err != nil {
+		return err
+	}
+
+	if err := db.WrapError(
+		d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+			return d.store.DeleteFreezeWindowByName(ctx, tx, r.ID(), name)
+		}),
+	); err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return proto.ErrFreezeWindowNotFound
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+func validateFreezeWindowName(name string) error {
+	if name == "" {
+		return fmt.Errorf("freeze window name cannot be empty")
+	}
+
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-' && r != '_' && r != '.' {
+			return fmt.Errorf("freeze window name can only contain letters, numbers, hyphens, underscores, and periods")
+		}
+	}
+
+	return nil
+}
+
+// checkFreezeWindows returns an error if the push updates refs frozen by a
+// freeze window of the repository, stating when the freeze lifts. Admins can
+// push anyway with the skip-freeze push option. It's meant to be called from
+// the pre-receive hook.
+func (d *Backend) checkFreezeWindows(ctx context.Context, stderr io.Writer, repo string, args []hooks.HookArg) error {
+	windows, err := d.FreezeWindows(ctx, repo)
+	if err != nil || len(windows) == 0 {
+		return err
+	}
+
+	now := time.Now()
+	for _, w := range windows {
+		until, frozen := w.FrozenUntil(now)
+		if !frozen {
+			continue
+		}
+
+		for _, arg := range args {
+			if !w.matchRef(arg.RefName) {
+				continue
+			}
+
+			lifts := "until further notice"
+			if !until.IsZero() {
+				lifts = "until " + until.Format("Mon, 02 Jan 2006 15:04 MST")
+			}
+			msg := fmt.Sprintf("%s is frozen by the %q freeze window %s", arg.RefName, w.Name, lifts)
+			switch {
+			case w.WarnOnly:
+				warnPolicy(ctx, stderr, policyFreezeWindows, msg)
+				continue
+			case hooks.HasPushOption(SkipFreezeOption) && d.hookAccessLevel(ctx, repo) >= access.AdminAccess:
+				fmt.Fprintf(stderr, "warning: %s (skipped by admin)\n", msg) // nolint: errcheck
+				d.logger.Info("freeze window skipped by admin", "repo", repo, "window", w.Name, "ref", arg.RefName)
+				continue
+			}
+
+			return fmt.Errorf("%s; admins can bypass this with `git push -o %s`", msg, SkipFreezeOption)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,89 @@
+package backend
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFreezeWindowFrozenUntil(t *testing.T) {
+	// A Friday.
+	friday := time.Date(2026, time.October, 16, 10, 30, 0, 0, time.UTC)
+	saturday := time.Date(2026, time.October, 17, 0, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		name   string
+		window FreezeWindow
+		t      time.Time
+		frozen bool
+		until  time.Time
+	}{
+		{
+			name:   "every minute on fridays",
+			window: FreezeWindow{Schedule: "CRON_TZ=UTC * * * * FRI", Duration: time.Minute},
+			t:      friday,
+			frozen: true,
+			until:  saturday,
+		},
+		{
+			name:   "not on thursdays",
+			window: FreezeWindow{Schedule: "CRON_TZ=UTC * * * * FRI", Duration: time.Minute},
+			t:      friday.Add(-24 * time.Hour),
+		},
+		{
+			name:   "weekend from friday evening",
+			window: FreezeWindow{Schedule: "CRON_TZ=UTC 0 18 * * FRI", Duration: 54 * time.Hour},
+			t:      saturday,
+			frozen: true,
+			until:  time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:   "before the weekend",
+			window: FreezeWindow{Schedule: "CRON_TZ=UTC 0 18 * * FRI", Duration: 54 * time.Hour},
+			t:      friday,
+		},
+		{
+			name:   "always",
+			window: FreezeWindow{Schedule: "* * * * *", Duration: time.Minute},
+			t:      friday,
+			frozen: true,
+		},
+		{
+			name:   "never",
+			window: FreezeWindow{Schedule: "0 0 30 2 *", Duration: time.Minute},
+			t:      friday,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			until, frozen := c.window.FrozenUntil(c.t)
+			if frozen != c.frozen {
+				t.Fatalf("FrozenUntil() frozen = %v, want %v", frozen, c.frozen)
+			}
+			if !until.Equal(c.until) {
+				t.Errorf("FrozenUntil() until = %v, want %v", until, c.until)
+			}
+		})
+	}
+}
+
+func TestFreezeWindowMatchRef(t *testing.T) {
+	w := FreezeWindow{Refs: []string{"release/*", "refs/tags/v*"}}
+	cases := map[string]bool{
+		"refs/heads/release/1.0": true,
+		"refs/heads/main":        false,
+		"refs/tags/v1.0":         true,
+		"refs/tags/release/1.0":  false,
+		"refs/notes/commits":     false,
+	}
+
+	for ref, want := range cases {
+		if got := w.matchRef(ref); got != want {
+			t.Errorf("matchRef(%q) = %v, want %v", ref, got, want)
+		}
+	}
+
+	if !(FreezeWindow{}).matchRef("refs/notes/commits") {
+		t.Errorf("a window without refs should freeze every ref")
+	}
+}
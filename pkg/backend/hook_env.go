@@ -0,0 +1,48 @@
+package backend
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/charmbracelet/soft-serve/pkg/access"
+	"github.com/charmbracelet/soft-serve/pkg/proto"
+)
+
+// HookEnv returns the environment variables describing the repository and
+// the user to pass down to git hooks, so hooks can make policy decisions
+// without looking them up. The user is nil for anonymous users, and the
+// access level is the user's access level to the repository.
+func (d *Backend) HookEnv(ctx context.Context, repo string, user proto.User, level access.AccessLevel) []string {
+	var pusher string
+	if user != nil {
+		pusher = user.Username()
+	}
+
+	envs := []string{
+		"SOFT_SERVE_REPO=" + repo,
+		"SOFT_SERVE_PUSHER=" + pusher,
+		"SOFT_SERVE_ACCESS_LEVEL=" + level.String(),
+	}
+
+	// The repository doesn't exist yet when it's created by a push.
+	r, err := d.Repository(ctx, repo)
+	if err != nil {
+		return envs
+	}
+
+	var owner string
+	if id := r.UserID(); id > 0 {
+		if u, err := d.UserByID(ctx, id); err == nil {
+			owner = u.Username()
+		}
+	}
+
+	return append(envs,
+		"SOFT_SERVE_REPO_OWNER="+owner,
+		"SOFT_SERVE_REPO_PRIVATE="+strconv.FormatBool(r.IsPrivate()),
+		"SOFT_SERVE_REPO_HIDDEN="+strconv.FormatBool(r.IsHidden()),
+		"SOFT_SERVE_REPO_MIRROR="+strconv.FormatBool(r.IsMirror()),
+		"SOFT_SERVE_REPO_PROJECT_NAME="+r.ProjectName(),
+		"SOFT_SERVE_REPO_DESCRIPTION="+r.Description(),
+	)
+}
@@ -0,0 +1,90 @@
+package backend
+
+import (
+	"context"
+	"errors"
+
+	"github.com/charmbracelet/soft-serve/pkg/hooks"
+	"github.com/charmbracelet/soft-serve/pkg/proto"
+	"github.com/charmbracelet/soft-serve/pkg/tracing"
+	"github.com/charmbracelet/soft-serve/pkg/webhook"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var (
+	hookSeconds = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "soft_serve",
+		Subsystem: "git",
+		Name:      "hook_duration_seconds",
+		Help:      "The duration of git hook executions by outcome: accepted, rejected, or errored",
+		Buckets:   []float64{.01, .05, .1, .5, 1, 5, 10, 30, 60, 300},
+	}, []string{"hook", "repo", "outcome"})
+
+	hookTimeoutCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "soft_serve",
+		Subsystem: "git",
+		Name:      "hook_timeouts_total",
+		Help:      "The total number of git hooks killed for running too long",
+	}, []string{"hook", "repo"})
+)
+
+// RecordHookExecutions records the metrics and spans of hook executions.
+// Hooks that failed to run or timed out, as opposed to hooks rejecting a
+// push, are logged as alerts and sent to the repository hook_error webhooks.
+func (d *Backend) RecordHookExecutions(ctx context.Context, execs []hooks.Execution) {
+	for _, e := range execs {
+		hookSeconds.WithLabelValues(e.Hook, e.Repo, e.Outcome).Observe(e.Duration.Seconds())
+		traceHookExecution(ctx, e)
+		if e.TimedOut {
+			hookTimeoutCounter.WithLabelValues(e.Hook, e.Repo).Inc()
+		}
+		d.countForcePushes(ctx, e)
+
+		if e.Outcome != hooks.OutcomeErrored {
+			continue
+		}
+
+		d.logger.Error("git hook failed", "alert", true, "hook", e.Hook, "repo", e.Repo, "duration", e.Duration, "timed-out", e.TimedOut, "err", e.Error)
+
+		r, err := d.Repository(ctx, e.Repo)
+		if err != nil {
+			d.logger.Error("error finding repository", "repo", e.Repo, "err", err)
+			continue
+		}
+
+		wh, err := webhook.NewHookErrorEvent(ctx, proto.UserFromContext(ctx), r, e.Hook, e.Error, e.TimedOut)
+		if err != nil {
+			d.logger.Error("error creating hook_error webhook", "err", err)
+		} else if err := webhook.SendEvent(ctx, wh); err != nil {
+			d.logger.Error("error sending hook_error webhook", "err", err)
+		}
+	}
+}
+
+// traceHookExecution records the span of a hook execution. Hooks run in their
+// own process, so the span is recorded after the fact from the execution
+// report.
+func traceHookExecution(ctx context.Context, e hooks.Execution) {
+	if e.Start.IsZero() {
+		return
+	}
+
+	_, span := tracing.Start(ctx, "git.hook."+e.Hook,
+		trace.WithTimestamp(e.Start),
+		trace.WithAttributes(
+			attribute.String("hook", e.Hook),
+			attribute.String("repo", e.Repo),
+			attribute.String("outcome", e.Outcome),
+			attribute.Bool("timed_out", e.TimedOut),
+		),
+	)
+
+	var err error
+	if e.Outcome == hooks.OutcomeErrored {
+		err = errors.New(e.Error)
+	}
+	tracing.End(span, err, trace.WithTimestamp(e.Start.Add(e.Duration)))
+}
@@ -2,11 +2,14 @@ package backend
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"io"
 	"os"
 	"sync"
 
 	"github.com/charmbracelet/soft-serve/git"
+	"github.com/charmbracelet/soft-serve/pkg/access"
 	"github.com/charmbracelet/soft-serve/pkg/hooks"
 	"github.com/charmbracelet/soft-serve/pkg/proto"
 	"github.com/charmbracelet/soft-serve/pkg/sshutils"
@@ -18,15 +21,140 @@ var _ hooks.Hooks = (*Backend)(nil)
 // PostReceive is called by the git post-receive hook.
 //
 // It implements Hooks.
-func (d *Backend) PostReceive(_ context.Context, _ io.Writer, _ io.Writer, repo string, args []hooks.HookArg) {
+func (d *Backend) PostReceive(ctx context.Context, _ io.Writer, stderr io.Writer, repo string, args []hooks.HookArg) {
 	d.logger.Debug("post-receive hook called", "repo", repo, "args", args)
+
+	// Changes proposed for review didn't update any branch.
+	args = d.captureReviews(ctx, stderr, repo, args)
+
+	if err := d.setInitialHead(ctx, repo, args); err != nil {
+		d.logger.Error("error setting default branch", "repo", repo, "err", err)
+	}
+
+	if err := d.autoDescribe(ctx, repo, args); err != nil {
+		d.logger.Error("error setting automatic description", "repo", repo, "err", err)
+	}
+
+	if _, err := d.RecomputeRepoSize(ctx, repo); err != nil {
+		d.logger.Error("error computing repository size", "repo", repo, "err", err)
+	}
+
+	// Pushes with keys that aren't registered to a user, like deploy keys and
+	// repository keys, still send their events, without a user.
+	user, err := d.hookUser(ctx)
+	if err != nil && !errors.Is(err, proto.ErrUserNotFound) {
+		d.logger.Error("error finding user", "err", err)
+	}
+
+	forced, err := d.recordForcePushes(ctx, repo, user, args)
+	if err != nil {
+		d.logger.Error("error recording force pushes", "repo", repo, "err", err)
+	}
+
+	d.sendPushWebhooks(ctx, repo, user, args, forced)
+}
+
+// sendPushWebhooks sends a push event for each updated ref. Push events are
+// sent after all the refs are updated so that every payload knows about the
+// other refs of the push. Forced are the refs of the updates that aren't
+// fast-forwards.
+func (d *Backend) sendPushWebhooks(ctx context.Context, repo string, user proto.User, args []hooks.HookArg, forced map[string]bool) {
+	r, err := d.Repository(ctx, repo)
+	if err != nil {
+		d.logger.Error("error finding repository", "repo", repo, "err", err)
+		return
+	}
+
+	refs := make([]webhook.RefUpdate, len(args))
+	for i, arg := range args {
+		refs[i] = webhook.RefUpdate{Ref: arg.RefName, Before: arg.OldSha, After: arg.NewSha, Forced: forced[arg.RefName]}
+	}
+
+	// TODO: run this async
+	for _, arg := range args {
+		wh, err := webhook.NewPushEvent(ctx, user, r, arg.RefName, arg.OldSha, arg.NewSha, refs)
+		if err != nil {
+			d.logger.Error("error creating push webhook", "err", err)
+			continue
+		}
+
+		wh.Forced = forced[arg.RefName]
+		if err := webhook.SendEvent(ctx, wh); err != nil {
+			d.logger.Error("error sending push webhook", "err", err)
+		}
+	}
 }
 
 // PreReceive is called by the git pre-receive hook.
 //
 // It implements Hooks.
-func (d *Backend) PreReceive(_ context.Context, _ io.Writer, _ io.Writer, repo string, args []hooks.HookArg) {
+func (d *Backend) PreReceive(ctx context.Context, _ io.Writer, stderr io.Writer, repo string, args []hooks.HookArg) error {
 	d.logger.Debug("pre-receive hook called", "repo", repo, "args", args)
+
+	ctx, warnings := withPolicyWarnings(ctx)
+
+	if err := d.checkTerms(ctx); err != nil {
+		return err
+	}
+
+	if err := d.checkReviewRefs(ctx, repo, args); err != nil {
+		return err
+	}
+
+	if err := d.checkNotesAccess(ctx, repo, args); err != nil {
+		return err
+	}
+
+	if err := d.checkPushCert(ctx, repo, args); err != nil {
+		return err
+	}
+
+	if err := d.checkTagProtection(ctx, stderr, repo, args); err != nil {
+		return err
+	}
+
+	if err := d.checkLinearHistory(ctx, stderr, repo, args); err != nil {
+		return err
+	}
+
+	// Review refs aren't kept, only the names of the other refs matter.
+	_, refs := d.splitReviewArgs(args)
+	if err := d.checkFreezeWindows(ctx, stderr, repo, refs); err != nil {
+		return err
+	}
+
+	if err := d.checkRefNames(ctx, repo, refs); err != nil {
+		return err
+	}
+
+	if err := d.checkDeniedPaths(ctx, repo, args); err != nil {
+		return err
+	}
+
+	if err := d.checkFileModes(ctx, stderr, repo, args); err != nil {
+		return err
+	}
+
+	if err := d.checkBinaryLimit(ctx, stderr, repo, args); err != nil {
+		return err
+	}
+
+	if err := d.checkRequiredStatuses(ctx, repo, args); err != nil {
+		return err
+	}
+
+	if err := d.checkQuota(ctx, stderr, repo, args); err != nil {
+		return err
+	}
+
+	if err := d.checkPushLimits(ctx, stderr, repo, args); err != nil {
+		return err
+	}
+
+	d.writePolicyBanner(stderr, repo, warnings)
+	d.writeBranchRenameHints(ctx, stderr, repo, args)
+
+	return nil
 }
 
 // Update is called by the git update hook.
@@ -35,32 +163,15 @@ func (d *Backend) PreReceive(_ context.Context, _ io.Writer, _ io.Writer, repo s
 func (d *Backend) Update(ctx context.Context, _ io.Writer, _ io.Writer, repo string, arg hooks.HookArg) {
 	d.logger.Debug("update hook called", "repo", repo, "arg", arg)
 
-	// Find user
-	var user proto.User
-	if pubkey := os.Getenv("SOFT_SERVE_PUBLIC_KEY"); pubkey != "" {
-		pk, _, err := sshutils.ParseAuthorizedKey(pubkey)
-		if err != nil {
-			d.logger.Error("error parsing public key", "err", err)
-			return
-		}
-
-		user, err = d.UserByPublicKey(ctx, pk)
-		if err != nil {
-			d.logger.Error("error finding user from public key", "key", pubkey, "err", err)
-			return
-		}
-	} else if username := os.Getenv("SOFT_SERVE_USERNAME"); username != "" {
-		var err error
-		user, err = d.User(ctx, username)
-		if err != nil {
-			d.logger.Error("error finding user from username", "username", username, "err", err)
-			return
-		}
-	} else {
-		d.logger.Error("error finding user")
+	if _, ok := d.reviewBranch(arg.RefName); ok {
 		return
 	}
 
+	user, err := d.hookUser(ctx)
+	if err != nil && !errors.Is(err, proto.ErrUserNotFound) {
+		d.logger.Error("error finding user", "err", err)
+	}
+
 	// Get repo
 	r, err := d.Repository(ctx, repo)
 	if err != nil {
@@ -78,20 +189,30 @@ func (d *Backend) Update(ctx context.Context, _ io.Writer, _ io.Writer, repo str
 			d.logger.Error("error sending branch_tag webhook", "err", err)
 		}
 	}
-	wh, err := webhook.NewPushEvent(ctx, user, r, arg.RefName, arg.OldSha, arg.NewSha)
-	if err != nil {
-		d.logger.Error("error creating push webhook", "err", err)
-	} else if err := webhook.SendEvent(ctx, wh); err != nil {
-		d.logger.Error("error sending push webhook", "err", err)
-	}
 }
 
 // PostUpdate is called by the git post-update hook.
 //
 // It implements Hooks.
-func (d *Backend) PostUpdate(ctx context.Context, _ io.Writer, _ io.Writer, repo string, args ...string) {
+func (d *Backend) PostUpdate(ctx context.Context, _ io.Writer, stderr io.Writer, repo string, args ...string) {
 	d.logger.Debug("post-update hook called", "repo", repo, "args", args)
 
+	// Queue push mirrors. These are pushed in the background by the
+	// push-mirror job so we don't block the user's push.
+	mirrors, err := d.PushMirrors(ctx, repo)
+	if err != nil {
+		d.logger.Error("error getting push mirrors", "repo", repo, "err", err)
+	} else if len(mirrors) > 0 {
+		for _, m := range mirrors {
+			if m.Status == PushMirrorStatusFailed {
+				fmt.Fprintf(stderr, "warning: push mirror %q failed: %s\n", m.Name, m.LastError.String) // nolint: errcheck
+			}
+		}
+		if err := d.QueuePushMirrors(ctx, repo); err != nil {
+			d.logger.Error("error queuing push mirrors", "repo", repo, "err", err)
+		}
+	}
+
 	var wg sync.WaitGroup
 
 	// Populate last-modified file.
@@ -104,9 +225,61 @@ func (d *Backend) PostUpdate(ctx context.Context, _ io.Writer, _ io.Writer, repo
 		}
 	}()
 
+	// Update the commit-graph.
+	if d.cfg.CommitGraph.AfterPush {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := d.WriteCommitGraph(ctx, repo); err != nil {
+				d.logger.Error("error writing commit-graph", "repo", repo, "err", err)
+			}
+		}()
+	}
+
 	wg.Wait()
 }
 
+// hookUser returns the user running a git hook. The user is identified by the
+// environment set by the server before invoking git-receive-pack, or by the
+// context for the server-side updates going through the hooks, like merges.
+func (d *Backend) hookUser(ctx context.Context) (proto.User, error) {
+	if user := proto.UserFromContext(ctx); user != nil {
+		return user, nil
+	}
+
+	if pubkey := os.Getenv("SOFT_SERVE_PUBLIC_KEY"); pubkey != "" {
+		pk, _, err := sshutils.ParseAuthorizedKey(pubkey)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing public key: %w", err)
+		}
+
+		return d.UserByPublicKey(ctx, pk)
+	} else if username := os.Getenv("SOFT_SERVE_USERNAME"); username != "" {
+		return d.User(ctx, username)
+	}
+
+	return nil, proto.ErrUserNotFound
+}
+
+// hookAccessLevel returns the access level of the user running a git hook.
+func (d *Backend) hookAccessLevel(ctx context.Context, repo string) access.AccessLevel {
+	if user := proto.UserFromContext(ctx); user != nil {
+		return d.AccessLevelForUser(ctx, repo, user)
+	}
+
+	if pubkey := os.Getenv("SOFT_SERVE_PUBLIC_KEY"); pubkey != "" {
+		pk, _, err := sshutils.ParseAuthorizedKey(pubkey)
+		if err != nil {
+			return access.NoAccess
+		}
+
+		return d.AccessLevelByPublicKey(ctx, repo, pk)
+	}
+
+	user, _ := d.hookUser(ctx)
+	return d.AccessLevelForUser(ctx, repo, user)
+}
+
 func populateLastModified(ctx context.Context, d *Backend, name string) error {
 	var rr *repo
 	_rr, err := d.Repository(ctx, name)
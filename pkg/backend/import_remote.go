@@ -0,0 +1,177 @@
+package backend
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/soft-serve/git"
+	"github.com/charmbracelet/soft-serve/pkg/proto"
+)
+
+// settingImportCredentials is the repository setting recording that a mirror
+// was imported with the stored credentials of its remote, so syncs use them
+// too.
+const settingImportCredentials = "import_credentials"
+
+// ValidateRemoteURL returns an error if remote isn't the URL of a remote
+// repository, over HTTP, SSH, or the git protocol. Local paths are rejected.
+func ValidateRemoteURL(remote string) error {
+	if remote == "" || isLocalRemote(remote) {
+		return fmt.Errorf("invalid remote %q: not a remote URL", remote)
+	}
+
+	if strings.Contains(remote, "://") {
+		u, err := url.Parse(remote)
+		if err != nil {
+			return fmt.Errorf("invalid remote %q: %w", RedactURL(remote), err)
+		}
+
+		switch u.Scheme {
+		case "http", "https", "ssh", "git":
+		default:
+			return fmt.Errorf("invalid remote %q: unsupported scheme %q", RedactURL(remote), u.Scheme)
+		}
+		if u.Host == "" {
+			return fmt.Errorf("invalid remote %q: missing host", RedactURL(remote))
+		}
+
+		return nil
+	}
+
+	// scp-like SSH remotes, user@host:path.
+	if host, _, ok := strings.Cut(remote, ":"); ok && host != "" && !strings.Contains(host, "/") {
+		return nil
+	}
+
+	return fmt.Errorf("invalid remote %q: not a remote URL", remote)
+}
+
+// importCredentials returns the stored credentials of an HTTP remote,
+// configured in import.credentials by host. Remotes with credentials in
+// their URL don't use the stored ones.
+func (d *Backend) importCredentials(remote string) (username string, password string, ok bool) {
+	u, err := url.Parse(remote)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.User != nil {
+		return "", "", false
+	}
+
+	for host, cred := range d.cfg.Import.Credentials {
+		if strings.EqualFold(host, u.Host) {
+			username, password, _ = strings.Cut(cred, ":")
+			return username, password, true
+		}
+	}
+
+	return "", "", false
+}
+
+// credentialEnvs returns the environment passing credentials to git as the
+// Authorization header of the requests to the host of remote. Unlike
+// credentials in the URL, they never end up in the repository config.
+func credentialEnvs(remote string, username string, password string) []string {
+	u, err := url.Parse(remote)
+	if err != nil {
+		return nil
+	}
+
+	auth := base64.StdEncoding.EncodeToString([]byte(username + ":" + password))
+	return []string{
+		"GIT_CONFIG_COUNT=1",
+		fmt.Sprintf("GIT_CONFIG_KEY_0=http.%s://%s/.extraHeader", u.Scheme, u.Host),
+		"GIT_CONFIG_VALUE_0=Authorization: Basic " + auth,
+	}
+}
+
+// mirrorCredentialEnvs returns the credential environment of a mirror
+// imported with the stored credentials of its remote.
+func (d *Backend) mirrorCredentialEnvs(ctx context.Context, repo string, remote string) []string {
+	settings, err := d.RepoSettings(ctx, repo)
+	if err != nil {
+		return nil
+	}
+
+	if use, _ := strconv.ParseBool(settings[settingImportCredentials]); !use {
+		return nil
+	}
+
+	username, password, ok := d.importCredentials(remote)
+	if !ok {
+		return nil
+	}
+
+	return credentialEnvs(remote, username, password)
+}
+
+// progressWriter writes the progress of a command, ignoring write errors so
+// a client going away doesn't fail the command.
+type progressWriter struct {
+	w io.Writer
+}
+
+// Write implements io.Writer.
+func (p progressWriter) Write(b []byte) (int, error) {
+	p.w.Write(b) // nolint: errcheck
+	return len(b), nil
+}
+
+// cloneRemote clones remote into the bare repository at dst. The clone
+// progress is written to progress, if any.
+func cloneRemote(ctx context.Context, remote string, dst string, mirror bool, progress io.Writer, envs []string) error {
+	if progress == nil {
+		return git.Clone(remote, dst, git.CloneOptions{
+			Bare:   true,
+			Mirror: mirror,
+			Quiet:  true,
+			CommandOptions: git.CommandOptions{
+				Timeout: -1,
+				Context: ctx,
+				Envs:    envs,
+			},
+		})
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dst), os.ModePerm); err != nil {
+		return err
+	}
+
+	args := []string{"clone", "--bare", "--progress"}
+	if mirror {
+		args = append(args, "--mirror")
+	}
+	args = append(args, "--", remote, dst)
+
+	var stderr bytes.Buffer
+	if err := git.NewCommand(args...).AddEnvs(envs...).WithContext(ctx).WithTimeout(-1).
+		RunInDirWithOptions(filepath.Dir(dst), git.RunInDirOptions{
+			Stderr: io.MultiWriter(&stderr, progressWriter{progress}),
+		}); err != nil {
+		// Progress lines end with carriage returns, the error is the last
+		// full line.
+		lines := strings.Split(strings.TrimSpace(stderr.String()), "\n")
+		return fmt.Errorf("%w - %s", err, lines[len(lines)-1])
+	}
+
+	return nil
+}
+
+// fsckRepository checks the connectivity and validity of the objects of a
+// repository.
+func fsckRepository(ctx context.Context, rp string, progress io.Writer) error {
+	if progress != nil {
+		fmt.Fprintln(progressWriter{progress}, "Checking objects...") // nolint: errcheck
+	}
+
+	if _, err := git.NewCommand("fsck", "--no-progress", "--no-dangling").WithContext(ctx).WithTimeout(-1).RunInDir(rp); err != nil {
+		return fmt.Errorf("%w: %w", proto.ErrCorruptRepository, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,66 @@
+package backend
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/soft-serve/pkg/config"
+)
+
+func TestValidateRemoteURL(t *testing.T) {
+	cases := map[string]bool{
+		"https://github.com/charmbracelet/soft-serve.git": true,
+		"http://localhost:23232/repo":                     true,
+		"ssh://git@github.com/charmbracelet/soft-serve":   true,
+		"git://localhost/repo":                            true,
+		"git@github.com:charmbracelet/soft-serve.git":     true,
+		"":                         false,
+		"/srv/git/repo.git":        false,
+		"./repo.git":               false,
+		"file:///srv/git/repo.git": false,
+		"ftp://localhost/repo":     false,
+		"https:///repo":            false,
+		"repo/with:colon":          false,
+	}
+
+	for remote, valid := range cases {
+		if err := ValidateRemoteURL(remote); (err == nil) != valid {
+			t.Errorf("ValidateRemoteURL(%q) error = %v, want valid %v", remote, err, valid)
+		}
+	}
+}
+
+func TestImportCredentials(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Import.Credentials = map[string]string{"git.example.com": "user:token"}
+	d := &Backend{cfg: cfg}
+
+	username, password, ok := d.importCredentials("https://Git.example.com/repo.git")
+	if !ok || username != "user" || password != "token" {
+		t.Errorf("importCredentials() = %q, %q, %v, want user, token, true", username, password, ok)
+	}
+
+	for _, remote := range []string{
+		"https://other@git.example.com/repo.git",
+		"ssh://git.example.com/repo.git",
+		"https://git.example.com:8443/repo.git",
+	} {
+		if _, _, ok := d.importCredentials(remote); ok {
+			t.Errorf("importCredentials(%q) found credentials", remote)
+		}
+	}
+
+	envs := credentialEnvs("https://git.example.com/repo.git", "user", "token")
+	want := []string{
+		"GIT_CONFIG_COUNT=1",
+		"GIT_CONFIG_KEY_0=http.https://git.example.com/.extraHeader",
+		"GIT_CONFIG_VALUE_0=Authorization: Basic dXNlcjp0b2tlbg==",
+	}
+	if len(envs) != len(want) {
+		t.Fatalf("credentialEnvs() = %q, want %q", envs, want)
+	}
+	for i := range want {
+		if envs[i] != want[i] {
+			t.Errorf("credentialEnvs()[%d] = %q, want %q", i, envs[i], want[i])
+		}
+	}
+}
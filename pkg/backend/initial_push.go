@@ -0,0 +1,83 @@
+package backend
+
+import (
+	"context"
+	"strings"
+
+	"github.com/charmbracelet/soft-serve/git"
+	"github.com/charmbracelet/soft-serve/pkg/hooks"
+)
+
+// initialPushRefs returns the refs created by the initial push to a
+// repository, that is when every ref of the repository was created by the
+// push. It returns no refs for other pushes.
+func initialPushRefs(ctx context.Context, rr *git.Repository, args []hooks.HookArg) ([]hooks.HookArg, error) {
+	var created []hooks.HookArg
+	for _, arg := range args {
+		if !git.IsZeroHash(arg.OldSha) {
+			return nil, nil
+		}
+		if !git.IsZeroHash(arg.NewSha) {
+			created = append(created, arg)
+		}
+	}
+
+	if len(created) == 0 {
+		return nil, nil
+	}
+
+	refs, err := git.NewCommand("for-each-ref", "--format=%(refname)").WithContext(ctx).RunInDir(rr.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(strings.Fields(string(refs))) != len(created) {
+		// The repository had refs before this push.
+		return nil, nil
+	}
+
+	return created, nil
+}
+
+// setInitialHead points HEAD of a repository at the configured default
+// branch on the initial push, if the push created that branch. Otherwise,
+// HEAD is left as is. It's meant to be called from the post-receive hook,
+// before anything reading HEAD.
+func (d *Backend) setInitialHead(ctx context.Context, repo string, args []hooks.HookArg) error {
+	branch := d.cfg.InitialPush.DefaultBranch
+	if branch == "" {
+		return nil
+	}
+
+	ref := git.RefsHeads + branch
+	pushed := false
+	for _, arg := range args {
+		pushed = pushed || (arg.RefName == ref && !git.IsZeroHash(arg.NewSha))
+	}
+	if !pushed {
+		return nil
+	}
+
+	r, err := d.Repository(ctx, repo)
+	if err != nil {
+		return err
+	}
+
+	rr, err := r.Open()
+	if err != nil {
+		return err
+	}
+
+	created, err := initialPushRefs(ctx, rr, args)
+	if err != nil || len(created) == 0 {
+		return err
+	}
+
+	if head, err := rr.HEAD(); err == nil && head.Refspec == ref {
+		return nil
+	}
+
+	d.logger.Debug("setting default branch on initial push", "repo", repo, "branch", branch)
+	_, err = rr.SymbolicRef(git.HEAD, ref)
+	return err
+}
@@ -0,0 +1,214 @@
+package backend
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/db/models"
+	"github.com/charmbracelet/soft-serve/pkg/proto"
+	"github.com/charmbracelet/soft-serve/pkg/utils"
+	gossh "golang.org/x/crypto/ssh"
+)
+
+// CreateInvite creates a single-use invite code registering a public key to
+// the user with the given username, which is created with the given admin
+// access if it doesn't exist yet. The code expires after expiresIn and is
+// only stored hashed.
+func (d *Backend) CreateInvite(ctx context.Context, username string, admin bool, expiresIn time.Duration) (string, error) {
+	username = strings.ToLower(username)
+	if err := utils.ValidateUsername(username); err != nil {
+		return "", err
+	}
+	if expiresIn <= 0 {
+		return "", fmt.Errorf("invite expiration must be positive")
+	}
+
+	code, err := generateInviteCode()
+	if err != nil {
+		return "", err
+	}
+
+	var createdByID int64
+	if user := proto.UserFromContext(ctx); user != nil {
+		createdByID = user.ID()
+	}
+
+	hash := HashToken(normalizeInviteCode(code))
+	var invite models.Invite
+	if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		if err := d.store.CreateInvite(ctx, tx, hash, username, admin, createdByID, time.Now().Add(expiresIn)); err != nil {
+			return err
+		}
+
+		invite, err = d.store.GetInviteByCodeHash(ctx, tx, hash)
+		return err
+	}); err != nil {
+		return "", db.WrapError(err)
+	}
+
+	if err := d.recordAuditEvent(ctx, AuditActionInviteCreated, nil, nil,
+		fmt.Sprintf("%d %s admin=%t", invite.ID, username, admin)); err != nil {
+		return "", err
+	}
+
+	return code, nil
+}
+
+// Invites returns all the invites, newest first.
+func (d *Backend) Invites(ctx context.Context) ([]models.Invite, error) {
+	var invites []models.Invite
+	if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		var err error
+		invites, err = d.store.GetInvites(ctx, tx)
+		return err
+	}); err != nil {
+		return nil, db.WrapError(err)
+	}
+
+	return invites, nil
+}
+
+// RevokeInvite deletes an invite so its code can't be used anymore.
+func (d *Backend) RevokeInvite(ctx context.Context, id int64) error {
+	var invite models.Invite
+	if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		var err error
+		invite, err = d.store.GetInviteByID(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+
+		return d.store.DeleteInvite(ctx, tx, id)
+	}); err != nil {
+		err = db.WrapError(err)
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return proto.ErrInviteNotFound
+		}
+		return err
+	}
+
+	return d.recordAuditEvent(ctx, AuditActionInviteRevoked, nil, nil,
+		fmt.Sprintf("%d %s", invite.ID, invite.Username))
+}
+
+// CheckInvite returns the invite with the given code without using it. It
+// returns proto.ErrInvalidInvite if the code doesn't exist, was already used,
+// or expired.
+func (d *Backend) CheckInvite(ctx context.Context, code string) (models.Invite, error) {
+	hash := HashToken(normalizeInviteCode(code))
+
+	var invite models.Invite
+	if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		var err error
+		invite, err = d.store.GetInviteByCodeHash(ctx, tx, hash)
+		return err
+	}); err != nil {
+		err = db.WrapError(err)
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return models.Invite{}, proto.ErrInvalidInvite
+		}
+		return models.Invite{}, err
+	}
+
+	if invite.UsedAt.Valid || time.Now().After(invite.ExpiresAt) {
+		return models.Invite{}, proto.ErrInvalidInvite
+	}
+
+	return invite, nil
+}
+
+// RedeemInvite registers the public key to the user of the invite with the
+// given code, creating the user if it doesn't exist, and marks the invite as
+// used. It returns proto.ErrInvalidInvite if the code doesn't exist, was
+// already used, or expired.
+func (d *Backend) RedeemInvite(ctx context.Context, code string, pk gossh.PublicKey) (proto.User, error) {
+	hash := HashToken(normalizeInviteCode(code))
+	fp := gossh.FingerprintSHA256(pk)
+
+	var invite models.Invite
+	if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		var err error
+		invite, err = d.store.GetInviteByCodeHash(ctx, tx, hash)
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return proto.ErrInvalidInvite
+		} else if err != nil {
+			return err
+		}
+
+		if invite.UsedAt.Valid || time.Now().After(invite.ExpiresAt) {
+			return proto.ErrInvalidInvite
+		}
+
+		if _, err := d.store.FindUserByPublicKey(ctx, tx, pk); err == nil {
+			return fmt.Errorf("public key is already registered")
+		} else if !errors.Is(err, db.ErrRecordNotFound) {
+			return err
+		}
+
+		// Two clients redeeming the same code race here, only one of them
+		// marks it as used.
+		if err := d.store.UseInvite(ctx, tx, invite.ID, fp); errors.Is(err, db.ErrRecordNotFound) {
+			return proto.ErrInvalidInvite
+		} else if err != nil {
+			return err
+		}
+
+		if _, err := d.store.FindUserByUsername(ctx, tx, invite.Username); errors.Is(err, db.ErrRecordNotFound) {
+			return d.store.CreateUser(ctx, tx, invite.Username, invite.Admin, []gossh.PublicKey{pk})
+		} else if err != nil {
+			return err
+		}
+
+		return d.store.AddPublicKeyByUsername(ctx, tx, invite.Username, pk)
+	}); err != nil {
+		return nil, db.WrapError(err)
+	}
+
+	user, err := d.User(ctx, invite.Username)
+	if err != nil {
+		return nil, err
+	}
+
+	d.logger.Info("registered public key with invite", "username", invite.Username, "fingerprint", fp, "invite", invite.ID)
+	if err := d.recordAuditEvent(ctx, AuditActionInviteUsed, nil, user,
+		fmt.Sprintf("%d %s", invite.ID, fp)); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// generateInviteCode returns a random invite code, in groups of four
+// characters that are easy to type.
+func generateInviteCode() (string, error) {
+	buf := make([]byte, 10)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("generate invite code: %w", err)
+	}
+
+	s := base32.StdEncoding.EncodeToString(buf)
+	var groups []string
+	for i := 0; i < len(s); i += 4 {
+		groups = append(groups, s[i:i+4])
+	}
+
+	return strings.Join(groups, "-"), nil
+}
+
+// normalizeInviteCode returns an invite code without its separators and in
+// upper case, the way it's hashed.
+func normalizeInviteCode(code string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '-', ' ', '\t':
+			return -1
+		}
+		return r
+	}, strings.ToUpper(strings.TrimSpace(code)))
+}
@@ -0,0 +1,37 @@
+package backend
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestGenerateInviteCode(t *testing.T) {
+	code, err := generateInviteCode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !regexp.MustCompile(`^[A-Z2-7]{4}(-[A-Z2-7]{4}){3}$`).MatchString(code) {
+		t.Errorf("unexpected invite code format %q", code)
+	}
+
+	other, err := generateInviteCode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if code == other {
+		t.Error("expected different invite codes")
+	}
+}
+
+func TestNormalizeInviteCode(t *testing.T) {
+	for _, c := range []string{
+		"ABCD-EFGH-IJKL-MNOP",
+		"abcd-efgh-ijkl-mnop",
+		" ABCD EFGH\tIJKL MNOP ",
+		"ABCDEFGHIJKLMNOP",
+	} {
+		if got := normalizeInviteCode(c); got != "ABCDEFGHIJKLMNOP" {
+			t.Errorf("normalizeInviteCode(%q) = %q", c, got)
+		}
+	}
+}
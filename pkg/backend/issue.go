@@ -0,0 +1,283 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/soft-serve/pkg/access"
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/db/models"
+	"github.com/charmbracelet/soft-serve/pkg/proto"
+)
+
+const (
+	// IssueStateOpen is the state of an open issue.
+	IssueStateOpen = "open"
+	// IssueStateClosed is the state of a closed issue.
+	IssueStateClosed = "closed"
+)
+
+const (
+	// issueTitleMaxLen is the maximum length of an issue title.
+	issueTitleMaxLen = 255
+	// issueBodyMaxLen is the maximum size in bytes of an issue body or
+	// comment.
+	issueBodyMaxLen = 64 * 1024
+)
+
+// ErrInvalidIssue is returned when an issue or a comment is empty or too
+// long.
+var ErrInvalidIssue = errors.New("invalid issue")
+
+// Issue is an issue of a repository, with its comments.
+type Issue struct {
+	// Number identifies the issue in its repository, starting at 1.
+	Number int64 `json:"number"`
+	// Title is the title of the issue.
+	Title string `json:"title"`
+	// Body is the description of the issue.
+	Body string `json:"body"`
+	// Username is the user that opened the issue, empty if the user was
+	// deleted.
+	Username string `json:"username"`
+	// State is either IssueStateOpen or IssueStateClosed.
+	State string `json:"state"`
+	// CommentCount is the number of comments of the issue.
+	CommentCount int64 `json:"comment_count"`
+	// Comments are the comments of the issue, oldest first. Only Issue and
+	// ExportIssues return them.
+	Comments []IssueComment `json:"comments,omitempty"`
+	// CreatedAt is when the issue was opened.
+	CreatedAt time.Time `json:"created_at"`
+	// UpdatedAt is when the issue was last commented on or closed.
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// IssueComment is a comment on an issue.
+type IssueComment struct {
+	// Username is the user that commented, empty if the user was deleted.
+	Username string `json:"username"`
+	// Body is the content of the comment.
+	Body string `json:"body"`
+	// CreatedAt is when the comment was added.
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ValidIssueState returns whether a state is a valid issue state.
+func ValidIssueState(state string) bool {
+	return state == IssueStateOpen || state == IssueStateClosed
+}
+
+// CreateIssue opens an issue in a repository. Issues are opened by users,
+// the caller must check that the user can read the repository.
+func (d *Backend) CreateIssue(ctx context.Context, repo string, user proto.User, title string, body string) (Issue, error) {
+	if err := d.checkWritable(); err != nil {
+		return Issue{}, err
+	}
+
+	if user == nil {
+		return Issue{}, proto.ErrUnauthorized
+	}
+
+	title = strings.TrimSpace(title)
+	if title == "" || utf8.RuneCountInString(title) > issueTitleMaxLen {
+		return Issue{}, fmt.Errorf("%w: the title must be between 1 and %d characters", ErrInvalidIssue, issueTitleMaxLen)
+	}
+	if len(body) > issueBodyMaxLen {
+		return Issue{}, fmt.Errorf("%w: the body must be at most %d bytes", ErrInvalidIssue, issueBodyMaxLen)
+	}
+
+	r, err := d.Repository(ctx, repo)
+	if err != nil {
+		return Issue{}, err
+	}
+
+	var number int64
+	if err := db.WrapError(d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		var err error
+		number, err = d.store.CreateIssue(ctx, tx, r.ID(), user.ID(), title, body)
+		return err
+	})); err != nil {
+		return Issue{}, err
+	}
+
+	if err := d.recordAuditEvent(ctx, AuditActionIssueCreated, r, user, strconv.FormatInt(number, 10)); err != nil {
+		return Issue{}, err
+	}
+
+	return d.Issue(ctx, r.Name(), number)
+}
+
+// CommentIssue adds a comment to an issue. Closed issues can be commented
+// on too, the caller must check that the user can read the repository.
+func (d *Backend) CommentIssue(ctx context.Context, repo string, user proto.User, number int64, body string) error {
+	if err := d.checkWritable(); err != nil {
+		return err
+	}
+
+	if user == nil {
+		return proto.ErrUnauthorized
+	}
+
+	if strings.TrimSpace(body) == "" || len(body) > issueBodyMaxLen {
+		return fmt.Errorf("%w: the comment must be between 1 and %d bytes", ErrInvalidIssue, issueBodyMaxLen)
+	}
+
+	_, m, err := d.issue(ctx, repo, number)
+	if err != nil {
+		return err
+	}
+
+	return db.WrapError(d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		return d.store.CreateIssueComment(ctx, tx, m.ID, user.ID(), body)
+	}))
+}
+
+// CloseIssue closes an issue. Only the user that opened the issue and the
+// users allowed to manage issues, with triage access by default, can close
+// it, others get proto.ErrUnauthorized. Closing a closed issue does nothing.
+func (d *Backend) CloseIssue(ctx context.Context, repo string, user proto.User, number int64) error {
+	if err := d.checkWritable(); err != nil {
+		return err
+	}
+
+	r, m, err := d.issue(ctx, repo, number)
+	if err != nil {
+		return err
+	}
+
+	if user == nil || (!m.UserID.Valid || m.UserID.Int64 != user.ID()) &&
+		!d.HasCapability(ctx, r.Name(), user, access.CapabilityManageIssues) {
+		return proto.ErrUnauthorized
+	}
+
+	if m.State == IssueStateClosed {
+		return nil
+	}
+
+	if err := db.WrapError(d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		return d.store.SetIssueState(ctx, tx, m.ID, IssueStateClosed)
+	})); err != nil {
+		return err
+	}
+
+	return d.recordAuditEvent(ctx, AuditActionIssueClosed, r, user, strconv.FormatInt(number, 10))
+}
+
+// Issue returns the issue of a repository with a number, with its comments.
+func (d *Backend) Issue(ctx context.Context, repo string, number int64) (Issue, error) {
+	_, m, err := d.issue(ctx, repo, number)
+	if err != nil {
+		return Issue{}, err
+	}
+
+	return d.newIssue(ctx, m, true)
+}
+
+// Issues returns the issues of a repository in a state, newest first,
+// without their comments. An empty state returns all the issues.
+func (d *Backend) Issues(ctx context.Context, repo string, state string) ([]Issue, error) {
+	return d.issues(ctx, repo, state, false)
+}
+
+// ExportIssues returns all the issues of a repository, newest first, with
+// their comments.
+func (d *Backend) ExportIssues(ctx context.Context, repo string) ([]Issue, error) {
+	return d.issues(ctx, repo, "", true)
+}
+
+func (d *Backend) issues(ctx context.Context, repo string, state string, comments bool) ([]Issue, error) {
+	if state != "" && !ValidIssueState(state) {
+		return nil, fmt.Errorf("%w: unknown state %q", ErrInvalidIssue, state)
+	}
+
+	r, err := d.Repository(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	var ms []models.Issue
+	if err := d.retryTx(ctx, "issues", func(tx *db.Tx) error {
+		var err error
+		ms, err = d.store.GetIssues(ctx, tx, r.ID(), state)
+		return err
+	}); err != nil {
+		return nil, db.WrapError(err)
+	}
+
+	issues := make([]Issue, 0, len(ms))
+	for _, m := range ms {
+		issue, err := d.newIssue(ctx, m, comments)
+		if err != nil {
+			return nil, err
+		}
+		issues = append(issues, issue)
+	}
+
+	return issues, nil
+}
+
+// issue returns a repository and its issue with a number. It returns
+// proto.ErrIssueNotFound if there's no such issue.
+func (d *Backend) issue(ctx context.Context, repo string, number int64) (proto.Repository, models.Issue, error) {
+	r, err := d.Repository(ctx, repo)
+	if err != nil {
+		return nil, models.Issue{}, err
+	}
+
+	var m models.Issue
+	if err := d.retryTx(ctx, "issue", func(tx *db.Tx) error {
+		var err error
+		m, err = d.store.GetIssue(ctx, tx, r.ID(), number)
+		return err
+	}); err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return nil, models.Issue{}, proto.ErrIssueNotFound
+		}
+		return nil, models.Issue{}, db.WrapError(err)
+	}
+
+	return r, m, nil
+}
+
+// newIssue returns an issue, with its comments if comments is set.
+func (d *Backend) newIssue(ctx context.Context, m models.Issue, comments bool) (Issue, error) {
+	issue := Issue{
+		Number:       m.Number,
+		Title:        m.Title,
+		Body:         m.Body,
+		Username:     m.Username.String,
+		State:        m.State,
+		CommentCount: m.Comments,
+		CreatedAt:    m.CreatedAt,
+		UpdatedAt:    m.UpdatedAt,
+	}
+	if !comments {
+		return issue, nil
+	}
+
+	var cms []models.IssueComment
+	if err := d.retryTx(ctx, "issue_comments", func(tx *db.Tx) error {
+		var err error
+		cms, err = d.store.GetIssueComments(ctx, tx, m.ID)
+		return err
+	}); err != nil {
+		return Issue{}, db.WrapError(err)
+	}
+
+	issue.Comments = make([]IssueComment, 0, len(cms))
+	for _, cm := range cms {
+		issue.Comments = append(issue.Comments, IssueComment{
+			Username:  cm.Username.String,
+			Body:      cm.Body,
+			CreatedAt: cm.CreatedAt,
+		})
+	}
+
+	return issue, nil
+}
@@ -0,0 +1,127 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/soft-serve/git"
+	"github.com/charmbracelet/soft-serve/pkg/hooks"
+)
+
+// Repository setting keys for linear history.
+const (
+	settingLinearHistoryBranches = "linear_history_branches"
+	settingLinearHistoryWarn     = "linear_history_warn_only"
+)
+
+// LinearHistory is the linear history rule of a repository.
+type LinearHistory struct {
+	// Branches are the branch name patterns that require linear history.
+	// Patterns use the same syntax as path.Match.
+	Branches []string
+	// WarnOnly reports merge commits without rejecting the push.
+	WarnOnly bool
+}
+
+// LinearHistory returns the linear history rule of a repository.
+func (d *Backend) LinearHistory(ctx context.Context, repo string) (LinearHistory, error) {
+	var l LinearHistory
+	settings, err := d.RepoSettings(ctx, repo)
+	if err != nil {
+		return l, err
+	}
+
+	l.Branches, err = d.branchPatterns(ctx, repo, settingLinearHistoryBranches)
+	if err != nil {
+		return l, err
+	}
+
+	l.WarnOnly, _ = strconv.ParseBool(settings[settingLinearHistoryWarn])
+	return l, nil
+}
+
+// SetLinearHistory sets the linear history rule of a repository. No branches
+// disables the rule.
+func (d *Backend) SetLinearHistory(ctx context.Context, repo string, l LinearHistory) error {
+	if err := d.setBranchPatterns(ctx, repo, settingLinearHistoryBranches, l.Branches); err != nil {
+		return err
+	}
+
+	var warn string
+	if l.WarnOnly {
+		warn = "true"
+	}
+
+	return d.SetRepoSettings(ctx, repo, map[string]string{settingLinearHistoryWarn: warn})
+}
+
+// checkLinearHistory returns an error if the push introduces merge commits to
+// a branch that requires linear history. The initial push to an empty
+// repository is exempt so existing projects can be imported. It's meant to be
+// called from the pre-receive hook.
+func (d *Backend) checkLinearHistory(ctx context.Context, stderr io.Writer, repo string, args []hooks.HookArg) error {
+	l, err := d.LinearHistory(ctx, repo)
+	if err != nil || len(l.Branches) == 0 {
+		return err
+	}
+
+	r, err := d.Repository(ctx, repo)
+	if err != nil {
+		return err
+	}
+
+	rr, err := r.Open()
+	if err != nil {
+		return err
+	}
+
+	// The pre-receive hook runs before any ref is updated, so an empty repo
+	// means this is the initial import.
+	refs, err := git.NewCommand("for-each-ref", "--count=1").WithContext(ctx).RunInDir(rr.Path)
+	if err != nil {
+		return err
+	}
+
+	if len(strings.TrimSpace(string(refs))) == 0 {
+		return nil
+	}
+
+	for _, arg := range args {
+		branch, ok := matchBranch(l.Branches, arg.RefName)
+		if !ok || git.IsZeroHash(arg.NewSha) {
+			continue
+		}
+
+		// Only check the commits introduced to the branch. New branches are
+		// checked against all the existing refs.
+		revs := []string{"rev-list", "--merges", "--max-count=1", arg.NewSha}
+		if git.IsZeroHash(arg.OldSha) {
+			revs = append(revs, "--not", "--all")
+		} else {
+			revs = append(revs, "^"+arg.OldSha)
+		}
+
+		out, err := git.NewCommand(revs...).WithContext(ctx).RunInDir(rr.Path)
+		if err != nil {
+			return err
+		}
+
+		merge := strings.TrimSpace(string(out))
+		if merge == "" {
+			continue
+		}
+
+		msg := fmt.Sprintf("merge commit %s is not allowed on %s, the branch requires linear history", merge, branch)
+		if l.WarnOnly {
+			warnPolicy(ctx, stderr, policyLinearHistory, msg)
+			continue
+		}
+
+		return fmt.Errorf("%s; rebase your changes instead", msg)
+	}
+
+	return nil
+}
@@ -0,0 +1,260 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/charmbracelet/soft-serve/pkg/access"
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/db/models"
+	"github.com/charmbracelet/soft-serve/pkg/sshutils"
+	gossh "golang.org/x/crypto/ssh"
+)
+
+// fingerprintPrefix is the prefix of SHA256 public key fingerprints.
+const fingerprintPrefix = "SHA256:"
+
+// ErrInvalidFingerprint is returned when a public key fingerprint is invalid.
+var ErrInvalidFingerprint = errors.New("invalid key fingerprint, expected SHA256:<base64>")
+
+// Lockdown returns whether the server is in lockdown. In lockdown, only
+// admins can access the server.
+func (d *Backend) Lockdown(ctx context.Context) bool {
+	enabled, err := d.lockdown(ctx)
+	if err != nil {
+		d.logger.Error("error getting lockdown", "err", err)
+		return false
+	}
+
+	return enabled
+}
+
+func (d *Backend) lockdown(ctx context.Context) (bool, error) {
+	var enabled bool
+	if err := d.retryTx(ctx, "lockdown", func(tx *db.Tx) error {
+		var err error
+		enabled, err = d.store.GetLockdown(ctx, tx)
+		return err
+	}); err != nil {
+		return false, err
+	}
+
+	return enabled, nil
+}
+
+// SetLockdown enters or lifts the lockdown.
+func (d *Backend) SetLockdown(ctx context.Context, enabled bool) error {
+	if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		return d.store.SetLockdown(ctx, tx, enabled)
+	}); err != nil {
+		return db.WrapError(err)
+	}
+
+	action := AuditActionLockdownLifted
+	if enabled {
+		action = AuditActionLockdownEnabled
+	}
+
+	return d.recordAuditEvent(ctx, action, nil, nil, "")
+}
+
+// NormalizeFingerprint returns the SHA256 fingerprint fp with its "SHA256:"
+// prefix. fp can also be a line of `ssh-keygen -l`.
+func NormalizeFingerprint(fp string) (string, error) {
+	for _, f := range strings.Fields(fp) {
+		if strings.HasPrefix(f, fingerprintPrefix) {
+			fp = f
+			break
+		}
+	}
+
+	fp = strings.TrimPrefix(strings.TrimSpace(fp), fingerprintPrefix)
+	if fp == "" || strings.ContainsAny(fp, " \t:") {
+		return "", ErrInvalidFingerprint
+	}
+
+	return fingerprintPrefix + fp, nil
+}
+
+// RevokeKeys revokes the public keys with the given fingerprints. Revoked
+// keys, including admin keys, can't authenticate and their sessions are
+// terminated. Revoking an already revoked key is a no-op.
+func (d *Backend) RevokeKeys(ctx context.Context, fingerprints ...string) error {
+	fps, err := normalizeFingerprints(fingerprints)
+	if err != nil {
+		return err
+	}
+
+	var revoked []string
+	if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		revoked = revoked[:0]
+		for _, fp := range fps {
+			ok, err := d.store.IsKeyRevoked(ctx, tx, fp)
+			if err != nil {
+				return err
+			}
+			if ok {
+				continue
+			}
+
+			if err := d.store.CreateRevokedKey(ctx, tx, fp); err != nil {
+				return err
+			}
+			revoked = append(revoked, fp)
+		}
+		return nil
+	}); err != nil {
+		return db.WrapError(err)
+	}
+
+	for _, fp := range revoked {
+		if err := d.recordAuditEvent(ctx, AuditActionKeyRevoked, nil, nil, fp); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RestoreKeys restores the revoked public keys with the given fingerprints.
+// Restoring a key that isn't revoked is a no-op.
+func (d *Backend) RestoreKeys(ctx context.Context, fingerprints ...string) error {
+	fps, err := normalizeFingerprints(fingerprints)
+	if err != nil {
+		return err
+	}
+
+	var restored []string
+	if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		restored = restored[:0]
+		for _, fp := range fps {
+			ok, err := d.store.IsKeyRevoked(ctx, tx, fp)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				continue
+			}
+
+			if err := d.store.DeleteRevokedKey(ctx, tx, fp); err != nil {
+				return err
+			}
+			restored = append(restored, fp)
+		}
+		return nil
+	}); err != nil {
+		return db.WrapError(err)
+	}
+
+	for _, fp := range restored {
+		if err := d.recordAuditEvent(ctx, AuditActionKeyRestored, nil, nil, fp); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RevokedKeys returns the revoked public keys, oldest first.
+func (d *Backend) RevokedKeys(ctx context.Context) ([]models.RevokedKey, error) {
+	var keys []models.RevokedKey
+	if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		var err error
+		keys, err = d.store.GetRevokedKeys(ctx, tx)
+		return err
+	}); err != nil {
+		return nil, db.WrapError(err)
+	}
+
+	return keys, nil
+}
+
+// IsKeyRevoked returns whether a public key is revoked.
+func (d *Backend) IsKeyRevoked(ctx context.Context, pk gossh.PublicKey) bool {
+	revoked, err := d.isKeyRevoked(ctx, pk)
+	if err != nil {
+		d.logger.Error("error checking revoked key", "err", err)
+		return false
+	}
+
+	return revoked
+}
+
+func (d *Backend) isKeyRevoked(ctx context.Context, pk gossh.PublicKey) (bool, error) {
+	if pk == nil {
+		return false, nil
+	}
+
+	var revoked bool
+	if err := d.retryTx(ctx, "is_key_revoked", func(tx *db.Tx) error {
+		var err error
+		revoked, err = d.store.IsKeyRevoked(ctx, tx, gossh.FingerprintSHA256(pk))
+		return err
+	}); err != nil {
+		return false, err
+	}
+
+	return revoked, nil
+}
+
+// KeyDenied returns whether a public key is denied access to the server,
+// either because it's revoked or because the server is in lockdown and the
+// key doesn't belong to an admin. A nil key is a keyless client, denied in
+// lockdown. On backend errors, the key is denied unless the server is
+// configured to fail open for reads.
+func (d *Backend) KeyDenied(ctx context.Context, pk gossh.PublicKey) bool {
+	revoked, err := d.isKeyRevoked(ctx, pk)
+	if err != nil {
+		return d.accessLevelOnError("", err) < access.ReadOnlyAccess
+	}
+	if revoked {
+		return true
+	}
+
+	locked, err := d.lockdown(ctx)
+	if err != nil {
+		return d.accessLevelOnError("", err) < access.ReadOnlyAccess
+	}
+	if !locked {
+		return false
+	}
+
+	return !d.IsAdminKey(ctx, pk)
+}
+
+// IsAdminKey returns whether a public key is an admin key or belongs to an
+// admin user.
+func (d *Backend) IsAdminKey(ctx context.Context, pk gossh.PublicKey) bool {
+	if pk == nil {
+		return false
+	}
+
+	for _, k := range d.cfg.AdminKeys() {
+		if sshutils.KeysEqual(pk, k) {
+			return true
+		}
+	}
+
+	user, _ := d.UserByPublicKey(ctx, pk)
+	return user != nil && user.IsAdmin()
+}
+
+// normalizeFingerprints normalizes fingerprints, dropping duplicates.
+func normalizeFingerprints(fingerprints []string) ([]string, error) {
+	fps := make([]string, 0, len(fingerprints))
+	seen := map[string]struct{}{}
+	for _, fp := range fingerprints {
+		fp, err := NormalizeFingerprint(fp)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := seen[fp]; ok {
+			continue
+		}
+		seen[fp] = struct{}{}
+		fps = append(fps, fp)
+	}
+
+	return fps, nil
+}
@@ -0,0 +1,310 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/soft-serve/pkg/access"
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/proto"
+	"github.com/charmbracelet/soft-serve/pkg/utils"
+	"gopkg.in/yaml.v3"
+)
+
+// Manifest is the desired state of the server repositories and their
+// collaborators.
+type Manifest struct {
+	Repos []ManifestRepo `yaml:"repos"`
+}
+
+// ManifestRepo is the desired state of a repository.
+type ManifestRepo struct {
+	Name        string `yaml:"name"`
+	ProjectName string `yaml:"project_name"`
+	Description string `yaml:"description"`
+	Private     bool   `yaml:"private"`
+	Hidden      bool   `yaml:"hidden"`
+	// Collaborators maps usernames to their access level.
+	Collaborators map[string]access.AccessLevel `yaml:"collaborators"`
+}
+
+// ParseManifest reads and validates a manifest file.
+func ParseManifest(path string) (Manifest, error) {
+	var m Manifest
+	f, err := os.Open(path)
+	if err != nil {
+		return m, err
+	}
+
+	defer f.Close() // nolint: errcheck
+	dec := yaml.NewDecoder(f)
+	dec.KnownFields(true)
+	if err := dec.Decode(&m); err != nil {
+		return m, fmt.Errorf("parse manifest: %w", err)
+	}
+
+	seen := map[string]struct{}{}
+	for i, r := range m.Repos {
+		r.Name = utils.SanitizeRepo(r.Name)
+		if err := utils.ValidateRepo(r.Name); err != nil {
+			return m, fmt.Errorf("repo %q: %w", r.Name, err)
+		}
+
+		if _, ok := seen[r.Name]; ok {
+			return m, fmt.Errorf("repo %q: defined more than once", r.Name)
+		}
+		seen[r.Name] = struct{}{}
+
+		collabs := make(map[string]access.AccessLevel, len(r.Collaborators))
+		for username, level := range r.Collaborators {
+			username = strings.ToLower(username)
+			if err := utils.ValidateUsername(username); err != nil {
+				return m, fmt.Errorf("repo %q: collaborator %q: %w", r.Name, username, err)
+			}
+			collabs[username] = level
+		}
+		r.Collaborators = collabs
+
+		m.Repos[i] = r
+	}
+
+	return m, nil
+}
+
+// Change is a single change needed to converge the server to a manifest.
+type Change struct {
+	// Repo is the name of the repository the change applies to.
+	Repo string
+	// Description describes the change.
+	Description string
+	// Delete is true if the change removes a repository or collaborator.
+	Delete bool
+
+	apply func(ctx context.Context) error
+}
+
+// String implements fmt.Stringer.
+func (c Change) String() string {
+	return fmt.Sprintf("%s: %s", c.Repo, c.Description)
+}
+
+// Plan returns the changes needed to make the server match the manifest. The
+// changes are ordered so that repositories are created before their
+// collaborators are added.
+func (d *Backend) Plan(ctx context.Context, m Manifest) ([]Change, error) {
+	repos, err := d.Repositories(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	existing := make(map[string]proto.Repository, len(repos))
+	for _, r := range repos {
+		existing[r.Name()] = r
+	}
+
+	var changes []Change
+	desired := map[string]struct{}{}
+	for _, mr := range m.Repos {
+		mr := mr
+		desired[mr.Name] = struct{}{}
+
+		r, ok := existing[mr.Name]
+		if !ok {
+			changes = append(changes, Change{
+				Repo:        mr.Name,
+				Description: "create repository",
+				apply: func(ctx context.Context) error {
+					return d.createManifestRepo(ctx, mr)
+				},
+			})
+		} else {
+			changes = append(changes, d.planRepoUpdate(r, mr)...)
+		}
+
+		collabChanges, err := d.planCollaborators(ctx, mr, ok)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, collabChanges...)
+	}
+
+	names := make([]string, 0, len(existing))
+	for name := range existing {
+		if _, ok := desired[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		name := name
+		changes = append(changes, Change{
+			Repo:        name,
+			Description: "delete repository",
+			Delete:      true,
+			apply: func(ctx context.Context) error {
+				return d.DeleteRepository(ctx, name)
+			},
+		})
+	}
+
+	return changes, nil
+}
+
+// ApplyChange applies a planned change. Changes are made on behalf of the
+// user in the context, or the oldest admin user if there is none.
+func (d *Backend) ApplyChange(ctx context.Context, c Change) error {
+	if c.apply == nil {
+		return errors.New("invalid change")
+	}
+
+	if proto.UserFromContext(ctx) == nil {
+		var user proto.User
+		if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+			id, err := d.firstAdminID(ctx, tx)
+			if err != nil {
+				return err
+			}
+
+			user, err = d.UserByID(ctx, id)
+			return err
+		}); err != nil {
+			return db.WrapError(err)
+		}
+
+		ctx = proto.WithUserContext(ctx, user)
+	}
+
+	return c.apply(ctx)
+}
+
+func (d *Backend) createManifestRepo(ctx context.Context, mr ManifestRepo) error {
+	owner := proto.UserFromContext(ctx)
+	_, err := d.CreateRepository(ctx, mr.Name, owner, proto.RepositoryOptions{
+		Private:            mr.Private,
+		Hidden:             mr.Hidden,
+		Description:        mr.Description,
+		ProjectName:        mr.ProjectName,
+		ExplicitVisibility: true,
+	})
+	return err
+}
+
+func (d *Backend) planRepoUpdate(r proto.Repository, mr ManifestRepo) []Change {
+	var changes []Change
+	name := mr.Name
+	if r.ProjectName() != mr.ProjectName {
+		changes = append(changes, Change{
+			Repo:        name,
+			Description: fmt.Sprintf("set project name to %q", mr.ProjectName),
+			apply: func(ctx context.Context) error {
+				return d.SetProjectName(ctx, name, mr.ProjectName)
+			},
+		})
+	}
+
+	if r.Description() != mr.Description {
+		changes = append(changes, Change{
+			Repo:        name,
+			Description: fmt.Sprintf("set description to %q", mr.Description),
+			apply: func(ctx context.Context) error {
+				return d.SetDescription(ctx, name, mr.Description)
+			},
+		})
+	}
+
+	if r.IsPrivate() != mr.Private {
+		changes = append(changes, Change{
+			Repo:        name,
+			Description: fmt.Sprintf("set private to %t", mr.Private),
+			apply: func(ctx context.Context) error {
+				return d.SetPrivate(ctx, name, mr.Private)
+			},
+		})
+	}
+
+	if r.IsHidden() != mr.Hidden {
+		changes = append(changes, Change{
+			Repo:        name,
+			Description: fmt.Sprintf("set hidden to %t", mr.Hidden),
+			apply: func(ctx context.Context) error {
+				return d.SetHidden(ctx, name, mr.Hidden)
+			},
+		})
+	}
+
+	return changes
+}
+
+func (d *Backend) planCollaborators(ctx context.Context, mr ManifestRepo, exists bool) ([]Change, error) {
+	current := map[string]access.AccessLevel{}
+	if exists {
+		usernames, err := d.Collaborators(ctx, mr.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, username := range usernames {
+			level, _, err := d.IsCollaborator(ctx, mr.Name, username)
+			if err != nil {
+				return nil, err
+			}
+			current[username] = level
+		}
+	}
+
+	usernames := make([]string, 0, len(mr.Collaborators)+len(current))
+	for username := range mr.Collaborators {
+		usernames = append(usernames, username)
+	}
+	for username := range current {
+		if _, ok := mr.Collaborators[username]; !ok {
+			usernames = append(usernames, username)
+		}
+	}
+	sort.Strings(usernames)
+
+	var changes []Change
+	name := mr.Name
+	for _, username := range usernames {
+		username := username
+		want, desired := mr.Collaborators[username]
+		have, ok := current[username]
+		switch {
+		case desired && !ok:
+			changes = append(changes, Change{
+				Repo:        name,
+				Description: fmt.Sprintf("add collaborator %s with %s access", username, want),
+				apply: func(ctx context.Context) error {
+					return d.AddCollaborator(ctx, name, username, want)
+				},
+			})
+		case desired && have != want:
+			changes = append(changes, Change{
+				Repo:        name,
+				Description: fmt.Sprintf("change collaborator %s access from %s to %s", username, have, want),
+				apply: func(ctx context.Context) error {
+					if err := d.RemoveCollaborator(ctx, name, username); err != nil {
+						return err
+					}
+					return d.AddCollaborator(ctx, name, username, want)
+				},
+			})
+		case !desired:
+			changes = append(changes, Change{
+				Repo:        name,
+				Description: fmt.Sprintf("remove collaborator %s", username),
+				Delete:      true,
+				apply: func(ctx context.Context) error {
+					return d.RemoveCollaborator(ctx, name, username)
+				},
+			})
+		}
+	}
+
+	return changes, nil
+}
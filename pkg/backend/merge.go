@@ -0,0 +1,228 @@
+package backend
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+
+	"github.com/charmbracelet/soft-serve/git"
+	"github.com/charmbracelet/soft-serve/pkg/access"
+	"github.com/charmbracelet/soft-serve/pkg/hooks"
+	"github.com/charmbracelet/soft-serve/pkg/proto"
+)
+
+// Merge modes.
+const (
+	// MergeFastForward fast-forwards the target branch when possible, and
+	// creates a merge commit otherwise.
+	MergeFastForward = "ff"
+	// MergeFastForwardOnly only fast-forwards the target branch.
+	MergeFastForwardOnly = "ff-only"
+	// MergeNoFastForward always creates a merge commit.
+	MergeNoFastForward = "no-ff"
+)
+
+// ErrMergeFailed is returned when a merge can't be done, like on conflicts.
+var ErrMergeFailed = errors.New("merge failed")
+
+// MergeOptions are the options of a server-side merge.
+type MergeOptions struct {
+	// Mode is one of MergeFastForward, MergeFastForwardOnly, or
+	// MergeNoFastForward. It defaults to MergeFastForward.
+	Mode string
+	// Message is the message of the merge commit. It defaults to
+	// "Merge <source> into <target>".
+	Message string
+}
+
+// MergeResult is the outcome of a server-side merge.
+type MergeResult struct {
+	// Before is the commit of the target branch before the merge.
+	Before string
+	// After is the commit of the target branch after the merge. It's the
+	// same as Before when the target branch was up to date.
+	After string
+	// FastForward is whether the target branch was fast-forwarded, as
+	// opposed to getting a merge commit.
+	FastForward bool
+}
+
+// UpToDate returns whether the target branch already contained the source.
+func (m MergeResult) UpToDate() bool {
+	return m.Before == m.After
+}
+
+// Merge merges a source revision, like a branch or a review ref, into a
+// target branch without a checkout. The result goes through the same policies
+// as a push to the target branch, and is sent to the push webhooks. Conflicts
+// and rejected results return ErrMergeFailed, and leave the target branch as
+// is.
+func (d *Backend) Merge(ctx context.Context, stderr io.Writer, repo string, user proto.User, source string, target string, opts MergeOptions) (MergeResult, error) {
+	if err := d.checkWritable(); err != nil {
+		return MergeResult{}, err
+	}
+
+	if user == nil || !d.HasCapability(ctx, repo, user, access.CapabilityMerge) {
+		return MergeResult{}, proto.ErrUnauthorized
+	}
+
+	switch opts.Mode {
+	case "":
+		opts.Mode = MergeFastForward
+	case MergeFastForward, MergeFastForwardOnly, MergeNoFastForward:
+	default:
+		return MergeResult{}, fmt.Errorf("unknown merge mode %q", opts.Mode)
+	}
+
+	if source == "" || strings.HasPrefix(source, "-") || strings.ContainsAny(source, " \t\n\r") {
+		return MergeResult{}, fmt.Errorf("%w: invalid source %q", ErrMergeFailed, source)
+	}
+
+	// The policies run as the user of the merge.
+	ctx = proto.WithUserContext(ctx, user)
+
+	unlock, err := d.LockRepositoryForPush(ctx, repo)
+	if err != nil {
+		return MergeResult{}, err
+	}
+	defer unlock()
+
+	r, err := d.Repository(ctx, repo)
+	if err != nil {
+		return MergeResult{}, err
+	}
+
+	rr, err := r.Open()
+	if err != nil {
+		return MergeResult{}, err
+	}
+
+	ref := git.RefsHeads + target
+	if _, err := git.NewCommand("check-ref-format", ref).WithContext(ctx).RunInDir(rr.Path); err != nil {
+		return MergeResult{}, fmt.Errorf("%w: %q", proto.ErrInvalidBranch, target)
+	}
+
+	before, err := revParse(ctx, rr.Path, ref)
+	if err != nil {
+		return MergeResult{}, fmt.Errorf("%w: %q", git.ErrReferenceNotExist, target)
+	}
+
+	src, err := revParse(ctx, rr.Path, source+"^{commit}")
+	if err != nil {
+		return MergeResult{}, fmt.Errorf("%w: %q", git.ErrReferenceNotExist, source)
+	}
+
+	res := MergeResult{Before: before, After: before}
+	if isAncestor(ctx, rr.Path, src, before) {
+		return res, nil
+	}
+
+	if opts.Mode != MergeNoFastForward && isAncestor(ctx, rr.Path, before, src) {
+		res.After = src
+		res.FastForward = true
+	} else if opts.Mode == MergeFastForwardOnly {
+		return MergeResult{}, fmt.Errorf("%w: %s can't be fast-forwarded to %s", ErrMergeFailed, target, source)
+	} else {
+		msg := opts.Message
+		if msg == "" {
+			msg = fmt.Sprintf("Merge %s into %s", source, target)
+		}
+		res.After, err = d.mergeCommit(ctx, rr.Path, user, before, src, msg)
+		if err != nil {
+			return MergeResult{}, err
+		}
+	}
+
+	args := []hooks.HookArg{{OldSha: before, NewSha: res.After, RefName: ref}}
+	if err := d.PreReceive(ctx, io.Discard, stderr, repo, args); err != nil {
+		return MergeResult{}, fmt.Errorf("%w: %w", ErrMergeFailed, err)
+	}
+
+	if _, err := git.NewCommand("update-ref", ref, res.After, before).WithContext(ctx).RunInDir(rr.Path); err != nil {
+		return MergeResult{}, fmt.Errorf("update %s: %w", target, err)
+	}
+
+	d.logger.Info("merged", "repo", repo, "source", source, "target", target, "commit", res.After, "fast-forward", res.FastForward, "user", user.Username())
+	details := fmt.Sprintf("%s %s %s", source, target, res.After)
+	if err := d.recordAuditEvent(ctx, AuditActionMerged, r, user, details); err != nil {
+		d.logger.Error("error recording merge", "repo", repo, "err", err)
+	}
+
+	// Merges are pushes as far as everything else is concerned.
+	d.PostReceive(ctx, io.Discard, io.Discard, repo, args)
+	d.PostUpdate(ctx, io.Discard, io.Discard, repo, ref)
+
+	return res, nil
+}
+
+// mergeCommit writes a merge commit of two commits and returns it. It returns
+// ErrMergeFailed with the conflicting files on conflicts.
+func (d *Backend) mergeCommit(ctx context.Context, dir string, user proto.User, ours string, theirs string, msg string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	if err := git.NewCommand("merge-tree", "--write-tree", "--name-only", "--no-messages", ours, theirs).
+		WithContext(ctx).RunInDirPipeline(&stdout, &stderr, dir); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+			// The first line is the tree, the others the conflicting files.
+			files := strings.Fields(stdout.String())
+			if len(files) > 0 {
+				files = files[1:]
+			}
+			return "", fmt.Errorf("%w: conflicts in %s", ErrMergeFailed, strings.Join(files, ", "))
+		}
+		return "", fmt.Errorf("merge-tree: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	tree := strings.TrimSpace(stdout.String())
+
+	out, err := git.NewCommand("commit-tree", tree, "-p", ours, "-p", theirs, "-m", msg).
+		AddEnvs(d.commitIdentity(user)...).
+		WithContext(ctx).RunInDir(dir)
+	if err != nil {
+		return "", fmt.Errorf("commit-tree: %w", err)
+	}
+
+	return strings.TrimSpace(string(out)), nil
+}
+
+// commitIdentity returns the environment of the git commands writing commits
+// on behalf of a user, authored and committed by them. Commits without a user
+// are written by Soft Serve.
+func (d *Backend) commitIdentity(user proto.User) []string {
+	name := "Soft Serve"
+	local := "soft-serve"
+	if user != nil {
+		name, local = user.Username(), user.Username()
+	}
+
+	host := d.cfg.PublicHost
+	if host == "" {
+		host = "localhost"
+	}
+	email := local + "@" + host
+
+	return []string{
+		"GIT_AUTHOR_NAME=" + name, "GIT_AUTHOR_EMAIL=" + email,
+		"GIT_COMMITTER_NAME=" + name, "GIT_COMMITTER_EMAIL=" + email,
+	}
+}
+
+// revParse returns the object name of a revision.
+func revParse(ctx context.Context, dir string, rev string) (string, error) {
+	out, err := git.NewCommand("rev-parse", "--verify", "--quiet", rev).WithContext(ctx).RunInDir(dir)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(string(out)), nil
+}
+
+// isAncestor returns whether a commit is an ancestor of another one, or the
+// same commit.
+func isAncestor(ctx context.Context, dir string, ancestor string, commit string) bool {
+	_, err := git.NewCommand("merge-base", "--is-ancestor", ancestor, commit).WithContext(ctx).RunInDir(dir)
+	return err == nil
+}
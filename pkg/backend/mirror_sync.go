@@ -0,0 +1,351 @@
+package backend
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	gitm "github.com/aymanbagabas/git-module"
+	"github.com/charmbracelet/soft-serve/git"
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/db/models"
+	"github.com/charmbracelet/soft-serve/pkg/lfs"
+	"github.com/charmbracelet/soft-serve/pkg/proto"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Pull mirror sync statuses.
+const (
+	MirrorSyncStatusOK = "ok"
+	// MirrorSyncStatusAuthFailed means the upstream rejected the server
+	// credentials, or asked for credentials the server doesn't have.
+	MirrorSyncStatusAuthFailed = "auth-failed"
+	// MirrorSyncStatusNetworkFailed means the upstream couldn't be reached.
+	MirrorSyncStatusNetworkFailed = "network-failed"
+	// MirrorSyncStatusFailed is any other failure, like a missing upstream
+	// repository.
+	MirrorSyncStatusFailed = "failed"
+)
+
+var (
+	mirrorSyncCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "soft_serve",
+		Subsystem: "mirror",
+		Name:      "syncs_total",
+		Help:      "The total number of pull mirror syncs, by status",
+	}, []string{"repo", "status"})
+
+	mirrorSyncObjectsCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "soft_serve",
+		Subsystem: "mirror",
+		Name:      "sync_objects_total",
+		Help:      "The total number of new objects fetched by pull mirror syncs",
+	}, []string{"repo"})
+
+	mirrorSyncRefsCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "soft_serve",
+		Subsystem: "mirror",
+		Name:      "sync_refs_total",
+		Help:      "The total number of refs created, updated, or deleted by pull mirror syncs",
+	}, []string{"repo"})
+
+	mirrorSyncBytesCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "soft_serve",
+		Subsystem: "mirror",
+		Name:      "sync_bytes_total",
+		Help:      "The total number of bytes fetched by pull mirror syncs",
+	}, []string{"repo"})
+
+	mirrorSyncLastSuccess = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "soft_serve",
+		Subsystem: "mirror",
+		Name:      "last_success_timestamp_seconds",
+		Help:      "The time of the last successful pull mirror sync",
+	}, []string{"repo"})
+)
+
+// SyncMirror fetches the upstream of a pull mirror, then records and returns
+// the result. The new objects and fetched bytes are measured on disk, so they
+// approximate what was transferred. A failed sync returns its error along
+// with the recorded result.
+func (d *Backend) SyncMirror(ctx context.Context, repo proto.Repository) (models.MirrorSync, error) {
+	sync := models.MirrorSync{RepoID: repo.ID(), Status: MirrorSyncStatusOK}
+	if !repo.IsMirror() {
+		return sync, fmt.Errorf("repository %q is not a mirror", repo.Name())
+	}
+
+	unlock, err := d.LockRepository(ctx, repo.Name(), "mirror-sync")
+	if err != nil {
+		return sync, err
+	}
+	defer unlock()
+
+	r, err := repo.Open()
+	if err != nil {
+		return sync, err
+	}
+
+	start := time.Now()
+	syncErr := d.fetchMirror(ctx, repo, r, &sync)
+	sync.DurationMS = time.Since(start).Milliseconds()
+	if syncErr != nil {
+		sync.Status = mirrorSyncFailure(syncErr)
+		sync.Error = sql.NullString{String: syncErr.Error(), Valid: true}
+	}
+
+	if err := db.WrapError(
+		d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+			return d.store.CreateMirrorSync(ctx, tx, sync)
+		}),
+	); err != nil {
+		d.logger.Error("failed to record mirror sync", "repo", repo.Name(), "err", err)
+	}
+
+	name := repo.Name()
+	mirrorSyncCounter.WithLabelValues(name, sync.Status).Inc()
+	mirrorSyncObjectsCounter.WithLabelValues(name).Add(float64(sync.NewObjects))
+	mirrorSyncRefsCounter.WithLabelValues(name).Add(float64(sync.UpdatedRefs))
+	mirrorSyncBytesCounter.WithLabelValues(name).Add(float64(sync.FetchedBytes))
+	if syncErr == nil {
+		mirrorSyncLastSuccess.WithLabelValues(name).SetToCurrentTime()
+	}
+
+	return sync, syncErr
+}
+
+// MirrorSyncs returns the latest syncs of a pull mirror, newest first.
+func (d *Backend) MirrorSyncs(ctx context.Context, repo string, limit int) ([]models.MirrorSync, error) {
+	r, err := d.Repository(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	var syncs []models.MirrorSync
+	if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		var err error
+		syncs, err = d.store.GetRecentMirrorSyncsByRepoID(ctx, tx, r.ID(), limit)
+		return err
+	}); err != nil {
+		return nil, db.WrapError(err)
+	}
+
+	return syncs, nil
+}
+
+// fetchMirror fetches the upstream of a mirror and its missing LFS objects,
+// and counts what changed in sync.
+func (d *Backend) fetchMirror(ctx context.Context, repo proto.Repository, r *git.Repository, sync *models.MirrorSync) error {
+	rcfg, err := r.Config()
+	if err != nil {
+		return err
+	}
+
+	// Never store or log the remote credentials.
+	remote := rcfg.Section("remote").Subsection("origin").Option("url")
+	redact := func(err error) error {
+		if remote == "" {
+			return err
+		}
+		return errors.New(strings.ReplaceAll(err.Error(), remote, RedactURL(remote)))
+	}
+
+	refsBefore, err := mirrorRefs(r.Path)
+	if err != nil {
+		return err
+	}
+	objectsBefore, bytesBefore, err := mirrorObjects(r.Path)
+	if err != nil {
+		return err
+	}
+
+	credentials := d.mirrorCredentialEnvs(ctx, repo.Name(), remote)
+
+	cmds := []string{
+		"fetch --prune",         // fetch prune before updating remote
+		"remote update --prune", // update remote and prune remote refs
+	}
+
+	var fetchErr error
+	for _, c := range cmds {
+		args := strings.Split(c, " ")
+		cmd := git.NewCommand(args...).WithContext(ctx)
+		cmd.AddEnvs(
+			"GIT_TERMINAL_PROMPT=0",
+			fmt.Sprintf(`GIT_SSH_COMMAND=ssh -o UserKnownHostsFile="%s" -o StrictHostKeyChecking=no -i "%s"`,
+				filepath.Join(d.cfg.DataPath, "ssh", "known_hosts"),
+				d.cfg.SSH.ClientKeyPath,
+			),
+		)
+		cmd.AddEnvs(credentials...)
+
+		if _, err := cmd.RunInDir(r.Path); errors.Is(err, gitm.ErrExecTimeout) {
+			fetchErr = err
+			break
+		} else if err != nil {
+			fetchErr = redact(err)
+			break
+		}
+	}
+
+	// Count what was fetched even if a command failed midway.
+	refsAfter, err := mirrorRefs(r.Path)
+	if err != nil {
+		return err
+	}
+	objectsAfter, bytesAfter, err := mirrorObjects(r.Path)
+	if err != nil {
+		return err
+	}
+
+	for ref, id := range refsAfter {
+		if refsBefore[ref] != id {
+			sync.UpdatedRefs++
+		}
+	}
+	for ref := range refsBefore {
+		if _, ok := refsAfter[ref]; !ok {
+			sync.UpdatedRefs++
+		}
+	}
+	// Automatic garbage collection can leave fewer objects than before.
+	sync.NewObjects = max(objectsAfter-objectsBefore, 0)
+	sync.FetchedBytes = max(bytesAfter-bytesBefore, 0)
+
+	if fetchErr != nil {
+		return fetchErr
+	}
+
+	if d.cfg.LFS.Enabled {
+		lfsEndpoint := rcfg.Section("lfs").Option("url")
+		if lfsEndpoint == "" {
+			// If there is no LFS url defined, means the repo
+			// doesn't use LFS and we can skip it.
+			return nil
+		}
+
+		ep, err := lfs.NewEndpoint(lfsEndpoint)
+		if err != nil {
+			return fmt.Errorf("create LFS endpoint: %w", err)
+		}
+
+		client := lfs.NewClient(ep)
+		if client == nil {
+			return fmt.Errorf("unsupported LFS endpoint %s", RedactURL(lfsEndpoint))
+		}
+
+		if err := StoreRepoMissingLFSObjects(ctx, repo, d.db, d.store, client); err != nil {
+			return redact(fmt.Errorf("store missing LFS objects: %w", err))
+		}
+	}
+
+	return nil
+}
+
+// mirrorRefs returns the object IDs of the refs of a repository.
+func mirrorRefs(path string) (map[string]string, error) {
+	out, err := git.NewCommand("for-each-ref", "--format=%(objectname) %(refname)").RunInDir(path)
+	if err != nil {
+		return nil, err
+	}
+
+	refs := map[string]string{}
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		if id, ref, ok := strings.Cut(line, " "); ok {
+			refs[ref] = id
+		}
+	}
+
+	return refs, nil
+}
+
+// mirrorObjects returns the number of objects of a repository and their size
+// on disk in bytes.
+func mirrorObjects(path string) (count int64, size int64, err error) {
+	out, err := git.NewCommand("count-objects", "-v").RunInDir(path)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	s := bufio.NewScanner(bytes.NewReader(out))
+	for s.Scan() {
+		key, value, ok := strings.Cut(s.Text(), ": ")
+		if !ok {
+			continue
+		}
+
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			continue
+		}
+
+		// Sizes are in KiB.
+		switch key {
+		case "count", "in-pack":
+			count += n
+		case "size", "size-pack":
+			size += n * 1024
+		}
+	}
+
+	return count, size, s.Err()
+}
+
+// mirrorSyncFailure classifies a failed sync from the error git printed.
+func mirrorSyncFailure(err error) string {
+	if errors.Is(err, gitm.ErrExecTimeout) || errors.Is(err, context.DeadlineExceeded) {
+		return MirrorSyncStatusNetworkFailed
+	}
+
+	msg := strings.ToLower(err.Error())
+	for _, s := range []string{
+		"authentication failed",
+		"permission denied",
+		"could not read username",
+		"could not read password",
+		"terminal prompts disabled",
+		"host key verification failed",
+		"returned error: 401",
+		"returned error: 403",
+	} {
+		if strings.Contains(msg, s) {
+			return MirrorSyncStatusAuthFailed
+		}
+	}
+
+	for _, s := range []string{
+		"returned error: 404",
+		"not found",
+		"does not appear to be a git repository",
+	} {
+		if strings.Contains(msg, s) {
+			return MirrorSyncStatusFailed
+		}
+	}
+
+	for _, s := range []string{
+		"could not resolve host",
+		"connection refused",
+		"connection timed out",
+		"operation timed out",
+		"network is unreachable",
+		"no route to host",
+		"connection reset",
+		"failed to connect",
+		"unable to access",
+		"the remote end hung up unexpectedly",
+	} {
+		if strings.Contains(msg, s) {
+			return MirrorSyncStatusNetworkFailed
+		}
+	}
+
+	return MirrorSyncStatusFailed
+}
@@ -0,0 +1,33 @@
+package backend
+
+import (
+	"errors"
+	"testing"
+
+	gitm "github.com/aymanbagabas/git-module"
+)
+
+func TestMirrorSyncFailure(t *testing.T) {
+	for _, c := range []struct {
+		err  string
+		want string
+	}{
+		{"exit status 128 - fatal: Authentication failed for 'https://example.com/repo.git/'", MirrorSyncStatusAuthFailed},
+		{"exit status 128 - fatal: could not read Username for 'https://example.com': terminal prompts disabled", MirrorSyncStatusAuthFailed},
+		{"exit status 128 - git@example.com: Permission denied (publickey).\nfatal: Could not read from remote repository.", MirrorSyncStatusAuthFailed},
+		{"exit status 128 - fatal: unable to access 'https://example.com/repo.git/': The requested URL returned error: 403", MirrorSyncStatusAuthFailed},
+		{"exit status 128 - fatal: unable to access 'https://example.com/repo.git/': Could not resolve host: example.com", MirrorSyncStatusNetworkFailed},
+		{"exit status 128 - ssh: connect to host example.com port 22: Connection refused\nfatal: Could not read from remote repository.", MirrorSyncStatusNetworkFailed},
+		{"exit status 128 - fatal: repository 'https://example.com/repo.git/' not found", MirrorSyncStatusFailed},
+		{"exit status 128 - fatal: '/srv/repo.git' does not appear to be a git repository", MirrorSyncStatusFailed},
+		{"exit status 1 - error: cannot lock ref 'refs/heads/main'", MirrorSyncStatusFailed},
+	} {
+		if got := mirrorSyncFailure(errors.New(c.err)); got != c.want {
+			t.Errorf("%q: expected %s, got %s", c.err, c.want, got)
+		}
+	}
+
+	if got := mirrorSyncFailure(gitm.ErrExecTimeout); got != MirrorSyncStatusNetworkFailed {
+		t.Errorf("timeout: expected %s, got %s", MirrorSyncStatusNetworkFailed, got)
+	}
+}
@@ -0,0 +1,75 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/soft-serve/pkg/access"
+	"github.com/charmbracelet/soft-serve/pkg/hooks"
+)
+
+// settingNotesAccess is the repository setting key for the access level
+// required to update notes refs.
+const settingNotesAccess = "notes_access"
+
+// notesRefPrefix is the prefix of git notes refs.
+const notesRefPrefix = "refs/notes/"
+
+// NotesAccess returns the access level required to update the notes refs
+// (refs/notes/*) of a repository. It defaults to read-write, the access level
+// required to push to the repository.
+func (d *Backend) NotesAccess(ctx context.Context, repo string) (access.AccessLevel, error) {
+	settings, err := d.RepoSettings(ctx, repo)
+	if err != nil {
+		return -1, err
+	}
+
+	if level := access.ParseAccessLevel(settings[settingNotesAccess]); level >= access.ReadWriteAccess {
+		return level, nil
+	}
+
+	return access.ReadWriteAccess, nil
+}
+
+// SetNotesAccess sets the access level required to update the notes refs of
+// a repository. The level must be read-write or admin-access.
+func (d *Backend) SetNotesAccess(ctx context.Context, repo string, level access.AccessLevel) error {
+	if level < access.ReadWriteAccess {
+		return fmt.Errorf("notes access must be %s or %s", access.ReadWriteAccess, access.AdminAccess)
+	}
+
+	value := level.String()
+	if level == access.ReadWriteAccess {
+		// Default, remove the setting.
+		value = ""
+	}
+
+	return d.SetRepoSettings(ctx, repo, map[string]string{settingNotesAccess: value})
+}
+
+// checkNotesAccess returns an error if the pushed refs update notes refs and
+// the user doesn't have the required access level.
+func (d *Backend) checkNotesAccess(ctx context.Context, repo string, args []hooks.HookArg) error {
+	var refs []string
+	for _, arg := range args {
+		if strings.HasPrefix(arg.RefName, notesRefPrefix) {
+			refs = append(refs, arg.RefName)
+		}
+	}
+
+	if len(refs) == 0 {
+		return nil
+	}
+
+	level, err := d.NotesAccess(ctx, repo)
+	if err != nil {
+		return err
+	}
+
+	if d.hookAccessLevel(ctx, repo) < level {
+		return fmt.Errorf("updating notes refs requires %s: %s", level, strings.Join(refs, ", "))
+	}
+
+	return nil
+}
@@ -0,0 +1,101 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/soft-serve/git"
+	"github.com/charmbracelet/soft-serve/pkg/proto"
+)
+
+// Object formats of repositories, the hash algorithm naming their objects.
+const (
+	// ObjectFormatSHA1 is the default object format.
+	ObjectFormatSHA1 = "sha1"
+	// ObjectFormatSHA256 needs git 2.29 or later on the server and clients.
+	ObjectFormatSHA256 = "sha256"
+)
+
+// settingObjectFormat is the repository setting recording the object format
+// of a repository when it's created.
+const settingObjectFormat = "object_format"
+
+// validateObjectFormat returns the object format with the default applied,
+// or an error if it's not supported.
+func validateObjectFormat(format string) (string, error) {
+	switch format {
+	case "":
+		return ObjectFormatSHA1, nil
+	case ObjectFormatSHA1, ObjectFormatSHA256:
+		return format, nil
+	default:
+		return "", fmt.Errorf("%w: %q, use %s or %s", proto.ErrInvalidObjectFormat, format, ObjectFormatSHA1, ObjectFormatSHA256)
+	}
+}
+
+// initRepository initializes a bare repository with the given object format.
+// Initializing an existing repository with another object format fails.
+func initRepository(ctx context.Context, rp string, format string) (*git.Repository, error) {
+	if format == ObjectFormatSHA1 {
+		return git.Init(rp, true)
+	}
+
+	if _, err := git.NewCommand("init", "--bare", "--object-format="+format, rp).WithContext(ctx).Run(); err != nil {
+		return nil, err
+	}
+
+	return git.Open(rp)
+}
+
+// repoObjectFormat returns the object format of the repository at dir, as
+// git sees it.
+func repoObjectFormat(ctx context.Context, dir string) (string, error) {
+	out, err := git.NewCommand("rev-parse", "--show-object-format").WithContext(ctx).RunInDir(dir)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(string(out)), nil
+}
+
+// ObjectFormat returns the object format of a repository. Repositories
+// created before object formats were recorded are asked to git.
+func (d *Backend) ObjectFormat(ctx context.Context, repo string) (string, error) {
+	settings, err := d.RepoSettings(ctx, repo)
+	if err != nil {
+		return "", err
+	}
+
+	if f := settings[settingObjectFormat]; f != "" {
+		return f, nil
+	}
+
+	r, err := d.Repository(ctx, repo)
+	if err != nil {
+		return "", err
+	}
+
+	rr, err := r.Open()
+	if err != nil {
+		return "", err
+	}
+
+	return repoObjectFormat(ctx, rr.Path)
+}
+
+// checkObjectFormat returns proto.ErrObjectFormatMismatch if the repository
+// at dir doesn't use the given object format. Objects can't be shared
+// between repositories of different formats.
+func checkObjectFormat(ctx context.Context, dir string, format string, what string) error {
+	f, err := repoObjectFormat(ctx, dir)
+	if err != nil {
+		return err
+	}
+
+	if f != format {
+		return fmt.Errorf("%w: %s uses %s, not %s", proto.ErrObjectFormatMismatch, what, f, format)
+	}
+
+	return nil
+}
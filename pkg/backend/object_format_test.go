@@ -0,0 +1,29 @@
+package backend
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/charmbracelet/soft-serve/pkg/proto"
+)
+
+func TestValidateObjectFormat(t *testing.T) {
+	cases := map[string]string{
+		"":       ObjectFormatSHA1,
+		"sha1":   ObjectFormatSHA1,
+		"sha256": ObjectFormatSHA256,
+	}
+
+	for in, want := range cases {
+		got, err := validateObjectFormat(in)
+		if err != nil || got != want {
+			t.Errorf("validateObjectFormat(%q) = %q, %v, want %q", in, got, err, want)
+		}
+	}
+
+	for _, in := range []string{"SHA256", "sha512", "md5"} {
+		if _, err := validateObjectFormat(in); !errors.Is(err, proto.ErrInvalidObjectFormat) {
+			t.Errorf("validateObjectFormat(%q) error = %v, want %v", in, err, proto.ErrInvalidObjectFormat)
+		}
+	}
+}
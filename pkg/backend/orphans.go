@@ -0,0 +1,181 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/hooks"
+	"github.com/charmbracelet/soft-serve/pkg/utils"
+)
+
+// OrphanReport lists the repositories whose on-disk directory and backend
+// metadata are out of sync.
+type OrphanReport struct {
+	// Directories are repository directories with no backend metadata.
+	Directories []string
+	// Metadata are backend repository records with no directory on disk.
+	Metadata []string
+}
+
+// Empty returns true if the report has no orphans.
+func (r OrphanReport) Empty() bool {
+	return len(r.Directories) == 0 && len(r.Metadata) == 0
+}
+
+// ScanOrphans walks the repositories directory and compares it against the
+// backend repository records.
+func (d *Backend) ScanOrphans(ctx context.Context) (OrphanReport, error) {
+	var report OrphanReport
+
+	dirs := map[string]struct{}{}
+	reposPath := d.reposPath()
+	if err := filepath.WalkDir(reposPath, func(path string, de fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) && path == reposPath {
+				return filepath.SkipDir
+			}
+			return err
+		}
+
+		if !de.IsDir() || path == reposPath || !strings.HasSuffix(de.Name(), ".git") {
+			return nil
+		}
+
+		rel, err := filepath.Rel(reposPath, path)
+		if err != nil {
+			return err
+		}
+
+		dirs[utils.SanitizeRepo(filepath.ToSlash(rel))] = struct{}{}
+
+		// Don't descend into repositories.
+		return filepath.SkipDir
+	}); err != nil {
+		return report, err
+	}
+
+	names := map[string]struct{}{}
+	if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		ms, err := d.store.GetAllRepos(ctx, tx)
+		if err != nil {
+			return err
+		}
+
+		for _, m := range ms {
+			names[m.Name] = struct{}{}
+		}
+
+		return nil
+	}); err != nil {
+		return report, db.WrapError(err)
+	}
+
+	for name := range dirs {
+		if _, ok := names[name]; !ok {
+			report.Directories = append(report.Directories, name)
+		}
+	}
+
+	for name := range names {
+		if _, ok := dirs[name]; !ok {
+			report.Metadata = append(report.Metadata, name)
+		}
+	}
+
+	sort.Strings(report.Directories)
+	sort.Strings(report.Metadata)
+
+	return report, nil
+}
+
+// RegisterRepository creates the backend metadata for an existing repository
+// directory that has none. The description and visibility are read from the
+// repository directory, and the repository is owned by the first admin user.
+func (d *Backend) RegisterRepository(ctx context.Context, name string) error {
+	name = utils.SanitizeRepo(name)
+	if err := utils.ValidateRepo(name); err != nil {
+		return err
+	}
+
+	repo := name + ".git"
+	rp := filepath.Join(d.reposPath(), repo)
+	if _, err := os.Stat(rp); err != nil {
+		return err
+	}
+
+	desc, _ := readOneline(filepath.Join(rp, "description"))
+	if strings.HasPrefix(desc, "Unnamed repository;") {
+		// Default description created by git-init.
+		desc = ""
+	}
+	_, err := os.Stat(filepath.Join(rp, "git-daemon-export-ok"))
+	private := err != nil
+
+	if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		userID, err := d.firstAdminID(ctx, tx)
+		if err != nil {
+			return err
+		}
+
+		if err := d.store.CreateRepo(ctx, tx, name, userID, "", desc, private, false, false); err != nil {
+			return err
+		}
+
+		return hooks.GenerateHooks(ctx, d.cfg, repo)
+	}); err != nil {
+		return db.WrapError(err)
+	}
+
+	return nil
+}
+
+// DeleteRepositoryMetadata deletes the backend metadata and LFS objects of a
+// repository whose directory no longer exists.
+func (d *Backend) DeleteRepositoryMetadata(ctx context.Context, name string) error {
+	name = utils.SanitizeRepo(name)
+	rp := filepath.Join(d.reposPath(), name+".git")
+	if _, err := os.Stat(rp); err == nil {
+		return errors.New("repository directory exists")
+	}
+
+	defer d.cache.Delete(name)
+
+	return db.WrapError(d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		m, err := d.store.GetRepoByName(ctx, tx, name)
+		if err != nil {
+			return err
+		}
+
+		if err := d.store.DeleteRepoByName(ctx, tx, name); err != nil {
+			return err
+		}
+
+		lfsPath := filepath.Join(d.cfg.DataPath, "lfs", strconv.FormatInt(m.ID, 10))
+		return os.RemoveAll(lfsPath)
+	}))
+}
+
+// firstAdminID returns the ID of the oldest admin user. It's used as the owner
+// of repositories that aren't created by a user.
+func (d *Backend) firstAdminID(ctx context.Context, h db.Handler) (int64, error) {
+	users, err := d.store.GetAllUsers(ctx, h)
+	if err != nil {
+		return 0, err
+	}
+
+	var userID int64
+	for _, u := range users {
+		if u.Admin && (userID == 0 || u.ID < userID) {
+			userID = u.ID
+		}
+	}
+
+	return userID, nil
+}
@@ -0,0 +1,124 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/charmbracelet/soft-serve/git"
+	"github.com/charmbracelet/soft-serve/pkg/config"
+)
+
+// PackSettings are the settings of the packs served to clients cloning or
+// fetching a repository.
+type PackSettings struct {
+	config.PackConfig
+	// Custom is whether the settings are set in the repository git config
+	// rather than inherited from the server.
+	Custom bool
+}
+
+// PackSettings returns the pack settings of a repository.
+func (d *Backend) PackSettings(ctx context.Context, repo string) (PackSettings, error) {
+	s := PackSettings{PackConfig: d.cfg.Pack}
+	rr, err := d.openRepo(ctx, repo)
+	if err != nil {
+		return s, err
+	}
+
+	rcfg, err := rr.Config()
+	if err != nil {
+		return s, err
+	}
+
+	if !rcfg.HasSection("pack") {
+		return s, nil
+	}
+
+	sec := rcfg.Section("pack")
+	for key, v := range map[string]*int{
+		"compression": &s.Compression,
+		"window":      &s.Window,
+	} {
+		if !sec.HasOption(key) {
+			continue
+		}
+
+		n, err := strconv.Atoi(sec.Option(key))
+		if err != nil {
+			return s, fmt.Errorf("invalid pack.%s: %w", key, err)
+		}
+
+		*v = n
+		s.Custom = true
+	}
+
+	return s, nil
+}
+
+// SetPackSettings sets the pack settings of a repository in its git config.
+// They also apply to repacks on the server.
+func (d *Backend) SetPackSettings(ctx context.Context, repo string, p config.PackConfig) error {
+	if err := d.checkWritable(); err != nil {
+		return err
+	}
+
+	if err := p.Validate(); err != nil {
+		return err
+	}
+
+	rr, err := d.openRepo(ctx, repo)
+	if err != nil {
+		return err
+	}
+
+	return setPackConfig(rr, p)
+}
+
+// ResetPackSettings removes the pack settings from the git config of a
+// repository, so it inherits the server settings.
+func (d *Backend) ResetPackSettings(ctx context.Context, repo string) error {
+	if err := d.checkWritable(); err != nil {
+		return err
+	}
+
+	rr, err := d.openRepo(ctx, repo)
+	if err != nil {
+		return err
+	}
+
+	rcfg, err := rr.Config()
+	if err != nil {
+		return err
+	}
+
+	if !rcfg.HasSection("pack") {
+		return nil
+	}
+
+	rcfg.Section("pack").RemoveOption("compression").RemoveOption("window")
+	return rr.SetConfig(rcfg)
+}
+
+// setPackConfig writes the pack settings to the git config of a repository.
+func setPackConfig(rr *git.Repository, p config.PackConfig) error {
+	rcfg, err := rr.Config()
+	if err != nil {
+		return err
+	}
+
+	rcfg.Section("pack").
+		SetOption("compression", strconv.Itoa(p.Compression)).
+		SetOption("window", strconv.Itoa(p.Window))
+	return rr.SetConfig(rcfg)
+}
+
+// openRepo opens the git repository of a repository.
+func (d *Backend) openRepo(ctx context.Context, repo string) (*git.Repository, error) {
+	r, err := d.Repository(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.Open()
+}
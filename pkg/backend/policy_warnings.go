@@ -0,0 +1,105 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Policies that can be violated in warn-only mode.
+const (
+	policyTagProtection = "tag protection"
+	policyLinearHistory = "linear history"
+	policyFileModes     = "file modes"
+	policyPushLimits    = "push limits"
+	policyBinaryLimit   = "binary limit"
+	policyFreezeWindows = "freeze windows"
+)
+
+// policyCommands are the repo commands showing the settings of each policy.
+var policyCommands = map[string]string{
+	policyTagProtection: "tag-protection",
+	policyLinearHistory: "linear-history",
+	policyFileModes:     "file-modes",
+	policyPushLimits:    "push-limits",
+	policyBinaryLimit:   "binary-limit",
+	policyFreezeWindows: "freeze-window",
+}
+
+// policyWarningsKey is the context key of the policies a push violated in
+// warn-only mode.
+type policyWarningsKey struct{}
+
+// policyWarnings are the policies a push violated without being rejected, in
+// the order they were checked.
+type policyWarnings struct {
+	policies []string
+}
+
+// withPolicyWarnings returns a context recording the policies violated in
+// warn-only mode.
+func withPolicyWarnings(ctx context.Context) (context.Context, *policyWarnings) {
+	w := &policyWarnings{}
+	return context.WithValue(ctx, policyWarningsKey{}, w), w
+}
+
+// notePolicyWarning records that the push violated a policy in warn-only
+// mode. It does nothing if the context doesn't record warnings.
+func notePolicyWarning(ctx context.Context, policy string) {
+	w, ok := ctx.Value(policyWarningsKey{}).(*policyWarnings)
+	if !ok {
+		return
+	}
+
+	for _, p := range w.policies {
+		if p == policy {
+			return
+		}
+	}
+	w.policies = append(w.policies, policy)
+}
+
+// warnPolicy writes a warning about a violation of a policy in warn-only
+// mode and records it for the banner.
+func warnPolicy(ctx context.Context, stderr io.Writer, policy string, msg string) {
+	fmt.Fprintf(stderr, "warning: %s\n", msg) // nolint: errcheck
+	notePolicyWarning(ctx, policy)
+}
+
+// writePolicyBanner writes a banner listing the policies the push violated
+// in warn-only mode, if there are any and the banner is enabled.
+func (d *Backend) writePolicyBanner(stderr io.Writer, repo string, w *policyWarnings) {
+	if len(w.policies) == 0 || !d.cfg.PolicyWarnings.Banner {
+		return
+	}
+
+	policies := "policy"
+	if len(w.policies) > 1 {
+		policies = "policies"
+	}
+
+	rule := strings.Repeat("*", 72)
+	lines := []string{
+		rule,
+		fmt.Sprintf("* WARNING: this push violates %d repository %s:", len(w.policies), policies),
+		"*",
+	}
+	for _, p := range w.policies {
+		lines = append(lines, fmt.Sprintf("*   - %s (see `repo %s %s`)", p, policyCommands[p], repo))
+	}
+	lines = append(lines,
+		"*",
+		"* The push was accepted because the policies only warn for now. Once",
+		"* they are enforced, pushes like this one will be rejected. Fix the",
+		"* issues reported above to keep your pushes working.",
+	)
+	if g := d.cfg.PolicyWarnings.Guidance; g != "" {
+		lines = append(lines, "*", "* "+g)
+	}
+	lines = append(lines, rule)
+
+	for _, l := range lines {
+		fmt.Fprintln(stderr, l) // nolint: errcheck
+	}
+}
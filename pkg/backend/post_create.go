@@ -0,0 +1,100 @@
+package backend
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/soft-serve/pkg/hooks"
+	"github.com/charmbracelet/soft-serve/pkg/proto"
+)
+
+// PostCreateHook is the name of the hook run after a repository is created.
+const PostCreateHook = "post-create"
+
+// postCreate runs the post-create hook of a new repository. The hook failing
+// is logged and reported like failing git hooks, and with rollback, the error
+// is returned for the caller to delete the repository.
+func (d *Backend) postCreate(ctx context.Context, r proto.Repository, user proto.User) error {
+	path := d.cfg.PostCreate.Hook
+	if stat, err := os.Stat(path); path == "" || err != nil || stat.IsDir() || stat.Mode()&0o111 == 0 {
+		return nil
+	}
+
+	start := time.Now()
+	out, err := d.runPostCreate(ctx, path, r, user)
+	e := hooks.Execution{
+		Hook:     PostCreateHook,
+		Repo:     r.Name(),
+		Outcome:  hooks.OutcomeAccepted,
+		Start:    start,
+		Duration: time.Since(start),
+	}
+	if err != nil {
+		e.Outcome = hooks.OutcomeErrored
+		e.TimedOut = errors.Is(err, context.DeadlineExceeded)
+		e.Error = err.Error()
+	}
+	d.RecordHookExecutions(ctx, []hooks.Execution{e})
+
+	if err == nil {
+		d.logger.Debug("post-create hook succeeded", "repo", r.Name(), "output", out)
+		return nil
+	}
+
+	if !d.cfg.PostCreate.Rollback {
+		return nil
+	}
+
+	return fmt.Errorf("post-create hook: %w", err)
+}
+
+// runPostCreate runs the post-create hook in the repository directory with
+// the repository and its creator in the environment, and returns its output.
+func (d *Backend) runPostCreate(ctx context.Context, path string, r proto.Repository, user proto.User) (string, error) {
+	// The hook outlives the request that created the repository, like an
+	// SSH session closing right after the creation.
+	ctx = context.WithoutCancel(ctx)
+	if timeout := time.Duration(d.cfg.Timeouts.Hook) * time.Second; timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	rr, err := r.Open()
+	if err != nil {
+		return "", err
+	}
+
+	level := d.AccessLevelForUser(ctx, r.Name(), user)
+	var out bytes.Buffer
+	cmd := exec.CommandContext(ctx, path)
+	cmd.Dir = rr.Path
+	cmd.Env = append(os.Environ(), d.cfg.Environ()...)
+	cmd.Env = append(cmd.Env, d.HookEnv(ctx, r.Name(), user, level)...)
+	cmd.Env = append(cmd.Env,
+		"GIT_DIR="+rr.Path,
+		"SOFT_SERVE_REPO_NAME="+r.Name(),
+		"SOFT_SERVE_REPO_PATH="+rr.Path,
+	)
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	// Don't wait forever on children of the hook holding its output open.
+	cmd.WaitDelay = time.Second
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return out.String(), fmt.Errorf("timed out after %s: %w", time.Duration(d.cfg.Timeouts.Hook)*time.Second, ctx.Err())
+		}
+		if msg := strings.TrimSpace(out.String()); msg != "" {
+			return out.String(), fmt.Errorf("%w: %s", err, msg)
+		}
+		return out.String(), err
+	}
+
+	return out.String(), nil
+}
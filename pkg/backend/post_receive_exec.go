@@ -0,0 +1,143 @@
+package backend
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/soft-serve/pkg/hooks"
+	"github.com/charmbracelet/soft-serve/pkg/proto"
+	"github.com/charmbracelet/soft-serve/pkg/utils"
+)
+
+// PostReceiveExecHook is the name of the command run in the background after
+// a push.
+const PostReceiveExecHook = "post-receive-exec"
+
+// maxPostReceiveExecOutput is the number of bytes of the command output
+// logged.
+const maxPostReceiveExecOutput = 64 << 10
+
+// PostReceiveExec starts the post-receive exec command of the repositories
+// pushed to in the hook executions, in the background. The command failing
+// is logged and reported like failing git hooks, it never delays the push.
+func (d *Backend) PostReceiveExec(ctx context.Context, execs []hooks.Execution) {
+	for _, e := range execs {
+		if e.Hook != hooks.PostReceiveHook || len(e.Updates) == 0 {
+			continue
+		}
+
+		path := d.postReceiveExecCommand(e.Repo)
+		if stat, err := os.Stat(path); path == "" || err != nil || stat.IsDir() || stat.Mode()&0o111 == 0 {
+			continue
+		}
+
+		// The command outlives the push.
+		ctx := context.WithoutCancel(ctx)
+		go d.postReceiveExec(ctx, path, e.Repo, e.Updates)
+	}
+}
+
+// postReceiveExecCommand returns the post-receive exec command of a
+// repository.
+func (d *Backend) postReceiveExecCommand(repo string) string {
+	repo = utils.SanitizeRepo(repo)
+	for name, command := range d.cfg.PostReceiveExec.Repos {
+		if utils.SanitizeRepo(name) == repo {
+			return command
+		}
+	}
+
+	return d.cfg.PostReceiveExec.Command
+}
+
+// postReceiveExec runs the post-receive exec command of a repository and
+// logs its output.
+func (d *Backend) postReceiveExec(ctx context.Context, path string, repo string, updates []hooks.HookArg) {
+	start := time.Now()
+	out, err := d.runPostReceiveExec(ctx, path, repo, updates)
+	e := hooks.Execution{
+		Hook:     PostReceiveExecHook,
+		Repo:     repo,
+		Outcome:  hooks.OutcomeAccepted,
+		Start:    start,
+		Duration: time.Since(start),
+	}
+	if err != nil {
+		e.Outcome = hooks.OutcomeErrored
+		e.TimedOut = errors.Is(err, context.DeadlineExceeded)
+		e.Error = err.Error()
+	}
+	d.RecordHookExecutions(ctx, []hooks.Execution{e})
+
+	if len(out) > maxPostReceiveExecOutput {
+		out = out[len(out)-maxPostReceiveExecOutput:]
+	}
+	d.logger.Info("post-receive exec finished", "repo", repo, "command", path, "duration", e.Duration, "ok", err == nil, "output", strings.TrimSpace(out))
+}
+
+// runPostReceiveExec runs the post-receive exec command in the repository
+// directory with the ref updates on its standard input and in the
+// environment, and returns its output.
+func (d *Backend) runPostReceiveExec(ctx context.Context, path string, repo string, updates []hooks.HookArg) (string, error) {
+	timeout := time.Duration(d.cfg.PostReceiveExec.Timeout) * time.Second
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	r, err := d.Repository(ctx, repo)
+	if err != nil {
+		return "", err
+	}
+
+	rr, err := r.Open()
+	if err != nil {
+		return "", err
+	}
+
+	var input strings.Builder
+	refs := make([]string, 0, len(updates))
+	for _, u := range updates {
+		fmt.Fprintf(&input, "%s %s %s\n", u.OldSha, u.NewSha, u.RefName)
+		refs = append(refs, u.RefName)
+	}
+
+	user := proto.UserFromContext(ctx)
+	level := d.AccessLevelForUser(ctx, r.Name(), user)
+	var out bytes.Buffer
+	cmd := exec.CommandContext(ctx, path)
+	cmd.Dir = rr.Path
+	cmd.Env = append(os.Environ(), d.cfg.Environ()...)
+	cmd.Env = append(cmd.Env, d.HookEnv(ctx, r.Name(), user, level)...)
+	cmd.Env = append(cmd.Env,
+		"GIT_DIR="+rr.Path,
+		"SOFT_SERVE_REPO_NAME="+r.Name(),
+		"SOFT_SERVE_REPO_PATH="+rr.Path,
+		"SOFT_SERVE_REFS="+strings.Join(refs, " "),
+		"SOFT_SERVE_REF_UPDATES="+input.String(),
+	)
+	cmd.Stdin = strings.NewReader(input.String())
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	// Don't wait forever on children of the command holding its output
+	// open.
+	cmd.WaitDelay = time.Second
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return out.String(), fmt.Errorf("timed out after %s: %w", timeout, ctx.Err())
+		}
+		if msg := strings.TrimSpace(out.String()); msg != "" {
+			return out.String(), fmt.Errorf("%w: %s", err, msg)
+		}
+		return out.String(), err
+	}
+
+	return out.String(), nil
+}
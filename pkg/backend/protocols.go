@@ -0,0 +1,98 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/soft-serve/pkg/proto"
+)
+
+// Repository setting keys for the allowed protocols.
+const (
+	settingPushProtocols = "push_protocols"
+	settingReadProtocols = "read_protocols"
+)
+
+// Protocols git clients use to access repositories.
+const (
+	ProtocolSSH  = "ssh"
+	ProtocolHTTP = "http"
+	// ProtocolGit is the Git daemon protocol. It's read-only.
+	ProtocolGit = "git"
+)
+
+// Protocols are the protocols a repository can be pushed to and read over.
+// No protocols means all of them.
+type Protocols struct {
+	Push []string
+	Read []string
+}
+
+// Protocols returns the protocols allowed to access a repository.
+func (d *Backend) Protocols(ctx context.Context, repo string) (Protocols, error) {
+	var p Protocols
+	settings, err := d.RepoSettings(ctx, repo)
+	if err != nil {
+		return p, err
+	}
+
+	if v := settings[settingPushProtocols]; v != "" {
+		p.Push = strings.Split(v, ",")
+	}
+	if v := settings[settingReadProtocols]; v != "" {
+		p.Read = strings.Split(v, ",")
+	}
+
+	return p, nil
+}
+
+// SetProtocols sets the protocols allowed to access a repository. The Git
+// daemon protocol can't be allowed to push.
+func (d *Backend) SetProtocols(ctx context.Context, repo string, p Protocols) error {
+	for _, name := range p.Push {
+		switch name {
+		case ProtocolSSH, ProtocolHTTP:
+		default:
+			return fmt.Errorf("invalid push protocol %q, expected %s or %s", name, ProtocolSSH, ProtocolHTTP)
+		}
+	}
+	for _, name := range p.Read {
+		switch name {
+		case ProtocolSSH, ProtocolHTTP, ProtocolGit:
+		default:
+			return fmt.Errorf("invalid read protocol %q, expected %s, %s, or %s", name, ProtocolSSH, ProtocolHTTP, ProtocolGit)
+		}
+	}
+
+	return d.SetRepoSettings(ctx, repo, map[string]string{
+		settingPushProtocols: strings.Join(p.Push, ","),
+		settingReadProtocols: strings.Join(p.Read, ","),
+	})
+}
+
+// CheckProtocol returns proto.ErrProtocolNotAllowed if the repository can't
+// be pushed to, or read when push is false, over the protocol. The error
+// tells the allowed protocols. Repositories that don't exist yet allow all
+// protocols.
+func (d *Backend) CheckProtocol(ctx context.Context, repo string, protocol string, push bool) error {
+	p, err := d.Protocols(ctx, repo)
+	if errors.Is(err, proto.ErrRepoNotFound) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	allowed, op := p.Read, "read"
+	if push {
+		allowed, op = p.Push, "pushed to"
+	}
+
+	if len(allowed) == 0 || slices.Contains(allowed, protocol) {
+		return nil
+	}
+
+	return fmt.Errorf("%w: it can only be %s over %s", proto.ErrProtocolNotAllowed, op, strings.Join(allowed, ", "))
+}
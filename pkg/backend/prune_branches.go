@@ -0,0 +1,131 @@
+package backend
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/soft-serve/git"
+	"github.com/charmbracelet/soft-serve/pkg/proto"
+	"github.com/charmbracelet/soft-serve/pkg/webhook"
+)
+
+// MergedBranch is a branch fully merged into a base branch.
+type MergedBranch struct {
+	// Name is the branch name.
+	Name string
+	// Commit is the commit the branch points to.
+	Commit string
+	// Protected is whether the branch has protection rules. Protected
+	// branches are never pruned.
+	Protected bool
+}
+
+// MergedBranches returns the branches of a repository fully merged into the
+// base branch, sorted by name. An empty base is the default branch. The base
+// and the default branch are never listed.
+func (d *Backend) MergedBranches(ctx context.Context, repo string, base string) ([]MergedBranch, error) {
+	rr, err := d.openRepo(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	var def string
+	if head, err := rr.HEAD(); err == nil {
+		def = head.Name().Short()
+	}
+
+	if base == "" {
+		if def == "" {
+			return nil, fmt.Errorf("%w: repository has no default branch", git.ErrReferenceNotExist)
+		}
+		base = def
+	}
+
+	if _, err := git.NewCommand("rev-parse", "--verify", "--quiet", git.RefsHeads+base).WithContext(ctx).RunInDir(rr.Path); err != nil {
+		return nil, fmt.Errorf("%w: %q", git.ErrReferenceNotExist, base)
+	}
+
+	protected, err := d.protectedBranchPatterns(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := git.NewCommand("for-each-ref", "--merged="+git.RefsHeads+base, "--format=%(refname) %(objectname)", git.RefsHeads).
+		WithContext(ctx).RunInDir(rr.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	branches := make([]MergedBranch, 0)
+	s := bufio.NewScanner(bytes.NewReader(out))
+	for s.Scan() {
+		ref, commit, ok := strings.Cut(s.Text(), " ")
+		if !ok {
+			continue
+		}
+
+		name, isProtected := matchBranch(protected, ref)
+		if name == base || name == def {
+			continue
+		}
+
+		branches = append(branches, MergedBranch{Name: name, Commit: commit, Protected: isProtected})
+	}
+
+	sort.Slice(branches, func(i, j int) bool {
+		return branches[i].Name < branches[j].Name
+	})
+
+	return branches, nil
+}
+
+// PruneBranch deletes a branch merged into the base branch, records it in
+// the audit log, and sends the branch delete webhook. The branch is only
+// deleted if it still points to the given commit. Protected branches are
+// never deleted.
+func (d *Backend) PruneBranch(ctx context.Context, repo string, user proto.User, base string, b MergedBranch) error {
+	if err := d.checkWritable(); err != nil {
+		return err
+	}
+
+	if b.Protected {
+		return fmt.Errorf("branch %q is protected", b.Name)
+	}
+
+	unlock, err := d.RLockRepository(ctx, repo, "prune-branches")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	r, err := d.Repository(ctx, repo)
+	if err != nil {
+		return err
+	}
+
+	rr, err := r.Open()
+	if err != nil {
+		return err
+	}
+
+	ref := git.RefsHeads + b.Name
+	if _, err := git.NewCommand("update-ref", "-d", ref, b.Commit).WithContext(ctx).RunInDir(rr.Path); err != nil {
+		return fmt.Errorf("delete branch %q: %w", b.Name, err)
+	}
+
+	d.logger.Info("pruned merged branch", "repo", repo, "branch", b.Name, "commit", b.Commit, "base", base)
+	if err := d.recordAuditEvent(ctx, AuditActionBranchPruned, r, user, fmt.Sprintf("%s %s %s", b.Name, b.Commit, base)); err != nil {
+		d.logger.Error("error recording pruned branch", "repo", repo, "branch", b.Name, "err", err)
+	}
+
+	wh, err := webhook.NewBranchTagEvent(ctx, user, r, ref, b.Commit, git.ZeroIDOf(b.Commit))
+	if err != nil {
+		return err
+	}
+
+	return webhook.SendEvent(ctx, wh)
+}
@@ -0,0 +1,156 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/soft-serve/git"
+	"github.com/charmbracelet/soft-serve/pkg/access"
+	"github.com/charmbracelet/soft-serve/pkg/hooks"
+)
+
+// Repository setting keys for push limits.
+const (
+	settingMaxPushCommits = "max_push_commits"
+	settingMaxPushRefs    = "max_push_refs"
+	settingPushLimitsWarn = "push_limits_warn_only"
+)
+
+// SkipPushLimitsOption is the push option admins can use to bypass push
+// limits, e.g. `git push -o skip-push-limits`.
+const SkipPushLimitsOption = "skip-push-limits"
+
+// PushLimits are the limits applied to a single push to a repository.
+type PushLimits struct {
+	// MaxCommits is the maximum number of new commits in a push.
+	// Zero means no limit.
+	MaxCommits int
+	// MaxRefs is the maximum number of refs updated in a push.
+	// Zero means no limit.
+	MaxRefs int
+	// WarnOnly reports pushes exceeding the limits without rejecting them.
+	WarnOnly bool
+}
+
+// PushLimits returns the push limits of a repository.
+func (d *Backend) PushLimits(ctx context.Context, repo string) (PushLimits, error) {
+	settings, err := d.RepoSettings(ctx, repo)
+	if err != nil {
+		return PushLimits{}, err
+	}
+
+	var l PushLimits
+	l.MaxCommits, _ = strconv.Atoi(settings[settingMaxPushCommits])
+	l.MaxRefs, _ = strconv.Atoi(settings[settingMaxPushRefs])
+	l.WarnOnly, _ = strconv.ParseBool(settings[settingPushLimitsWarn])
+
+	return l, nil
+}
+
+// SetPushLimits sets the push limits of a repository.
+func (d *Backend) SetPushLimits(ctx context.Context, repo string, l PushLimits) error {
+	if l.MaxCommits < 0 || l.MaxRefs < 0 {
+		return fmt.Errorf("push limits cannot be negative")
+	}
+
+	settings := map[string]string{
+		settingMaxPushCommits: "",
+		settingMaxPushRefs:    "",
+		settingPushLimitsWarn: "",
+	}
+	if l.MaxCommits > 0 {
+		settings[settingMaxPushCommits] = strconv.Itoa(l.MaxCommits)
+	}
+	if l.MaxRefs > 0 {
+		settings[settingMaxPushRefs] = strconv.Itoa(l.MaxRefs)
+	}
+	if l.WarnOnly {
+		settings[settingPushLimitsWarn] = "true"
+	}
+
+	return d.SetRepoSettings(ctx, repo, settings)
+}
+
+// checkPushLimits returns an error if the pushed refs exceed the repository
+// push limits. It's meant to be called from the pre-receive hook.
+func (d *Backend) checkPushLimits(ctx context.Context, stderr io.Writer, repo string, args []hooks.HookArg) error {
+	l, err := d.PushLimits(ctx, repo)
+	if err != nil {
+		return err
+	}
+
+	if l.MaxCommits == 0 && l.MaxRefs == 0 {
+		return nil
+	}
+
+	var problems []string
+	if l.MaxRefs > 0 && len(args) > l.MaxRefs {
+		problems = append(problems, fmt.Sprintf("%d refs updated, the limit is %d", len(args), l.MaxRefs))
+	}
+
+	if l.MaxCommits > 0 {
+		n, err := d.countNewCommits(ctx, repo, args)
+		if err != nil {
+			return err
+		}
+
+		if n > l.MaxCommits {
+			problems = append(problems, fmt.Sprintf("%d new commits, the limit is %d", n, l.MaxCommits))
+		}
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+
+	msg := "push exceeds repository limits: " + strings.Join(problems, ", ")
+	switch {
+	case l.WarnOnly:
+		warnPolicy(ctx, stderr, policyPushLimits, msg)
+		return nil
+	case hooks.HasPushOption(SkipPushLimitsOption) && d.hookAccessLevel(ctx, repo) >= access.AdminAccess:
+		fmt.Fprintf(stderr, "warning: %s (skipped by admin)\n", msg) // nolint: errcheck
+		d.logger.Info("push limits skipped by admin", "repo", repo, "problems", problems)
+		return nil
+	}
+
+	return fmt.Errorf("%s; admins can bypass this with `git push -o %s`", msg, SkipPushLimitsOption)
+}
+
+// countNewCommits returns the number of commits reachable from the pushed refs
+// that aren't reachable from any existing ref.
+func (d *Backend) countNewCommits(ctx context.Context, repo string, args []hooks.HookArg) (int, error) {
+	r, err := d.Repository(ctx, repo)
+	if err != nil {
+		return 0, err
+	}
+
+	rr, err := r.Open()
+	if err != nil {
+		return 0, err
+	}
+
+	revs := []string{"rev-list", "--count"}
+	for _, arg := range args {
+		if !git.IsZeroHash(arg.NewSha) {
+			revs = append(revs, arg.NewSha)
+		}
+	}
+
+	if len(revs) == 2 {
+		return 0, nil
+	}
+
+	// The hook runs with the quarantine object directory in the environment so
+	// the new objects are visible to git.
+	revs = append(revs, "--not", "--all")
+	out, err := git.NewCommand(revs...).WithContext(ctx).RunInDir(rr.Path)
+	if err != nil {
+		return 0, err
+	}
+
+	return strconv.Atoi(strings.TrimSpace(string(out)))
+}
@@ -0,0 +1,149 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/hooks"
+	"github.com/charmbracelet/soft-serve/pkg/proto"
+	"github.com/dustin/go-humanize"
+)
+
+// DiskUsage is the disk space used by the repositories a user owns, and
+// their disk quota.
+type DiskUsage struct {
+	// Used is the total cached size of the repositories in bytes.
+	Used int64
+	// Quota is the quota of the user in bytes. Zero means no quota.
+	Quota int64
+}
+
+// Percent returns the share of the quota in use, in percent. It's zero when
+// there's no quota.
+func (u DiskUsage) Percent() int64 {
+	if u.Quota <= 0 {
+		return 0
+	}
+
+	return u.Used * 100 / u.Quota
+}
+
+// String returns the usage in a human readable form.
+func (u DiskUsage) String() string {
+	if u.Quota <= 0 {
+		return humanize.IBytes(uint64(u.Used))
+	}
+
+	return fmt.Sprintf("%s of %s (%d%%)", humanize.IBytes(uint64(u.Used)), humanize.IBytes(uint64(u.Quota)), u.Percent())
+}
+
+// DiskUsage returns the disk usage of a user. The usage is the sum of the
+// cached sizes of the repositories they own, so it can lag behind changes
+// made outside of pushes. Admins have no quota.
+func (d *Backend) DiskUsage(ctx context.Context, user proto.User) (DiskUsage, error) {
+	var u DiskUsage
+	if user == nil {
+		return u, nil
+	}
+
+	used, err := d.store.GetUserRepoSizeTotal(ctx, d.db, user.ID())
+	if err != nil {
+		return u, db.WrapError(err)
+	}
+
+	u.Used = used
+	if !user.IsAdmin() {
+		u.Quota = d.cfg.Quota.UserQuota(user.Username())
+	}
+
+	return u, nil
+}
+
+// QuotaWarning returns a warning for a user whose usage is getting close to
+// their quota, or an empty string. It's shown from quota.warn_percent.
+func (d *Backend) QuotaWarning(ctx context.Context, user proto.User) string {
+	warn := int64(d.cfg.Quota.WarnPercent)
+	if warn <= 0 {
+		return ""
+	}
+
+	u, err := d.DiskUsage(ctx, user)
+	if err != nil {
+		d.logger.Error("error computing disk usage", "err", err)
+		return ""
+	}
+
+	if u.Quota <= 0 || u.Percent() < warn {
+		return ""
+	}
+
+	return fmt.Sprintf("your repositories use %s of your disk quota", u)
+}
+
+// checkCreateQuota returns proto.ErrQuotaExceeded if the owner of a new
+// repository has used up their quota.
+func (d *Backend) checkCreateQuota(ctx context.Context, owner proto.User) error {
+	u, err := d.DiskUsage(ctx, owner)
+	if err != nil || u.Quota <= 0 {
+		return err
+	}
+
+	if u.Used >= u.Quota {
+		return fmt.Errorf("%w: your repositories use %s", proto.ErrQuotaExceeded, u)
+	}
+
+	return nil
+}
+
+// checkQuota returns an error if a push would make the repositories of the
+// owner of the repository exceed their quota. The pushed objects are counted
+// from the quarantine directory git receives them in. It's meant to be called
+// from the pre-receive hook.
+func (d *Backend) checkQuota(ctx context.Context, stderr io.Writer, repo string, args []hooks.HookArg) error {
+	if len(args) == 0 {
+		return nil
+	}
+
+	r, err := d.Repository(ctx, repo)
+	if err != nil {
+		return err
+	}
+
+	// Repositories without an owner don't count against any quota.
+	if r.UserID() <= 0 {
+		return nil
+	}
+
+	owner, err := d.UserByID(ctx, r.UserID())
+	if err != nil {
+		return err
+	}
+
+	u, err := d.DiskUsage(ctx, owner)
+	if err != nil || u.Quota <= 0 {
+		return err
+	}
+
+	var incoming int64
+	if dir := os.Getenv("GIT_QUARANTINE_PATH"); dir != "" {
+		incoming, err = dirSize(dir)
+		if err != nil {
+			return err
+		}
+	}
+
+	after := DiskUsage{Used: u.Used + incoming, Quota: u.Quota}
+	if after.Used > after.Quota {
+		return fmt.Errorf("%w: this push would make the repositories of %s use %s",
+			proto.ErrQuotaExceeded, owner.Username(), after)
+	}
+
+	if warn := int64(d.cfg.Quota.WarnPercent); warn > 0 && after.Percent() >= warn {
+		fmt.Fprintf(stderr, "warning: the repositories of %s use %s of their disk quota\n", owner.Username(), after) // nolint: errcheck
+	}
+
+	return nil
+}
@@ -0,0 +1,24 @@
+package backend
+
+import "testing"
+
+func TestDiskUsage(t *testing.T) {
+	cases := []struct {
+		usage   DiskUsage
+		percent int64
+		str     string
+	}{
+		{DiskUsage{Used: 512}, 0, "512 B"},
+		{DiskUsage{Used: 1 << 20, Quota: 4 << 20}, 25, "1.0 MiB of 4.0 MiB (25%)"},
+		{DiskUsage{Used: 5 << 20, Quota: 4 << 20}, 125, "5.0 MiB of 4.0 MiB (125%)"},
+	}
+
+	for _, c := range cases {
+		if got := c.usage.Percent(); got != c.percent {
+			t.Errorf("%+v.Percent() = %d, want %d", c.usage, got, c.percent)
+		}
+		if got := c.usage.String(); got != c.str {
+			t.Errorf("%+v.String() = %q, want %q", c.usage, got, c.str)
+		}
+	}
+}
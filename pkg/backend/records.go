@@ -0,0 +1,68 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+)
+
+// PrunedRecords is the number of activity records deleted by PruneRecords.
+type PrunedRecords struct {
+	// AuditEvents is the number of audit events rotated out of the database.
+	AuditEvents int64
+	CloneEvents int64
+	RepoRenames int64
+	MirrorSyncs int64
+}
+
+// PruneRecords rotates the audit log, see RotateAuditLog, and deletes the
+// clone events that are older than their configured retention, the pull
+// mirror syncs older than theirs, and the repository renames past their grace
+// period.
+// Records with no retention are kept forever.
+func (d *Backend) PruneRecords(ctx context.Context) (PrunedRecords, error) {
+	var p PrunedRecords
+	r := d.cfg.DB.Retention
+	now := time.Now()
+	rot, err := d.RotateAuditLog(ctx)
+	if err != nil {
+		return PrunedRecords{}, fmt.Errorf("rotate audit log: %w", err)
+	}
+
+	p.AuditEvents = rot.Events
+	if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		var err error
+		if r.CloneEvents > 0 {
+			p.CloneEvents, err = d.store.DeleteCloneEventsBefore(ctx, tx, now.AddDate(0, 0, -r.CloneEvents))
+			if err != nil {
+				return err
+			}
+		}
+
+		if r.MirrorSyncs > 0 {
+			p.MirrorSyncs, err = d.store.DeleteMirrorSyncsBefore(ctx, tx, now.AddDate(0, 0, -r.MirrorSyncs))
+			if err != nil {
+				return err
+			}
+		}
+
+		if period := d.renameGracePeriod(); period > 0 {
+			p.RepoRenames, err = d.store.DeleteRepoRenamesBefore(ctx, tx, now.Add(-period))
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}); err != nil {
+		return PrunedRecords{}, db.WrapError(err)
+	}
+
+	if p.AuditEvents > 0 || p.CloneEvents > 0 || p.MirrorSyncs > 0 || p.RepoRenames > 0 {
+		d.logger.Info("pruned activity records", "audit_events", p.AuditEvents, "clone_events", p.CloneEvents, "mirror_syncs", p.MirrorSyncs, "repo_renames", p.RepoRenames)
+	}
+
+	return p, nil
+}
@@ -0,0 +1,119 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/charmbracelet/soft-serve/git"
+	"github.com/charmbracelet/soft-serve/pkg/hooks"
+)
+
+// Repository setting keys for ref name rules.
+const (
+	settingMaxRefNameLength = "max_ref_name_length"
+	settingRefNamePattern   = "ref_name_pattern"
+)
+
+// RefNameRules are the rules new ref names of a repository must follow, on
+// top of git's own ref name validation.
+type RefNameRules struct {
+	// MaxLength is the maximum length of a full ref name, like
+	// refs/heads/main, in bytes. Zero means no limit.
+	MaxLength int
+	// Pattern is a regular expression full ref names must match entirely.
+	// Empty means any name.
+	Pattern string
+}
+
+// RefNameRules returns the ref name rules of a repository.
+func (d *Backend) RefNameRules(ctx context.Context, repo string) (RefNameRules, error) {
+	settings, err := d.RepoSettings(ctx, repo)
+	if err != nil {
+		return RefNameRules{}, err
+	}
+
+	var r RefNameRules
+	r.MaxLength, _ = strconv.Atoi(settings[settingMaxRefNameLength])
+	r.Pattern = settings[settingRefNamePattern]
+
+	return r, nil
+}
+
+// SetRefNameRules sets the ref name rules of a repository.
+func (d *Backend) SetRefNameRules(ctx context.Context, repo string, r RefNameRules) error {
+	if r.MaxLength < 0 {
+		return fmt.Errorf("maximum ref name length cannot be negative")
+	}
+
+	if _, err := compileRefNamePattern(r.Pattern); err != nil {
+		return err
+	}
+
+	settings := map[string]string{
+		settingMaxRefNameLength: "",
+		settingRefNamePattern:   r.Pattern,
+	}
+	if r.MaxLength > 0 {
+		settings[settingMaxRefNameLength] = strconv.Itoa(r.MaxLength)
+	}
+
+	return d.SetRepoSettings(ctx, repo, settings)
+}
+
+// checkRefNames returns an error if a ref created by the push breaks the
+// repository ref name rules. Updates and deletions of existing refs are
+// allowed, so refs created before the rules can still be cleaned up. It's
+// meant to be called from the pre-receive hook.
+func (d *Backend) checkRefNames(ctx context.Context, repo string, args []hooks.HookArg) error {
+	r, err := d.RefNameRules(ctx, repo)
+	if err != nil || (r.MaxLength == 0 && r.Pattern == "") {
+		return err
+	}
+
+	for _, arg := range args {
+		if !git.IsZeroHash(arg.OldSha) || git.IsZeroHash(arg.NewSha) {
+			continue
+		}
+
+		if err := checkRefName(arg.RefName, r); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// checkRefName returns an error if the full ref name breaks the rules.
+func checkRefName(name string, r RefNameRules) error {
+	if r.MaxLength > 0 && len(name) > r.MaxLength {
+		return fmt.Errorf("ref %q is %d bytes long, the limit is %d", name, len(name), r.MaxLength)
+	}
+
+	re, err := compileRefNamePattern(r.Pattern)
+	if err != nil {
+		return err
+	}
+
+	if re != nil && !re.MatchString(name) {
+		return fmt.Errorf("ref %q doesn't match the allowed pattern %s", name, r.Pattern)
+	}
+
+	return nil
+}
+
+// compileRefNamePattern compiles a ref name pattern anchored to match entire
+// names. An empty pattern returns nil.
+func compileRefNamePattern(pattern string) (*regexp.Regexp, error) {
+	if pattern == "" {
+		return nil, nil
+	}
+
+	re, err := regexp.Compile(`^(?:` + pattern + `)$`)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ref name pattern: %w", err)
+	}
+
+	return re, nil
+}
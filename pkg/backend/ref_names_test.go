@@ -0,0 +1,53 @@
+package backend
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheckRefName(t *testing.T) {
+	cases := []struct {
+		name  string
+		ref   string
+		rules RefNameRules
+		ok    bool
+	}{
+		{"no rules", "refs/heads/" + strings.Repeat("a", 1000), RefNameRules{}, true},
+		{"at the limit", "refs/heads/" + strings.Repeat("a", 9), RefNameRules{MaxLength: 20}, true},
+		{"over the limit", "refs/heads/" + strings.Repeat("a", 10), RefNameRules{MaxLength: 20}, false},
+		{"limit counts bytes", "refs/heads/ééééé", RefNameRules{MaxLength: 20}, false},
+		{"matches pattern", "refs/heads/feature/login", RefNameRules{Pattern: `refs/heads/(main|feature/[a-z-]+)`}, true},
+		{"pattern is anchored", "refs/heads/feature/login-x", RefNameRules{Pattern: `refs/heads/feature/login`}, false},
+		{"pattern prefix only", "refs/heads/main2", RefNameRules{Pattern: `refs/heads/main|refs/tags/.+`}, false},
+		{"alternation", "refs/tags/v1", RefNameRules{Pattern: `refs/heads/main|refs/tags/.+`}, true},
+		{"unicode", "refs/heads/fix-ü", RefNameRules{Pattern: `refs/heads/[a-z0-9._/-]+`}, false},
+		{"emoji", "refs/heads/🚀", RefNameRules{Pattern: `refs/heads/[a-z0-9._/-]+`}, false},
+		{"spaces", "refs/heads/a b", RefNameRules{Pattern: `refs/heads/\S+`}, false},
+		{"both rules", "refs/heads/ok", RefNameRules{MaxLength: 13, Pattern: `refs/heads/[a-z]+`}, true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := checkRefName(c.ref, c.rules)
+			if c.ok && err != nil {
+				t.Fatalf("expected %q to be allowed, got %v", c.ref, err)
+			}
+			if !c.ok && err == nil {
+				t.Fatalf("expected %q to be rejected", c.ref)
+			}
+			if err != nil && !strings.Contains(err.Error(), c.ref) {
+				t.Fatalf("expected the error to name %q, got %v", c.ref, err)
+			}
+		})
+	}
+}
+
+func TestCompileRefNamePattern(t *testing.T) {
+	if re, err := compileRefNamePattern(""); err != nil || re != nil {
+		t.Fatalf("expected no pattern, got %v, %v", re, err)
+	}
+
+	if _, err := compileRefNamePattern("refs/heads/("); err == nil {
+		t.Fatal("expected an invalid pattern error")
+	}
+}
@@ -0,0 +1,406 @@
+package backend
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/db/models"
+	"github.com/charmbracelet/soft-serve/pkg/proto"
+	"github.com/charmbracelet/soft-serve/pkg/storage"
+	"github.com/charmbracelet/soft-serve/pkg/utils"
+)
+
+// releaseAssetNameMaxLen is the maximum length of a release asset name.
+const releaseAssetNameMaxLen = 255
+
+// ErrInvalidReleaseAsset is returned when a release asset name is invalid.
+var ErrInvalidReleaseAsset = errors.New("invalid release asset name")
+
+// Release is a release of a repository, attached to a tag, with its assets.
+type Release struct {
+	// Tag is the name of the tag of the release.
+	Tag string
+	// Title is the title of the release, the tag by default.
+	Title string
+	// Notes are the release notes.
+	Notes string
+	// Username is the user that created the release, empty if there's none.
+	Username string
+	// Assets are the files attached to the release, by name.
+	Assets []ReleaseAsset
+	// CreatedAt is when the release was created.
+	CreatedAt time.Time
+}
+
+// ReleaseAsset is a file attached to a release.
+type ReleaseAsset struct {
+	// Name is the file name of the asset, unique in its release.
+	Name string
+	// Size is the size of the asset in bytes.
+	Size int64
+	// SHA256 is the hex encoded SHA-256 digest of the asset.
+	SHA256 string
+	// UpdatedAt is when the asset was last uploaded.
+	UpdatedAt time.Time
+}
+
+// ValidateReleaseAssetName returns an error if a release asset name can't be
+// used as a file name. Names starting with a dot are reserved.
+func ValidateReleaseAssetName(name string) error {
+	if name == "" || utf8.RuneCountInString(name) > releaseAssetNameMaxLen ||
+		strings.HasPrefix(name, ".") || strings.ContainsAny(name, `/\`) ||
+		strings.IndexFunc(name, unicode.IsControl) >= 0 {
+		return fmt.Errorf("%w: %q", ErrInvalidReleaseAsset, name)
+	}
+
+	return nil
+}
+
+// releasesPath returns the directory of the release assets of a repository.
+// It's keyed by the repository ID so it survives renames.
+func (d *Backend) releasesPath(repoID int64) string {
+	return filepath.Join(d.cfg.DataPath, "releases", strconv.FormatInt(repoID, 10))
+}
+
+// releaseAssetPath returns the storage path of a release asset.
+func releaseAssetPath(releaseID int64, name string) string {
+	return path.Join(strconv.FormatInt(releaseID, 10), name)
+}
+
+// ReleaseAssetURL returns the HTTP API URL to download a release asset, or an
+// empty string if the server has no public HTTP URL.
+func (d *Backend) ReleaseAssetURL(repo string, tag string, name string) string {
+	if d.cfg.HTTP.PublicURL == "" {
+		return ""
+	}
+
+	return fmt.Sprintf("%s/api/repos/%s/releases/%s/assets/%s",
+		strings.TrimSuffix(d.cfg.HTTP.PublicURL, "/"), utils.SanitizeRepo(repo),
+		url.PathEscape(tag), url.PathEscape(name))
+}
+
+// CreateRelease creates a release of a repository for an existing tag. The
+// title defaults to the tag.
+func (d *Backend) CreateRelease(ctx context.Context, repo string, user proto.User, tag string, title string, notes string) (Release, error) {
+	if err := d.checkWritable(); err != nil {
+		return Release{}, err
+	}
+
+	r, err := d.Repository(ctx, repo)
+	if err != nil {
+		return Release{}, err
+	}
+
+	rr, err := r.Open()
+	if err != nil {
+		return Release{}, err
+	}
+
+	if tag == "" || !rr.HasTag(tag) {
+		return Release{}, fmt.Errorf("%w: %q", proto.ErrTagNotFound, tag)
+	}
+
+	title = strings.TrimSpace(title)
+	if title == "" {
+		title = tag
+	}
+
+	var userID int64
+	if user != nil {
+		userID = user.ID()
+	}
+
+	if err := db.WrapError(d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		return d.store.CreateRelease(ctx, tx, r.ID(), userID, tag, title, notes)
+	})); err != nil {
+		if errors.Is(err, db.ErrDuplicateKey) {
+			return Release{}, proto.ErrReleaseExist
+		}
+		return Release{}, err
+	}
+
+	if err := d.recordAuditEvent(ctx, AuditActionReleaseCreated, r, user, tag); err != nil {
+		return Release{}, err
+	}
+
+	return d.Release(ctx, r.Name(), tag)
+}
+
+// EditRelease sets the title and the notes of a release. An empty title
+// resets it to the tag.
+func (d *Backend) EditRelease(ctx context.Context, repo string, tag string, title string, notes string) error {
+	if err := d.checkWritable(); err != nil {
+		return err
+	}
+
+	_, m, err := d.release(ctx, repo, tag)
+	if err != nil {
+		return err
+	}
+
+	title = strings.TrimSpace(title)
+	if title == "" {
+		title = tag
+	}
+
+	return db.WrapError(d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		return d.store.UpdateRelease(ctx, tx, m.ID, title, notes)
+	}))
+}
+
+// DeleteRelease deletes a release and its assets. The tag is kept.
+func (d *Backend) DeleteRelease(ctx context.Context, repo string, tag string) error {
+	if err := d.checkWritable(); err != nil {
+		return err
+	}
+
+	r, m, err := d.release(ctx, repo, tag)
+	if err != nil {
+		return err
+	}
+
+	if err := db.WrapError(d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		return d.store.DeleteRelease(ctx, tx, m.ID)
+	})); err != nil {
+		return err
+	}
+
+	dir := filepath.Join(d.releasesPath(r.ID()), strconv.FormatInt(m.ID, 10))
+	if err := os.RemoveAll(dir); err != nil {
+		d.logger.Error("failed to delete release assets", "repo", r.Name(), "tag", tag, "err", err)
+	}
+
+	return d.recordAuditEvent(ctx, AuditActionReleaseDeleted, r, nil, tag)
+}
+
+// Release returns the release of a repository for a tag, with its assets.
+func (d *Backend) Release(ctx context.Context, repo string, tag string) (Release, error) {
+	_, m, err := d.release(ctx, repo, tag)
+	if err != nil {
+		return Release{}, err
+	}
+
+	return d.newRelease(ctx, m)
+}
+
+// Releases returns the releases of a repository, newest first, with their
+// assets.
+func (d *Backend) Releases(ctx context.Context, repo string) ([]Release, error) {
+	r, err := d.Repository(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	var ms []models.Release
+	if err := d.retryTx(ctx, "releases", func(tx *db.Tx) error {
+		var err error
+		ms, err = d.store.GetReleases(ctx, tx, r.ID())
+		return err
+	}); err != nil {
+		return nil, db.WrapError(err)
+	}
+
+	releases := make([]Release, 0, len(ms))
+	for _, m := range ms {
+		rel, err := d.newRelease(ctx, m)
+		if err != nil {
+			return nil, err
+		}
+		releases = append(releases, rel)
+	}
+
+	return releases, nil
+}
+
+// UploadReleaseAsset stores the content of an asset of a release, replacing
+// the asset with the same name if there's one.
+func (d *Backend) UploadReleaseAsset(ctx context.Context, repo string, tag string, name string, content io.Reader) (ReleaseAsset, error) {
+	if err := d.checkWritable(); err != nil {
+		return ReleaseAsset{}, err
+	}
+
+	if err := ValidateReleaseAssetName(name); err != nil {
+		return ReleaseAsset{}, err
+	}
+
+	r, m, err := d.release(ctx, repo, tag)
+	if err != nil {
+		return ReleaseAsset{}, err
+	}
+
+	// Write the asset under a reserved name first so a failed upload never
+	// replaces the asset.
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return ReleaseAsset{}, err
+	}
+
+	strg := storage.NewLocalStorage(d.releasesPath(r.ID()))
+	tmp := releaseAssetPath(m.ID, ".upload-"+hex.EncodeToString(buf))
+	h := sha256.New()
+	size, err := strg.Put(tmp, io.TeeReader(content, h))
+	if err != nil {
+		strg.Delete(tmp) // nolint: errcheck
+		return ReleaseAsset{}, fmt.Errorf("failed to store release asset: %w", err)
+	}
+
+	if err := strg.Rename(tmp, releaseAssetPath(m.ID, name)); err != nil {
+		strg.Delete(tmp) // nolint: errcheck
+		return ReleaseAsset{}, fmt.Errorf("failed to store release asset: %w", err)
+	}
+
+	sum := hex.EncodeToString(h.Sum(nil))
+	if err := db.WrapError(d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		return d.store.SetReleaseAsset(ctx, tx, m.ID, name, size, sum)
+	})); err != nil {
+		return ReleaseAsset{}, err
+	}
+
+	details := fmt.Sprintf("%s %s %s", tag, name, sum)
+	if err := d.recordAuditEvent(ctx, AuditActionReleaseAssetUploaded, r, nil, details); err != nil {
+		return ReleaseAsset{}, err
+	}
+
+	return ReleaseAsset{
+		Name:      name,
+		Size:      size,
+		SHA256:    sum,
+		UpdatedAt: time.Now(),
+	}, nil
+}
+
+// DeleteReleaseAsset deletes an asset of a release. It returns
+// proto.ErrReleaseAssetNotFound if there's no such asset.
+func (d *Backend) DeleteReleaseAsset(ctx context.Context, repo string, tag string, name string) error {
+	if err := d.checkWritable(); err != nil {
+		return err
+	}
+
+	r, m, err := d.release(ctx, repo, tag)
+	if err != nil {
+		return err
+	}
+
+	if err := db.WrapError(d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		return d.store.DeleteReleaseAsset(ctx, tx, m.ID, name)
+	})); err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return proto.ErrReleaseAssetNotFound
+		}
+		return err
+	}
+
+	strg := storage.NewLocalStorage(d.releasesPath(r.ID()))
+	if err := strg.Delete(releaseAssetPath(m.ID, name)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		d.logger.Error("failed to delete release asset", "repo", r.Name(), "tag", tag, "asset", name, "err", err)
+	}
+
+	return d.recordAuditEvent(ctx, AuditActionReleaseAssetDeleted, r, nil, tag+" "+name)
+}
+
+// OpenReleaseAsset opens an asset of a release for reading. The caller must
+// close it. It returns proto.ErrReleaseAssetNotFound if there's no such
+// asset.
+func (d *Backend) OpenReleaseAsset(ctx context.Context, repo string, tag string, name string) (storage.Object, ReleaseAsset, error) {
+	r, m, err := d.release(ctx, repo, tag)
+	if err != nil {
+		return nil, ReleaseAsset{}, err
+	}
+
+	var am models.ReleaseAsset
+	if err := d.retryTx(ctx, "release_asset", func(tx *db.Tx) error {
+		var err error
+		am, err = d.store.GetReleaseAsset(ctx, tx, m.ID, name)
+		return err
+	}); err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return nil, ReleaseAsset{}, proto.ErrReleaseAssetNotFound
+		}
+		return nil, ReleaseAsset{}, db.WrapError(err)
+	}
+
+	strg := storage.NewLocalStorage(d.releasesPath(r.ID()))
+	obj, err := strg.Open(releaseAssetPath(m.ID, name))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			d.logger.Error("missing release asset", "repo", r.Name(), "tag", tag, "asset", name)
+			return nil, ReleaseAsset{}, proto.ErrReleaseAssetNotFound
+		}
+		return nil, ReleaseAsset{}, err
+	}
+
+	return obj, newReleaseAsset(am), nil
+}
+
+// release returns a repository and its release for a tag. It returns
+// proto.ErrReleaseNotFound if there's no such release.
+func (d *Backend) release(ctx context.Context, repo string, tag string) (proto.Repository, models.Release, error) {
+	r, err := d.Repository(ctx, repo)
+	if err != nil {
+		return nil, models.Release{}, err
+	}
+
+	var m models.Release
+	if err := d.retryTx(ctx, "release", func(tx *db.Tx) error {
+		var err error
+		m, err = d.store.GetRelease(ctx, tx, r.ID(), tag)
+		return err
+	}); err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return nil, models.Release{}, proto.ErrReleaseNotFound
+		}
+		return nil, models.Release{}, db.WrapError(err)
+	}
+
+	return r, m, nil
+}
+
+// newRelease returns a release with its assets.
+func (d *Backend) newRelease(ctx context.Context, m models.Release) (Release, error) {
+	var ams []models.ReleaseAsset
+	if err := d.retryTx(ctx, "release_assets", func(tx *db.Tx) error {
+		var err error
+		ams, err = d.store.GetReleaseAssets(ctx, tx, m.ID)
+		return err
+	}); err != nil {
+		return Release{}, db.WrapError(err)
+	}
+
+	rel := Release{
+		Tag:       m.Tag,
+		Title:     m.Title,
+		Notes:     m.Notes,
+		Username:  m.Username.String,
+		Assets:    make([]ReleaseAsset, 0, len(ams)),
+		CreatedAt: m.CreatedAt,
+	}
+	for _, am := range ams {
+		rel.Assets = append(rel.Assets, newReleaseAsset(am))
+	}
+
+	return rel, nil
+}
+
+func newReleaseAsset(m models.ReleaseAsset) ReleaseAsset {
+	return ReleaseAsset{
+		Name:      m.Name,
+		Size:      m.Size,
+		SHA256:    m.SHA256,
+		UpdatedAt: m.UpdatedAt,
+	}
+}
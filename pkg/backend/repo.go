@@ -9,10 +9,14 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/charmbracelet/soft-serve/git"
+	"github.com/charmbracelet/soft-serve/pkg/access"
+	"github.com/charmbracelet/soft-serve/pkg/config"
 	"github.com/charmbracelet/soft-serve/pkg/db"
 	"github.com/charmbracelet/soft-serve/pkg/db/models"
 	"github.com/charmbracelet/soft-serve/pkg/hooks"
@@ -28,18 +32,118 @@ func (d *Backend) reposPath() string {
 	return filepath.Join(d.cfg.DataPath, "repos")
 }
 
+// checkWritable returns proto.ErrReadOnlyReplica when the server is a
+// replica. Repositories can only be created, deleted, or renamed on the
+// primary.
+func (d *Backend) checkWritable() error {
+	if d.cfg.Replication.IsReplica() {
+		return proto.ErrReadOnlyReplica
+	}
+
+	return nil
+}
+
 // CreateRepository creates a new repository.
 //
 // It implements backend.Backend.
 func (d *Backend) CreateRepository(ctx context.Context, name string, user proto.User, opts proto.RepositoryOptions) (proto.Repository, error) {
+	if err := d.checkWritable(); err != nil {
+		return nil, err
+	}
+
+	if err := d.checkCreateQuota(ctx, user); err != nil {
+		return nil, err
+	}
+
+	release, err := d.reserveRepoCreate(user)
+	if err != nil {
+		return nil, err
+	}
+
+	unlock, err := d.LockRepository(ctx, name, "create")
+	if err != nil {
+		release()
+		return nil, err
+	}
+	defer unlock()
+
+	r, err := d.createRepository(ctx, name, user, opts)
+	if err != nil {
+		release()
+		return nil, err
+	}
+
+	if err := d.postCreate(ctx, r, user); err != nil {
+		if rerr := d.deleteRepository(ctx, r.Name()); rerr != nil {
+			d.logger.Error("failed to delete repository", "err", rerr, "name", r.Name())
+		}
+		release()
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// createRepository creates a repository without checking the creation
+// limits.
+func (d *Backend) createRepository(ctx context.Context, name string, user proto.User, opts proto.RepositoryOptions) (proto.Repository, error) {
 	name = utils.SanitizeRepo(name)
 	if err := utils.ValidateRepo(name); err != nil {
 		return nil, err
 	}
 
+	if _, ok := d.ResolveRepoAlias(ctx, name); ok {
+		return nil, proto.ErrRepoAliasExist
+	}
+
 	repo := name + ".git"
 	rp := filepath.Join(d.reposPath(), repo)
 
+	if !opts.ExplicitVisibility {
+		switch vis, _ := d.cfg.Access.DefaultVisibility(name); vis {
+		case config.VisibilityPrivate:
+			opts.Private = true
+		case config.VisibilityHidden:
+			opts.Hidden = true
+		}
+	}
+
+	if opts.DefaultBranch != "" {
+		if _, err := git.NewCommand("check-ref-format", "--branch", opts.DefaultBranch).WithContext(ctx).Run(); err != nil {
+			return nil, fmt.Errorf("%w: %q", proto.ErrInvalidBranch, opts.DefaultBranch)
+		}
+	}
+
+	format, err := validateObjectFormat(opts.ObjectFormat)
+	if err != nil {
+		return nil, err
+	}
+
+	var tmplPath string
+	if opts.Template != "" {
+		// Users can only copy the repositories they can read, the others look
+		// like they don't exist.
+		if d.AccessLevelForUser(ctx, opts.Template, user) < access.ReadOnlyAccess {
+			return nil, fmt.Errorf("template %q: %w", opts.Template, proto.ErrRepoNotFound)
+		}
+
+		tmpl, err := d.Repository(ctx, opts.Template)
+		if err != nil {
+			return nil, fmt.Errorf("template %q: %w", opts.Template, err)
+		}
+
+		tr, err := tmpl.Open()
+		if err != nil {
+			return nil, err
+		}
+
+		if err := checkObjectFormat(ctx, tr.Path, format, fmt.Sprintf("template %q", opts.Template)); err != nil {
+			return nil, err
+		}
+
+		tmplPath = tr.Path
+	}
+
 	var userID int64
 	if user != nil {
 		userID = user.ID()
@@ -60,12 +164,38 @@ func (d *Backend) CreateRepository(ctx context.Context, name string, user proto.
 			return err
 		}
 
-		_, err := git.Init(rp, true)
+		// The new repository takes precedence over a renamed one.
+		if err := d.store.DeleteRepoRename(ctx, tx, name); err != nil {
+			return err
+		}
+
+		r, err := initRepository(ctx, rp, format)
 		if err != nil {
 			d.logger.Debug("failed to create repository", "err", err)
 			return err
 		}
 
+		if tmplPath != "" {
+			if err := copyTemplate(ctx, r, tmplPath); err != nil {
+				d.logger.Error("failed to copy template", "repo", name, "template", opts.Template, "err", err)
+				return err
+			}
+		}
+
+		if d.cfg.Pack != (config.PackConfig{Compression: config.DefaultPackCompression, Window: config.DefaultPackWindow}) {
+			if err := setPackConfig(r, d.cfg.Pack); err != nil {
+				d.logger.Error("failed to set pack config", "repo", name, "err", err)
+				return err
+			}
+		}
+
+		if opts.DefaultBranch != "" {
+			if _, err := r.SymbolicRef(git.HEAD, git.RefsHeads+opts.DefaultBranch); err != nil {
+				d.logger.Error("failed to set default branch", "repo", name, "err", err)
+				return err
+			}
+		}
+
 		if err := os.WriteFile(filepath.Join(rp, "description"), []byte(opts.Description), fs.ModePerm); err != nil {
 			d.logger.Error("failed to write description", "repo", name, "err", err)
 			return err
@@ -89,12 +219,47 @@ func (d *Backend) CreateRepository(ctx context.Context, name string, user proto.
 		return nil, err
 	}
 
-	return d.Repository(ctx, name)
+	d.addDefaultCollaborators(ctx, name, user)
+
+	r, err := d.Repository(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := d.SetRepoSettings(ctx, name, map[string]string{settingObjectFormat: format}); err != nil {
+		d.logger.Error("failed to record object format", "repo", name, "err", err)
+	}
+
+	if opts.Readme {
+		if err := d.commitReadme(ctx, r, user); err != nil {
+			d.logger.Error("failed to commit readme", "repo", name, "err", err)
+		}
+	}
+
+	return r, nil
+}
+
+// addDefaultCollaborators grants the default collaborators access to a new
+// repository. Failing to add one doesn't fail the creation of the repository.
+func (d *Backend) addDefaultCollaborators(ctx context.Context, repo string, creator proto.User) {
+	for username, level := range d.cfg.Access.DefaultCollaborators {
+		if creator != nil && strings.EqualFold(username, creator.Username()) {
+			continue
+		}
+
+		if err := d.AddCollaborator(ctx, repo, username, access.ParseAccessLevel(level)); err != nil {
+			d.logger.Error("failed to add default collaborator", "repo", repo, "username", username, "err", err)
+		}
+	}
 }
 
 // ImportRepository imports a repository from remote.
 // XXX: This a expensive operation and should be run in a goroutine.
-func (d *Backend) ImportRepository(_ context.Context, name string, user proto.User, remote string, opts proto.RepositoryOptions) (proto.Repository, error) {
+func (d *Backend) ImportRepository(ctx context.Context, name string, user proto.User, remote string, opts proto.RepositoryOptions) (proto.Repository, error) {
+	if err := d.checkWritable(); err != nil {
+		return nil, err
+	}
+
 	name = utils.SanitizeRepo(name)
 	if err := utils.ValidateRepo(name); err != nil {
 		return nil, err
@@ -112,29 +277,69 @@ func (d *Backend) ImportRepository(_ context.Context, name string, user proto.Us
 		return nil, proto.ErrRepoExist
 	}
 
+	if _, ok := d.ResolveRepoAlias(ctx, name); ok {
+		return nil, proto.ErrRepoAliasExist
+	}
+
+	if opts.ObjectFormat != "" {
+		if _, err := validateObjectFormat(opts.ObjectFormat); err != nil {
+			return nil, err
+		}
+	}
+
+	// Local repositories can be any repository on the server, including
+	// private ones, so only admins can import them.
+	local := isLocalRemote(remote)
+	if local && (user == nil || !user.IsAdmin()) {
+		return nil, proto.ErrUnauthorized
+	}
+
+	// Check the quota and creation limit before cloning, the clone can take a
+	// while.
+	if err := d.checkCreateQuota(ctx, user); err != nil {
+		return nil, err
+	}
+
+	release, err := d.reserveRepoCreate(user)
+	if err != nil {
+		return nil, err
+	}
+
 	done := make(chan error, 1)
 	repoc := make(chan proto.Repository, 1)
 	d.logger.Info("importing repository", "name", name, "remote", remote, "path", rp)
 	d.manager.Add(tid, func(ctx context.Context) (err error) {
 		ctx = proto.WithUserContext(ctx, user)
+		// Don't leave the caller waiting on the repository if the import
+		// fails before it's created.
+		defer close(repoc)
+		defer func() {
+			if err != nil {
+				release()
+			}
+		}()
+
+		unlock, err := d.LockRepository(ctx, name, "import")
+		if err != nil {
+			return err
+		}
+		defer unlock()
+
+		envs := []string{
+			fmt.Sprintf(`GIT_SSH_COMMAND=ssh -o UserKnownHostsFile="%s" -o StrictHostKeyChecking=no -i "%s"`,
+				filepath.Join(d.cfg.DataPath, "ssh", "known_hosts"),
+				d.cfg.SSH.ClientKeyPath,
+			),
+		}
+
+		// The stored credentials are the server's, only admins can use them.
+		username, password, withCredentials := d.importCredentials(remote)
+		withCredentials = withCredentials && user != nil && user.IsAdmin()
+		if withCredentials {
+			envs = append(envs, credentialEnvs(remote, username, password)...)
+		}
 
-		copts := git.CloneOptions{
-			Bare:   true,
-			Mirror: opts.Mirror,
-			Quiet:  true,
-			CommandOptions: git.CommandOptions{
-				Timeout: -1,
-				Context: ctx,
-				Envs: []string{
-					fmt.Sprintf(`GIT_SSH_COMMAND=ssh -o UserKnownHostsFile="%s" -o StrictHostKeyChecking=no -i "%s"`,
-						filepath.Join(d.cfg.DataPath, "ssh", "known_hosts"),
-						d.cfg.SSH.ClientKeyPath,
-					),
-				},
-			},
-		}
-
-		if err := git.Clone(remote, rp, copts); err != nil {
+		if err := cloneRemote(ctx, remote, rp, opts.Mirror, opts.Progress, envs); err != nil {
 			d.logger.Error("failed to clone repository", "err", err, "mirror", opts.Mirror, "remote", remote, "path", rp)
 			// Cleanup the mess!
 			if rerr := os.RemoveAll(rp); rerr != nil {
@@ -144,7 +349,42 @@ func (d *Backend) ImportRepository(_ context.Context, name string, user proto.Us
 			return err
 		}
 
-		r, err := d.CreateRepository(ctx, name, user, opts)
+		if err := d.checkAlternates(ctx, rp, opts.Dissociate); err != nil {
+			d.logger.Error("invalid repository alternates", "err", err, "remote", remote, "path", rp)
+			if rerr := os.RemoveAll(rp); rerr != nil {
+				err = errors.Join(err, rerr)
+			}
+
+			return err
+		}
+
+		// The new repository keeps the object format of the remote.
+		format, err := repoObjectFormat(ctx, rp)
+		if err == nil && opts.ObjectFormat != "" && format != opts.ObjectFormat {
+			err = fmt.Errorf("%w: the remote uses %s, not %s", proto.ErrObjectFormatMismatch, format, opts.ObjectFormat)
+		}
+		if err != nil {
+			d.logger.Error("invalid repository object format", "err", err, "remote", remote, "path", rp)
+			if rerr := os.RemoveAll(rp); rerr != nil {
+				err = errors.Join(err, rerr)
+			}
+
+			return err
+		}
+		opts.ObjectFormat = format
+
+		if opts.Fsck {
+			if err := fsckRepository(ctx, rp, opts.Progress); err != nil {
+				d.logger.Error("imported repository failed fsck", "err", err, "remote", remote, "path", rp)
+				if rerr := os.RemoveAll(rp); rerr != nil {
+					err = errors.Join(err, rerr)
+				}
+
+				return err
+			}
+		}
+
+		r, err := d.createRepository(ctx, name, user, opts)
 		if err != nil {
 			d.logger.Error("failed to create repository", "err", err, "name", name)
 			return err
@@ -152,7 +392,7 @@ func (d *Backend) ImportRepository(_ context.Context, name string, user proto.Us
 
 		defer func() {
 			if err != nil {
-				if rerr := d.DeleteRepository(ctx, name); rerr != nil {
+				if rerr := d.deleteRepository(ctx, name); rerr != nil {
 					d.logger.Error("failed to delete repository", "err", rerr, "name", name)
 				}
 			}
@@ -164,6 +404,16 @@ func (d *Backend) ImportRepository(_ context.Context, name string, user proto.Us
 			return err
 		}
 
+		if withCredentials && opts.Mirror {
+			if err := d.SetRepoSettings(ctx, name, map[string]string{settingImportCredentials: "true"}); err != nil {
+				return err
+			}
+		}
+
+		if err := d.postCreate(ctx, r, user); err != nil {
+			return err
+		}
+
 		repoc <- r
 
 		rcfg, err := rr.Config()
@@ -175,6 +425,9 @@ func (d *Backend) ImportRepository(_ context.Context, name string, user proto.Us
 		endpoint := remote
 		if opts.LFSEndpoint != "" {
 			endpoint = opts.LFSEndpoint
+		} else if local {
+			// Local repositories have no LFS server.
+			return nil
 		}
 
 		rcfg.Section("lfs").SetOption("url", endpoint)
@@ -212,10 +465,32 @@ func (d *Backend) ImportRepository(_ context.Context, name string, user proto.Us
 	return <-repoc, <-done
 }
 
+// isLocalRemote returns whether remote is a repository on the local file
+// system rather than a URL.
+func isLocalRemote(remote string) bool {
+	return strings.HasPrefix(remote, "file://") || filepath.IsAbs(remote) ||
+		strings.HasPrefix(remote, "./") || strings.HasPrefix(remote, "../")
+}
+
 // DeleteRepository deletes a repository.
 //
 // It implements backend.Backend.
 func (d *Backend) DeleteRepository(ctx context.Context, name string) error {
+	if err := d.checkWritable(); err != nil {
+		return err
+	}
+
+	unlock, err := d.LockRepository(ctx, name, "delete")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	return d.deleteRepository(ctx, name)
+}
+
+// deleteRepository deletes a repository without taking its lock.
+func (d *Backend) deleteRepository(ctx context.Context, name string) error {
 	name = utils.SanitizeRepo(name)
 	repo := name + ".git"
 	rp := filepath.Join(d.reposPath(), repo)
@@ -269,6 +544,10 @@ func (d *Backend) DeleteRepository(ctx context.Context, name string) error {
 			}
 		}
 
+		if err := os.RemoveAll(d.releasesPath(repom.ID)); err != nil {
+			d.logger.Error("failed to delete release assets", "repo", name, "err", err)
+		}
+
 		if err := d.store.DeleteRepoByName(ctx, tx, name); err != nil {
 			return db.WrapError(err)
 		}
@@ -316,6 +595,10 @@ func (d *Backend) DeleteUserRepositories(ctx context.Context, username string) e
 //
 // It implements backend.Backend.
 func (d *Backend) RenameRepository(ctx context.Context, oldName string, newName string) error {
+	if err := d.checkWritable(); err != nil {
+		return err
+	}
+
 	oldName = utils.SanitizeRepo(oldName)
 	if err := utils.ValidateRepo(oldName); err != nil {
 		return err
@@ -330,6 +613,18 @@ func (d *Backend) RenameRepository(ctx context.Context, oldName string, newName
 		return nil
 	}
 
+	// Lock both names in the same order for renames in opposite directions
+	// not to wait for each other.
+	names := []string{oldName, newName}
+	sort.Strings(names)
+	for _, n := range names {
+		unlock, err := d.LockRepository(ctx, n, "rename")
+		if err != nil {
+			return err
+		}
+		defer unlock()
+	}
+
 	oldRepo := oldName + ".git"
 	newRepo := newName + ".git"
 	op := filepath.Join(d.reposPath(), oldRepo)
@@ -342,14 +637,35 @@ func (d *Backend) RenameRepository(ctx context.Context, oldName string, newName
 		return proto.ErrRepoExist
 	}
 
+	// The repository can take the name of one of its own aliases.
+	alias, aliased := d.ResolveRepoAlias(ctx, newName)
+	if aliased && alias.RepoName != oldName {
+		return proto.ErrRepoAliasExist
+	}
+
+	old, err := d.Repository(ctx, oldName)
+	if err != nil {
+		return err
+	}
+
 	if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
 		// Delete cache
 		defer d.cache.Delete(oldName)
 
+		if aliased {
+			if err := d.store.DeleteRepoAlias(ctx, tx, newName); err != nil {
+				return err
+			}
+		}
+
 		if err := d.store.SetRepoNameByName(ctx, tx, oldName, newName); err != nil {
 			return err
 		}
 
+		if err := d.recordRename(ctx, tx, old.ID(), oldName, newName); err != nil {
+			return err
+		}
+
 		// Make sure the new repository parent directory exists.
 		if err := os.MkdirAll(filepath.Dir(np), os.ModePerm); err != nil {
 			return err
@@ -426,7 +742,7 @@ func (d *Backend) Repository(ctx context.Context, name string) (proto.Repository
 		return nil, proto.ErrRepoNotFound
 	}
 
-	if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+	if err := d.retryTx(ctx, "repository", func(tx *db.Tx) error {
 		var err error
 		m, err = d.store.GetRepoByName(ctx, tx, name)
 		return db.WrapError(err)
@@ -757,3 +1073,22 @@ func readOneline(path string) (string, error) {
 	s.Scan()
 	return s.Text(), s.Err()
 }
+
+// copyTemplate copies the branches and tags of the template repository at
+// tmplPath into r, and points HEAD at the template's default branch.
+func copyTemplate(ctx context.Context, r *git.Repository, tmplPath string) error {
+	if _, err := git.NewCommand(
+		"fetch", "--quiet", "--no-tags", tmplPath,
+		"+refs/heads/*:refs/heads/*", "+refs/tags/*:refs/tags/*",
+	).WithContext(ctx).WithTimeout(-1).RunInDir(r.Path); err != nil {
+		return err
+	}
+
+	head, err := git.NewCommand("symbolic-ref", git.HEAD).WithContext(ctx).RunInDir(tmplPath)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.SymbolicRef(git.HEAD, strings.TrimSpace(string(head)))
+	return err
+}
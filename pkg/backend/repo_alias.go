@@ -0,0 +1,116 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/db/models"
+	"github.com/charmbracelet/soft-serve/pkg/proto"
+	"github.com/charmbracelet/soft-serve/pkg/utils"
+)
+
+// ResolveRepoAlias returns the alias named name, and whether there's one.
+// The alias has the name of the canonical repository. name must be
+// sanitized.
+func (d *Backend) ResolveRepoAlias(ctx context.Context, name string) (models.RepoAlias, bool) {
+	var alias models.RepoAlias
+	if err := d.retryTx(ctx, "repo_alias", func(tx *db.Tx) error {
+		var err error
+		alias, err = d.store.GetRepoAlias(ctx, tx, name)
+		return err
+	}); err != nil {
+		if !errors.Is(err, db.ErrRecordNotFound) {
+			d.logger.Error("error resolving repository alias", "name", name, "err", err)
+		}
+		return alias, false
+	}
+
+	return alias, true
+}
+
+// RepoAliases returns the aliases of a repository.
+func (d *Backend) RepoAliases(ctx context.Context, repo string) ([]models.RepoAlias, error) {
+	r, err := d.Repository(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	var aliases []models.RepoAlias
+	if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		aliases, err = d.store.GetRepoAliasesByRepoID(ctx, tx, r.ID())
+		return err
+	}); err != nil {
+		return nil, db.WrapError(err)
+	}
+
+	return aliases, nil
+}
+
+// AddRepoAlias adds an alias to a repository. Clients using the alias are
+// transparently served the repository, and shown message if it's not empty.
+// The alias can't be the name of an existing repository or alias, and
+// aliases always point to a repository, never to another alias, so they
+// can't form cycles.
+func (d *Backend) AddRepoAlias(ctx context.Context, repo string, alias string, message string) error {
+	if err := d.checkWritable(); err != nil {
+		return err
+	}
+
+	alias = utils.SanitizeRepo(alias)
+	if err := utils.ValidateRepo(alias); err != nil {
+		return err
+	}
+
+	r, err := d.Repository(ctx, repo)
+	if err != nil {
+		return err
+	}
+
+	if _, err := d.Repository(ctx, alias); err == nil {
+		return proto.ErrRepoExist
+	}
+
+	if _, err := os.Stat(filepath.Join(d.reposPath(), alias+".git")); err == nil {
+		return proto.ErrRepoExist
+	}
+
+	if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		return d.store.CreateRepoAlias(ctx, tx, r.ID(), alias, message)
+	}); err != nil {
+		err = db.WrapError(err)
+		if errors.Is(err, db.ErrDuplicateKey) {
+			return proto.ErrRepoAliasExist
+		}
+		return err
+	}
+
+	return nil
+}
+
+// RemoveRepoAlias removes an alias of a repository.
+func (d *Backend) RemoveRepoAlias(ctx context.Context, repo string, alias string) error {
+	if err := d.checkWritable(); err != nil {
+		return err
+	}
+
+	r, err := d.Repository(ctx, repo)
+	if err != nil {
+		return err
+	}
+
+	alias = utils.SanitizeRepo(alias)
+	return db.WrapError(d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		a, err := d.store.GetRepoAlias(ctx, tx, alias)
+		if errors.Is(db.WrapError(err), db.ErrRecordNotFound) || (err == nil && a.RepoID != r.ID()) {
+			return proto.ErrRepoAliasNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		return d.store.DeleteRepoAlias(ctx, tx, alias)
+	}))
+}
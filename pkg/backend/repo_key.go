@@ -0,0 +1,159 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/soft-serve/pkg/access"
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/db/models"
+	"github.com/charmbracelet/soft-serve/pkg/proto"
+	"github.com/charmbracelet/soft-serve/pkg/sshutils"
+	"github.com/charmbracelet/soft-serve/pkg/utils"
+	gossh "golang.org/x/crypto/ssh"
+)
+
+// RepoKey is an SSH public key granted access to a repository, whether or
+// not it belongs to a user.
+type RepoKey struct {
+	// PublicKey is the key.
+	PublicKey gossh.PublicKey
+	// AccessLevel is the access level of the key to the repository.
+	AccessLevel access.AccessLevel
+	// CreatedAt is when the key was first granted access.
+	CreatedAt time.Time
+}
+
+// GrantRepoKey grants a public key an access level to a repository,
+// replacing the level it already has. The key doesn't need to belong to a
+// user, and gets the access even if it has none on the rest of the server.
+func (d *Backend) GrantRepoKey(ctx context.Context, repo string, pk gossh.PublicKey, level access.AccessLevel) error {
+	if level <= access.NoAccess {
+		return fmt.Errorf("%w: keys must be granted read-only access or higher", access.ErrInvalidAccessLevel)
+	}
+
+	r, err := d.Repository(ctx, repo)
+	if err != nil {
+		return err
+	}
+
+	fp := gossh.FingerprintSHA256(pk)
+	if err := db.WrapError(d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		return d.store.SetRepoKey(ctx, tx, r.Name(), fp, sshutils.MarshalAuthorizedKey(pk), level)
+	})); err != nil {
+		return err
+	}
+
+	return d.recordAuditEvent(ctx, AuditActionRepoKeyGranted, r, nil, fmt.Sprintf("%s %s", fp, level))
+}
+
+// RemoveRepoKey removes the access of a public key to a repository. It
+// returns proto.ErrRepoKeyNotFound if the key wasn't granted access.
+func (d *Backend) RemoveRepoKey(ctx context.Context, repo string, pk gossh.PublicKey) error {
+	r, err := d.Repository(ctx, repo)
+	if err != nil {
+		return err
+	}
+
+	fp := gossh.FingerprintSHA256(pk)
+	if err := db.WrapError(d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		return d.store.DeleteRepoKey(ctx, tx, r.Name(), fp)
+	})); err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return proto.ErrRepoKeyNotFound
+		}
+		return err
+	}
+
+	return d.recordAuditEvent(ctx, AuditActionRepoKeyRemoved, r, nil, fp)
+}
+
+// RepoKeys returns the public keys granted access to a repository, oldest
+// first.
+func (d *Backend) RepoKeys(ctx context.Context, repo string) ([]RepoKey, error) {
+	r, err := d.Repository(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	ms, err := d.repoKeys(ctx, r.Name())
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]RepoKey, 0, len(ms))
+	for _, m := range ms {
+		pk, _, err := sshutils.ParseAuthorizedKey(m.PublicKey)
+		if err != nil {
+			d.logger.Error("invalid repository key", "repo", r.Name(), "fingerprint", m.Fingerprint, "err", err)
+			continue
+		}
+
+		keys = append(keys, RepoKey{PublicKey: pk, AccessLevel: m.AccessLevel, CreatedAt: m.CreatedAt})
+	}
+
+	return keys, nil
+}
+
+func (d *Backend) repoKeys(ctx context.Context, repo string) ([]models.RepoKey, error) {
+	var keys []models.RepoKey
+	if err := d.retryTx(ctx, "repo_keys", func(tx *db.Tx) error {
+		var err error
+		keys, err = d.store.GetRepoKeys(ctx, tx, utils.SanitizeRepo(repo))
+		return err
+	}); err != nil {
+		return nil, db.WrapError(err)
+	}
+
+	return keys, nil
+}
+
+// KeyHasRepoAccess returns whether a public key was granted access to any
+// repository, directly or with a deploy token.
+func (d *Backend) KeyHasRepoAccess(ctx context.Context, pk gossh.PublicKey) bool {
+	var keys []models.RepoKey
+	if err := d.retryTx(ctx, "repo_keys_by_fingerprint", func(tx *db.Tx) error {
+		var err error
+		keys, err = d.store.GetRepoKeysByFingerprint(ctx, tx, gossh.FingerprintSHA256(pk))
+		return err
+	}); err != nil {
+		d.logger.Error("failed to get repository keys", "fingerprint", gossh.FingerprintSHA256(pk), "err", err)
+		return false
+	}
+	if len(keys) > 0 {
+		return true
+	}
+
+	tokens, err := d.deployTokensByKey(ctx, pk)
+	if err != nil {
+		d.logger.Error("failed to get deploy tokens", "fingerprint", gossh.FingerprintSHA256(pk), "err", err)
+		return false
+	}
+
+	return len(tokens) > 0
+}
+
+// repoKeyAccessLevel returns the access level granted to the public key on
+// the repository, if any.
+func (d *Backend) repoKeyAccessLevel(ctx context.Context, repo string, pk gossh.PublicKey) (access.AccessLevel, error) {
+	level := access.NoAccess
+	if pk == nil {
+		return level, nil
+	}
+
+	keys, err := d.repoKeys(ctx, repo)
+	if err != nil {
+		return -1, err
+	}
+
+	fp := gossh.FingerprintSHA256(pk)
+	for _, k := range keys {
+		if k.Fingerprint == fp && k.AccessLevel > level {
+			level = k.AccessLevel
+		}
+	}
+
+	return level, nil
+}
@@ -0,0 +1,79 @@
+package backend
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/soft-serve/pkg/proto"
+)
+
+// repoCreateLimiter tracks recent repository creations keyed by owner.
+type repoCreateLimiter struct {
+	mu      sync.Mutex
+	created map[int64][]time.Time
+}
+
+// reserve records a repository creation by the owner if it's within the limit
+// and returns a function to undo it when the creation fails. Otherwise, it
+// returns how long until the owner can create a repository again.
+func (l *repoCreateLimiter) reserve(owner int64, limit int, window time.Duration, now time.Time) (release func(), wait time.Duration, ok bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.created == nil {
+		l.created = map[int64][]time.Time{}
+	}
+
+	// Drop creations that are outside the window.
+	recent := l.created[owner][:0]
+	for _, t := range l.created[owner] {
+		if now.Sub(t) < window {
+			recent = append(recent, t)
+		}
+	}
+
+	if len(recent) >= limit {
+		l.created[owner] = recent
+		return nil, recent[0].Add(window).Sub(now), false
+	}
+
+	l.created[owner] = append(recent, now)
+	return func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		times := l.created[owner]
+		for i, t := range times {
+			if t.Equal(now) {
+				l.created[owner] = append(times[:i], times[i+1:]...)
+				break
+			}
+		}
+	}, 0, true
+}
+
+// reserveRepoCreate checks the repository creation limit of the given owner
+// and records the creation. The returned function undoes the record and must
+// be called if the creation fails. Admins are not limited. A nil owner means
+// an anonymous user.
+func (d *Backend) reserveRepoCreate(owner proto.User) (func(), error) {
+	limit := d.cfg.RepoLimits.CreatePerWindow
+	if limit <= 0 || (owner != nil && owner.IsAdmin()) {
+		return func() {}, nil
+	}
+
+	var id int64
+	if owner != nil {
+		id = owner.ID()
+	}
+
+	window := time.Duration(d.cfg.RepoLimits.Window) * time.Second
+	release, wait, ok := d.createLimiter.reserve(id, limit, window, time.Now())
+	if !ok {
+		return nil, fmt.Errorf("%w: you can create %d repositories every %s, try again in %s",
+			proto.ErrRepoCreateLimit, limit, window, wait.Round(time.Second))
+	}
+
+	return release, nil
+}
@@ -0,0 +1,47 @@
+package backend
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRepoCreateLimiter(t *testing.T) {
+	var l repoCreateLimiter
+	now := time.Now()
+	window := time.Hour
+
+	for i := 0; i < 2; i++ {
+		if _, _, ok := l.reserve(1, 2, window, now); !ok {
+			t.Fatalf("creation %d should be allowed", i+1)
+		}
+	}
+
+	_, wait, ok := l.reserve(1, 2, window, now.Add(time.Minute))
+	if ok {
+		t.Fatal("creation over the limit should be denied")
+	}
+	if wait != window-time.Minute {
+		t.Fatalf("expected to wait %s, got %s", window-time.Minute, wait)
+	}
+
+	// Other owners have their own limit.
+	if _, _, ok := l.reserve(2, 2, window, now); !ok {
+		t.Fatal("creation by another owner should be allowed")
+	}
+
+	// Creations outside the window don't count.
+	later := now.Add(window)
+	if _, _, ok := l.reserve(1, 2, window, later); !ok {
+		t.Fatal("creation after the window should be allowed")
+	}
+
+	// Failed creations don't count.
+	release, _, ok := l.reserve(1, 2, window, later.Add(time.Second))
+	if !ok {
+		t.Fatal("creation after the window should be allowed")
+	}
+	release()
+	if _, _, ok := l.reserve(1, 2, window, later.Add(2*time.Second)); !ok {
+		t.Fatal("released creation should not count")
+	}
+}
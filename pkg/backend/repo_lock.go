@@ -0,0 +1,404 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/soft-serve/pkg/config"
+	"github.com/charmbracelet/soft-serve/pkg/proto"
+	"github.com/charmbracelet/soft-serve/pkg/tracing"
+	"github.com/charmbracelet/soft-serve/pkg/utils"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var repoLockWaitSeconds = promauto.NewHistogramVec(prometheus.HistogramOpts{
+	Namespace: "soft_serve",
+	Subsystem: "repo",
+	Name:      "lock_wait_seconds",
+	Help:      "The time operations waited for a repository lock",
+	Buckets:   []float64{0.01, 0.1, 1, 5, 15, 60},
+}, []string{"op", "acquired"})
+
+// RepoLockHolder is an operation holding a repository lock.
+type RepoLockHolder struct {
+	// Op is the name of the operation, like "push" or "rename".
+	Op string
+	// Exclusive is whether the operation excludes all the others.
+	Exclusive bool
+	// Since is when the operation acquired the lock.
+	Since time.Time
+}
+
+// RepoLock is the state of a repository lock.
+type RepoLock struct {
+	// Repo is the name of the repository.
+	Repo string
+	// Holders are the operations holding the lock, oldest first.
+	Holders []RepoLockHolder
+	// Waiting is the number of operations waiting for the lock.
+	Waiting int
+}
+
+// repoLocks are the locks of the repositories keyed by name. Operations
+// changing refs, like pushes, share the lock of a repository, and operations
+// changing the repository as a whole, like renames, hold it exclusively.
+type repoLocks struct {
+	mu    sync.Mutex
+	locks map[string]*repoLockState
+}
+
+// repoLockState is the state of the lock of a repository.
+type repoLockState struct {
+	holders []*RepoLockHolder
+	waiting int
+	// exclusiveWaiting is the number of waiting exclusive operations. Shared
+	// operations wait behind them so they aren't starved by a steady stream
+	// of pushes.
+	exclusiveWaiting int
+	// changed is closed and replaced when the lock is released.
+	changed chan struct{}
+}
+
+// available returns whether an operation can acquire the lock.
+func (s *repoLockState) available(exclusive bool) bool {
+	if exclusive {
+		return len(s.holders) == 0
+	}
+
+	if s.exclusiveWaiting > 0 {
+		return false
+	}
+
+	for _, h := range s.holders {
+		if h.Exclusive {
+			return false
+		}
+	}
+
+	return true
+}
+
+// broadcast wakes up the operations waiting for the lock.
+func (s *repoLockState) broadcast() {
+	close(s.changed)
+	s.changed = make(chan struct{})
+}
+
+// acquire waits up to timeout for the lock of repo. A zero timeout doesn't
+// wait and a negative one waits until the context is done. It returns a
+// function releasing the lock, or false along with the current holders if
+// the lock wasn't acquired in time.
+func (l *repoLocks) acquire(ctx context.Context, repo string, op string, exclusive bool, timeout time.Duration) (func(), []RepoLockHolder, bool) {
+	var expired <-chan time.Time
+	if timeout > 0 {
+		t := time.NewTimer(timeout)
+		defer t.Stop()
+		expired = t.C
+	}
+
+	l.mu.Lock()
+	if l.locks == nil {
+		l.locks = map[string]*repoLockState{}
+	}
+	s, ok := l.locks[repo]
+	if !ok {
+		s = &repoLockState{changed: make(chan struct{})}
+		l.locks[repo] = s
+	}
+	s.waiting++
+	if exclusive {
+		s.exclusiveWaiting++
+	}
+
+	for !s.available(exclusive) {
+		changed := s.changed
+		if timeout == 0 {
+			return nil, l.giveUp(repo, s, exclusive), false
+		}
+		l.mu.Unlock()
+
+		select {
+		case <-changed:
+			l.mu.Lock()
+		case <-expired:
+			l.mu.Lock()
+			return nil, l.giveUp(repo, s, exclusive), false
+		case <-ctx.Done():
+			l.mu.Lock()
+			return nil, l.giveUp(repo, s, exclusive), false
+		}
+	}
+
+	h := &RepoLockHolder{Op: op, Exclusive: exclusive, Since: time.Now()}
+	s.holders = append(s.holders, h)
+	s.waiting--
+	if exclusive {
+		s.exclusiveWaiting--
+	}
+	l.mu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			l.mu.Lock()
+			defer l.mu.Unlock()
+			for i, o := range s.holders {
+				if o == h {
+					s.holders = append(s.holders[:i], s.holders[i+1:]...)
+					break
+				}
+			}
+			l.done(repo, s)
+		})
+	}, nil, true
+}
+
+// giveUp removes a waiting operation from the lock of repo and returns the
+// holders of the lock. It must be called with the mutex held, and unlocks
+// it.
+func (l *repoLocks) giveUp(repo string, s *repoLockState, exclusive bool) []RepoLockHolder {
+	defer l.mu.Unlock()
+	holders := make([]RepoLockHolder, len(s.holders))
+	for i, h := range s.holders {
+		holders[i] = *h
+	}
+
+	s.waiting--
+	if exclusive {
+		s.exclusiveWaiting--
+	}
+	l.done(repo, s)
+
+	return holders
+}
+
+// done wakes up the waiting operations once the lock of repo changed, and
+// forgets the lock once it has no operations left. It must be called with
+// the mutex held.
+func (l *repoLocks) done(repo string, s *repoLockState) {
+	s.broadcast()
+	if len(s.holders) == 0 && s.waiting == 0 && l.locks[repo] == s {
+		delete(l.locks, repo)
+	}
+}
+
+// state returns the state of the locks of all the repositories, sorted by
+// repository name.
+func (l *repoLocks) state() []RepoLock {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	locks := make([]RepoLock, 0, len(l.locks))
+	for repo, s := range l.locks {
+		lock := RepoLock{Repo: repo, Waiting: s.waiting}
+		for _, h := range s.holders {
+			lock.Holders = append(lock.Holders, *h)
+		}
+		locks = append(locks, lock)
+	}
+
+	sort.Slice(locks, func(i, j int) bool {
+		return locks[i].Repo < locks[j].Repo
+	})
+
+	return locks
+}
+
+// LockRepository takes the lock of a repository exclusively for the
+// operation op, waiting up to the repository lock timeout for the other
+// operations to finish. The returned function releases the lock and must be
+// called once the operation is over. It returns proto.ErrRepoLocked if the
+// lock wasn't acquired in time.
+//
+// Operations changing the repository as a whole, like renaming or deleting
+// it, take the lock exclusively.
+func (d *Backend) LockRepository(ctx context.Context, repo string, op string) (func(), error) {
+	return d.lockRepository(ctx, repo, op, true, d.repoLockTimeout())
+}
+
+// RLockRepository takes the lock of a repository shared with the other
+// shared operations, like LockRepository.
+//
+// Operations only changing refs, like pushes, share the lock, since git
+// already locks the refs they update.
+func (d *Backend) RLockRepository(ctx context.Context, repo string, op string) (func(), error) {
+	return d.lockRepository(ctx, repo, op, false, d.repoLockTimeout())
+}
+
+// TryLockRepository takes the lock of a repository exclusively like
+// LockRepository, without waiting if another operation holds it.
+func (d *Backend) TryLockRepository(ctx context.Context, repo string, op string) (func(), error) {
+	return d.lockRepository(ctx, repo, op, true, 0)
+}
+
+// LockRepositoryForPush takes the lock of a repository for a push, according
+// to the concurrent pushes mode. Pushes share the lock by default. Serialized
+// pushes hold it exclusively, waiting up to the queue timeout for the other
+// operations to finish, and rejected concurrent pushes don't wait at all.
+func (d *Backend) LockRepositoryForPush(ctx context.Context, repo string) (func(), error) {
+	switch d.cfg.ConcurrentPushes.Mode {
+	case config.ConcurrentPushesSerialize:
+		timeout := time.Duration(d.cfg.ConcurrentPushes.QueueTimeout) * time.Second
+		if timeout <= 0 {
+			timeout = -1
+		}
+		unlock, err := d.lockRepository(ctx, repo, "push", true, timeout)
+		if err != nil {
+			if timeout > 0 {
+				return nil, fmt.Errorf("gave up waiting %s for other pushes to finish: %w", timeout, err)
+			}
+			return nil, fmt.Errorf("gave up waiting for other pushes to finish: %w", err)
+		}
+		return unlock, nil
+	case config.ConcurrentPushesReject:
+		unlock, err := d.lockRepository(ctx, repo, "push", true, 0)
+		if err != nil {
+			return nil, fmt.Errorf("concurrent pushes are not allowed: %w", err)
+		}
+		return unlock, nil
+	default:
+		return d.RLockRepository(ctx, repo, "push")
+	}
+}
+
+// RepoLocks returns the repositories with operations holding or waiting for
+// their lock.
+func (d *Backend) RepoLocks() []RepoLock {
+	return d.repoLocks.state()
+}
+
+// repoLockTimeout returns how long operations wait for a repository lock. A
+// negative duration waits until the operation is canceled.
+func (d *Backend) repoLockTimeout() time.Duration {
+	if d.cfg.Timeouts.RepoLock <= 0 {
+		return -1
+	}
+
+	return time.Duration(d.cfg.Timeouts.RepoLock) * time.Second
+}
+
+func (d *Backend) lockRepository(ctx context.Context, repo string, op string, exclusive bool, timeout time.Duration) (func(), error) {
+	repo = utils.SanitizeRepo(repo)
+	start := time.Now()
+	_, span := tracing.Start(ctx, "backend.lock_repository", trace.WithAttributes(
+		attribute.String("repo", repo),
+		attribute.String("op", op),
+		attribute.Bool("exclusive", exclusive),
+	))
+	release, holders, ok := d.repoLocks.acquire(ctx, repo, op, exclusive, timeout)
+
+	// Other processes, like admin commands, hold the lock file of the
+	// repository.
+	var other bool
+	if ok {
+		remaining := timeout
+		if timeout > 0 {
+			remaining = max(timeout-time.Since(start), 0)
+		}
+		unlockFile, locked, err := d.lockRepoFile(ctx, repo, exclusive, remaining)
+		if err != nil {
+			release()
+			tracing.End(span, err)
+			return nil, fmt.Errorf("lock repository: %w", err)
+		}
+		if locked {
+			unlockMemory := release
+			var once sync.Once
+			release = func() {
+				once.Do(func() {
+					unlockFile()
+					unlockMemory()
+				})
+			}
+		} else {
+			release()
+			ok, other = false, true
+		}
+	}
+
+	repoLockWaitSeconds.WithLabelValues(op, fmt.Sprint(ok)).Observe(time.Since(start).Seconds())
+	span.SetAttributes(attribute.Bool("acquired", ok))
+	tracing.End(span, nil)
+	if !ok {
+		ops := make([]string, 0, len(holders))
+		for _, h := range holders {
+			ops = append(ops, h.Op)
+		}
+		d.logger.Info("repository is locked", "repo", repo, "op", op, "holders", ops, "other_process", other)
+		switch {
+		case other:
+			return nil, fmt.Errorf("%w by another process, try again later", proto.ErrRepoLocked)
+		case len(ops) == 0:
+			// Another operation is waiting to hold the lock exclusively.
+			return nil, fmt.Errorf("%w, try again later", proto.ErrRepoLocked)
+		}
+		return nil, fmt.Errorf("%w by %s, try again later", proto.ErrRepoLocked, strings.Join(ops, ", "))
+	}
+
+	return release, nil
+}
+
+// repoLockFileInterval is how often a lock file held by another process is
+// tried again.
+const repoLockFileInterval = 50 * time.Millisecond
+
+// lockRepoFile locks the lock file of repo, shared by the processes using the
+// data directory, waiting up to timeout like repoLocks.acquire. It returns a
+// function releasing the lock, or false if another process held it for the
+// whole timeout. The files are kept in their own directory, so they aren't
+// affected by renaming the repositories.
+func (d *Backend) lockRepoFile(ctx context.Context, repo string, exclusive bool, timeout time.Duration) (func(), bool, error) {
+	fp := filepath.Join(d.cfg.DataPath, "locks", "repos", filepath.FromSlash(repo)+".lock")
+	if err := os.MkdirAll(filepath.Dir(fp), os.ModePerm); err != nil {
+		return nil, false, err
+	}
+
+	f, err := os.OpenFile(fp, os.O_RDWR|os.O_CREATE, 0o600) // nolint: gosec
+	if err != nil {
+		return nil, false, err
+	}
+
+	var expired <-chan time.Time
+	if timeout > 0 {
+		t := time.NewTimer(timeout)
+		defer t.Stop()
+		expired = t.C
+	}
+
+	for {
+		locked, err := tryLockFile(f, exclusive)
+		if err != nil {
+			f.Close() // nolint: errcheck
+			return nil, false, err
+		}
+		if locked {
+			return func() {
+				unlockFile(f) // nolint: errcheck
+				f.Close()     // nolint: errcheck
+			}, true, nil
+		}
+		if timeout == 0 {
+			f.Close() // nolint: errcheck
+			return nil, false, nil
+		}
+
+		select {
+		case <-time.After(repoLockFileInterval):
+		case <-expired:
+			f.Close() // nolint: errcheck
+			return nil, false, nil
+		case <-ctx.Done():
+			f.Close() // nolint: errcheck
+			return nil, false, nil
+		}
+	}
+}
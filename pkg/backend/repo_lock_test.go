@@ -0,0 +1,202 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/charmbracelet/soft-serve/pkg/config"
+	"github.com/charmbracelet/soft-serve/pkg/proto"
+)
+
+func TestRepoLocks(t *testing.T) {
+	var l repoLocks
+	ctx := context.Background()
+
+	// Shared operations don't exclude each other.
+	push1, _, ok := l.acquire(ctx, "repo1", "push", false, 0)
+	if !ok {
+		t.Fatal("shared lock should be acquired")
+	}
+	push2, _, ok := l.acquire(ctx, "repo1", "push", false, 0)
+	if !ok {
+		t.Fatal("second shared lock should be acquired")
+	}
+
+	// Exclusive operations wait for the shared ones.
+	_, holders, ok := l.acquire(ctx, "repo1", "rename", true, 0)
+	if ok {
+		t.Fatal("exclusive lock should not be acquired while shared")
+	}
+	if len(holders) != 2 || holders[0].Op != "push" {
+		t.Fatalf("expected the pushes to hold the lock, got %+v", holders)
+	}
+
+	// Other repositories have their own lock.
+	other, _, ok := l.acquire(ctx, "repo2", "rename", true, 0)
+	if !ok {
+		t.Fatal("lock of another repository should be acquired")
+	}
+	other()
+
+	// Waiting exclusive operations get the lock once it's released, and
+	// shared operations queue behind them.
+	acquired := make(chan func())
+	go func() {
+		rename, _, ok := l.acquire(ctx, "repo1", "rename", true, 5*time.Second)
+		if !ok {
+			t.Error("exclusive lock should be acquired once released")
+		}
+		acquired <- rename
+	}()
+	for waiting := 0; waiting == 0; {
+		time.Sleep(time.Millisecond)
+		if state := l.state(); len(state) > 0 {
+			waiting = state[0].Waiting
+		}
+	}
+	if _, _, ok := l.acquire(ctx, "repo1", "push", false, 0); ok {
+		t.Fatal("shared lock should wait behind a waiting exclusive one")
+	}
+	push1()
+	push2()
+	rename := <-acquired
+
+	state := l.state()
+	if len(state) != 1 || len(state[0].Holders) != 1 || !state[0].Holders[0].Exclusive {
+		t.Fatalf("expected the rename to hold the lock, got %+v", state)
+	}
+
+	// Operations give up after the timeout.
+	if _, _, ok := l.acquire(ctx, "repo1", "push", false, 10*time.Millisecond); ok {
+		t.Fatal("shared lock should time out while exclusive")
+	}
+
+	// And once the context is done.
+	cctx, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, _, ok := l.acquire(cctx, "repo1", "push", false, -1); ok {
+		t.Fatal("shared lock should give up once the context is done")
+	}
+
+	// Releasing twice releases once, and released locks are forgotten.
+	rename()
+	rename()
+	if len(l.locks) != 0 {
+		t.Fatalf("expected released locks to be forgotten, got %d", len(l.locks))
+	}
+}
+
+func TestLockRepositoryForPush(t *testing.T) {
+	ctx := context.Background()
+	cfg := config.DefaultConfig()
+	cfg.DataPath = t.TempDir()
+	d := &Backend{cfg: cfg, logger: log.New(nil)}
+
+	// Pushes share the lock by default.
+	push1, err := d.LockRepositoryForPush(ctx, "repo1")
+	if err != nil {
+		t.Fatalf("push should be allowed: %v", err)
+	}
+	push2, err := d.LockRepositoryForPush(ctx, "repo1")
+	if err != nil {
+		t.Fatalf("concurrent push should be allowed: %v", err)
+	}
+	push1()
+	push2()
+
+	// Rejected concurrent pushes don't wait.
+	cfg.ConcurrentPushes.Mode = config.ConcurrentPushesReject
+	push1, err = d.LockRepositoryForPush(ctx, "repo1")
+	if err != nil {
+		t.Fatalf("push should be allowed: %v", err)
+	}
+	if _, err := d.LockRepositoryForPush(ctx, "repo1"); !errors.Is(err, proto.ErrRepoLocked) {
+		t.Fatalf("expected concurrent push to be rejected, got %v", err)
+	}
+	push1()
+
+	// Serialized pushes wait for each other.
+	cfg.ConcurrentPushes.Mode = config.ConcurrentPushesSerialize
+	push1, err = d.LockRepositoryForPush(ctx, "repo1")
+	if err != nil {
+		t.Fatalf("push should be allowed: %v", err)
+	}
+	acquired := make(chan func())
+	go func() {
+		push2, err := d.LockRepositoryForPush(ctx, "repo1")
+		if err != nil {
+			t.Errorf("queued push should be allowed once the other is done: %v", err)
+		}
+		acquired <- push2
+	}()
+	for waiting := 0; waiting == 0; {
+		time.Sleep(time.Millisecond)
+		if state := d.RepoLocks(); len(state) > 0 {
+			waiting = state[0].Waiting
+		}
+	}
+	push1()
+	(<-acquired)()
+
+	// And give up after the timeout.
+	cfg.ConcurrentPushes.QueueTimeout = 1
+	push1, err = d.LockRepositoryForPush(ctx, "repo1")
+	if err != nil {
+		t.Fatalf("push should be allowed: %v", err)
+	}
+	defer push1()
+	if _, err := d.LockRepositoryForPush(ctx, "repo1"); !errors.Is(err, proto.ErrRepoLocked) {
+		t.Fatalf("expected queued push to time out, got %v", err)
+	}
+}
+
+func TestLockRepositoryAcrossProcesses(t *testing.T) {
+	ctx := context.Background()
+	cfg := config.DefaultConfig()
+	cfg.DataPath = t.TempDir()
+
+	// Backends sharing the data directory only share the lock files, like
+	// the server and an admin command.
+	server := &Backend{cfg: cfg, logger: log.New(nil)}
+	admin := &Backend{cfg: cfg, logger: log.New(nil)}
+
+	push, err := server.RLockRepository(ctx, "team/repo1", "push")
+	if err != nil {
+		t.Fatalf("push should be allowed: %v", err)
+	}
+	mirror, err := admin.RLockRepository(ctx, "team/repo1", "push-mirror")
+	if err != nil {
+		t.Fatalf("shared lock should be acquired in another process: %v", err)
+	}
+	mirror()
+
+	if _, err := admin.TryLockRepository(ctx, "team/repo1", "rename-default-branch"); !errors.Is(err, proto.ErrRepoLocked) {
+		t.Fatalf("expected the push of the other process to hold the lock, got %v", err)
+	}
+
+	// Waiting operations get the lock once the other process releases it.
+	acquired := make(chan func())
+	go func() {
+		rename, err := admin.LockRepository(ctx, "team/repo1", "rename-default-branch")
+		if err != nil {
+			t.Errorf("exclusive lock should be acquired once released: %v", err)
+		}
+		acquired <- rename
+	}()
+	time.Sleep(2 * repoLockFileInterval)
+	push()
+	rename := <-acquired
+	if _, err := server.TryLockRepository(ctx, "team/repo1", "delete"); !errors.Is(err, proto.ErrRepoLocked) {
+		t.Fatalf("expected the rename of the other process to hold the lock, got %v", err)
+	}
+	rename()
+
+	unlock, err := server.TryLockRepository(ctx, "team/repo1", "delete")
+	if err != nil {
+		t.Fatalf("lock should be acquired once released: %v", err)
+	}
+	unlock()
+}
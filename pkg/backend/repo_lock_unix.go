@@ -0,0 +1,30 @@
+//go:build !windows
+
+package backend
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// tryLockFile locks f, shared or exclusively, without waiting. It returns
+// false if another process holds a conflicting lock.
+func tryLockFile(f *os.File, exclusive bool) (bool, error) {
+	how := syscall.LOCK_SH
+	if exclusive {
+		how = syscall.LOCK_EX
+	}
+
+	err := syscall.Flock(int(f.Fd()), how|syscall.LOCK_NB)
+	if errors.Is(err, syscall.EWOULDBLOCK) {
+		return false, nil
+	}
+
+	return err == nil, err
+}
+
+// unlockFile releases the lock of f.
+func unlockFile(f *os.File) error {
+	return syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+}
@@ -0,0 +1,71 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/db/models"
+	"github.com/charmbracelet/soft-serve/pkg/proto"
+)
+
+// renameGracePeriod returns how long clients using the old name of a renamed
+// repository are told about the new name. Zero disables rename redirects.
+func (d *Backend) renameGracePeriod() time.Duration {
+	return time.Duration(d.cfg.RepoRenames.GracePeriod) * 24 * time.Hour
+}
+
+// RenamedRepository returns the current name of the repository that was
+// known as name before being renamed, and whether there's one. Renames are
+// forgotten after the configured grace period. name must be sanitized.
+func (d *Backend) RenamedRepository(ctx context.Context, name string) (string, bool) {
+	period := d.renameGracePeriod()
+	if period <= 0 {
+		return "", false
+	}
+
+	var rename models.RepoRename
+	if err := d.retryTx(ctx, "repo_rename", func(tx *db.Tx) error {
+		var err error
+		rename, err = d.store.GetRepoRename(ctx, tx, name)
+		return err
+	}); err != nil {
+		if !errors.Is(err, db.ErrRecordNotFound) {
+			d.logger.Error("error resolving renamed repository", "name", name, "err", err)
+		}
+		return "", false
+	}
+
+	if time.Since(rename.CreatedAt) > period {
+		return "", false
+	}
+
+	return rename.RepoName, true
+}
+
+// RenamedRepositoryError returns an error telling a client using the old name
+// of a renamed repository to use url, the URL of its new name, instead.
+func RenamedRepositoryError(newName string, url string) error {
+	return fmt.Errorf("%w to %s, update your remote with `git remote set-url origin %s`", proto.ErrRepoRenamed, newName, url)
+}
+
+// recordRename records that a repository was renamed from oldName, replacing
+// an earlier rename from the same name. The new name stops being a past name
+// of any repository.
+func (d *Backend) recordRename(ctx context.Context, tx *db.Tx, repoID int64, oldName string, newName string) error {
+	if err := d.store.DeleteRepoRename(ctx, tx, newName); err != nil {
+		return err
+	}
+
+	if d.renameGracePeriod() <= 0 {
+		return nil
+	}
+
+	if err := d.store.DeleteRepoRename(ctx, tx, oldName); err != nil {
+		return err
+	}
+
+	return d.store.CreateRepoRename(ctx, tx, repoID, oldName)
+}
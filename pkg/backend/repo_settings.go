@@ -0,0 +1,59 @@
+package backend
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+)
+
+// RepoSettings returns the settings of a repository as a key/value map.
+func (d *Backend) RepoSettings(ctx context.Context, repo string) (map[string]string, error) {
+	r, err := d.Repository(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	settings := map[string]string{}
+	if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		ms, err := d.store.GetRepoSettingsByRepoID(ctx, tx, r.ID())
+		if err != nil {
+			return err
+		}
+
+		for _, m := range ms {
+			settings[m.Key] = m.Value
+		}
+
+		return nil
+	}); err != nil {
+		return nil, db.WrapError(err)
+	}
+
+	return settings, nil
+}
+
+// SetRepoSettings sets the given settings of a repository. Settings with an
+// empty value are deleted.
+func (d *Backend) SetRepoSettings(ctx context.Context, repo string, settings map[string]string) error {
+	r, err := d.Repository(ctx, repo)
+	if err != nil {
+		return err
+	}
+
+	return db.WrapError(d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		for k, v := range settings {
+			if v == "" {
+				if err := d.store.DeleteRepoSetting(ctx, tx, r.ID(), k); err != nil {
+					return err
+				}
+				continue
+			}
+
+			if err := d.store.SetRepoSetting(ctx, tx, r.ID(), k, v); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}))
+}
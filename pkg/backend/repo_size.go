@@ -0,0 +1,115 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var repoSizeGauge = promauto.NewGaugeVec(prometheus.GaugeOpts{
+	Namespace: "soft_serve",
+	Subsystem: "repo",
+	Name:      "size_bytes",
+	Help:      "The cached size of the repositories on disk",
+}, []string{"repo"})
+
+// RepoSize is the cached size of a repository on disk.
+type RepoSize struct {
+	Repo      string    `json:"repo"`
+	Size      int64     `json:"size"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// RepoSize returns the size of a repository on disk in bytes.
+//
+// Walking the repository directory is expensive on large repositories, so
+// the size is cached in the database. It's recomputed after each push, and
+// when it's older than repo_sizes.max_age.
+func (d *Backend) RepoSize(ctx context.Context, repo string) (int64, error) {
+	r, err := d.Repository(ctx, repo)
+	if err != nil {
+		return 0, err
+	}
+
+	m, err := d.store.GetRepoSize(ctx, d.db, r.ID())
+	if err != nil && !errors.Is(err, db.ErrRecordNotFound) {
+		return 0, db.WrapError(err)
+	}
+
+	maxAge := time.Duration(d.cfg.RepoSizes.MaxAge) * time.Second
+	if err == nil && (maxAge == 0 || time.Since(m.UpdatedAt) < maxAge) {
+		repoSizeGauge.WithLabelValues(r.Name()).Set(float64(m.Size))
+		return m.Size, nil
+	}
+
+	return d.RecomputeRepoSize(ctx, repo)
+}
+
+// RecomputeRepoSize computes the size of a repository on disk in bytes, and
+// caches it.
+func (d *Backend) RecomputeRepoSize(ctx context.Context, repo string) (int64, error) {
+	r, err := d.Repository(ctx, repo)
+	if err != nil {
+		return 0, err
+	}
+
+	rr, err := r.Open()
+	if err != nil {
+		return 0, err
+	}
+
+	size, err := dirSize(rr.Path)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := d.store.SetRepoSize(ctx, d.db, r.ID(), size); err != nil {
+		return 0, db.WrapError(err)
+	}
+
+	repoSizeGauge.WithLabelValues(r.Name()).Set(float64(size))
+	return size, nil
+}
+
+// RepoSizes returns the cached sizes of all repositories, by name.
+// Repositories without a cached size are left out.
+func (d *Backend) RepoSizes(ctx context.Context) ([]RepoSize, error) {
+	ms, err := d.store.GetRepoSizes(ctx, d.db)
+	if err != nil {
+		return nil, db.WrapError(err)
+	}
+
+	sizes := make([]RepoSize, 0, len(ms))
+	for _, m := range ms {
+		sizes = append(sizes, RepoSize{Repo: m.RepoName, Size: m.Size, UpdatedAt: m.UpdatedAt})
+	}
+
+	return sizes, nil
+}
+
+// RefreshRepoSizes recomputes the stale cached sizes of all repositories,
+// and reports them in the metrics. Repositories are walked one at a time.
+func (d *Backend) RefreshRepoSizes(ctx context.Context) error {
+	repos, err := d.Repositories(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Forget the sizes of the deleted and renamed repositories.
+	repoSizeGauge.Reset()
+	for _, r := range repos {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if _, err := d.RepoSize(ctx, r.Name()); err != nil {
+			d.logger.Error("error computing repository size", "repo", r.Name(), "err", err)
+		}
+	}
+
+	return nil
+}
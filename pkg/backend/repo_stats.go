@@ -0,0 +1,254 @@
+package backend
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/soft-serve/git"
+)
+
+// repoStatsLargestFiles is the number of largest files reported in the
+// repository statistics.
+const repoStatsLargestFiles = 5
+
+// RepoStats are the statistics of a repository.
+type RepoStats struct {
+	// Commits is the number of commits on the default branch.
+	Commits int `json:"commits"`
+	// Contributors is the number of distinct commit authors on the default
+	// branch.
+	Contributors int `json:"contributors"`
+	// Branches is the number of branches.
+	Branches int `json:"branches"`
+	// Tags is the number of tags.
+	Tags int `json:"tags"`
+	// Size is the size of the repository on disk in bytes.
+	Size int64 `json:"size"`
+	// LargestFiles are the largest files on the default branch, largest
+	// first.
+	LargestFiles []RepoFileSize `json:"largest_files"`
+	// FirstCommit is the date of the oldest commit on the default branch.
+	FirstCommit *time.Time `json:"first_commit,omitempty"`
+	// LastCommit is the date of the newest commit on the default branch.
+	LastCommit *time.Time `json:"last_commit,omitempty"`
+	// CommitGraph is the commit-graph state of the repository, one of
+	// "missing", "stale", or "up-to-date".
+	CommitGraph string `json:"commit_graph"`
+}
+
+// RepoFileSize is the size of a file in a repository.
+type RepoFileSize struct {
+	Path string `json:"path"`
+	Size int64  `json:"size"`
+}
+
+// repoHistoryStats are the statistics computed by walking the default
+// branch. They only change when the default branch does.
+type repoHistoryStats struct {
+	commits      int
+	contributors int
+	largestFiles []RepoFileSize
+	firstCommit  *time.Time
+	lastCommit   *time.Time
+}
+
+// repoStatsCache caches the history statistics of each repository keyed on
+// the default branch commit.
+type repoStatsCache struct {
+	mu      sync.Mutex
+	entries map[string]repoStatsEntry
+}
+
+// repoStatsEntry is a cached repoHistoryStats.
+type repoStatsEntry struct {
+	head  string
+	stats repoHistoryStats
+}
+
+func (c *repoStatsCache) get(repo string, head string) (repoHistoryStats, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[repo]
+	if !ok || e.head != head {
+		return repoHistoryStats{}, false
+	}
+
+	return e.stats, true
+}
+
+func (c *repoStatsCache) set(repo string, head string, stats repoHistoryStats) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries == nil {
+		c.entries = map[string]repoStatsEntry{}
+	}
+	c.entries[repo] = repoStatsEntry{head: head, stats: stats}
+}
+
+// RepoStats returns the statistics of a repository. Empty repositories have
+// zero statistics.
+//
+// Walking the default branch is expensive on large repositories, so the
+// history statistics are cached until the default branch changes, and the
+// size is the cached one.
+func (d *Backend) RepoStats(ctx context.Context, repo string) (RepoStats, error) {
+	stats := RepoStats{LargestFiles: []RepoFileSize{}}
+	r, err := d.Repository(ctx, repo)
+	if err != nil {
+		return stats, err
+	}
+
+	rr, err := r.Open()
+	if err != nil {
+		return stats, err
+	}
+
+	stats.Size, err = d.RepoSize(ctx, r.Name())
+	if err != nil {
+		return stats, err
+	}
+
+	// Unlike show-ref, for-each-ref doesn't fail on empty repositories.
+	refs, err := git.NewCommand("for-each-ref", "--format=%(refname)", "refs/heads", "refs/tags").WithContext(ctx).RunInDir(rr.Path)
+	if err != nil {
+		return stats, err
+	}
+
+	stats.CommitGraph, _, err = commitGraphState(ctx, rr.Path)
+	if err != nil {
+		return stats, err
+	}
+
+	for _, ref := range strings.Fields(string(refs)) {
+		switch {
+		case strings.HasPrefix(ref, git.RefsHeads):
+			stats.Branches++
+		case strings.HasPrefix(ref, git.RefsTags):
+			stats.Tags++
+		}
+	}
+
+	head, err := rr.HEAD()
+	if err != nil {
+		// The default branch doesn't exist yet.
+		return stats, nil
+	}
+
+	hs, ok := d.repoStats.get(r.Name(), head.ID)
+	if !ok {
+		hs, err = repoHistory(ctx, rr.Path, head.ID)
+		if err != nil {
+			return stats, err
+		}
+
+		d.repoStats.set(r.Name(), head.ID, hs)
+	}
+
+	stats.Commits = hs.commits
+	stats.Contributors = hs.contributors
+	stats.LargestFiles = hs.largestFiles
+	stats.FirstCommit = hs.firstCommit
+	stats.LastCommit = hs.lastCommit
+
+	return stats, nil
+}
+
+// repoHistory computes the history statistics of a commit.
+func repoHistory(ctx context.Context, path string, rev string) (repoHistoryStats, error) {
+	var hs repoHistoryStats
+	out, err := git.NewCommand("log", "--format=%ct%x09%aE", rev).WithContext(ctx).RunInDir(path)
+	if err != nil {
+		return hs, err
+	}
+
+	authors := map[string]struct{}{}
+	var first, last int64
+	s := bufio.NewScanner(bytes.NewReader(out))
+	for s.Scan() {
+		ts, email, _ := strings.Cut(s.Text(), "\t")
+		t, err := strconv.ParseInt(ts, 10, 64)
+		if err != nil {
+			continue
+		}
+
+		hs.commits++
+		authors[strings.ToLower(email)] = struct{}{}
+		if first == 0 || t < first {
+			first = t
+		}
+		if t > last {
+			last = t
+		}
+	}
+	hs.contributors = len(authors)
+	if hs.commits > 0 {
+		f, l := time.Unix(first, 0).UTC(), time.Unix(last, 0).UTC()
+		hs.firstCommit, hs.lastCommit = &f, &l
+	}
+
+	out, err = git.NewCommand("ls-tree", "-r", "-l", "-z", rev).WithContext(ctx).RunInDir(path)
+	if err != nil {
+		return hs, err
+	}
+
+	files := make([]RepoFileSize, 0)
+	for _, entry := range bytes.Split(out, []byte{0}) {
+		// <mode> SP <type> SP <object> SP <size> TAB <path>
+		meta, name, ok := strings.Cut(string(entry), "\t")
+		if !ok {
+			continue
+		}
+
+		fields := strings.Fields(meta)
+		if len(fields) != 4 || fields[1] != "blob" {
+			continue
+		}
+
+		size, err := strconv.ParseInt(fields[3], 10, 64)
+		if err != nil {
+			continue
+		}
+
+		files = append(files, RepoFileSize{Path: name, Size: size})
+	}
+
+	sort.SliceStable(files, func(i, j int) bool {
+		return files[i].Size > files[j].Size
+	})
+	if len(files) > repoStatsLargestFiles {
+		files = files[:repoStatsLargestFiles]
+	}
+	hs.largestFiles = files
+
+	return hs, nil
+}
+
+// dirSize returns the total size of the files in a directory.
+func dirSize(path string) (int64, error) {
+	var size int64
+	err := filepath.WalkDir(path, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.Type().IsRegular() {
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			size += info.Size()
+		}
+
+		return nil
+	})
+
+	return size, err
+}
@@ -0,0 +1,80 @@
+package backend
+
+import (
+	"context"
+	"time"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/tracing"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var (
+	operationSeconds = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "soft_serve",
+		Subsystem: "backend",
+		Name:      "operation_duration_seconds",
+		Help:      "The time spent on backend operations in the authentication and access check path, including retries",
+		Buckets:   []float64{.001, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5},
+	}, []string{"operation"})
+
+	operationRetryCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "soft_serve",
+		Subsystem: "backend",
+		Name:      "operation_retries_total",
+		Help:      "The total number of backend operations retried after a transient database error",
+	}, []string{"operation"})
+)
+
+// retryBackoff is the initial wait before retrying an operation. It doubles
+// after each attempt.
+const retryBackoff = 25 * time.Millisecond
+
+// retryTx runs a read-only database transaction for an operation in the hot
+// path, such as authentication or access checks. Transient database errors
+// are retried with exponential backoff. All attempts share the configured
+// retry deadline so a slow database can't hang an SSH handshake. fn may run
+// more than once.
+func (d *Backend) retryTx(ctx context.Context, op string, fn func(tx *db.Tx) error) (err error) {
+	start := time.Now()
+	ctx, span := tracing.Start(ctx, "backend."+op)
+	defer func() {
+		operationSeconds.WithLabelValues(op).Observe(time.Since(start).Seconds())
+		tracing.End(span, err)
+	}()
+
+	var retries int
+	if d.cfg != nil {
+		retries = d.cfg.DB.Retries
+		if timeout := d.cfg.DB.RetryTimeout; timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, time.Duration(timeout)*time.Millisecond)
+			defer cancel()
+		}
+	}
+
+	backoff := retryBackoff
+	for attempt := 0; ; attempt++ {
+		err := d.db.TransactionContext(ctx, fn)
+		if err == nil || attempt >= retries || !db.IsTransient(err) {
+			return err
+		}
+
+		operationRetryCounter.WithLabelValues(op).Inc()
+		span.AddEvent("retry", trace.WithAttributes(attribute.Int("attempt", attempt+1)))
+		d.logger.Warn("retrying backend operation", "operation", op, "attempt", attempt+1, "err", err)
+
+		t := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return err
+		case <-t.C:
+		}
+
+		backoff *= 2
+	}
+}
@@ -0,0 +1,84 @@
+package backend
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/charmbracelet/soft-serve/pkg/config"
+	"github.com/charmbracelet/soft-serve/pkg/db"
+)
+
+func newRetryBackend(t *testing.T, retries, timeout int) *Backend {
+	t.Helper()
+	dbx, err := db.Open(context.Background(), "sqlite", filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { dbx.Close() }) // nolint: errcheck
+
+	cfg := config.DefaultConfig()
+	cfg.DB.Retries = retries
+	cfg.DB.RetryTimeout = timeout
+	return &Backend{
+		cfg:    cfg,
+		db:     dbx,
+		logger: log.New(io.Discard),
+	}
+}
+
+func TestRetryTx(t *testing.T) {
+	d := newRetryBackend(t, 3, 2000)
+
+	var attempts int
+	err := d.retryTx(context.Background(), "test", func(*db.Tx) error {
+		attempts++
+		if attempts < 3 {
+			return driver.ErrBadConn
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("expected success after retries, got %v", err)
+	}
+	if attempts != 3 {
+		t.Errorf("expected 3 attempts, got %d", attempts)
+	}
+}
+
+func TestRetryTxNotTransient(t *testing.T) {
+	d := newRetryBackend(t, 3, 2000)
+
+	var attempts int
+	want := errors.New("not transient")
+	err := d.retryTx(context.Background(), "test", func(*db.Tx) error {
+		attempts++
+		return want
+	})
+	if !errors.Is(err, want) {
+		t.Fatalf("expected %v, got %v", want, err)
+	}
+	if attempts != 1 {
+		t.Errorf("expected 1 attempt, got %d", attempts)
+	}
+}
+
+func TestRetryTxDeadline(t *testing.T) {
+	d := newRetryBackend(t, 100, 100)
+
+	start := time.Now()
+	err := d.retryTx(context.Background(), "test", func(*db.Tx) error {
+		return driver.ErrBadConn
+	})
+	if !errors.Is(err, driver.ErrBadConn) {
+		t.Fatalf("expected %v, got %v", driver.ErrBadConn, err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected retries to stop at the deadline, took %s", elapsed)
+	}
+}
@@ -0,0 +1,217 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/soft-serve/git"
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/db/models"
+	"github.com/charmbracelet/soft-serve/pkg/hooks"
+)
+
+// reviewsRefPrefix is the prefix of the refs changes proposed for review are
+// kept under, refs/reviews/<number>.
+const reviewsRefPrefix = "refs/reviews/"
+
+// ErrInvalidReview is returned when a push to a review ref can't be proposed
+// for review.
+var ErrInvalidReview = errors.New("invalid review")
+
+// Review is a change proposed to a branch for review.
+type Review struct {
+	// Number identifies the review in its repository, starting at 1.
+	Number int64 `json:"number"`
+	// TargetBranch is the branch the change is proposed to.
+	TargetBranch string `json:"target_branch"`
+	// BaseSHA is the commit of the target branch when the change was
+	// pushed.
+	BaseSHA string `json:"base_sha"`
+	// CommitSHA is the commit of the change.
+	CommitSHA string `json:"commit_sha"`
+	// Ref is the ref the change is kept under.
+	Ref string `json:"ref"`
+	// Username is the user that pushed the change, empty if the user was
+	// deleted.
+	Username string `json:"username"`
+	// CreatedAt is when the change was pushed.
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ReviewRef returns the ref a review is kept under.
+func ReviewRef(number int64) string {
+	return reviewsRefPrefix + strconv.FormatInt(number, 10)
+}
+
+// reviewBranch returns the branch a push to a review ref proposes a change
+// to, and whether the ref is a review ref at all.
+func (d *Backend) reviewBranch(ref string) (string, bool) {
+	prefix := d.cfg.Review.RefPrefix
+	if prefix == "" || !strings.HasPrefix(ref, prefix) {
+		return "", false
+	}
+
+	return strings.TrimPrefix(ref, prefix), true
+}
+
+// splitReviewArgs splits the ref updates of a push into the proposed changes
+// and the other updates.
+func (d *Backend) splitReviewArgs(args []hooks.HookArg) (reviews []hooks.HookArg, others []hooks.HookArg) {
+	for _, arg := range args {
+		if _, ok := d.reviewBranch(arg.RefName); ok {
+			reviews = append(reviews, arg)
+		} else {
+			others = append(others, arg)
+		}
+	}
+
+	return reviews, others
+}
+
+// checkReviewRefs returns an error if the push updates the review refs in a
+// way that can't be proposed for review: deleting them, or targeting a
+// branch that doesn't exist. It also keeps pushes from updating the refs of
+// the proposed changes, they're managed by the server. It's meant to be
+// called from the pre-receive hook.
+func (d *Backend) checkReviewRefs(ctx context.Context, repo string, args []hooks.HookArg) error {
+	if d.cfg.Review.RefPrefix == "" {
+		return nil
+	}
+
+	reviews, others := d.splitReviewArgs(args)
+	for _, arg := range others {
+		if strings.HasPrefix(arg.RefName, reviewsRefPrefix) {
+			return fmt.Errorf("%w: %s is managed by the server, push to %s<branch> to propose a change", ErrInvalidReview, arg.RefName, d.cfg.Review.RefPrefix)
+		}
+	}
+
+	if len(reviews) == 0 {
+		return nil
+	}
+
+	r, err := d.Repository(ctx, repo)
+	if err != nil {
+		return err
+	}
+
+	rr, err := r.Open()
+	if err != nil {
+		return err
+	}
+
+	for _, arg := range reviews {
+		branch, _ := d.reviewBranch(arg.RefName)
+		if git.IsZeroHash(arg.NewSha) {
+			return fmt.Errorf("%w: %s can't be deleted", ErrInvalidReview, arg.RefName)
+		}
+		if _, err := git.NewCommand("rev-parse", "--verify", "--quiet", git.RefsHeads+branch).WithContext(ctx).RunInDir(rr.Path); err != nil {
+			return fmt.Errorf("%w: branch %q doesn't exist", ErrInvalidReview, branch)
+		}
+	}
+
+	return nil
+}
+
+// captureReviews records the changes pushed to the review refs for review.
+// Each change is moved from the review ref to refs/reviews/<number>, so the
+// next push to the same branch proposes a new change. It's meant to be called
+// from the post-receive hook, and returns the other ref updates of the push.
+func (d *Backend) captureReviews(ctx context.Context, stderr io.Writer, repo string, args []hooks.HookArg) []hooks.HookArg {
+	reviews, others := d.splitReviewArgs(args)
+	if len(reviews) == 0 {
+		return others
+	}
+
+	r, err := d.Repository(ctx, repo)
+	if err != nil {
+		d.logger.Error("error finding repository", "repo", repo, "err", err)
+		return others
+	}
+
+	rr, err := r.Open()
+	if err != nil {
+		d.logger.Error("error opening repository", "repo", repo, "err", err)
+		return others
+	}
+
+	user, _ := d.hookUser(ctx)
+	var userID int64
+	if user != nil {
+		userID = user.ID()
+	}
+
+	for _, arg := range reviews {
+		branch, _ := d.reviewBranch(arg.RefName)
+		var base string
+		if out, err := git.NewCommand("rev-parse", "--verify", "--quiet", git.RefsHeads+branch).WithContext(ctx).RunInDir(rr.Path); err == nil {
+			base = strings.TrimSpace(string(out))
+		}
+
+		var number int64
+		if err := db.WrapError(d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+			var err error
+			number, err = d.store.CreateReview(ctx, tx, r.ID(), userID, branch, base, arg.NewSha)
+			return err
+		})); err != nil {
+			d.logger.Error("error recording review", "repo", repo, "branch", branch, "err", err)
+			continue
+		}
+
+		ref := ReviewRef(number)
+		if _, err := git.NewCommand("update-ref", ref, arg.NewSha, "").WithContext(ctx).RunInDir(rr.Path); err != nil {
+			d.logger.Error("error creating review ref", "repo", repo, "ref", ref, "err", err)
+			continue
+		}
+		if _, err := git.NewCommand("update-ref", "-d", arg.RefName, arg.NewSha).WithContext(ctx).RunInDir(rr.Path); err != nil {
+			d.logger.Error("error deleting review ref", "repo", repo, "ref", arg.RefName, "err", err)
+		}
+
+		d.logger.Info("change proposed for review", "repo", repo, "number", number, "branch", branch, "commit", arg.NewSha)
+		details := fmt.Sprintf("%d %s %s", number, branch, arg.NewSha)
+		if err := d.recordAuditEvent(ctx, AuditActionReviewProposed, r, user, details); err != nil {
+			d.logger.Error("error recording review", "repo", repo, "err", err)
+		}
+
+		fmt.Fprintf(stderr, "Review #%d proposed for %s, fetch it with: git fetch origin %s\n", number, branch, ref) // nolint: errcheck
+	}
+
+	return others
+}
+
+// Reviews returns the changes proposed for review in a repository, newest
+// first.
+func (d *Backend) Reviews(ctx context.Context, repo string) ([]Review, error) {
+	r, err := d.Repository(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	var ms []models.Review
+	if err := d.retryTx(ctx, "reviews", func(tx *db.Tx) error {
+		var err error
+		ms, err = d.store.GetReviews(ctx, tx, r.ID())
+		return err
+	}); err != nil {
+		return nil, db.WrapError(err)
+	}
+
+	reviews := make([]Review, 0, len(ms))
+	for _, m := range ms {
+		reviews = append(reviews, Review{
+			Number:       m.Number,
+			TargetBranch: m.TargetBranch,
+			BaseSHA:      m.BaseSHA,
+			CommitSHA:    m.CommitSHA,
+			Ref:          ReviewRef(m.Number),
+			Username:     m.Username.String,
+			CreatedAt:    m.CreatedAt,
+		})
+	}
+
+	return reviews, nil
+}
@@ -12,7 +12,7 @@ import (
 // It implements backend.Backend.
 func (b *Backend) AllowKeyless(ctx context.Context) bool {
 	var allow bool
-	if err := b.db.TransactionContext(ctx, func(tx *db.Tx) error {
+	if err := b.retryTx(ctx, "allow_keyless", func(tx *db.Tx) error {
 		var err error
 		allow, err = b.store.GetAllowKeylessAccess(ctx, tx)
 		return err
@@ -36,16 +36,25 @@ func (b *Backend) SetAllowKeyless(ctx context.Context, allow bool) error {
 //
 // It implements backend.Backend.
 func (b *Backend) AnonAccess(ctx context.Context) access.AccessLevel {
+	level, err := b.anonAccess(ctx)
+	if err != nil {
+		return access.NoAccess
+	}
+
+	return level
+}
+
+func (b *Backend) anonAccess(ctx context.Context) (access.AccessLevel, error) {
 	var level access.AccessLevel
-	if err := b.db.TransactionContext(ctx, func(tx *db.Tx) error {
+	if err := b.retryTx(ctx, "anon_access", func(tx *db.Tx) error {
 		var err error
 		level, err = b.store.GetAnonAccess(ctx, tx)
 		return err
 	}); err != nil {
-		return access.NoAccess
+		return -1, err
 	}
 
-	return level
+	return level, nil
 }
 
 // SetAnonAccess sets the level of anonymous access.
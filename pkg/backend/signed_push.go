@@ -0,0 +1,115 @@
+package backend
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/soft-serve/git"
+	"github.com/charmbracelet/soft-serve/pkg/hooks"
+	"github.com/charmbracelet/soft-serve/pkg/proto"
+	"github.com/charmbracelet/soft-serve/pkg/sshutils"
+)
+
+// settingSignedPushBranches is the repository setting key for the
+// comma-separated branch name patterns that require signed pushes.
+const settingSignedPushBranches = "signed_push_branches"
+
+// pushCertNamespace is the SSH signature namespace git uses for push
+// certificates.
+const pushCertNamespace = "git"
+
+// sshSignatureHeader is the first line of an armored SSH signature.
+const sshSignatureHeader = "-----BEGIN SSH SIGNATURE-----"
+
+// SignedPushBranches returns the branch name patterns of a repository that
+// require signed pushes. Patterns use the same syntax as path.Match.
+func (d *Backend) SignedPushBranches(ctx context.Context, repo string) ([]string, error) {
+	return d.branchPatterns(ctx, repo, settingSignedPushBranches)
+}
+
+// SetSignedPushBranches sets the branch name patterns of a repository that
+// require signed pushes. No patterns means signed pushes are optional.
+func (d *Backend) SetSignedPushBranches(ctx context.Context, repo string, patterns []string) error {
+	return d.setBranchPatterns(ctx, repo, settingSignedPushBranches, patterns)
+}
+
+// checkPushCert verifies the push certificate sent with `git push --signed`
+// and records it in the audit log. It returns an error if the certificate is
+// invalid, or if it's missing and the push updates a branch that requires
+// signed pushes. It's meant to be called from the pre-receive hook.
+func (d *Backend) checkPushCert(ctx context.Context, repo string, args []hooks.HookArg) error {
+	cert, ok := hooks.PushCertificate()
+	if !ok {
+		patterns, err := d.SignedPushBranches(ctx, repo)
+		if err != nil {
+			return err
+		}
+
+		for _, arg := range args {
+			if branch, ok := matchBranch(patterns, arg.RefName); ok {
+				return fmt.Errorf("pushes to %s must be signed, use `git push --signed`", branch)
+			}
+		}
+
+		return nil
+	}
+
+	r, err := d.Repository(ctx, repo)
+	if err != nil {
+		return err
+	}
+
+	signer, payload, err := d.verifyPushCert(ctx, r, cert)
+	if err != nil {
+		return fmt.Errorf("invalid push certificate: %w", err)
+	}
+
+	d.logger.Info("verified push certificate", "repo", repo, "user", signer.Username())
+	return d.recordAuditEvent(ctx, AuditActionPushCertificate, r, signer, payload)
+}
+
+// verifyPushCert verifies a push certificate signature and returns the
+// registered user who signed it along with the certificate.
+func (d *Backend) verifyPushCert(ctx context.Context, r proto.Repository, cert hooks.PushCert) (proto.User, string, error) {
+	switch cert.NonceStatus {
+	case hooks.NonceStatusOK, hooks.NonceStatusSlop:
+	default:
+		return nil, "", fmt.Errorf("bad nonce (%s)", strings.ToLower(cert.NonceStatus))
+	}
+
+	rr, err := r.Open()
+	if err != nil {
+		return nil, "", err
+	}
+
+	// The hook runs with the quarantine object directory in the environment so
+	// the certificate blob is visible to git.
+	data, err := git.NewCommand("cat-file", "blob", cert.ID).WithContext(ctx).RunInDir(rr.Path)
+	if err != nil {
+		return nil, "", err
+	}
+
+	i := bytes.Index(data, []byte(sshSignatureHeader))
+	if i < 0 {
+		return nil, "", errors.New("only SSH signed push certificates are supported, set gpg.format to ssh")
+	}
+
+	pk, err := sshutils.VerifySignature(data[:i], data[i:], pushCertNamespace)
+	if err != nil {
+		return nil, "", err
+	}
+
+	signer, err := d.UserByPublicKey(ctx, pk)
+	if err != nil {
+		return nil, "", errors.New("signing key is not registered")
+	}
+
+	if pusher, _ := d.hookUser(ctx); pusher != nil && pusher.ID() != signer.ID() {
+		return nil, "", fmt.Errorf("signed by %s instead of %s", signer.Username(), pusher.Username())
+	}
+
+	return signer, string(data), nil
+}
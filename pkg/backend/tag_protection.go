@@ -0,0 +1,192 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/soft-serve/git"
+	"github.com/charmbracelet/soft-serve/pkg/access"
+	"github.com/charmbracelet/soft-serve/pkg/hooks"
+	"github.com/charmbracelet/soft-serve/pkg/proto"
+)
+
+// Repository setting keys for tag protection.
+const (
+	settingProtectedTags         = "protected_tags"
+	settingProtectedTagsWarnOnly = "protected_tags_warn_only"
+)
+
+// Outcomes of an attempt to move or delete a protected tag, as recorded in
+// the audit log.
+const (
+	// ProtectedTagRejected is an attempt rejected by the rule.
+	ProtectedTagRejected = "rejected"
+	// ProtectedTagWarned is an attempt allowed with a warning by a warn-only
+	// rule.
+	ProtectedTagWarned = "warned"
+	// ProtectedTagAdmin is an attempt allowed because it was made by an admin.
+	ProtectedTagAdmin = "admin"
+)
+
+// TagProtection is the tag protection rule of a repository. Protected tags
+// can be created, but not moved or deleted, except by admins.
+type TagProtection struct {
+	// Tags are the tag name patterns that are protected. Patterns use the
+	// same syntax as path.Match.
+	Tags []string
+	// WarnOnly reports changes to protected tags without rejecting the push.
+	WarnOnly bool
+}
+
+// TagProtection returns the tag protection rule of a repository.
+func (d *Backend) TagProtection(ctx context.Context, repo string) (TagProtection, error) {
+	var t TagProtection
+	settings, err := d.RepoSettings(ctx, repo)
+	if err != nil {
+		return t, err
+	}
+
+	if v := settings[settingProtectedTags]; v != "" {
+		t.Tags = strings.Split(v, ",")
+	}
+
+	t.WarnOnly, _ = strconv.ParseBool(settings[settingProtectedTagsWarnOnly])
+	return t, nil
+}
+
+// SetTagProtection sets the tag protection rule of a repository. No tags
+// disables the rule.
+func (d *Backend) SetTagProtection(ctx context.Context, repo string, t TagProtection) error {
+	for _, p := range t.Tags {
+		if p == "" || strings.Contains(p, ",") {
+			return fmt.Errorf("invalid tag pattern %q", p)
+		}
+		if _, err := path.Match(p, ""); err != nil {
+			return fmt.Errorf("invalid tag pattern %q: %w", p, err)
+		}
+	}
+
+	var warn string
+	if t.WarnOnly {
+		warn = "true"
+	}
+
+	return d.SetRepoSettings(ctx, repo, map[string]string{
+		settingProtectedTags:         strings.Join(t.Tags, ","),
+		settingProtectedTagsWarnOnly: warn,
+	})
+}
+
+// checkTagProtection returns an error if the push moves or deletes a
+// protected tag. Admins are allowed to. Every attempt is recorded in the
+// audit log. It's meant to be called from the pre-receive hook.
+func (d *Backend) checkTagProtection(ctx context.Context, stderr io.Writer, repo string, args []hooks.HookArg) error {
+	t, err := d.TagProtection(ctx, repo)
+	if err != nil || len(t.Tags) == 0 {
+		return err
+	}
+
+	// Anonymous pushers are recorded without a user.
+	user, err := d.hookUser(ctx)
+	if err != nil {
+		user = nil
+	}
+
+	admin := d.hookAccessLevel(ctx, repo) >= access.AdminAccess
+	for _, arg := range args {
+		if err := d.protectTag(ctx, stderr, t, repo, arg, user, admin); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// CheckTagDeletion returns an error if the tag is protected and the user of
+// the context isn't an admin of the repository. It's meant to be called
+// before deleting a tag outside of a push.
+func (d *Backend) CheckTagDeletion(ctx context.Context, stderr io.Writer, repo string, tag string, commit string) error {
+	t, err := d.TagProtection(ctx, repo)
+	if err != nil || len(t.Tags) == 0 {
+		return err
+	}
+
+	user := proto.UserFromContext(ctx)
+	admin := d.AccessLevelForUser(ctx, repo, user) >= access.AdminAccess
+	return d.protectTag(ctx, stderr, t, repo, hooks.HookArg{
+		OldSha:  commit,
+		NewSha:  git.ZeroIDOf(commit),
+		RefName: git.RefsTags + tag,
+	}, user, admin)
+}
+
+// protectTag applies the tag protection rule to a ref update by the user.
+func (d *Backend) protectTag(ctx context.Context, stderr io.Writer, t TagProtection, repo string, arg hooks.HookArg, user proto.User, admin bool) error {
+	tag, ok := matchTag(t.Tags, arg.RefName)
+	if !ok || git.IsZeroHash(arg.OldSha) {
+		return nil
+	}
+
+	change := "moved"
+	if git.IsZeroHash(arg.NewSha) {
+		change = "deleted"
+	}
+
+	outcome := ProtectedTagRejected
+	switch {
+	case admin:
+		outcome = ProtectedTagAdmin
+	case t.WarnOnly:
+		outcome = ProtectedTagWarned
+	}
+
+	if err := d.recordProtectedTagAttempt(ctx, repo, arg, user, outcome); err != nil {
+		d.logger.Error("error recording protected tag attempt", "repo", repo, "tag", tag, "err", err)
+	}
+
+	msg := fmt.Sprintf("tag %s is protected and cannot be %s", tag, change)
+	switch outcome {
+	case ProtectedTagAdmin:
+		fmt.Fprintf(stderr, "warning: %s (allowed for admin)\n", msg) // nolint: errcheck
+	case ProtectedTagWarned:
+		warnPolicy(ctx, stderr, policyTagProtection, msg)
+	default:
+		return fmt.Errorf("%s, create a new tag instead", msg)
+	}
+
+	return nil
+}
+
+// recordProtectedTagAttempt records an attempt to move or delete a protected
+// tag in the audit log. The details are the tag ref, its old and new commits,
+// and the outcome.
+func (d *Backend) recordProtectedTagAttempt(ctx context.Context, repo string, arg hooks.HookArg, user proto.User, outcome string) error {
+	r, err := d.Repository(ctx, repo)
+	if err != nil {
+		return err
+	}
+
+	return d.recordAuditEvent(ctx, AuditActionProtectedTag, r, user,
+		fmt.Sprintf("%s %s %s %s", arg.RefName, arg.OldSha, arg.NewSha, outcome))
+}
+
+// matchTag returns the tag name of ref and whether it matches one of the
+// patterns. Refs outside of refs/tags never match.
+func matchTag(patterns []string, ref string) (string, bool) {
+	if !strings.HasPrefix(ref, git.RefsTags) {
+		return "", false
+	}
+
+	tag := strings.TrimPrefix(ref, git.RefsTags)
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, tag); ok {
+			return tag, true
+		}
+	}
+
+	return tag, false
+}
@@ -0,0 +1,176 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/db/models"
+	"github.com/charmbracelet/soft-serve/pkg/proto"
+)
+
+// ErrTermsNotCurrent is returned when accepting a version of the terms other
+// than the current one.
+var ErrTermsNotCurrent = errors.New("not the current version of the terms")
+
+// Terms are the server terms, like a contributor license agreement, users
+// must accept before pushing.
+type Terms struct {
+	// Version is the version of the terms. Empty means there are no terms.
+	Version string
+	// Text is the text of the terms.
+	Text string
+}
+
+// Terms returns the current terms.
+func (d *Backend) Terms(ctx context.Context) (Terms, error) {
+	var t Terms
+	if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		var err error
+		t.Version, t.Text, err = d.store.GetTerms(ctx, tx)
+		return err
+	}); err != nil {
+		return Terms{}, db.WrapError(err)
+	}
+
+	return t, nil
+}
+
+// SetTerms sets the current terms. Users have to accept each new version
+// before pushing again. Terms without a version remove the requirement.
+func (d *Backend) SetTerms(ctx context.Context, t Terms) error {
+	if err := d.checkWritable(); err != nil {
+		return err
+	}
+
+	t.Version = strings.TrimSpace(t.Version)
+	if t.Version != "" && strings.TrimSpace(t.Text) == "" {
+		return fmt.Errorf("terms version %q has no text", t.Version)
+	}
+	if t.Version == "" {
+		t.Text = ""
+	}
+
+	if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		return d.store.SetTerms(ctx, tx, t.Version, t.Text)
+	}); err != nil {
+		return db.WrapError(err)
+	}
+
+	return d.recordAuditEvent(ctx, AuditActionTermsSet, nil, nil, t.Version)
+}
+
+// AcceptTerms records that the user accepted the given version of the terms,
+// which must be the current one.
+func (d *Backend) AcceptTerms(ctx context.Context, user proto.User, version string) error {
+	if err := d.checkWritable(); err != nil {
+		return err
+	}
+
+	if user == nil {
+		return proto.ErrUserNotFound
+	}
+
+	t, err := d.Terms(ctx)
+	if err != nil {
+		return err
+	}
+
+	if t.Version == "" || t.Version != version {
+		return fmt.Errorf("%q: %w", version, ErrTermsNotCurrent)
+	}
+
+	return db.WrapError(d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		return d.store.CreateTermsAcceptance(ctx, tx, user.ID(), version)
+	}))
+}
+
+// TermsAcceptedAt returns when the user accepted the given version of the
+// terms. The time is zero if the user didn't accept them.
+func (d *Backend) TermsAcceptedAt(ctx context.Context, user proto.User, version string) (time.Time, error) {
+	if user == nil {
+		return time.Time{}, nil
+	}
+
+	var a models.TermsAcceptance
+	if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		var err error
+		a, err = d.store.GetTermsAcceptance(ctx, tx, user.ID(), version)
+		return err
+	}); err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return time.Time{}, nil
+		}
+		return time.Time{}, db.WrapError(err)
+	}
+
+	return a.CreatedAt, nil
+}
+
+// TermsAcceptances returns the acceptances of the given version of the terms,
+// oldest first.
+func (d *Backend) TermsAcceptances(ctx context.Context, version string) ([]models.TermsAcceptance, error) {
+	var as []models.TermsAcceptance
+	if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+		var err error
+		as, err = d.store.GetTermsAcceptances(ctx, tx, version)
+		return err
+	}); err != nil {
+		return nil, db.WrapError(err)
+	}
+
+	return as, nil
+}
+
+// checkTerms rejects pushes by users who didn't accept the current terms.
+// Admins are exempt. It's meant to be called from the pre-receive hook.
+func (d *Backend) checkTerms(ctx context.Context) error {
+	t, err := d.Terms(ctx)
+	if err != nil || t.Version == "" {
+		return err
+	}
+
+	user, err := d.hookUser(ctx)
+	if err != nil && !errors.Is(err, proto.ErrUserNotFound) {
+		return err
+	}
+	if user != nil && user.IsAdmin() {
+		return nil
+	}
+
+	ssh := d.sshCommand()
+	if user == nil {
+		return fmt.Errorf("pushing requires accepting the terms (version %s), sign in with a registered SSH key and run `%s terms` to read them", t.Version, ssh)
+	}
+
+	at, err := d.TermsAcceptedAt(ctx, user, t.Version)
+	if err != nil || !at.IsZero() {
+		return err
+	}
+
+	return fmt.Errorf("you must accept the terms (version %s) before pushing, read them with `%s terms` and accept them with `%s terms accept %s`", t.Version, ssh, ssh, t.Version)
+}
+
+// sshCommand returns the ssh command connecting to the server, derived from
+// its public SSH URL.
+func (d *Backend) sshCommand() string {
+	u, err := url.Parse(d.cfg.SSH.PublicURL)
+	if err != nil || u.Host == "" {
+		return "ssh"
+	}
+
+	host, port, err := net.SplitHostPort(u.Host)
+	if err != nil {
+		return "ssh " + u.Host
+	}
+	if port == "22" {
+		return "ssh " + host
+	}
+
+	return fmt.Sprintf("ssh -p %s %s", port, host)
+}
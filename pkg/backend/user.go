@@ -11,97 +11,241 @@ import (
 	"github.com/charmbracelet/soft-serve/pkg/db/models"
 	"github.com/charmbracelet/soft-serve/pkg/proto"
 	"github.com/charmbracelet/soft-serve/pkg/sshutils"
+	"github.com/charmbracelet/soft-serve/pkg/tracing"
 	"github.com/charmbracelet/soft-serve/pkg/utils"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/crypto/ssh"
 )
 
-// AccessLevel returns the access level of a user for a repository.
+// AccessLevel returns the access level of a user for a repository, limited to
+// the maximum access level of the context.
 //
 // It implements backend.Backend.
 func (d *Backend) AccessLevel(ctx context.Context, repo string, username string) access.AccessLevel {
-	user, _ := d.User(ctx, username)
-	return d.AccessLevelForUser(ctx, repo, user)
+	level, err := d.accessLevel(ctx, repo, username)
+	if err != nil {
+		return access.Limit(ctx, d.accessLevelOnError(repo, err))
+	}
+
+	return access.Limit(ctx, level)
+}
+
+func (d *Backend) accessLevel(ctx context.Context, repo string, username string) (access.AccessLevel, error) {
+	// Invalid usernames, such as the empty username of anonymous users, don't
+	// belong to any user.
+	var user proto.User
+	if utils.ValidateUsername(strings.ToLower(username)) == nil {
+		var err error
+		user, err = d.User(ctx, username)
+		if err != nil && !errors.Is(err, proto.ErrUserNotFound) {
+			return -1, err
+		}
+	}
+
+	return d.accessLevelForUser(ctx, repo, user)
 }
 
 // AccessLevelByPublicKey returns the access level of a user's public key for a repository.
 //
 // It implements backend.Backend.
 func (d *Backend) AccessLevelByPublicKey(ctx context.Context, repo string, pk ssh.PublicKey) access.AccessLevel {
+	level, err := d.accessLevelByPublicKey(ctx, repo, pk)
+	if err != nil {
+		return access.Limit(ctx, d.accessLevelOnError(repo, err))
+	}
+
+	return access.Limit(ctx, level)
+}
+
+func (d *Backend) accessLevelByPublicKey(ctx context.Context, repo string, pk ssh.PublicKey) (access.AccessLevel, error) {
+	// Revoked keys have no access, even admin keys.
+	revoked, err := d.isKeyRevoked(ctx, pk)
+	if err != nil {
+		return -1, err
+	}
+	if revoked {
+		return access.NoAccess, nil
+	}
+
 	for _, k := range d.cfg.AdminKeys() {
 		if sshutils.KeysEqual(pk, k) {
-			return access.AdminAccess
+			return access.AdminAccess, nil
 		}
 	}
 
-	user, _ := d.UserByPublicKey(ctx, pk)
-	if user != nil {
-		return d.AccessLevel(ctx, repo, user.Username())
+	user, err := d.UserByPublicKey(ctx, pk)
+	if err != nil && !errors.Is(err, proto.ErrUserNotFound) {
+		return -1, err
 	}
-
-	return d.AccessLevel(ctx, repo, "")
+	// Registered keys have the access of their user and unregistered ones the
+	// anonymous access, plus the access granted to the key on the repository.
+	return d.accessLevelFor(ctx, repo, user, pk)
 }
 
 // AccessLevelForUser returns the access level of a user for a repository.
 // TODO: user repository ownership
 func (d *Backend) AccessLevelForUser(ctx context.Context, repo string, user proto.User) access.AccessLevel {
+	ctx, span := tracing.Start(ctx, "backend.access_level", trace.WithAttributes(
+		attribute.String("repo", repo),
+	))
+	level, err := d.accessLevelForUser(ctx, repo, user)
+	if err != nil {
+		level = d.accessLevelOnError(repo, err)
+	}
+	level = access.Limit(ctx, level)
+	span.SetAttributes(attribute.String("access_level", level.String()))
+	tracing.End(span, err)
+
+	return level
+}
+
+// HasCapability returns whether a user has the access level a capability
+// requires on a repository.
+func (d *Backend) HasCapability(ctx context.Context, repo string, user proto.User, c access.Capability) bool {
+	return d.AccessLevelForUser(ctx, repo, user) >= d.cfg.Access.CapabilityLevel(c)
+}
+
+func (d *Backend) accessLevelForUser(ctx context.Context, repo string, user proto.User) (access.AccessLevel, error) {
+	return d.accessLevelFor(ctx, repo, user, signInKey(ctx, user))
+}
+
+// signInKey returns the public key the SSH client of the context signed in
+// with, if it's the key of the user, or nil. Keys are added to accounts
+// without proving their possession, so the access granted to a key only
+// counts when signing in with it.
+func signInKey(ctx context.Context, user proto.User) ssh.PublicKey {
+	pk := sshutils.PublicKeyFromContext(ctx)
+	if pk == nil || user == nil {
+		return pk
+	}
+
+	for _, k := range user.PublicKeys() {
+		if sshutils.KeysEqual(pk, k) {
+			return pk
+		}
+	}
+
+	return nil
+}
+
+// accessLevelFor returns the access level of a user, nil for anonymous
+// users, for a repository. The access granted to the public key pk on the
+// repository, the key the client signed in with, is added to the one of the
+// user.
+func (d *Backend) accessLevelFor(ctx context.Context, repo string, user proto.User, pk ssh.PublicKey) (access.AccessLevel, error) {
 	var username string
-	anon := d.AnonAccess(ctx)
 	if user != nil {
 		username = user.Username()
 	}
 
 	// If the user is an admin, they have admin access.
 	if user != nil && user.IsAdmin() {
-		return access.AdminAccess
+		return access.AdminAccess, nil
+	}
+
+	anon, err := d.anonAccess(ctx)
+	if err != nil {
+		return -1, err
+	}
+
+	// In lockdown, only admins have access.
+	locked, err := d.lockdown(ctx)
+	if err != nil {
+		return -1, err
+	}
+	if locked {
+		return access.NoAccess, nil
 	}
 
 	// If the repository exists, check if the user is a collaborator.
 	r := proto.RepositoryFromContext(ctx)
 	if r == nil {
-		r, _ = d.Repository(ctx, repo)
+		r, err = d.Repository(ctx, repo)
+		if err != nil && !errors.Is(err, proto.ErrRepoNotFound) {
+			return -1, err
+		}
 	}
 
+	// In strict mode, only explicitly public repositories are readable
+	// without a grant.
+	public := !d.cfg.Access.Strict || d.cfg.Access.IsPublicRepo(utils.SanitizeRepo(repo))
+
 	if r != nil {
-		if user != nil {
-			// If the user is the owner, they have admin access.
-			if r.UserID() == user.ID() {
-				return access.AdminAccess
+		// If the user is the owner, they have the creator access level, at
+		// least. It's admin access by default.
+		var owner access.AccessLevel
+		if user != nil && r.UserID() == user.ID() {
+			owner = d.cfg.Access.CreatorAccessLevel()
+			if owner == access.AdminAccess {
+				return owner, nil
 			}
 		}
 
-		// If the user is a collaborator, they have return their access level.
-		collabAccess, isCollab, _ := d.IsCollaborator(ctx, repo, username)
-		if isCollab {
-			if anon > collabAccess {
-				return anon
-			}
-			return collabAccess
+		level, err := d.repoAccessLevel(ctx, r, username, user != nil, anon, public)
+		if err != nil {
+			return -1, err
 		}
 
-		// If the repository is private, the user has no access.
-		if r.IsPrivate() {
-			return access.NoAccess
+		// Keys granted access to the repository, directly or with a deploy
+		// token, have it even without any access to the rest of the server.
+		keyLevel, err := d.repoKeyAccessLevel(ctx, r.Name(), pk)
+		if err != nil {
+			return -1, err
 		}
 
-		// Otherwise, the user has read-only access.
-		if user == nil {
-			return anon
+		deployLevel, err := d.deployKeyAccessLevel(ctx, r, pk)
+		if err != nil {
+			return -1, err
 		}
 
-		return access.ReadOnlyAccess
+		return max(owner, level, keyLevel, deployLevel), nil
+	}
+
+	// In strict mode, creating repositories requires an explicit grant.
+	if d.cfg.Access.Strict {
+		return access.NoAccess, nil
 	}
 
 	if user != nil {
 		// If the repository doesn't exist, the user has read/write access.
 		if anon > access.ReadWriteAccess {
-			return anon
+			return anon, nil
 		}
 
-		return access.ReadWriteAccess
+		return access.ReadWriteAccess, nil
 	}
 
 	// If the user doesn't exist, give them the anonymous access level.
-	return anon
+	return anon, nil
+}
+
+// repoAccessLevel returns the access level of a user to an existing
+// repository, not counting ownership.
+func (d *Backend) repoAccessLevel(ctx context.Context, r proto.Repository, username string, isUser bool, anon access.AccessLevel, public bool) (access.AccessLevel, error) {
+	// If the user is a collaborator, they have return their access level.
+	collabAccess, isCollab, err := d.IsCollaborator(ctx, r.Name(), username)
+	if err != nil && !errors.Is(err, db.ErrRecordNotFound) {
+		return -1, err
+	}
+	if isCollab {
+		if public && anon > collabAccess {
+			return anon, nil
+		}
+		return collabAccess, nil
+	}
+
+	// If the repository is private, the user has no access.
+	if r.IsPrivate() || !public {
+		return access.NoAccess, nil
+	}
+
+	// Otherwise, the user has read-only access.
+	if !isUser {
+		return anon, nil
+	}
+
+	return access.ReadOnlyAccess, nil
 }
 
 // User finds a user by username.
@@ -115,7 +259,7 @@ func (d *Backend) User(ctx context.Context, username string) (proto.User, error)
 
 	var m models.User
 	var pks []ssh.PublicKey
-	if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+	if err := d.retryTx(ctx, "user", func(tx *db.Tx) error {
 		var err error
 		m, err = d.store.FindUserByUsername(ctx, tx, username)
 		if err != nil {
@@ -173,7 +317,7 @@ func (d *Backend) UserByID(ctx context.Context, id int64) (proto.User, error) {
 func (d *Backend) UserByPublicKey(ctx context.Context, pk ssh.PublicKey) (proto.User, error) {
 	var m models.User
 	var pks []ssh.PublicKey
-	if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+	if err := d.retryTx(ctx, "user_by_public_key", func(tx *db.Tx) error {
 		var err error
 		m, err = d.store.FindUserByPublicKey(ctx, tx, pk)
 		if err != nil {
@@ -204,7 +348,7 @@ func (d *Backend) UserByAccessToken(ctx context.Context, token string) (proto.Us
 	var pks []ssh.PublicKey
 	token = HashToken(token)
 
-	if err := d.db.TransactionContext(ctx, func(tx *db.Tx) error {
+	if err := d.retryTx(ctx, "user_by_access_token", func(tx *db.Tx) error {
 		t, err := d.store.GetAccessTokenByToken(ctx, tx, token)
 		if err != nil {
 			return db.WrapError(err)
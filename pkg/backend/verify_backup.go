@@ -0,0 +1,194 @@
+package backend
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/charmbracelet/soft-serve/git"
+)
+
+// Backup types.
+const (
+	// BackupBundle is a git bundle file.
+	BackupBundle = "bundle"
+	// BackupMirror is a mirror clone of the repository.
+	BackupMirror = "mirror"
+)
+
+// BackupRef is a ref that differs between a repository and its backup. The
+// object ID is empty on the side missing the ref.
+type BackupRef struct {
+	Ref    string `json:"ref"`
+	Repo   string `json:"repo,omitempty"`
+	Backup string `json:"backup,omitempty"`
+}
+
+// BackupReport is the result of comparing a repository against a backup.
+type BackupReport struct {
+	// Repo is the name of the repository.
+	Repo string `json:"repo"`
+	// Backup is the path of the backup.
+	Backup string `json:"backup"`
+	// Type is the backup type, BackupBundle or BackupMirror.
+	Type string `json:"type"`
+	// Refs are the refs that differ, sorted by name.
+	Refs []BackupRef `json:"refs"`
+	// Objects is the number of objects reachable from the repository refs.
+	Objects int `json:"objects"`
+	// BackupObjects is the number of objects reachable from the backup refs.
+	BackupObjects int `json:"backup_objects"`
+	// MissingObjects is the number of repository objects missing from the
+	// backup.
+	MissingObjects int `json:"missing_objects"`
+	// ExtraObjects is the number of backup objects missing from the
+	// repository.
+	ExtraObjects int `json:"extra_objects"`
+}
+
+// Match returns true if the backup has the same refs and objects as the
+// repository.
+func (r BackupReport) Match() bool {
+	return len(r.Refs) == 0 && r.MissingObjects == 0 && r.ExtraObjects == 0
+}
+
+// VerifyBackup compares the refs and the reachable objects of a repository
+// against a backup, either a bundle file or a mirror clone. Bundles are
+// restored in a temporary repository first, which checks they're complete.
+func (d *Backend) VerifyBackup(ctx context.Context, repo string, backup string) (BackupReport, error) {
+	report := BackupReport{Repo: repo, Backup: backup, Refs: []BackupRef{}}
+	r, err := d.Repository(ctx, repo)
+	if err != nil {
+		return report, err
+	}
+
+	rr, err := r.Open()
+	if err != nil {
+		return report, err
+	}
+
+	fi, err := os.Stat(backup)
+	if err != nil {
+		return report, fmt.Errorf("backup: %w", err)
+	}
+
+	backupPath, err := filepath.Abs(backup)
+	if err != nil {
+		return report, err
+	}
+
+	report.Type = BackupMirror
+	if !fi.IsDir() {
+		report.Type = BackupBundle
+		backupPath, err = restoreBundle(ctx, backupPath)
+		if err != nil {
+			return report, fmt.Errorf("restore bundle: %w", err)
+		}
+		defer os.RemoveAll(backupPath) // nolint: errcheck
+	} else if !isGitDir(backupPath) && !isGitDir(filepath.Join(backupPath, ".git")) {
+		return report, fmt.Errorf("backup is not a git repository: %s", backup)
+	}
+
+	refs, err := mirrorRefs(rr.Path)
+	if err != nil {
+		return report, err
+	}
+	backupRefs, err := mirrorRefs(backupPath)
+	if err != nil {
+		return report, err
+	}
+	for ref, id := range refs {
+		if backupRefs[ref] != id {
+			report.Refs = append(report.Refs, BackupRef{Ref: ref, Repo: id, Backup: backupRefs[ref]})
+		}
+	}
+	for ref, id := range backupRefs {
+		if _, ok := refs[ref]; !ok {
+			report.Refs = append(report.Refs, BackupRef{Ref: ref, Backup: id})
+		}
+	}
+	sort.Slice(report.Refs, func(i, j int) bool {
+		return report.Refs[i].Ref < report.Refs[j].Ref
+	})
+
+	objects, err := reachableObjects(ctx, rr.Path)
+	if err != nil {
+		return report, err
+	}
+	backupObjects, err := reachableObjects(ctx, backupPath)
+	if err != nil {
+		return report, err
+	}
+	report.Objects = len(objects)
+	report.BackupObjects = len(backupObjects)
+	for id := range objects {
+		if _, ok := backupObjects[id]; !ok {
+			report.MissingObjects++
+		}
+	}
+	for id := range backupObjects {
+		if _, ok := objects[id]; !ok {
+			report.ExtraObjects++
+		}
+	}
+
+	return report, nil
+}
+
+// restoreBundle fetches all the refs of a bundle into a new temporary bare
+// repository and returns its path.
+func restoreBundle(ctx context.Context, bundle string) (string, error) {
+	dir, err := os.MkdirTemp("", "soft-serve-verify-backup-*")
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := git.NewCommand("init", "--bare", "--quiet", dir).WithContext(ctx).Run(); err != nil {
+		os.RemoveAll(dir) // nolint: errcheck
+		return "", err
+	}
+
+	if _, err := git.NewCommand("fetch", "--quiet", "--no-tags", bundle, "+refs/*:refs/*").
+		WithContext(ctx).WithTimeout(-1).RunInDir(dir); err != nil {
+		os.RemoveAll(dir) // nolint: errcheck
+		return "", err
+	}
+
+	return dir, nil
+}
+
+// isGitDir returns true if path looks like a git directory.
+func isGitDir(path string) bool {
+	for _, name := range []string{"HEAD", "objects", "refs"} {
+		if _, err := os.Stat(filepath.Join(path, name)); err != nil {
+			return false
+		}
+	}
+
+	return true
+}
+
+// reachableObjects returns the IDs of the objects reachable from the refs of
+// a repository.
+func reachableObjects(ctx context.Context, path string) (map[string]struct{}, error) {
+	out, err := git.NewCommand("rev-list", "--objects", "--all").WithContext(ctx).WithTimeout(-1).RunInDir(path)
+	if err != nil {
+		return nil, err
+	}
+
+	objects := map[string]struct{}{}
+	s := bufio.NewScanner(bytes.NewReader(out))
+	for s.Scan() {
+		// Trees and blobs are followed by their path.
+		id, _, _ := bytes.Cut(s.Bytes(), []byte(" "))
+		if len(id) > 0 {
+			objects[string(id)] = struct{}{}
+		}
+	}
+
+	return objects, s.Err()
+}
@@ -3,6 +3,8 @@ package backend
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"strings"
 
 	"github.com/charmbracelet/log"
 	"github.com/charmbracelet/soft-serve/pkg/db"
@@ -13,13 +15,20 @@ import (
 	"github.com/google/uuid"
 )
 
-// CreateWebhook creates a webhook for a repository.
-func (b *Backend) CreateWebhook(ctx context.Context, repo proto.Repository, url string, contentType webhook.ContentType, secret string, events []webhook.Event, active bool) error {
+// CreateWebhook creates a webhook for a repository. Push events are only sent
+// for the refs matching the ref patterns, if any, and include all the updated
+// refs when allRefs is true.
+func (b *Backend) CreateWebhook(ctx context.Context, repo proto.Repository, url string, contentType webhook.ContentType, secret string, events []webhook.Event, refPatterns []string, allRefs bool, active bool) error {
+	patterns, err := joinRefPatterns(refPatterns)
+	if err != nil {
+		return err
+	}
+
 	dbx := db.FromContext(ctx)
 	datastore := store.FromContext(ctx)
 
 	return dbx.TransactionContext(ctx, func(tx *db.Tx) error {
-		lastID, err := datastore.CreateWebhook(ctx, tx, repo.ID(), url, secret, int(contentType), active)
+		lastID, err := datastore.CreateWebhook(ctx, tx, repo.ID(), url, secret, int(contentType), patterns, allRefs, active)
 		if err != nil {
 			return db.WrapError(err)
 		}
@@ -61,7 +70,8 @@ func (b *Backend) Webhook(ctx context.Context, repo proto.Repository, id int64)
 			wh.Events[i] = webhook.Event(e.Event)
 		}
 
-		return nil
+		wh.LastDelivery, err = webhookLastDelivery(ctx, tx, datastore, id)
+		return err
 	}); err != nil {
 		return webhook.Hook{}, db.WrapError(err)
 	}
@@ -76,6 +86,7 @@ func (b *Backend) ListWebhooks(ctx context.Context, repo proto.Repository) ([]we
 
 	var webhooks []models.Webhook
 	webhookEvents := map[int64][]models.WebhookEvent{}
+	lastDeliveries := map[int64]*models.WebhookLastDelivery{}
 	if err := dbx.TransactionContext(ctx, func(tx *db.Tx) error {
 		var err error
 		webhooks, err = datastore.GetWebhooksByRepoID(ctx, tx, repo.ID())
@@ -89,6 +100,11 @@ func (b *Backend) ListWebhooks(ctx context.Context, repo proto.Repository) ([]we
 				return err
 			}
 			webhookEvents[h.ID] = events
+
+			lastDeliveries[h.ID], err = webhookLastDelivery(ctx, tx, datastore, h.ID)
+			if err != nil {
+				return err
+			}
 		}
 
 		return nil
@@ -104,9 +120,10 @@ func (b *Backend) ListWebhooks(ctx context.Context, repo proto.Repository) ([]we
 		}
 
 		hooks[i] = webhook.Hook{
-			Webhook:     h,
-			ContentType: webhook.ContentType(h.ContentType),
-			Events:      events,
+			Webhook:      h,
+			ContentType:  webhook.ContentType(h.ContentType),
+			Events:       events,
+			LastDelivery: lastDeliveries[h.ID],
 		}
 	}
 
@@ -114,12 +131,17 @@ func (b *Backend) ListWebhooks(ctx context.Context, repo proto.Repository) ([]we
 }
 
 // UpdateWebhook updates a webhook.
-func (b *Backend) UpdateWebhook(ctx context.Context, repo proto.Repository, id int64, url string, contentType webhook.ContentType, secret string, updatedEvents []webhook.Event, active bool) error {
+func (b *Backend) UpdateWebhook(ctx context.Context, repo proto.Repository, id int64, url string, contentType webhook.ContentType, secret string, updatedEvents []webhook.Event, refPatterns []string, allRefs bool, active bool) error {
+	patterns, err := joinRefPatterns(refPatterns)
+	if err != nil {
+		return err
+	}
+
 	dbx := db.FromContext(ctx)
 	datastore := store.FromContext(ctx)
 
 	return dbx.TransactionContext(ctx, func(tx *db.Tx) error {
-		if err := datastore.UpdateWebhookByID(ctx, tx, repo.ID(), id, url, secret, int(contentType), active); err != nil {
+		if err := datastore.UpdateWebhookByID(ctx, tx, repo.ID(), id, url, secret, int(contentType), patterns, allRefs, active); err != nil {
 			return db.WrapError(err)
 		}
 
@@ -277,3 +299,47 @@ func (b *Backend) WebhookDelivery(ctx context.Context, webhookID int64, id uuid.
 
 	return delivery, nil
 }
+
+// TestWebhook sends a ping event to a webhook, whether it's active or not, and
+// returns the result of the delivery.
+func (b *Backend) TestWebhook(ctx context.Context, repo proto.Repository, id int64) (webhook.Result, error) {
+	dbx := db.FromContext(ctx)
+	datastore := store.FromContext(ctx)
+
+	wh, err := datastore.GetWebhookByID(ctx, dbx, repo.ID(), id)
+	if err != nil {
+		return webhook.Result{}, db.WrapError(err)
+	}
+
+	payload, err := webhook.NewPingEvent(ctx, proto.UserFromContext(ctx), repo, wh.ID)
+	if err != nil {
+		return webhook.Result{}, err
+	}
+
+	return webhook.Deliver(ctx, wh, webhook.EventPing, payload)
+}
+
+// webhookLastDelivery returns the last delivery of a webhook, or nil if it
+// was never delivered.
+func webhookLastDelivery(ctx context.Context, h db.Handler, datastore store.Store, id int64) (*models.WebhookLastDelivery, error) {
+	d, err := datastore.GetWebhookLastDelivery(ctx, h, id)
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &d, nil
+}
+
+// joinRefPatterns validates webhook ref patterns and joins them for storage.
+func joinRefPatterns(patterns []string) (string, error) {
+	for _, p := range patterns {
+		if err := webhook.ValidateRefPattern(p); err != nil {
+			return "", err
+		}
+	}
+
+	return strings.Join(patterns, ","), nil
+}
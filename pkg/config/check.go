@@ -0,0 +1,130 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/robfig/cron/v3"
+)
+
+// Check reports problems with the configuration that would prevent the server
+// from starting or working correctly. Unlike Validate, it inspects the file
+// system and looks for conflicting settings. It expects a validated
+// configuration.
+func (c *Config) Check() []error {
+	var errs []error
+	addErr := func(format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	if fi, err := os.Stat(c.DataPath); err != nil {
+		addErr("data path %q: %w", c.DataPath, err)
+	} else if !fi.IsDir() {
+		addErr("data path %q is not a directory", c.DataPath)
+	}
+
+	// Key files are generated on first start, but if they exist they must be
+	// readable.
+	for _, f := range []namedValue{
+		{"ssh host key", c.SSH.KeyPath},
+		{"ssh client key", c.SSH.ClientKeyPath},
+	} {
+		if f.value == "" {
+			continue
+		}
+		if err := checkReadable(f.value); err != nil && !os.IsNotExist(err) {
+			addErr("%s %q: %w", f.name, f.value, err)
+		}
+	}
+
+	if (c.HTTP.TLSKeyPath == "") != (c.HTTP.TLSCertPath == "") {
+		addErr("http tls_key_path and tls_cert_path must be set together")
+	} else if c.HTTP.TLSKeyPath != "" {
+		for _, f := range []namedValue{
+			{"http tls key", c.HTTP.TLSKeyPath},
+			{"http tls cert", c.HTTP.TLSCertPath},
+		} {
+			if err := checkReadable(f.value); err != nil {
+				addErr("%s %q: %w", f.name, f.value, err)
+			}
+		}
+	}
+
+	listeners := []namedValue{
+		{"ssh", c.SSH.ListenAddr},
+		{"http", c.HTTP.ListenAddr},
+		{"stats", c.Stats.ListenAddr},
+	}
+	if c.Git.Enabled {
+		listeners = append(listeners, namedValue{"git", c.Git.ListenAddr})
+	}
+	if c.Profiling.Enabled {
+		listeners = append(listeners, namedValue{"profiling", c.Profiling.ListenAddr})
+	}
+	sshAddrs := make([]string, 0, len(c.SSH.Listeners))
+	for addr := range c.SSH.Listeners {
+		sshAddrs = append(sshAddrs, addr)
+	}
+	sort.Strings(sshAddrs)
+	for _, addr := range sshAddrs {
+		listeners = append(listeners, namedValue{"ssh listener", addr})
+	}
+
+	addrs := map[string]string{}
+	for _, l := range listeners {
+		if l.value == "" {
+			continue
+		}
+		if other, ok := addrs[l.value]; ok {
+			addErr("%s and %s listen on the same address %q", other, l.name, l.value)
+			continue
+		}
+		addrs[l.value] = l.name
+	}
+
+	if c.LFS.SSHEnabled && !c.LFS.Enabled {
+		addErr("lfs ssh_enabled requires lfs to be enabled")
+	}
+
+	switch strings.ToLower(c.Log.Format) {
+	case "", "text", "json", "logfmt":
+	default:
+		addErr("unknown log format %q", c.Log.Format)
+	}
+
+	switch c.DB.Driver {
+	case "sqlite", "sqlite3", "postgres":
+	default:
+		addErr("unknown database driver %q", c.DB.Driver)
+	}
+
+	for _, j := range []namedValue{
+		{"mirror_pull", c.Jobs.MirrorPull},
+		{"push_mirror", c.Jobs.PushMirror},
+	} {
+		if j.value == "" {
+			continue
+		}
+		if _, err := cron.ParseStandard(j.value); err != nil {
+			addErr("jobs %s %q: %w", j.name, j.value, err)
+		}
+	}
+
+	return errs
+}
+
+type namedValue struct {
+	name  string
+	value string
+}
+
+// checkReadable returns an error if the given file cannot be read.
+func checkReadable(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	return f.Close()
+}
@@ -2,14 +2,21 @@ package config
 
 import (
 	"fmt"
+	"math"
+	"net"
+	"net/url"
 	"os"
+	"path"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/caarlos0/env/v11"
+	"github.com/charmbracelet/soft-serve/pkg/access"
 	"github.com/charmbracelet/soft-serve/pkg/sshutils"
+	"github.com/dustin/go-humanize"
 	"golang.org/x/crypto/ssh"
 	"gopkg.in/yaml.v3"
 )
@@ -21,6 +28,12 @@ type SSHConfig struct {
 	// ListenAddr is the address on which the SSH server will listen.
 	ListenAddr string `env:"LISTEN_ADDR" yaml:"listen_addr"`
 
+	// Listeners maps additional addresses the SSH server listens on to the
+	// maximum access level of the clients connecting to them, e.g.
+	// "0.0.0.0:2222" => "read-only". Clients connecting to ListenAddr have
+	// their full access.
+	Listeners map[string]string `env:"LISTENERS" envKeyValSeparator:"=" yaml:"listeners"`
+
 	// PublicURL is the public URL of the SSH server.
 	PublicURL string `env:"PUBLIC_URL" yaml:"public_url"`
 
@@ -35,10 +48,135 @@ type SSHConfig struct {
 
 	// IdleTimeout is the number of seconds a connection can be idle before it is closed.
 	IdleTimeout int `env:"IDLE_TIMEOUT" yaml:"idle_timeout"`
+
+	// PreAuthTimeout is the number of seconds a connection has to complete
+	// the handshake and authentication before it is closed.
+	PreAuthTimeout int `env:"PRE_AUTH_TIMEOUT" yaml:"pre_auth_timeout"`
+
+	// MaxStartups throttles the connections that haven't authenticated yet,
+	// like OpenSSH's MaxStartups. It's either "start:rate:full", randomly
+	// dropping rate percent of the new connections once there are start
+	// unauthenticated ones, increasing linearly up to all of them at full, or
+	// a single number of unauthenticated connections after which every new
+	// one is dropped. Leave it empty to disable the throttling.
+	MaxStartups string `env:"MAX_STARTUPS" yaml:"max_startups"`
+
+	// StrictUsernames rejects SSH connections whose username is neither one
+	// of AllowedUsernames nor the username of the authenticated user.
+	StrictUsernames bool `env:"STRICT_USERNAMES" yaml:"strict_usernames"`
+
+	// AllowedUsernames is the list of SSH usernames any client can connect
+	// with when StrictUsernames is enabled.
+	AllowedUsernames []string `env:"ALLOWED_USERNAMES" yaml:"allowed_usernames"`
+
+	// AllowedCommands is the list of commands SSH clients can run, by name,
+	// e.g. "git-upload-pack" or "repo". An empty list allows every command.
+	AllowedCommands []string `env:"ALLOWED_COMMANDS" yaml:"allowed_commands"`
+
+	// Sources maps client network CIDRs to source names used to label
+	// authentication metrics, e.g. "10.0.0.0/8" => "office".
+	Sources map[string]string `env:"SOURCES" envKeyValSeparator:"=" yaml:"sources"`
+
+	// ProxyProtocol is whether connections from the trusted proxies start
+	// with a PROXY protocol header carrying the real client address. Only
+	// enable this behind a load balancer sending it, their connections
+	// without the header are rejected.
+	ProxyProtocol bool `env:"PROXY_PROTOCOL" yaml:"proxy_protocol"`
+
+	// TrustedProxies are the IP addresses and CIDRs of the load balancers
+	// sending the PROXY protocol header. Headers from other peers aren't
+	// read. It's required when ProxyProtocol is enabled.
+	TrustedProxies []string `env:"TRUSTED_PROXIES" envSeparator:"," yaml:"trusted_proxies"`
+
+	// Invites lets clients with an unregistered key register it with an
+	// invite code over keyboard-interactive authentication. It only applies
+	// when anonymous users have no access.
+	Invites bool `env:"INVITES" yaml:"invites"`
+
+	// InteractiveMaxFailures is the number of failed keyboard-interactive
+	// authentication attempts, like wrong invite codes, after which a client
+	// IP address is locked out. A value of 0 disables the lockout.
+	InteractiveMaxFailures int `env:"INTERACTIVE_MAX_FAILURES" yaml:"interactive_max_failures"`
+
+	// InteractiveLockout is the number of seconds a client IP address is
+	// locked out for, and over which its failures are counted.
+	InteractiveLockout int `env:"INTERACTIVE_LOCKOUT" yaml:"interactive_lockout"`
+
+	// DeprecatedAlgorithms is the list of key exchange, cipher, and MAC
+	// algorithms clients shouldn't negotiate anymore. An empty list disables
+	// the check.
+	DeprecatedAlgorithms []string `env:"DEPRECATED_ALGORITHMS" yaml:"deprecated_algorithms"`
+
+	// DeprecatedAlgorithmsAction is what happens to connections negotiating
+	// a deprecated algorithm. It's either "warn", logging the client, or
+	// "deny", no longer offering the deprecated algorithms.
+	DeprecatedAlgorithmsAction string `env:"DEPRECATED_ALGORITHMS_ACTION" yaml:"deprecated_algorithms_action"`
+}
+
+// Actions on SSH connections negotiating deprecated algorithms.
+const (
+	// DeprecatedAlgorithmsWarn logs the connection and its identity.
+	DeprecatedAlgorithmsWarn = "warn"
+	// DeprecatedAlgorithmsDeny stops offering the deprecated algorithms, so
+	// clients supporting nothing else can't connect.
+	DeprecatedAlgorithmsDeny = "deny"
+)
+
+// MaxStartups are the thresholds of the throttling of unauthenticated SSH
+// connections.
+type MaxStartups struct {
+	// Start is the number of unauthenticated connections after which new
+	// ones are dropped at random.
+	Start int
+	// Rate is the percentage of new connections dropped at Start.
+	Rate int
+	// Full is the number of unauthenticated connections after which every
+	// new one is dropped.
+	Full int
+}
+
+// ParseMaxStartups parses the SSH max startups setting, either
+// "start:rate:full" or a single number. An empty setting returns zero
+// thresholds, disabling the throttling.
+func ParseMaxStartups(s string) (MaxStartups, error) {
+	if s == "" {
+		return MaxStartups{}, nil
+	}
+
+	parts := strings.Split(s, ":")
+	nums := make([]int, len(parts))
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return MaxStartups{}, fmt.Errorf("invalid ssh.max_startups %q", s)
+		}
+		nums[i] = n
+	}
+
+	var m MaxStartups
+	switch len(nums) {
+	case 1:
+		m = MaxStartups{Start: nums[0], Rate: 100, Full: nums[0]}
+	case 3:
+		m = MaxStartups{Start: nums[0], Rate: nums[1], Full: nums[2]}
+	default:
+		return MaxStartups{}, fmt.Errorf("invalid ssh.max_startups %q, expected start:rate:full", s)
+	}
+
+	if m.Start == 0 || m.Rate > 100 || m.Full < m.Start {
+		return MaxStartups{}, fmt.Errorf("invalid ssh.max_startups %q, start must be positive, rate at most 100, and full at least start", s)
+	}
+
+	return m, nil
 }
 
 // GitConfig is the Git daemon configuration for the server.
 type GitConfig struct {
+	// Enabled is whether the Git daemon is enabled. It serves repositories
+	// over the unauthenticated git:// protocol, read-only, and only those
+	// explicitly exported.
+	Enabled bool `env:"ENABLED" yaml:"enabled"`
+
 	// ListenAddr is the address on which the Git daemon will listen.
 	ListenAddr string `env:"LISTEN_ADDR" yaml:"listen_addr"`
 
@@ -53,6 +191,17 @@ type GitConfig struct {
 
 	// MaxConnections is the maximum number of concurrent connections.
 	MaxConnections int `env:"MAX_CONNECTIONS" yaml:"max_connections"`
+
+	// ProxyProtocol is whether connections from the trusted proxies start
+	// with a PROXY protocol header carrying the real client address. Only
+	// enable this behind a load balancer sending it, their connections
+	// without the header are rejected.
+	ProxyProtocol bool `env:"PROXY_PROTOCOL" yaml:"proxy_protocol"`
+
+	// TrustedProxies are the IP addresses and CIDRs of the load balancers
+	// sending the PROXY protocol header. Headers from other peers aren't
+	// read. It's required when ProxyProtocol is enabled.
+	TrustedProxies []string `env:"TRUSTED_PROXIES" envSeparator:"," yaml:"trusted_proxies"`
 }
 
 // HTTPConfig is the HTTP configuration for the server.
@@ -68,12 +217,296 @@ type HTTPConfig struct {
 
 	// PublicURL is the public URL of the HTTP server.
 	PublicURL string `env:"PUBLIC_URL" yaml:"public_url"`
+
+	// ProxyProtocol is whether connections from the trusted proxies start
+	// with a PROXY protocol header carrying the real client address. Only
+	// enable this behind a load balancer sending it, their connections
+	// without the header are rejected.
+	ProxyProtocol bool `env:"PROXY_PROTOCOL" yaml:"proxy_protocol"`
+
+	// TrustedProxies are the IP addresses and CIDRs of the load balancers
+	// sending the PROXY protocol header. Headers from other peers aren't
+	// read. It's required when ProxyProtocol is enabled.
+	TrustedProxies []string `env:"TRUSTED_PROXIES" envSeparator:"," yaml:"trusted_proxies"`
+
+	// ErrorHelp is a message added to error responses, like who to contact
+	// for access.
+	ErrorHelp string `env:"ERROR_HELP" yaml:"error_help"`
+
+	// ErrorPage is the path to an HTML template rendering the error pages
+	// shown to browsers. The path is relative to the data directory unless
+	// absolute. It defaults to a built-in page.
+	ErrorPage string `env:"ERROR_PAGE" yaml:"error_page"`
+
+	// Browse is whether to serve a read-only HTML interface to browse the
+	// repositories under /browse.
+	Browse bool `env:"BROWSE" yaml:"browse"`
 }
 
+// Stats exporters.
+const (
+	// StatsExporterPrometheus serves the metrics on the /metrics endpoint of
+	// the stats server, to be scraped by Prometheus.
+	StatsExporterPrometheus = "prometheus"
+	// StatsExporterOTLP pushes the metrics to an OpenTelemetry collector.
+	StatsExporterOTLP = "otlp"
+)
+
+// OTLP protocols.
+const (
+	// OTLPProtocolGRPC exports OTLP over gRPC.
+	OTLPProtocolGRPC = "grpc"
+	// OTLPProtocolHTTP exports OTLP as protobuf over HTTP.
+	OTLPProtocolHTTP = "http/protobuf"
+)
+
 // StatsConfig is the configuration for the stats server.
 type StatsConfig struct {
 	// ListenAddr is the address on which the stats server will listen.
 	ListenAddr string `env:"LISTEN_ADDR" yaml:"listen_addr"`
+
+	// Exporters are the ways metrics are exported: "prometheus", "otlp", or
+	// both. The stats server only listens with the prometheus exporter.
+	Exporters []string `env:"EXPORTERS" envSeparator:"," yaml:"exporters"`
+
+	// OTLP is the configuration of the OTLP exporter.
+	OTLP OTLPConfig `envPrefix:"OTLP_" yaml:"otlp"`
+
+	// SeparateAnonymous is whether the git metrics tell anonymous requests
+	// apart from authenticated ones, with an anonymous label.
+	SeparateAnonymous bool `env:"SEPARATE_ANONYMOUS" yaml:"separate_anonymous"`
+}
+
+// OTLPConfig is the configuration for exporting metrics to an OpenTelemetry
+// collector.
+type OTLPConfig struct {
+	// Endpoint is the URL of the collector, like "http://localhost:4318".
+	// Plain http URLs export without TLS.
+	Endpoint string `env:"ENDPOINT" yaml:"endpoint"`
+
+	// Protocol is the OTLP protocol: "grpc" or "http/protobuf".
+	Protocol string `env:"PROTOCOL" yaml:"protocol"`
+
+	// Interval is the number of seconds between exports.
+	Interval int `env:"INTERVAL" yaml:"interval"`
+}
+
+// TracingConfig is the configuration for exporting traces to an
+// OpenTelemetry collector.
+type TracingConfig struct {
+	// Enabled is whether to trace SSH connections, git operations, hooks, and
+	// backend calls.
+	Enabled bool `env:"ENABLED" yaml:"enabled"`
+
+	// Endpoint is the URL of the collector, like "http://localhost:4318".
+	// Plain http URLs export without TLS.
+	Endpoint string `env:"ENDPOINT" yaml:"endpoint"`
+
+	// Protocol is the OTLP protocol: "grpc" or "http/protobuf".
+	Protocol string `env:"PROTOCOL" yaml:"protocol"`
+
+	// SampleRatio is the ratio of traces recorded, from 0 to 1.
+	SampleRatio float64 `env:"SAMPLE_RATIO" yaml:"sample_ratio"`
+}
+
+// Validate returns an error if tracing is misconfigured.
+func (t TracingConfig) Validate() error {
+	if !t.Enabled {
+		return nil
+	}
+
+	if err := validateOTLP("tracing", t.Endpoint, t.Protocol); err != nil {
+		return err
+	}
+
+	if t.SampleRatio < 0 || t.SampleRatio > 1 {
+		return fmt.Errorf("tracing.sample_ratio must be between 0 and 1")
+	}
+
+	return nil
+}
+
+// validateOTLP returns an error if an OTLP endpoint or protocol is invalid.
+func validateOTLP(section string, endpoint string, protocol string) error {
+	u, err := url.Parse(endpoint)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%s.endpoint must be an http or https URL", section)
+	}
+
+	switch protocol {
+	case OTLPProtocolGRPC, OTLPProtocolHTTP:
+	default:
+		return fmt.Errorf("%s.protocol must be %q or %q", section, OTLPProtocolGRPC, OTLPProtocolHTTP)
+	}
+
+	return nil
+}
+
+// HasExporter returns whether the metrics are exported with the given
+// exporter.
+func (s StatsConfig) HasExporter(exporter string) bool {
+	for _, e := range s.Exporters {
+		if e == exporter {
+			return true
+		}
+	}
+
+	return false
+}
+
+// AnonymousLabel returns the value of the anonymous label of the git metrics:
+// "true" for anonymous requests, "false" for authenticated ones, and empty when
+// anonymous requests aren't told apart.
+func (s StatsConfig) AnonymousLabel(anonymous bool) string {
+	if !s.SeparateAnonymous {
+		return ""
+	}
+
+	return strconv.FormatBool(anonymous)
+}
+
+// Validate returns an error if the stats exporters are misconfigured.
+func (s StatsConfig) Validate() error {
+	for _, e := range s.Exporters {
+		switch e {
+		case StatsExporterPrometheus, StatsExporterOTLP:
+		default:
+			return fmt.Errorf("invalid stats.exporters entry %q: must be %q or %q", e, StatsExporterPrometheus, StatsExporterOTLP)
+		}
+	}
+
+	if !s.HasExporter(StatsExporterOTLP) {
+		return nil
+	}
+
+	if err := validateOTLP("stats.otlp", s.OTLP.Endpoint, s.OTLP.Protocol); err != nil {
+		return err
+	}
+
+	if s.OTLP.Interval <= 0 {
+		return fmt.Errorf("stats.otlp.interval must be positive")
+	}
+
+	return nil
+}
+
+// Git's default pack settings.
+const (
+	// DefaultPackCompression is the zlib default compression level.
+	DefaultPackCompression = -1
+	// DefaultPackWindow is the default delta compression window.
+	DefaultPackWindow = 10
+)
+
+// PackConfig is the configuration of the packs served to clients. These are
+// git's pack.compression and pack.window settings.
+type PackConfig struct {
+	// Compression is the zlib compression level of the packs, from 0 (no
+	// compression) to 9 (the smallest packs). -1 is the zlib default.
+	Compression int `env:"COMPRESSION" yaml:"compression"`
+
+	// Window is the number of objects considered when looking for deltas.
+	// Larger windows can produce smaller packs at the cost of CPU time.
+	Window int `env:"WINDOW" yaml:"window"`
+}
+
+// QuotaConfig is the configuration for the disk quota of users, the total
+// size of the repositories they own on disk.
+type QuotaConfig struct {
+	// Default is the quota of every user, e.g. "10GiB". Leave it empty for
+	// no quota.
+	Default string `env:"DEFAULT" yaml:"default"`
+
+	// Users maps usernames to their quota, overriding the default. A quota
+	// of "0" means no quota.
+	Users map[string]string `env:"USERS" envKeyValSeparator:"=" yaml:"users"`
+
+	// WarnPercent is the share of their quota, in percent, from which users
+	// are warned about their usage. A value of 0 disables the warnings.
+	WarnPercent int `env:"WARN_PERCENT" yaml:"warn_percent"`
+}
+
+// UserQuota returns the quota of a user in bytes. Zero means no quota.
+func (c QuotaConfig) UserQuota(username string) int64 {
+	quota := c.Default
+	for u, q := range c.Users {
+		if strings.EqualFold(u, username) {
+			quota = q
+			break
+		}
+	}
+
+	n, _ := parseQuota(quota)
+	return n
+}
+
+// parseQuota parses a quota size, like "10GiB" or "500MB". An empty size is
+// no quota.
+func parseQuota(s string) (int64, error) {
+	if s == "" {
+		return 0, nil
+	}
+
+	n, err := humanize.ParseBytes(s)
+	if err != nil || n > math.MaxInt64 {
+		return 0, fmt.Errorf("invalid quota %q", s)
+	}
+
+	return int64(n), nil
+}
+
+// ImportConfig is the configuration for importing repositories.
+type ImportConfig struct {
+	// Credentials maps the hosts of HTTP remotes, with their port if any, to
+	// the "username:password" credentials used to import and mirror their
+	// repositories. Only imports by admins use them.
+	Credentials map[string]string `env:"CREDENTIALS" envKeyValSeparator:"=" yaml:"credentials"`
+}
+
+// RepoRenamesConfig is the configuration for renamed repositories.
+type RepoRenamesConfig struct {
+	// GracePeriod is the number of days clients using the old name of a
+	// renamed repository are told about its new name. A value of 0 disables
+	// it.
+	GracePeriod int `env:"GRACE_PERIOD" yaml:"grace_period"`
+}
+
+// PostCreateConfig is the configuration for the hook run after a repository
+// is created.
+type PostCreateConfig struct {
+	// Hook is the path of the executable run after a repository is created
+	// or imported. It's skipped if it doesn't exist or isn't executable.
+	Hook string `env:"HOOK" yaml:"hook"`
+
+	// Rollback is whether a failing hook deletes the new repository and
+	// fails its creation. Otherwise, the failure is only logged.
+	Rollback bool `env:"ROLLBACK" yaml:"rollback"`
+}
+
+// Validate returns an error if the pack settings are out of range.
+func (p PackConfig) Validate() error {
+	if p.Compression < -1 || p.Compression > 9 {
+		return fmt.Errorf("pack.compression must be between -1 and 9")
+	}
+
+	if p.Window < 0 {
+		return fmt.Errorf("pack.window cannot be negative")
+	}
+
+	return nil
+}
+
+// ProfilingConfig is the configuration for the profiling server.
+type ProfilingConfig struct {
+	// Enabled is whether or not the profiling server is enabled.
+	Enabled bool `env:"ENABLED" yaml:"enabled"`
+
+	// ListenAddr is the address on which the profiling server will listen.
+	ListenAddr string `env:"LISTEN_ADDR" yaml:"listen_addr"`
+
+	// Token is the token required to access the profiling endpoints. It's
+	// required when the profiling server is enabled.
+	Token string `env:"TOKEN" yaml:"token"`
 }
 
 // LogConfig is the logger configuration.
@@ -89,6 +522,113 @@ type LogConfig struct {
 	// Path to a file to write logs to.
 	// If not set, logs will be written to stderr.
 	Path string `env:"PATH" yaml:"path"`
+
+	// GitStderr logs the stderr output of git commands at debug level.
+	GitStderr bool `env:"GIT_STDERR" yaml:"git_stderr"`
+
+	// GitErrorDetails includes a summary of the git error in the error
+	// returned to clients when a git command fails. Server paths are removed
+	// from the summary.
+	GitErrorDetails bool `env:"GIT_ERROR_DETAILS" yaml:"git_error_details"`
+
+	// SampleRate logs one in every SampleRate successful SSH sessions and HTTP
+	// requests. Failures are always logged. A value of 0 or 1 logs everything.
+	SampleRate int `env:"SAMPLE_RATE" yaml:"sample_rate"`
+
+	// RateLimit is the maximum number of successful SSH sessions and HTTP
+	// requests logged per second. A value of 0 means no limit.
+	RateLimit int `env:"RATE_LIMIT" yaml:"rate_limit"`
+
+	// Syslog is the configuration of the syslog output of the access and
+	// audit logs.
+	Syslog SyslogConfig `envPrefix:"SYSLOG_" yaml:"syslog"`
+}
+
+// Syslog drop policies.
+const (
+	// SyslogDropNewest drops new messages when the syslog buffer is full.
+	SyslogDropNewest = "newest"
+	// SyslogDropOldest drops the oldest buffered message to make room for a
+	// new one when the syslog buffer is full.
+	SyslogDropOldest = "oldest"
+)
+
+// syslogFacilities are the valid syslog facility names.
+var syslogFacilities = []string{
+	"kern", "user", "mail", "daemon", "auth", "syslog", "lpr", "news",
+	"uucp", "cron", "authpriv", "ftp", "ntp", "security", "console", "solaris-cron",
+	"local0", "local1", "local2", "local3", "local4", "local5", "local6", "local7",
+}
+
+// SyslogFacility returns the code of a syslog facility name, and whether the
+// name is valid.
+func SyslogFacility(name string) (int, bool) {
+	for i, f := range syslogFacilities {
+		if f == name {
+			return i, true
+		}
+	}
+
+	return 0, false
+}
+
+// SyslogConfig is the configuration of the syslog output. SSH sessions, HTTP
+// requests, and audit events are sent in RFC 5424 format to a syslog endpoint.
+type SyslogConfig struct {
+	// Address is the address of the syslog endpoint, host:port for network
+	// endpoints and a socket path for unix. Empty disables syslog.
+	Address string `env:"ADDRESS" yaml:"address"`
+
+	// Network is the transport to the endpoint: "udp", "tcp", "tls", or
+	// "unix".
+	Network string `env:"NETWORK" yaml:"network"`
+
+	// Facility is the syslog facility of the messages, e.g. "local0".
+	Facility string `env:"FACILITY" yaml:"facility"`
+
+	// AppName is the RFC 5424 APP-NAME of the messages.
+	AppName string `env:"APP_NAME" yaml:"app_name"`
+
+	// Only sends the access logs to syslog only, instead of also writing them
+	// to the server logs.
+	Only bool `env:"ONLY" yaml:"only"`
+
+	// BufferSize is the number of messages buffered while the endpoint is
+	// slow or unavailable.
+	BufferSize int `env:"BUFFER_SIZE" yaml:"buffer_size"`
+
+	// DropPolicy is the message dropped when the buffer is full: "newest" or
+	// "oldest".
+	DropPolicy string `env:"DROP_POLICY" yaml:"drop_policy"`
+}
+
+// Validate validates the syslog configuration.
+func (s SyslogConfig) Validate() error {
+	if s.Address == "" {
+		return nil
+	}
+
+	switch s.Network {
+	case "udp", "tcp", "tls", "unix":
+	default:
+		return fmt.Errorf("log.syslog.network must be one of udp, tcp, tls, or unix")
+	}
+
+	if _, ok := SyslogFacility(s.Facility); !ok {
+		return fmt.Errorf("invalid log.syslog.facility %q", s.Facility)
+	}
+
+	if s.BufferSize <= 0 {
+		return fmt.Errorf("log.syslog.buffer_size must be positive")
+	}
+
+	switch s.DropPolicy {
+	case SyslogDropNewest, SyslogDropOldest:
+	default:
+		return fmt.Errorf("log.syslog.drop_policy must be %q or %q", SyslogDropNewest, SyslogDropOldest)
+	}
+
+	return nil
 }
 
 // DBConfig is the database connection configuration.
@@ -98,6 +638,66 @@ type DBConfig struct {
 
 	// DataSource is the database data source name.
 	DataSource string `env:"DATA_SOURCE" yaml:"data_source"`
+
+	// MaxOpenConns is the maximum number of open connections to the database.
+	// A value of 0 means no limit.
+	MaxOpenConns int `env:"MAX_OPEN_CONNS" yaml:"max_open_conns"`
+
+	// MaxIdleConns is the maximum number of idle connections kept in the pool.
+	MaxIdleConns int `env:"MAX_IDLE_CONNS" yaml:"max_idle_conns"`
+
+	// ConnMaxLifetime is the maximum number of seconds a connection can be
+	// reused. A value of 0 means connections are reused forever.
+	ConnMaxLifetime int `env:"CONN_MAX_LIFETIME" yaml:"conn_max_lifetime"`
+
+	// Retries is the number of times authentication and access checks are
+	// retried on transient database errors.
+	Retries int `env:"RETRIES" yaml:"retries"`
+
+	// RetryTimeout is the maximum number of milliseconds authentication and
+	// access checks can take, including retries.
+	RetryTimeout int `env:"RETRY_TIMEOUT" yaml:"retry_timeout"`
+
+	// Retention is how long activity records are kept in the database.
+	Retention RetentionConfig `envPrefix:"RETENTION_" yaml:"retention"`
+}
+
+// RetentionConfig is the number of days activity records are kept in the
+// database before they're pruned. A value of 0 keeps them forever.
+type RetentionConfig struct {
+	// AuditEvents is the number of days audit events are kept. Older events
+	// are rotated into audit log segments.
+	AuditEvents int `env:"AUDIT_EVENTS" yaml:"audit_events"`
+
+	// CloneEvents is the number of days clone events are kept. Clone counts
+	// only count the events that are kept.
+	CloneEvents int `env:"CLONE_EVENTS" yaml:"clone_events"`
+
+	// MirrorSyncs is the number of days the sync history of pull mirrors is
+	// kept.
+	MirrorSyncs int `env:"MIRROR_SYNCS" yaml:"mirror_syncs"`
+}
+
+// AuditConfig is the configuration for the rotation of the audit log. Audit
+// events past their retention, see RetentionConfig, or over the maximum
+// number of events are moved from the database to compressed segment files.
+type AuditConfig struct {
+	// MaxEvents is the number of audit events kept in the database. Older
+	// events are rotated into segments. A value of 0 disables the limit.
+	MaxEvents int `env:"MAX_EVENTS" yaml:"max_events"`
+
+	// SegmentsPath is the directory the rotated segments are written to.
+	SegmentsPath string `env:"SEGMENTS_PATH" yaml:"segments_path"`
+
+	// ShipCommand is the path of an executable run with the path of each
+	// rotated segment as its argument, to copy it to external storage.
+	// Failures are retried on the next rotation.
+	ShipCommand string `env:"SHIP_COMMAND" yaml:"ship_command"`
+
+	// SegmentRetention is the number of days rotated segments are kept on
+	// disk. With a ship command, segments are only removed once shipped. A
+	// value of 0 keeps them forever.
+	SegmentRetention int `env:"SEGMENT_RETENTION" yaml:"segment_retention"`
 }
 
 // LFSConfig is the configuration for Git LFS.
@@ -110,9 +710,449 @@ type LFSConfig struct {
 	SSHEnabled bool `env:"SSH_ENABLED" yaml:"ssh_enabled"`
 }
 
+// AccessConfig is the access control configuration.
+type AccessConfig struct {
+	// Strict enables deny-by-default access control. When enabled, a user
+	// must have an explicit grant (admin, owner, or collaborator) to access a
+	// repository. Anonymous and unregistered users can only access
+	// repositories matching PublicRepos.
+	Strict bool `env:"STRICT" yaml:"strict"`
+
+	// PublicRepos is a list of repository name patterns that are explicitly
+	// marked as public. Patterns use the same syntax as path.Match.
+	// This is only used in strict mode.
+	PublicRepos []string `env:"PUBLIC_REPOS" envSeparator:"," yaml:"public_repos"`
+
+	// NamespaceVisibility maps repository namespaces to the default
+	// visibility of new repositories created under them, e.g. "internal" =>
+	// "private". The most specific namespace wins and "*" matches all
+	// repositories. Existing repositories are not affected.
+	NamespaceVisibility map[string]string `env:"NAMESPACE_VISIBILITY" envKeyValSeparator:"=" yaml:"namespace_visibility"`
+
+	// NewRepoVisibility is the default visibility of new repositories that
+	// don't match any namespace rule. Empty means public.
+	NewRepoVisibility string `env:"NEW_REPO_VISIBILITY" yaml:"new_repo_visibility"`
+
+	// CreatorAccess is the access level the creator of a repository has to
+	// it. Empty means admin access.
+	CreatorAccess string `env:"CREATOR_ACCESS" yaml:"creator_access"`
+
+	// DefaultCollaborators maps usernames to the access level they're granted
+	// as collaborators of every new repository.
+	DefaultCollaborators map[string]string `env:"DEFAULT_COLLABORATORS" envKeyValSeparator:"=" yaml:"default_collaborators"`
+
+	// Capabilities maps repository capabilities, like "manage-issues", to the
+	// access level they require. Capabilities that aren't listed require
+	// their default level, see access.DefaultCapabilities.
+	Capabilities map[string]string `env:"CAPABILITIES" envKeyValSeparator:"=" yaml:"capabilities"`
+
+	// OnBackendError is what happens to access checks when the access level
+	// can't be resolved because of a backend error, such as the database
+	// being unavailable. See FailClosed and FailOpenRead. Empty means
+	// FailClosed.
+	OnBackendError string `env:"ON_BACKEND_ERROR" yaml:"on_backend_error"`
+}
+
+// Behaviors of access checks on backend errors.
+const (
+	// FailClosed denies all access.
+	FailClosed = "fail-closed"
+	// FailOpenRead allows read-only access, as if every repository were
+	// public, but no writes.
+	FailOpenRead = "fail-open-read"
+)
+
+// Repository visibilities.
+const (
+	VisibilityPublic  = "public"
+	VisibilityPrivate = "private"
+	VisibilityHidden  = "hidden"
+)
+
+// IsPublicRepo returns true if the given repository matches one of the
+// explicitly public repository patterns.
+func (a AccessConfig) IsPublicRepo(repo string) bool {
+	for _, p := range a.PublicRepos {
+		if ok, _ := path.Match(p, repo); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// DefaultVisibility returns the default visibility of a new repository with the
+// given name and the namespace rule it was derived from. It returns
+// NewRepoVisibility, or VisibilityPublic, and an empty namespace if no rule
+// matches.
+func (a AccessConfig) DefaultVisibility(repo string) (visibility string, namespace string) {
+	visibility = VisibilityPublic
+	if a.NewRepoVisibility != "" {
+		visibility = a.NewRepoVisibility
+	}
+	best := -1
+	for ns, v := range a.NamespaceVisibility {
+		n := normalizeNamespace(ns)
+		switch {
+		case n == "*":
+			if best < 0 {
+				visibility, namespace = v, ns
+				best = 0
+			}
+		case repo == n || strings.HasPrefix(repo, n+"/"):
+			if len(n) > best {
+				visibility, namespace = v, ns
+				best = len(n)
+			}
+		}
+	}
+	return strings.ToLower(visibility), namespace
+}
+
+// CreatorAccessLevel returns the access level the creator of a repository has
+// to it.
+func (a AccessConfig) CreatorAccessLevel() access.AccessLevel {
+	if a.CreatorAccess == "" {
+		return access.AdminAccess
+	}
+	return access.ParseAccessLevel(a.CreatorAccess)
+}
+
+// CapabilityLevel returns the access level a repository capability requires.
+func (a AccessConfig) CapabilityLevel(c access.Capability) access.AccessLevel {
+	if level, ok := a.Capabilities[string(c)]; ok {
+		return access.ParseAccessLevel(level)
+	}
+	return access.DefaultCapabilities[c]
+}
+
+// normalizeNamespace strips trailing glob suffixes from a namespace, e.g.
+// "internal/*" => "internal".
+func normalizeNamespace(ns string) string {
+	ns = strings.Trim(ns, "/")
+	if ns == "*" || ns == "**" {
+		return "*"
+	}
+	ns = strings.TrimSuffix(ns, "/**")
+	ns = strings.TrimSuffix(ns, "/*")
+	return ns
+}
+
+// TimeoutsConfig is the configuration for git operation timeouts. These apply
+// to the git subprocess regardless of the transport and are distinct from the
+// connection timeouts.
+type TimeoutsConfig struct {
+	// UploadPack is the maximum number of seconds a git-upload-pack
+	// operation can take. A value of 0 means no timeout.
+	UploadPack int `env:"UPLOAD_PACK" yaml:"upload_pack"`
+
+	// ReceivePack is the maximum number of seconds a git-receive-pack
+	// operation can take. A value of 0 means no timeout.
+	ReceivePack int `env:"RECEIVE_PACK" yaml:"receive_pack"`
+
+	// Hook is the maximum number of seconds a custom git hook can take. A
+	// value of 0 means no timeout.
+	Hook int `env:"HOOK" yaml:"hook"`
+
+	// RepoLock is the maximum number of seconds an operation waits for
+	// another one to release the lock of a repository, like a push waiting
+	// for a rename. A value of 0 means no timeout.
+	RepoLock int `env:"REPO_LOCK" yaml:"repo_lock"`
+
+	// PushMirror is the maximum number of seconds a push to a push mirror
+	// can take. A value of 0 means no timeout.
+	PushMirror int `env:"PUSH_MIRROR" yaml:"push_mirror"`
+}
+
+// CloneLimitsConfig is the configuration for per-IP concurrent clone limits.
+type CloneLimitsConfig struct {
+	// PerIP is the maximum number of concurrent git-upload-pack operations
+	// from a single client IP address. A value of 0 means no limit.
+	PerIP int `env:"PER_IP" yaml:"per_ip"`
+
+	// QueueTimeout is the number of seconds an operation over the limit
+	// waits for another one to finish before it's rejected. A value of 0
+	// rejects it right away.
+	QueueTimeout int `env:"QUEUE_TIMEOUT" yaml:"queue_timeout"`
+
+	// Allowlist is a list of IP addresses and CIDRs exempt from the limit,
+	// e.g. CI runners.
+	Allowlist []string `env:"ALLOWLIST" envSeparator:"," yaml:"allowlist"`
+}
+
+// ArchiveLimitsConfig is the configuration for concurrent git-upload-archive
+// limits. Archives are generated on the fly, which is CPU and memory
+// intensive.
+type ArchiveLimitsConfig struct {
+	// MaxConcurrent is the maximum number of concurrent git-upload-archive
+	// operations on the server. A value of 0 means no limit.
+	MaxConcurrent int `env:"MAX_CONCURRENT" yaml:"max_concurrent"`
+
+	// PerIP is the maximum number of concurrent git-upload-archive
+	// operations from a single client IP address. A value of 0 means no
+	// limit.
+	PerIP int `env:"PER_IP" yaml:"per_ip"`
+
+	// QueueTimeout is the number of seconds an operation over a limit waits
+	// for another one to finish before it's rejected. A value of 0 rejects
+	// it right away.
+	QueueTimeout int `env:"QUEUE_TIMEOUT" yaml:"queue_timeout"`
+}
+
+// Concurrent push modes.
+const (
+	// ConcurrentPushesShared lets pushes to the same repository run at the
+	// same time, git locks the refs they update.
+	ConcurrentPushesShared = "shared"
+	// ConcurrentPushesSerialize queues pushes to the same repository so they
+	// run one at a time.
+	ConcurrentPushesSerialize = "serialize"
+	// ConcurrentPushesReject rejects a push while another one to the same
+	// repository is running.
+	ConcurrentPushesReject = "reject"
+)
+
+// ConcurrentPushesConfig is the configuration for concurrent pushes to the
+// same repository.
+type ConcurrentPushesConfig struct {
+	// Mode is how concurrent pushes to the same repository are handled:
+	// "shared", "serialize", or "reject". It defaults to "shared".
+	Mode string `env:"MODE" yaml:"mode"`
+
+	// QueueTimeout is the number of seconds a serialized push waits for the
+	// others to finish before it's rejected. A value of 0 means no timeout.
+	QueueTimeout int `env:"QUEUE_TIMEOUT" yaml:"queue_timeout"`
+}
+
+// PostReceiveExecConfig is the configuration for the command run in the
+// background after a push, like a CI trigger.
+type PostReceiveExecConfig struct {
+	// Command is the path of the executable run after a push to any
+	// repository. It's skipped if it doesn't exist or isn't executable.
+	Command string `env:"COMMAND" yaml:"command"`
+
+	// Repos maps repository names to the executable run after a push to
+	// them instead of the command.
+	Repos map[string]string `env:"REPOS" envKeyValSeparator:"=" yaml:"repos"`
+
+	// Timeout is the maximum number of seconds the command can take. A value
+	// of 0 means no timeout.
+	Timeout int `env:"TIMEOUT" yaml:"timeout"`
+}
+
+// GeoblockConfig is the configuration for blocking connections by the region
+// of the client. It's disabled unless some regions are denied.
+type GeoblockConfig struct {
+	// Regions maps client IP addresses and CIDRs to regions, e.g.
+	// "203.0.113.0/24" => "NL". It's checked before the resolver.
+	Regions map[string]string `env:"REGIONS" envKeyValSeparator:"=" yaml:"regions"`
+
+	// Resolver is the path of an executable run with a client IP address as
+	// its argument, printing its region, e.g. a GeoIP database lookup.
+	// Regions are cached for 10 minutes.
+	Resolver string `env:"RESOLVER" yaml:"resolver"`
+
+	// DenyRegions is the list of regions connections are denied from.
+	DenyRegions []string `env:"DENY_REGIONS" envSeparator:"," yaml:"deny_regions"`
+
+	// DenyUnresolved is whether connections from addresses without a region
+	// are denied too.
+	DenyUnresolved bool `env:"DENY_UNRESOLVED" yaml:"deny_unresolved"`
+
+	// Allowlist is a list of IP addresses and CIDRs never blocked, e.g.
+	// a VPN.
+	Allowlist []string `env:"ALLOWLIST" envSeparator:"," yaml:"allowlist"`
+}
+
+// ParseIPNet parses an IP address or a CIDR. A single address is a network of
+// its own.
+func ParseIPNet(s string) (*net.IPNet, error) {
+	if ip := net.ParseIP(s); ip != nil {
+		bits := 8 * net.IPv6len
+		if ip4 := ip.To4(); ip4 != nil {
+			ip, bits = ip4, 8*net.IPv4len
+		}
+		return &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}, nil
+	}
+
+	_, ipnet, err := net.ParseCIDR(s)
+	return ipnet, err
+}
+
+// ParseIPNets parses a list of IP addresses and CIDRs with ParseIPNet.
+func ParseIPNets(ss []string) ([]*net.IPNet, error) {
+	nets := make([]*net.IPNet, 0, len(ss))
+	for _, s := range ss {
+		ipnet, err := ParseIPNet(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid address %q: %w", s, err)
+		}
+		nets = append(nets, ipnet)
+	}
+
+	return nets, nil
+}
+
+// RepoLimitsConfig is the configuration for per-user repository creation
+// limits. Admins are exempt.
+type RepoLimitsConfig struct {
+	// CreatePerWindow is the maximum number of repositories a user can create
+	// within a window. A value of 0 means no limit.
+	CreatePerWindow int `env:"CREATE_PER_WINDOW" yaml:"create_per_window"`
+
+	// Window is the length of the window in seconds.
+	Window int `env:"WINDOW" yaml:"window"`
+}
+
+// Automatic description sources.
+const (
+	// AutoDescriptionCommit takes the description from the subject of the
+	// first commit.
+	AutoDescriptionCommit = "commit"
+	// AutoDescriptionFile takes the description from the first line of a
+	// file in the repository.
+	AutoDescriptionFile = "file"
+)
+
+// AutoDescriptionConfig is the configuration for setting the description of
+// repositories without one on their initial push.
+type AutoDescriptionConfig struct {
+	// Source is where the description is taken from. Valid values are
+	// "commit" and "file". Leave it empty to disable automatic descriptions.
+	Source string `env:"SOURCE" yaml:"source"`
+
+	// File is the path of the file in the repository to take the description
+	// from when Source is "file".
+	File string `env:"FILE" yaml:"file"`
+}
+
+// AutoReadmeConfig is the configuration for generating a README in new,
+// empty repositories.
+type AutoReadmeConfig struct {
+	// Enabled is whether repositories created from the command line or the
+	// API get a generated README by default.
+	Enabled bool `env:"ENABLED" yaml:"enabled"`
+}
+
+// InitialPushConfig is the configuration for the initial push to an empty
+// repository.
+type InitialPushConfig struct {
+	// DefaultBranch is the branch HEAD points to after the initial push, if
+	// the push creates it. Leave it empty to keep HEAD as is.
+	DefaultBranch string `env:"DEFAULT_BRANCH" yaml:"default_branch"`
+}
+
+// ReviewConfig is the configuration for proposing changes for review by
+// pushing to a magic ref.
+type ReviewConfig struct {
+	// RefPrefix is the prefix of the refs pushes propose a change to a branch
+	// to, instead of updating it, like "refs/for/" for pushes to
+	// refs/for/main. Leave it empty to disable reviews.
+	RefPrefix string `env:"REF_PREFIX" yaml:"ref_prefix"`
+}
+
+// PolicyWarningsConfig is the configuration for the summary of policy
+// violations shown to pushers when policies only warn.
+type PolicyWarningsConfig struct {
+	// Banner is whether to show a banner listing the policies a push
+	// violated in warn-only mode.
+	Banner bool `env:"BANNER" yaml:"banner"`
+
+	// Guidance is shown in the banner, like a date the policies will be
+	// enforced or a link to their documentation.
+	Guidance string `env:"GUIDANCE" yaml:"guidance"`
+}
+
+// Server roles.
+const (
+	// RolePrimary is the role of a server that accepts reads and writes.
+	RolePrimary = "primary"
+	// RoleReplica is the role of a server that only serves reads from storage
+	// shared with a primary.
+	RoleReplica = "replica"
+)
+
+// ReplicationConfig is the configuration for running multiple servers on the
+// same repository storage and database.
+type ReplicationConfig struct {
+	// Role is the role of the server. Valid values are "primary" and
+	// "replica". Replicas reject pushes and repository changes, and don't run
+	// cron jobs.
+	Role string `env:"ROLE" yaml:"role"`
+
+	// PrimarySSHURL is the public SSH URL of the primary. SSH pushes to a
+	// replica are pointed to it.
+	PrimarySSHURL string `env:"PRIMARY_SSH_URL" yaml:"primary_ssh_url"`
+
+	// PrimaryHTTPURL is the public HTTP URL of the primary. HTTP pushes to a
+	// replica are redirected to it.
+	PrimaryHTTPURL string `env:"PRIMARY_HTTP_URL" yaml:"primary_http_url"`
+}
+
+// IsReplica returns whether the server is a read-only replica.
+func (c ReplicationConfig) IsReplica() bool {
+	return c.Role == RoleReplica
+}
+
+// TUIConfig is the configuration for the SSH TUI.
+type TUIConfig struct {
+	// Enabled is whether interactive SSH sessions open the TUI. When
+	// disabled, they get the list of commands instead, and commands run even
+	// when a pty is requested.
+	Enabled bool `env:"ENABLED" yaml:"enabled"`
+
+	// HomepageRepo is the repository whose README is shown as the server
+	// homepage when users connect to the TUI.
+	HomepageRepo string `env:"HOMEPAGE_REPO" yaml:"homepage_repo"`
+
+	// HomepageFile is the path to a markdown file shown as the server
+	// homepage when users connect to the TUI. It takes precedence over
+	// HomepageRepo.
+	HomepageFile string `env:"HOMEPAGE_FILE" yaml:"homepage_file"`
+
+	// MaxRepos is the number of repositories loaded before the repository
+	// list is shown. The rest are loaded in the background, in batches of
+	// the same size. Zero loads all of them at once.
+	MaxRepos int `env:"MAX_REPOS" yaml:"max_repos"`
+
+	// IdleTimeout is the number of seconds a TUI session can go without input
+	// before it is closed. Zero disables it, leaving only the SSH idle
+	// timeout.
+	IdleTimeout int `env:"IDLE_TIMEOUT" yaml:"idle_timeout"`
+}
+
+// HasHomepage returns whether a server homepage is configured.
+func (c TUIConfig) HasHomepage() bool {
+	return c.HomepageRepo != "" || c.HomepageFile != ""
+}
+
 // JobsConfig is the configuration for cron jobs.
 type JobsConfig struct {
-	MirrorPull string `env:"MIRROR_PULL" yaml:"mirror_pull"`
+	MirrorPull  string `env:"MIRROR_PULL" yaml:"mirror_pull"`
+	PushMirror  string `env:"PUSH_MIRROR" yaml:"push_mirror"`
+	CommitGraph string `env:"COMMIT_GRAPH" yaml:"commit_graph"`
+	Prune       string `env:"PRUNE" yaml:"prune"`
+	RepoSizes   string `env:"REPO_SIZES" yaml:"repo_sizes"`
+}
+
+// RepoSizesConfig is the configuration for the cached sizes of repositories
+// on disk.
+type RepoSizesConfig struct {
+	// MaxAge is the number of seconds a cached size is used before it's
+	// recomputed. Sizes are also recomputed after each push. A value of 0
+	// means cached sizes never expire.
+	MaxAge int `env:"MAX_AGE" yaml:"max_age"`
+}
+
+// CommitGraphConfig is the configuration for writing the git commit-graph of
+// repositories, which speeds up history walks like logs and commit counts.
+type CommitGraphConfig struct {
+	// Enabled is whether commit-graphs are written. When disabled, existing
+	// commit-graphs are still used.
+	Enabled bool `env:"ENABLED" yaml:"enabled"`
+
+	// AfterPush is whether to update the commit-graph of a repository after
+	// each push. Otherwise only the commit-graph job updates them.
+	AfterPush bool `env:"AFTER_PUSH" yaml:"after_push"`
 }
 
 // Config is the configuration for Soft Serve.
@@ -120,6 +1160,11 @@ type Config struct {
 	// Name is the name of the server.
 	Name string `env:"NAME" yaml:"name"`
 
+	// PublicHost is the public hostname of the server. When set, it's used to
+	// build the public URLs of the SSH, HTTP, and Git daemon servers that are
+	// left at their defaults. Explicit public URLs take precedence.
+	PublicHost string `env:"PUBLIC_HOST" yaml:"public_host"`
+
 	// SSH is the configuration for the SSH server.
 	SSH SSHConfig `envPrefix:"SSH_" yaml:"ssh"`
 
@@ -132,18 +1177,100 @@ type Config struct {
 	// Stats is the configuration for the stats server.
 	Stats StatsConfig `envPrefix:"STATS_" yaml:"stats"`
 
+	// Tracing is the configuration for exporting traces.
+	Tracing TracingConfig `envPrefix:"TRACING_" yaml:"tracing"`
+
+	// Pack is the configuration of the packs served to clients.
+	Pack PackConfig `envPrefix:"PACK_" yaml:"pack"`
+
+	// Profiling is the configuration for the profiling server.
+	Profiling ProfilingConfig `envPrefix:"PROFILING_" yaml:"profiling"`
+
 	// Log is the logger configuration.
 	Log LogConfig `envPrefix:"LOG_" yaml:"log"`
 
 	// DB is the database configuration.
 	DB DBConfig `envPrefix:"DB_" yaml:"db"`
 
+	// Audit is the configuration for the rotation of the audit log.
+	Audit AuditConfig `envPrefix:"AUDIT_" yaml:"audit"`
+
 	// LFS is the configuration for Git LFS.
 	LFS LFSConfig `envPrefix:"LFS_" yaml:"lfs"`
 
 	// Jobs is the configuration for cron jobs
 	Jobs JobsConfig `envPrefix:"JOBS_" yaml:"jobs"`
 
+	// Access is the access control configuration.
+	Access AccessConfig `envPrefix:"ACCESS_" yaml:"access"`
+
+	// Timeouts is the configuration for git operation timeouts.
+	Timeouts TimeoutsConfig `envPrefix:"TIMEOUTS_" yaml:"timeouts"`
+
+	// CloneLimits is the configuration for per-IP concurrent clone limits.
+	CloneLimits CloneLimitsConfig `envPrefix:"CLONE_LIMITS_" yaml:"clone_limits"`
+
+	// ArchiveLimits is the configuration for concurrent git-upload-archive
+	// limits.
+	ArchiveLimits ArchiveLimitsConfig `envPrefix:"ARCHIVE_LIMITS_" yaml:"archive_limits"`
+
+	// ConcurrentPushes is the configuration for concurrent pushes to the
+	// same repository.
+	ConcurrentPushes ConcurrentPushesConfig `envPrefix:"CONCURRENT_PUSHES_" yaml:"concurrent_pushes"`
+
+	// Geoblock is the configuration for blocking connections by region.
+	Geoblock GeoblockConfig `envPrefix:"GEOBLOCK_" yaml:"geoblock"`
+
+	// RepoLimits is the configuration for repository creation limits.
+	RepoLimits RepoLimitsConfig `envPrefix:"REPO_LIMITS_" yaml:"repo_limits"`
+
+	// Quota is the configuration for the disk quota of users.
+	Quota QuotaConfig `envPrefix:"QUOTA_" yaml:"quota"`
+
+	// Import is the configuration for importing repositories.
+	Import ImportConfig `envPrefix:"IMPORT_" yaml:"import"`
+
+	// RepoRenames is the configuration for renamed repositories.
+	RepoRenames RepoRenamesConfig `envPrefix:"REPO_RENAMES_" yaml:"repo_renames"`
+
+	// PostCreate is the configuration for the post-create hook.
+	PostCreate PostCreateConfig `envPrefix:"POST_CREATE_" yaml:"post_create"`
+
+	// PostReceiveExec is the configuration for the command run after a
+	// push.
+	PostReceiveExec PostReceiveExecConfig `envPrefix:"POST_RECEIVE_EXEC_" yaml:"post_receive_exec"`
+
+	// AutoDescription is the configuration for automatic repository
+	// descriptions.
+	AutoDescription AutoDescriptionConfig `envPrefix:"AUTO_DESCRIPTION_" yaml:"auto_description"`
+
+	// AutoReadme is the configuration for generated READMEs in new
+	// repositories.
+	AutoReadme AutoReadmeConfig `envPrefix:"AUTO_README_" yaml:"auto_readme"`
+
+	// InitialPush is the configuration for the initial push to empty
+	// repositories.
+	InitialPush InitialPushConfig `envPrefix:"INITIAL_PUSH_" yaml:"initial_push"`
+
+	// Review is the configuration for proposing changes for review.
+	Review ReviewConfig `envPrefix:"REVIEW_" yaml:"review"`
+
+	// PolicyWarnings is the configuration for the summary of warn-only
+	// policy violations.
+	PolicyWarnings PolicyWarningsConfig `envPrefix:"POLICY_WARNINGS_" yaml:"policy_warnings"`
+
+	// CommitGraph is the configuration for writing commit-graphs.
+	CommitGraph CommitGraphConfig `envPrefix:"COMMIT_GRAPH_" yaml:"commit_graph"`
+
+	// RepoSizes is the configuration for the cached repository sizes.
+	RepoSizes RepoSizesConfig `envPrefix:"REPO_SIZES_" yaml:"repo_sizes"`
+
+	// Replication is the configuration for primary and replica servers.
+	Replication ReplicationConfig `envPrefix:"REPLICATION_" yaml:"replication"`
+
+	// TUI is the configuration for the SSH TUI.
+	TUI TUIConfig `envPrefix:"TUI_" yaml:"tui"`
+
 	// InitialAdminKeys is a list of public keys that will be added to the list of admins.
 	InitialAdminKeys []string `env:"INITIAL_ADMIN_KEYS" envSeparator:"\n" yaml:"initial_admin_keys"`
 
@@ -161,9 +1288,13 @@ func (c *Config) Environ() []string {
 	}
 
 	// TODO: do this dynamically
+	// The import credentials and the profiling token are left out on
+	// purpose: the environment is passed to git, hooks, and custom commands,
+	// and only the server uses them.
 	envs = append(envs, []string{
 		fmt.Sprintf("SOFT_SERVE_DATA_PATH=%s", c.DataPath),
 		fmt.Sprintf("SOFT_SERVE_NAME=%s", c.Name),
+		fmt.Sprintf("SOFT_SERVE_PUBLIC_HOST=%s", c.PublicHost),
 		fmt.Sprintf("SOFT_SERVE_INITIAL_ADMIN_KEYS=%s", strings.Join(c.InitialAdminKeys, "\n")),
 		fmt.Sprintf("SOFT_SERVE_SSH_LISTEN_ADDR=%s", c.SSH.ListenAddr),
 		fmt.Sprintf("SOFT_SERVE_SSH_PUBLIC_URL=%s", c.SSH.PublicURL),
@@ -171,28 +1302,155 @@ func (c *Config) Environ() []string {
 		fmt.Sprintf("SOFT_SERVE_SSH_CLIENT_KEY_PATH=%s", c.SSH.ClientKeyPath),
 		fmt.Sprintf("SOFT_SERVE_SSH_MAX_TIMEOUT=%d", c.SSH.MaxTimeout),
 		fmt.Sprintf("SOFT_SERVE_SSH_IDLE_TIMEOUT=%d", c.SSH.IdleTimeout),
+		fmt.Sprintf("SOFT_SERVE_SSH_PRE_AUTH_TIMEOUT=%d", c.SSH.PreAuthTimeout),
+		fmt.Sprintf("SOFT_SERVE_SSH_MAX_STARTUPS=%s", c.SSH.MaxStartups),
+		fmt.Sprintf("SOFT_SERVE_SSH_STRICT_USERNAMES=%t", c.SSH.StrictUsernames),
+		fmt.Sprintf("SOFT_SERVE_SSH_ALLOWED_USERNAMES=%s", strings.Join(c.SSH.AllowedUsernames, ",")),
+		fmt.Sprintf("SOFT_SERVE_SSH_ALLOWED_COMMANDS=%s", strings.Join(c.SSH.AllowedCommands, ",")),
+		fmt.Sprintf("SOFT_SERVE_SSH_SOURCES=%s", joinMap(c.SSH.Sources)),
+		fmt.Sprintf("SOFT_SERVE_SSH_LISTENERS=%s", joinMap(c.SSH.Listeners)),
+		fmt.Sprintf("SOFT_SERVE_SSH_PROXY_PROTOCOL=%t", c.SSH.ProxyProtocol),
+		fmt.Sprintf("SOFT_SERVE_SSH_TRUSTED_PROXIES=%s", strings.Join(c.SSH.TrustedProxies, ",")),
+		fmt.Sprintf("SOFT_SERVE_SSH_INVITES=%t", c.SSH.Invites),
+		fmt.Sprintf("SOFT_SERVE_SSH_INTERACTIVE_MAX_FAILURES=%d", c.SSH.InteractiveMaxFailures),
+		fmt.Sprintf("SOFT_SERVE_SSH_INTERACTIVE_LOCKOUT=%d", c.SSH.InteractiveLockout),
+		fmt.Sprintf("SOFT_SERVE_SSH_DEPRECATED_ALGORITHMS=%s", strings.Join(c.SSH.DeprecatedAlgorithms, ",")),
+		fmt.Sprintf("SOFT_SERVE_SSH_DEPRECATED_ALGORITHMS_ACTION=%s", c.SSH.DeprecatedAlgorithmsAction),
+		fmt.Sprintf("SOFT_SERVE_GIT_ENABLED=%t", c.Git.Enabled),
 		fmt.Sprintf("SOFT_SERVE_GIT_LISTEN_ADDR=%s", c.Git.ListenAddr),
 		fmt.Sprintf("SOFT_SERVE_GIT_PUBLIC_URL=%s", c.Git.PublicURL),
 		fmt.Sprintf("SOFT_SERVE_GIT_MAX_TIMEOUT=%d", c.Git.MaxTimeout),
 		fmt.Sprintf("SOFT_SERVE_GIT_IDLE_TIMEOUT=%d", c.Git.IdleTimeout),
 		fmt.Sprintf("SOFT_SERVE_GIT_MAX_CONNECTIONS=%d", c.Git.MaxConnections),
+		fmt.Sprintf("SOFT_SERVE_GIT_PROXY_PROTOCOL=%t", c.Git.ProxyProtocol),
+		fmt.Sprintf("SOFT_SERVE_GIT_TRUSTED_PROXIES=%s", strings.Join(c.Git.TrustedProxies, ",")),
 		fmt.Sprintf("SOFT_SERVE_HTTP_LISTEN_ADDR=%s", c.HTTP.ListenAddr),
 		fmt.Sprintf("SOFT_SERVE_HTTP_TLS_KEY_PATH=%s", c.HTTP.TLSKeyPath),
 		fmt.Sprintf("SOFT_SERVE_HTTP_TLS_CERT_PATH=%s", c.HTTP.TLSCertPath),
 		fmt.Sprintf("SOFT_SERVE_HTTP_PUBLIC_URL=%s", c.HTTP.PublicURL),
+		fmt.Sprintf("SOFT_SERVE_HTTP_PROXY_PROTOCOL=%t", c.HTTP.ProxyProtocol),
+		fmt.Sprintf("SOFT_SERVE_HTTP_TRUSTED_PROXIES=%s", strings.Join(c.HTTP.TrustedProxies, ",")),
+		fmt.Sprintf("SOFT_SERVE_HTTP_ERROR_HELP=%s", c.HTTP.ErrorHelp),
+		fmt.Sprintf("SOFT_SERVE_HTTP_ERROR_PAGE=%s", c.HTTP.ErrorPage),
+		fmt.Sprintf("SOFT_SERVE_HTTP_BROWSE=%t", c.HTTP.Browse),
 		fmt.Sprintf("SOFT_SERVE_STATS_LISTEN_ADDR=%s", c.Stats.ListenAddr),
+		fmt.Sprintf("SOFT_SERVE_STATS_EXPORTERS=%s", strings.Join(c.Stats.Exporters, ",")),
+		fmt.Sprintf("SOFT_SERVE_STATS_OTLP_ENDPOINT=%s", c.Stats.OTLP.Endpoint),
+		fmt.Sprintf("SOFT_SERVE_STATS_OTLP_PROTOCOL=%s", c.Stats.OTLP.Protocol),
+		fmt.Sprintf("SOFT_SERVE_STATS_OTLP_INTERVAL=%d", c.Stats.OTLP.Interval),
+		fmt.Sprintf("SOFT_SERVE_STATS_SEPARATE_ANONYMOUS=%t", c.Stats.SeparateAnonymous),
+		fmt.Sprintf("SOFT_SERVE_TRACING_ENABLED=%t", c.Tracing.Enabled),
+		fmt.Sprintf("SOFT_SERVE_TRACING_ENDPOINT=%s", c.Tracing.Endpoint),
+		fmt.Sprintf("SOFT_SERVE_TRACING_PROTOCOL=%s", c.Tracing.Protocol),
+		fmt.Sprintf("SOFT_SERVE_TRACING_SAMPLE_RATIO=%g", c.Tracing.SampleRatio),
+		fmt.Sprintf("SOFT_SERVE_PACK_COMPRESSION=%d", c.Pack.Compression),
+		fmt.Sprintf("SOFT_SERVE_PACK_WINDOW=%d", c.Pack.Window),
+		fmt.Sprintf("SOFT_SERVE_PROFILING_ENABLED=%t", c.Profiling.Enabled),
+		fmt.Sprintf("SOFT_SERVE_PROFILING_LISTEN_ADDR=%s", c.Profiling.ListenAddr),
 		fmt.Sprintf("SOFT_SERVE_LOG_FORMAT=%s", c.Log.Format),
 		fmt.Sprintf("SOFT_SERVE_LOG_TIME_FORMAT=%s", c.Log.TimeFormat),
+		fmt.Sprintf("SOFT_SERVE_LOG_GIT_STDERR=%t", c.Log.GitStderr),
+		fmt.Sprintf("SOFT_SERVE_LOG_GIT_ERROR_DETAILS=%t", c.Log.GitErrorDetails),
+		fmt.Sprintf("SOFT_SERVE_LOG_SAMPLE_RATE=%d", c.Log.SampleRate),
+		fmt.Sprintf("SOFT_SERVE_LOG_RATE_LIMIT=%d", c.Log.RateLimit),
+		fmt.Sprintf("SOFT_SERVE_LOG_SYSLOG_ADDRESS=%s", c.Log.Syslog.Address),
+		fmt.Sprintf("SOFT_SERVE_LOG_SYSLOG_NETWORK=%s", c.Log.Syslog.Network),
+		fmt.Sprintf("SOFT_SERVE_LOG_SYSLOG_FACILITY=%s", c.Log.Syslog.Facility),
+		fmt.Sprintf("SOFT_SERVE_LOG_SYSLOG_APP_NAME=%s", c.Log.Syslog.AppName),
+		fmt.Sprintf("SOFT_SERVE_LOG_SYSLOG_ONLY=%t", c.Log.Syslog.Only),
+		fmt.Sprintf("SOFT_SERVE_LOG_SYSLOG_BUFFER_SIZE=%d", c.Log.Syslog.BufferSize),
+		fmt.Sprintf("SOFT_SERVE_LOG_SYSLOG_DROP_POLICY=%s", c.Log.Syslog.DropPolicy),
 		fmt.Sprintf("SOFT_SERVE_DB_DRIVER=%s", c.DB.Driver),
 		fmt.Sprintf("SOFT_SERVE_DB_DATA_SOURCE=%s", c.DB.DataSource),
+		fmt.Sprintf("SOFT_SERVE_DB_MAX_OPEN_CONNS=%d", c.DB.MaxOpenConns),
+		fmt.Sprintf("SOFT_SERVE_DB_MAX_IDLE_CONNS=%d", c.DB.MaxIdleConns),
+		fmt.Sprintf("SOFT_SERVE_DB_CONN_MAX_LIFETIME=%d", c.DB.ConnMaxLifetime),
+		fmt.Sprintf("SOFT_SERVE_DB_RETRIES=%d", c.DB.Retries),
+		fmt.Sprintf("SOFT_SERVE_DB_RETRY_TIMEOUT=%d", c.DB.RetryTimeout),
+		fmt.Sprintf("SOFT_SERVE_DB_RETENTION_AUDIT_EVENTS=%d", c.DB.Retention.AuditEvents),
+		fmt.Sprintf("SOFT_SERVE_DB_RETENTION_CLONE_EVENTS=%d", c.DB.Retention.CloneEvents),
+		fmt.Sprintf("SOFT_SERVE_DB_RETENTION_MIRROR_SYNCS=%d", c.DB.Retention.MirrorSyncs),
+		fmt.Sprintf("SOFT_SERVE_AUDIT_MAX_EVENTS=%d", c.Audit.MaxEvents),
+		fmt.Sprintf("SOFT_SERVE_AUDIT_SEGMENTS_PATH=%s", c.Audit.SegmentsPath),
+		fmt.Sprintf("SOFT_SERVE_AUDIT_SHIP_COMMAND=%s", c.Audit.ShipCommand),
+		fmt.Sprintf("SOFT_SERVE_AUDIT_SEGMENT_RETENTION=%d", c.Audit.SegmentRetention),
 		fmt.Sprintf("SOFT_SERVE_LFS_ENABLED=%t", c.LFS.Enabled),
 		fmt.Sprintf("SOFT_SERVE_LFS_SSH_ENABLED=%t", c.LFS.SSHEnabled),
 		fmt.Sprintf("SOFT_SERVE_JOBS_MIRROR_PULL=%s", c.Jobs.MirrorPull),
+		fmt.Sprintf("SOFT_SERVE_JOBS_PUSH_MIRROR=%s", c.Jobs.PushMirror),
+		fmt.Sprintf("SOFT_SERVE_JOBS_COMMIT_GRAPH=%s", c.Jobs.CommitGraph),
+		fmt.Sprintf("SOFT_SERVE_JOBS_PRUNE=%s", c.Jobs.Prune),
+		fmt.Sprintf("SOFT_SERVE_JOBS_REPO_SIZES=%s", c.Jobs.RepoSizes),
+		fmt.Sprintf("SOFT_SERVE_REPO_SIZES_MAX_AGE=%d", c.RepoSizes.MaxAge),
+		fmt.Sprintf("SOFT_SERVE_ACCESS_STRICT=%t", c.Access.Strict),
+		fmt.Sprintf("SOFT_SERVE_ACCESS_PUBLIC_REPOS=%s", strings.Join(c.Access.PublicRepos, ",")),
+		fmt.Sprintf("SOFT_SERVE_ACCESS_NAMESPACE_VISIBILITY=%s", joinMap(c.Access.NamespaceVisibility)),
+		fmt.Sprintf("SOFT_SERVE_ACCESS_NEW_REPO_VISIBILITY=%s", c.Access.NewRepoVisibility),
+		fmt.Sprintf("SOFT_SERVE_ACCESS_CREATOR_ACCESS=%s", c.Access.CreatorAccess),
+		fmt.Sprintf("SOFT_SERVE_ACCESS_DEFAULT_COLLABORATORS=%s", joinMap(c.Access.DefaultCollaborators)),
+		fmt.Sprintf("SOFT_SERVE_ACCESS_CAPABILITIES=%s", joinMap(c.Access.Capabilities)),
+		fmt.Sprintf("SOFT_SERVE_ACCESS_ON_BACKEND_ERROR=%s", c.Access.OnBackendError),
+		fmt.Sprintf("SOFT_SERVE_TIMEOUTS_UPLOAD_PACK=%d", c.Timeouts.UploadPack),
+		fmt.Sprintf("SOFT_SERVE_TIMEOUTS_RECEIVE_PACK=%d", c.Timeouts.ReceivePack),
+		fmt.Sprintf("SOFT_SERVE_TIMEOUTS_HOOK=%d", c.Timeouts.Hook),
+		fmt.Sprintf("SOFT_SERVE_TIMEOUTS_REPO_LOCK=%d", c.Timeouts.RepoLock),
+		fmt.Sprintf("SOFT_SERVE_TIMEOUTS_PUSH_MIRROR=%d", c.Timeouts.PushMirror),
+		fmt.Sprintf("SOFT_SERVE_CLONE_LIMITS_PER_IP=%d", c.CloneLimits.PerIP),
+		fmt.Sprintf("SOFT_SERVE_CLONE_LIMITS_QUEUE_TIMEOUT=%d", c.CloneLimits.QueueTimeout),
+		fmt.Sprintf("SOFT_SERVE_CLONE_LIMITS_ALLOWLIST=%s", strings.Join(c.CloneLimits.Allowlist, ",")),
+		fmt.Sprintf("SOFT_SERVE_ARCHIVE_LIMITS_MAX_CONCURRENT=%d", c.ArchiveLimits.MaxConcurrent),
+		fmt.Sprintf("SOFT_SERVE_ARCHIVE_LIMITS_PER_IP=%d", c.ArchiveLimits.PerIP),
+		fmt.Sprintf("SOFT_SERVE_ARCHIVE_LIMITS_QUEUE_TIMEOUT=%d", c.ArchiveLimits.QueueTimeout),
+		fmt.Sprintf("SOFT_SERVE_CONCURRENT_PUSHES_MODE=%s", c.ConcurrentPushes.Mode),
+		fmt.Sprintf("SOFT_SERVE_CONCURRENT_PUSHES_QUEUE_TIMEOUT=%d", c.ConcurrentPushes.QueueTimeout),
+		fmt.Sprintf("SOFT_SERVE_GEOBLOCK_REGIONS=%s", joinMap(c.Geoblock.Regions)),
+		fmt.Sprintf("SOFT_SERVE_GEOBLOCK_RESOLVER=%s", c.Geoblock.Resolver),
+		fmt.Sprintf("SOFT_SERVE_GEOBLOCK_DENY_REGIONS=%s", strings.Join(c.Geoblock.DenyRegions, ",")),
+		fmt.Sprintf("SOFT_SERVE_GEOBLOCK_DENY_UNRESOLVED=%t", c.Geoblock.DenyUnresolved),
+		fmt.Sprintf("SOFT_SERVE_GEOBLOCK_ALLOWLIST=%s", strings.Join(c.Geoblock.Allowlist, ",")),
+		fmt.Sprintf("SOFT_SERVE_REPO_LIMITS_CREATE_PER_WINDOW=%d", c.RepoLimits.CreatePerWindow),
+		fmt.Sprintf("SOFT_SERVE_REPO_LIMITS_WINDOW=%d", c.RepoLimits.Window),
+		fmt.Sprintf("SOFT_SERVE_QUOTA_DEFAULT=%s", c.Quota.Default),
+		fmt.Sprintf("SOFT_SERVE_QUOTA_USERS=%s", joinMap(c.Quota.Users)),
+		fmt.Sprintf("SOFT_SERVE_QUOTA_WARN_PERCENT=%d", c.Quota.WarnPercent),
+		fmt.Sprintf("SOFT_SERVE_REPO_RENAMES_GRACE_PERIOD=%d", c.RepoRenames.GracePeriod),
+		fmt.Sprintf("SOFT_SERVE_POST_CREATE_HOOK=%s", c.PostCreate.Hook),
+		fmt.Sprintf("SOFT_SERVE_POST_CREATE_ROLLBACK=%t", c.PostCreate.Rollback),
+		fmt.Sprintf("SOFT_SERVE_POST_RECEIVE_EXEC_COMMAND=%s", c.PostReceiveExec.Command),
+		fmt.Sprintf("SOFT_SERVE_POST_RECEIVE_EXEC_REPOS=%s", joinMap(c.PostReceiveExec.Repos)),
+		fmt.Sprintf("SOFT_SERVE_POST_RECEIVE_EXEC_TIMEOUT=%d", c.PostReceiveExec.Timeout),
+		fmt.Sprintf("SOFT_SERVE_AUTO_DESCRIPTION_SOURCE=%s", c.AutoDescription.Source),
+		fmt.Sprintf("SOFT_SERVE_AUTO_DESCRIPTION_FILE=%s", c.AutoDescription.File),
+		fmt.Sprintf("SOFT_SERVE_AUTO_README_ENABLED=%t", c.AutoReadme.Enabled),
+		fmt.Sprintf("SOFT_SERVE_INITIAL_PUSH_DEFAULT_BRANCH=%s", c.InitialPush.DefaultBranch),
+		fmt.Sprintf("SOFT_SERVE_REVIEW_REF_PREFIX=%s", c.Review.RefPrefix),
+		fmt.Sprintf("SOFT_SERVE_POLICY_WARNINGS_BANNER=%t", c.PolicyWarnings.Banner),
+		fmt.Sprintf("SOFT_SERVE_POLICY_WARNINGS_GUIDANCE=%s", c.PolicyWarnings.Guidance),
+		fmt.Sprintf("SOFT_SERVE_COMMIT_GRAPH_ENABLED=%t", c.CommitGraph.Enabled),
+		fmt.Sprintf("SOFT_SERVE_COMMIT_GRAPH_AFTER_PUSH=%t", c.CommitGraph.AfterPush),
+		fmt.Sprintf("SOFT_SERVE_REPLICATION_ROLE=%s", c.Replication.Role),
+		fmt.Sprintf("SOFT_SERVE_REPLICATION_PRIMARY_SSH_URL=%s", c.Replication.PrimarySSHURL),
+		fmt.Sprintf("SOFT_SERVE_REPLICATION_PRIMARY_HTTP_URL=%s", c.Replication.PrimaryHTTPURL),
+		fmt.Sprintf("SOFT_SERVE_TUI_ENABLED=%t", c.TUI.Enabled),
+		fmt.Sprintf("SOFT_SERVE_TUI_HOMEPAGE_REPO=%s", c.TUI.HomepageRepo),
+		fmt.Sprintf("SOFT_SERVE_TUI_HOMEPAGE_FILE=%s", c.TUI.HomepageFile),
+		fmt.Sprintf("SOFT_SERVE_TUI_MAX_REPOS=%d", c.TUI.MaxRepos),
+		fmt.Sprintf("SOFT_SERVE_TUI_IDLE_TIMEOUT=%d", c.TUI.IdleTimeout),
 	}...)
 
 	return envs
 }
 
+// joinMap formats the given map in the environment variable format.
+func joinMap(m map[string]string) string {
+	kvs := make([]string, 0, len(m))
+	for k, v := range m {
+		kvs = append(kvs, k+"="+v)
+	}
+	sort.Strings(kvs)
+	return strings.Join(kvs, ",")
+}
+
 // IsDebug returns true if the server is running in debug mode.
 func IsDebug() bool {
 	debug, _ := strconv.ParseBool(os.Getenv("SOFT_SERVE_DEBUG"))
@@ -222,6 +1480,12 @@ func parseFile(cfg *Config, path string) error {
 	return cfg.Validate()
 }
 
+// ParseFileAt parses the config from the given file path.
+// This also calls Validate() on the config.
+func (c *Config) ParseFileAt(path string) error {
+	return parseFile(c, path)
+}
+
 // ParseFile parses the config from the default file path.
 // This also calls Validate() on the config.
 func (c *Config) ParseFile() error {
@@ -312,12 +1576,32 @@ func DefaultConfig() *Config {
 		Name:     "Soft Serve",
 		DataPath: DefaultDataPath(),
 		SSH: SSHConfig{
-			ListenAddr:    ":23231",
-			PublicURL:     "ssh://localhost:23231",
-			KeyPath:       filepath.Join("ssh", "soft_serve_host_ed25519"),
-			ClientKeyPath: filepath.Join("ssh", "soft_serve_client_ed25519"),
-			MaxTimeout:    0,
-			IdleTimeout:   10 * 60, // 10 minutes
+			ListenAddr:             ":23231",
+			PublicURL:              "ssh://localhost:23231",
+			KeyPath:                filepath.Join("ssh", "soft_serve_host_ed25519"),
+			ClientKeyPath:          filepath.Join("ssh", "soft_serve_client_ed25519"),
+			MaxTimeout:             0,
+			IdleTimeout:            10 * 60, // 10 minutes
+			PreAuthTimeout:         30,
+			MaxStartups:            "10:30:100",
+			InteractiveMaxFailures: 5,
+			InteractiveLockout:     15 * 60, // 15 minutes
+			AllowedUsernames: []string{
+				"git",
+			},
+			DeprecatedAlgorithms: []string{
+				"diffie-hellman-group1-sha1",
+				"diffie-hellman-group14-sha1",
+				"diffie-hellman-group-exchange-sha1",
+				"3des-cbc",
+				"aes128-cbc",
+				"arcfour",
+				"arcfour128",
+				"arcfour256",
+				"hmac-sha1",
+				"hmac-sha1-96",
+			},
+			DeprecatedAlgorithmsAction: DeprecatedAlgorithmsWarn,
 		},
 		Git: GitConfig{
 			ListenAddr:     ":9418",
@@ -332,22 +1616,115 @@ func DefaultConfig() *Config {
 		},
 		Stats: StatsConfig{
 			ListenAddr: "localhost:23233",
+			Exporters:  []string{StatsExporterPrometheus},
+			OTLP: OTLPConfig{
+				Protocol: OTLPProtocolHTTP,
+				Interval: 60,
+			},
+			SeparateAnonymous: true,
+		},
+		Tracing: TracingConfig{
+			Protocol:    OTLPProtocolHTTP,
+			SampleRatio: 1,
+		},
+		Pack: PackConfig{
+			Compression: DefaultPackCompression,
+			Window:      DefaultPackWindow,
+		},
+		Profiling: ProfilingConfig{
+			ListenAddr: "localhost:23234",
 		},
 		Log: LogConfig{
 			Format:     "text",
 			TimeFormat: time.DateTime,
+			SampleRate: 1,
+			Syslog: SyslogConfig{
+				Network:    "udp",
+				Facility:   "local0",
+				AppName:    "soft-serve",
+				BufferSize: 1000,
+				DropPolicy: SyslogDropNewest,
+			},
 		},
 		DB: DBConfig{
 			Driver: "sqlite",
 			DataSource: "soft-serve.db" +
 				"?_pragma=busy_timeout(5000)&_pragma=foreign_keys(1)",
+			MaxOpenConns:    0,
+			MaxIdleConns:    5,
+			ConnMaxLifetime: 0,
+			Retries:         3,
+			RetryTimeout:    2000, // 2 seconds
+			Retention: RetentionConfig{
+				MirrorSyncs: 30,
+			},
+		},
+		Audit: AuditConfig{
+			SegmentsPath: "audit",
 		},
 		LFS: LFSConfig{
 			Enabled:    true,
 			SSHEnabled: false,
 		},
+		Access: AccessConfig{
+			OnBackendError: FailClosed,
+		},
 		Jobs: JobsConfig{
-			MirrorPull: "@every 10m",
+			MirrorPull:  "@every 10m",
+			PushMirror:  "@every 1m",
+			CommitGraph: "@every 1h",
+			Prune:       "@every 24h",
+		},
+		Timeouts: TimeoutsConfig{
+			UploadPack:  60 * 60, // 1 hour
+			ReceivePack: 60 * 60, // 1 hour
+			Hook:        5 * 60,  // 5 minutes
+			RepoLock:    60,      // 1 minute
+			PushMirror:  10 * 60, // 10 minutes
+		},
+		ConcurrentPushes: ConcurrentPushesConfig{
+			Mode:         ConcurrentPushesShared,
+			QueueTimeout: 60,
+		},
+		Review: ReviewConfig{
+			RefPrefix: "refs/for/",
+		},
+		RepoLimits: RepoLimitsConfig{
+			CreatePerWindow: 0,
+			Window:          60 * 60, // 1 hour
+		},
+		Quota: QuotaConfig{
+			WarnPercent: 90,
+		},
+		RepoRenames: RepoRenamesConfig{
+			GracePeriod: 30,
+		},
+		PostCreate: PostCreateConfig{
+			Hook: filepath.Join("hooks", "post-create"),
+		},
+		PostReceiveExec: PostReceiveExecConfig{
+			Command: filepath.Join("hooks", "post-receive-exec"),
+			Timeout: 10 * 60, // 10 minutes
+		},
+		TUI: TUIConfig{
+			Enabled:  true,
+			MaxRepos: 100,
+		},
+		AutoDescription: AutoDescriptionConfig{
+			File: "DESCRIPTION",
+		},
+		PolicyWarnings: PolicyWarningsConfig{
+			Banner: true,
+		},
+		CommitGraph: CommitGraphConfig{
+			Enabled:   true,
+			AfterPush: true,
+		},
+		RepoSizes: RepoSizesConfig{
+			MaxAge: 60 * 60, // 1 hour
+		},
+		Replication: ReplicationConfig{
+			Role: RolePrimary,
 		},
 	}
 }
@@ -364,9 +1741,27 @@ func (c *Config) Validate() error {
 		c.DataPath = dp
 	}
 
+	if err := c.applyPublicHost(); err != nil {
+		return err
+	}
+
 	c.SSH.PublicURL = strings.TrimSuffix(c.SSH.PublicURL, "/")
 	c.HTTP.PublicURL = strings.TrimSuffix(c.HTTP.PublicURL, "/")
 
+	for _, u := range []struct {
+		name    string
+		value   string
+		schemes []string
+	}{
+		{"ssh.public_url", c.SSH.PublicURL, []string{"ssh"}},
+		{"git.public_url", c.Git.PublicURL, []string{"git"}},
+		{"http.public_url", c.HTTP.PublicURL, []string{"http", "https"}},
+	} {
+		if err := validatePublicURL(u.value, u.schemes...); err != nil {
+			return fmt.Errorf("invalid %s %q: %w", u.name, u.value, err)
+		}
+	}
+
 	if c.SSH.KeyPath != "" && !filepath.IsAbs(c.SSH.KeyPath) {
 		c.SSH.KeyPath = filepath.Join(c.DataPath, c.SSH.KeyPath)
 	}
@@ -383,10 +1778,302 @@ func (c *Config) Validate() error {
 		c.HTTP.TLSCertPath = filepath.Join(c.DataPath, c.HTTP.TLSCertPath)
 	}
 
+	if c.PostCreate.Hook != "" && !filepath.IsAbs(c.PostCreate.Hook) {
+		c.PostCreate.Hook = filepath.Join(c.DataPath, c.PostCreate.Hook)
+	}
+
+	if c.PostReceiveExec.Command != "" && !filepath.IsAbs(c.PostReceiveExec.Command) {
+		c.PostReceiveExec.Command = filepath.Join(c.DataPath, c.PostReceiveExec.Command)
+	}
+
+	for repo, command := range c.PostReceiveExec.Repos {
+		if command != "" && !filepath.IsAbs(command) {
+			c.PostReceiveExec.Repos[repo] = filepath.Join(c.DataPath, command)
+		}
+	}
+
+	if c.Audit.SegmentsPath != "" && !filepath.IsAbs(c.Audit.SegmentsPath) {
+		c.Audit.SegmentsPath = filepath.Join(c.DataPath, c.Audit.SegmentsPath)
+	}
+
+	if c.Audit.ShipCommand != "" && !filepath.IsAbs(c.Audit.ShipCommand) {
+		c.Audit.ShipCommand = filepath.Join(c.DataPath, c.Audit.ShipCommand)
+	}
+
+	if c.Geoblock.Resolver != "" && !filepath.IsAbs(c.Geoblock.Resolver) {
+		c.Geoblock.Resolver = filepath.Join(c.DataPath, c.Geoblock.Resolver)
+	}
+
+	if c.HTTP.ErrorPage != "" && !filepath.IsAbs(c.HTTP.ErrorPage) {
+		c.HTTP.ErrorPage = filepath.Join(c.DataPath, c.HTTP.ErrorPage)
+	}
+
+	if c.TUI.HomepageFile != "" && !filepath.IsAbs(c.TUI.HomepageFile) {
+		c.TUI.HomepageFile = filepath.Join(c.DataPath, c.TUI.HomepageFile)
+	}
+
 	if strings.HasPrefix(c.DB.Driver, "sqlite") && !filepath.IsAbs(c.DB.DataSource) {
 		c.DB.DataSource = filepath.Join(c.DataPath, c.DB.DataSource)
 	}
 
+	if c.DB.MaxOpenConns < 0 || c.DB.MaxIdleConns < 0 || c.DB.ConnMaxLifetime < 0 ||
+		c.DB.Retries < 0 || c.DB.RetryTimeout < 0 {
+		return fmt.Errorf("database pool and retry settings cannot be negative")
+	}
+
+	if c.DB.Retention.AuditEvents < 0 || c.DB.Retention.CloneEvents < 0 || c.DB.Retention.MirrorSyncs < 0 {
+		return fmt.Errorf("database retention settings cannot be negative")
+	}
+
+	if c.Audit.MaxEvents < 0 || c.Audit.SegmentRetention < 0 {
+		return fmt.Errorf("audit log rotation settings cannot be negative")
+	}
+
+	if c.Log.SampleRate < 0 || c.Log.RateLimit < 0 {
+		return fmt.Errorf("log sampling settings cannot be negative")
+	}
+
+	if err := c.Log.Syslog.Validate(); err != nil {
+		return err
+	}
+
+	if err := c.Stats.Validate(); err != nil {
+		return err
+	}
+
+	if err := c.Tracing.Validate(); err != nil {
+		return err
+	}
+
+	if err := c.Pack.Validate(); err != nil {
+		return err
+	}
+
+	if c.Profiling.Enabled && c.Profiling.Token == "" {
+		return fmt.Errorf("profiling.token is required when profiling is enabled")
+	}
+
+	if c.Timeouts.UploadPack < 0 || c.Timeouts.ReceivePack < 0 || c.Timeouts.Hook < 0 || c.Timeouts.RepoLock < 0 ||
+		c.Timeouts.PushMirror < 0 {
+		return fmt.Errorf("timeouts cannot be negative")
+	}
+
+	if c.RepoSizes.MaxAge < 0 {
+		return fmt.Errorf("repo_sizes.max_age cannot be negative")
+	}
+
+	if c.PostReceiveExec.Timeout < 0 {
+		return fmt.Errorf("post_receive_exec.timeout cannot be negative")
+	}
+
+	if _, err := ParseMaxStartups(c.SSH.MaxStartups); err != nil {
+		return err
+	}
+
+	if c.SSH.InteractiveMaxFailures < 0 || c.SSH.InteractiveLockout < 0 {
+		return fmt.Errorf("ssh interactive lockout settings cannot be negative")
+	}
+
+	for _, name := range c.SSH.AllowedCommands {
+		if name == "" || strings.ContainsAny(name, " \t") {
+			return fmt.Errorf("invalid ssh.allowed_commands entry %q", name)
+		}
+	}
+
+	switch c.SSH.DeprecatedAlgorithmsAction {
+	case "", DeprecatedAlgorithmsWarn, DeprecatedAlgorithmsDeny:
+	default:
+		return fmt.Errorf("invalid ssh.deprecated_algorithms_action %q", c.SSH.DeprecatedAlgorithmsAction)
+	}
+
+	if c.TUI.MaxRepos < 0 || c.TUI.IdleTimeout < 0 {
+		return fmt.Errorf("tui limits cannot be negative")
+	}
+
+	if c.CloneLimits.PerIP < 0 || c.CloneLimits.QueueTimeout < 0 {
+		return fmt.Errorf("clone limits cannot be negative")
+	}
+
+	for _, p := range []struct {
+		name    string
+		enabled bool
+		trusted []string
+	}{
+		{"ssh", c.SSH.ProxyProtocol, c.SSH.TrustedProxies},
+		{"git", c.Git.ProxyProtocol, c.Git.TrustedProxies},
+		{"http", c.HTTP.ProxyProtocol, c.HTTP.TrustedProxies},
+	} {
+		if p.enabled && len(p.trusted) == 0 {
+			return fmt.Errorf("%s.trusted_proxies must be set when %s.proxy_protocol is enabled", p.name, p.name)
+		}
+		if _, err := ParseIPNets(p.trusted); err != nil {
+			return fmt.Errorf("invalid %s.trusted_proxies: %w", p.name, err)
+		}
+	}
+
+	for _, a := range c.CloneLimits.Allowlist {
+		if _, err := ParseIPNet(a); err != nil {
+			return fmt.Errorf("invalid clone_limits.allowlist entry %q: %w", a, err)
+		}
+	}
+
+	if c.ArchiveLimits.MaxConcurrent < 0 || c.ArchiveLimits.PerIP < 0 || c.ArchiveLimits.QueueTimeout < 0 {
+		return fmt.Errorf("archive limits cannot be negative")
+	}
+
+	switch c.ConcurrentPushes.Mode {
+	case "", ConcurrentPushesShared, ConcurrentPushesSerialize, ConcurrentPushesReject:
+	default:
+		return fmt.Errorf("concurrent_pushes.mode must be one of %s, %s, or %s", ConcurrentPushesShared, ConcurrentPushesSerialize, ConcurrentPushesReject)
+	}
+
+	if c.ConcurrentPushes.QueueTimeout < 0 {
+		return fmt.Errorf("concurrent_pushes.queue_timeout cannot be negative")
+	}
+
+	if c.RepoLimits.CreatePerWindow < 0 {
+		return fmt.Errorf("repo_limits.create_per_window cannot be negative")
+	}
+
+	if c.RepoLimits.CreatePerWindow > 0 && c.RepoLimits.Window <= 0 {
+		return fmt.Errorf("repo_limits.window must be positive")
+	}
+
+	if _, err := parseQuota(c.Quota.Default); err != nil {
+		return fmt.Errorf("quota.default: %w", err)
+	}
+	for u, q := range c.Quota.Users {
+		if _, err := parseQuota(q); err != nil {
+			return fmt.Errorf("quota.users %q: %w", u, err)
+		}
+	}
+	if c.Quota.WarnPercent < 0 || c.Quota.WarnPercent > 100 {
+		return fmt.Errorf("quota.warn_percent must be between 0 and 100")
+	}
+
+	for host, cred := range c.Import.Credentials {
+		if host == "" || strings.Contains(host, "/") {
+			return fmt.Errorf("invalid import.credentials host %q", host)
+		}
+		if u, _, ok := strings.Cut(cred, ":"); !ok || u == "" {
+			return fmt.Errorf("invalid import.credentials for %q, they must look like \"username:password\"", host)
+		}
+	}
+
+	if c.RepoRenames.GracePeriod < 0 {
+		return fmt.Errorf("repo_renames.grace_period cannot be negative")
+	}
+
+	switch c.AutoDescription.Source {
+	case "", AutoDescriptionCommit:
+	case AutoDescriptionFile:
+		if c.AutoDescription.File == "" {
+			return fmt.Errorf("auto_description.file is required when the source is %q", AutoDescriptionFile)
+		}
+	default:
+		return fmt.Errorf("invalid auto_description source %q", c.AutoDescription.Source)
+	}
+
+	if b := c.InitialPush.DefaultBranch; b != "" && (strings.HasPrefix(b, "-") || strings.HasPrefix(b, "refs/") ||
+		strings.ContainsAny(b, " \t~^:?*[\\") || strings.Contains(b, "..")) {
+		return fmt.Errorf("invalid initial_push.default_branch %q", b)
+	}
+
+	if p := c.Review.RefPrefix; p != "" {
+		if !strings.HasPrefix(p, "refs/") || !strings.HasSuffix(p, "/") || p == "refs/" ||
+			strings.ContainsAny(p, " \t~^:?*[\\") || strings.Contains(p, "..") {
+			return fmt.Errorf("invalid review.ref_prefix %q, it must look like \"refs/for/\"", p)
+		}
+		// Review refs can't shadow the refs a push updates for real.
+		for _, reserved := range []string{"refs/heads/", "refs/tags/", "refs/notes/", "refs/reviews/"} {
+			if strings.HasPrefix(p, reserved) || strings.HasPrefix(reserved, p) {
+				return fmt.Errorf("invalid review.ref_prefix %q, it overlaps %s", p, reserved)
+			}
+		}
+	}
+
+	if c.Replication.Role == "" {
+		c.Replication.Role = RolePrimary
+	}
+	if c.Replication.Role != RolePrimary && c.Replication.Role != RoleReplica {
+		return fmt.Errorf("invalid replication role %q", c.Replication.Role)
+	}
+
+	for addr, level := range c.SSH.Listeners {
+		if _, _, err := net.SplitHostPort(addr); err != nil {
+			return fmt.Errorf("invalid ssh listener %q: %w", addr, err)
+		}
+		if addr == c.SSH.ListenAddr {
+			return fmt.Errorf("invalid ssh listener %q: already the listen address", addr)
+		}
+		if access.ParseAccessLevel(level) < 0 {
+			return fmt.Errorf("invalid ssh listener %q access level %q", addr, level)
+		}
+	}
+
+	for cidr := range c.SSH.Sources {
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			return fmt.Errorf("invalid ssh source %q: %w", cidr, err)
+		}
+	}
+
+	for a := range c.Geoblock.Regions {
+		if _, err := ParseIPNet(a); err != nil {
+			return fmt.Errorf("invalid geoblock.regions entry %q: %w", a, err)
+		}
+	}
+
+	for _, a := range c.Geoblock.Allowlist {
+		if _, err := ParseIPNet(a); err != nil {
+			return fmt.Errorf("invalid geoblock.allowlist entry %q: %w", a, err)
+		}
+	}
+
+	for ns, v := range c.Access.NamespaceVisibility {
+		switch strings.ToLower(v) {
+		case VisibilityPublic, VisibilityPrivate, VisibilityHidden:
+		default:
+			return fmt.Errorf("invalid visibility %q for namespace %q", v, ns)
+		}
+	}
+
+	switch strings.ToLower(c.Access.NewRepoVisibility) {
+	case "", VisibilityPublic, VisibilityPrivate, VisibilityHidden:
+	default:
+		return fmt.Errorf("invalid access.new_repo_visibility %q", c.Access.NewRepoVisibility)
+	}
+
+	if c.Access.CreatorAccessLevel() < 0 {
+		return fmt.Errorf("invalid access.creator_access %q", c.Access.CreatorAccess)
+	}
+
+	for username, level := range c.Access.DefaultCollaborators {
+		if access.ParseAccessLevel(level) < 0 {
+			return fmt.Errorf("invalid access level %q for default collaborator %q", level, username)
+		}
+	}
+
+	for name, level := range c.Access.Capabilities {
+		if _, ok := access.ParseCapability(name); !ok {
+			return fmt.Errorf("invalid access.capabilities entry %q", name)
+		}
+		if access.ParseAccessLevel(level) < access.ReadOnlyAccess {
+			return fmt.Errorf("invalid access level %q for capability %q: must be read-only or higher", level, name)
+		}
+	}
+
+	for _, p := range c.Access.PublicRepos {
+		if _, err := path.Match(p, ""); err != nil {
+			return fmt.Errorf("invalid public repo pattern %q: %w", p, err)
+		}
+	}
+
+	switch c.Access.OnBackendError {
+	case "", FailClosed, FailOpenRead:
+	default:
+		return fmt.Errorf("invalid access.on_backend_error %q", c.Access.OnBackendError)
+	}
+
 	// Validate keys
 	pks := make([]string, 0)
 	for _, key := range parseAuthKeys(c.InitialAdminKeys) {
@@ -399,6 +2086,73 @@ func (c *Config) Validate() error {
 	return nil
 }
 
+// applyPublicHost builds the public URLs that are left at their defaults from
+// the public host and the listen addresses.
+func (c *Config) applyPublicHost() error {
+	if c.PublicHost == "" {
+		return nil
+	}
+
+	if strings.ContainsAny(c.PublicHost, ":/") {
+		return fmt.Errorf("invalid public host %q: must be a hostname without a scheme, port, or path", c.PublicHost)
+	}
+
+	defaults := DefaultConfig()
+	if c.SSH.PublicURL == defaults.SSH.PublicURL {
+		c.SSH.PublicURL = publicURL("ssh", c.PublicHost, c.SSH.ListenAddr, "22")
+	}
+
+	if c.Git.PublicURL == defaults.Git.PublicURL {
+		c.Git.PublicURL = publicURL("git", c.PublicHost, c.Git.ListenAddr, "9418")
+	}
+
+	if c.HTTP.PublicURL == defaults.HTTP.PublicURL {
+		if c.HTTP.TLSKeyPath != "" && c.HTTP.TLSCertPath != "" {
+			c.HTTP.PublicURL = publicURL("https", c.PublicHost, c.HTTP.ListenAddr, "443")
+		} else {
+			c.HTTP.PublicURL = publicURL("http", c.PublicHost, c.HTTP.ListenAddr, "80")
+		}
+	}
+
+	return nil
+}
+
+// publicURL returns a URL for the given host using the port of the listen
+// address. The port is omitted if it's the default port of the scheme.
+func publicURL(scheme, host, listenAddr, defaultPort string) string {
+	_, port, err := net.SplitHostPort(listenAddr)
+	if err != nil || port == "" || port == defaultPort {
+		return fmt.Sprintf("%s://%s", scheme, host)
+	}
+
+	return fmt.Sprintf("%s://%s", scheme, net.JoinHostPort(host, port))
+}
+
+// validatePublicURL returns an error if the given URL isn't an absolute URL
+// with one of the given schemes. Empty URLs are valid.
+func validatePublicURL(s string, schemes ...string) error {
+	if s == "" {
+		return nil
+	}
+
+	u, err := url.Parse(s)
+	if err != nil {
+		return err
+	}
+
+	if u.Host == "" {
+		return fmt.Errorf("missing host")
+	}
+
+	for _, scheme := range schemes {
+		if u.Scheme == scheme {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("scheme must be one of %s", strings.Join(schemes, ", "))
+}
+
 // parseAuthKeys parses authorized keys from either file paths or string authorized_keys.
 func parseAuthKeys(aks []string) []ssh.PublicKey {
 	exist := make(map[string]struct{}, 0)
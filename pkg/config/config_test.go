@@ -2,8 +2,10 @@ package config
 
 import (
 	"os"
+	"strings"
 	"testing"
 
+	"github.com/charmbracelet/soft-serve/pkg/access"
 	"github.com/matryer/is"
 )
 
@@ -56,3 +58,360 @@ func TestValidateInitAdminKeys(t *testing.T) {
 		"ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAINMwLvyV3ouVrTysUYGoJdl5Vgn5BACKov+n9PlzfPwH",
 	})
 }
+
+func TestValidatePublicRepos(t *testing.T) {
+	is := is.New(t)
+	cfg := &Config{
+		DataPath: t.TempDir(),
+		Access: AccessConfig{
+			PublicRepos: []string{"public/*", "dotfiles"},
+		},
+	}
+	is.NoErr(cfg.Validate())
+	is.True(cfg.Access.IsPublicRepo("public/repo1"))
+	is.True(cfg.Access.IsPublicRepo("dotfiles"))
+	is.True(!cfg.Access.IsPublicRepo("internal/repo1"))
+
+	cfg.Access.PublicRepos = []string{"public/["}
+	is.True(cfg.Validate() != nil)
+}
+
+func TestParseSSHSources(t *testing.T) {
+	is := is.New(t)
+	is.NoErr(os.Setenv("SOFT_SERVE_SSH_SOURCES", "10.0.0.0/8=office,fd00::/8=vpn"))
+	t.Cleanup(func() { is.NoErr(os.Unsetenv("SOFT_SERVE_SSH_SOURCES")) })
+	cfg := DefaultConfig()
+	cfg.DataPath = t.TempDir()
+	is.NoErr(cfg.ParseEnv())
+	is.Equal(cfg.SSH.Sources, map[string]string{
+		"10.0.0.0/8": "office",
+		"fd00::/8":   "vpn",
+	})
+
+	cfg.SSH.Sources = map[string]string{"not-a-cidr": "office"}
+	is.True(cfg.Validate() != nil)
+}
+
+func TestCheck(t *testing.T) {
+	is := is.New(t)
+	cfg := DefaultConfig()
+	cfg.DataPath = t.TempDir()
+	is.NoErr(cfg.Validate())
+	is.Equal(len(cfg.Check()), 0)
+
+	cfg.HTTP.ListenAddr = cfg.SSH.ListenAddr
+	cfg.HTTP.TLSKeyPath = "key.pem"
+	cfg.Jobs.MirrorPull = "not a spec"
+	is.Equal(len(cfg.Check()), 3)
+
+	cfg = DefaultConfig()
+	cfg.DataPath = t.TempDir()
+	cfg.SSH.Listeners = map[string]string{cfg.HTTP.ListenAddr: "read-only"}
+	cfg.Profiling.Enabled = true
+	cfg.Profiling.ListenAddr = cfg.Stats.ListenAddr
+	is.Equal(len(cfg.Check()), 2)
+}
+
+func TestDefaultVisibility(t *testing.T) {
+	is := is.New(t)
+	a := AccessConfig{
+		NamespaceVisibility: map[string]string{
+			"*":             VisibilityHidden,
+			"internal/*":    VisibilityPrivate,
+			"internal/docs": VisibilityPublic,
+			"public":        VisibilityPublic,
+		},
+	}
+
+	for repo, want := range map[string]string{
+		"foo":                  VisibilityHidden,
+		"internal/repo":        VisibilityPrivate,
+		"internal/team/repo":   VisibilityPrivate,
+		"internal/docs/readme": VisibilityPublic,
+		"internalish/repo":     VisibilityHidden,
+		"public/repo":          VisibilityPublic,
+	} {
+		vis, _ := a.DefaultVisibility(repo)
+		is.Equal(vis, want)
+	}
+
+	vis, ns := AccessConfig{}.DefaultVisibility("foo")
+	is.Equal(vis, VisibilityPublic)
+	is.Equal(ns, "")
+
+	a = AccessConfig{
+		NamespaceVisibility: map[string]string{"public": VisibilityPublic},
+		NewRepoVisibility:   VisibilityPrivate,
+	}
+	vis, ns = a.DefaultVisibility("foo")
+	is.Equal(vis, VisibilityPrivate)
+	is.Equal(ns, "")
+	vis, _ = a.DefaultVisibility("public/repo")
+	is.Equal(vis, VisibilityPublic)
+
+	cfg := DefaultConfig()
+	cfg.DataPath = t.TempDir()
+	cfg.Access.NamespaceVisibility = map[string]string{"internal": "secret"}
+	is.True(cfg.Validate() != nil)
+	cfg.Access.NamespaceVisibility = nil
+	cfg.Access.NewRepoVisibility = "secret"
+	is.True(cfg.Validate() != nil)
+	cfg.Access.NewRepoVisibility = VisibilityPrivate
+	cfg.Access.CreatorAccess = "owner"
+	is.True(cfg.Validate() != nil)
+	cfg.Access.CreatorAccess = "read-write"
+	cfg.Access.DefaultCollaborators = map[string]string{"ops": "everything"}
+	is.True(cfg.Validate() != nil)
+	cfg.Access.DefaultCollaborators = map[string]string{"ops": "read-only"}
+	is.NoErr(cfg.Validate())
+	cfg.Access.OnBackendError = "fail-open"
+	is.True(cfg.Validate() != nil)
+	cfg.Access.OnBackendError = FailOpenRead
+	is.NoErr(cfg.Validate())
+}
+
+func TestPublicHost(t *testing.T) {
+	is := is.New(t)
+	cfg := DefaultConfig()
+	cfg.DataPath = t.TempDir()
+	cfg.PublicHost = "git.example.com"
+	cfg.SSH.ListenAddr = ":22"
+	cfg.HTTP.PublicURL = "https://code.example.com/"
+	is.NoErr(cfg.Validate())
+	is.Equal(cfg.SSH.PublicURL, "ssh://git.example.com")
+	is.Equal(cfg.Git.PublicURL, "git://git.example.com")
+	is.Equal(cfg.HTTP.PublicURL, "https://code.example.com")
+
+	cfg = DefaultConfig()
+	cfg.DataPath = t.TempDir()
+	cfg.PublicHost = "git.example.com:2222"
+	is.True(cfg.Validate() != nil)
+
+	cfg = DefaultConfig()
+	cfg.DataPath = t.TempDir()
+	cfg.SSH.PublicURL = "localhost:23231"
+	is.True(cfg.Validate() != nil)
+}
+
+func TestParseTimeouts(t *testing.T) {
+	is := is.New(t)
+	is.NoErr(os.Setenv("SOFT_SERVE_TIMEOUTS_UPLOAD_PACK", "30"))
+	t.Cleanup(func() { is.NoErr(os.Unsetenv("SOFT_SERVE_TIMEOUTS_UPLOAD_PACK")) })
+	cfg := DefaultConfig()
+	cfg.DataPath = t.TempDir()
+	is.NoErr(cfg.ParseEnv())
+	is.Equal(cfg.Timeouts.UploadPack, 30)
+	is.Equal(cfg.Timeouts.ReceivePack, 60*60)
+
+	cfg.Timeouts.ReceivePack = -1
+	is.True(cfg.Validate() != nil)
+}
+
+func TestValidateInitialPush(t *testing.T) {
+	is := is.New(t)
+	cfg := DefaultConfig()
+	cfg.DataPath = t.TempDir()
+	is.NoErr(cfg.Validate())
+	cfg.InitialPush.DefaultBranch = "main"
+	is.NoErr(cfg.Validate())
+	cfg.InitialPush.DefaultBranch = "release/v1"
+	is.NoErr(cfg.Validate())
+
+	for _, b := range []string{"refs/heads/main", "-main", "ma in", "a..b", "main:dev"} {
+		cfg.InitialPush.DefaultBranch = b
+		is.True(cfg.Validate() != nil)
+	}
+}
+
+func TestValidateTracing(t *testing.T) {
+	is := is.New(t)
+	cfg := DefaultConfig()
+	cfg.DataPath = t.TempDir()
+	// Tracing is off by default, no endpoint needed.
+	is.NoErr(cfg.Validate())
+
+	cfg.Tracing.Enabled = true
+	is.True(cfg.Validate() != nil)
+	cfg.Tracing.Endpoint = "http://localhost:4318"
+	is.NoErr(cfg.Validate())
+
+	cfg.Tracing.SampleRatio = 1.5
+	is.True(cfg.Validate() != nil)
+	cfg.Tracing.SampleRatio = 0.1
+	cfg.Tracing.Protocol = "udp"
+	is.True(cfg.Validate() != nil)
+}
+
+func TestValidateReviewRefPrefix(t *testing.T) {
+	is := is.New(t)
+	cfg := DefaultConfig()
+	cfg.DataPath = t.TempDir()
+	is.NoErr(cfg.Validate())
+	for _, p := range []string{"", "refs/for/", "refs/drafts/for/"} {
+		cfg.Review.RefPrefix = p
+		is.NoErr(cfg.Validate())
+	}
+
+	for _, p := range []string{"refs/for", "for/", "refs/", "refs/heads/for/", "refs/reviews/", "refs/fo r/"} {
+		cfg.Review.RefPrefix = p
+		is.True(cfg.Validate() != nil)
+	}
+}
+
+func TestAnonymousLabel(t *testing.T) {
+	is := is.New(t)
+	cfg := DefaultConfig()
+	is.Equal(cfg.Stats.AnonymousLabel(true), "true")
+	is.Equal(cfg.Stats.AnonymousLabel(false), "false")
+
+	cfg.Stats.SeparateAnonymous = false
+	is.Equal(cfg.Stats.AnonymousLabel(true), "")
+	is.Equal(cfg.Stats.AnonymousLabel(false), "")
+}
+
+func TestValidateCapabilities(t *testing.T) {
+	is := is.New(t)
+	cfg := DefaultConfig()
+	cfg.DataPath = t.TempDir()
+	is.Equal(cfg.Access.CapabilityLevel(access.CapabilityManageIssues), access.TriageAccess)
+	is.Equal(cfg.Access.CapabilityLevel(access.CapabilityMerge), access.ReadWriteAccess)
+
+	cfg.Access.Capabilities = map[string]string{"manage-releases": "triage"}
+	is.NoErr(cfg.Validate())
+	is.Equal(cfg.Access.CapabilityLevel(access.CapabilityManageReleases), access.TriageAccess)
+
+	cfg.Access.Capabilities = map[string]string{"push": "triage"}
+	is.True(cfg.Validate() != nil)
+	cfg.Access.Capabilities = map[string]string{"merge": "no-access"}
+	is.True(cfg.Validate() != nil)
+	cfg.Access.Capabilities = map[string]string{"merge": "owner"}
+	is.True(cfg.Validate() != nil)
+}
+
+func TestParseMaxStartups(t *testing.T) {
+	cases := []struct {
+		in    string
+		want  MaxStartups
+		valid bool
+	}{
+		{"", MaxStartups{}, true},
+		{"10:30:100", MaxStartups{Start: 10, Rate: 30, Full: 100}, true},
+		{"20", MaxStartups{Start: 20, Rate: 100, Full: 20}, true},
+		{"10:30", MaxStartups{}, false},
+		{"0:30:100", MaxStartups{}, false},
+		{"10:101:100", MaxStartups{}, false},
+		{"10:30:5", MaxStartups{}, false},
+		{"10:-1:100", MaxStartups{}, false},
+		{"ten", MaxStartups{}, false},
+	}
+
+	for _, c := range cases {
+		got, err := ParseMaxStartups(c.in)
+		if (err == nil) != c.valid {
+			t.Errorf("ParseMaxStartups(%q) error = %v, want valid %v", c.in, err, c.valid)
+		}
+		if got != c.want {
+			t.Errorf("ParseMaxStartups(%q) = %+v, want %+v", c.in, got, c.want)
+		}
+	}
+}
+
+func TestUserQuota(t *testing.T) {
+	c := QuotaConfig{
+		Default: "10MiB",
+		Users: map[string]string{
+			"alice": "1GiB",
+			"Bob":   "0",
+		},
+	}
+
+	cases := map[string]int64{
+		"carol": 10 << 20,
+		"alice": 1 << 30,
+		"bob":   0,
+	}
+	for username, want := range cases {
+		if got := c.UserQuota(username); got != want {
+			t.Errorf("UserQuota(%q) = %d, want %d", username, got, want)
+		}
+	}
+}
+
+func TestValidateQuota(t *testing.T) {
+	cases := []struct {
+		quota QuotaConfig
+		valid bool
+	}{
+		{QuotaConfig{}, true},
+		{QuotaConfig{Default: "500MB", WarnPercent: 90}, true},
+		{QuotaConfig{Default: "lots"}, false},
+		{QuotaConfig{Users: map[string]string{"alice": "-1"}}, false},
+		{QuotaConfig{WarnPercent: 101}, false},
+	}
+
+	for _, c := range cases {
+		cfg := &Config{Quota: c.quota}
+		if err := cfg.Validate(); (err == nil) != c.valid {
+			t.Errorf("Validate(%+v) error = %v, want valid %v", c.quota, err, c.valid)
+		}
+	}
+}
+
+func TestValidateImportCredentials(t *testing.T) {
+	cases := []struct {
+		credentials map[string]string
+		valid       bool
+	}{
+		{map[string]string{"git.example.com": "user:token"}, true},
+		{map[string]string{"localhost:8080": "user:"}, true},
+		{map[string]string{"git.example.com": "token"}, false},
+		{map[string]string{"git.example.com": ":token"}, false},
+		{map[string]string{"https://git.example.com/": "user:token"}, false},
+	}
+
+	for _, c := range cases {
+		cfg := &Config{Import: ImportConfig{Credentials: c.credentials}}
+		if err := cfg.Validate(); (err == nil) != c.valid {
+			t.Errorf("Validate(%v) error = %v, want valid %v", c.credentials, err, c.valid)
+		}
+	}
+}
+
+func TestValidateTrustedProxies(t *testing.T) {
+	cases := []struct {
+		http  HTTPConfig
+		valid bool
+	}{
+		{HTTPConfig{}, true},
+		{HTTPConfig{ProxyProtocol: true, TrustedProxies: []string{"10.0.0.0/24", "192.0.2.1"}}, true},
+		{HTTPConfig{ProxyProtocol: true}, false},
+		{HTTPConfig{ProxyProtocol: true, TrustedProxies: []string{"nope"}}, false},
+	}
+
+	for _, c := range cases {
+		cfg := &Config{HTTP: c.http}
+		if err := cfg.Validate(); (err == nil) != c.valid {
+			t.Errorf("Validate(%+v) error = %v, want valid %v", c.http, err, c.valid)
+		}
+	}
+}
+
+func TestEnvironImportCredentials(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Import.Credentials = map[string]string{"git.example.com": "user:token"}
+	for _, env := range cfg.Environ() {
+		if strings.Contains(env, "token") {
+			t.Errorf("Environ() contains the import credentials: %q", env)
+		}
+	}
+}
+
+func TestEnvironProfilingToken(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Profiling.Token = "secret"
+	for _, env := range cfg.Environ() {
+		if strings.HasPrefix(env, "SOFT_SERVE_PROFILING_TOKEN=") || strings.Contains(env, "secret") {
+			t.Errorf("Environ() contains the profiling token: %q", env)
+		}
+	}
+}
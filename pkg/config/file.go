@@ -11,6 +11,11 @@ var configFileTmpl = template.Must(template.New("config").Parse(`# Soft Serve Se
 # This is the name that will be displayed in the UI.
 name: "{{ .Name }}"
 
+# The public hostname of the server. When set, it's used to build the public
+# URLs of the SSH, HTTP, and Git daemon servers that are left at their defaults.
+# Use this when the listen addresses differ from the address clients use.
+#public_host: "{{ .PublicHost }}"
+
 # Logging configuration.
 log:
   # Log format to use. Valid values are "json", "logfmt", and "text".
@@ -20,12 +25,51 @@ log:
   time_format: "{{ .Log.TimeFormat }}"
   # Path to the log file. Leave empty to write to stderr.
   #path: "{{ .Log.Path }}"
+  # Log the stderr output of git commands at debug level.
+  git_stderr: {{ .Log.GitStderr }}
+  # Include a summary of git errors in the errors returned to clients. Server
+  # paths are removed from the summary.
+  git_error_details: {{ .Log.GitErrorDetails }}
+  # Log one in every sample_rate successful SSH sessions and HTTP requests.
+  # Failures are always logged. Send SIGHUP to the server to reload this.
+  sample_rate: {{ .Log.SampleRate }}
+  # The maximum number of successful SSH sessions and HTTP requests logged per
+  # second. A value of 0 means no limit.
+  rate_limit: {{ .Log.RateLimit }}
+  # Send SSH sessions, HTTP requests, and audit events to syslog in RFC 5424
+  # format. Messages are buffered and dropped, never blocking git operations,
+  # while the endpoint is unavailable.
+  syslog:
+    # The address of the syslog endpoint, host:port or a unix socket path.
+    # Leave empty to disable syslog.
+    address: "{{ .Log.Syslog.Address }}"
+    # The transport: "udp", "tcp", "tls", or "unix".
+    network: "{{ .Log.Syslog.Network }}"
+    # The syslog facility of the messages.
+    facility: "{{ .Log.Syslog.Facility }}"
+    # The APP-NAME of the messages.
+    app_name: "{{ .Log.Syslog.AppName }}"
+    # Send SSH sessions and HTTP requests to syslog only, not to the server
+    # logs.
+    only: {{ .Log.Syslog.Only }}
+    # The number of messages buffered while the endpoint is unavailable.
+    buffer_size: {{ .Log.Syslog.BufferSize }}
+    # The message dropped when the buffer is full: "newest" or "oldest".
+    drop_policy: "{{ .Log.Syslog.DropPolicy }}"
 
 # The SSH server configuration.
 ssh:
   # The address on which the SSH server will listen.
   listen_addr: "{{ .SSH.ListenAddr }}"
 
+  # Additional addresses to listen on, mapped to the maximum access level of
+  # the clients connecting to them: no-access, read-only, read-write, or
+  # admin-access. Clients connecting to the listen address have their full
+  # access. This exposes a restricted endpoint publicly while keeping full
+  # access internal.
+  # listeners:
+  #   "0.0.0.0:2222": read-only
+
   # The public URL of the SSH server.
   # This is the address that will be used to clone repositories.
   public_url: "{{ .SSH.PublicURL }}"
@@ -45,8 +89,71 @@ ssh:
   # A value of 0 means no timeout.
   idle_timeout: {{ .SSH.IdleTimeout }}
 
+  # The number of seconds a connection has to complete authentication before
+  # it is closed. A value of 0 means no timeout.
+  pre_auth_timeout: {{ .SSH.PreAuthTimeout }}
+
+  # Throttle the connections that haven't authenticated yet, like OpenSSH's
+  # MaxStartups. With "start:rate:full", rate percent of new connections are
+  # dropped once start connections are unauthenticated, increasing linearly
+  # up to every new connection at full. A single number drops every new
+  # connection past it. Leave empty to disable the throttling.
+  max_startups: "{{ .SSH.MaxStartups }}"
+
+  # Reject SSH connections whose username is neither one of the allowed
+  # usernames nor the username of the authenticated user.
+  strict_usernames: {{ .SSH.StrictUsernames }}
+
+  # The SSH usernames anyone can connect with when strict_usernames is enabled.
+  allowed_usernames:{{ range .SSH.AllowedUsernames }}
+    - "{{ . }}"{{ end }}
+
+  # The commands SSH clients can run, by name, like "git-upload-pack" or
+  # "repo". Other commands are rejected, and sessions without a command never
+  # get a shell. Leave empty to allow every command.
+  allowed_commands:{{ range .SSH.AllowedCommands }}
+    - "{{ . }}"{{ end }}
+
+  # Map client network CIDRs to source names. These names are used to label
+  # SSH authentication metrics by network origin. Unmatched connections are
+  # labeled "unknown". Send SIGHUP to the server to reload this mapping.
+  # sources:
+  #   "10.0.0.0/8": office
+  #   "100.64.0.0/10": vpn
+
+  # Whether connections from the trusted proxies start with a PROXY protocol
+  # header carrying the real client address. Only enable this behind a load
+  # balancer sending it.
+  proxy_protocol: {{ .SSH.ProxyProtocol }}
+
+  # IP addresses and CIDRs of the load balancers sending the PROXY protocol
+  # header, required with proxy_protocol. Headers from other peers aren't read.
+  trusted_proxies:{{ range .SSH.TrustedProxies }}
+    - "{{ . }}"{{ end }}
+
+  # Let clients with an unregistered key register it with an invite code
+  # created by an admin. It only applies when anonymous users have no access.
+  invites: {{ .SSH.Invites }}
+
+  # The number of failed keyboard-interactive attempts, like wrong invite
+  # codes, after which a client IP address is locked out for
+  # interactive_lockout seconds. A value of 0 disables the lockout.
+  interactive_max_failures: {{ .SSH.InteractiveMaxFailures }}
+  interactive_lockout: {{ .SSH.InteractiveLockout }}
+
+  # The key exchange, cipher, and MAC algorithms clients shouldn't negotiate
+  # anymore. Clients negotiating one are logged with "warn", while "deny"
+  # stops offering them. An empty list disables the check.
+  deprecated_algorithms:{{ range .SSH.DeprecatedAlgorithms }}
+    - "{{ . }}"{{ end }}
+  deprecated_algorithms_action: "{{ .SSH.DeprecatedAlgorithmsAction }}"
+
 # The Git daemon configuration.
 git:
+  # Whether to serve repositories over the unauthenticated git:// protocol.
+  # Only public repositories marked with "repo daemon-export" are served.
+  enabled: {{ .Git.Enabled }}
+
   # The address on which the Git daemon will listen.
   listen_addr: "{{ .Git.ListenAddr }}"
 
@@ -64,6 +171,16 @@ git:
   # The maximum number of concurrent connections.
   max_connections: {{ .Git.MaxConnections }}
 
+  # Whether connections from the trusted proxies start with a PROXY protocol
+  # header carrying the real client address. Only enable this behind a load
+  # balancer sending it.
+  proxy_protocol: {{ .Git.ProxyProtocol }}
+
+  # IP addresses and CIDRs of the load balancers sending the PROXY protocol
+  # header, required with proxy_protocol. Headers from other peers aren't read.
+  trusted_proxies:{{ range .Git.TrustedProxies }}
+    - "{{ . }}"{{ end }}
+
 # The HTTP server configuration.
 http:
   # The address on which the HTTP server will listen.
@@ -80,11 +197,93 @@ http:
   # Make sure to use https:// if you are using TLS.
   public_url: "{{ .HTTP.PublicURL }}"
 
+  # Whether connections from the trusted proxies start with a PROXY protocol
+  # header carrying the real client address. Only enable this behind a load
+  # balancer sending it.
+  proxy_protocol: {{ .HTTP.ProxyProtocol }}
+
+  # IP addresses and CIDRs of the load balancers sending the PROXY protocol
+  # header, required with proxy_protocol. Headers from other peers aren't read.
+  trusted_proxies:{{ range .HTTP.TrustedProxies }}
+    - "{{ . }}"{{ end }}
+
+  # A message added to error responses, like who to contact for access.
+  error_help: "{{ .HTTP.ErrorHelp }}"
+
+  # The path to an HTML template rendering the error pages shown to browsers,
+  # relative to the data directory unless absolute. It gets the .Code,
+  # .Status, .Message, and .Help of the error. Leave empty for the built-in
+  # page.
+  error_page: "{{ .HTTP.ErrorPage }}"
+
+  # Serve a read-only HTML interface to browse the repositories under
+  # /browse. Private repositories need an access token or a password.
+  browse: {{ .HTTP.Browse }}
+
 # The stats server configuration.
 stats:
   # The address on which the stats server will listen.
   listen_addr: "{{ .Stats.ListenAddr }}"
 
+  # How metrics are exported: "prometheus" serves them on /metrics, and
+  # "otlp" pushes them to an OpenTelemetry collector.
+  exporters:{{ range .Stats.Exporters }}
+    - "{{ . }}"{{ end }}
+
+  # The OTLP exporter configuration.
+  otlp:
+    # The URL of the collector. Plain http URLs export without TLS.
+    endpoint: "{{ .Stats.OTLP.Endpoint }}"
+
+    # The OTLP protocol, "grpc" or "http/protobuf".
+    protocol: "{{ .Stats.OTLP.Protocol }}"
+
+    # The number of seconds between exports.
+    interval: {{ .Stats.OTLP.Interval }}
+
+  # Whether the git metrics tell anonymous requests apart from authenticated
+  # ones, with an anonymous label.
+  separate_anonymous: {{ .Stats.SeparateAnonymous }}
+
+# Tracing of SSH connections, git operations, hooks, and backend calls. Traces
+# are exported to an OpenTelemetry collector over OTLP.
+tracing:
+  # Whether to enable tracing.
+  enabled: {{ .Tracing.Enabled }}
+
+  # The URL of the collector. Plain http URLs export without TLS.
+  endpoint: "{{ .Tracing.Endpoint }}"
+
+  # The OTLP protocol, "grpc" or "http/protobuf".
+  protocol: "{{ .Tracing.Protocol }}"
+
+  # The ratio of traces recorded, from 0 to 1.
+  sample_ratio: {{ .Tracing.SampleRatio }}
+
+# The configuration of the packs served to clients, see git's pack.compression
+# and pack.window. New repositories get these in their git config. The defaults
+# are git's.
+pack:
+  # The zlib compression level, from 0 (none) to 9 (smallest packs). -1 is
+  # the zlib default.
+  compression: {{ .Pack.Compression }}
+
+  # The number of objects considered when looking for deltas.
+  window: {{ .Pack.Window }}
+
+# The profiling server configuration. The server exposes the Go runtime
+# profiles (pprof) and is disabled by default.
+profiling:
+  # Whether to enable the profiling server.
+  enabled: {{ .Profiling.Enabled }}
+
+  # The address on which the profiling server will listen.
+  listen_addr: "{{ .Profiling.ListenAddr }}"
+
+  # The token required to access the profiles. Profiles can reveal sensitive
+  # data, keep this secret.
+  token: "{{ .Profiling.Token }}"
+
 # The database configuration.
 db:
   # The database driver to use.
@@ -95,6 +294,45 @@ db:
   # Make sure foreign key support is enabled when using SQLite.
   data_source: "{{ .DB.DataSource }}"
 
+  # The maximum number of open connections to the database.
+  # A value of 0 means no limit.
+  max_open_conns: {{ .DB.MaxOpenConns }}
+
+  # The maximum number of idle connections kept in the pool.
+  max_idle_conns: {{ .DB.MaxIdleConns }}
+
+  # The maximum number of seconds a connection can be reused.
+  # A value of 0 means connections are reused forever.
+  conn_max_lifetime: {{ .DB.ConnMaxLifetime }}
+
+  # The number of times authentication and access checks are retried on
+  # transient database errors.
+  retries: {{ .DB.Retries }}
+
+  # The maximum number of milliseconds authentication and access checks can
+  # take, including retries. This keeps a slow database from hanging SSH
+  # handshakes.
+  retry_timeout: {{ .DB.RetryTimeout }}
+
+  # The number of days activity records are kept before the prune job deletes
+  # them. A value of 0 keeps them forever. Audit events are rotated into audit
+  # log segments instead.
+  retention:
+    audit_events: {{ .DB.Retention.AuditEvents }}
+    clone_events: {{ .DB.Retention.CloneEvents }}
+    mirror_syncs: {{ .DB.Retention.MirrorSyncs }}
+
+# Rotation of the audit log. Audit events past their retention, or over
+# max_events, are moved to compressed segment files in segments_path, relative
+# to the data path. The ship command is run with the path of each new segment
+# to copy it to external storage. Segments are removed after
+# segment_retention days, once shipped. A value of 0 disables the limits.
+audit:
+  max_events: {{ .Audit.MaxEvents }}
+  segments_path: "{{ .Audit.SegmentsPath }}"
+  #ship_command: "{{ .Audit.ShipCommand }}"
+  segment_retention: {{ .Audit.SegmentRetention }}
+
 # Git LFS configuration.
 lfs:
   # Enable Git LFS.
@@ -105,6 +343,276 @@ lfs:
 # Cron job configuration
 jobs:
   mirror_pull: "{{ .Jobs.MirrorPull }}"
+  push_mirror: "{{ .Jobs.PushMirror }}"
+  commit_graph: "{{ .Jobs.CommitGraph }}"
+  prune: "{{ .Jobs.Prune }}"
+  repo_sizes: "{{ .Jobs.RepoSizes }}"
+
+# Access control configuration.
+access:
+  # Enable deny-by-default access. Users must be admins, repository owners, or
+  # collaborators to access a repository. Anonymous access is limited to the
+  # repositories matching public_repos.
+  strict: {{ .Access.Strict }}
+  # Repository name patterns that are explicitly public in strict mode.
+  #public_repos:
+  #  - "public/*"
+  # Default visibility of new repositories by namespace. Valid values are
+  # "public", "private", and "hidden". The most specific namespace wins.
+  #namespace_visibility:
+  #  internal: private
+  #  public: public
+  # Default visibility of new repositories that don't match a namespace. Use
+  # "private" on locked-down servers.
+  #new_repo_visibility: public
+  # The access level the creator of a repository has to it. Valid values are
+  # "no-access", "read-only", "triage", "read-write", and "admin-access".
+  #creator_access: admin-access
+  # Collaborators added to every new repository, with their access level.
+  #default_collaborators:
+  #  ops: read-only
+  # The access level repository capabilities require, when not the default.
+  # Capabilities are "manage-issues" (triage by default), "post-statuses",
+  # "manage-releases", and "merge" (read-write by default).
+  #capabilities:
+  #  manage-releases: triage
+  # What access checks do when the backend fails, e.g. the database is down.
+  # "fail-closed" denies all access, "fail-open-read" allows read-only access
+  # to every repository until the backend recovers.
+  on_backend_error: {{ .Access.OnBackendError }}
+
+# Git operation timeouts. A git-upload-pack or git-receive-pack process that
+# runs longer than this is killed and the connection is closed, regardless of
+# the transport.
+timeouts:
+  # The maximum number of seconds a git-upload-pack operation can take.
+  # A value of 0 means no timeout.
+  upload_pack: {{ .Timeouts.UploadPack }}
+
+  # The maximum number of seconds a git-receive-pack operation can take.
+  # A value of 0 means no timeout.
+  receive_pack: {{ .Timeouts.ReceivePack }}
+
+  # The maximum number of seconds a custom git hook can take. A hook that runs
+  # longer is killed and counted as errored. A value of 0 means no timeout.
+  hook: {{ .Timeouts.Hook }}
+
+  # The maximum number of seconds an operation waits for another one to
+  # release the lock of a repository, like a push waiting for a rename to
+  # finish. A value of 0 means no timeout.
+  repo_lock: {{ .Timeouts.RepoLock }}
+
+  # The maximum number of seconds a push to a push mirror can take. A push
+  # that takes longer is killed and retried later. A value of 0 means no
+  # timeout.
+  push_mirror: {{ .Timeouts.PushMirror }}
+
+# Per-IP concurrent clone limits. These apply to git-upload-pack operations,
+# that is clones and fetches, over all transports.
+clone_limits:
+  # The maximum number of concurrent operations from a single IP address.
+  # A value of 0 means no limit.
+  per_ip: {{ .CloneLimits.PerIP }}
+
+  # The number of seconds an operation over the limit waits for another one to
+  # finish before it's rejected. A value of 0 rejects it right away.
+  queue_timeout: {{ .CloneLimits.QueueTimeout }}
+
+  # IP addresses and CIDRs exempt from the limit, e.g. CI runners.
+  allowlist:{{ range .CloneLimits.Allowlist }}
+    - "{{ . }}"{{ end }}
+
+# Concurrent archive limits. These apply to git-upload-archive operations,
+# that is git archive --remote, over SSH and the Git daemon.
+archive_limits:
+  # The maximum number of concurrent operations on the server. A value of 0
+  # means no limit.
+  max_concurrent: {{ .ArchiveLimits.MaxConcurrent }}
+
+  # The maximum number of concurrent operations from a single IP address.
+  # A value of 0 means no limit.
+  per_ip: {{ .ArchiveLimits.PerIP }}
+
+  # The number of seconds an operation over a limit waits for another one to
+  # finish before it's rejected. A value of 0 rejects it right away.
+  queue_timeout: {{ .ArchiveLimits.QueueTimeout }}
+
+# Concurrent pushes to the same repository, over SSH and HTTP.
+concurrent_pushes:
+  # How concurrent pushes are handled: "shared" runs them at the same time,
+  # "serialize" queues them to run one at a time, and "reject" rejects a push
+  # while another one is running.
+  mode: "{{ .ConcurrentPushes.Mode }}"
+
+  # The number of seconds a serialized push waits for the others to finish
+  # before it's rejected. A value of 0 means no timeout.
+  queue_timeout: {{ .ConcurrentPushes.QueueTimeout }}
+
+# Block connections over all transports by the region of the client. Regions
+# are resolved with the regions map of IP addresses and CIDRs, then with the
+# resolver, an executable run with the client IP address printing its region,
+# e.g. a GeoIP database lookup. Disabled unless some regions are denied. Send
+# SIGHUP to the server to reload these settings.
+geoblock:
+  # regions:
+  #   "203.0.113.0/24": NL
+  #resolver: "{{ .Geoblock.Resolver }}"
+  deny_regions:{{ range .Geoblock.DenyRegions }}
+    - "{{ . }}"{{ end }}
+  # Whether connections whose region can't be resolved are denied.
+  deny_unresolved: {{ .Geoblock.DenyUnresolved }}
+  # IP addresses and CIDRs never blocked, e.g. a VPN.
+  allowlist:{{ range .Geoblock.Allowlist }}
+    - "{{ . }}"{{ end }}
+
+# Repository creation limits. These apply to repositories created by pushing
+# to a new repository, and through the CLI and API. Admins are exempt.
+repo_limits:
+  # The maximum number of repositories a user can create within a window.
+  # A value of 0 means no limit.
+  create_per_window: {{ .RepoLimits.CreatePerWindow }}
+
+  # The length of the window in seconds.
+  window: {{ .RepoLimits.Window }}
+
+# The disk quota of users, the total size of the repositories they own. Pushes
+# and new repositories over the quota of their owner are rejected. Admins are
+# exempt.
+quota:
+  # The quota of every user, like "10GiB". Leave empty for no quota.
+  default: "{{ .Quota.Default }}"
+
+  # The quota of some users, overriding the default. "0" means no quota.
+  # users:
+  #   alice: "50GiB"
+
+  # Warn users using this share of their quota, in percent. A value of 0
+  # disables the warnings.
+  warn_percent: {{ .Quota.WarnPercent }}
+
+# Importing repositories.
+import:
+  # The credentials of HTTP remotes, by host, used when admins import or
+  # mirror their repositories. SSH remotes use the server client key.
+  # credentials:
+  #   git.example.com: "user:token"
+
+# Renamed repositories. Clients using the old name of a repository are told
+# its new name, and HTTP clients are redirected to it, so pushes don't create
+# a new repository by mistake.
+repo_renames:
+  # The number of days the old name is remembered after a rename.
+  # A value of 0 disables it.
+  grace_period: {{ .RepoRenames.GracePeriod }}
+
+# The hook run after a repository is created or imported, if it's executable.
+# Relative paths are relative to the data directory. It's killed after
+# timeouts.hook seconds. With rollback, a failing hook deletes the repository
+# and fails its creation, otherwise the failure is only logged.
+post_create:
+  hook: "{{ .PostCreate.Hook }}"
+  rollback: {{ .PostCreate.Rollback }}
+
+# The command run in the background after a push, like a CI trigger, if it's
+# executable. It gets the ref updates on its standard input, like a
+# post-receive hook, and in the environment with the repository. Its output is
+# logged, and it never delays the push. Relative paths are relative to the
+# data directory. Repositories can run another command.
+post_receive_exec:
+  command: "{{ .PostReceiveExec.Command }}"
+  # repos:
+  #   repo1: "/usr/local/bin/ci-trigger"
+  # The maximum number of seconds the command can take. A value of 0 means no
+  # timeout.
+  timeout: {{ .PostReceiveExec.Timeout }}
+
+# Automatic descriptions for repositories without one, set on their initial
+# push. The source is either "commit" for the subject of the first commit, or
+# "file" for the first line of a file in the repository. Disabled by default.
+auto_description:
+  #source: "{{ .AutoDescription.Source }}"
+  file: "{{ .AutoDescription.File }}"
+
+# Generated READMEs for repositories created from the command line or the API.
+# When enabled, a new repository without a template gets a README with its
+# name, description, and clone instructions, unless the creator opts out.
+auto_readme:
+  enabled: {{ .AutoReadme.Enabled }}
+
+# The initial push to an empty repository. By default, HEAD keeps pointing at
+# the branch it pointed at when the repository was created, even if the push
+# doesn't create it.
+initial_push:
+  # The branch HEAD points to after the initial push, if the push creates it,
+  # e.g. "main". Otherwise, HEAD is left as is.
+  default_branch: "{{ .InitialPush.DefaultBranch }}"
+
+# Pushing to a branch under the review ref prefix, like refs/for/main, proposes
+# a change to the branch for review instead of updating it. The change is kept
+# under refs/reviews/<number>.
+review:
+  # The prefix of the review refs. Leave it empty to disable reviews.
+  ref_prefix: "{{ .Review.RefPrefix }}"
+
+# Pushes that violate repository policies in warn-only mode are accepted, and
+# end with a banner listing the violated policies, so that pushers can fix
+# their changes before the policies are enforced.
+policy_warnings:
+  # Whether to show the banner.
+  banner: {{ .PolicyWarnings.Banner }}
+  # Guidance shown in the banner, like when the policies will be enforced.
+  guidance: "{{ .PolicyWarnings.Guidance }}"
+
+# Git commit-graph generation. Commit-graphs speed up history walks, like the
+# TUI logs and commit counts, on large repositories. They're written
+# incrementally, and stale ones are updated by the commit_graph job.
+commit_graph:
+  # Whether to write commit-graphs.
+  enabled: {{ .CommitGraph.Enabled }}
+  # Whether to update the commit-graph of a repository after each push.
+  after_push: {{ .CommitGraph.AfterPush }}
+
+# Cached repository sizes on disk, shown in the stats and the metrics. Sizes
+# are recomputed after each push, by the repo_sizes job, and by
+# "soft admin recompute-sizes".
+repo_sizes:
+  # The number of seconds a cached size is used before it's recomputed. A
+  # value of 0 means cached sizes never expire.
+  max_age: {{ .RepoSizes.MaxAge }}
+
+# Multi-server configuration. Several servers can share the same repository
+# storage and database, with a single primary accepting writes.
+replication:
+  # The role of this server. Valid values are "primary" and "replica".
+  # Replicas serve clones and fetches, and reject pushes.
+  role: "{{ .Replication.Role }}"
+
+  # The public URLs of the primary. Pushes to a replica are pointed to the
+  # primary.
+  #primary_ssh_url: "{{ .Replication.PrimarySSHURL }}"
+  #primary_http_url: "{{ .Replication.PrimaryHTTPURL }}"
+
+# SSH TUI configuration.
+tui:
+  # Whether interactive SSH sessions open the TUI. When disabled, they get
+  # the list of commands instead.
+  enabled: {{ .TUI.Enabled }}
+
+  # The server homepage shown when users connect to the TUI, either the
+  # README of a repository or a markdown file. Relative file paths are
+  # relative to the data directory. By default, the README of the
+  # ".soft-serve" repository is shown in the "About" tab.
+  #homepage_repo: "{{ .TUI.HomepageRepo }}"
+  #homepage_file: "{{ .TUI.HomepageFile }}"
+
+  # The number of repositories loaded before the repository list is shown,
+  # the rest are loaded in the background. Set to 0 to load all of them at
+  # once.
+  max_repos: {{ .TUI.MaxRepos }}
+
+  # The number of seconds a TUI session can go without input before it's
+  # closed. Set to 0 to only use the SSH idle timeout.
+  idle_timeout: {{ .TUI.IdleTimeout }}
 
 # Additional admin keys.
 #initial_admin_keys:
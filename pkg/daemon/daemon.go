@@ -3,6 +3,7 @@ package daemon
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"net"
 	"path/filepath"
@@ -14,7 +15,10 @@ import (
 	"github.com/charmbracelet/soft-serve/pkg/access"
 	"github.com/charmbracelet/soft-serve/pkg/backend"
 	"github.com/charmbracelet/soft-serve/pkg/config"
+	"github.com/charmbracelet/soft-serve/pkg/geoblock"
 	"github.com/charmbracelet/soft-serve/pkg/git"
+	"github.com/charmbracelet/soft-serve/pkg/proto"
+	"github.com/charmbracelet/soft-serve/pkg/proxyproto"
 	"github.com/charmbracelet/soft-serve/pkg/utils"
 	"github.com/go-git/go-git/v5/plumbing/format/pktline"
 	"github.com/prometheus/client_golang/prometheus"
@@ -52,6 +56,7 @@ type GitDaemon struct {
 	wg       sync.WaitGroup
 	once     sync.Once
 	logger   *log.Logger
+	geo      *geoblock.Blocker
 }
 
 // NewDaemon returns a new Git daemon.
@@ -66,11 +71,20 @@ func NewGitDaemon(ctx context.Context) (*GitDaemon, error) {
 		be:       backend.FromContext(ctx),
 		conns:    connections{m: make(map[net.Conn]struct{})},
 		logger:   log.FromContext(ctx).WithPrefix("gitdaemon"),
+		geo:      geoblock.FromContext(ctx),
 	}
 	listener, err := net.Listen("tcp", d.addr)
 	if err != nil {
 		return nil, err
 	}
+	if cfg.Git.ProxyProtocol {
+		trusted, err := config.ParseIPNets(cfg.Git.TrustedProxies)
+		if err != nil {
+			listener.Close() // nolint: errcheck
+			return nil, err
+		}
+		listener = proxyproto.NewListener(listener, trusted)
+	}
 	d.listener = listener
 	return d, nil
 }
@@ -131,7 +145,12 @@ func (d *GitDaemon) fatal(c net.Conn, err error) {
 
 // handleClient handles a git protocol client.
 func (d *GitDaemon) handleClient(conn net.Conn) {
-	ctx, cancel := context.WithCancel(context.Background())
+	if !d.geo.Allow(d.ctx, conn.RemoteAddr().String(), "git") {
+		d.fatal(conn, git.ErrRegionDenied)
+		return
+	}
+
+	ctx, cancel := context.WithCancel(config.WithContext(context.Background(), d.cfg))
 	idleTimeout := time.Duration(d.cfg.Git.IdleTimeout) * time.Second
 	c := &serverConn{
 		Conn:          conn,
@@ -234,6 +253,10 @@ func (d *GitDaemon) handleClient(conn net.Conn) {
 		}
 
 		name := utils.SanitizeRepo(string(opts[0]))
+		// Aliases are served their canonical repository.
+		if alias, ok := be.ResolveRepoAlias(ctx, name); ok {
+			name = alias.RepoName
+		}
 		d.logger.Debugf("git: connect %s %s %s", c.RemoteAddr(), service, name)
 		defer d.logger.Debugf("git: disconnect %s %s %s", c.RemoteAddr(), service, name)
 
@@ -247,7 +270,28 @@ func (d *GitDaemon) handleClient(conn net.Conn) {
 			return
 		}
 
-		if _, err := d.be.Repository(ctx, repo); err != nil {
+		r, err := d.be.Repository(ctx, repo)
+		if err != nil {
+			// Clients using the old name of a renamed repository are told
+			// the new one, if it's served.
+			if newName, ok := be.RenamedRepository(ctx, name); ok && d.served(ctx, newName) {
+				url := strings.TrimSuffix(d.cfg.Git.PublicURL, "/") + "/" + newName
+				d.fatal(c, backend.RenamedRepositoryError(newName, url))
+				return
+			}
+			d.fatal(c, git.ErrInvalidRepo)
+			return
+		}
+
+		// Only explicitly exported public repositories are served. Others
+		// look like they don't exist.
+		export, err := be.DaemonExport(ctx, name)
+		if err != nil {
+			d.logger.Errorf("git: error checking repository export: %v", err)
+			d.fatal(c, git.ErrSystemMalfunction)
+			return
+		}
+		if !export || r.IsPrivate() {
 			d.fatal(c, git.ErrInvalidRepo)
 			return
 		}
@@ -258,6 +302,24 @@ func (d *GitDaemon) handleClient(conn net.Conn) {
 			return
 		}
 
+		if err := be.CheckProtocol(ctx, name, backend.ProtocolGit, false); errors.Is(err, proto.ErrProtocolNotAllowed) {
+			d.fatal(c, err)
+			return
+		} else if err != nil {
+			d.logger.Errorf("git: error checking protocol: %v", err)
+			d.fatal(c, git.ErrSystemMalfunction)
+			return
+		}
+
+		if err := be.CheckDeniedPaths(ctx, name); errors.Is(err, proto.ErrDeniedPath) {
+			d.fatal(c, err)
+			return
+		} else if err != nil {
+			d.logger.Errorf("git: error checking denied paths: %v", err)
+			d.fatal(c, git.ErrSystemMalfunction)
+			return
+		}
+
 		// Environment variables to pass down to git hooks.
 		envs := []string{
 			"SOFT_SERVE_REPO_NAME=" + name,
@@ -288,16 +350,55 @@ func (d *GitDaemon) handleClient(conn net.Conn) {
 			Dir:    filepath.Join(reposDir, repo),
 		}
 
+		switch service {
+		case git.UploadPackService, git.UploadArchiveService:
+			acquire := be.AcquireUploadPack
+			if service == git.UploadArchiveService {
+				acquire = be.AcquireUploadArchive
+			}
+			release, err := acquire(ctx, c.RemoteAddr().String())
+			if err != nil {
+				d.fatal(c, err)
+				return
+			}
+			defer release()
+		}
+
 		if err := service.Handler(ctx, cmd); err != nil {
 			d.logger.Debugf("git: error handling request: %v", err)
+			if !errors.Is(err, git.ErrServiceTimeout) && !errors.Is(err, git.ErrInvalidRepo) {
+				err = git.ClientError(ctx, err)
+			}
 			d.fatal(c, err)
 			return
 		}
 
 		counter.WithLabelValues(name)
+
+		if service == git.UploadPackService {
+			if err := be.RecordClone(ctx, name, nil, "git"); err != nil {
+				d.logger.Errorf("git: error recording clone: %v", err)
+			}
+		}
 	}
 }
 
+// served returns whether the daemon serves a repository: it's exported,
+// public, and readable anonymously.
+func (d *GitDaemon) served(ctx context.Context, name string) bool {
+	r, err := d.be.Repository(ctx, name)
+	if err != nil || r.IsPrivate() {
+		return false
+	}
+
+	export, err := d.be.DaemonExport(ctx, name)
+	if err != nil || !export {
+		return false
+	}
+
+	return d.be.AccessLevel(ctx, name, "") >= access.ReadOnlyAccess
+}
+
 // Close closes the underlying listener.
 func (d *GitDaemon) Close() error {
 	d.once.Do(func() { close(d.finished) })
@@ -2,6 +2,7 @@ package db
 
 import (
 	"context"
+	"path/filepath"
 	"strings"
 	"testing"
 )
@@ -15,3 +16,47 @@ func TestOpenUnknownDriver(t *testing.T) {
 		t.Errorf("Open(invalid) => %v, want error containing 'unknown driver'", err)
 	}
 }
+
+func TestStatsAndCompact(t *testing.T) {
+	ctx := context.TODO()
+	d, err := Open(ctx, "sqlite", filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Close() // nolint: errcheck
+
+	if _, err := d.ExecContext(ctx, `CREATE TABLE items (id INTEGER PRIMARY KEY, data TEXT);`); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 100; i++ {
+		if _, err := d.ExecContext(ctx, `INSERT INTO items (data) VALUES (?);`, strings.Repeat("x", 1000)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := d.ExecContext(ctx, `DELETE FROM items WHERE id > 10;`); err != nil {
+		t.Fatal(err)
+	}
+
+	before, err := d.Stats(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(before.Tables) != 1 || before.Tables[0].Name != "items" || before.Tables[0].Rows != 10 {
+		t.Errorf("Stats() tables => %v, want [{items 10}]", before.Tables)
+	}
+	if before.Free == 0 {
+		t.Error("Stats() free => 0, want unused space after deleting rows")
+	}
+
+	if err := d.Compact(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	after, err := d.Stats(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if after.Free != 0 || after.Size >= before.Size {
+		t.Errorf("Stats() after Compact() => size %d free %d, want size < %d and no free space", after.Size, after.Free, before.Size)
+	}
+}
@@ -1,8 +1,13 @@
 package db
 
 import (
+	"context"
 	"database/sql"
+	"database/sql/driver"
 	"errors"
+	"io"
+	"net"
+	"strings"
 
 	"github.com/lib/pq"
 	sqlite "modernc.org/sqlite"
@@ -46,3 +51,49 @@ func WrapError(err error) error {
 	}
 	return err
 }
+
+// IsTransient returns whether err is a temporary database failure, such as a
+// dropped connection or a locked database, that may succeed if retried.
+func IsTransient(err error) bool {
+	switch {
+	case err == nil,
+		errors.Is(err, context.Canceled),
+		errors.Is(err, context.DeadlineExceeded),
+		errors.Is(err, ErrRecordNotFound),
+		errors.Is(err, ErrDuplicateKey):
+		return false
+	case errors.Is(err, driver.ErrBadConn),
+		errors.Is(err, sql.ErrConnDone),
+		errors.Is(err, io.ErrUnexpectedEOF):
+		return true
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+
+	// Handle sqlite busy and locked errors.
+	var liteErr *sqlite.Error
+	if errors.As(err, &liteErr) {
+		switch liteErr.Code() & 0xff {
+		case sqlitelib.SQLITE_BUSY, sqlitelib.SQLITE_LOCKED:
+			return true
+		}
+	}
+
+	// Handle postgres connection exceptions, serialization failures,
+	// deadlocks, and server shutdowns.
+	var pgErr *pq.Error
+	if errors.As(err, &pgErr) {
+		code := string(pgErr.Code)
+		return strings.HasPrefix(code, "08") ||
+			code == "40001" ||
+			code == "40P01" ||
+			code == "53300" ||
+			code == "57P01" ||
+			code == "57P03"
+	}
+
+	return false
+}
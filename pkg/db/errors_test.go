@@ -1,10 +1,14 @@
 package db
 
 import (
+	"context"
 	"database/sql"
+	"database/sql/driver"
 	"errors"
 	"fmt"
 	"testing"
+
+	"github.com/lib/pq"
 )
 
 func TestWrapErrorBadNoRows(t *testing.T) {
@@ -23,3 +27,25 @@ func TestWrapErrorGoodNoRows(t *testing.T) {
 		t.Errorf("WrapError(sql.ErrNoRows) => %v, want %v", err, ErrRecordNotFound)
 	}
 }
+
+func TestIsTransient(t *testing.T) {
+	for _, c := range []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{sql.ErrNoRows, false},
+		{ErrDuplicateKey, false},
+		{context.Canceled, false},
+		{errors.New("syntax error"), false},
+		{driver.ErrBadConn, true},
+		{fmt.Errorf("failed to begin transaction: %w", sql.ErrConnDone), true},
+		{&pq.Error{Code: "08006"}, true},
+		{&pq.Error{Code: "40001"}, true},
+		{&pq.Error{Code: "42601"}, false},
+	} {
+		if got := IsTransient(c.err); got != c.want {
+			t.Errorf("IsTransient(%v) => %v, want %v", c.err, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// TableStats is the number of rows of a table.
+type TableStats struct {
+	Name string
+	Rows int64
+}
+
+// Stats is the size and contents of the database.
+type Stats struct {
+	// Size is the size of the database in bytes.
+	Size int64
+	// Free is the unused space in bytes that compacting the database
+	// reclaims. It's only known for sqlite.
+	Free int64
+	// Tables are the row counts of the tables, sorted by name.
+	Tables []TableStats
+}
+
+// Stats returns the size of the database and the row counts of its tables.
+func (d *DB) Stats(ctx context.Context) (Stats, error) {
+	var s Stats
+	var tables []string
+	switch d.DriverName() {
+	case "sqlite":
+		var pageSize, pages, free int64
+		if err := d.GetContext(ctx, &pageSize, `PRAGMA page_size;`); err != nil {
+			return s, err
+		}
+		if err := d.GetContext(ctx, &pages, `PRAGMA page_count;`); err != nil {
+			return s, err
+		}
+		if err := d.GetContext(ctx, &free, `PRAGMA freelist_count;`); err != nil {
+			return s, err
+		}
+		s.Size = pages * pageSize
+		s.Free = free * pageSize
+
+		if err := d.SelectContext(ctx, &tables, `SELECT name FROM sqlite_master
+				WHERE type = 'table' AND name NOT LIKE 'sqlite_%'
+				ORDER BY name;`); err != nil {
+			return s, err
+		}
+	case "postgres":
+		if err := d.GetContext(ctx, &s.Size, `SELECT pg_database_size(current_database());`); err != nil {
+			return s, err
+		}
+
+		if err := d.SelectContext(ctx, &tables, `SELECT tablename FROM pg_tables
+				WHERE schemaname = current_schema()
+				ORDER BY tablename;`); err != nil {
+			return s, err
+		}
+	default:
+		return s, fmt.Errorf("unsupported database driver %q", d.DriverName())
+	}
+
+	for _, name := range tables {
+		t := TableStats{Name: name}
+		// Table names come from the catalog, quoting them is enough.
+		query := fmt.Sprintf(`SELECT COUNT(*) FROM "%s";`, strings.ReplaceAll(name, `"`, `""`))
+		if err := d.GetContext(ctx, &t.Rows, query); err != nil {
+			return s, err
+		}
+		s.Tables = append(s.Tables, t)
+	}
+
+	return s, nil
+}
+
+// Compact reclaims the unused space of the database and refreshes the query
+// planner statistics.
+//
+// With sqlite, the database file is rebuilt, which blocks writes until it's
+// done, and reads while the result is written back. Blocked queries wait for
+// up to the busy timeout of their connection. With postgres, the tables are vacuumed without
+// blocking reads or writes, and the space is reused rather than returned to
+// the operating system.
+func (d *DB) Compact(ctx context.Context) error {
+	var stmts []string
+	switch d.DriverName() {
+	case "sqlite":
+		stmts = []string{`VACUUM;`, `PRAGMA optimize;`}
+	case "postgres":
+		stmts = []string{`VACUUM (ANALYZE);`}
+	default:
+		return fmt.Errorf("unsupported database driver %q", d.DriverName())
+	}
+
+	// VACUUM can't run in a transaction.
+	for _, stmt := range stmts {
+		if _, err := d.ExecContext(ctx, stmt); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS push_mirrors (
+  id SERIAL PRIMARY KEY,
+  repo_id INTEGER NOT NULL,
+  name TEXT NOT NULL,
+  remote_url TEXT NOT NULL,
+  status TEXT NOT NULL DEFAULT 'idle',
+  attempts INTEGER NOT NULL DEFAULT 0,
+  last_error TEXT,
+  last_pushed_at TIMESTAMP,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP NOT NULL,
+  UNIQUE (repo_id, name),
+  CONSTRAINT repo_id_fk
+  FOREIGN KEY(repo_id) REFERENCES repos(id)
+  ON DELETE CASCADE
+  ON UPDATE CASCADE
+);
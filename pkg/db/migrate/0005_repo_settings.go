@@ -0,0 +1,23 @@
+package migrate
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+)
+
+const (
+	repoSettingsName    = "repo_settings"
+	repoSettingsVersion = 5
+)
+
+var repoSettings = Migration{
+	Name:    repoSettingsName,
+	Version: repoSettingsVersion,
+	Migrate: func(ctx context.Context, tx *db.Tx) error {
+		return migrateUp(ctx, tx, repoSettingsVersion, repoSettingsName)
+	},
+	Rollback: func(ctx context.Context, tx *db.Tx) error {
+		return migrateDown(ctx, tx, repoSettingsVersion, repoSettingsName)
+	},
+}
@@ -0,0 +1,23 @@
+package migrate
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+)
+
+const (
+	cloneEventsName    = "clone_events"
+	cloneEventsVersion = 6
+)
+
+var cloneEvents = Migration{
+	Name:    cloneEventsName,
+	Version: cloneEventsVersion,
+	Migrate: func(ctx context.Context, tx *db.Tx) error {
+		return migrateUp(ctx, tx, cloneEventsVersion, cloneEventsName)
+	},
+	Rollback: func(ctx context.Context, tx *db.Tx) error {
+		return migrateDown(ctx, tx, cloneEventsVersion, cloneEventsName)
+	},
+}
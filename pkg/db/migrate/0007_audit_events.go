@@ -0,0 +1,23 @@
+package migrate
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+)
+
+const (
+	auditEventsName    = "audit_events"
+	auditEventsVersion = 7
+)
+
+var auditEvents = Migration{
+	Name:    auditEventsName,
+	Version: auditEventsVersion,
+	Migrate: func(ctx context.Context, tx *db.Tx) error {
+		return migrateUp(ctx, tx, auditEventsVersion, auditEventsName)
+	},
+	Rollback: func(ctx context.Context, tx *db.Tx) error {
+		return migrateDown(ctx, tx, auditEventsVersion, auditEventsName)
+	},
+}
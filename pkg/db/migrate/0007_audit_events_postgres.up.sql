@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS audit_events (
+  id SERIAL PRIMARY KEY,
+  action TEXT NOT NULL,
+  repo_id INTEGER,
+  user_id INTEGER,
+  details TEXT NOT NULL DEFAULT '',
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  CONSTRAINT repo_id_fk
+  FOREIGN KEY(repo_id) REFERENCES repos(id)
+  ON DELETE SET NULL
+  ON UPDATE CASCADE,
+  CONSTRAINT user_id_fk
+  FOREIGN KEY(user_id) REFERENCES users(id)
+  ON DELETE SET NULL
+  ON UPDATE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS audit_events_repo_id_idx ON audit_events (repo_id, created_at);
+CREATE INDEX IF NOT EXISTS audit_events_created_at_idx ON audit_events (created_at);
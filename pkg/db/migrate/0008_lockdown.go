@@ -0,0 +1,23 @@
+package migrate
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+)
+
+const (
+	lockdownName    = "lockdown"
+	lockdownVersion = 8
+)
+
+var lockdown = Migration{
+	Name:    lockdownName,
+	Version: lockdownVersion,
+	Migrate: func(ctx context.Context, tx *db.Tx) error {
+		return migrateUp(ctx, tx, lockdownVersion, lockdownName)
+	},
+	Rollback: func(ctx context.Context, tx *db.Tx) error {
+		return migrateDown(ctx, tx, lockdownVersion, lockdownName)
+	},
+}
@@ -0,0 +1,7 @@
+INSERT INTO settings (key, value, updated_at) VALUES ('lockdown', 'false', CURRENT_TIMESTAMP);
+
+CREATE TABLE IF NOT EXISTS revoked_keys (
+  id SERIAL PRIMARY KEY,
+  fingerprint TEXT NOT NULL UNIQUE,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS revoked_keys;
+DELETE FROM settings WHERE key = 'lockdown';
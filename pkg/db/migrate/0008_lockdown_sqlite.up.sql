@@ -0,0 +1,7 @@
+INSERT INTO settings (key, value, updated_at) VALUES ('lockdown', 'false', CURRENT_TIMESTAMP);
+
+CREATE TABLE IF NOT EXISTS revoked_keys (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  fingerprint TEXT NOT NULL UNIQUE,
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,23 @@
+package migrate
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+)
+
+const (
+	commitStatusesName    = "commit_statuses"
+	commitStatusesVersion = 9
+)
+
+var commitStatuses = Migration{
+	Name:    commitStatusesName,
+	Version: commitStatusesVersion,
+	Migrate: func(ctx context.Context, tx *db.Tx) error {
+		return migrateUp(ctx, tx, commitStatusesVersion, commitStatusesName)
+	},
+	Rollback: func(ctx context.Context, tx *db.Tx) error {
+		return migrateDown(ctx, tx, commitStatusesVersion, commitStatusesName)
+	},
+}
@@ -0,0 +1,23 @@
+package migrate
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+)
+
+const (
+	repoAliasesName    = "repo_aliases"
+	repoAliasesVersion = 10
+)
+
+var repoAliases = Migration{
+	Name:    repoAliasesName,
+	Version: repoAliasesVersion,
+	Migrate: func(ctx context.Context, tx *db.Tx) error {
+		return migrateUp(ctx, tx, repoAliasesVersion, repoAliasesName)
+	},
+	Rollback: func(ctx context.Context, tx *db.Tx) error {
+		return migrateDown(ctx, tx, repoAliasesVersion, repoAliasesName)
+	},
+}
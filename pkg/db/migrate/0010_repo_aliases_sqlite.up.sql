@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS repo_aliases (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  repo_id INTEGER NOT NULL,
+  alias TEXT NOT NULL UNIQUE,
+  message TEXT NOT NULL DEFAULT '',
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  CONSTRAINT repo_id_fk
+  FOREIGN KEY(repo_id) REFERENCES repos(id)
+  ON DELETE CASCADE
+  ON UPDATE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS repo_aliases_repo_id_idx ON repo_aliases (repo_id);
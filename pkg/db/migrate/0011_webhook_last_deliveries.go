@@ -0,0 +1,23 @@
+package migrate
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+)
+
+const (
+	webhookLastDeliveriesName    = "webhook_last_deliveries"
+	webhookLastDeliveriesVersion = 11
+)
+
+var webhookLastDeliveries = Migration{
+	Name:    webhookLastDeliveriesName,
+	Version: webhookLastDeliveriesVersion,
+	Migrate: func(ctx context.Context, tx *db.Tx) error {
+		return migrateUp(ctx, tx, webhookLastDeliveriesVersion, webhookLastDeliveriesName)
+	},
+	Rollback: func(ctx context.Context, tx *db.Tx) error {
+		return migrateDown(ctx, tx, webhookLastDeliveriesVersion, webhookLastDeliveriesName)
+	},
+}
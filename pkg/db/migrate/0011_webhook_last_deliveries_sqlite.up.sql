@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS webhook_last_deliveries (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  webhook_id INTEGER NOT NULL UNIQUE,
+  delivery_id TEXT NOT NULL,
+  event INTEGER NOT NULL,
+  response_status INTEGER NOT NULL,
+  request_error TEXT,
+  latency_ms INTEGER NOT NULL,
+  updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  CONSTRAINT webhook_id_fk
+  FOREIGN KEY(webhook_id) REFERENCES webhooks(id)
+  ON DELETE CASCADE
+  ON UPDATE CASCADE
+);
@@ -0,0 +1,23 @@
+package migrate
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+)
+
+const (
+	termsName    = "terms"
+	termsVersion = 12
+)
+
+var terms = Migration{
+	Name:    termsName,
+	Version: termsVersion,
+	Migrate: func(ctx context.Context, tx *db.Tx) error {
+		return migrateUp(ctx, tx, termsVersion, termsName)
+	},
+	Rollback: func(ctx context.Context, tx *db.Tx) error {
+		return migrateDown(ctx, tx, termsVersion, termsName)
+	},
+}
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS terms_acceptances;
+DELETE FROM settings WHERE key IN ('terms_version', 'terms_text');
@@ -0,0 +1,16 @@
+INSERT INTO settings (key, value, updated_at) VALUES ('terms_version', '', CURRENT_TIMESTAMP);
+INSERT INTO settings (key, value, updated_at) VALUES ('terms_text', '', CURRENT_TIMESTAMP);
+
+CREATE TABLE IF NOT EXISTS terms_acceptances (
+  id SERIAL PRIMARY KEY,
+  user_id INTEGER NOT NULL,
+  version TEXT NOT NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  UNIQUE (user_id, version),
+  CONSTRAINT user_id_fk
+  FOREIGN KEY(user_id) REFERENCES users(id)
+  ON DELETE CASCADE
+  ON UPDATE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS terms_acceptances_version_idx ON terms_acceptances (version, created_at);
@@ -0,0 +1,23 @@
+package migrate
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+)
+
+const (
+	repoRenamesName    = "repo_renames"
+	repoRenamesVersion = 13
+)
+
+var repoRenames = Migration{
+	Name:    repoRenamesName,
+	Version: repoRenamesVersion,
+	Migrate: func(ctx context.Context, tx *db.Tx) error {
+		return migrateUp(ctx, tx, repoRenamesVersion, repoRenamesName)
+	},
+	Rollback: func(ctx context.Context, tx *db.Tx) error {
+		return migrateDown(ctx, tx, repoRenamesVersion, repoRenamesName)
+	},
+}
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS repo_renames (
+  id SERIAL PRIMARY KEY,
+  repo_id INTEGER NOT NULL,
+  old_name TEXT NOT NULL UNIQUE,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  CONSTRAINT repo_id_fk
+  FOREIGN KEY(repo_id) REFERENCES repos(id)
+  ON DELETE CASCADE
+  ON UPDATE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS repo_renames_created_at_idx ON repo_renames (created_at);
@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS mirror_syncs (
+  id SERIAL PRIMARY KEY,
+  repo_id INTEGER NOT NULL,
+  status TEXT NOT NULL,
+  error TEXT,
+  new_objects INTEGER NOT NULL DEFAULT 0,
+  updated_refs INTEGER NOT NULL DEFAULT 0,
+  fetched_bytes BIGINT NOT NULL DEFAULT 0,
+  duration_ms INTEGER NOT NULL DEFAULT 0,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  CONSTRAINT repo_id_fk
+  FOREIGN KEY(repo_id) REFERENCES repos(id)
+  ON DELETE CASCADE
+  ON UPDATE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS mirror_syncs_repo_id_idx ON mirror_syncs (repo_id);
+CREATE INDEX IF NOT EXISTS mirror_syncs_created_at_idx ON mirror_syncs (created_at);
@@ -0,0 +1,23 @@
+package migrate
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+)
+
+const (
+	invitesName    = "invites"
+	invitesVersion = 15
+)
+
+var invites = Migration{
+	Name:    invitesName,
+	Version: invitesVersion,
+	Migrate: func(ctx context.Context, tx *db.Tx) error {
+		return migrateUp(ctx, tx, invitesVersion, invitesName)
+	},
+	Rollback: func(ctx context.Context, tx *db.Tx) error {
+		return migrateDown(ctx, tx, invitesVersion, invitesName)
+	},
+}
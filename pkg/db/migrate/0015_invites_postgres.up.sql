@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS invites (
+  id SERIAL PRIMARY KEY,
+  code_hash TEXT NOT NULL UNIQUE,
+  username TEXT NOT NULL,
+  admin BOOLEAN NOT NULL DEFAULT false,
+  created_by_id INTEGER,
+  expires_at TIMESTAMP NOT NULL,
+  used_at TIMESTAMP,
+  used_key TEXT,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  CONSTRAINT created_by_id_fk
+  FOREIGN KEY(created_by_id) REFERENCES users(id)
+  ON DELETE SET NULL
+  ON UPDATE CASCADE
+);
@@ -0,0 +1,23 @@
+package migrate
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+)
+
+const (
+	repoKeysName    = "repo_keys"
+	repoKeysVersion = 16
+)
+
+var repoKeys = Migration{
+	Name:    repoKeysName,
+	Version: repoKeysVersion,
+	Migrate: func(ctx context.Context, tx *db.Tx) error {
+		return migrateUp(ctx, tx, repoKeysVersion, repoKeysName)
+	},
+	Rollback: func(ctx context.Context, tx *db.Tx) error {
+		return migrateDown(ctx, tx, repoKeysVersion, repoKeysName)
+	},
+}
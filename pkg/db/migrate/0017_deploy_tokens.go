@@ -0,0 +1,23 @@
+package migrate
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+)
+
+const (
+	deployTokensName    = "deploy_tokens"
+	deployTokensVersion = 17
+)
+
+var deployTokens = Migration{
+	Name:    deployTokensName,
+	Version: deployTokensVersion,
+	Migrate: func(ctx context.Context, tx *db.Tx) error {
+		return migrateUp(ctx, tx, deployTokensVersion, deployTokensName)
+	},
+	Rollback: func(ctx context.Context, tx *db.Tx) error {
+		return migrateDown(ctx, tx, deployTokensVersion, deployTokensName)
+	},
+}
@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS deploy_tokens (
+  id SERIAL PRIMARY KEY,
+  repo_id INTEGER NOT NULL,
+  name TEXT NOT NULL,
+  token TEXT NOT NULL UNIQUE,
+  access_level INTEGER NOT NULL,
+  fingerprint TEXT NOT NULL DEFAULT '',
+  public_key TEXT NOT NULL DEFAULT '',
+  expires_at TIMESTAMP,
+  last_used_at TIMESTAMP,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP NOT NULL,
+  UNIQUE (repo_id, name),
+  CONSTRAINT repo_id_fk
+  FOREIGN KEY(repo_id) REFERENCES repos(id)
+  ON DELETE CASCADE
+  ON UPDATE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS deploy_tokens_fingerprint_idx ON deploy_tokens (fingerprint);
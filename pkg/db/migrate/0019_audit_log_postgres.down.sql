@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS audit_segments;
+DROP INDEX IF EXISTS audit_events_action_idx;
+DROP INDEX IF EXISTS audit_events_target_idx;
+DROP INDEX IF EXISTS audit_events_actor_idx;
+ALTER TABLE audit_events DROP COLUMN IF EXISTS hash;
+ALTER TABLE audit_events DROP COLUMN IF EXISTS prev_hash;
+ALTER TABLE audit_events DROP COLUMN IF EXISTS target;
+ALTER TABLE audit_events DROP COLUMN IF EXISTS actor;
@@ -0,0 +1,25 @@
+ALTER TABLE audit_events ADD COLUMN IF NOT EXISTS actor TEXT NOT NULL DEFAULT '';
+ALTER TABLE audit_events ADD COLUMN IF NOT EXISTS target TEXT NOT NULL DEFAULT '';
+ALTER TABLE audit_events ADD COLUMN IF NOT EXISTS prev_hash TEXT NOT NULL DEFAULT '';
+ALTER TABLE audit_events ADD COLUMN IF NOT EXISTS hash TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS audit_events_actor_idx ON audit_events (actor, created_at);
+CREATE INDEX IF NOT EXISTS audit_events_target_idx ON audit_events (target, created_at);
+CREATE INDEX IF NOT EXISTS audit_events_action_idx ON audit_events (action, created_at);
+
+CREATE TABLE IF NOT EXISTS audit_segments (
+  id SERIAL PRIMARY KEY,
+  name TEXT NOT NULL UNIQUE,
+  first_id INTEGER NOT NULL,
+  last_id INTEGER NOT NULL,
+  first_at TIMESTAMP NOT NULL,
+  last_at TIMESTAMP NOT NULL,
+  events INTEGER NOT NULL,
+  prev_hash TEXT NOT NULL DEFAULT '',
+  hash TEXT NOT NULL DEFAULT '',
+  checksum TEXT NOT NULL,
+  shipped_at TIMESTAMP,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS audit_segments_last_at_idx ON audit_segments (last_at);
@@ -0,0 +1,25 @@
+ALTER TABLE audit_events ADD COLUMN actor TEXT NOT NULL DEFAULT '';
+ALTER TABLE audit_events ADD COLUMN target TEXT NOT NULL DEFAULT '';
+ALTER TABLE audit_events ADD COLUMN prev_hash TEXT NOT NULL DEFAULT '';
+ALTER TABLE audit_events ADD COLUMN hash TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS audit_events_actor_idx ON audit_events (actor, created_at);
+CREATE INDEX IF NOT EXISTS audit_events_target_idx ON audit_events (target, created_at);
+CREATE INDEX IF NOT EXISTS audit_events_action_idx ON audit_events (action, created_at);
+
+CREATE TABLE IF NOT EXISTS audit_segments (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  name TEXT NOT NULL UNIQUE,
+  first_id INTEGER NOT NULL,
+  last_id INTEGER NOT NULL,
+  first_at DATETIME NOT NULL,
+  last_at DATETIME NOT NULL,
+  events INTEGER NOT NULL,
+  prev_hash TEXT NOT NULL DEFAULT '',
+  hash TEXT NOT NULL DEFAULT '',
+  checksum TEXT NOT NULL,
+  shipped_at DATETIME,
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS audit_segments_last_at_idx ON audit_segments (last_at);
@@ -0,0 +1,23 @@
+package migrate
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+)
+
+const (
+	repoSizesName    = "repo_sizes"
+	repoSizesVersion = 20
+)
+
+var repoSizes = Migration{
+	Name:    repoSizesName,
+	Version: repoSizesVersion,
+	Migrate: func(ctx context.Context, tx *db.Tx) error {
+		return migrateUp(ctx, tx, repoSizesVersion, repoSizesName)
+	},
+	Rollback: func(ctx context.Context, tx *db.Tx) error {
+		return migrateDown(ctx, tx, repoSizesVersion, repoSizesName)
+	},
+}
@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS repo_sizes (
+  repo_id INTEGER PRIMARY KEY,
+  size BIGINT NOT NULL,
+  updated_at TIMESTAMP NOT NULL,
+  CONSTRAINT repo_id_fk
+  FOREIGN KEY(repo_id) REFERENCES repos(id)
+  ON DELETE CASCADE
+  ON UPDATE CASCADE
+);
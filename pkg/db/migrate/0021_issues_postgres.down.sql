@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS issue_comments;
+DROP TABLE IF EXISTS issues;
@@ -0,0 +1,23 @@
+package migrate
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+)
+
+const (
+	webhookRefFiltersName    = "webhook_ref_filters"
+	webhookRefFiltersVersion = 22
+)
+
+var webhookRefFilters = Migration{
+	Name:    webhookRefFiltersName,
+	Version: webhookRefFiltersVersion,
+	Migrate: func(ctx context.Context, tx *db.Tx) error {
+		return migrateUp(ctx, tx, webhookRefFiltersVersion, webhookRefFiltersName)
+	},
+	Rollback: func(ctx context.Context, tx *db.Tx) error {
+		return migrateDown(ctx, tx, webhookRefFiltersVersion, webhookRefFiltersName)
+	},
+}
@@ -0,0 +1,2 @@
+ALTER TABLE webhooks ADD COLUMN IF NOT EXISTS ref_patterns TEXT NOT NULL DEFAULT '';
+ALTER TABLE webhooks ADD COLUMN IF NOT EXISTS all_refs BOOLEAN NOT NULL DEFAULT false;
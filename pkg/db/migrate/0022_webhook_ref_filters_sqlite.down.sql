@@ -0,0 +1,2 @@
+ALTER TABLE webhooks DROP COLUMN all_refs;
+ALTER TABLE webhooks DROP COLUMN ref_patterns;
@@ -0,0 +1,2 @@
+ALTER TABLE webhooks ADD COLUMN ref_patterns TEXT NOT NULL DEFAULT '';
+ALTER TABLE webhooks ADD COLUMN all_refs BOOLEAN NOT NULL DEFAULT false;
@@ -0,0 +1,23 @@
+package migrate
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+)
+
+const (
+	reviewsName    = "reviews"
+	reviewsVersion = 23
+)
+
+var reviews = Migration{
+	Name:    reviewsName,
+	Version: reviewsVersion,
+	Migrate: func(ctx context.Context, tx *db.Tx) error {
+		return migrateUp(ctx, tx, reviewsVersion, reviewsName)
+	},
+	Rollback: func(ctx context.Context, tx *db.Tx) error {
+		return migrateDown(ctx, tx, reviewsVersion, reviewsName)
+	},
+}
@@ -0,0 +1,25 @@
+package migrate
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+)
+
+const (
+	triageAccessName    = "triage_access"
+	triageAccessVersion = 24
+)
+
+// triageAccess makes room for the triage access level between read-only and
+// read-write in the stored access levels.
+var triageAccess = Migration{
+	Name:    triageAccessName,
+	Version: triageAccessVersion,
+	Migrate: func(ctx context.Context, tx *db.Tx) error {
+		return migrateUp(ctx, tx, triageAccessVersion, triageAccessName)
+	},
+	Rollback: func(ctx context.Context, tx *db.Tx) error {
+		return migrateDown(ctx, tx, triageAccessVersion, triageAccessName)
+	},
+}
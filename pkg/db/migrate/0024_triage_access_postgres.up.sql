@@ -0,0 +1,3 @@
+UPDATE collabs SET access_level = access_level + 1 WHERE access_level >= 2;
+UPDATE repo_keys SET access_level = access_level + 1 WHERE access_level >= 2;
+UPDATE deploy_tokens SET access_level = access_level + 1 WHERE access_level >= 2;
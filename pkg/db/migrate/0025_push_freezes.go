@@ -0,0 +1,25 @@
+package migrate
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+)
+
+const (
+	pushFreezesName    = "push_freezes"
+	pushFreezesVersion = 25
+)
+
+// pushFreezes adds the freeze windows, schedules denying pushes to
+// repositories.
+var pushFreezes = Migration{
+	Name:    pushFreezesName,
+	Version: pushFreezesVersion,
+	Migrate: func(ctx context.Context, tx *db.Tx) error {
+		return migrateUp(ctx, tx, pushFreezesVersion, pushFreezesName)
+	},
+	Rollback: func(ctx context.Context, tx *db.Tx) error {
+		return migrateDown(ctx, tx, pushFreezesVersion, pushFreezesName)
+	},
+}
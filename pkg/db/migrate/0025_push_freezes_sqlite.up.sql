@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS freeze_windows (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  repo_id INTEGER NOT NULL,
+  name TEXT NOT NULL,
+  refs TEXT NOT NULL DEFAULT '',
+  schedule TEXT NOT NULL,
+  duration INTEGER NOT NULL,
+  warn_only BOOLEAN NOT NULL DEFAULT false,
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at DATETIME NOT NULL,
+  UNIQUE (repo_id, name),
+  CONSTRAINT repo_id_fk
+  FOREIGN KEY(repo_id) REFERENCES repos(id)
+  ON DELETE CASCADE
+  ON UPDATE CASCADE
+);
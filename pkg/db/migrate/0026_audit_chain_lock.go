@@ -0,0 +1,25 @@
+package migrate
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+)
+
+const (
+	auditChainLockName    = "audit_chain_lock"
+	auditChainLockVersion = 26
+)
+
+// auditChainLock adds the row locked while appending to the audit log hash
+// chain, so processes sharing the database append one at a time.
+var auditChainLock = Migration{
+	Name:    auditChainLockName,
+	Version: auditChainLockVersion,
+	Migrate: func(ctx context.Context, tx *db.Tx) error {
+		return migrateUp(ctx, tx, auditChainLockVersion, auditChainLockName)
+	},
+	Rollback: func(ctx context.Context, tx *db.Tx) error {
+		return migrateDown(ctx, tx, auditChainLockVersion, auditChainLockName)
+	},
+}
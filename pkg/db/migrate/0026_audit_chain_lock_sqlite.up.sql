@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS audit_chain (
+  id INTEGER PRIMARY KEY,
+  updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO audit_chain (id) VALUES (1) ON CONFLICT (id) DO NOTHING;
@@ -2,9 +2,13 @@ package migrate
 
 import (
 	"context"
+	"slices"
+	"strconv"
 	"testing"
 
+	"github.com/charmbracelet/soft-serve/pkg/access"
 	"github.com/charmbracelet/soft-serve/pkg/config"
+	"github.com/charmbracelet/soft-serve/pkg/db"
 	"github.com/charmbracelet/soft-serve/pkg/db/internal/test"
 )
 
@@ -20,3 +24,58 @@ func TestMigrate(t *testing.T) {
 		t.Errorf("Migrate() => %v, want nil error", err)
 	}
 }
+
+func TestMigrateTriageAccess(t *testing.T) {
+	ctx := config.WithContext(context.TODO(), config.DefaultConfig())
+	dbx, err := test.OpenSqlite(ctx, t)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Migrate(ctx, dbx); err != nil {
+		t.Fatal(err)
+	}
+
+	levels := func(tx *db.Tx) []int {
+		var ls []int
+		if err := tx.SelectContext(ctx, &ls, "SELECT access_level FROM repo_keys ORDER BY id"); err != nil {
+			t.Fatal(err)
+		}
+		return ls
+	}
+
+	// Keys stored with no-access, read-only, read-write, and admin-access
+	// before the triage access level.
+	if err := dbx.TransactionContext(ctx, func(tx *db.Tx) error {
+		if err := triageAccess.Rollback(ctx, tx); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, `INSERT INTO repos (name, project_name, description, private, mirror, hidden, user_id, updated_at)
+			VALUES ('repo1', '', '', false, false, false, 1, CURRENT_TIMESTAMP)`); err != nil {
+			return err
+		}
+		for i := 0; i < 4; i++ {
+			if _, err := tx.ExecContext(ctx, `INSERT INTO repo_keys (repo_id, fingerprint, public_key, access_level, updated_at)
+				VALUES (1, ?, '', ?, CURRENT_TIMESTAMP)`, strconv.Itoa(i), i); err != nil {
+				return err
+			}
+		}
+
+		if err := triageAccess.Migrate(ctx, tx); err != nil {
+			return err
+		}
+		want := []int{int(access.NoAccess), int(access.ReadOnlyAccess), int(access.ReadWriteAccess), int(access.AdminAccess)}
+		if got := levels(tx); !slices.Equal(got, want) {
+			t.Errorf("migrated access levels => %v, want %v", got, want)
+		}
+
+		if err := triageAccess.Rollback(ctx, tx); err != nil {
+			return err
+		}
+		if got := levels(tx); !slices.Equal(got, []int{0, 1, 2, 3}) {
+			t.Errorf("rolled back access levels => %v, want %v", got, []int{0, 1, 2, 3})
+		}
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -18,6 +18,29 @@ var migrations = []Migration{
 	createTables,
 	webhooks,
 	migrateLfsObjects,
+	pushMirrors,
+	repoSettings,
+	cloneEvents,
+	auditEvents,
+	lockdown,
+	commitStatuses,
+	repoAliases,
+	webhookLastDeliveries,
+	terms,
+	repoRenames,
+	mirrorSyncs,
+	invites,
+	repoKeys,
+	deployTokens,
+	releases,
+	auditLog,
+	repoSizes,
+	issues,
+	webhookRefFilters,
+	reviews,
+	triageAccess,
+	pushFreezes,
+	auditChainLock,
 }
 
 func execMigration(ctx context.Context, tx *db.Tx, version int, name string, down bool) error {
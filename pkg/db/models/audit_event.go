@@ -0,0 +1,54 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+// AuditEvent is an entry of the audit log.
+type AuditEvent struct {
+	ID      int64         `db:"id"`
+	Action  string        `db:"action"`
+	RepoID  sql.NullInt64 `db:"repo_id"`
+	UserID  sql.NullInt64 `db:"user_id"`
+	Details string        `db:"details"`
+	// Actor is the username of the user at the time of the event, empty for
+	// events recorded before the audit log was chained.
+	Actor string `db:"actor"`
+	// Target is the name of the repository at the time of the event, empty
+	// for events recorded before the audit log was chained.
+	Target string `db:"target"`
+	// PrevHash is the hash of the previous event of the audit log, empty for
+	// the first one.
+	PrevHash string `db:"prev_hash"`
+	// Hash chains the event to the previous one, empty for events recorded
+	// before the audit log was chained.
+	Hash string `db:"hash"`
+	// Username is the username of the user, if any. It's populated by
+	// queries that join the users table.
+	Username sql.NullString `db:"username"`
+	// RepoName is the name of the repository, if any. It's populated by
+	// queries that join the repos table.
+	RepoName  sql.NullString `db:"repo_name"`
+	CreatedAt time.Time      `db:"created_at"`
+}
+
+// AuditSegment is a file of audit events rotated out of the database.
+type AuditSegment struct {
+	ID int64 `db:"id"`
+	// Name is the file name of the segment.
+	Name    string    `db:"name"`
+	FirstID int64     `db:"first_id"`
+	LastID  int64     `db:"last_id"`
+	FirstAt time.Time `db:"first_at"`
+	LastAt  time.Time `db:"last_at"`
+	Events  int64     `db:"events"`
+	// PrevHash is the previous hash of the first event of the segment.
+	PrevHash string `db:"prev_hash"`
+	// Hash is the hash of the last event of the segment.
+	Hash string `db:"hash"`
+	// Checksum is the SHA-256 digest of the segment file.
+	Checksum  string       `db:"checksum"`
+	ShippedAt sql.NullTime `db:"shipped_at"`
+	CreatedAt time.Time    `db:"created_at"`
+}
@@ -0,0 +1,18 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+// CloneEvent is a git-upload-pack request to a repository.
+type CloneEvent struct {
+	ID       int64         `db:"id"`
+	RepoID   int64         `db:"repo_id"`
+	UserID   sql.NullInt64 `db:"user_id"`
+	Protocol string        `db:"protocol"`
+	// Username is the username of the user, if any. It's populated by
+	// queries that join the users table.
+	Username  sql.NullString `db:"username"`
+	CreatedAt time.Time      `db:"created_at"`
+}
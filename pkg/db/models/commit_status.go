@@ -0,0 +1,24 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+// CommitStatus is the status of a commit reported by an external system,
+// like a CI, for a given context.
+type CommitStatus struct {
+	ID          int64         `db:"id"`
+	RepoID      int64         `db:"repo_id"`
+	CommitSHA   string        `db:"commit_sha"`
+	Context     string        `db:"context"`
+	State       string        `db:"state"`
+	Description string        `db:"description"`
+	TargetURL   string        `db:"target_url"`
+	UserID      sql.NullInt64 `db:"user_id"`
+	// Username is the username of the user, if any. It's populated by
+	// queries that join the users table.
+	Username  sql.NullString `db:"username"`
+	CreatedAt time.Time      `db:"created_at"`
+	UpdatedAt time.Time      `db:"updated_at"`
+}
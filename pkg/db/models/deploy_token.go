@@ -0,0 +1,23 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/charmbracelet/soft-serve/pkg/access"
+)
+
+// DeployToken is a token granting access to a single repository.
+type DeployToken struct {
+	ID          int64              `db:"id"`
+	RepoID      int64              `db:"repo_id"`
+	Name        string             `db:"name"`
+	Token       string             `db:"token"`
+	AccessLevel access.AccessLevel `db:"access_level"`
+	Fingerprint string             `db:"fingerprint"`
+	PublicKey   string             `db:"public_key"`
+	ExpiresAt   sql.NullTime       `db:"expires_at"`
+	LastUsedAt  sql.NullTime       `db:"last_used_at"`
+	CreatedAt   time.Time          `db:"created_at"`
+	UpdatedAt   time.Time          `db:"updated_at"`
+}
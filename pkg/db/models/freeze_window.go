@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// FreezeWindow is a schedule during which pushes to some refs of a
+// repository are denied.
+type FreezeWindow struct {
+	ID       int64  `db:"id"`
+	RepoID   int64  `db:"repo_id"`
+	Name     string `db:"name"`
+	Refs     string `db:"refs"`
+	Schedule string `db:"schedule"`
+	// Duration is the length of the window in seconds.
+	Duration  int64     `db:"duration"`
+	WarnOnly  bool      `db:"warn_only"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
@@ -0,0 +1,19 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Invite is an invite code to register a public key to a user.
+type Invite struct {
+	ID          int64          `db:"id"`
+	CodeHash    string         `db:"code_hash"`
+	Username    string         `db:"username"`
+	Admin       bool           `db:"admin"`
+	CreatedByID sql.NullInt64  `db:"created_by_id"`
+	ExpiresAt   time.Time      `db:"expires_at"`
+	UsedAt      sql.NullTime   `db:"used_at"`
+	UsedKey     sql.NullString `db:"used_key"`
+	CreatedAt   time.Time      `db:"created_at"`
+}
@@ -0,0 +1,39 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Issue is an issue of a repository.
+type Issue struct {
+	ID     int64 `db:"id"`
+	RepoID int64 `db:"repo_id"`
+	// Number identifies the issue in its repository, starting at 1.
+	Number int64         `db:"number"`
+	Title  string        `db:"title"`
+	Body   string        `db:"body"`
+	UserID sql.NullInt64 `db:"user_id"`
+	State  string        `db:"state"`
+	// Username is the username of the user, if any. It's populated by
+	// queries that join the users table.
+	Username sql.NullString `db:"username"`
+	// Comments is the number of comments of the issue. It's populated by
+	// queries that count them.
+	Comments  int64     `db:"comments"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+// IssueComment is a comment on an issue.
+type IssueComment struct {
+	ID      int64         `db:"id"`
+	IssueID int64         `db:"issue_id"`
+	Body    string        `db:"body"`
+	UserID  sql.NullInt64 `db:"user_id"`
+	// Username is the username of the user, if any. It's populated by
+	// queries that join the users table.
+	Username  sql.NullString `db:"username"`
+	CreatedAt time.Time      `db:"created_at"`
+	UpdatedAt time.Time      `db:"updated_at"`
+}
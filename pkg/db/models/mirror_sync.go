@@ -0,0 +1,19 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+// MirrorSync is the result of a pull mirror sync.
+type MirrorSync struct {
+	ID           int64          `db:"id"`
+	RepoID       int64          `db:"repo_id"`
+	Status       string         `db:"status"`
+	Error        sql.NullString `db:"error"`
+	NewObjects   int64          `db:"new_objects"`
+	UpdatedRefs  int64          `db:"updated_refs"`
+	FetchedBytes int64          `db:"fetched_bytes"`
+	DurationMS   int64          `db:"duration_ms"`
+	CreatedAt    time.Time      `db:"created_at"`
+}
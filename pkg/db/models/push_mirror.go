@@ -0,0 +1,20 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+// PushMirror is a repository push mirror.
+type PushMirror struct {
+	ID           int64          `db:"id"`
+	RepoID       int64          `db:"repo_id"`
+	Name         string         `db:"name"`
+	RemoteURL    string         `db:"remote_url"`
+	Status       string         `db:"status"`
+	Attempts     int            `db:"attempts"`
+	LastError    sql.NullString `db:"last_error"`
+	LastPushedAt sql.NullTime   `db:"last_pushed_at"`
+	CreatedAt    time.Time      `db:"created_at"`
+	UpdatedAt    time.Time      `db:"updated_at"`
+}
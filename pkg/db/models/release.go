@@ -0,0 +1,32 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Release is a release of a repository, attached to a tag.
+type Release struct {
+	ID     int64         `db:"id"`
+	RepoID int64         `db:"repo_id"`
+	Tag    string        `db:"tag"`
+	Title  string        `db:"title"`
+	Notes  string        `db:"notes"`
+	UserID sql.NullInt64 `db:"user_id"`
+	// Username is the username of the user, if any. It's populated by
+	// queries that join the users table.
+	Username  sql.NullString `db:"username"`
+	CreatedAt time.Time      `db:"created_at"`
+	UpdatedAt time.Time      `db:"updated_at"`
+}
+
+// ReleaseAsset is a file attached to a release.
+type ReleaseAsset struct {
+	ID        int64     `db:"id"`
+	ReleaseID int64     `db:"release_id"`
+	Name      string    `db:"name"`
+	Size      int64     `db:"size"`
+	SHA256    string    `db:"sha256"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
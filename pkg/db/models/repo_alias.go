@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// RepoAlias is an alternative name of a repository.
+type RepoAlias struct {
+	ID     int64  `db:"id"`
+	RepoID int64  `db:"repo_id"`
+	Alias  string `db:"alias"`
+	// Message is shown to clients using the alias, if any.
+	Message string `db:"message"`
+	// RepoName is the name of the repository. It's populated by queries
+	// that join the repos table.
+	RepoName  string    `db:"repo_name"`
+	CreatedAt time.Time `db:"created_at"`
+}
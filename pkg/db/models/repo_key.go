@@ -0,0 +1,18 @@
+package models
+
+import (
+	"time"
+
+	"github.com/charmbracelet/soft-serve/pkg/access"
+)
+
+// RepoKey is an SSH public key granted access to a repository.
+type RepoKey struct {
+	ID          int64              `db:"id"`
+	RepoID      int64              `db:"repo_id"`
+	Fingerprint string             `db:"fingerprint"`
+	PublicKey   string             `db:"public_key"`
+	AccessLevel access.AccessLevel `db:"access_level"`
+	CreatedAt   time.Time          `db:"created_at"`
+	UpdatedAt   time.Time          `db:"updated_at"`
+}
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// RepoRename is a past name of a repository.
+type RepoRename struct {
+	ID      int64  `db:"id"`
+	RepoID  int64  `db:"repo_id"`
+	OldName string `db:"old_name"`
+	// RepoName is the current name of the repository. It's populated by
+	// queries that join the repos table.
+	RepoName  string    `db:"repo_name"`
+	CreatedAt time.Time `db:"created_at"`
+}
@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// RepoSetting is a repository setting.
+type RepoSetting struct {
+	ID        int64     `db:"id"`
+	RepoID    int64     `db:"repo_id"`
+	Key       string    `db:"key"`
+	Value     string    `db:"value"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// RepoSize is the cached size of a repository on disk.
+type RepoSize struct {
+	RepoID int64 `db:"repo_id"`
+	Size   int64 `db:"size"`
+	// RepoName is the name of the repository. It's populated by queries
+	// that join the repos table.
+	RepoName  string    `db:"name"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
@@ -0,0 +1,26 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Review is a change proposed for review by pushing to a review ref.
+type Review struct {
+	ID     int64 `db:"id"`
+	RepoID int64 `db:"repo_id"`
+	// Number identifies the review in its repository, starting at 1.
+	Number int64 `db:"number"`
+	// TargetBranch is the branch the change is proposed to, like "main".
+	TargetBranch string `db:"target_branch"`
+	// BaseSHA is the commit of the target branch when the change was
+	// pushed.
+	BaseSHA string `db:"base_sha"`
+	// CommitSHA is the commit of the change.
+	CommitSHA string        `db:"commit_sha"`
+	UserID    sql.NullInt64 `db:"user_id"`
+	// Username is the username of the user, if any. It's populated by
+	// queries that join the users table.
+	Username  sql.NullString `db:"username"`
+	CreatedAt time.Time      `db:"created_at"`
+}
@@ -0,0 +1,10 @@
+package models
+
+import "time"
+
+// RevokedKey is a revoked SSH public key.
+type RevokedKey struct {
+	ID          int64     `db:"id"`
+	Fingerprint string    `db:"fingerprint"`
+	CreatedAt   time.Time `db:"created_at"`
+}
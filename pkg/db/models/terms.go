@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// TermsAcceptance is the acceptance of a version of the server terms by a
+// user.
+type TermsAcceptance struct {
+	ID        int64     `db:"id"`
+	UserID    int64     `db:"user_id"`
+	Username  string    `db:"username"`
+	Version   string    `db:"version"`
+	CreatedAt time.Time `db:"created_at"`
+}
@@ -9,14 +9,20 @@ import (
 
 // Webhook is a repository webhook.
 type Webhook struct {
-	ID          int64     `db:"id"`
-	RepoID      int64     `db:"repo_id"`
-	URL         string    `db:"url"`
-	Secret      string    `db:"secret"`
-	ContentType int       `db:"content_type"`
-	Active      bool      `db:"active"`
-	CreatedAt   time.Time `db:"created_at"`
-	UpdatedAt   time.Time `db:"updated_at"`
+	ID          int64  `db:"id"`
+	RepoID      int64  `db:"repo_id"`
+	URL         string `db:"url"`
+	Secret      string `db:"secret"`
+	ContentType int    `db:"content_type"`
+	Active      bool   `db:"active"`
+	// RefPatterns is a comma separated list of ref patterns push events are
+	// filtered by, empty to send push events for all refs.
+	RefPatterns string `db:"ref_patterns"`
+	// AllRefs is whether push payloads include all the updated refs, not only
+	// the matching ones.
+	AllRefs   bool      `db:"all_refs"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
 }
 
 // WebhookEvent is a webhook event.
@@ -42,3 +48,15 @@ type WebhookDelivery struct {
 	ResponseBody    string         `db:"response_body"`
 	CreatedAt       time.Time      `db:"created_at"`
 }
+
+// WebhookLastDelivery is the result of the last delivery of a webhook.
+type WebhookLastDelivery struct {
+	ID             int64          `db:"id"`
+	WebhookID      int64          `db:"webhook_id"`
+	DeliveryID     uuid.UUID      `db:"delivery_id"`
+	Event          int            `db:"event"`
+	ResponseStatus int            `db:"response_status"`
+	RequestError   sql.NullString `db:"request_error"`
+	LatencyMs      int64          `db:"latency_ms"`
+	UpdatedAt      time.Time      `db:"updated_at"`
+}
@@ -0,0 +1,311 @@
+// Package geoblock denies connections from clients in denied regions. The
+// region of a client address is resolved with a CIDR to region map, then with
+// a pluggable resolver, like a command looking the address up in a GeoIP
+// database.
+package geoblock
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"os/exec"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/charmbracelet/soft-serve/pkg/config"
+	lru "github.com/hashicorp/golang-lru/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var blockedCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "soft_serve",
+	Subsystem: "geoblock",
+	Name:      "blocked_connections_total",
+	Help:      "The total number of connections blocked because of the region of the client",
+}, []string{"protocol", "region"})
+
+const (
+	// cacheSize is the number of resolved addresses kept.
+	cacheSize = 10000
+	// cacheTTL is how long a resolved region is kept.
+	cacheTTL = 10 * time.Minute
+	// resolverTimeout is the time the resolver command has to resolve an
+	// address.
+	resolverTimeout = 2 * time.Second
+)
+
+// ContextKey is the key for the blocker in the context.
+var ContextKey = &struct{ string }{"geoblock"}
+
+// FromContext returns the blocker from a context.
+func FromContext(ctx context.Context) *Blocker {
+	if b, ok := ctx.Value(ContextKey).(*Blocker); ok {
+		return b
+	}
+
+	return nil
+}
+
+// WithContext returns a new context with the blocker attached.
+func WithContext(ctx context.Context, b *Blocker) context.Context {
+	return context.WithValue(ctx, ContextKey, b)
+}
+
+// Resolver resolves the region of an IP address, usually an ISO 3166-1
+// country code. An empty region means the address couldn't be resolved.
+type Resolver interface {
+	Resolve(ctx context.Context, ip net.IP) (string, error)
+}
+
+// ResolverFunc is a function implementing Resolver.
+type ResolverFunc func(ctx context.Context, ip net.IP) (string, error)
+
+// Resolve implements Resolver.
+func (f ResolverFunc) Resolve(ctx context.Context, ip net.IP) (string, error) {
+	return f(ctx, ip)
+}
+
+// CommandResolver resolves addresses by running an executable with the
+// address as its argument. It prints the region, or nothing if the address
+// can't be resolved.
+type CommandResolver string
+
+// Resolve implements Resolver.
+func (c CommandResolver) Resolve(ctx context.Context, ip net.IP) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, resolverTimeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, string(c), ip.String())
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	cmd.WaitDelay = time.Second
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%w: %s", err, msg)
+		}
+		return "", err
+	}
+
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+type regionNet struct {
+	region string
+	ipnet  *net.IPNet
+}
+
+type cachedRegion struct {
+	region  string
+	expires time.Time
+}
+
+// Blocker denies connections from clients in denied regions. It is safe for
+// concurrent use and can be reloaded at runtime. A nil Blocker, or one
+// without denied regions, allows every connection.
+type Blocker struct {
+	mu             sync.RWMutex
+	nets           []regionNet
+	allowlist      []*net.IPNet
+	resolver       Resolver
+	custom         bool
+	deny           map[string]struct{}
+	denyUnresolved bool
+	cache          *lru.Cache[string, cachedRegion]
+	logger         *log.Logger
+}
+
+// New returns a new blocker with the given configuration.
+func New(ctx context.Context, cfg config.GeoblockConfig) (*Blocker, error) {
+	cache, err := lru.New[string, cachedRegion](cacheSize)
+	if err != nil {
+		return nil, err
+	}
+
+	b := &Blocker{
+		cache:  cache,
+		logger: log.FromContext(ctx).WithPrefix("geoblock"),
+	}
+	if err := b.Set(cfg); err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
+
+// Set replaces the configuration of the blocker and forgets the resolved
+// regions. A resolver set with SetResolver is kept.
+func (b *Blocker) Set(cfg config.GeoblockConfig) error {
+	nets := make([]regionNet, 0, len(cfg.Regions))
+	for a, region := range cfg.Regions {
+		ipnet, err := config.ParseIPNet(a)
+		if err != nil {
+			return fmt.Errorf("invalid geoblock region %q: %w", a, err)
+		}
+		nets = append(nets, regionNet{region: normalizeRegion(region), ipnet: ipnet})
+	}
+
+	allowlist := make([]*net.IPNet, 0, len(cfg.Allowlist))
+	for _, a := range cfg.Allowlist {
+		ipnet, err := config.ParseIPNet(a)
+		if err != nil {
+			return fmt.Errorf("invalid geoblock allowlist entry %q: %w", a, err)
+		}
+		allowlist = append(allowlist, ipnet)
+	}
+
+	// Most specific networks first so they take precedence.
+	sort.Slice(nets, func(i, j int) bool {
+		oi, _ := nets[i].ipnet.Mask.Size()
+		oj, _ := nets[j].ipnet.Mask.Size()
+		return oi > oj
+	})
+
+	deny := make(map[string]struct{}, len(cfg.DenyRegions))
+	for _, r := range cfg.DenyRegions {
+		if r = normalizeRegion(r); r != "" {
+			deny[r] = struct{}{}
+		}
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.nets = nets
+	b.allowlist = allowlist
+	b.deny = deny
+	b.denyUnresolved = cfg.DenyUnresolved
+	if !b.custom {
+		b.resolver = nil
+		if cfg.Resolver != "" {
+			b.resolver = CommandResolver(cfg.Resolver)
+		}
+	}
+	b.cache.Purge()
+
+	return nil
+}
+
+// SetResolver replaces the resolver of the addresses that don't match the
+// CIDR to region map, like a GeoIP database lookup. It takes precedence over
+// the configured resolver command.
+func (b *Blocker) SetResolver(r Resolver) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.resolver = r
+	b.custom = r != nil
+	b.cache.Purge()
+}
+
+// Enabled returns whether the blocker denies any region.
+func (b *Blocker) Enabled() bool {
+	if b == nil {
+		return false
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return len(b.deny) > 0
+}
+
+// Region returns the region of an IP address, empty if it can't be
+// resolved. Resolved regions are cached.
+func (b *Blocker) Region(ctx context.Context, ip net.IP) string {
+	b.mu.RLock()
+	for _, n := range b.nets {
+		if n.ipnet.Contains(ip) {
+			b.mu.RUnlock()
+			return n.region
+		}
+	}
+	resolver := b.resolver
+	b.mu.RUnlock()
+
+	if resolver == nil {
+		return ""
+	}
+
+	key := ip.String()
+	if c, ok := b.cache.Get(key); ok && time.Now().Before(c.expires) {
+		return c.region
+	}
+
+	region, err := resolver.Resolve(ctx, ip)
+	if err != nil {
+		// Don't cache failures, the resolver may recover.
+		b.logger.Error("error resolving region", "ip", key, "err", err)
+		return ""
+	}
+
+	region = normalizeRegion(region)
+	b.cache.Add(key, cachedRegion{region: region, expires: time.Now().Add(cacheTTL)})
+	return region
+}
+
+// Allow returns whether a connection from addr, a host:port or an IP
+// address, over a protocol is allowed. Blocked connections are logged with
+// the region of the client.
+func (b *Blocker) Allow(ctx context.Context, addr string, protocol string) bool {
+	if !b.Enabled() {
+		return true
+	}
+
+	ip := addrIP(addr)
+	region := ""
+	if ip != nil {
+		if b.allowed(ip) {
+			return true
+		}
+		region = b.Region(ctx, ip)
+	}
+
+	b.mu.RLock()
+	_, denied := b.deny[region]
+	if region == "" {
+		denied = b.denyUnresolved
+	}
+	b.mu.RUnlock()
+
+	if !denied {
+		return true
+	}
+
+	label := region
+	if label == "" {
+		label = "unresolved"
+	}
+	blockedCounter.WithLabelValues(protocol, label).Inc()
+	b.logger.Warn("blocked connection from denied region", "protocol", protocol, "remote-addr", addr, "region", label)
+	return false
+}
+
+// allowed returns whether an IP address is in the allowlist.
+func (b *Blocker) allowed(ip net.IP) bool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for _, n := range b.allowlist {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// addrIP returns the IP address of a host:port or an IP address, nil if it
+// has none.
+func addrIP(addr string) net.IP {
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		addr = host
+	}
+
+	return net.ParseIP(addr)
+}
+
+func normalizeRegion(r string) string {
+	return strings.ToUpper(strings.TrimSpace(r))
+}
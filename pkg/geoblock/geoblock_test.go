@@ -0,0 +1,135 @@
+package geoblock
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/charmbracelet/soft-serve/pkg/config"
+)
+
+func TestAllow(t *testing.T) {
+	ctx := context.TODO()
+	b, err := New(ctx, config.GeoblockConfig{
+		Regions: map[string]string{
+			"192.0.2.0/24":   "nl",
+			"192.0.2.128/25": "de ",
+			"2001:db8::/32":  "NL",
+		},
+		DenyRegions: []string{"NL"},
+		Allowlist:   []string{"192.0.2.1"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		addr  string
+		allow bool
+	}{
+		{"192.0.2.2:22", false},
+		{"192.0.2.2", false},
+		{"[2001:db8::1]:22", false},
+		{"192.0.2.200:22", true},
+		{"192.0.2.1:22", true},
+		{"198.51.100.1:22", true},
+		{"invalid", true},
+	}
+	for _, c := range cases {
+		t.Run(c.addr, func(t *testing.T) {
+			if got := b.Allow(ctx, c.addr, "ssh"); got != c.allow {
+				t.Fatalf("expected allow %v, got %v", c.allow, got)
+			}
+		})
+	}
+}
+
+func TestAllowDisabled(t *testing.T) {
+	var b *Blocker
+	if b.Enabled() || !b.Allow(context.TODO(), "192.0.2.2:22", "ssh") {
+		t.Fatal("expected a nil blocker to allow every connection")
+	}
+
+	b, err := New(context.TODO(), config.GeoblockConfig{
+		Regions:        map[string]string{"192.0.2.0/24": "NL"},
+		DenyUnresolved: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.Enabled() || !b.Allow(context.TODO(), "198.51.100.1:22", "ssh") {
+		t.Fatal("expected a blocker without denied regions to allow every connection")
+	}
+}
+
+func TestResolver(t *testing.T) {
+	ctx := context.TODO()
+	b, err := New(ctx, config.GeoblockConfig{
+		Regions:     map[string]string{"192.0.2.0/24": "DE"},
+		DenyRegions: []string{"NL"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var calls int
+	b.SetResolver(ResolverFunc(func(_ context.Context, ip net.IP) (string, error) {
+		calls++
+		switch ip.String() {
+		case "198.51.100.1":
+			return " nl\n", nil
+		case "198.51.100.2":
+			return "", errors.New("lookup failed")
+		}
+		return "", nil
+	}))
+
+	if b.Allow(ctx, "198.51.100.1:22", "http") {
+		t.Fatal("expected the resolved region to be denied")
+	}
+	if b.Allow(ctx, "198.51.100.1:22", "http") {
+		t.Fatal("expected the cached region to be denied")
+	}
+	if calls != 1 {
+		t.Fatalf("expected the region to be cached, got %d calls", calls)
+	}
+
+	// The regions take precedence over the resolver.
+	if !b.Allow(ctx, "192.0.2.1:22", "http") || calls != 1 {
+		t.Fatal("expected the regions to be checked first")
+	}
+
+	// Failures aren't cached.
+	b.Allow(ctx, "198.51.100.2:22", "http")
+	b.Allow(ctx, "198.51.100.2:22", "http")
+	if calls != 3 {
+		t.Fatalf("expected resolver failures not to be cached, got %d calls", calls)
+	}
+
+	// A configured resolver command doesn't replace a custom resolver, and
+	// reloading forgets the cached regions.
+	if err := b.Set(config.GeoblockConfig{Resolver: "/nonexistent", DenyRegions: []string{"NL"}, DenyUnresolved: true}); err != nil {
+		t.Fatal(err)
+	}
+	if b.Allow(ctx, "198.51.100.1:22", "http") || calls != 4 {
+		t.Fatalf("expected the custom resolver to be kept, got %d calls", calls)
+	}
+	if b.Allow(ctx, "198.51.100.3:22", "http") {
+		t.Fatal("expected unresolved addresses to be denied")
+	}
+}
+
+func TestSetInvalid(t *testing.T) {
+	b, err := New(context.TODO(), config.GeoblockConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := b.Set(config.GeoblockConfig{Regions: map[string]string{"nope": "NL"}}); err == nil {
+		t.Fatal("expected an invalid region to be rejected")
+	}
+	if err := b.Set(config.GeoblockConfig{Allowlist: []string{"nope"}}); err == nil {
+		t.Fatal("expected an invalid allowlist entry to be rejected")
+	}
+}
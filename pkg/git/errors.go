@@ -1,6 +1,12 @@
 package git
 
-import "errors"
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/charmbracelet/soft-serve/pkg/config"
+)
 
 var (
 	// ErrNotAuthed represents unauthorized access.
@@ -20,4 +26,44 @@ var (
 
 	// ErrTimeout is returned when the maximum read timeout is exceeded.
 	ErrTimeout = errors.New("I/O timeout reached")
+
+	// ErrServiceTimeout is returned when a git service operation exceeds its
+	// configured timeout.
+	ErrServiceTimeout = errors.New("git operation timed out")
+
+	// ErrRegionDenied is returned when a client connects from a denied
+	// region.
+	ErrRegionDenied = errors.New("connections from your region are not allowed")
 )
+
+// ServiceError is returned when a git service command fails. It holds a
+// summary of what git wrote to stderr when stderr capturing is enabled.
+type ServiceError struct {
+	// Err is the underlying error.
+	Err error
+	// Summary is the last line git wrote to stderr with server paths removed.
+	Summary string
+}
+
+// Error implements error.
+func (e *ServiceError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *ServiceError) Unwrap() error {
+	return e.Err
+}
+
+// ClientError returns the error to report to clients for a failed git
+// service. It's ErrSystemMalfunction, followed by the git error summary when
+// git error details are enabled.
+func ClientError(ctx context.Context, err error) error {
+	var serr *ServiceError
+	cfg := config.FromContext(ctx)
+	if cfg != nil && cfg.Log.GitErrorDetails && errors.As(err, &serr) && serr.Summary != "" {
+		return fmt.Errorf("%w: %s", ErrSystemMalfunction, serr.Summary)
+	}
+
+	return ErrSystemMalfunction
+}
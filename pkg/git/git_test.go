@@ -95,3 +95,65 @@ func TestEnsureDefaultBranchEmpty(t *testing.T) {
 		t.Errorf("EnsureDefaultBranch(%q) => %v, want ErrNoBranches", tmp, err)
 	}
 }
+
+func TestStderrSummary(t *testing.T) {
+	cases := []struct {
+		name   string
+		stderr string
+		paths  []string
+		want   string
+	}{
+		{
+			name: "empty",
+		},
+		{
+			name:   "last line",
+			stderr: "warning: foo\nerror: unpack failed\n\n",
+			want:   "error: unpack failed",
+		},
+		{
+			name:   "repo path",
+			stderr: "fatal: '/srv/soft/repos/foo.git' does not appear to be a git repository\n",
+			paths:  []string{"/srv/soft/repos/foo.git", "/srv/soft"},
+			want:   "fatal: '<path>' does not appear to be a git repository",
+		},
+		{
+			name:   "relative data path",
+			stderr: "fatal: unable to write data/repos/foo.git/objects/pack/tmp_pack\n",
+			paths:  []string{"data"},
+			want:   "fatal: unable to write <path>/repos/foo.git/objects/pack/tmp_pack",
+		},
+		{
+			name:   "other paths",
+			stderr: "error: cannot lock ref 'refs/heads/main': unable to create /tmp/x.lock\n",
+			want:   "error: cannot lock ref 'refs/heads/main': unable to create <path>",
+		},
+		{
+			name:   "progress",
+			stderr: "Counting objects: 1\rCounting objects: 2\r",
+			want:   "Counting objects: 2",
+		},
+		{
+			name:   "control characters",
+			stderr: "fatal: \x1b[31mbad\x1b[0m\n",
+			want:   "fatal: [31mbad[0m",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := stderrSummary([]byte(c.stderr), c.paths...); got != c.want {
+				t.Errorf("expected %q, got %q", c.want, got)
+			}
+		})
+	}
+}
+
+func TestTailBuffer(t *testing.T) {
+	b := &tailBuffer{max: 4}
+	fmt.Fprint(b, "foo")
+	fmt.Fprint(b, "bar")
+	if got := string(b.Bytes()); got != "obar" {
+		t.Errorf("expected %q, got %q", "obar", got)
+	}
+}
@@ -0,0 +1,36 @@
+package git
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/soft-serve/git"
+	"github.com/charmbracelet/soft-serve/pkg/config"
+)
+
+// packConfigArgs returns the git options applying the server pack settings to
+// the repository in dir. Settings in the repository git config take
+// precedence, and settings matching git's defaults are left out.
+func packConfigArgs(cfg *config.Config, dir string) []string {
+	if cfg == nil {
+		return nil
+	}
+
+	var compression, window string
+	if r, err := git.Open(dir); err == nil {
+		if rcfg, err := r.Config(); err == nil {
+			sec := rcfg.Section("pack")
+			compression = sec.Option("compression")
+			window = sec.Option("window")
+		}
+	}
+
+	var args []string
+	if compression == "" && cfg.Pack.Compression != config.DefaultPackCompression {
+		args = append(args, "-c", fmt.Sprintf("pack.compression=%d", cfg.Pack.Compression))
+	}
+	if window == "" && cfg.Pack.Window != config.DefaultPackWindow {
+		args = append(args, "-c", fmt.Sprintf("pack.window=%d", cfg.Pack.Window))
+	}
+
+	return args
+}
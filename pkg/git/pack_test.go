@@ -0,0 +1,112 @@
+package git
+
+import (
+	"fmt"
+	"math/rand"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/soft-serve/pkg/config"
+)
+
+func TestPackConfigArgs(t *testing.T) {
+	dir := t.TempDir()
+	gitCmd(t, dir, "init", "--bare", "-q")
+
+	cfg := config.DefaultConfig()
+	if args := packConfigArgs(cfg, dir); len(args) != 0 {
+		t.Errorf("expected no args for git's defaults, got %q", args)
+	}
+
+	cfg.Pack = config.PackConfig{Compression: 9, Window: 50}
+	args := strings.Join(packConfigArgs(cfg, dir), " ")
+	if want := "-c pack.compression=9 -c pack.window=50"; args != want {
+		t.Errorf("expected %q, got %q", want, args)
+	}
+
+	// The repository settings take precedence.
+	gitCmd(t, dir, "config", "pack.compression", "1")
+	args = strings.Join(packConfigArgs(cfg, dir), " ")
+	if want := "-c pack.window=50"; args != want {
+		t.Errorf("expected %q, got %q", want, args)
+	}
+}
+
+// BenchmarkPackSettings measures the size and the time it takes to create the
+// pack of a clone with different pack settings.
+func BenchmarkPackSettings(b *testing.B) {
+	dir := b.TempDir()
+	gitCmd(b, dir, "init", "-q")
+	r := rand.New(rand.NewSource(1))
+	words := strings.Fields("func return if else for range var const type struct interface package import error nil true false string int map chan go defer")
+	line := func() string {
+		var sb strings.Builder
+		for w := 0; w < 8; w++ {
+			sb.WriteString(words[r.Intn(len(words))])
+			sb.WriteByte(' ')
+		}
+		return sb.String()
+	}
+
+	// Evolve a few files over many commits, so the pack has deltas.
+	files := make([][]string, 10)
+	for f := range files {
+		for l := 0; l < 500; l++ {
+			files[f] = append(files[f], line())
+		}
+	}
+	for i := 0; i < 100; i++ {
+		for f := range files {
+			for c := 0; c < 5; c++ {
+				files[f][r.Intn(len(files[f]))] = line()
+			}
+			files[f] = append(files[f], line())
+			content := strings.Join(files[f], "\n")
+			if err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("file%d.go", f)), []byte(content), 0o644); err != nil {
+				b.Fatal(err)
+			}
+		}
+		gitCmd(b, dir, "add", "-A")
+		gitCmd(b, dir, "-c", "user.name=bench", "-c", "user.email=bench@example.com", "commit", "-q", "-m", fmt.Sprintf("commit %d", i))
+	}
+
+	for _, p := range []config.PackConfig{
+		{Compression: 0, Window: config.DefaultPackWindow},
+		{Compression: 1, Window: config.DefaultPackWindow},
+		{Compression: config.DefaultPackCompression, Window: config.DefaultPackWindow},
+		{Compression: 9, Window: config.DefaultPackWindow},
+		{Compression: config.DefaultPackCompression, Window: 0},
+		{Compression: config.DefaultPackCompression, Window: 50},
+		{Compression: 9, Window: 250},
+	} {
+		b.Run(fmt.Sprintf("compression=%d/window=%d", p.Compression, p.Window), func(b *testing.B) {
+			var size int
+			for i := 0; i < b.N; i++ {
+				cmd := exec.Command("git",
+					"-c", fmt.Sprintf("pack.compression=%d", p.Compression),
+					"-c", fmt.Sprintf("pack.window=%d", p.Window),
+					"pack-objects", "--revs", "--all", "--stdout", "-q",
+				)
+				cmd.Dir = dir
+				out, err := cmd.Output()
+				if err != nil {
+					b.Fatal(err)
+				}
+				size = len(out)
+			}
+			b.ReportMetric(float64(size), "pack-bytes")
+		})
+	}
+}
+
+func gitCmd(tb testing.TB, dir string, args ...string) {
+	tb.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		tb.Fatalf("git %s: %v: %s", strings.Join(args, " "), err, out)
+	}
+}
@@ -1,16 +1,66 @@
 package git
 
 import (
+	"bytes"
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
 	"strings"
 	"sync"
+	"time"
+	"unicode"
 
 	"github.com/charmbracelet/log"
+	"github.com/charmbracelet/soft-serve/pkg/backend"
+	"github.com/charmbracelet/soft-serve/pkg/config"
+	"github.com/charmbracelet/soft-serve/pkg/hooks"
+	"github.com/charmbracelet/soft-serve/pkg/tracing"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var serviceTimeoutCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "soft_serve",
+	Subsystem: "git",
+	Name:      "service_timeout_total",
+	Help:      "The total number of git service operations killed for exceeding their timeout",
+}, []string{"service"})
+
+var (
+	// certNonceSeed is the secret git-receive-pack uses to generate and check
+	// push certificate nonces.
+	certNonceSeed = func() string {
+		b := make([]byte, 32)
+		rand.Read(b) // nolint: errcheck
+		return hex.EncodeToString(b)
+	}()
+
+	// certNonceSlop is the number of seconds a nonce stays valid across
+	// requests of stateless transports like HTTP.
+	certNonceSlop = 5 * 60
+
+	// absPathRe matches absolute paths in git output.
+	absPathRe = regexp.MustCompile(`(^|[\s'"(=])/[^\s'"):]*`)
+)
+
+const (
+	// maxStderrCapture is the number of trailing bytes of git stderr kept for
+	// logging and error summaries.
+	maxStderrCapture = 8 << 10
+
+	// maxStderrSummary is the maximum length of the git error summary
+	// returned to clients.
+	maxStderrSummary = 200
 )
 
 // Service is a Git daemon service.
@@ -53,11 +103,47 @@ func (s Service) Handler(ctx context.Context, cmd ServiceCommand) error {
 	}
 }
 
+// Timeout returns the configured maximum duration of the service operation.
+// Zero means no timeout.
+func (s Service) Timeout(cfg *config.Config) time.Duration {
+	if cfg == nil {
+		return 0
+	}
+
+	var secs int
+	switch s {
+	case UploadPackService:
+		secs = cfg.Timeouts.UploadPack
+	case ReceivePackService:
+		secs = cfg.Timeouts.ReceivePack
+	}
+
+	return time.Duration(secs) * time.Second
+}
+
 // ServiceHandler is a git service command handler.
 type ServiceHandler func(ctx context.Context, cmd ServiceCommand) error
 
 // gitServiceHandler is the default service handler using the git binary.
 func gitServiceHandler(ctx context.Context, svc Service, scmd ServiceCommand) error {
+	ctx, span := tracing.Start(ctx, "git."+svc.Name(), trace.WithAttributes(
+		attribute.String("service", svc.Name()),
+	))
+	err := runGitService(ctx, svc, scmd)
+	tracing.End(span, err)
+	return err
+}
+
+// runGitService runs a git service using the git binary.
+func runGitService(ctx context.Context, svc Service, scmd ServiceCommand) error {
+	cfg := config.FromContext(ctx)
+	timeout := svc.Timeout(cfg)
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, timeout, ErrServiceTimeout)
+		defer cancel()
+	}
+
 	cmd := exec.CommandContext(ctx, "git")
 	cmd.Dir = scmd.Dir
 	cmd.Args = append(cmd.Args, []string{
@@ -65,10 +151,21 @@ func gitServiceHandler(ctx context.Context, svc Service, scmd ServiceCommand) er
 		"-c", "uploadpack.allowFilter=true",
 		// Enable push options
 		"-c", "receive.advertisePushOptions=true",
+		// Enable signed pushes. The push certificate signature is verified by
+		// the pre-receive hook against the registered user keys. Git runs its
+		// own check first, the empty allowed signers file keeps it from
+		// failing on SSH signatures.
+		"-c", "receive.certNonceSeed=" + certNonceSeed,
+		"-c", fmt.Sprintf("receive.certNonceSlop=%d", certNonceSlop),
+		"-c", "gpg.ssh.allowedSignersFile=" + os.DevNull,
 		// Disable LFS filters
 		"-c", "filter.lfs.required=", "-c", "filter.lfs.smudge=", "-c", "filter.lfs.clean=",
-		svc.Name(),
 	}...)
+	if svc == UploadPackService {
+		cmd.Args = append(cmd.Args, packConfigArgs(cfg, scmd.Dir)...)
+	}
+
+	cmd.Args = append(cmd.Args, svc.Name())
 	if len(scmd.Args) > 0 {
 		cmd.Args = append(cmd.Args, scmd.Args...)
 	}
@@ -80,6 +177,17 @@ func gitServiceHandler(ctx context.Context, svc Service, scmd ServiceCommand) er
 		cmd.Env = append(cmd.Env, scmd.Env...)
 	}
 
+	// Hooks run in their own process and report their executions to a file
+	// read once git is done.
+	if svc == ReceivePackService {
+		if report, err := newHookReport(); err != nil {
+			log.FromContext(ctx).Error("failed to create hook report", "err", err)
+		} else {
+			cmd.Env = append(cmd.Env, hooks.ReportEnv+"="+report)
+			defer recordHookReport(ctx, report)
+		}
+	}
+
 	if scmd.CmdFunc != nil {
 		scmd.CmdFunc(cmd)
 	}
@@ -91,6 +199,18 @@ func gitServiceHandler(ctx context.Context, svc Service, scmd ServiceCommand) er
 		stderr io.ReadCloser
 	)
 
+	// Keep the end of stderr around for logging and error summaries.
+	var captured *tailBuffer
+	stderrW := scmd.Stderr
+	if cfg != nil && (cfg.Log.GitStderr || cfg.Log.GitErrorDetails) {
+		captured = &tailBuffer{max: maxStderrCapture}
+		if stderrW != nil {
+			stderrW = io.MultiWriter(stderrW, captured)
+		} else {
+			stderrW = captured
+		}
+	}
+
 	if scmd.Stdin != nil {
 		stdin, err = cmd.StdinPipe()
 		if err != nil {
@@ -105,7 +225,7 @@ func gitServiceHandler(ctx context.Context, svc Service, scmd ServiceCommand) er
 		}
 	}
 
-	if scmd.Stderr != nil {
+	if stderrW != nil {
 		stderr, err = cmd.StderrPipe()
 		if err != nil {
 			return err
@@ -143,11 +263,11 @@ func gitServiceHandler(ctx context.Context, svc Service, scmd ServiceCommand) er
 	}
 
 	// stderr
-	if scmd.Stderr != nil {
+	if stderrW != nil {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			if _, erro := io.Copy(scmd.Stderr, stderr); err != nil {
+			if _, erro := io.Copy(stderrW, stderr); err != nil {
 				log.Errorf("gitServiceHandler: failed to copy stderr: %v", erro)
 			}
 		}()
@@ -159,12 +279,37 @@ func gitServiceHandler(ctx context.Context, svc Service, scmd ServiceCommand) er
 	wg.Wait()
 
 	err = cmd.Wait()
+	var stderrOut []byte
+	if captured != nil {
+		stderrOut = bytes.TrimSpace(captured.Bytes())
+		if cfg.Log.GitStderr && len(stderrOut) > 0 {
+			log.FromContext(ctx).Debug("git stderr", "service", svc, "dir", scmd.Dir, "stderr", string(stderrOut))
+		}
+	}
+
+	if errors.Is(context.Cause(ctx), ErrServiceTimeout) {
+		serviceTimeoutCounter.WithLabelValues(svc.Name()).Inc()
+		log.FromContext(ctx).Warn("git service timed out", "service", svc, "dir", scmd.Dir, "timeout", timeout)
+		return ErrServiceTimeout
+	}
+
 	if err != nil && errors.Is(err, os.ErrNotExist) {
 		return ErrInvalidRepo
 	} else if err != nil {
 		var exitErr *exec.ExitError
 		if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
-			return fmt.Errorf("%s: %s", exitErr, exitErr.Stderr)
+			err = fmt.Errorf("%s: %s", exitErr, exitErr.Stderr)
+		}
+
+		if captured != nil {
+			var paths []string
+			for _, p := range []string{scmd.Dir, cfg.DataPath} {
+				paths = append(paths, p)
+				if abs, err := filepath.Abs(p); err == nil {
+					paths = append(paths, abs)
+				}
+			}
+			return &ServiceError{Err: err, Summary: stderrSummary(stderrOut, paths...)}
 		}
 
 		return err
@@ -173,6 +318,97 @@ func gitServiceHandler(ctx context.Context, svc Service, scmd ServiceCommand) er
 	return nil
 }
 
+// newHookReport creates an empty hook report file and returns its path.
+func newHookReport() (string, error) {
+	f, err := os.CreateTemp("", "soft-serve-hooks-*.jsonl")
+	if err != nil {
+		return "", err
+	}
+
+	return f.Name(), f.Close()
+}
+
+// recordHookReport records the hook executions of a report file, starts the
+// post-receive exec command, and removes it.
+func recordHookReport(ctx context.Context, path string) {
+	defer os.Remove(path) // nolint: errcheck
+
+	execs, err := hooks.ReadReport(path)
+	if err != nil {
+		log.FromContext(ctx).Error("failed to read hook report", "err", err)
+		return
+	}
+
+	if be := backend.FromContext(ctx); be != nil && len(execs) > 0 {
+		// The operation may have been canceled, the webhooks are still sent.
+		be.RecordHookExecutions(context.WithoutCancel(ctx), execs)
+		be.PostReceiveExec(ctx, execs)
+	}
+}
+
+// stderrSummary returns the last line of git stderr output with the given
+// paths and any other absolute paths removed, so it can be shown to clients.
+func stderrSummary(stderr []byte, paths ...string) string {
+	lines := strings.FieldsFunc(string(stderr), func(r rune) bool {
+		return r == '\n' || r == '\r'
+	})
+
+	var line string
+	for i := len(lines) - 1; i >= 0; i-- {
+		if l := strings.TrimSpace(lines[i]); l != "" {
+			line = l
+			break
+		}
+	}
+
+	// Replace longer paths first so a path isn't partly replaced by one of
+	// its parents.
+	sort.Slice(paths, func(i, j int) bool { return len(paths[i]) > len(paths[j]) })
+	for _, p := range paths {
+		if p != "" && p != "." && p != string(filepath.Separator) {
+			line = strings.ReplaceAll(line, p, "<path>")
+		}
+	}
+	line = absPathRe.ReplaceAllString(line, "${1}<path>")
+	line = strings.Map(func(r rune) rune {
+		if unicode.IsPrint(r) {
+			return r
+		}
+		return -1
+	}, line)
+
+	if r := []rune(line); len(r) > maxStderrSummary {
+		line = string(r[:maxStderrSummary]) + "..."
+	}
+
+	return line
+}
+
+// tailBuffer is an io.Writer that keeps the last max bytes written to it.
+type tailBuffer struct {
+	mu  sync.Mutex
+	max int
+	buf []byte
+}
+
+// Write implements io.Writer.
+func (b *tailBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.buf = append(b.buf, p...)
+	if len(b.buf) > b.max {
+		b.buf = b.buf[len(b.buf)-b.max:]
+	}
+	return len(p), nil
+}
+
+// Bytes returns the kept bytes.
+func (b *tailBuffer) Bytes() []byte {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]byte(nil), b.buf...)
+}
+
 // ServiceCommand is used to run a git service command.
 type ServiceCommand struct {
 	Stdin  io.Reader
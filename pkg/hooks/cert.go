@@ -0,0 +1,33 @@
+package hooks
+
+import "os"
+
+// Push certificate nonce statuses as reported by git. A nonce is "SLOP" when
+// it was issued by the server within receive.certNonceSlop seconds, which
+// happens with stateless transports like HTTP.
+const (
+	NonceStatusOK   = "OK"
+	NonceStatusSlop = "SLOP"
+)
+
+// PushCert is the push certificate sent with `git push --signed`.
+type PushCert struct {
+	// ID is the object ID of the certificate blob.
+	ID string
+	// NonceStatus is the result of git's nonce check, e.g. "OK" or "BAD".
+	NonceStatus string
+}
+
+// PushCertificate returns the push certificate sent by the client, if any.
+// It's only available to the pre-receive and post-receive hooks.
+func PushCertificate() (PushCert, bool) {
+	id := os.Getenv("GIT_PUSH_CERT")
+	if id == "" {
+		return PushCert{}, false
+	}
+
+	return PushCert{
+		ID:          id,
+		NonceStatus: os.Getenv("GIT_PUSH_CERT_NONCE_STATUS"),
+	}, true
+}
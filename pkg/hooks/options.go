@@ -0,0 +1,35 @@
+package hooks
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// PushOptions returns the push options sent by the client, i.e. the values
+// passed with `git push -o`. These are only available to the pre-receive and
+// post-receive hooks.
+func PushOptions() []string {
+	n, err := strconv.Atoi(os.Getenv("GIT_PUSH_OPTION_COUNT"))
+	if err != nil {
+		return nil
+	}
+
+	opts := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		opts = append(opts, os.Getenv(fmt.Sprintf("GIT_PUSH_OPTION_%d", i)))
+	}
+
+	return opts
+}
+
+// HasPushOption returns true if the client sent the given push option.
+func HasPushOption(opt string) bool {
+	for _, o := range PushOptions() {
+		if o == opt {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,93 @@
+package hooks
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"time"
+)
+
+// ReportEnv is the environment variable with the path of the file hook
+// executions are reported to. Hooks run in their own process, the server
+// reads the report once git is done.
+const ReportEnv = "SOFT_SERVE_HOOK_REPORT"
+
+// Hook execution outcomes.
+const (
+	// OutcomeAccepted is a hook that succeeded.
+	OutcomeAccepted = "accepted"
+	// OutcomeRejected is a hook that rejected the push on purpose, like a
+	// policy check or a custom hook exiting with a non-zero status.
+	OutcomeRejected = "rejected"
+	// OutcomeErrored is a hook that failed to run, or timed out.
+	OutcomeErrored = "errored"
+)
+
+// Execution is a hook execution.
+type Execution struct {
+	Hook     string        `json:"hook"`
+	Repo     string        `json:"repo"`
+	Outcome  string        `json:"outcome"`
+	Start    time.Time     `json:"start"`
+	Duration time.Duration `json:"duration"`
+	// TimedOut is whether the hook was killed for running too long.
+	TimedOut bool `json:"timed_out,omitempty"`
+	// Error is the error of a rejected or errored hook.
+	Error string `json:"error,omitempty"`
+	// Updates are the ref updates of a post-receive hook.
+	Updates []HookArg `json:"updates,omitempty"`
+}
+
+// WriteReport appends a hook execution to the report file of the
+// environment. It's a no-op if there's no report file.
+func WriteReport(e Execution) error {
+	path := os.Getenv(ReportEnv)
+	if path == "" {
+		return nil
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return err
+	}
+
+	if err := json.NewEncoder(f).Encode(e); err != nil {
+		f.Close() // nolint: errcheck
+		return err
+	}
+
+	return f.Close()
+}
+
+// ReadReport reads the hook executions from a report file. Malformed lines
+// are skipped.
+func ReadReport(path string) ([]Execution, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close() // nolint: errcheck
+
+	// Lines can be long, post-receive executions list every updated ref, so
+	// they're read whole instead of with a scanner and its line limit.
+	var execs []Execution
+	r := bufio.NewReader(f)
+	for {
+		line, err := r.ReadBytes('\n')
+		if len(bytes.TrimSpace(line)) > 0 {
+			var e Execution
+			if jerr := json.Unmarshal(line, &e); jerr == nil {
+				execs = append(execs, e)
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			return execs, nil
+		}
+		if err != nil {
+			return execs, err
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package hooks
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadReportManyUpdates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report")
+	t.Setenv(ReportEnv, path)
+
+	updates := make([]HookArg, 1000)
+	for i := range updates {
+		updates[i] = HookArg{
+			OldSha:  "0000000000000000000000000000000000000000",
+			NewSha:  "1111111111111111111111111111111111111111",
+			RefName: fmt.Sprintf("refs/tags/v%d", i),
+		}
+	}
+
+	if err := WriteReport(Execution{Hook: "pre-receive", Repo: "repo", Outcome: OutcomeAccepted}); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteReport(Execution{Hook: "post-receive", Repo: "repo", Outcome: OutcomeAccepted, Updates: updates}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Malformed lines are skipped.
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fmt.Fprintln(f, "not json") // nolint: errcheck
+	f.Close()                   // nolint: errcheck
+
+	execs, err := ReadReport(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(execs) != 2 {
+		t.Fatalf("ReadReport() returned %d executions, want 2", len(execs))
+	}
+	if got := len(execs[1].Updates); got != len(updates) {
+		t.Errorf("post-receive execution has %d updates, want %d", got, len(updates))
+	}
+}
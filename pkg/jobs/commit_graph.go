@@ -0,0 +1,74 @@
+package jobs
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/charmbracelet/log"
+	"github.com/charmbracelet/soft-serve/pkg/backend"
+	"github.com/charmbracelet/soft-serve/pkg/config"
+)
+
+func init() {
+	Register("commit-graph", commitGraph{})
+}
+
+type commitGraph struct{}
+
+// commitGraphRunning prevents overlapping runs when writing the commit-graphs
+// takes longer than the job interval.
+var commitGraphRunning atomic.Bool
+
+// Spec derives the spec used for commit-graphs and implements Runner.
+func (c commitGraph) Spec(ctx context.Context) string {
+	cfg := config.FromContext(ctx)
+	if cfg.Jobs.CommitGraph != "" {
+		return cfg.Jobs.CommitGraph
+	}
+	return "@every 1h"
+}
+
+// Func writes the missing and stale commit-graphs of all repositories and
+// implements Runner. Repositories are written one at a time, commit-graph
+// writes are CPU and IO intensive.
+func (c commitGraph) Func(ctx context.Context) func() {
+	cfg := config.FromContext(ctx)
+	logger := log.FromContext(ctx).WithPrefix("jobs.commit-graph")
+	b := backend.FromContext(ctx)
+	return func() {
+		if !cfg.CommitGraph.Enabled {
+			return
+		}
+
+		if !commitGraphRunning.CompareAndSwap(false, true) {
+			logger.Debug("commit-graphs are already being written")
+			return
+		}
+		defer commitGraphRunning.Store(false)
+
+		repos, err := b.Repositories(ctx)
+		if err != nil {
+			logger.Error("error getting repositories", "err", err)
+			return
+		}
+
+		for _, r := range repos {
+			if ctx.Err() != nil {
+				return
+			}
+
+			// Writes after a push are covered by the lock of the push.
+			unlock, err := b.RLockRepository(ctx, r.Name(), "commit-graph")
+			if err != nil {
+				logger.Info("skipping locked repository", "repo", r.Name(), "err", err)
+				continue
+			}
+
+			err = b.WriteCommitGraph(ctx, r.Name())
+			unlock()
+			if err != nil {
+				logger.Error("error writing commit-graph", "repo", r.Name(), "err", err)
+			}
+		}
+	}
+}
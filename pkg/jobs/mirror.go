@@ -2,18 +2,11 @@ package jobs
 
 import (
 	"context"
-	"fmt"
-	"path/filepath"
 	"runtime"
-	"strings"
 
 	"github.com/charmbracelet/log"
-	"github.com/charmbracelet/soft-serve/git"
 	"github.com/charmbracelet/soft-serve/pkg/backend"
 	"github.com/charmbracelet/soft-serve/pkg/config"
-	"github.com/charmbracelet/soft-serve/pkg/db"
-	"github.com/charmbracelet/soft-serve/pkg/lfs"
-	"github.com/charmbracelet/soft-serve/pkg/store"
 	"github.com/charmbracelet/soft-serve/pkg/sync"
 )
 
@@ -34,11 +27,8 @@ func (m mirrorPull) Spec(ctx context.Context) string {
 
 // Func runs the (pull) mirror job task and implements Runner.
 func (m mirrorPull) Func(ctx context.Context) func() {
-	cfg := config.FromContext(ctx)
 	logger := log.FromContext(ctx).WithPrefix("jobs.mirror")
 	b := backend.FromContext(ctx)
-	dbx := db.FromContext(ctx)
-	datastore := store.FromContext(ctx)
 	return func() {
 		repos, err := b.Repositories(ctx)
 		if err != nil {
@@ -54,67 +44,16 @@ func (m mirrorPull) Func(ctx context.Context) func() {
 		logger.Debug("updating mirror repos")
 		for _, repo := range repos {
 			if repo.IsMirror() {
-				r, err := repo.Open()
-				if err != nil {
-					logger.Error("error opening repository", "repo", repo.Name(), "err", err)
-					continue
-				}
-
+				repo := repo
 				name := repo.Name()
 				wq.Add(name, func() {
-					repo := repo
-
-					cmds := []string{
-						"fetch --prune",         // fetch prune before updating remote
-						"remote update --prune", // update remote and prune remote refs
-					}
-
-					for _, c := range cmds {
-						args := strings.Split(c, " ")
-						cmd := git.NewCommand(args...).WithContext(ctx)
-						cmd.AddEnvs(
-							fmt.Sprintf(`GIT_SSH_COMMAND=ssh -o UserKnownHostsFile="%s" -o StrictHostKeyChecking=no -i "%s"`,
-								filepath.Join(cfg.DataPath, "ssh", "known_hosts"),
-								cfg.SSH.ClientKeyPath,
-							),
-						)
-
-						if _, err := cmd.RunInDir(r.Path); err != nil {
-							logger.Error("error running git remote update", "repo", name, "err", err)
-						}
+					res, err := b.SyncMirror(ctx, repo)
+					if err != nil {
+						logger.Error("error syncing mirror", "repo", name, "status", res.Status, "err", err)
+						return
 					}
 
-					if cfg.LFS.Enabled {
-						rcfg, err := r.Config()
-						if err != nil {
-							logger.Error("error getting git config", "repo", name, "err", err)
-							return
-						}
-
-						lfsEndpoint := rcfg.Section("lfs").Option("url")
-						if lfsEndpoint == "" {
-							// If there is no LFS url defined, means the repo
-							// doesn't use LFS and we can skip it.
-							return
-						}
-
-						ep, err := lfs.NewEndpoint(lfsEndpoint)
-						if err != nil {
-							logger.Error("error creating LFS endpoint", "repo", name, "err", err)
-							return
-						}
-
-						client := lfs.NewClient(ep)
-						if client == nil {
-							logger.Errorf("failed to create lfs client: unsupported endpoint %s", lfsEndpoint)
-							return
-						}
-
-						if err := backend.StoreRepoMissingLFSObjects(ctx, repo, dbx, datastore, client); err != nil {
-							logger.Error("failed to store missing lfs objects", "err", err, "path", r.Path)
-							return
-						}
-					}
+					logger.Debug("synced mirror", "repo", name, "objects", res.NewObjects, "refs", res.UpdatedRefs, "bytes", res.FetchedBytes)
 				})
 			}
 		}
@@ -0,0 +1,36 @@
+package jobs
+
+import (
+	"context"
+
+	"github.com/charmbracelet/log"
+	"github.com/charmbracelet/soft-serve/pkg/backend"
+	"github.com/charmbracelet/soft-serve/pkg/config"
+)
+
+func init() {
+	Register("prune", prune{})
+}
+
+type prune struct{}
+
+// Spec derives the spec used for pruning and implements Runner.
+func (p prune) Spec(ctx context.Context) string {
+	cfg := config.FromContext(ctx)
+	if cfg.Jobs.Prune != "" {
+		return cfg.Jobs.Prune
+	}
+	return "@every 24h"
+}
+
+// Func deletes the activity records that are past their retention and
+// implements Runner.
+func (p prune) Func(ctx context.Context) func() {
+	logger := log.FromContext(ctx).WithPrefix("jobs.prune")
+	b := backend.FromContext(ctx)
+	return func() {
+		if _, err := b.PruneRecords(ctx); err != nil {
+			logger.Error("error pruning activity records", "err", err)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package jobs
+
+import (
+	"context"
+	"runtime"
+	"sync/atomic"
+
+	"github.com/charmbracelet/log"
+	"github.com/charmbracelet/soft-serve/pkg/backend"
+	"github.com/charmbracelet/soft-serve/pkg/config"
+	"github.com/charmbracelet/soft-serve/pkg/proto"
+	"github.com/charmbracelet/soft-serve/pkg/sync"
+)
+
+func init() {
+	Register("push-mirror", pushMirror{})
+}
+
+type pushMirror struct{}
+
+// pushMirrorRunning prevents overlapping runs when pushes take longer than
+// the job interval.
+var pushMirrorRunning atomic.Bool
+
+// Spec derives the spec used for push mirrors and implements Runner.
+func (m pushMirror) Spec(ctx context.Context) string {
+	cfg := config.FromContext(ctx)
+	if cfg.Jobs.PushMirror != "" {
+		return cfg.Jobs.PushMirror
+	}
+	return "@every 1m"
+}
+
+// Func runs the pending push mirrors and implements Runner.
+func (m pushMirror) Func(ctx context.Context) func() {
+	logger := log.FromContext(ctx).WithPrefix("jobs.push-mirror")
+	b := backend.FromContext(ctx)
+	return func() {
+		if !pushMirrorRunning.CompareAndSwap(false, true) {
+			logger.Debug("push mirrors are already running")
+			return
+		}
+		defer pushMirrorRunning.Store(false)
+
+		mirrors, err := b.PendingPushMirrors(ctx)
+		if err != nil {
+			logger.Error("error getting pending push mirrors", "err", err)
+			return
+		}
+
+		if len(mirrors) == 0 {
+			return
+		}
+
+		repos, err := b.Repositories(ctx)
+		if err != nil {
+			logger.Error("error getting repositories", "err", err)
+			return
+		}
+
+		byID := make(map[int64]proto.Repository, len(repos))
+		for _, r := range repos {
+			byID[r.ID()] = r
+		}
+
+		// Divide the work up among the number of CPUs.
+		wq := sync.NewWorkPool(ctx, runtime.GOMAXPROCS(0),
+			sync.WithWorkPoolLogger(logger.Errorf),
+		)
+
+		logger.Debug("pushing mirrors", "count", len(mirrors))
+		for _, pm := range mirrors {
+			pm := pm
+			repo, ok := byID[pm.RepoID]
+			if !ok {
+				continue
+			}
+
+			wq.Add(repo.Name()+":"+pm.Name, func() {
+				if err := b.RunPushMirror(ctx, repo, pm); err != nil {
+					logger.Error("error pushing mirror", "repo", repo.Name(), "mirror", pm.Name, "attempt", pm.Attempts+1, "err", err)
+				}
+			})
+		}
+
+		wq.Run()
+	}
+}
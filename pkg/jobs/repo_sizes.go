@@ -0,0 +1,47 @@
+package jobs
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/charmbracelet/log"
+	"github.com/charmbracelet/soft-serve/pkg/backend"
+	"github.com/charmbracelet/soft-serve/pkg/config"
+)
+
+func init() {
+	Register("repo-sizes", repoSizes{})
+}
+
+type repoSizes struct{}
+
+// repoSizesRunning prevents overlapping runs when walking the repositories
+// takes longer than the job interval.
+var repoSizesRunning atomic.Bool
+
+// Spec derives the spec used for repo sizes and implements Runner.
+func (r repoSizes) Spec(ctx context.Context) string {
+	cfg := config.FromContext(ctx)
+	if cfg.Jobs.RepoSizes != "" {
+		return cfg.Jobs.RepoSizes
+	}
+	return "@every 10m"
+}
+
+// Func recomputes the stale cached sizes of all repositories, and reports
+// them in the metrics, and implements Runner.
+func (r repoSizes) Func(ctx context.Context) func() {
+	logger := log.FromContext(ctx).WithPrefix("jobs.repo-sizes")
+	b := backend.FromContext(ctx)
+	return func() {
+		if !repoSizesRunning.CompareAndSwap(false, true) {
+			logger.Debug("repository sizes are already being computed")
+			return
+		}
+		defer repoSizesRunning.Store(false)
+
+		if err := b.RefreshRepoSizes(ctx); err != nil {
+			logger.Error("error refreshing repository sizes", "err", err)
+		}
+	}
+}
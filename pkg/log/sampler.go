@@ -0,0 +1,99 @@
+package log
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var droppedCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "soft_serve",
+	Subsystem: "log",
+	Name:      "dropped_total",
+	Help:      "The total number of operation logs dropped by sampling or rate limiting",
+}, []string{"reason"})
+
+// SamplerContextKey is the context key for the log sampler.
+var SamplerContextKey = struct{ string }{"log-sampler"}
+
+// WithSampler returns a new context with the log sampler attached.
+func WithSampler(ctx context.Context, s *Sampler) context.Context {
+	return context.WithValue(ctx, SamplerContextKey, s)
+}
+
+// SamplerFromContext returns the log sampler from the context.
+func SamplerFromContext(ctx context.Context) *Sampler {
+	if s, ok := ctx.Value(SamplerContextKey).(*Sampler); ok {
+		return s
+	}
+
+	return nil
+}
+
+// Sampler decides which successful operations get logged. It keeps one in
+// every rate operations and at most limit operations per second. Failed
+// operations should always be logged and don't go through the sampler.
+//
+// A nil Sampler keeps every operation.
+type Sampler struct {
+	mu      sync.Mutex
+	rate    int
+	limit   int
+	count   int
+	window  time.Time
+	written int
+	now     func() time.Time
+}
+
+// NewSampler returns a new Sampler that keeps one in every rate operations
+// and at most limit operations per second. A rate of 0 or 1 keeps every
+// operation, and a limit of 0 means no limit.
+func NewSampler(rate, limit int) *Sampler {
+	s := &Sampler{now: time.Now}
+	s.Set(rate, limit)
+	return s
+}
+
+// Set updates the sampling rate and the rate limit.
+func (s *Sampler) Set(rate, limit int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rate = rate
+	s.limit = limit
+	s.count = 0
+}
+
+// Sample reports whether an operation should be logged.
+func (s *Sampler) Sample() bool {
+	if s == nil {
+		return true
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n := s.count
+	s.count++
+	if s.rate > 1 && n%s.rate != 0 {
+		droppedCounter.WithLabelValues("sampled").Inc()
+		return false
+	}
+
+	if s.limit > 0 {
+		now := s.now()
+		if now.Sub(s.window) >= time.Second {
+			s.window = now
+			s.written = 0
+		}
+		if s.written >= s.limit {
+			droppedCounter.WithLabelValues("rate_limited").Inc()
+			return false
+		}
+		s.written++
+	}
+
+	return true
+}
@@ -0,0 +1,51 @@
+package log
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSamplerRate(t *testing.T) {
+	s := NewSampler(3, 0)
+	var kept int
+	for i := 0; i < 9; i++ {
+		if s.Sample() {
+			kept++
+		}
+	}
+	if kept != 3 {
+		t.Errorf("expected 3 sampled operations, got %d", kept)
+	}
+
+	// Changing the rate at runtime starts over.
+	s.Set(1, 0)
+	for i := 0; i < 5; i++ {
+		if !s.Sample() {
+			t.Errorf("expected operation %d to be sampled", i)
+		}
+	}
+}
+
+func TestSamplerRateLimit(t *testing.T) {
+	now := time.Now()
+	s := NewSampler(0, 2)
+	s.now = func() time.Time { return now }
+
+	for i, want := range []bool{true, true, false, false} {
+		if got := s.Sample(); got != want {
+			t.Errorf("operation %d: expected %t, got %t", i, want, got)
+		}
+	}
+
+	now = now.Add(time.Second)
+	if !s.Sample() {
+		t.Error("expected operation to be sampled in a new window")
+	}
+}
+
+func TestNilSampler(t *testing.T) {
+	var s *Sampler
+	if !s.Sample() {
+		t.Error("expected nil sampler to sample everything")
+	}
+}
@@ -0,0 +1,362 @@
+package log
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/charmbracelet/soft-serve/pkg/config"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	syslogSentCounter = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: "soft_serve",
+		Subsystem: "log",
+		Name:      "syslog_sent_total",
+		Help:      "The total number of messages sent to syslog",
+	})
+
+	syslogDroppedCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "soft_serve",
+		Subsystem: "log",
+		Name:      "syslog_dropped_total",
+		Help:      "The total number of syslog messages dropped because the buffer was full or the server shut down",
+	}, []string{"reason"})
+)
+
+// Syslog severities.
+const (
+	SeverityWarning = 4
+	SeverityNotice  = 5
+	SeverityInfo    = 6
+)
+
+// syslogSDID is the RFC 5424 structured data ID of the message fields. 32473
+// is the enterprise number reserved for documentation.
+const syslogSDID = "soft-serve@32473"
+
+const (
+	// syslogTimeout is the timeout of connecting and writing to the endpoint.
+	syslogTimeout = 5 * time.Second
+	// syslogMaxBackoff is the maximum wait between reconnection attempts.
+	syslogMaxBackoff = time.Minute
+	// syslogFlushTimeout is how long Close waits for buffered messages to be
+	// sent.
+	syslogFlushTimeout = 2 * time.Second
+)
+
+// SyslogContextKey is the context key for the syslog writer.
+var SyslogContextKey = struct{ string }{"syslog"}
+
+// WithSyslog returns a new context with the syslog writer attached.
+func WithSyslog(ctx context.Context, s *Syslog) context.Context {
+	return context.WithValue(ctx, SyslogContextKey, s)
+}
+
+// SyslogFromContext returns the syslog writer from the context.
+func SyslogFromContext(ctx context.Context) *Syslog {
+	if s, ok := ctx.Value(SyslogContextKey).(*Syslog); ok {
+		return s
+	}
+
+	return nil
+}
+
+// Syslog sends messages to a syslog endpoint in RFC 5424 format. Messages are
+// queued and sent in the background, so logging never blocks. While the
+// endpoint is unavailable, messages are buffered up to the buffer size, then
+// dropped following the drop policy.
+//
+// A nil Syslog discards every message.
+type Syslog struct {
+	cfg      config.SyslogConfig
+	facility int
+	hostname string
+	pid      string
+
+	mu      sync.Mutex
+	queue   chan []byte
+	closed  bool
+	stop    chan struct{}
+	done    chan struct{}
+	failing atomic.Bool
+	conn    net.Conn
+	dial    func() (net.Conn, error)
+}
+
+// NewSyslog returns a new Syslog writing to the configured endpoint. It
+// returns nil if syslog isn't configured. The connection is made in the
+// background.
+func NewSyslog(cfg config.SyslogConfig) (*Syslog, error) {
+	if cfg.Address == "" {
+		return nil, nil
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	facility, _ := config.SyslogFacility(cfg.Facility)
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		hostname = "-"
+	}
+
+	s := &Syslog{
+		cfg:      cfg,
+		facility: facility,
+		hostname: hostname,
+		pid:      strconv.Itoa(os.Getpid()),
+		queue:    make(chan []byte, cfg.BufferSize),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	s.dial = s.dialEndpoint
+
+	go s.run()
+	return s, nil
+}
+
+// Only reports whether access logs go to syslog only, instead of also being
+// written to the server logs.
+func (s *Syslog) Only() bool {
+	return s != nil && s.cfg.Only
+}
+
+// Log queues a message. The key-value pairs are sent as structured data. The
+// msgID identifies the type of the message, e.g. "ssh" or "audit".
+func (s *Syslog) Log(severity int, msgID string, msg string, keyvals ...interface{}) {
+	if s == nil {
+		return
+	}
+
+	m := s.format(time.Now(), severity, msgID, msg, keyvals...)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		syslogDroppedCounter.WithLabelValues("closed").Inc()
+		return
+	}
+
+	select {
+	case s.queue <- m:
+		return
+	default:
+	}
+
+	if s.cfg.DropPolicy == config.SyslogDropOldest {
+		select {
+		case <-s.queue:
+		default:
+		}
+		select {
+		case s.queue <- m:
+		default:
+		}
+	}
+
+	syslogDroppedCounter.WithLabelValues("buffer_full").Inc()
+}
+
+// Close stops accepting messages and waits a little for the buffered ones to
+// be sent. Messages that couldn't be sent in time, or at all because the
+// endpoint is unavailable, are dropped.
+func (s *Syslog) Close() error {
+	if s == nil {
+		return nil
+	}
+
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	close(s.queue)
+	s.mu.Unlock()
+
+	if s.failing.Load() {
+		close(s.stop)
+		return nil
+	}
+
+	select {
+	case <-s.done:
+	case <-time.After(syslogFlushTimeout):
+		close(s.stop)
+	}
+
+	return nil
+}
+
+// run sends the queued messages until the queue is closed and drained, or
+// Close gives up on flushing.
+func (s *Syslog) run() {
+	defer close(s.done)
+	defer func() {
+		if s.conn != nil {
+			s.conn.Close() // nolint: errcheck
+		}
+	}()
+
+	backoff := time.Second
+	for m := range s.queue {
+		for {
+			err := s.write(m)
+			s.failing.Store(err != nil)
+			if err == nil {
+				syslogSentCounter.Inc()
+				backoff = time.Second
+				break
+			}
+
+			// Keep the message and wait before trying again. New messages
+			// are buffered, or dropped when the buffer is full.
+			select {
+			case <-s.stop:
+				syslogDroppedCounter.WithLabelValues("closed").Add(float64(1 + len(s.queue)))
+				return
+			case <-time.After(backoff):
+			}
+
+			backoff *= 2
+			if backoff > syslogMaxBackoff {
+				backoff = syslogMaxBackoff
+			}
+		}
+	}
+}
+
+// write sends a message, connecting to the endpoint if needed.
+func (s *Syslog) write(m []byte) error {
+	if s.conn == nil {
+		conn, err := s.dial()
+		if err != nil {
+			return err
+		}
+		s.conn = conn
+	}
+
+	s.conn.SetWriteDeadline(time.Now().Add(syslogTimeout)) // nolint: errcheck
+	if _, err := s.conn.Write(s.frame(m)); err != nil {
+		s.conn.Close() // nolint: errcheck
+		s.conn = nil
+		return err
+	}
+
+	return nil
+}
+
+// dialEndpoint connects to the syslog endpoint.
+func (s *Syslog) dialEndpoint() (net.Conn, error) {
+	d := &net.Dialer{Timeout: syslogTimeout}
+	switch s.cfg.Network {
+	case "tls":
+		return tls.DialWithDialer(d, "tcp", s.cfg.Address, &tls.Config{MinVersion: tls.VersionTLS12})
+	case "unix":
+		// Local syslog daemons usually listen on a datagram socket.
+		conn, err := d.Dial("unixgram", s.cfg.Address)
+		if err == nil {
+			return conn, nil
+		}
+		return d.Dial("unix", s.cfg.Address)
+	default:
+		return d.Dial(s.cfg.Network, s.cfg.Address)
+	}
+}
+
+// frame returns the message as written on the connection. Stream transports
+// use octet counting framing (RFC 6587), datagrams hold a single message.
+func (s *Syslog) frame(m []byte) []byte {
+	if addr := s.conn.RemoteAddr(); addr != nil {
+		switch addr.Network() {
+		case "udp", "udp4", "udp6", "unixgram":
+			return m
+		}
+	}
+
+	return append([]byte(strconv.Itoa(len(m))+" "), m...)
+}
+
+// format returns an RFC 5424 message.
+func (s *Syslog) format(t time.Time, severity int, msgID string, msg string, keyvals ...interface{}) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "<%d>1 %s %s %s %s %s ",
+		s.facility*8+severity,
+		t.UTC().Format("2006-01-02T15:04:05.000000Z07:00"),
+		syslogHeaderField(s.hostname, 255),
+		syslogHeaderField(s.cfg.AppName, 48),
+		s.pid,
+		syslogHeaderField(msgID, 32),
+	)
+
+	if len(keyvals) < 2 {
+		b.WriteString("-")
+	} else {
+		b.WriteString("[" + syslogSDID)
+		for i := 0; i+1 < len(keyvals); i += 2 {
+			name := syslogParamName(fmt.Sprint(keyvals[i]))
+			if name == "" {
+				continue
+			}
+			fmt.Fprintf(&b, " %s=\"%s\"", name, syslogParamValue(fmt.Sprint(keyvals[i+1])))
+		}
+		b.WriteString("]")
+	}
+
+	if msg != "" {
+		b.WriteString(" " + msg)
+	}
+
+	return []byte(b.String())
+}
+
+// syslogHeaderField returns a header field of at most max printable ASCII
+// characters, or the nil value "-".
+func syslogHeaderField(v string, max int) string {
+	v = strings.Map(func(r rune) rune {
+		if r < 33 || r > 126 {
+			return -1
+		}
+		return r
+	}, v)
+	if len(v) > max {
+		v = v[:max]
+	}
+	if v == "" {
+		return "-"
+	}
+
+	return v
+}
+
+// syslogParamName returns a valid structured data parameter name of at most
+// 32 characters.
+func syslogParamName(v string) string {
+	v = strings.Map(func(r rune) rune {
+		if r < 33 || r > 126 || r == '=' || r == ']' || r == '"' {
+			return -1
+		}
+		return r
+	}, v)
+	if len(v) > 32 {
+		v = v[:32]
+	}
+
+	return v
+}
+
+// syslogParamValue escapes a structured data parameter value.
+func syslogParamValue(v string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`).Replace(v)
+}
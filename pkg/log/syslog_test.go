@@ -0,0 +1,211 @@
+package log
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/soft-serve/pkg/config"
+)
+
+func testSyslogConfig(network, addr string) config.SyslogConfig {
+	cfg := config.DefaultConfig().Log.Syslog
+	cfg.Network = network
+	cfg.Address = addr
+	return cfg
+}
+
+func TestSyslogFormat(t *testing.T) {
+	s := &Syslog{
+		cfg:      testSyslogConfig("udp", "localhost:514"),
+		facility: 16,
+		hostname: "git.example.com",
+		pid:      "42",
+	}
+
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 6000, time.UTC)
+	got := string(s.format(ts, SeverityInfo, "ssh", "session closed", "user", "alice", "cmd", `repo "x" [a]`, "bad key=", "v"))
+	want := `<134>1 2024-01-02T03:04:05.000006Z git.example.com soft-serve 42 ssh [soft-serve@32473 user="alice" cmd="repo \"x\" [a\]" badkey="v"] session closed`
+	if got != want {
+		t.Errorf("expected\n%s\ngot\n%s", want, got)
+	}
+
+	got = string(s.format(ts, SeverityWarning, "", "", nil...))
+	want = `<132>1 2024-01-02T03:04:05.000006Z git.example.com soft-serve 42 - -`
+	if got != want {
+		t.Errorf("expected\n%s\ngot\n%s", want, got)
+	}
+}
+
+func TestSyslogUDP(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pc.Close() // nolint: errcheck
+
+	s, err := NewSyslog(testSyslogConfig("udp", pc.LocalAddr().String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s.Log(SeverityInfo, "audit", "lockdown_enabled", "user", "admin")
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	buf := make([]byte, 1024)
+	pc.SetReadDeadline(time.Now().Add(5 * time.Second)) // nolint: errcheck
+	n, _, err := pc.ReadFrom(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	re := regexp.MustCompile(`^<134>1 \S+ \S+ soft-serve \d+ audit \[soft-serve@32473 user="admin"\] lockdown_enabled$`)
+	if !re.Match(buf[:n]) {
+		t.Errorf("unexpected message %q", buf[:n])
+	}
+}
+
+func TestSyslogTCP(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close() // nolint: errcheck
+
+	msgs := make(chan string, 2)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close() // nolint: errcheck
+
+		r := bufio.NewReader(conn)
+		for {
+			// Octet counting: the length, a space, then the message.
+			size, err := r.ReadString(' ')
+			if err != nil {
+				return
+			}
+			n, err := strconv.Atoi(strings.TrimSpace(size))
+			if err != nil {
+				return
+			}
+			m := make([]byte, n)
+			if _, err := io.ReadFull(r, m); err != nil {
+				return
+			}
+			msgs <- string(m)
+		}
+	}()
+
+	s, err := NewSyslog(testSyslogConfig("tcp", l.Addr().String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close() // nolint: errcheck
+
+	s.Log(SeverityInfo, "http", "first")
+	s.Log(SeverityWarning, "http", "second")
+	for _, want := range []string{"first", "second"} {
+		select {
+		case m := <-msgs:
+			if !strings.HasSuffix(m, " - "+want) {
+				t.Errorf("expected message %q, got %q", want, m)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for message %q", want)
+		}
+	}
+}
+
+func TestSyslogDropPolicy(t *testing.T) {
+	for _, c := range []struct {
+		policy string
+		want   []string
+	}{
+		{config.SyslogDropNewest, []string{"1", "2"}},
+		{config.SyslogDropOldest, []string{"2", "3"}},
+	} {
+		t.Run(c.policy, func(t *testing.T) {
+			cfg := testSyslogConfig("tcp", "unused")
+			cfg.BufferSize = 2
+			cfg.DropPolicy = c.policy
+
+			// The endpoint is unavailable, nothing leaves the buffer.
+			s := &Syslog{cfg: cfg, queue: make(chan []byte, cfg.BufferSize)}
+			for _, m := range []string{"1", "2", "3"} {
+				done := make(chan struct{})
+				go func() {
+					s.Log(SeverityInfo, "test", m)
+					close(done)
+				}()
+				select {
+				case <-done:
+				case <-time.After(time.Second):
+					t.Fatal("logging blocked")
+				}
+			}
+
+			close(s.queue)
+			var got []string
+			for m := range s.queue {
+				got = append(got, string(m[strings.LastIndexByte(string(m), ' ')+1:]))
+			}
+			if strings.Join(got, ",") != strings.Join(c.want, ",") {
+				t.Errorf("expected buffered messages %v, got %v", c.want, got)
+			}
+		})
+	}
+}
+
+func TestSyslogUnavailable(t *testing.T) {
+	cfg := testSyslogConfig("tcp", "unused")
+	s := &Syslog{
+		cfg:   cfg,
+		queue: make(chan []byte, cfg.BufferSize),
+		stop:  make(chan struct{}),
+		done:  make(chan struct{}),
+		dial:  func() (net.Conn, error) { return nil, errors.New("connection refused") },
+	}
+	go s.run()
+
+	s.Log(SeverityInfo, "test", "lost")
+
+	// Close gives up flushing instead of hanging.
+	start := time.Now()
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if d := time.Since(start); d > syslogFlushTimeout+time.Second {
+		t.Errorf("close took %s", d)
+	}
+
+	select {
+	case <-s.done:
+	case <-time.After(time.Second):
+		t.Fatal("expected the writer to stop")
+	}
+
+	// Messages logged after closing are dropped.
+	s.Log(SeverityInfo, "test", "late")
+}
+
+func TestNilSyslog(t *testing.T) {
+	var s *Syslog
+	s.Log(SeverityInfo, "test", "nothing")
+	if s.Only() {
+		t.Error("expected nil syslog to not be exclusive")
+	}
+	if err := s.Close(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,84 @@
+package profiling
+
+import (
+	"context"
+	"crypto/subtle"
+	"net/http"
+	"net/http/pprof"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/charmbracelet/soft-serve/pkg/config"
+)
+
+// ProfilingServer is a server exposing the Go runtime profiles.
+type ProfilingServer struct { //nolint:revive
+	ctx    context.Context
+	cfg    *config.Config
+	server *http.Server
+}
+
+// NewProfilingServer returns a new ProfilingServer.
+func NewProfilingServer(ctx context.Context) (*ProfilingServer, error) {
+	cfg := config.FromContext(ctx)
+	logger := log.FromContext(ctx).WithPrefix("profiling")
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	return &ProfilingServer{
+		ctx: ctx,
+		cfg: cfg,
+		server: &http.Server{
+			Addr:              cfg.Profiling.ListenAddr,
+			Handler:           withToken(logger, cfg.Profiling.Token, mux),
+			ReadHeaderTimeout: time.Second * 10,
+			ReadTimeout:       time.Second * 10,
+			// CPU profiles and traces are collected for the requested number
+			// of seconds before anything is written.
+			WriteTimeout:   time.Minute * 5,
+			MaxHeaderBytes: http.DefaultMaxHeaderBytes,
+		},
+	}, nil
+}
+
+// ListenAndServe starts the ProfilingServer.
+func (s *ProfilingServer) ListenAndServe() error {
+	return s.server.ListenAndServe()
+}
+
+// Shutdown gracefully shuts down the ProfilingServer.
+func (s *ProfilingServer) Shutdown(ctx context.Context) error {
+	return s.server.Shutdown(ctx)
+}
+
+// Close closes the ProfilingServer.
+func (s *ProfilingServer) Close() error {
+	return s.server.Close()
+}
+
+// withToken only lets through requests carrying the token, either as a bearer
+// token or as the token query parameter. The latter works with tools that
+// can't set headers, like go tool pprof.
+func withToken(logger *log.Logger, token string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got := r.URL.Query().Get("token")
+		if auth := r.Header.Get("Authorization"); auth != "" {
+			scheme, value, _ := strings.Cut(auth, " ")
+			if strings.EqualFold(scheme, "bearer") {
+				got = strings.TrimSpace(value)
+			}
+		}
+
+		if token == "" || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			logger.Debug("unauthorized profiling request", "path", r.URL.Path, "remote", r.RemoteAddr)
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
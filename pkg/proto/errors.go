@@ -13,14 +13,94 @@ var (
 	ErrRepoNotFound = errors.New("repository not found")
 	// ErrRepoExist is returned when a repository already exists.
 	ErrRepoExist = errors.New("repository already exists")
+	// ErrRepoAliasExist is returned when a repository alias already exists.
+	ErrRepoAliasExist = errors.New("repository alias already exists")
+	// ErrRepoAliasNotFound is returned when a repository alias is not found.
+	ErrRepoAliasNotFound = errors.New("repository alias not found")
+	// ErrRepoRenamed is returned when a client uses the old name of a
+	// renamed repository.
+	ErrRepoRenamed = errors.New("repository has been renamed")
+	// ErrRepoCreateLimit is returned when a user has created too many
+	// repositories recently.
+	ErrRepoCreateLimit = errors.New("repository creation limit reached")
+	// ErrQuotaExceeded is returned when a user's repositories use up their
+	// disk quota.
+	ErrQuotaExceeded = errors.New("disk quota exceeded")
+	// ErrCloneLimit is returned when a client runs too many concurrent clones
+	// or fetches.
+	ErrCloneLimit = errors.New("too many concurrent clones")
+	// ErrArchiveLimit is returned when there are too many concurrent
+	// archives, from a client or on the server.
+	ErrArchiveLimit = errors.New("too many concurrent archives")
+	// ErrReadOnlyReplica is returned when a write is attempted on a replica
+	// server.
+	ErrReadOnlyReplica = errors.New("this server is a read-only replica")
+	// ErrDeniedPath is returned when serving a repository whose history
+	// contains a denied path.
+	ErrDeniedPath = errors.New("repository history contains a denied path")
+	// ErrProtocolNotAllowed is returned when a repository is pushed to or
+	// read over a protocol it doesn't allow.
+	ErrProtocolNotAllowed = errors.New("protocol not allowed for this repository")
 	// ErrUserNotFound is returned when a user is not found.
 	ErrUserNotFound = errors.New("user not found")
 	// ErrTokenNotFound is returned when a token is not found.
 	ErrTokenNotFound = errors.New("token not found")
+	// ErrDeployTokenNotFound is returned when a deploy token is not found.
+	ErrDeployTokenNotFound = errors.New("deploy token not found")
+	// ErrDeployTokenExist is returned when a repository already has a
+	// deploy token with the same name.
+	ErrDeployTokenExist = errors.New("deploy token already exists")
+	// ErrInviteNotFound is returned when an invite is not found.
+	ErrInviteNotFound = errors.New("invite not found")
+	// ErrInvalidInvite is returned when an invite code doesn't exist, was
+	// already used, or expired.
+	ErrInvalidInvite = errors.New("invalid or expired invite code")
 	// ErrTokenExpired is returned when a token is expired.
 	ErrTokenExpired = errors.New("token expired")
 	// ErrCollaboratorNotFound is returned when a collaborator is not found.
 	ErrCollaboratorNotFound = errors.New("collaborator not found")
 	// ErrCollaboratorExist is returned when a collaborator already exists.
 	ErrCollaboratorExist = errors.New("collaborator already exists")
+	// ErrRepoKeyNotFound is returned when a key has no access to a
+	// repository.
+	ErrRepoKeyNotFound = errors.New("key has no access to the repository")
+	// ErrInvalidBranch is returned when a branch name is invalid.
+	ErrInvalidBranch = errors.New("invalid branch name")
+	// ErrPushMirrorNotFound is returned when a push mirror is not found.
+	ErrPushMirrorNotFound = errors.New("push mirror not found")
+	// ErrPushMirrorExist is returned when a push mirror already exists.
+	ErrPushMirrorExist = errors.New("push mirror already exists")
+	// ErrFreezeWindowNotFound is returned when a freeze window is not found.
+	ErrFreezeWindowNotFound = errors.New("freeze window not found")
+	// ErrFreezeWindowExist is returned when a freeze window already exists.
+	ErrFreezeWindowExist = errors.New("freeze window already exists")
+	// ErrBrokenAlternates is returned when a repository borrows objects from
+	// alternate object stores that don't exist or miss objects.
+	ErrBrokenAlternates = errors.New("repository has broken alternates")
+	// ErrCorruptRepository is returned when the objects of an imported
+	// repository fail git fsck.
+	ErrCorruptRepository = errors.New("repository is corrupt")
+	// ErrInvalidObjectFormat is returned when a repository object format
+	// isn't supported.
+	ErrInvalidObjectFormat = errors.New("invalid object format")
+	// ErrObjectFormatMismatch is returned when repositories with different
+	// object formats would share objects.
+	ErrObjectFormatMismatch = errors.New("object formats don't match")
+	// ErrCommitNotFound is returned when a commit is not found.
+	ErrCommitNotFound = errors.New("commit not found")
+	// ErrRepoLocked is returned when other operations hold the lock of a
+	// repository for too long.
+	ErrRepoLocked = errors.New("repository is locked")
+	// ErrNoMergeBase is returned when two commits have no common ancestor.
+	ErrNoMergeBase = errors.New("commits have no common ancestor")
+	// ErrTagNotFound is returned when a tag is not found.
+	ErrTagNotFound = errors.New("tag not found")
+	// ErrReleaseNotFound is returned when a release is not found.
+	ErrReleaseNotFound = errors.New("release not found")
+	// ErrReleaseExist is returned when a release already exists.
+	ErrReleaseExist = errors.New("release already exists")
+	// ErrReleaseAssetNotFound is returned when a release asset is not found.
+	ErrReleaseAssetNotFound = errors.New("release asset not found")
+	// ErrIssueNotFound is returned when an issue is not found.
+	ErrIssueNotFound = errors.New("issue not found")
 )
@@ -1,6 +1,7 @@
 package proto
 
 import (
+	"io"
 	"time"
 
 	"github.com/charmbracelet/soft-serve/git"
@@ -43,6 +44,31 @@ type RepositoryOptions struct {
 	Hidden      bool
 	LFS         bool
 	LFSEndpoint string
+	// DefaultBranch is the name of the default branch. If empty, git's
+	// default or the template's default branch is used.
+	DefaultBranch string
+	// Template is the name of an existing repository whose branches and tags
+	// are copied into the new repository.
+	Template string
+	// ObjectFormat is the hash algorithm of the objects of the repository,
+	// sha1 or sha256. If empty, sha1 is used, and imported repositories keep
+	// the format of their remote.
+	ObjectFormat string
+	// Readme commits a generated README to the new repository when it has no
+	// branches or tags.
+	Readme bool
+	// Dissociate copies the objects an imported repository borrows from
+	// alternate object stores and stops using them.
+	Dissociate bool
+	// Fsck checks the objects of an imported repository, and fails the
+	// import if they're corrupt.
+	Fsck bool
+	// Progress, if set, receives the progress of the clone of an imported
+	// repository.
+	Progress io.Writer
+	// ExplicitVisibility indicates that Private and Hidden were set
+	// explicitly and namespace default visibility rules don't apply.
+	ExplicitVisibility bool
 }
 
 // RepositoryDefaultBranch returns the default branch of a repository.
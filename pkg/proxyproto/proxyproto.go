@@ -0,0 +1,280 @@
+// Package proxyproto implements the server side of the HAProxy PROXY
+// protocol, versions 1 and 2. Load balancers use it to pass the address of
+// the client to the server behind them.
+//
+// See https://www.haproxy.org/download/2.9/doc/proxy-protocol.txt
+package proxyproto
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultHeaderTimeout is the default time a client has to send the PROXY
+// header.
+const DefaultHeaderTimeout = 10 * time.Second
+
+var (
+	// ErrNoHeader is returned when a connection doesn't start with a PROXY
+	// header.
+	ErrNoHeader = errors.New("proxyproto: missing PROXY header")
+	// ErrInvalidHeader is returned when a connection has a malformed PROXY
+	// header.
+	ErrInvalidHeader = errors.New("proxyproto: invalid PROXY header")
+)
+
+// v2Signature is the signature of version 2 headers.
+var v2Signature = []byte("\r\n\r\n\x00\r\nQUIT\n")
+
+// v1MaxLength is the maximum length of a version 1 header, including the
+// CRLF.
+const v1MaxLength = 107
+
+// Listener is a net.Listener accepting connections that start with a PROXY
+// header from trusted proxies. The header is read on the first read or call
+// to RemoteAddr of the connection, so slow clients don't block Accept.
+// Connections from other peers are returned as is, their header isn't read,
+// so they can't claim another client address.
+type Listener struct {
+	net.Listener
+	// Trusted are the networks of the proxies sending the header.
+	Trusted []*net.IPNet
+	// HeaderTimeout is the time a client has to send the header. Zero means
+	// DefaultHeaderTimeout.
+	HeaderTimeout time.Duration
+}
+
+// NewListener returns a Listener wrapping l, reading the header of the
+// connections from the trusted networks.
+func NewListener(l net.Listener, trusted []*net.IPNet) *Listener {
+	return &Listener{Listener: l, Trusted: trusted}
+}
+
+// Accept waits for and returns the next connection to the listener.
+func (l *Listener) Accept() (net.Conn, error) {
+	c, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+
+	if !l.trusted(c.RemoteAddr()) {
+		return c, nil
+	}
+
+	timeout := l.HeaderTimeout
+	if timeout <= 0 {
+		timeout = DefaultHeaderTimeout
+	}
+
+	return &Conn{Conn: c, r: bufio.NewReader(c), timeout: timeout}, nil
+}
+
+// trusted returns whether the peer address addr is in a trusted network.
+func (l *Listener) trusted(addr net.Addr) bool {
+	tcp, ok := addr.(*net.TCPAddr)
+	if !ok {
+		return false
+	}
+
+	for _, n := range l.Trusted {
+		if n.Contains(tcp.IP) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Conn is a connection whose remote address is the client address from the
+// PROXY header.
+type Conn struct {
+	net.Conn
+	r       *bufio.Reader
+	timeout time.Duration
+
+	once   sync.Once
+	remote net.Addr
+	err    error
+
+	mu       sync.Mutex
+	deadline time.Time
+}
+
+// Read reads data from the connection after the PROXY header. It returns an
+// error if the header is missing or invalid.
+func (c *Conn) Read(b []byte) (int, error) {
+	c.once.Do(c.readHeader)
+	if c.err != nil {
+		return 0, c.err
+	}
+
+	return c.r.Read(b)
+}
+
+// RemoteAddr returns the client address from the PROXY header, or the address
+// of the peer if the header doesn't carry one.
+func (c *Conn) RemoteAddr() net.Addr {
+	c.once.Do(c.readHeader)
+	if c.remote != nil {
+		return c.remote
+	}
+
+	return c.Conn.RemoteAddr()
+}
+
+// SetDeadline implements net.Conn.
+func (c *Conn) SetDeadline(t time.Time) error {
+	c.mu.Lock()
+	c.deadline = t
+	c.mu.Unlock()
+	return c.Conn.SetDeadline(t)
+}
+
+// SetReadDeadline implements net.Conn.
+func (c *Conn) SetReadDeadline(t time.Time) error {
+	c.mu.Lock()
+	c.deadline = t
+	c.mu.Unlock()
+	return c.Conn.SetReadDeadline(t)
+}
+
+// readHeader reads the PROXY header within the header timeout, or the read
+// deadline of the connection if it's sooner. The read deadline is restored
+// afterwards.
+func (c *Conn) readHeader() {
+	c.mu.Lock()
+	deadline := c.deadline
+	c.mu.Unlock()
+
+	hd := time.Now().Add(c.timeout)
+	if !deadline.IsZero() && deadline.Before(hd) {
+		hd = deadline
+	}
+
+	c.Conn.SetReadDeadline(hd) // nolint: errcheck
+	c.remote, c.err = ReadHeader(c.r)
+	c.Conn.SetReadDeadline(deadline) // nolint: errcheck
+	if c.err != nil {
+		c.Conn.Close() // nolint: errcheck
+	}
+}
+
+// ReadHeader reads a version 1 or 2 PROXY header from r and returns the
+// client address. The address is nil if the header doesn't carry one, like
+// health checks of the proxy itself.
+func ReadHeader(r *bufio.Reader) (net.Addr, error) {
+	sig, err := r.Peek(len(v2Signature))
+	switch {
+	case bytes.HasPrefix(sig, []byte("PROXY ")):
+		return readV1(r)
+	case bytes.Equal(sig, v2Signature):
+		return readV2(r)
+	case err != nil:
+		if errors.Is(err, io.EOF) {
+			return nil, ErrNoHeader
+		}
+		return nil, err
+	default:
+		return nil, ErrNoHeader
+	}
+}
+
+// readV1 reads a version 1 header, e.g.
+// "PROXY TCP4 192.0.2.1 198.51.100.1 56324 443\r\n".
+func readV1(r *bufio.Reader) (net.Addr, error) {
+	var line []byte
+	for len(line) < v1MaxLength {
+		b, err := r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+
+		line = append(line, b)
+		if bytes.HasSuffix(line, []byte("\r\n")) {
+			break
+		}
+	}
+
+	if !bytes.HasSuffix(line, []byte("\r\n")) {
+		return nil, fmt.Errorf("%w: header too long", ErrInvalidHeader)
+	}
+
+	fields := strings.Fields(string(line))
+	if len(fields) < 2 {
+		return nil, ErrInvalidHeader
+	}
+
+	switch fields[1] {
+	case "UNKNOWN":
+		return nil, nil
+	case "TCP4", "TCP6":
+	default:
+		return nil, fmt.Errorf("%w: unsupported protocol %q", ErrInvalidHeader, fields[1])
+	}
+
+	if len(fields) != 6 {
+		return nil, ErrInvalidHeader
+	}
+
+	ip := net.ParseIP(fields[2])
+	port, err := strconv.ParseUint(fields[4], 10, 16)
+	if ip == nil || err != nil {
+		return nil, fmt.Errorf("%w: invalid source address", ErrInvalidHeader)
+	}
+
+	return &net.TCPAddr{IP: ip, Port: int(port)}, nil
+}
+
+// readV2 reads a binary version 2 header.
+func readV2(r *bufio.Reader) (net.Addr, error) {
+	hdr := make([]byte, len(v2Signature)+4)
+	if _, err := io.ReadFull(r, hdr); err != nil {
+		return nil, err
+	}
+
+	verCmd, fam := hdr[12], hdr[13]
+	length := binary.BigEndian.Uint16(hdr[14:16])
+	if verCmd>>4 != 2 {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrInvalidHeader, verCmd>>4)
+	}
+
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return nil, err
+	}
+
+	switch verCmd & 0xf {
+	case 0x0:
+		// LOCAL, the connection was made by the proxy itself.
+		return nil, nil
+	case 0x1:
+		// PROXY
+	default:
+		return nil, fmt.Errorf("%w: unsupported command %d", ErrInvalidHeader, verCmd&0xf)
+	}
+
+	// Only TCP over IPv4 and IPv6 carry an address we can use.
+	switch fam {
+	case 0x11:
+		if len(payload) < 12 {
+			return nil, ErrInvalidHeader
+		}
+		return &net.TCPAddr{IP: net.IP(payload[0:4]), Port: int(binary.BigEndian.Uint16(payload[8:10]))}, nil
+	case 0x21:
+		if len(payload) < 36 {
+			return nil, ErrInvalidHeader
+		}
+		return &net.TCPAddr{IP: net.IP(payload[0:16]), Port: int(binary.BigEndian.Uint16(payload[32:34]))}, nil
+	default:
+		return nil, nil
+	}
+}
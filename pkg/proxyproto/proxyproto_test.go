@@ -0,0 +1,141 @@
+package proxyproto
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"testing"
+)
+
+func v2Header(cmd byte, fam byte, payload []byte) string {
+	hdr := append([]byte{}, v2Signature...)
+	hdr = append(hdr, 0x20|cmd, fam, 0, 0)
+	binary.BigEndian.PutUint16(hdr[14:16], uint16(len(payload)))
+	return string(append(hdr, payload...))
+}
+
+func TestReadHeader(t *testing.T) {
+	v4 := []byte{192, 0, 2, 1, 198, 51, 100, 1, 0xdc, 0x04, 0x01, 0xbb}
+	v6 := make([]byte, 36)
+	copy(v6, net.ParseIP("2001:db8::1"))
+	binary.BigEndian.PutUint16(v6[32:34], 56324)
+
+	cases := []struct {
+		name   string
+		in     string
+		remote string
+		err    error
+	}{
+		{name: "v1 tcp4", in: "PROXY TCP4 192.0.2.1 198.51.100.1 56324 443\r\n", remote: "192.0.2.1:56324"},
+		{name: "v1 tcp6", in: "PROXY TCP6 2001:db8::1 2001:db8::2 56324 443\r\n", remote: "[2001:db8::1]:56324"},
+		{name: "v1 unknown", in: "PROXY UNKNOWN\r\n"},
+		{name: "v1 invalid address", in: "PROXY TCP4 nope 198.51.100.1 56324 443\r\n", err: ErrInvalidHeader},
+		{name: "v1 too long", in: "PROXY TCP4 " + strings.Repeat("1", 200) + "\r\n", err: ErrInvalidHeader},
+		{name: "v2 tcp4", in: v2Header(0x1, 0x11, v4), remote: "192.0.2.1:56324"},
+		{name: "v2 tcp6", in: v2Header(0x1, 0x21, v6), remote: "[2001:db8::1]:56324"},
+		{name: "v2 local", in: v2Header(0x0, 0x00, nil)},
+		{name: "v2 short address", in: v2Header(0x1, 0x11, v4[:4]), err: ErrInvalidHeader},
+		{name: "no header", in: "SSH-2.0-OpenSSH_9.6\r\n", err: ErrNoHeader},
+		{name: "empty", in: "", err: ErrNoHeader},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			r := bufio.NewReader(strings.NewReader(c.in + "data"))
+			addr, err := ReadHeader(r)
+			if c.err != nil {
+				if !errors.Is(err, c.err) {
+					t.Fatalf("expected error %v, got %v", c.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var remote string
+			if addr != nil {
+				remote = addr.String()
+			}
+			if remote != c.remote {
+				t.Errorf("expected remote address %q, got %q", c.remote, remote)
+			}
+
+			rest, _ := io.ReadAll(r)
+			if string(rest) != "data" {
+				t.Errorf("expected the header to be consumed, got %q", rest)
+			}
+		})
+	}
+}
+
+func TestListener(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close() // nolint: errcheck
+
+	_, trusted, _ := net.ParseCIDR("127.0.0.0/8")
+	pl := NewListener(l, []*net.IPNet{trusted})
+	go func() {
+		c, err := net.Dial("tcp", l.Addr().String())
+		if err != nil {
+			return
+		}
+		defer c.Close()                                                           // nolint: errcheck
+		io.WriteString(c, "PROXY TCP4 192.0.2.1 198.51.100.1 56324 443\r\nhello") // nolint: errcheck
+	}()
+
+	c, err := pl.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close() // nolint: errcheck
+
+	if got := c.RemoteAddr().String(); got != "192.0.2.1:56324" {
+		t.Errorf("expected remote address 192.0.2.1:56324, got %q", got)
+	}
+
+	b, _ := io.ReadAll(c)
+	if string(b) != "hello" {
+		t.Errorf("expected hello, got %q", b)
+	}
+}
+
+func TestListenerUntrusted(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close() // nolint: errcheck
+
+	_, trusted, _ := net.ParseCIDR("192.0.2.0/24")
+	pl := NewListener(l, []*net.IPNet{trusted})
+	go func() {
+		c, err := net.Dial("tcp", l.Addr().String())
+		if err != nil {
+			return
+		}
+		defer c.Close()                                                           // nolint: errcheck
+		io.WriteString(c, "PROXY TCP4 192.0.2.1 198.51.100.1 56324 443\r\nhello") // nolint: errcheck
+	}()
+
+	c, err := pl.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close() // nolint: errcheck
+
+	if host, _, _ := net.SplitHostPort(c.RemoteAddr().String()); host != "127.0.0.1" {
+		t.Errorf("expected the peer address 127.0.0.1, got %q", host)
+	}
+
+	b, _ := io.ReadAll(c)
+	if !strings.HasPrefix(string(b), "PROXY TCP4 ") {
+		t.Errorf("expected the header not to be read, got %q", b)
+	}
+}
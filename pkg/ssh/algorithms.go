@@ -0,0 +1,382 @@
+package ssh
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/soft-serve/pkg/config"
+	"github.com/charmbracelet/soft-serve/pkg/proto"
+	"github.com/charmbracelet/ssh"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	gossh "golang.org/x/crypto/ssh"
+)
+
+var deprecatedAlgorithmsCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "soft_serve",
+	Subsystem: "ssh",
+	Name:      "deprecated_algorithms_total",
+	Help:      "The total number of connections negotiating a deprecated algorithm",
+}, []string{"algorithm", "action"})
+
+// maxKexInitSize is the number of bytes read from a connection looking for
+// the client's key exchange init message before giving up.
+const maxKexInitSize = 64 << 10
+
+var (
+	// contextKeyAlgorithms holds the negotiatedAlgorithms of a connection.
+	contextKeyAlgorithms = &struct{ string }{"algorithms"}
+
+	// contextKeyAlgorithmsChecked marks a connection whose algorithms have
+	// been checked for deprecated ones.
+	contextKeyAlgorithmsChecked = &struct{ string }{"algorithms-checked"}
+
+	errShortKexInit   = errors.New("short key exchange init")
+	errInvalidKexInit = errors.New("invalid key exchange init")
+)
+
+// aeadCiphers are the ciphers that don't use a MAC.
+var aeadCiphers = map[string]bool{
+	"aes128-gcm@openssh.com":        true,
+	"aes256-gcm@openssh.com":        true,
+	"chacha20-poly1305@openssh.com": true,
+}
+
+// serverAlgorithms are the key exchange, cipher, and MAC algorithms offered
+// by the server, in order of preference.
+type serverAlgorithms struct {
+	keyExchanges []string
+	ciphers      []string
+	macs         []string
+}
+
+// defaultServerAlgorithms are the golang.org/x/crypto/ssh defaults. They're
+// set explicitly so the algorithms a connection negotiates are known.
+var defaultServerAlgorithms = serverAlgorithms{
+	keyExchanges: []string{
+		"curve25519-sha256",
+		"curve25519-sha256@libssh.org",
+		"ecdh-sha2-nistp256",
+		"ecdh-sha2-nistp384",
+		"ecdh-sha2-nistp521",
+		"diffie-hellman-group14-sha256",
+		"diffie-hellman-group14-sha1",
+	},
+	ciphers: []string{
+		"aes128-gcm@openssh.com",
+		"aes256-gcm@openssh.com",
+		"chacha20-poly1305@openssh.com",
+		"aes128-ctr",
+		"aes192-ctr",
+		"aes256-ctr",
+	},
+	macs: []string{
+		"hmac-sha2-256-etm@openssh.com",
+		"hmac-sha2-512-etm@openssh.com",
+		"hmac-sha2-256",
+		"hmac-sha2-512",
+		"hmac-sha1",
+		"hmac-sha1-96",
+	},
+}
+
+// without returns the algorithms without the given ones.
+func (a serverAlgorithms) without(algos []string) serverAlgorithms {
+	keep := func(s []string) []string {
+		return slices.DeleteFunc(slices.Clone(s), func(algo string) bool {
+			return slices.Contains(algos, algo)
+		})
+	}
+
+	return serverAlgorithms{
+		keyExchanges: keep(a.keyExchanges),
+		ciphers:      keep(a.ciphers),
+		macs:         keep(a.macs),
+	}
+}
+
+// validate returns an error if an algorithm category is empty, since no
+// client could connect.
+func (a serverAlgorithms) validate() error {
+	switch {
+	case len(a.keyExchanges) == 0:
+		return fmt.Errorf("all ssh key exchange algorithms are deprecated")
+	case len(a.ciphers) == 0:
+		return fmt.Errorf("all ssh ciphers are deprecated")
+	case len(a.macs) == 0:
+		return fmt.Errorf("all ssh MAC algorithms are deprecated")
+	}
+
+	return nil
+}
+
+// negotiate returns the algorithms negotiated with a client sending msg. It
+// returns false if there's no algorithm in common.
+func (a serverAlgorithms) negotiate(msg *kexInitMsg) (negotiatedAlgorithms, bool) {
+	n := negotiatedAlgorithms{kex: findCommon(msg.KexAlgos, a.keyExchanges)}
+	if n.kex == "" {
+		return n, false
+	}
+
+	for _, dir := range []struct{ ciphers, macs []string }{
+		{msg.CiphersClientServer, msg.MACsClientServer},
+		{msg.CiphersServerClient, msg.MACsServerClient},
+	} {
+		cipher := findCommon(dir.ciphers, a.ciphers)
+		if cipher == "" {
+			return n, false
+		}
+		if !slices.Contains(n.ciphers, cipher) {
+			n.ciphers = append(n.ciphers, cipher)
+		}
+		if aeadCiphers[cipher] {
+			continue
+		}
+
+		mac := findCommon(dir.macs, a.macs)
+		if mac == "" {
+			return n, false
+		}
+		if !slices.Contains(n.macs, mac) {
+			n.macs = append(n.macs, mac)
+		}
+	}
+
+	return n, true
+}
+
+// findCommon returns the first client algorithm supported by the server, as
+// described in RFC 4253 section 7.1.
+func findCommon(client, server []string) string {
+	for _, algo := range client {
+		if slices.Contains(server, algo) {
+			return algo
+		}
+	}
+
+	return ""
+}
+
+// negotiatedAlgorithms are the algorithms used by a connection. MACs used
+// with AEAD ciphers are left out.
+type negotiatedAlgorithms struct {
+	kex     string
+	ciphers []string
+	macs    []string
+}
+
+// deprecated returns the negotiated algorithms that are one of the given
+// deprecated algorithms.
+func (n negotiatedAlgorithms) deprecated(algos []string) []string {
+	var deprecated []string
+	for _, algo := range append(append([]string{n.kex}, n.ciphers...), n.macs...) {
+		if slices.Contains(algos, algo) {
+			deprecated = append(deprecated, algo)
+		}
+	}
+
+	return deprecated
+}
+
+// logArgs returns the negotiated algorithms as log key-value pairs.
+func (n negotiatedAlgorithms) logArgs() []interface{} {
+	return []interface{}{
+		"kex", n.kex,
+		"ciphers", strings.Join(n.ciphers, ","),
+		"macs", strings.Join(n.macs, ","),
+	}
+}
+
+// kexInitMsg is the SSH_MSG_KEXINIT message, see RFC 4253 section 7.1.
+type kexInitMsg struct {
+	Cookie                  [16]byte `sshtype:"20"`
+	KexAlgos                []string
+	ServerHostKeyAlgos      []string
+	CiphersClientServer     []string
+	CiphersServerClient     []string
+	MACsClientServer        []string
+	MACsServerClient        []string
+	CompressionClientServer []string
+	CompressionServerClient []string
+	LanguagesClientServer   []string
+	LanguagesServerClient   []string
+	FirstKexFollows         bool
+	Reserved                uint32
+}
+
+// parseClientKexInit parses the version and the key exchange init message a
+// client sends in the clear at the start of a connection. It returns
+// errShortKexInit if b doesn't hold the whole message yet.
+func parseClientKexInit(b []byte) (version string, msg *kexInitMsg, err error) {
+	// Lines before the version line are ignored, see RFC 4253 section 4.2.
+	for {
+		i := bytes.IndexByte(b, '\n')
+		if i < 0 {
+			return "", nil, errShortKexInit
+		}
+
+		line := bytes.TrimSuffix(b[:i], []byte("\r"))
+		b = b[i+1:]
+		if bytes.HasPrefix(line, []byte("SSH-")) {
+			version = string(line)
+			break
+		}
+	}
+
+	// The binary packet, see RFC 4253 section 6.
+	if len(b) < 5 {
+		return "", nil, errShortKexInit
+	}
+
+	length := binary.BigEndian.Uint32(b)
+	padding := uint32(b[4])
+	if length > maxKexInitSize || length < padding+1 {
+		return "", nil, errInvalidKexInit
+	}
+	if uint32(len(b)-4) < length {
+		return "", nil, errShortKexInit
+	}
+
+	msg = new(kexInitMsg)
+	if err := gossh.Unmarshal(b[5:4+length-padding], msg); err != nil {
+		return "", nil, fmt.Errorf("%w: %v", errInvalidKexInit, err)
+	}
+
+	return version, msg, nil
+}
+
+// kexInitConn is a net.Conn passing the client's key exchange init message
+// to onKexInit as it's read by the server.
+type kexInitConn struct {
+	net.Conn
+
+	buf       []byte
+	done      bool
+	onKexInit func(version string, msg *kexInitMsg)
+}
+
+// Read implements net.Conn.
+func (c *kexInitConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	if c.done || n == 0 {
+		return n, err
+	}
+
+	c.buf = append(c.buf, b[:n]...)
+	version, msg, perr := parseClientKexInit(c.buf)
+	if errors.Is(perr, errShortKexInit) && len(c.buf) <= maxKexInitSize {
+		return n, err
+	}
+
+	c.done = true
+	c.buf = nil
+	if perr == nil {
+		c.onKexInit(version, msg)
+	}
+
+	return n, err
+}
+
+// serverConfig returns the SSH server configuration, offering only the
+// allowed algorithms, and releasing the startup slot of connections once
+// they authenticate.
+func (s *SSHServer) serverConfig(ctx ssh.Context) *gossh.ServerConfig {
+	cfg := &gossh.ServerConfig{
+		Config: gossh.Config{
+			KeyExchanges: s.algorithms.keyExchanges,
+			Ciphers:      s.algorithms.ciphers,
+			MACs:         s.algorithms.macs,
+		},
+	}
+	debug := config.IsDebug()
+	release, _ := ctx.Value(contextKeyStartup).(func())
+	cfg.AuthLogCallback = func(conn gossh.ConnMetadata, method string, err error) {
+		if debug {
+			s.logger.Debug("authentication", "user", conn.User(), "method", method, "err", err)
+		}
+		// A nil error means the authentication is complete.
+		if err == nil && release != nil {
+			release()
+		}
+	}
+
+	return cfg
+}
+
+// checkAlgorithmsConn returns conn reporting the algorithms the client
+// negotiates. They're kept in the context, and clients that can only
+// negotiate denied algorithms are logged.
+func (s *SSHServer) checkAlgorithmsConn(ctx ssh.Context, conn net.Conn) net.Conn {
+	deprecated := s.cfg.SSH.DeprecatedAlgorithms
+	if len(deprecated) == 0 {
+		return conn
+	}
+
+	return &kexInitConn{
+		Conn: conn,
+		onKexInit: func(version string, msg *kexInitMsg) {
+			if n, ok := s.algorithms.negotiate(msg); ok {
+				ctx.SetValue(contextKeyAlgorithms, n)
+				return
+			}
+
+			n, ok := defaultServerAlgorithms.negotiate(msg)
+			if !ok || s.cfg.SSH.DeprecatedAlgorithmsAction != config.DeprecatedAlgorithmsDeny {
+				return
+			}
+
+			denied := n.deprecated(deprecated)
+			for _, algo := range denied {
+				deprecatedAlgorithmsCounter.WithLabelValues(algo, config.DeprecatedAlgorithmsDeny).Inc()
+			}
+			s.logger.Warn("rejecting client supporting only deprecated ssh algorithms",
+				append([]interface{}{
+					"deprecated", strings.Join(denied, ","),
+					"remote-addr", conn.RemoteAddr(),
+					"client-version", version,
+				}, n.logArgs()...)...)
+		},
+	}
+}
+
+// AlgorithmsMiddleware logs authenticated clients that negotiated a
+// deprecated algorithm, once per connection, so they can be asked to
+// upgrade.
+func (s *SSHServer) AlgorithmsMiddleware(sh ssh.Handler) ssh.Handler {
+	return func(sess ssh.Session) {
+		ctx := sess.Context()
+		n, ok := ctx.Value(contextKeyAlgorithms).(negotiatedAlgorithms)
+		if ok && ctx.Value(contextKeyAlgorithmsChecked) == nil {
+			ctx.SetValue(contextKeyAlgorithmsChecked, true)
+			if deprecated := n.deprecated(s.cfg.SSH.DeprecatedAlgorithms); len(deprecated) > 0 {
+				for _, algo := range deprecated {
+					deprecatedAlgorithmsCounter.WithLabelValues(algo, config.DeprecatedAlgorithmsWarn).Inc()
+				}
+
+				var username, fp string
+				if user := proto.UserFromContext(ctx); user != nil {
+					username = user.Username()
+				}
+				if perms := ctx.Permissions(); perms != nil && perms.Permissions != nil {
+					fp = perms.Extensions["pubkey-fp"]
+				}
+				s.logger.Warn("client negotiated deprecated ssh algorithms",
+					append([]interface{}{
+						"deprecated", strings.Join(deprecated, ","),
+						"username", username,
+						"user", ctx.User(),
+						"fingerprint", fp,
+						"remote-addr", ctx.RemoteAddr(),
+						"client-version", ctx.ClientVersion(),
+					}, n.logArgs()...)...)
+			}
+		}
+
+		sh(sess)
+	}
+}
@@ -0,0 +1,162 @@
+package ssh
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/charmbracelet/keygen"
+	"github.com/charmbracelet/log"
+	"github.com/charmbracelet/soft-serve/pkg/config"
+	"github.com/charmbracelet/ssh"
+	"github.com/matryer/is"
+	gossh "golang.org/x/crypto/ssh"
+)
+
+// clientKexInit returns the bytes a client sends at the start of a
+// connection, up to its key exchange init message.
+func clientKexInit(msg *kexInitMsg) []byte {
+	payload := gossh.Marshal(msg)
+	padding := 4
+	b := []byte("SSH-2.0-Test\r\n")
+	b = binary.BigEndian.AppendUint32(b, uint32(1+len(payload)+padding))
+	b = append(b, byte(padding))
+	b = append(b, payload...)
+	return append(b, make([]byte, padding)...)
+}
+
+func TestParseClientKexInit(t *testing.T) {
+	is := is.New(t)
+	msg := &kexInitMsg{
+		KexAlgos:            []string{"curve25519-sha256", "ext-info-c"},
+		CiphersClientServer: []string{"aes128-ctr"},
+		CiphersServerClient: []string{"aes128-ctr"},
+		MACsClientServer:    []string{"hmac-sha1"},
+		MACsServerClient:    []string{"hmac-sha1"},
+	}
+	b := clientKexInit(msg)
+
+	version, got, err := parseClientKexInit(b)
+	is.NoErr(err)
+	is.Equal(version, "SSH-2.0-Test")
+	is.Equal(got.KexAlgos, msg.KexAlgos)
+	is.Equal(got.MACsServerClient, msg.MACsServerClient)
+
+	_, _, err = parseClientKexInit(b[:len(b)-1])
+	is.True(errors.Is(err, errShortKexInit))
+
+	_, _, err = parseClientKexInit([]byte("SSH-2.0-Test\r\n\xff\xff\xff\xff\x04"))
+	is.True(errors.Is(err, errInvalidKexInit))
+}
+
+func TestNegotiateAlgorithms(t *testing.T) {
+	is := is.New(t)
+	msg := &kexInitMsg{
+		KexAlgos:            []string{"sntrup761x25519-sha512@openssh.com", "diffie-hellman-group14-sha1"},
+		CiphersClientServer: []string{"aes128-gcm@openssh.com"},
+		CiphersServerClient: []string{"aes128-ctr"},
+		MACsClientServer:    []string{"hmac-sha1"},
+		MACsServerClient:    []string{"hmac-sha1", "hmac-sha2-256"},
+	}
+
+	n, ok := defaultServerAlgorithms.negotiate(msg)
+	is.True(ok)
+	is.Equal(n.kex, "diffie-hellman-group14-sha1")
+	is.Equal(n.ciphers, []string{"aes128-gcm@openssh.com", "aes128-ctr"})
+	is.Equal(n.macs, []string{"hmac-sha1"}) // the AEAD cipher's MAC is unused
+	deprecated := config.DefaultConfig().SSH.DeprecatedAlgorithms
+	is.Equal(n.deprecated(deprecated), []string{"diffie-hellman-group14-sha1", "hmac-sha1"})
+
+	// Only the key exchange algorithm has no replacement.
+	allowed := defaultServerAlgorithms.without(deprecated)
+	is.NoErr(allowed.validate())
+	_, ok = allowed.negotiate(msg)
+	is.True(!ok)
+	msg.KexAlgos = append(msg.KexAlgos, "curve25519-sha256")
+	n, ok = allowed.negotiate(msg)
+	is.True(ok)
+	is.Equal(n.macs, []string{"hmac-sha2-256"})
+	is.Equal(len(n.deprecated(deprecated)), 0)
+
+	is.True(defaultServerAlgorithms.without([]string{"hmac-sha2-256-etm@openssh.com", "hmac-sha2-512-etm@openssh.com", "hmac-sha2-256", "hmac-sha2-512", "hmac-sha1", "hmac-sha1-96"}).validate() != nil)
+}
+
+// syncBuffer is a bytes.Buffer safe for concurrent use.
+type syncBuffer struct {
+	mu sync.Mutex
+	b  bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.b.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.b.String()
+}
+
+func TestDeprecatedAlgorithms(t *testing.T) {
+	kp, err := keygen.New("", keygen.WithKeyType(keygen.Ed25519))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	deprecated := &gossh.ClientConfig{
+		Config: gossh.Config{
+			KeyExchanges: []string{"diffie-hellman-group14-sha1"},
+			Ciphers:      []string{"aes128-ctr"},
+			MACs:         []string{"hmac-sha1"},
+		},
+		User:            "user",
+		HostKeyCallback: gossh.InsecureIgnoreHostKey(), // nolint: gosec
+	}
+
+	for _, action := range []string{config.DeprecatedAlgorithmsWarn, config.DeprecatedAlgorithmsDeny} {
+		t.Run(action, func(t *testing.T) {
+			is := is.New(t)
+			cfg := config.DefaultConfig()
+			cfg.SSH.DeprecatedAlgorithmsAction = action
+			var logs syncBuffer
+			s := &SSHServer{cfg: cfg, logger: log.New(&logs), algorithms: defaultServerAlgorithms}
+			if action == config.DeprecatedAlgorithmsDeny {
+				s.algorithms = s.algorithms.without(cfg.SSH.DeprecatedAlgorithms)
+			}
+
+			srv := &ssh.Server{
+				Handler:              s.AlgorithmsMiddleware(func(ssh.Session) {}),
+				ConnCallback:         s.ConnCallback,
+				ServerConfigCallback: s.serverConfig,
+				HostSigners:          []ssh.Signer{kp.Signer()},
+			}
+			l, err := net.Listen("tcp", "127.0.0.1:0")
+			is.NoErr(err)
+			go srv.Serve(l)                   // nolint: errcheck
+			t.Cleanup(func() { srv.Close() }) // nolint: errcheck
+
+			client, err := gossh.Dial("tcp", l.Addr().String(), deprecated)
+			if action == config.DeprecatedAlgorithmsDeny {
+				is.True(err != nil)
+				is.True(strings.Contains(logs.String(), "rejecting client supporting only deprecated ssh algorithms"))
+				return
+			}
+			is.NoErr(err)
+			defer client.Close() // nolint: errcheck
+
+			sess, err := client.NewSession()
+			is.NoErr(err)
+			is.NoErr(sess.Run(""))
+			out := logs.String()
+			is.True(strings.Contains(out, "client negotiated deprecated ssh algorithms"))
+			is.True(strings.Contains(out, "deprecated=diffie-hellman-group14-sha1,hmac-sha1"))
+			is.True(strings.Contains(out, "client-version=SSH-2.0-Go"))
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"github.com/charmbracelet/soft-serve/pkg/backend"
+	"github.com/spf13/cobra"
+)
+
+func aliasCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "alias",
+		Aliases: []string{"aliases"},
+		Short:   "Manage repository aliases",
+		Long:    "Manage the alternative names of a repository. Cloning, fetching, or pushing to an alias transparently uses the repository, e.g. to keep the old name working during a migration.",
+	}
+
+	cmd.AddCommand(
+		aliasAddCommand(),
+		aliasRemoveCommand(),
+		aliasListCommand(),
+	)
+
+	return cmd
+}
+
+func aliasAddCommand() *cobra.Command {
+	var message string
+
+	cmd := &cobra.Command{
+		Use:               "add REPOSITORY ALIAS",
+		Short:             "Add an alias to a repository",
+		Long:              "Add an alias to a repository. The alias can't be the name of an existing repository or alias. With --message, SSH clients using the alias are shown the message.",
+		Args:              cobra.ExactArgs(2),
+		PersistentPreRunE: checkIfAdmin,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+
+			return be.AddRepoAlias(ctx, args[0], args[1], message)
+		},
+	}
+
+	cmd.Flags().StringVarP(&message, "message", "m", "", "message shown to clients using the alias")
+
+	return cmd
+}
+
+func aliasRemoveCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "remove REPOSITORY ALIAS",
+		Short:             "Remove an alias from a repository",
+		Args:              cobra.ExactArgs(2),
+		PersistentPreRunE: checkIfAdmin,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+
+			return be.RemoveRepoAlias(ctx, args[0], args[1])
+		},
+	}
+
+	return cmd
+}
+
+func aliasListCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "list REPOSITORY",
+		Short:             "List the aliases of a repository",
+		Args:              cobra.ExactArgs(1),
+		PersistentPreRunE: checkIfReadable,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+
+			aliases, err := be.RepoAliases(ctx, args[0])
+			if err != nil {
+				return err
+			}
+
+			for _, a := range aliases {
+				if a.Message != "" {
+					cmd.Printf("%s\t%s\n", a.Alias, a.Message)
+				} else {
+					cmd.Println(a.Alias)
+				}
+			}
+
+			return nil
+		},
+	}
+
+	return cmd
+}
@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"github.com/charmbracelet/soft-serve/pkg/backend"
+	"github.com/charmbracelet/soft-serve/pkg/sshutils"
+	"github.com/charmbracelet/soft-serve/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+// ArchiveCommand returns a command that streams a tar archive of a path of a
+// repository.
+func ArchiveCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "archive REPOSITORY REVISION [PATH]",
+		Short:             "Stream a tar archive of a repository tree",
+		Long:              "Stream a tar archive of the tree of a revision to stdout, or only of a path in it, without cloning the repository. The files keep their path in the repository, e.g. `archive icecream main docs | tar -x` extracts the docs directory. Archives count against the archive limits.",
+		Args:              cobra.RangeArgs(2, 3),
+		PersistentPreRunE: checkIfReadable,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			name := utils.SanitizeRepo(args[0])
+
+			if err := checkProtocol(ctx, be, name, false); err != nil {
+				return err
+			}
+
+			var subpath string
+			if len(args) > 2 {
+				subpath = args[2]
+			}
+
+			a, err := be.TreeArchive(ctx, name, args[1], subpath)
+			if err != nil {
+				return err
+			}
+
+			var addr string
+			if sess := sshutils.SessionFromContext(ctx); sess != nil {
+				addr = sess.RemoteAddr().String()
+			}
+
+			release, err := be.AcquireUploadArchive(ctx, addr)
+			if err != nil {
+				return err
+			}
+			defer release()
+
+			return a.Stream(ctx, cmd.OutOrStdout())
+		},
+	}
+
+	return cmd
+}
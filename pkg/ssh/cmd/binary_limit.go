@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/soft-serve/pkg/backend"
+	"github.com/dustin/go-humanize"
+	"github.com/spf13/cobra"
+)
+
+func binaryLimitCommand() *cobra.Command {
+	var exempt []string
+	var clear, warnOnly bool
+
+	cmd := &cobra.Command{
+		Use:   "binary-limit REPOSITORY [SIZE]",
+		Short: "Show or set the size of binary files a push can add",
+		Long:  "Show or set the maximum total size of the binary files added or changed by the commits of a single push, e.g. `10MiB`. A size of 0 means no limit. Files are binary following git's heuristics and the binary attribute, and pushes exceeding the limit are rejected with the largest files, to steer them to Git LFS. Exempt paths use shell glob syntax, e.g. `assets/*`, and match everything under a directory. With --warn-only, such pushes are allowed with a warning.",
+		Args:  cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			repo := args[0]
+
+			flags := cmd.Flags()
+			if len(args) == 1 && !clear && !flags.Changed("exempt") && !flags.Changed("warn-only") {
+				if err := checkIfReadable(cmd, args); err != nil {
+					return err
+				}
+
+				l, err := be.BinaryLimit(ctx, repo)
+				if err != nil {
+					return err
+				}
+
+				size := "0"
+				if l.MaxBytes > 0 {
+					size = humanize.IBytes(uint64(l.MaxBytes))
+				}
+
+				mode := "enforce"
+				if l.WarnOnly {
+					mode = "warn"
+				}
+
+				cmd.Printf("size\t%s\n", size)
+				cmd.Printf("exempt\t%s\n", strings.Join(l.Exempt, ","))
+				cmd.Printf("mode\t%s\n", mode)
+				return nil
+			}
+
+			if err := checkIfAdmin(cmd, args); err != nil {
+				return err
+			}
+
+			if clear {
+				return be.SetBinaryLimit(ctx, repo, backend.BinaryLimit{})
+			}
+
+			l, err := be.BinaryLimit(ctx, repo)
+			if err != nil {
+				return err
+			}
+
+			if len(args) > 1 {
+				size, err := humanize.ParseBytes(args[1])
+				if err != nil {
+					return fmt.Errorf("invalid size %q", args[1])
+				}
+				l.MaxBytes = int64(size)
+			}
+			if flags.Changed("exempt") {
+				l.Exempt = exempt
+			}
+			if flags.Changed("warn-only") {
+				l.WarnOnly = warnOnly
+			}
+
+			return be.SetBinaryLimit(ctx, repo, l)
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&exempt, "exempt", nil, "paths not counted against the limit")
+	cmd.Flags().BoolVar(&clear, "clear", false, "remove the binary size limit")
+	cmd.Flags().BoolVar(&warnOnly, "warn-only", false, "warn instead of rejecting pushes exceeding the limit")
+
+	return cmd
+}
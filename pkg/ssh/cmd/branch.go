@@ -94,6 +94,12 @@ func branchDefaultCommand() *cobra.Command {
 					return err
 				}
 
+				unlock, err := be.RLockRepository(ctx, rn, "default-branch")
+				if err != nil {
+					return err
+				}
+				defer unlock()
+
 				rr, err := be.Repository(ctx, rn)
 				if err != nil {
 					return err
@@ -154,6 +160,12 @@ func branchDeleteCommand() *cobra.Command {
 			ctx := cmd.Context()
 			be := backend.FromContext(ctx)
 			rn := strings.TrimSuffix(args[0], ".git")
+			unlock, err := be.RLockRepository(ctx, rn, "branch-delete")
+			if err != nil {
+				return err
+			}
+			defer unlock()
+
 			rr, err := be.Repository(ctx, rn)
 			if err != nil {
 				return err
@@ -187,7 +199,7 @@ func branchDeleteCommand() *cobra.Command {
 				return fmt.Errorf("cannot delete the default branch")
 			}
 
-			branchCommit, err := r.BranchCommit(branch)
+			branchCommit, err := r.BranchCommitID(branch)
 			if err != nil {
 				return err
 			}
@@ -196,7 +208,7 @@ func branchDeleteCommand() *cobra.Command {
 				return err
 			}
 
-			wh, err := webhook.NewBranchTagEvent(ctx, proto.UserFromContext(ctx), rr, git.RefsHeads+branch, branchCommit.ID.String(), git.ZeroID)
+			wh, err := webhook.NewBranchTagEvent(ctx, proto.UserFromContext(ctx), rr, git.RefsHeads+branch, branchCommit, git.ZeroIDOf(branchCommit))
 			if err != nil {
 				return err
 			}
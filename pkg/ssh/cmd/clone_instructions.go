@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"github.com/charmbracelet/soft-serve/pkg/backend"
+	"github.com/spf13/cobra"
+)
+
+func cloneInstructionsCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "clone-instructions REPOSITORY",
+		Aliases:           []string{"clone-urls"},
+		Short:             "Show how to clone a repository",
+		Long:              "Show the commands to clone a repository over the protocols it can be read over, the default clone protocol first, with notes on submodules and Git LFS.",
+		Args:              cobra.ExactArgs(1),
+		PersistentPreRunE: checkIfReadable,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			ci, err := be.CloneInstructions(ctx, args[0])
+			if err != nil {
+				return err
+			}
+
+			for _, u := range ci.URLs {
+				cmd.Printf("%s\t%s\n", u.Protocol, u.Command)
+			}
+			if len(ci.Notes) > 0 {
+				cmd.Println()
+				for _, note := range ci.Notes {
+					cmd.Println(note)
+				}
+			}
+
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+func cloneProtocolCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "clone-protocol REPOSITORY [ssh|http|git]",
+		Short:             "Set or get the default clone protocol of a repository",
+		Long:              "Set or get the protocol shown first in the clone instructions of a repository, in the TUI and the API. It defaults to ssh.",
+		Args:              cobra.RangeArgs(1, 2),
+		PersistentPreRunE: checkIfAdmin,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			repo := args[0]
+			switch len(args) {
+			case 1:
+				protocol, err := be.CloneProtocol(ctx, repo)
+				if err != nil {
+					return err
+				}
+
+				if protocol == "" {
+					protocol = backend.ProtocolSSH
+				}
+				cmd.Println(protocol)
+			case 2:
+				return be.SetCloneProtocol(ctx, repo, args[1])
+			}
+
+			return nil
+		},
+	}
+
+	return cmd
+}
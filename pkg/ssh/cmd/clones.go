@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/caarlos0/tablewriter"
+	"github.com/charmbracelet/soft-serve/pkg/backend"
+	"github.com/charmbracelet/soft-serve/pkg/db/models"
+	"github.com/dustin/go-humanize"
+	"github.com/spf13/cobra"
+)
+
+func clonesCommand() *cobra.Command {
+	var limit int
+
+	cmd := &cobra.Command{
+		Use:               "clones REPOSITORY",
+		Short:             "Show who recently cloned a repository",
+		Long:              "Show the number of clones and fetches of a repository and who recently made them. Clone tracking must be enabled with `repo clone-tracking`.",
+		Args:              cobra.ExactArgs(1),
+		PersistentPreRunE: checkIfAdmin,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			repo := args[0]
+
+			enabled, err := be.CloneTracking(ctx, repo)
+			if err != nil {
+				return err
+			}
+
+			if !enabled {
+				cmd.PrintErrln("Clone tracking is disabled for this repository.")
+			}
+
+			stats, err := be.CloneStats(ctx, repo, limit)
+			if err != nil {
+				return err
+			}
+
+			cmd.Printf("Total: %d\n", stats.Count)
+			if len(stats.Recent) == 0 {
+				return nil
+			}
+
+			return tablewriter.Render(
+				cmd.OutOrStdout(),
+				stats.Recent,
+				[]string{"User", "Protocol", "When"},
+				func(e models.CloneEvent) ([]string, error) {
+					username := "anonymous"
+					if e.Username.Valid {
+						username = e.Username.String
+					}
+
+					return []string{
+						username,
+						e.Protocol,
+						humanize.Time(e.CreatedAt),
+					}, nil
+				},
+			)
+		},
+	}
+
+	cmd.Flags().IntVarP(&limit, "limit", "n", 10, "number of recent clones to show")
+
+	return cmd
+}
+
+func cloneTrackingCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "clone-tracking REPOSITORY [TRUE|FALSE]",
+		Short:             "Enable or disable clone tracking for a repository",
+		Long:              "Enable or disable recording who clones and fetches a repository. Clone tracking is disabled by default.",
+		Args:              cobra.RangeArgs(1, 2),
+		PersistentPreRunE: checkIfAdmin,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			repo := args[0]
+			switch len(args) {
+			case 1:
+				enabled, err := be.CloneTracking(ctx, repo)
+				if err != nil {
+					return err
+				}
+
+				cmd.Println(enabled)
+			case 2:
+				enabled, err := strconv.ParseBool(args[1])
+				if err != nil {
+					return fmt.Errorf("invalid value %q: %w", args[1], err)
+				}
+
+				return be.SetCloneTracking(ctx, repo, enabled)
+			}
+
+			return nil
+		},
+	}
+
+	return cmd
+}
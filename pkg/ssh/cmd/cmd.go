@@ -147,6 +147,11 @@ func checkIfAdmin(cmd *cobra.Command, args []string) error {
 	cfg := config.FromContext(ctx)
 	be := backend.FromContext(ctx)
 	rn := utils.SanitizeRepo(repo)
+	// Limited SSH listeners can deny admin access, even to admins.
+	if access.Limit(ctx, access.AdminAccess) < access.AdminAccess {
+		return proto.ErrUnauthorized
+	}
+
 	pk := sshutils.PublicKeyFromContext(ctx)
 	if IsPublicKeyAdmin(cfg, pk) {
 		return nil
@@ -185,3 +190,23 @@ func checkIfCollab(cmd *cobra.Command, args []string) error {
 	}
 	return nil
 }
+
+// checkCapability returns a function checking that the user has the access
+// level a capability requires on the repository of the first argument.
+func checkCapability(c access.Capability) func(*cobra.Command, []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		var repo string
+		if len(args) > 0 {
+			repo = args[0]
+		}
+
+		ctx := cmd.Context()
+		be := backend.FromContext(ctx)
+		rn := utils.SanitizeRepo(repo)
+		user := proto.UserFromContext(ctx)
+		if !be.HasCapability(ctx, rn, user, c) {
+			return proto.ErrUnauthorized
+		}
+		return nil
+	}
+}
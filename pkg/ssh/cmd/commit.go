@@ -35,6 +35,15 @@ func commitCommand(renderer *lipgloss.Renderer) *cobra.Command {
 				return err
 			}
 
+			// The commits of SHA-256 repositories can't be parsed yet.
+			format, err := be.ObjectFormat(ctx, repoName)
+			if err != nil {
+				return err
+			}
+			if format != backend.ObjectFormatSHA1 {
+				return fmt.Errorf("commits of %s repositories aren't supported yet", format)
+			}
+
 			r, err := rr.Open()
 			if err != nil {
 				return err
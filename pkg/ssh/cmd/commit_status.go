@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/soft-serve/pkg/backend"
+	"github.com/spf13/cobra"
+)
+
+func statusesCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "statuses REPOSITORY REVISION",
+		Short:             "Show the statuses of a commit",
+		Long:              "Show the statuses reported for a commit, like CI results, and their combined state.",
+		Args:              cobra.ExactArgs(2),
+		PersistentPreRunE: checkIfReadable,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			repo := args[0]
+
+			sha, err := be.ResolveCommit(ctx, repo, args[1])
+			if err != nil {
+				return err
+			}
+
+			statuses, err := be.CommitStatuses(ctx, repo, sha)
+			if err != nil {
+				return err
+			}
+
+			state := backend.CombinedCommitState(statuses)
+			if state == "" {
+				state = "none"
+			}
+
+			cmd.Printf("%s\t%s\n", sha, state)
+			for _, s := range statuses {
+				cmd.Printf("%s\t%s\t%s\t%s\n", s.Context, s.State, s.Description, s.TargetURL)
+			}
+
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+func requiredStatusesCommand() *cobra.Command {
+	var clear bool
+	var contexts []string
+
+	cmd := &cobra.Command{
+		Use:   "required-statuses REPOSITORY [BRANCH...]",
+		Short: "Show or set the branches requiring passing commit statuses",
+		Long:  "Show or set the branch name patterns that only accept commits with passing statuses. Patterns use shell glob syntax, e.g. `release/*`. With --context, the given status contexts must succeed, otherwise the commit needs at least one status and all of them must succeed.",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			repo := args[0]
+
+			flags := cmd.Flags()
+			if len(args) == 1 && !clear && !flags.Changed("context") {
+				if err := checkIfReadable(cmd, args); err != nil {
+					return err
+				}
+
+				rs, err := be.RequiredStatuses(ctx, repo)
+				if err != nil {
+					return err
+				}
+
+				cmd.Printf("branches\t%s\n", strings.Join(rs.Branches, ","))
+				cmd.Printf("contexts\t%s\n", strings.Join(rs.Contexts, ","))
+				return nil
+			}
+
+			if err := checkIfAdmin(cmd, args); err != nil {
+				return err
+			}
+
+			rs, err := be.RequiredStatuses(ctx, repo)
+			if err != nil {
+				return err
+			}
+
+			switch {
+			case clear:
+				rs = backend.RequiredStatuses{}
+			case len(args) > 1:
+				rs.Branches = args[1:]
+			}
+			if flags.Changed("context") {
+				rs.Contexts = contexts
+			}
+
+			return be.SetRequiredStatuses(ctx, repo, rs)
+		},
+	}
+
+	cmd.Flags().BoolVar(&clear, "clear", false, "don't require passing statuses on any branch")
+	cmd.Flags().StringSliceVar(&contexts, "context", nil, "status contexts that must succeed")
+
+	return cmd
+}
@@ -15,6 +15,9 @@ func createCommand() *cobra.Command {
 	var description string
 	var projectName string
 	var hidden bool
+	var template string
+	var readme bool
+	var objectFormat string
 
 	cmd := &cobra.Command{
 		Use:               "create REPOSITORY",
@@ -27,11 +30,19 @@ func createCommand() *cobra.Command {
 			be := backend.FromContext(ctx)
 			user := proto.UserFromContext(ctx)
 			name := args[0]
+			if !cmd.Flags().Changed("readme") {
+				readme = cfg.AutoReadme.Enabled
+			}
+			explicitVisibility := cmd.Flags().Changed("private") || cmd.Flags().Changed("hidden")
 			r, err := be.CreateRepository(ctx, name, user, proto.RepositoryOptions{
-				Private:     private,
-				Description: description,
-				ProjectName: projectName,
-				Hidden:      hidden,
+				Private:            private,
+				Description:        description,
+				ProjectName:        projectName,
+				Hidden:             hidden,
+				Template:           template,
+				Readme:             readme,
+				ExplicitVisibility: explicitVisibility,
+				ObjectFormat:       objectFormat,
 			})
 			if err != nil {
 				return err
@@ -49,6 +60,10 @@ func createCommand() *cobra.Command {
 	cmd.Flags().StringVarP(&description, "description", "d", "", "set the repository description")
 	cmd.Flags().StringVarP(&projectName, "name", "n", "", "set the project name")
 	cmd.Flags().BoolVarP(&hidden, "hidden", "H", false, "hide the repository from the UI")
+	cmd.Flags().StringVarP(&template, "template", "t", "", "copy the branches and tags of an existing repository")
+	cmd.Flags().BoolVar(&readme, "readme", false, "commit a generated README if the repository is empty (defaults to auto_readme.enabled)")
+
+	cmd.Flags().StringVar(&objectFormat, "object-format", "", "the object format of the repository, sha1 or sha256 (defaults to sha1)")
 
 	return cmd
 }
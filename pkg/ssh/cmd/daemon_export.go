@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/charmbracelet/soft-serve/pkg/backend"
+	"github.com/spf13/cobra"
+)
+
+func daemonExportCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "daemon-export REPOSITORY [TRUE|FALSE]",
+		Short:             "Export or unexport a repository over the git:// protocol",
+		Long:              "Export or unexport a repository over the unauthenticated git:// protocol. Repositories aren't exported by default, and private repositories are never served, even if exported. The Git daemon must be enabled in the server configuration.",
+		Args:              cobra.RangeArgs(1, 2),
+		PersistentPreRunE: checkIfAdmin,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			repo := args[0]
+			switch len(args) {
+			case 1:
+				export, err := be.DaemonExport(ctx, repo)
+				if err != nil {
+					return err
+				}
+
+				cmd.Println(export)
+			case 2:
+				export, err := strconv.ParseBool(args[1])
+				if err != nil {
+					return fmt.Errorf("invalid value %q: %w", args[1], err)
+				}
+
+				return be.SetDaemonExport(ctx, repo, export)
+			}
+
+			return nil
+		},
+	}
+
+	return cmd
+}
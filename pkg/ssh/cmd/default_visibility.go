@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"sort"
+
+	"github.com/charmbracelet/soft-serve/pkg/config"
+	"github.com/charmbracelet/soft-serve/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+func defaultVisibilityCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "default-visibility [NAMESPACE]",
+		Short: "Show the default visibility of new repositories",
+		Long:  "Show the effective default visibility of new repositories created under a namespace. Without a namespace, list all the namespace visibility rules.",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			cfg := config.FromContext(ctx)
+			rules := cfg.Access.NamespaceVisibility
+
+			if len(args) == 0 {
+				namespaces := make([]string, 0, len(rules))
+				for ns := range rules {
+					namespaces = append(namespaces, ns)
+				}
+				sort.Strings(namespaces)
+				for _, ns := range namespaces {
+					cmd.Printf("%s\t%s\n", ns, rules[ns])
+				}
+				return nil
+			}
+
+			// Use a placeholder repository name to find what new repositories
+			// under the namespace would inherit.
+			ns := utils.SanitizeRepo(args[0])
+			vis, rule := cfg.Access.DefaultVisibility(ns + "/_")
+			if rule == "" {
+				rule = "default"
+			}
+			cmd.Printf("%s\t%s\n", vis, rule)
+			return nil
+		},
+	}
+
+	return cmd
+}
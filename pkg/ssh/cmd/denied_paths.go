@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"github.com/charmbracelet/soft-serve/pkg/backend"
+	"github.com/spf13/cobra"
+)
+
+func deniedPathsCommand() *cobra.Command {
+	var clear bool
+
+	cmd := &cobra.Command{
+		Use:               "denied-paths REPOSITORY [PATH...]",
+		Short:             "Show or set the paths that must not be in a repository history",
+		Long:              "Show or set the file or directory paths that must not be in the history of a repository. Clones, fetches, and archives of a repository with a denied path in any commit are refused, and so are pushes adding one.",
+		Args:              cobra.MinimumNArgs(1),
+		PersistentPreRunE: checkIfAdmin,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			repo := args[0]
+
+			if len(args) == 1 && !clear {
+				paths, err := be.DeniedPaths(ctx, repo)
+				if err != nil {
+					return err
+				}
+
+				for _, p := range paths {
+					cmd.Println(p)
+				}
+
+				return nil
+			}
+
+			return be.SetDeniedPaths(ctx, repo, args[1:])
+		},
+	}
+
+	cmd.Flags().BoolVar(&clear, "clear", false, "don't deny any path")
+
+	return cmd
+}
@@ -0,0 +1,178 @@
+package cmd
+
+import (
+	"strings"
+	"time"
+
+	"github.com/caarlos0/duration"
+	"github.com/caarlos0/tablewriter"
+	"github.com/charmbracelet/soft-serve/pkg/access"
+	"github.com/charmbracelet/soft-serve/pkg/backend"
+	"github.com/charmbracelet/soft-serve/pkg/sshutils"
+	"github.com/dustin/go-humanize"
+	"github.com/spf13/cobra"
+	gossh "golang.org/x/crypto/ssh"
+)
+
+func deployTokenCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "deploy-token",
+		Aliases: []string{"deploy-tokens"},
+		Short:   "Manage the deploy tokens of a repo",
+		Long:    "Manage the deploy tokens of a repo. Deploy tokens grant access to a single repo without a user, over HTTP as the password, or the username, of HTTP Basic authentication, and over SSH with the key they're mapped to.",
+	}
+
+	cmd.AddCommand(
+		deployTokenCreateCommand(),
+		deployTokenListCommand(),
+		deployTokenRevokeCommand(),
+	)
+
+	return cmd
+}
+
+func deployTokenCreateCommand() *cobra.Command {
+	var level string
+	var expiresIn string
+	var key string
+
+	cmd := &cobra.Command{
+		Use:               "create REPOSITORY NAME",
+		Short:             "Create a deploy token",
+		Long:              "Create a deploy token for a repo. LEVEL can be one of: read-only or read-write. Defaults to read-only. The token is only shown once.",
+		Args:              cobra.ExactArgs(2),
+		PersistentPreRunE: checkIfAdmin,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+
+			al := access.ParseAccessLevel(level)
+			if al < 0 {
+				return access.ErrInvalidAccessLevel
+			}
+
+			var pk gossh.PublicKey
+			if key != "" {
+				var err error
+				pk, _, err = sshutils.ParseAuthorizedKey(key)
+				if err != nil {
+					return err
+				}
+			}
+
+			var expiresAt time.Time
+			if expiresIn != "" {
+				d, err := duration.Parse(expiresIn)
+				if err != nil {
+					return err
+				}
+
+				expiresAt = time.Now().Add(d)
+			}
+
+			token, err := be.CreateDeployToken(ctx, args[0], args[1], al, pk, expiresAt)
+			if err != nil {
+				return err
+			}
+
+			notice := "Deploy token created"
+			if !expiresAt.IsZero() {
+				notice += " (expires " + humanize.Time(expiresAt) + ")"
+			}
+
+			cmd.PrintErrln(notice)
+			cmd.Println(token)
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&level, "level", "l", access.ReadOnlyAccess.String(), "access level of the token")
+	cmd.Flags().StringVar(&expiresIn, "expires-in", "", "token expiration time (e.g. 1y, 3mo, 2w, 5d4h, 1h30m)")
+	cmd.Flags().StringVar(&key, "key", "", "SSH public key, in authorized_keys format, to map the token to")
+
+	return cmd
+}
+
+func deployTokenListCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "list REPOSITORY",
+		Aliases:           []string{"ls"},
+		Short:             "List the deploy tokens of a repo",
+		Args:              cobra.ExactArgs(1),
+		PersistentPreRunE: checkIfAdmin,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			tokens, err := be.DeployTokens(ctx, args[0])
+			if err != nil {
+				return err
+			}
+
+			if len(tokens) == 0 {
+				cmd.Println("No deploy tokens found")
+				return nil
+			}
+
+			now := time.Now()
+			return tablewriter.Render(
+				cmd.OutOrStdout(),
+				tokens,
+				[]string{"Name", "Access", "Key", "Created At", "Expires In", "Last Used"},
+				func(t backend.DeployToken) ([]string, error) {
+					key := "-"
+					if t.PublicKey != nil {
+						key = gossh.FingerprintSHA256(t.PublicKey)
+					}
+
+					expiresAt := "-"
+					if !t.ExpiresAt.IsZero() {
+						if now.After(t.ExpiresAt) {
+							expiresAt = "expired"
+						} else {
+							expiresAt = humanize.Time(t.ExpiresAt)
+						}
+					}
+
+					lastUsed := "never"
+					if !t.LastUsedAt.IsZero() {
+						lastUsed = humanize.Time(t.LastUsedAt)
+					}
+
+					return []string{
+						t.Name,
+						t.AccessLevel.String(),
+						key,
+						humanize.Time(t.CreatedAt),
+						expiresAt,
+						lastUsed,
+					}, nil
+				},
+			)
+		},
+	}
+
+	return cmd
+}
+
+func deployTokenRevokeCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "revoke REPOSITORY NAME",
+		Aliases:           []string{"delete", "rm", "remove"},
+		Short:             "Revoke a deploy token",
+		Args:              cobra.ExactArgs(2),
+		PersistentPreRunE: checkIfAdmin,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			if err := be.RevokeDeployToken(ctx, args[0], strings.TrimSpace(args[1])); err != nil {
+				return err
+			}
+
+			cmd.PrintErrln("Deploy token revoked")
+			return nil
+		},
+	}
+
+	return cmd
+}
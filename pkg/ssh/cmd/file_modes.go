@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/soft-serve/pkg/backend"
+	"github.com/spf13/cobra"
+)
+
+func fileModesCommand() *cobra.Command {
+	var exempt []string
+	var clear, warnOnly bool
+
+	cmd := &cobra.Command{
+		Use:   "file-modes REPOSITORY [MODE...]",
+		Short: "Show or set the file modes pushes must not introduce",
+		Long:  "Show or set the tree entry modes pushes must not introduce to a repository: symlink, submodule, or executable. Pushes adding an entry with a denied mode, or changing an entry to one, are rejected with the offending paths. Exempt paths use shell glob syntax, e.g. `scripts/*`, and match everything under a directory. With --warn-only, such pushes are allowed with a warning.",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			repo := args[0]
+
+			flags := cmd.Flags()
+			if len(args) == 1 && !clear && !flags.Changed("exempt") && !flags.Changed("warn-only") {
+				if err := checkIfReadable(cmd, args); err != nil {
+					return err
+				}
+
+				r, err := be.FileModeRules(ctx, repo)
+				if err != nil {
+					return err
+				}
+
+				denied := make([]string, len(r.Denied))
+				for i, m := range r.Denied {
+					denied[i] = string(m)
+				}
+
+				mode := "enforce"
+				if r.WarnOnly {
+					mode = "warn"
+				}
+
+				cmd.Printf("denied\t%s\n", strings.Join(denied, ","))
+				cmd.Printf("exempt\t%s\n", strings.Join(r.Exempt, ","))
+				cmd.Printf("mode\t%s\n", mode)
+				return nil
+			}
+
+			if err := checkIfAdmin(cmd, args); err != nil {
+				return err
+			}
+
+			if clear {
+				return be.SetFileModeRules(ctx, repo, backend.FileModeRules{})
+			}
+
+			r, err := be.FileModeRules(ctx, repo)
+			if err != nil {
+				return err
+			}
+
+			if len(args) > 1 {
+				r.Denied = nil
+				for _, m := range args[1:] {
+					r.Denied = append(r.Denied, backend.FileMode(m))
+				}
+			}
+			if flags.Changed("exempt") {
+				r.Exempt = exempt
+			}
+			if flags.Changed("warn-only") {
+				r.WarnOnly = warnOnly
+			}
+
+			return be.SetFileModeRules(ctx, repo, r)
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&exempt, "exempt", nil, "paths allowed to use denied file modes")
+	cmd.Flags().BoolVar(&clear, "clear", false, "remove the file mode rules")
+	cmd.Flags().BoolVar(&warnOnly, "warn-only", false, "warn instead of rejecting pushes introducing denied file modes")
+
+	return cmd
+}
@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"github.com/charmbracelet/soft-serve/pkg/backend"
+	"github.com/dustin/go-humanize"
+	"github.com/spf13/cobra"
+)
+
+func forcePushesCommand() *cobra.Command {
+	var limit int
+
+	cmd := &cobra.Command{
+		Use:               "force-pushes REPOSITORY",
+		Short:             "Show the latest force-pushes to a repository",
+		Long:              "Show the latest ref updates that weren't fast-forwards, rewriting the history of the ref, with the ref, the old, and the new commit.",
+		Args:              cobra.ExactArgs(1),
+		PersistentPreRunE: checkIfReadable,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			repo := args[0]
+
+			events, err := be.AuditEvents(ctx, repo, backend.AuditActionForcePushed, limit)
+			if err != nil {
+				return err
+			}
+
+			for _, e := range events {
+				username := "anonymous"
+				if e.Username.Valid {
+					username = e.Username.String
+				}
+
+				cmd.Printf("%s\t%s\t%s\n", humanize.Time(e.CreatedAt), username, e.Details)
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVarP(&limit, "limit", "n", 10, "number of force-pushes to show")
+
+	return cmd
+}
@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"strings"
+	"time"
+
+	"github.com/caarlos0/tablewriter"
+	"github.com/charmbracelet/soft-serve/pkg/backend"
+	"github.com/spf13/cobra"
+)
+
+func freezeWindowCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "freeze-window",
+		Aliases: []string{"freeze-windows"},
+		Short:   "Manage repository freeze windows",
+		Long:    "Manage repository freeze windows. Pushes to the refs of a freeze window are rejected while it's on, with a message stating when it lifts. Admins can push anyway with `git push -o " + backend.SkipFreezeOption + "`.",
+	}
+
+	cmd.AddCommand(
+		freezeWindowAddCommand(),
+		freezeWindowRemoveCommand(),
+		freezeWindowListCommand(),
+	)
+
+	return cmd
+}
+
+func freezeWindowAddCommand() *cobra.Command {
+	var refs []string
+	var duration time.Duration
+	var warnOnly bool
+
+	cmd := &cobra.Command{
+		Use:               "add REPOSITORY NAME SCHEDULE...",
+		Short:             "Add a freeze window to a repository",
+		Long:              "Add a freeze window to a repository. The schedule is a cron expression of the times the window starts, like \"* * * * FRI\" for every minute of Fridays, optionally prefixed with a time zone, like \"CRON_TZ=Europe/Paris 0 18 * * FRI\". The window lasts --duration from each of these times. Ref patterns starting with refs/ match full ref names, the others match branch names.",
+		Args:              cobra.MinimumNArgs(3),
+		PersistentPreRunE: checkIfAdmin,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			// The fields of the schedule can come as separate arguments.
+			return be.AddFreezeWindow(ctx, args[0], backend.FreezeWindow{
+				Name:     args[1],
+				Refs:     refs,
+				Schedule: strings.Join(args[2:], " "),
+				Duration: duration,
+				WarnOnly: warnOnly,
+			})
+		},
+	}
+
+	cmd.Flags().StringSliceVarP(&refs, "refs", "r", nil, "ref patterns frozen by the window, all refs by default")
+	cmd.Flags().DurationVarP(&duration, "duration", "d", time.Minute, "how long the window lasts from each time of the schedule")
+	cmd.Flags().BoolVarP(&warnOnly, "warn-only", "w", false, "warn about pushes during the window instead of rejecting them")
+
+	return cmd
+}
+
+func freezeWindowRemoveCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "remove REPOSITORY NAME",
+		Short:             "Remove a freeze window from a repository",
+		Args:              cobra.ExactArgs(2),
+		PersistentPreRunE: checkIfAdmin,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			return be.RemoveFreezeWindow(ctx, args[0], args[1])
+		},
+	}
+
+	return cmd
+}
+
+func freezeWindowListCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "list REPOSITORY",
+		Short:             "List repository freeze windows and whether they're on",
+		Args:              cobra.ExactArgs(1),
+		PersistentPreRunE: checkIfReadable,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			windows, err := be.FreezeWindows(ctx, args[0])
+			if err != nil {
+				return err
+			}
+
+			now := time.Now()
+			return tablewriter.Render(
+				cmd.OutOrStdout(),
+				windows,
+				[]string{"Name", "Refs", "Schedule", "Duration", "Mode", "Status"},
+				func(w backend.FreezeWindow) ([]string, error) {
+					refs := "all"
+					if len(w.Refs) > 0 {
+						refs = strings.Join(w.Refs, ",")
+					}
+
+					mode := "enforce"
+					if w.WarnOnly {
+						mode = "warn"
+					}
+
+					status := "open"
+					if until, frozen := w.FrozenUntil(now); frozen {
+						status = "frozen until further notice"
+						if !until.IsZero() {
+							status = "frozen until " + until.Format("Mon, 02 Jan 2006 15:04 MST")
+						}
+					}
+
+					return []string{w.Name, refs, w.Schedule, w.Duration.String(), mode, status}, nil
+				},
+			)
+		},
+	}
+
+	return cmd
+}
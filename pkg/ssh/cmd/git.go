@@ -1,7 +1,9 @@
 package cmd
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"path/filepath"
 	"strings"
 	"time"
@@ -14,10 +16,12 @@ import (
 	"github.com/charmbracelet/soft-serve/pkg/lfs"
 	"github.com/charmbracelet/soft-serve/pkg/proto"
 	"github.com/charmbracelet/soft-serve/pkg/sshutils"
+	"github.com/charmbracelet/soft-serve/pkg/tracing"
 	"github.com/charmbracelet/soft-serve/pkg/utils"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
 	"github.com/spf13/cobra"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 var (
@@ -26,21 +30,21 @@ var (
 		Subsystem: "git",
 		Name:      "upload_pack_total",
 		Help:      "The total number of git-upload-pack requests",
-	}, []string{"repo"})
+	}, []string{"repo", "anonymous"})
 
 	receivePackCounter = promauto.NewCounterVec(prometheus.CounterOpts{
 		Namespace: "soft_serve",
 		Subsystem: "git",
 		Name:      "receive_pack_total",
 		Help:      "The total number of git-receive-pack requests",
-	}, []string{"repo"})
+	}, []string{"repo", "anonymous"})
 
 	uploadArchiveCounter = promauto.NewCounterVec(prometheus.CounterOpts{
 		Namespace: "soft_serve",
 		Subsystem: "git",
 		Name:      "upload_archive_total",
 		Help:      "The total number of git-upload-archive requests",
-	}, []string{"repo"})
+	}, []string{"repo", "anonymous"})
 
 	lfsAuthenticateCounter = promauto.NewCounterVec(prometheus.CounterOpts{
 		Namespace: "soft_serve",
@@ -61,21 +65,21 @@ var (
 		Subsystem: "git",
 		Name:      "upload_pack_seconds_total",
 		Help:      "The total time spent on git-upload-pack requests",
-	}, []string{"repo"})
+	}, []string{"repo", "anonymous"})
 
 	receivePackSeconds = promauto.NewCounterVec(prometheus.CounterOpts{
 		Namespace: "soft_serve",
 		Subsystem: "git",
 		Name:      "receive_pack_seconds_total",
 		Help:      "The total time spent on git-receive-pack requests",
-	}, []string{"repo"})
+	}, []string{"repo", "anonymous"})
 
 	uploadArchiveSeconds = promauto.NewCounterVec(prometheus.CounterOpts{
 		Namespace: "soft_serve",
 		Subsystem: "git",
 		Name:      "upload_archive_seconds_total",
 		Help:      "The total time spent on git-upload-archive requests",
-	}, []string{"repo"})
+	}, []string{"repo", "anonymous"})
 
 	lfsAuthenticateSeconds = promauto.NewCounterVec(prometheus.CounterOpts{
 		Namespace: "soft_serve",
@@ -173,10 +177,23 @@ func gitRunE(cmd *cobra.Command, args []string) error {
 
 	// repo should be in the form of "repo.git"
 	name := utils.SanitizeRepo(args[0])
+	// Aliases are served their canonical repository.
+	if alias, ok := be.ResolveRepoAlias(ctx, name); ok {
+		logger.Debug("resolved repository alias", "alias", name, "repo", alias.RepoName)
+		name = alias.RepoName
+		if alias.Message != "" && !strings.HasPrefix(cmd.Name(), "git-lfs") {
+			fmt.Fprintln(cmd.ErrOrStderr(), alias.Message) // nolint: errcheck
+		}
+	}
 	pk := sshutils.PublicKeyFromContext(ctx)
 	ak := sshutils.MarshalAuthorizedKey(pk)
 	user := proto.UserFromContext(ctx)
 	accessLevel := be.AccessLevelForUser(ctx, name, user)
+	anonymous := cfg.Stats.AnonymousLabel(user == nil)
+	tracing.SetAttributes(ctx,
+		attribute.String("repo", name),
+		attribute.String("access_level", accessLevel.String()),
+	)
 	// git bare repositories should end in ".git"
 	// https://git-scm.com/docs/gitrepository-layout
 	repoDir := name + ".git"
@@ -189,6 +206,15 @@ func gitRunE(cmd *cobra.Command, args []string) error {
 	repo, _ := be.Repository(ctx, name)
 	ctx = proto.WithRepositoryContext(ctx, repo)
 
+	// Clients using the old name of a renamed repository are told the new
+	// one, instead of creating a new repository by pushing.
+	if repo == nil {
+		if newName, ok := be.RenamedRepository(ctx, name); ok && be.AccessLevelForUser(ctx, newName, user) >= access.ReadOnlyAccess {
+			url := strings.TrimSuffix(cfg.SSH.PublicURL, "/") + "/" + newName
+			return backend.RenamedRepositoryError(newName, url)
+		}
+	}
+
 	// Environment variables to pass down to git hooks.
 	envs := []string{
 		"SOFT_SERVE_REPO_NAME=" + name,
@@ -203,6 +229,7 @@ func gitRunE(cmd *cobra.Command, args []string) error {
 		)
 	}
 
+	envs = append(envs, be.HookEnv(ctx, name, user, accessLevel)...)
 	envs = append(envs, cfg.Environ()...)
 
 	// Add GIT_PROTOCOL from session.
@@ -230,31 +257,48 @@ func gitRunE(cmd *cobra.Command, args []string) error {
 
 	switch service {
 	case git.ReceivePackService:
-		receivePackCounter.WithLabelValues(name).Inc()
+		receivePackCounter.WithLabelValues(name, anonymous).Inc()
 		defer func() {
-			receivePackSeconds.WithLabelValues(name).Add(time.Since(start).Seconds())
+			receivePackSeconds.WithLabelValues(name, anonymous).Add(time.Since(start).Seconds())
 		}()
 		if accessLevel < access.ReadWriteAccess {
 			return git.ErrNotAuthed
 		}
+		if cfg.Replication.IsReplica() {
+			return replicaPushError(cfg, name)
+		}
+		if err := checkProtocol(ctx, be, name, true); err != nil {
+			return err
+		}
 		if repo == nil {
-			if _, err := be.CreateRepository(ctx, name, user, proto.RepositoryOptions{Private: false}); err != nil {
+			if _, err := be.CreateRepository(ctx, name, user, proto.RepositoryOptions{}); err != nil {
 				log.Errorf("failed to create repo: %s", err)
 				return err
 			}
 			createRepoCounter.WithLabelValues(name).Inc()
 		}
 
+		unlock, err := be.LockRepositoryForPush(ctx, name)
+		if err != nil {
+			return err
+		}
+		defer unlock()
+
 		if err := service.Handler(ctx, scmd); err != nil {
 			logger.Error("failed to handle git service", "service", service, "err", err, "repo", name)
 			defer func() {
 				if repo == nil {
 					// If the repo was created, but the request failed, delete it.
+					unlock()
 					be.DeleteRepository(ctx, name) // nolint: errcheck
 				}
 			}()
 
-			return git.ErrSystemMalfunction
+			if errors.Is(err, git.ErrServiceTimeout) {
+				return git.ErrServiceTimeout
+			}
+
+			return git.ClientError(ctx, err)
 		}
 
 		if err := git.EnsureDefaultBranch(ctx, scmd.Dir); err != nil {
@@ -262,7 +306,7 @@ func gitRunE(cmd *cobra.Command, args []string) error {
 			return git.ErrSystemMalfunction
 		}
 
-		receivePackCounter.WithLabelValues(name).Inc()
+		receivePackCounter.WithLabelValues(name, anonymous).Inc()
 
 		return nil
 	case git.UploadPackService, git.UploadArchiveService:
@@ -274,25 +318,60 @@ func gitRunE(cmd *cobra.Command, args []string) error {
 			return git.ErrInvalidRepo
 		}
 
+		if err := checkProtocol(ctx, be, name, false); err != nil {
+			return err
+		}
+
+		if err := be.CheckDeniedPaths(ctx, name); errors.Is(err, proto.ErrDeniedPath) {
+			return err
+		} else if err != nil {
+			logger.Error("failed to check denied paths", "err", err, "repo", name)
+			return git.ErrSystemMalfunction
+		}
+
 		switch service {
 		case git.UploadArchiveService:
-			uploadArchiveCounter.WithLabelValues(name).Inc()
+			uploadArchiveCounter.WithLabelValues(name, anonymous).Inc()
 			defer func() {
-				uploadArchiveSeconds.WithLabelValues(name).Add(time.Since(start).Seconds())
+				uploadArchiveSeconds.WithLabelValues(name, anonymous).Add(time.Since(start).Seconds())
 			}()
 		default:
-			uploadPackCounter.WithLabelValues(name).Inc()
+			uploadPackCounter.WithLabelValues(name, anonymous).Inc()
 			defer func() {
-				uploadPackSeconds.WithLabelValues(name).Add(time.Since(start).Seconds())
+				uploadPackSeconds.WithLabelValues(name, anonymous).Add(time.Since(start).Seconds())
 			}()
 		}
 
-		err := service.Handler(ctx, scmd)
+		var addr string
+		if sess := sshutils.SessionFromContext(ctx); sess != nil {
+			addr = sess.RemoteAddr().String()
+		}
+
+		acquire := be.AcquireUploadPack
+		if service == git.UploadArchiveService {
+			acquire = be.AcquireUploadArchive
+		}
+		release, err := acquire(ctx, addr)
+		if err != nil {
+			return err
+		}
+		defer release()
+
+		err = service.Handler(ctx, scmd)
 		if errors.Is(err, git.ErrInvalidRepo) {
 			return git.ErrInvalidRepo
+		} else if errors.Is(err, git.ErrServiceTimeout) {
+			logger.Error("failed to handle git service", "service", service, "err", err, "repo", name)
+			return git.ErrServiceTimeout
 		} else if err != nil {
 			logger.Error("failed to handle git service", "service", service, "err", err, "repo", name)
-			return git.ErrSystemMalfunction
+			return git.ClientError(ctx, err)
+		}
+
+		if service == git.UploadPackService {
+			if err := be.RecordClone(ctx, name, user, "ssh"); err != nil {
+				logger.Error("failed to record clone", "err", err, "repo", name)
+			}
 		}
 
 		return nil
@@ -307,6 +386,9 @@ func gitRunE(cmd *cobra.Command, args []string) error {
 			if accessLevel < access.ReadWriteAccess {
 				return git.ErrNotAuthed
 			}
+			if cfg.Replication.IsReplica() {
+				return replicaPushError(cfg, name)
+			}
 		default:
 			return git.ErrInvalidRequest
 		}
@@ -315,6 +397,14 @@ func gitRunE(cmd *cobra.Command, args []string) error {
 			return git.ErrInvalidRepo
 		}
 
+		// git-lfs-authenticate only hands out credentials, the objects are
+		// then transferred over HTTP and checked there.
+		if service == git.LFSTransferService {
+			if err := checkProtocol(ctx, be, name, operation == lfs.OperationUpload); err != nil {
+				return err
+			}
+		}
+
 		scmd.Args = []string{
 			name,
 			args[1],
@@ -343,3 +433,27 @@ func gitRunE(cmd *cobra.Command, args []string) error {
 
 	return errors.New("unsupported git service")
 }
+
+// replicaPushError returns the error for a push to a replica, pointing to the
+// repository on the primary when its URL is known.
+func replicaPushError(cfg *config.Config, name string) error {
+	if cfg.Replication.PrimarySSHURL == "" {
+		return proto.ErrReadOnlyReplica
+	}
+
+	url := strings.TrimSuffix(cfg.Replication.PrimarySSHURL, "/") + "/" + name
+	return fmt.Errorf("%w, push to %s instead", proto.ErrReadOnlyReplica, url)
+}
+
+// checkProtocol returns an error if the repository can't be pushed to, or
+// read when push is false, over SSH.
+func checkProtocol(ctx context.Context, be *backend.Backend, name string, push bool) error {
+	if err := be.CheckProtocol(ctx, name, backend.ProtocolSSH, push); errors.Is(err, proto.ErrProtocolNotAllowed) {
+		return err
+	} else if err != nil {
+		log.FromContext(ctx).Error("failed to check protocol", "err", err, "repo", name)
+		return git.ErrSystemMalfunction
+	}
+
+	return nil
+}
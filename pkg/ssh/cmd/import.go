@@ -18,6 +18,7 @@ func importCommand() *cobra.Command {
 	var hidden bool
 	var lfs bool
 	var lfsEndpoint string
+	var dissociate bool
 
 	cmd := &cobra.Command{
 		Use:               "import REPOSITORY REMOTE",
@@ -29,15 +30,18 @@ func importCommand() *cobra.Command {
 			be := backend.FromContext(ctx)
 			user := proto.UserFromContext(ctx)
 			name := args[0]
+			explicitVisibility := cmd.Flags().Changed("private") || cmd.Flags().Changed("hidden")
 			remote := args[1]
 			if _, err := be.ImportRepository(ctx, name, user, remote, proto.RepositoryOptions{
-				Private:     private,
-				Description: description,
-				ProjectName: projectName,
-				Mirror:      mirror,
-				Hidden:      hidden,
-				LFS:         lfs,
-				LFSEndpoint: lfsEndpoint,
+				Private:            private,
+				Description:        description,
+				ProjectName:        projectName,
+				Mirror:             mirror,
+				Hidden:             hidden,
+				LFS:                lfs,
+				LFSEndpoint:        lfsEndpoint,
+				Dissociate:         dissociate,
+				ExplicitVisibility: explicitVisibility,
 			}); err != nil {
 				if errors.Is(err, task.ErrAlreadyStarted) {
 					return errors.New("import already in progress")
@@ -57,6 +61,7 @@ func importCommand() *cobra.Command {
 	cmd.Flags().StringVarP(&description, "description", "d", "", "set the repository description")
 	cmd.Flags().StringVarP(&projectName, "name", "n", "", "set the project name")
 	cmd.Flags().BoolVarP(&hidden, "hidden", "H", false, "hide the repository from the UI")
+	cmd.Flags().BoolVarP(&dissociate, "dissociate", "", false, "copy the objects borrowed from alternates of a local repository")
 
 	return cmd
 }
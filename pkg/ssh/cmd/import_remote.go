@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"errors"
+
+	"github.com/charmbracelet/soft-serve/pkg/backend"
+	"github.com/charmbracelet/soft-serve/pkg/proto"
+	"github.com/charmbracelet/soft-serve/pkg/task"
+	"github.com/spf13/cobra"
+)
+
+// importRemoteCommand is the command for importing a repository from a
+// remote URL.
+func importRemoteCommand() *cobra.Command {
+	var private bool
+	var description string
+	var projectName string
+	var mirror bool
+	var hidden bool
+	var lfs bool
+
+	cmd := &cobra.Command{
+		Use:               "import-remote URL REPOSITORY",
+		Short:             "Import a repository from a remote URL",
+		Long:              "Import a repository from a remote URL, over HTTP, SSH, or the git protocol. The objects of the new repository are checked with git fsck. HTTP remotes use the credentials stored in import.credentials for their host, SSH remotes use the server client key. With --mirror, the repository keeps syncing from the remote.",
+		Args:              cobra.ExactArgs(2),
+		PersistentPreRunE: checkIfAdmin,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			user := proto.UserFromContext(ctx)
+			remote, name := args[0], args[1]
+			if err := backend.ValidateRemoteURL(remote); err != nil {
+				return err
+			}
+
+			explicitVisibility := cmd.Flags().Changed("private") || cmd.Flags().Changed("hidden")
+			r, err := be.ImportRepository(ctx, name, user, remote, proto.RepositoryOptions{
+				Private:            private,
+				Description:        description,
+				ProjectName:        projectName,
+				Mirror:             mirror,
+				Hidden:             hidden,
+				LFS:                lfs,
+				ExplicitVisibility: explicitVisibility,
+				Fsck:               true,
+				Progress:           cmd.ErrOrStderr(),
+			})
+			if err != nil {
+				if errors.Is(err, task.ErrAlreadyStarted) {
+					return errors.New("import already in progress")
+				}
+
+				return err
+			}
+
+			cmd.PrintErrf("Imported repository %s\n", r.Name())
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVarP(&lfs, "lfs", "", false, "pull Git LFS objects")
+	cmd.Flags().BoolVarP(&mirror, "mirror", "m", false, "keep mirroring the remote")
+	cmd.Flags().BoolVarP(&private, "private", "p", false, "make the repository private")
+	cmd.Flags().StringVarP(&description, "description", "d", "", "set the repository description")
+	cmd.Flags().StringVarP(&projectName, "name", "n", "", "set the project name")
+	cmd.Flags().BoolVarP(&hidden, "hidden", "H", false, "hide the repository from the UI")
+
+	return cmd
+}
@@ -23,6 +23,10 @@ func InfoCommand() *cobra.Command {
 
 			cmd.Printf("Username: %s\n", user.Username())
 			cmd.Printf("Admin: %t\n", user.IsAdmin())
+			if usage, err := be.DiskUsage(ctx, user); err == nil && usage.Quota > 0 {
+				cmd.Printf("Disk usage: %s\n", usage)
+			}
+			cmd.Printf("Current key: %s\n", sshutils.KeyFingerprint(pk))
 			cmd.Printf("Public keys:\n")
 			for _, pk := range user.PublicKeys() {
 				cmd.Printf("  %s\n", sshutils.MarshalAuthorizedKey(pk))
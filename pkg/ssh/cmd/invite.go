@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/caarlos0/duration"
+	"github.com/caarlos0/tablewriter"
+	"github.com/charmbracelet/soft-serve/pkg/backend"
+	"github.com/charmbracelet/soft-serve/pkg/config"
+	"github.com/charmbracelet/soft-serve/pkg/db/models"
+	"github.com/dustin/go-humanize"
+	"github.com/spf13/cobra"
+)
+
+func userInviteCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "invite",
+		Aliases: []string{"invites"},
+		Short:   "Manage key registration invites",
+		Long:    "Manage key registration invites. A client connecting with an unregistered key is asked for an invite code, then has to reconnect and sign in with the key, which registers it to the invited user, creating the user if needed. Codes can only be used once. Invites require ssh.invites to be enabled and anonymous users to have no access.",
+	}
+
+	var admin bool
+	var expiresIn string
+	createCmd := &cobra.Command{
+		Use:               "create USERNAME",
+		Short:             "Create an invite code for a user",
+		Args:              cobra.ExactArgs(1),
+		PersistentPreRunE: checkIfAdmin,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			d, err := duration.Parse(expiresIn)
+			if err != nil {
+				return err
+			}
+
+			code, err := be.CreateInvite(ctx, args[0], admin, d)
+			if err != nil {
+				return err
+			}
+
+			if !config.FromContext(ctx).SSH.Invites {
+				cmd.PrintErrln("Invites are disabled, enable ssh.invites to let clients use this code")
+			}
+
+			cmd.PrintErrln("Invite created (expires " + humanize.Time(time.Now().Add(d)) + ")")
+			cmd.Println(code)
+			return nil
+		},
+	}
+
+	createCmd.Flags().BoolVarP(&admin, "admin", "a", false, "make the user an admin if it's created")
+	createCmd.Flags().StringVar(&expiresIn, "expires-in", "7d", "invite expiration time (e.g. 2w, 5d4h, 1h30m)")
+
+	listCmd := &cobra.Command{
+		Use:               "list",
+		Aliases:           []string{"ls"},
+		Short:             "List invites",
+		Args:              cobra.NoArgs,
+		PersistentPreRunE: checkIfAdmin,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			invites, err := be.Invites(ctx)
+			if err != nil {
+				return err
+			}
+
+			if len(invites) == 0 {
+				cmd.Println("No invites found")
+				return nil
+			}
+
+			now := time.Now()
+			return tablewriter.Render(
+				cmd.OutOrStdout(),
+				invites,
+				[]string{"ID", "Username", "Admin", "Created At", "Status", "Key"},
+				func(i models.Invite) ([]string, error) {
+					status := "expires " + humanize.Time(i.ExpiresAt)
+					switch {
+					case i.UsedAt.Valid:
+						status = "used " + humanize.Time(i.UsedAt.Time)
+					case now.After(i.ExpiresAt):
+						status = "expired"
+					}
+
+					return []string{
+						strconv.FormatInt(i.ID, 10),
+						i.Username,
+						strconv.FormatBool(i.Admin),
+						humanize.Time(i.CreatedAt),
+						status,
+						i.UsedKey.String,
+					}, nil
+				},
+			)
+		},
+	}
+
+	revokeCmd := &cobra.Command{
+		Use:               "revoke ID",
+		Aliases:           []string{"delete", "rm"},
+		Short:             "Revoke an invite",
+		Args:              cobra.ExactArgs(1),
+		PersistentPreRunE: checkIfAdmin,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			id, err := strconv.ParseInt(args[0], 10, 64)
+			if err != nil {
+				return err
+			}
+
+			return be.RevokeInvite(ctx, id)
+		},
+	}
+
+	cmd.AddCommand(
+		createCmd,
+		listCmd,
+		revokeCmd,
+	)
+
+	return cmd
+}
@@ -0,0 +1,251 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/caarlos0/tablewriter"
+	"github.com/charmbracelet/soft-serve/pkg/backend"
+	"github.com/charmbracelet/soft-serve/pkg/proto"
+	"github.com/dustin/go-humanize"
+	"github.com/spf13/cobra"
+)
+
+func issueCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "issue",
+		Aliases: []string{"issues"},
+		Short:   "Manage the issues of a repo",
+		Long:    "Manage the issues of a repo. Users that can read a repo can open issues and comment on them. Issues can be closed by the user that opened them, and by collaborators.",
+	}
+
+	cmd.AddCommand(
+		issueCloseCommand(),
+		issueCommentCommand(),
+		issueCreateCommand(),
+		issueExportCommand(),
+		issueListCommand(),
+		issueShowCommand(),
+	)
+
+	return cmd
+}
+
+func issueCreateCommand() *cobra.Command {
+	var body string
+
+	cmd := &cobra.Command{
+		Use:               "create REPOSITORY TITLE...",
+		Aliases:           []string{"open", "new"},
+		Short:             "Open an issue",
+		Args:              cobra.MinimumNArgs(2),
+		PersistentPreRunE: checkIfReadable,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			user := proto.UserFromContext(ctx)
+			issue, err := be.CreateIssue(ctx, args[0], user, strings.Join(args[1:], " "), body)
+			if err != nil {
+				return err
+			}
+
+			cmd.PrintErrf("Issue #%d created\n", issue.Number)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&body, "body", "b", "", "description of the issue")
+
+	return cmd
+}
+
+func issueListCommand() *cobra.Command {
+	var state string
+
+	cmd := &cobra.Command{
+		Use:               "list REPOSITORY",
+		Aliases:           []string{"ls"},
+		Short:             "List the issues of a repo",
+		Args:              cobra.ExactArgs(1),
+		PersistentPreRunE: checkIfReadable,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			if state == "all" {
+				state = ""
+			}
+
+			issues, err := be.Issues(ctx, args[0], state)
+			if err != nil {
+				return err
+			}
+
+			if len(issues) == 0 {
+				cmd.Println("No issues found")
+				return nil
+			}
+
+			return tablewriter.Render(
+				cmd.OutOrStdout(),
+				issues,
+				[]string{"Number", "Title", "State", "Comments", "Opened By", "Opened At"},
+				func(i backend.Issue) ([]string, error) {
+					by := i.Username
+					if by == "" {
+						by = "-"
+					}
+
+					return []string{
+						"#" + strconv.FormatInt(i.Number, 10),
+						i.Title,
+						i.State,
+						strconv.FormatInt(i.CommentCount, 10),
+						by,
+						humanize.Time(i.CreatedAt),
+					}, nil
+				},
+			)
+		},
+	}
+
+	cmd.Flags().StringVarP(&state, "state", "s", backend.IssueStateOpen, "only list the issues in this state, one of open, closed, or all")
+
+	return cmd
+}
+
+func issueShowCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "show REPOSITORY NUMBER",
+		Aliases:           []string{"info"},
+		Short:             "Show an issue and its comments",
+		Args:              cobra.ExactArgs(2),
+		PersistentPreRunE: checkIfReadable,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			number, err := parseIssueNumber(args[1])
+			if err != nil {
+				return err
+			}
+
+			issue, err := be.Issue(ctx, args[0], number)
+			if err != nil {
+				return err
+			}
+
+			cmd.Printf("#%d %s\n", issue.Number, issue.Title)
+			cmd.Println("State:", issue.State)
+			if issue.Username != "" {
+				cmd.Println("Opened By:", issue.Username)
+			}
+			cmd.Println("Opened At:", humanize.Time(issue.CreatedAt))
+			if body := strings.TrimSpace(issue.Body); body != "" {
+				cmd.Println()
+				cmd.Println(body)
+			}
+			for _, c := range issue.Comments {
+				by := c.Username
+				if by == "" {
+					by = "-"
+				}
+				cmd.Println()
+				cmd.Printf("%s commented %s:\n", by, humanize.Time(c.CreatedAt))
+				cmd.Println(strings.TrimSpace(c.Body))
+			}
+
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+func issueCommentCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "comment REPOSITORY NUMBER COMMENT...",
+		Short:             "Comment on an issue",
+		Args:              cobra.MinimumNArgs(3),
+		PersistentPreRunE: checkIfReadable,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			user := proto.UserFromContext(ctx)
+			number, err := parseIssueNumber(args[1])
+			if err != nil {
+				return err
+			}
+
+			if err := be.CommentIssue(ctx, args[0], user, number, strings.Join(args[2:], " ")); err != nil {
+				return err
+			}
+
+			cmd.PrintErrln("Comment added")
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+func issueCloseCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "close REPOSITORY NUMBER",
+		Short:             "Close an issue",
+		Long:              "Close an issue. Only the user that opened the issue and collaborators can close it.",
+		Args:              cobra.ExactArgs(2),
+		PersistentPreRunE: checkIfReadable,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			user := proto.UserFromContext(ctx)
+			number, err := parseIssueNumber(args[1])
+			if err != nil {
+				return err
+			}
+
+			if err := be.CloseIssue(ctx, args[0], user, number); err != nil {
+				return err
+			}
+
+			cmd.PrintErrf("Issue #%d closed\n", number)
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+func issueExportCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "export REPOSITORY",
+		Short:             "Write all the issues of a repo and their comments as JSON",
+		Args:              cobra.ExactArgs(1),
+		PersistentPreRunE: checkIfReadable,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			issues, err := be.ExportIssues(ctx, args[0])
+			if err != nil {
+				return err
+			}
+
+			enc := json.NewEncoder(cmd.OutOrStdout())
+			enc.SetIndent("", "  ")
+			return enc.Encode(issues)
+		},
+	}
+
+	return cmd
+}
+
+// parseIssueNumber parses an issue number, with or without a leading '#'.
+func parseIssueNumber(s string) (int64, error) {
+	n, err := strconv.ParseInt(strings.TrimPrefix(s, "#"), 10, 64)
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("invalid issue number %q", s)
+	}
+
+	return n, nil
+}
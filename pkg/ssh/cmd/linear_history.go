@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/soft-serve/pkg/backend"
+	"github.com/spf13/cobra"
+)
+
+func linearHistoryCommand() *cobra.Command {
+	var clear, warnOnly bool
+
+	cmd := &cobra.Command{
+		Use:   "linear-history REPOSITORY [BRANCH...]",
+		Short: "Show or set the branches requiring linear history",
+		Long:  "Show or set the branch name patterns that don't accept merge commits. Patterns use shell glob syntax, e.g. `release/*`. The initial push to an empty repository is exempt. With --warn-only, pushes introducing merge commits are allowed with a warning.",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			repo := args[0]
+
+			flags := cmd.Flags()
+			if len(args) == 1 && !clear && !flags.Changed("warn-only") {
+				if err := checkIfReadable(cmd, args); err != nil {
+					return err
+				}
+
+				l, err := be.LinearHistory(ctx, repo)
+				if err != nil {
+					return err
+				}
+
+				mode := "enforce"
+				if l.WarnOnly {
+					mode = "warn"
+				}
+
+				cmd.Printf("branches\t%s\n", strings.Join(l.Branches, ","))
+				cmd.Printf("mode\t%s\n", mode)
+				return nil
+			}
+
+			if err := checkIfAdmin(cmd, args); err != nil {
+				return err
+			}
+
+			l, err := be.LinearHistory(ctx, repo)
+			if err != nil {
+				return err
+			}
+
+			switch {
+			case clear:
+				l.Branches = nil
+			case len(args) > 1:
+				l.Branches = args[1:]
+			}
+			if flags.Changed("warn-only") {
+				l.WarnOnly = warnOnly
+			}
+
+			return be.SetLinearHistory(ctx, repo, l)
+		},
+	}
+
+	cmd.Flags().BoolVar(&clear, "clear", false, "don't require linear history on any branch")
+	cmd.Flags().BoolVar(&warnOnly, "warn-only", false, "warn instead of rejecting pushes introducing merge commits")
+
+	return cmd
+}
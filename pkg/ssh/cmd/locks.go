@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/caarlos0/tablewriter"
+	"github.com/charmbracelet/soft-serve/pkg/backend"
+	"github.com/charmbracelet/soft-serve/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+func locksCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "locks [REPOSITORY]",
+		Short:             "Show the operations holding repository locks",
+		Long:              "Show the operations holding or waiting for the lock of each repository, or of the given repository. Operations changing refs, like pushes, share the lock, and operations changing the repository as a whole, like renames, hold it exclusively.",
+		Args:              cobra.RangeArgs(0, 1),
+		PersistentPreRunE: checkIfAdmin,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+
+			type row struct {
+				repo   string
+				holder backend.RepoLockHolder
+				wait   int
+			}
+
+			var rows []row
+			for _, l := range be.RepoLocks() {
+				if len(args) > 0 && l.Repo != utils.SanitizeRepo(args[0]) {
+					continue
+				}
+
+				if len(l.Holders) == 0 {
+					rows = append(rows, row{repo: l.Repo, wait: l.Waiting})
+				}
+				for _, h := range l.Holders {
+					rows = append(rows, row{repo: l.Repo, holder: h, wait: l.Waiting})
+				}
+			}
+
+			if len(rows) == 0 {
+				cmd.Println("No repository locks are held.")
+				return nil
+			}
+
+			return tablewriter.Render(
+				cmd.OutOrStdout(),
+				rows,
+				[]string{"Repository", "Operation", "Mode", "Held for", "Waiting"},
+				func(r row) ([]string, error) {
+					if r.holder.Op == "" {
+						return []string{r.repo, "-", "-", "-", strconv.Itoa(r.wait)}, nil
+					}
+
+					mode := "shared"
+					if r.holder.Exclusive {
+						mode = "exclusive"
+					}
+
+					return []string{
+						r.repo,
+						r.holder.Op,
+						mode,
+						fmt.Sprint(time.Since(r.holder.Since).Round(time.Second)),
+						strconv.Itoa(r.wait),
+					}, nil
+				},
+			)
+		},
+	}
+
+	return cmd
+}
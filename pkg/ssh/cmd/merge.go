@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/soft-serve/pkg/access"
+	"github.com/charmbracelet/soft-serve/pkg/backend"
+	"github.com/charmbracelet/soft-serve/pkg/proto"
+	"github.com/spf13/cobra"
+)
+
+func mergeCommand() *cobra.Command {
+	var ffOnly, noFF bool
+	var message string
+
+	cmd := &cobra.Command{
+		Use:               "merge REPOSITORY SOURCE TARGET",
+		Short:             "Merge a branch or a review into a branch",
+		Long:              "Merge SOURCE, a branch, a review ref like refs/reviews/1, or a commit, into the TARGET branch on the server. The target branch is fast-forwarded when possible, and gets a merge commit otherwise. The result must pass the same policies as a push to the target branch, and conflicts are rejected.",
+		Args:              cobra.ExactArgs(3),
+		PersistentPreRunE: checkCapability(access.CapabilityMerge),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			user := proto.UserFromContext(ctx)
+			rn := strings.TrimSuffix(args[0], ".git")
+
+			opts := backend.MergeOptions{Message: message}
+			switch {
+			case ffOnly:
+				opts.Mode = backend.MergeFastForwardOnly
+			case noFF:
+				opts.Mode = backend.MergeNoFastForward
+			}
+
+			res, err := be.Merge(ctx, cmd.ErrOrStderr(), rn, user, args[1], args[2], opts)
+			if err != nil {
+				return err
+			}
+
+			switch {
+			case res.UpToDate():
+				cmd.Printf("%s is already up to date with %s\n", args[2], args[1])
+			case res.FastForward:
+				cmd.Printf("Fast-forwarded %s to %s\n", args[2], res.After[:7])
+			default:
+				cmd.Printf("Merged %s into %s: %s\n", args[1], args[2], res.After[:7])
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&ffOnly, "ff-only", false, "only fast-forward the target branch")
+	cmd.Flags().BoolVar(&noFF, "no-ff", false, "always create a merge commit")
+	cmd.Flags().StringVarP(&message, "message", "m", "", "message of the merge commit")
+	cmd.MarkFlagsMutuallyExclusive("ff-only", "no-ff")
+
+	return cmd
+}
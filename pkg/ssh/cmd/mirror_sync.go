@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/caarlos0/tablewriter"
+	"github.com/charmbracelet/soft-serve/pkg/backend"
+	"github.com/charmbracelet/soft-serve/pkg/db/models"
+	"github.com/dustin/go-humanize"
+	"github.com/spf13/cobra"
+)
+
+func mirrorSyncCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "mirror-sync",
+		Aliases: []string{"mirror-syncs"},
+		Short:   "Manage pull mirror syncs",
+		Long:    "Manage pull mirror syncs. Pull mirrors fetch their upstream periodically, and every sync records the new objects, the updated refs, and the bytes fetched, or why it failed.",
+	}
+
+	cmd.AddCommand(
+		mirrorSyncListCommand(),
+		mirrorSyncRunCommand(),
+	)
+
+	return cmd
+}
+
+func mirrorSyncListCommand() *cobra.Command {
+	var limit int
+
+	cmd := &cobra.Command{
+		Use:               "list REPOSITORY",
+		Short:             "List the latest syncs of a pull mirror",
+		Args:              cobra.ExactArgs(1),
+		PersistentPreRunE: checkIfAdmin,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			syncs, err := be.MirrorSyncs(ctx, args[0], limit)
+			if err != nil {
+				return err
+			}
+
+			return tablewriter.Render(
+				cmd.OutOrStdout(),
+				syncs,
+				[]string{"Synced", "Status", "Objects", "Refs", "Fetched", "Duration", "Error"},
+				func(s models.MirrorSync) ([]string, error) {
+					// Only the first line of git errors fits the table.
+					lastErr, _, _ := strings.Cut(strings.TrimSpace(s.Error.String), "\n")
+					row := []string{
+						humanize.Time(s.CreatedAt),
+						s.Status,
+						strconv.FormatInt(s.NewObjects, 10),
+						strconv.FormatInt(s.UpdatedRefs, 10),
+						humanize.IBytes(uint64(s.FetchedBytes)),
+						(time.Duration(s.DurationMS) * time.Millisecond).String(),
+						lastErr,
+					}
+
+					return row, nil
+				},
+			)
+		},
+	}
+
+	cmd.Flags().IntVarP(&limit, "limit", "n", 10, "number of syncs to show")
+
+	return cmd
+}
+
+func mirrorSyncRunCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "run REPOSITORY",
+		Short:             "Sync a pull mirror with its upstream now",
+		Args:              cobra.ExactArgs(1),
+		PersistentPreRunE: checkIfAdmin,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			repo, err := be.Repository(ctx, args[0])
+			if err != nil {
+				return err
+			}
+
+			s, err := be.SyncMirror(ctx, repo)
+			if err != nil && s.Status != backend.MirrorSyncStatusOK {
+				return fmt.Errorf("%s: %w", s.Status, err)
+			} else if err != nil {
+				return err
+			}
+
+			cmd.Printf("Fetched %d new object(s) (%s) and updated %d ref(s).\n",
+				s.NewObjects, humanize.IBytes(uint64(s.FetchedBytes)), s.UpdatedRefs)
+			return nil
+		},
+	}
+
+	return cmd
+}
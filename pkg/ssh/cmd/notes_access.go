@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/soft-serve/pkg/access"
+	"github.com/charmbracelet/soft-serve/pkg/backend"
+	"github.com/spf13/cobra"
+)
+
+func notesAccessCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "notes-access REPOSITORY [read-write|admin-access]",
+		Short: "Set or get the access level required to update notes",
+		Long:  "Set or get the access level required to push git notes (refs/notes/*) to a repository. Defaults to read-write.",
+		Args:  cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			repo := args[0]
+			switch len(args) {
+			case 1:
+				if err := checkIfReadable(cmd, args); err != nil {
+					return err
+				}
+
+				level, err := be.NotesAccess(ctx, repo)
+				if err != nil {
+					return err
+				}
+
+				cmd.Println(level)
+			case 2:
+				if err := checkIfAdmin(cmd, args); err != nil {
+					return err
+				}
+
+				level := access.ParseAccessLevel(args[1])
+				if level < 0 {
+					return fmt.Errorf("invalid access level %q", args[1])
+				}
+
+				if err := be.SetNotesAccess(ctx, repo, level); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		},
+	}
+
+	return cmd
+}
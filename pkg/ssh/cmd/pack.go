@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"github.com/charmbracelet/soft-serve/pkg/backend"
+	"github.com/spf13/cobra"
+)
+
+func packCommand() *cobra.Command {
+	var compression, window int
+	var reset bool
+
+	cmd := &cobra.Command{
+		Use:   "pack REPOSITORY",
+		Short: "Show or set the pack settings of a repository",
+		Long:  "Show or set the compression level and delta window of the packs served when cloning or fetching a repository. Higher values produce smaller packs at the cost of server CPU time. With --reset, the repository uses the server settings.",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			repo := args[0]
+
+			flags := cmd.Flags()
+			if !reset && !flags.Changed("compression") && !flags.Changed("window") {
+				if err := checkIfReadable(cmd, args); err != nil {
+					return err
+				}
+
+				p, err := be.PackSettings(ctx, repo)
+				if err != nil {
+					return err
+				}
+
+				source := "server"
+				if p.Custom {
+					source = "repository"
+				}
+
+				cmd.Printf("compression\t%d\n", p.Compression)
+				cmd.Printf("window\t%d\n", p.Window)
+				cmd.Printf("source\t%s\n", source)
+				return nil
+			}
+
+			if err := checkIfAdmin(cmd, args); err != nil {
+				return err
+			}
+
+			if reset {
+				return be.ResetPackSettings(ctx, repo)
+			}
+
+			p, err := be.PackSettings(ctx, repo)
+			if err != nil {
+				return err
+			}
+
+			if flags.Changed("compression") {
+				p.Compression = compression
+			}
+			if flags.Changed("window") {
+				p.Window = window
+			}
+
+			return be.SetPackSettings(ctx, repo, p.PackConfig)
+		},
+	}
+
+	cmd.Flags().IntVar(&compression, "compression", 0, "zlib compression level, from 0 (none) to 9 (smallest), -1 for the zlib default")
+	cmd.Flags().IntVar(&window, "window", 0, "number of objects considered when looking for deltas")
+	cmd.Flags().BoolVar(&reset, "reset", false, "use the server settings")
+	cmd.MarkFlagsMutuallyExclusive("reset", "compression")
+	cmd.MarkFlagsMutuallyExclusive("reset", "window")
+
+	return cmd
+}
@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/soft-serve/pkg/backend"
+	"github.com/spf13/cobra"
+)
+
+func protocolsCommand() *cobra.Command {
+	var push, read []string
+	var clear bool
+
+	cmd := &cobra.Command{
+		Use:   "protocols REPOSITORY",
+		Short: "Show or set the protocols allowed to access the repository",
+		Long:  "Show or set the protocols the repository can be pushed to and read over. Pushes can be allowed over ssh and http, and reads over ssh, http, and git, the Git daemon protocol. Use all to allow every protocol, and --clear to allow every protocol for both. The restrictions apply to every user, admins included.",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			repo := args[0]
+
+			flags := cmd.Flags()
+			if !flags.Changed("push") && !flags.Changed("read") && !clear {
+				if err := checkIfReadable(cmd, args); err != nil {
+					return err
+				}
+
+				p, err := be.Protocols(ctx, repo)
+				if err != nil {
+					return err
+				}
+
+				cmd.Printf("push\t%s\n", protocolList(p.Push))
+				cmd.Printf("read\t%s\n", protocolList(p.Read))
+				return nil
+			}
+
+			if err := checkIfAdmin(cmd, args); err != nil {
+				return err
+			}
+
+			if clear {
+				return be.SetProtocols(ctx, repo, backend.Protocols{})
+			}
+
+			p, err := be.Protocols(ctx, repo)
+			if err != nil {
+				return err
+			}
+
+			if flags.Changed("push") {
+				p.Push = parseProtocolList(push)
+			}
+			if flags.Changed("read") {
+				p.Read = parseProtocolList(read)
+			}
+
+			return be.SetProtocols(ctx, repo, p)
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&push, "push", nil, "protocols allowed to push, or all")
+	cmd.Flags().StringSliceVar(&read, "read", nil, "protocols allowed to read, or all")
+	cmd.Flags().BoolVar(&clear, "clear", false, "allow every protocol")
+
+	return cmd
+}
+
+// protocolList formats allowed protocols, where none means all of them.
+func protocolList(protocols []string) string {
+	if len(protocols) == 0 {
+		return "all"
+	}
+
+	return strings.Join(protocols, ",")
+}
+
+// parseProtocolList parses the protocols of a flag, where all means none.
+func parseProtocolList(protocols []string) []string {
+	var list []string
+	for _, p := range protocols {
+		p = strings.ToLower(strings.TrimSpace(p))
+		switch p {
+		case "":
+		case "all":
+			return nil
+		default:
+			list = append(list, p)
+		}
+	}
+
+	return list
+}
@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/soft-serve/pkg/backend"
+	"github.com/charmbracelet/soft-serve/pkg/proto"
+	"github.com/spf13/cobra"
+)
+
+func pruneBranchesCommand() *cobra.Command {
+	var base string
+	var dryRun, yes bool
+
+	cmd := &cobra.Command{
+		Use:               "prune-branches REPOSITORY",
+		Short:             "Delete branches merged into a base branch",
+		Long:              "Delete the branches fully merged into the base branch, the default branch unless --base is given. Branches with protection rules, like linear history, signed pushes, or required statuses, are kept. Asks for confirmation unless --yes is given.",
+		Args:              cobra.ExactArgs(1),
+		PersistentPreRunE: checkIfCollab,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			rn := strings.TrimSuffix(args[0], ".git")
+
+			if base == "" {
+				rr, err := be.Repository(ctx, rn)
+				if err != nil {
+					return err
+				}
+
+				r, err := rr.Open()
+				if err != nil {
+					return err
+				}
+
+				head, err := r.HEAD()
+				if err != nil {
+					return err
+				}
+
+				base = head.Name().Short()
+			}
+
+			branches, err := be.MergedBranches(ctx, rn, base)
+			if err != nil {
+				return err
+			}
+
+			var prune []backend.MergedBranch
+			for _, b := range branches {
+				if b.Protected {
+					cmd.Printf("Skipping protected branch %s\n", b.Name)
+					continue
+				}
+
+				prune = append(prune, b)
+			}
+
+			if len(prune) == 0 {
+				cmd.Printf("No branches merged into %s to prune\n", base)
+				return nil
+			}
+
+			for _, b := range prune {
+				cmd.Printf("%s\t%s\n", b.Name, b.Commit[:7])
+			}
+
+			if dryRun {
+				return nil
+			}
+
+			if !yes {
+				cmd.Printf("Delete %d branch(es) merged into %s? [y/N] ", len(prune), base)
+				answer, _ := bufio.NewReader(cmd.InOrStdin()).ReadString('\n')
+				if a := strings.ToLower(strings.TrimSpace(answer)); a != "y" && a != "yes" {
+					cmd.Println("Aborted.")
+					return nil
+				}
+			}
+
+			user := proto.UserFromContext(ctx)
+			var failed int
+			for _, b := range prune {
+				if err := be.PruneBranch(ctx, rn, user, base, b); err != nil {
+					cmd.PrintErrf("failed to delete %s: %v\n", b.Name, err)
+					failed++
+					continue
+				}
+
+				cmd.Printf("Deleted branch %s\n", b.Name)
+			}
+
+			if failed > 0 {
+				return fmt.Errorf("failed to delete %d branch(es)", failed)
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&base, "base", "", "base branch, defaults to the default branch")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "only list the branches that would be deleted")
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "don't ask for confirmation")
+
+	return cmd
+}
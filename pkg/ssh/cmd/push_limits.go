@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"github.com/charmbracelet/soft-serve/pkg/backend"
+	"github.com/spf13/cobra"
+)
+
+func pushLimitsCommand() *cobra.Command {
+	var commits, refs int
+	var warnOnly bool
+
+	cmd := &cobra.Command{
+		Use:   "push-limits REPOSITORY",
+		Short: "Show or set the repository push limits",
+		Long:  "Show or set the maximum number of new commits and updated refs in a single push. A limit of 0 means no limit. With --warn-only, pushes exceeding the limits are allowed with a warning.",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			repo := args[0]
+
+			flags := cmd.Flags()
+			if !flags.Changed("commits") && !flags.Changed("refs") && !flags.Changed("warn-only") {
+				if err := checkIfReadable(cmd, args); err != nil {
+					return err
+				}
+
+				l, err := be.PushLimits(ctx, repo)
+				if err != nil {
+					return err
+				}
+
+				mode := "enforce"
+				if l.WarnOnly {
+					mode = "warn"
+				}
+
+				cmd.Printf("commits\t%d\n", l.MaxCommits)
+				cmd.Printf("refs\t%d\n", l.MaxRefs)
+				cmd.Printf("mode\t%s\n", mode)
+				return nil
+			}
+
+			if err := checkIfAdmin(cmd, args); err != nil {
+				return err
+			}
+
+			l, err := be.PushLimits(ctx, repo)
+			if err != nil {
+				return err
+			}
+
+			if flags.Changed("commits") {
+				l.MaxCommits = commits
+			}
+			if flags.Changed("refs") {
+				l.MaxRefs = refs
+			}
+			if flags.Changed("warn-only") {
+				l.WarnOnly = warnOnly
+			}
+
+			return be.SetPushLimits(ctx, repo, l)
+		},
+	}
+
+	cmd.Flags().IntVar(&commits, "commits", 0, "maximum number of new commits per push")
+	cmd.Flags().IntVar(&refs, "refs", 0, "maximum number of updated refs per push")
+	cmd.Flags().BoolVar(&warnOnly, "warn-only", false, "warn instead of rejecting pushes exceeding the limits")
+
+	return cmd
+}
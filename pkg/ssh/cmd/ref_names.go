@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"github.com/charmbracelet/soft-serve/pkg/backend"
+	"github.com/spf13/cobra"
+)
+
+func refNamesCommand() *cobra.Command {
+	var maxLength int
+	var pattern string
+	var clear bool
+
+	cmd := &cobra.Command{
+		Use:   "ref-names REPOSITORY",
+		Short: "Show or set the repository ref name rules",
+		Long:  "Show or set the rules names of new refs must follow, on top of git's own validation. The maximum length is in bytes of the full ref name, like refs/heads/main, and 0 means no limit. The pattern is a regular expression full ref names must match entirely, and --clear removes both rules. Existing refs can still be updated and deleted.",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			repo := args[0]
+
+			flags := cmd.Flags()
+			if !flags.Changed("max-length") && !flags.Changed("pattern") && !clear {
+				if err := checkIfReadable(cmd, args); err != nil {
+					return err
+				}
+
+				r, err := be.RefNameRules(ctx, repo)
+				if err != nil {
+					return err
+				}
+
+				cmd.Printf("max-length\t%d\n", r.MaxLength)
+				cmd.Printf("pattern\t%s\n", r.Pattern)
+				return nil
+			}
+
+			if err := checkIfAdmin(cmd, args); err != nil {
+				return err
+			}
+
+			if clear {
+				return be.SetRefNameRules(ctx, repo, backend.RefNameRules{})
+			}
+
+			r, err := be.RefNameRules(ctx, repo)
+			if err != nil {
+				return err
+			}
+
+			if flags.Changed("max-length") {
+				r.MaxLength = maxLength
+			}
+			if flags.Changed("pattern") {
+				r.Pattern = pattern
+			}
+
+			return be.SetRefNameRules(ctx, repo, r)
+		},
+	}
+
+	cmd.Flags().IntVar(&maxLength, "max-length", 0, "maximum length of new ref names in bytes")
+	cmd.Flags().StringVar(&pattern, "pattern", "", "regular expression new ref names must match")
+	cmd.Flags().BoolVar(&clear, "clear", false, "remove the ref name rules")
+
+	return cmd
+}
@@ -0,0 +1,281 @@
+package cmd
+
+import (
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/caarlos0/tablewriter"
+	"github.com/charmbracelet/soft-serve/pkg/access"
+	"github.com/charmbracelet/soft-serve/pkg/backend"
+	"github.com/charmbracelet/soft-serve/pkg/proto"
+	"github.com/dustin/go-humanize"
+	"github.com/spf13/cobra"
+)
+
+func releaseCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "release",
+		Aliases: []string{"releases"},
+		Short:   "Manage the releases of a repo",
+		Long:    "Manage the releases of a repo. A release attaches a title, notes, and files, like built binaries, to a tag. Release assets can be downloaded over SSH, or over HTTP with the API.",
+	}
+
+	cmd.AddCommand(
+		releaseCreateCommand(),
+		releaseDeleteAssetCommand(),
+		releaseDeleteCommand(),
+		releaseDownloadCommand(),
+		releaseEditCommand(),
+		releaseListCommand(),
+		releaseShowCommand(),
+		releaseUploadCommand(),
+	)
+
+	return cmd
+}
+
+func releaseCreateCommand() *cobra.Command {
+	var title string
+	var notes string
+
+	cmd := &cobra.Command{
+		Use:               "create REPOSITORY TAG",
+		Short:             "Create a release for a tag",
+		Long:              "Create a release for an existing tag. The title defaults to the tag.",
+		Args:              cobra.ExactArgs(2),
+		PersistentPreRunE: checkCapability(access.CapabilityManageReleases),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			user := proto.UserFromContext(ctx)
+			if _, err := be.CreateRelease(ctx, args[0], user, args[1], title, notes); err != nil {
+				return err
+			}
+
+			cmd.PrintErrln("Release created")
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&title, "title", "t", "", "title of the release")
+	cmd.Flags().StringVarP(&notes, "notes", "n", "", "release notes")
+
+	return cmd
+}
+
+func releaseEditCommand() *cobra.Command {
+	var title string
+	var notes string
+
+	cmd := &cobra.Command{
+		Use:               "edit REPOSITORY TAG",
+		Short:             "Edit the title or the notes of a release",
+		Args:              cobra.ExactArgs(2),
+		PersistentPreRunE: checkCapability(access.CapabilityManageReleases),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			rel, err := be.Release(ctx, args[0], args[1])
+			if err != nil {
+				return err
+			}
+
+			if cmd.Flags().Changed("title") {
+				rel.Title = title
+			}
+			if cmd.Flags().Changed("notes") {
+				rel.Notes = notes
+			}
+
+			return be.EditRelease(ctx, args[0], args[1], rel.Title, rel.Notes)
+		},
+	}
+
+	cmd.Flags().StringVarP(&title, "title", "t", "", "title of the release, empty to reset it to the tag")
+	cmd.Flags().StringVarP(&notes, "notes", "n", "", "release notes")
+
+	return cmd
+}
+
+func releaseListCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "list REPOSITORY",
+		Aliases:           []string{"ls"},
+		Short:             "List the releases of a repo",
+		Args:              cobra.ExactArgs(1),
+		PersistentPreRunE: checkIfReadable,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			releases, err := be.Releases(ctx, args[0])
+			if err != nil {
+				return err
+			}
+
+			if len(releases) == 0 {
+				cmd.Println("No releases found")
+				return nil
+			}
+
+			return tablewriter.Render(
+				cmd.OutOrStdout(),
+				releases,
+				[]string{"Tag", "Title", "Assets", "Created By", "Created At"},
+				func(r backend.Release) ([]string, error) {
+					by := r.Username
+					if by == "" {
+						by = "-"
+					}
+
+					return []string{
+						r.Tag,
+						r.Title,
+						strconv.Itoa(len(r.Assets)),
+						by,
+						humanize.Time(r.CreatedAt),
+					}, nil
+				},
+			)
+		},
+	}
+
+	return cmd
+}
+
+func releaseShowCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "show REPOSITORY TAG",
+		Aliases:           []string{"info"},
+		Short:             "Show a release and its assets",
+		Args:              cobra.ExactArgs(2),
+		PersistentPreRunE: checkIfReadable,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			rel, err := be.Release(ctx, args[0], args[1])
+			if err != nil {
+				return err
+			}
+
+			cmd.Println("Title:", rel.Title)
+			cmd.Println("Tag:", rel.Tag)
+			if rel.Username != "" {
+				cmd.Println("Created By:", rel.Username)
+			}
+			cmd.Println("Created At:", humanize.Time(rel.CreatedAt))
+			if notes := strings.TrimSpace(rel.Notes); notes != "" {
+				cmd.Println()
+				cmd.Println(notes)
+			}
+			if len(rel.Assets) > 0 {
+				cmd.Println()
+				cmd.Println("Assets:")
+				for _, a := range rel.Assets {
+					cmd.Printf("  %s\t%s\tsha256:%s\n", a.Name, humanize.IBytes(uint64(a.Size)), a.SHA256)
+					if url := be.ReleaseAssetURL(args[0], rel.Tag, a.Name); url != "" {
+						cmd.Printf("    %s\n", url)
+					}
+				}
+			}
+
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+func releaseDeleteCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "delete REPOSITORY TAG",
+		Aliases:           []string{"rm", "remove"},
+		Short:             "Delete a release and its assets",
+		Long:              "Delete a release and its assets. The tag is kept.",
+		Args:              cobra.ExactArgs(2),
+		PersistentPreRunE: checkCapability(access.CapabilityManageReleases),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			if err := be.DeleteRelease(ctx, args[0], args[1]); err != nil {
+				return err
+			}
+
+			cmd.PrintErrln("Release deleted")
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+func releaseUploadCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "upload REPOSITORY TAG NAME",
+		Short: "Upload an asset to a release, reading it from stdin",
+		Long: "Upload an asset to a release, reading its content from stdin, e.g.\n\n" +
+			"  ssh soft repo release upload app v1.0.0 app_linux_amd64.tar.gz < app_linux_amd64.tar.gz\n\n" +
+			"An asset with the same name is replaced.",
+		Args:              cobra.ExactArgs(3),
+		PersistentPreRunE: checkCapability(access.CapabilityManageReleases),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			a, err := be.UploadReleaseAsset(ctx, args[0], args[1], args[2], cmd.InOrStdin())
+			if err != nil {
+				return err
+			}
+
+			cmd.PrintErrf("Uploaded %s (%s)\n", a.Name, humanize.IBytes(uint64(a.Size)))
+			cmd.Printf("sha256:%s\n", a.SHA256)
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+func releaseDownloadCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "download REPOSITORY TAG NAME",
+		Short:             "Write an asset of a release to stdout",
+		Args:              cobra.ExactArgs(3),
+		PersistentPreRunE: checkIfReadable,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			obj, _, err := be.OpenReleaseAsset(ctx, args[0], args[1], args[2])
+			if err != nil {
+				return err
+			}
+			defer obj.Close() // nolint: errcheck
+
+			_, err = io.Copy(cmd.OutOrStdout(), obj)
+			return err
+		},
+	}
+
+	return cmd
+}
+
+func releaseDeleteAssetCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "delete-asset REPOSITORY TAG NAME",
+		Aliases:           []string{"rm-asset", "remove-asset"},
+		Short:             "Delete an asset of a release",
+		Args:              cobra.ExactArgs(3),
+		PersistentPreRunE: checkCapability(access.CapabilityManageReleases),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			if err := be.DeleteReleaseAsset(ctx, args[0], args[1], args[2]); err != nil {
+				return err
+			}
+
+			cmd.PrintErrln("Release asset deleted")
+			return nil
+		},
+	}
+
+	return cmd
+}
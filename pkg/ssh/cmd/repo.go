@@ -19,21 +19,56 @@ func RepoCommand(renderer *lipgloss.Renderer) *cobra.Command {
 	}
 
 	cmd.AddCommand(
+		aliasCommand(),
+		binaryLimitCommand(),
 		blobCommand(renderer),
 		branchCommand(),
+		cloneInstructionsCommand(),
+		cloneProtocolCommand(),
+		cloneTrackingCommand(),
+		clonesCommand(),
 		collabCommand(),
 		commitCommand(renderer),
 		createCommand(),
+		daemonExportCommand(),
+		defaultVisibilityCommand(),
 		deleteCommand(),
+		deniedPathsCommand(),
+		deployTokenCommand(),
 		descriptionCommand(),
+		fileModesCommand(),
+		forcePushesCommand(),
+		freezeWindowCommand(),
 		hiddenCommand(),
 		importCommand(),
+		importRemoteCommand(),
+		issueCommand(),
+		linearHistoryCommand(),
 		listCommand(),
+		locksCommand(),
+		mergeCommand(),
 		mirrorCommand(),
+		mirrorSyncCommand(),
+		notesAccessCommand(),
+		packCommand(),
 		privateCommand(),
 		projectName(),
+		protocolsCommand(),
+		pruneBranchesCommand(),
+		pushCertsCommand(),
+		pushLimitsCommand(),
+		pushMirrorCommand(),
+		refNamesCommand(),
+		releaseCommand(),
+		repoKeyCommand(),
 		renameCommand(),
+		requiredStatusesCommand(),
+		reviewCommand(),
+		signedPushCommand(),
+		statsCommand(),
+		statusesCommand(),
 		tagCommand(),
+		tagProtectionCommand(),
 		treeCommand(),
 		webhookCommand(),
 	)
@@ -71,6 +106,11 @@ func RepoCommand(renderer *lipgloss.Renderer) *cobra.Command {
 					}
 				}
 
+				format, err := be.ObjectFormat(ctx, rn)
+				if err != nil {
+					return err
+				}
+
 				branches, _ := r.Branches()
 				tags, _ := r.Tags()
 
@@ -85,6 +125,7 @@ func RepoCommand(renderer *lipgloss.Renderer) *cobra.Command {
 				if owner != nil {
 					cmd.Println(strings.TrimSpace(fmt.Sprint("Owner: ", owner.Username())))
 				}
+				cmd.Println("Object Format:", format)
 				cmd.Println("Default Branch:", head.Name().Short())
 				if len(branches) > 0 {
 					cmd.Println("Branches:")
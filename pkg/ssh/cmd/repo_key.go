@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/soft-serve/pkg/access"
+	"github.com/charmbracelet/soft-serve/pkg/backend"
+	"github.com/charmbracelet/soft-serve/pkg/sshutils"
+	"github.com/spf13/cobra"
+)
+
+func repoKeyCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "key",
+		Aliases: []string{"keys"},
+		Short:   "Manage the keys granted access to a repo",
+		Long:    "Manage the SSH public keys granted access to a single repo. Keys don't need to belong to a user, and have the access even without any access to the rest of the server. Keys of users also grant the access to these users over HTTP.",
+	}
+
+	cmd.AddCommand(
+		repoKeyGrantCommand(),
+		repoKeyRemoveCommand(),
+		repoKeyListCommand(),
+	)
+
+	return cmd
+}
+
+func repoKeyGrantCommand() *cobra.Command {
+	var level string
+
+	cmd := &cobra.Command{
+		Use:               "grant REPOSITORY AUTHORIZED_KEY",
+		Short:             "Grant a key access to a repo",
+		Long:              "Grant a key access to a repo, replacing the access it already has. LEVEL can be one of: read-only, read-write, or admin-access. Defaults to read-only.",
+		Args:              cobra.MinimumNArgs(2),
+		PersistentPreRunE: checkIfAdmin,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			pk, _, err := sshutils.ParseAuthorizedKey(strings.Join(args[1:], " "))
+			if err != nil {
+				return err
+			}
+
+			al := access.ParseAccessLevel(level)
+			if al < 0 {
+				return access.ErrInvalidAccessLevel
+			}
+
+			return be.GrantRepoKey(ctx, args[0], pk, al)
+		},
+	}
+
+	cmd.Flags().StringVarP(&level, "level", "l", access.ReadOnlyAccess.String(), "access level of the key")
+
+	return cmd
+}
+
+func repoKeyRemoveCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "remove REPOSITORY AUTHORIZED_KEY",
+		Short:             "Remove the access of a key to a repo",
+		Args:              cobra.MinimumNArgs(2),
+		PersistentPreRunE: checkIfAdmin,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			pk, _, err := sshutils.ParseAuthorizedKey(strings.Join(args[1:], " "))
+			if err != nil {
+				return err
+			}
+
+			return be.RemoveRepoKey(ctx, args[0], pk)
+		},
+	}
+
+	return cmd
+}
+
+func repoKeyListCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "list REPOSITORY",
+		Short:             "List the keys granted access to a repo",
+		Args:              cobra.ExactArgs(1),
+		PersistentPreRunE: checkIfAdmin,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			keys, err := be.RepoKeys(ctx, args[0])
+			if err != nil {
+				return err
+			}
+
+			for _, k := range keys {
+				cmd.Printf("%s\t%s\t%s\n", k.AccessLevel, sshutils.KeyFingerprint(k.PublicKey), sshutils.MarshalAuthorizedKey(k.PublicKey))
+			}
+
+			return nil
+		},
+	}
+
+	return cmd
+}
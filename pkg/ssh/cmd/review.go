@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"strconv"
+
+	"github.com/caarlos0/tablewriter"
+	"github.com/charmbracelet/soft-serve/pkg/backend"
+	"github.com/charmbracelet/soft-serve/pkg/config"
+	"github.com/dustin/go-humanize"
+	"github.com/spf13/cobra"
+)
+
+func reviewCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "review",
+		Aliases: []string{"reviews"},
+		Short:   "Manage the changes proposed for review",
+		Long:    "Manage the changes proposed for review. Pushing to refs/for/BRANCH, or the configured review ref prefix, proposes a change to BRANCH instead of updating it.",
+	}
+
+	cmd.AddCommand(
+		reviewListCommand(),
+	)
+
+	return cmd
+}
+
+func reviewListCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "list REPOSITORY",
+		Aliases:           []string{"ls"},
+		Short:             "List the changes proposed for review",
+		Args:              cobra.ExactArgs(1),
+		PersistentPreRunE: checkIfReadable,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			reviews, err := be.Reviews(ctx, args[0])
+			if err != nil {
+				return err
+			}
+
+			if len(reviews) == 0 {
+				cmd.Println("No reviews found")
+				if cfg := config.FromContext(ctx); cfg.Review.RefPrefix != "" {
+					cmd.Printf("Push to %sBRANCH to propose a change\n", cfg.Review.RefPrefix)
+				}
+				return nil
+			}
+
+			return tablewriter.Render(
+				cmd.OutOrStdout(),
+				reviews,
+				[]string{"Number", "Branch", "Commit", "Ref", "Pushed By", "Pushed At"},
+				func(r backend.Review) ([]string, error) {
+					by := r.Username
+					if by == "" {
+						by = "-"
+					}
+
+					return []string{
+						"#" + strconv.FormatInt(r.Number, 10),
+						r.TargetBranch,
+						r.CommitSHA[:7],
+						r.Ref,
+						by,
+						humanize.Time(r.CreatedAt),
+					}, nil
+				},
+			)
+		},
+	}
+
+	return cmd
+}
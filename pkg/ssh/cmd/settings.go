@@ -40,7 +40,7 @@ func SettingsCommand() *cobra.Command {
 		},
 	)
 
-	als := []string{access.NoAccess.String(), access.ReadOnlyAccess.String(), access.ReadWriteAccess.String(), access.AdminAccess.String()}
+	als := []string{access.NoAccess.String(), access.ReadOnlyAccess.String(), access.TriageAccess.String(), access.ReadWriteAccess.String(), access.AdminAccess.String()}
 	cmd.AddCommand(
 		&cobra.Command{
 			Use:               "anon-access [ACCESS_LEVEL]",
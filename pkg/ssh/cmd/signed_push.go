@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/soft-serve/pkg/backend"
+	"github.com/dustin/go-humanize"
+	"github.com/spf13/cobra"
+)
+
+func signedPushCommand() *cobra.Command {
+	var clear bool
+
+	cmd := &cobra.Command{
+		Use:   "signed-push REPOSITORY [BRANCH...]",
+		Short: "Show or set the branches requiring signed pushes",
+		Long:  "Show or set the branch name patterns that require pushes signed with `git push --signed`. Patterns use shell glob syntax, e.g. `release/*`.",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			repo := args[0]
+
+			if len(args) == 1 && !clear {
+				if err := checkIfReadable(cmd, args); err != nil {
+					return err
+				}
+
+				patterns, err := be.SignedPushBranches(ctx, repo)
+				if err != nil {
+					return err
+				}
+
+				for _, p := range patterns {
+					cmd.Println(p)
+				}
+
+				return nil
+			}
+
+			if err := checkIfAdmin(cmd, args); err != nil {
+				return err
+			}
+
+			return be.SetSignedPushBranches(ctx, repo, args[1:])
+		},
+	}
+
+	cmd.Flags().BoolVar(&clear, "clear", false, "don't require signed pushes on any branch")
+
+	return cmd
+}
+
+func pushCertsCommand() *cobra.Command {
+	var limit int
+
+	cmd := &cobra.Command{
+		Use:               "push-certs REPOSITORY",
+		Short:             "Show the verified push certificates of a repository",
+		Args:              cobra.ExactArgs(1),
+		PersistentPreRunE: checkIfAdmin,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			repo := args[0]
+
+			events, err := be.AuditEvents(ctx, repo, backend.AuditActionPushCertificate, limit)
+			if err != nil {
+				return err
+			}
+
+			for i, e := range events {
+				if i > 0 {
+					cmd.Println()
+				}
+
+				username := "deleted user"
+				if e.Username.Valid {
+					username = e.Username.String
+				}
+
+				cmd.Printf("Signed by %s %s\n\n", username, humanize.Time(e.CreatedAt))
+				cmd.Println(strings.TrimSpace(e.Details))
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVarP(&limit, "limit", "n", 10, "number of push certificates to show")
+
+	return cmd
+}
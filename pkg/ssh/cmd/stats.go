@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/charmbracelet/soft-serve/pkg/backend"
+	"github.com/dustin/go-humanize"
+	"github.com/spf13/cobra"
+)
+
+func statsCommand() *cobra.Command {
+	var asJSON bool
+
+	cmd := &cobra.Command{
+		Use:               "stats REPOSITORY",
+		Short:             "Show repository statistics",
+		Long:              "Show the commit and contributor counts of the default branch, the branch and tag counts, the size on disk, the commit-graph state, the largest files, and the first and last commit dates of a repository.",
+		Args:              cobra.ExactArgs(1),
+		PersistentPreRunE: checkIfReadable,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			repo := args[0]
+
+			stats, err := be.RepoStats(ctx, repo)
+			if err != nil {
+				return err
+			}
+
+			if asJSON {
+				enc := json.NewEncoder(cmd.OutOrStdout())
+				enc.SetIndent("", "  ")
+				return enc.Encode(stats)
+			}
+
+			cmd.Printf("Commits: %d\n", stats.Commits)
+			cmd.Printf("Contributors: %d\n", stats.Contributors)
+			cmd.Printf("Branches: %d\n", stats.Branches)
+			cmd.Printf("Tags: %d\n", stats.Tags)
+			cmd.Printf("Size: %s\n", humanize.Bytes(uint64(stats.Size)))
+			cmd.Printf("Commit graph: %s\n", stats.CommitGraph)
+			if stats.FirstCommit != nil {
+				cmd.Printf("First commit: %s\n", stats.FirstCommit.Format(time.RFC3339))
+			}
+			if stats.LastCommit != nil {
+				cmd.Printf("Last commit: %s\n", stats.LastCommit.Format(time.RFC3339))
+			}
+			if len(stats.LargestFiles) > 0 {
+				cmd.Println("Largest files:")
+				for _, f := range stats.LargestFiles {
+					cmd.Printf("  %s\t%s\n", humanize.Bytes(uint64(f.Size)), f.Path)
+				}
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&asJSON, "json", false, "output the statistics as JSON")
+
+	return cmd
+}
@@ -69,6 +69,12 @@ func tagDeleteCommand() *cobra.Command {
 			ctx := cmd.Context()
 			be := backend.FromContext(ctx)
 			rn := strings.TrimSuffix(args[0], ".git")
+			unlock, err := be.RLockRepository(ctx, rn, "tag-delete")
+			if err != nil {
+				return err
+			}
+			defer unlock()
+
 			rr, err := be.Repository(ctx, rn)
 			if err != nil {
 				return err
@@ -95,18 +101,22 @@ func tagDeleteCommand() *cobra.Command {
 				return git.ErrReferenceNotExist
 			}
 
-			tagCommit, err := r.TagCommit(tag)
+			tagCommit, err := r.RevParse(git.RefsTags + tag + "^{commit}")
 			if err != nil {
 				log.Errorf("failed to get tag commit: %s", err)
 				return err
 			}
 
+			if err := be.CheckTagDeletion(ctx, cmd.ErrOrStderr(), rr.Name(), tag, tagCommit); err != nil {
+				return err
+			}
+
 			if err := r.DeleteTag(tag); err != nil {
 				log.Errorf("failed to delete tag: %s", err)
 				return err
 			}
 
-			wh, err := webhook.NewBranchTagEvent(ctx, proto.UserFromContext(ctx), rr, git.RefsTags+tag, tagCommit.ID.String(), git.ZeroID)
+			wh, err := webhook.NewBranchTagEvent(ctx, proto.UserFromContext(ctx), rr, git.RefsTags+tag, tagCommit, git.ZeroIDOf(tagCommit))
 			if err != nil {
 				log.Error("failed to create branch_tag webhook", "err", err)
 				return err
@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/soft-serve/pkg/backend"
+	"github.com/dustin/go-humanize"
+	"github.com/spf13/cobra"
+)
+
+func tagProtectionCommand() *cobra.Command {
+	var clear, warnOnly, attempts bool
+	var limit int
+
+	cmd := &cobra.Command{
+		Use:   "tag-protection REPOSITORY [TAG...]",
+		Short: "Show or set the protected tags",
+		Long:  "Show or set the tag name patterns that can be created but not moved or deleted, except by admins. Patterns use shell glob syntax, e.g. `v*`. With --warn-only, pushes changing protected tags are allowed with a warning. Every attempt is recorded in the audit log, use --attempts to show the latest ones.",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			repo := args[0]
+
+			if attempts {
+				if err := checkIfAdmin(cmd, args); err != nil {
+					return err
+				}
+
+				events, err := be.AuditEvents(ctx, repo, backend.AuditActionProtectedTag, limit)
+				if err != nil {
+					return err
+				}
+
+				for _, e := range events {
+					username := "anonymous"
+					if e.Username.Valid {
+						username = e.Username.String
+					}
+
+					cmd.Printf("%s\t%s\t%s\n", humanize.Time(e.CreatedAt), username, e.Details)
+				}
+
+				return nil
+			}
+
+			flags := cmd.Flags()
+			if len(args) == 1 && !clear && !flags.Changed("warn-only") {
+				if err := checkIfReadable(cmd, args); err != nil {
+					return err
+				}
+
+				t, err := be.TagProtection(ctx, repo)
+				if err != nil {
+					return err
+				}
+
+				mode := "enforce"
+				if t.WarnOnly {
+					mode = "warn"
+				}
+
+				cmd.Printf("tags\t%s\n", strings.Join(t.Tags, ","))
+				cmd.Printf("mode\t%s\n", mode)
+				return nil
+			}
+
+			if err := checkIfAdmin(cmd, args); err != nil {
+				return err
+			}
+
+			t, err := be.TagProtection(ctx, repo)
+			if err != nil {
+				return err
+			}
+
+			switch {
+			case clear:
+				t.Tags = nil
+			case len(args) > 1:
+				t.Tags = args[1:]
+			}
+			if flags.Changed("warn-only") {
+				t.WarnOnly = warnOnly
+			}
+
+			return be.SetTagProtection(ctx, repo, t)
+		},
+	}
+
+	cmd.Flags().BoolVar(&clear, "clear", false, "don't protect any tag")
+	cmd.Flags().BoolVar(&warnOnly, "warn-only", false, "warn instead of rejecting pushes changing protected tags")
+	cmd.Flags().BoolVar(&attempts, "attempts", false, "show the latest attempts to move or delete protected tags")
+	cmd.Flags().IntVarP(&limit, "limit", "n", 10, "number of attempts to show")
+
+	return cmd
+}
@@ -0,0 +1,189 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/caarlos0/tablewriter"
+	"github.com/charmbracelet/soft-serve/pkg/backend"
+	"github.com/charmbracelet/soft-serve/pkg/db/models"
+	"github.com/charmbracelet/soft-serve/pkg/proto"
+	"github.com/spf13/cobra"
+)
+
+// TermsCommand returns a command that shows, accepts, and manages the server
+// terms users must accept before pushing.
+func TermsCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "terms",
+		Short: "Show the terms you must accept before pushing",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+
+			t, err := be.Terms(ctx)
+			if err != nil {
+				return err
+			}
+
+			if t.Version == "" {
+				cmd.Println("No terms to accept")
+				return nil
+			}
+
+			cmd.Printf("Version: %s\n\n", t.Version)
+			cmd.Println(strings.TrimRight(t.Text, "\n"))
+			cmd.Println()
+
+			user := proto.UserFromContext(ctx)
+			at, err := be.TermsAcceptedAt(ctx, user, t.Version)
+			if err != nil {
+				return err
+			}
+
+			if at.IsZero() {
+				cmd.Printf("Not accepted, run `terms accept %s` to accept them\n", t.Version)
+			} else {
+				cmd.Printf("Accepted on %s\n", at.UTC().Format("2006-01-02 15:04:05 MST"))
+			}
+
+			return nil
+		},
+	}
+
+	acceptCmd := &cobra.Command{
+		Use:   "accept VERSION",
+		Short: "Accept the current terms",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+
+			user := proto.UserFromContext(ctx)
+			if user == nil {
+				return proto.ErrUserNotFound
+			}
+
+			if err := be.AcceptTerms(ctx, user, args[0]); err != nil {
+				return err
+			}
+
+			cmd.PrintErrf("Accepted terms version %s\n", args[0])
+			return nil
+		},
+	}
+
+	setCmd := &cobra.Command{
+		Use:   "set VERSION [TEXT...]",
+		Short: "Set the terms, reading the text from stdin if not given",
+		Long: "Set the terms users must accept before pushing.\n\n" +
+			"Every new version has to be accepted again, even if the text is the same.",
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := checkIfAdmin(cmd, nil); err != nil {
+				return err
+			}
+
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+
+			t := backend.Terms{Version: args[0]}
+			if len(args) > 1 {
+				t.Text = strings.Join(args[1:], " ")
+			} else {
+				text, err := io.ReadAll(cmd.InOrStdin())
+				if err != nil {
+					return err
+				}
+				t.Text = string(text)
+			}
+
+			if strings.TrimSpace(t.Version) == "" {
+				return fmt.Errorf("terms version is empty, use `terms clear` to remove the terms")
+			}
+
+			return be.SetTerms(ctx, t)
+		},
+	}
+
+	clearCmd := &cobra.Command{
+		Use:   "clear",
+		Short: "Remove the terms, allowing pushes without accepting them",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if err := checkIfAdmin(cmd, nil); err != nil {
+				return err
+			}
+
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+
+			return be.SetTerms(ctx, backend.Terms{})
+		},
+	}
+
+	acceptancesCmd := &cobra.Command{
+		Use:     "acceptances [VERSION]",
+		Aliases: []string{"accepted"},
+		Short:   "List the users who accepted the terms, the current version by default",
+		Args:    cobra.RangeArgs(0, 1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := checkIfAdmin(cmd, nil); err != nil {
+				return err
+			}
+
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+
+			var version string
+			if len(args) > 0 {
+				version = args[0]
+			} else {
+				t, err := be.Terms(ctx)
+				if err != nil {
+					return err
+				}
+				version = t.Version
+			}
+
+			if version == "" {
+				cmd.Println("No terms to accept")
+				return nil
+			}
+
+			as, err := be.TermsAcceptances(ctx, version)
+			if err != nil {
+				return err
+			}
+
+			if len(as) == 0 {
+				cmd.Printf("No acceptances of terms version %s\n", version)
+				return nil
+			}
+
+			return tablewriter.Render(
+				cmd.OutOrStdout(),
+				as,
+				[]string{"Username", "Version", "Accepted At"},
+				func(a models.TermsAcceptance) ([]string, error) {
+					return []string{
+						a.Username,
+						a.Version,
+						a.CreatedAt.UTC().Format("2006-01-02 15:04:05 MST"),
+					}, nil
+				},
+			)
+		},
+	}
+
+	cmd.AddCommand(
+		acceptCmd,
+		setCmd,
+		clearCmd,
+		acceptancesCmd,
+	)
+
+	return cmd
+}
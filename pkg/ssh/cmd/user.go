@@ -161,6 +161,9 @@ func UserCommand() *cobra.Command {
 
 			cmd.Printf("Username: %s\n", user.Username())
 			cmd.Printf("Admin: %t\n", isAdmin)
+			if usage, err := be.DiskUsage(ctx, user); err == nil && usage.Quota > 0 {
+				cmd.Printf("Disk usage: %s\n", usage)
+			}
 			cmd.Printf("Public keys:\n")
 			for _, pk := range user.PublicKeys() {
 				cmd.Printf("  %s\n", sshutils.MarshalAuthorizedKey(pk))
@@ -189,6 +192,7 @@ func UserCommand() *cobra.Command {
 		userCreateCommand,
 		userAddPubkeyCommand,
 		userInfoCommand,
+		userInviteCommand(),
 		userListCommand,
 		userDeleteCommand,
 		userRemovePubkeyCommand,
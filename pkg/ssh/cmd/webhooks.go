@@ -2,11 +2,14 @@ package cmd
 
 import (
 	"fmt"
+	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/caarlos0/tablewriter"
 	"github.com/charmbracelet/soft-serve/pkg/backend"
+	"github.com/charmbracelet/soft-serve/pkg/db/models"
 	"github.com/charmbracelet/soft-serve/pkg/webhook"
 	"github.com/dustin/go-humanize"
 	"github.com/google/uuid"
@@ -25,6 +28,7 @@ func webhookCommand() *cobra.Command {
 		webhookCreateCommand(),
 		webhookDeleteCommand(),
 		webhookUpdateCommand(),
+		webhookTestCommand(),
 		webhookDeliveriesCommand(),
 	)
 
@@ -63,7 +67,7 @@ func webhookListCommand() *cobra.Command {
 			return tablewriter.Render(
 				cmd.OutOrStdout(),
 				webhooks,
-				[]string{"ID", "URL", "Events", "Active", "Created At", "Updated At"},
+				[]string{"ID", "URL", "Events", "Refs", "Active", "Last Delivery", "Created At", "Updated At"},
 				func(h webhook.Hook) ([]string, error) {
 					events := make([]string, len(h.Events))
 					for i, e := range h.Events {
@@ -74,7 +78,9 @@ func webhookListCommand() *cobra.Command {
 						strconv.FormatInt(h.ID, 10),
 						h.URL,
 						strings.Join(events, ","),
+						refsString(h),
 						strconv.FormatBool(h.Active),
+						lastDeliveryString(h.LastDelivery),
 						humanize.Time(h.CreatedAt),
 						humanize.Time(h.UpdatedAt),
 					}
@@ -90,6 +96,8 @@ func webhookListCommand() *cobra.Command {
 
 func webhookCreateCommand() *cobra.Command {
 	var events []string
+	var refs []string
+	var allRefs bool
 	var secret string
 	var active bool
 	var contentType string
@@ -126,11 +134,13 @@ func webhookCreateCommand() *cobra.Command {
 				return webhook.ErrInvalidContentType
 			}
 
-			return be.CreateWebhook(ctx, repo, strings.TrimSpace(args[1]), ct, secret, evs, active)
+			return be.CreateWebhook(ctx, repo, strings.TrimSpace(args[1]), ct, secret, evs, refs, allRefs, active)
 		},
 	}
 
 	cmd.Flags().StringSliceVarP(&events, "events", "e", nil, fmt.Sprintf("events to trigger the webhook, available events are (%s)", strings.Join(webhookEvents, ", ")))
+	cmd.Flags().StringSliceVarP(&refs, "refs", "r", nil, "only send push events for refs matching these patterns, e.g. refs/heads/main,refs/tags/*")
+	cmd.Flags().BoolVar(&allRefs, "all-refs", false, "include all the updated refs in push payloads, not only the matching ones")
 	cmd.Flags().StringVarP(&secret, "secret", "s", "", "secret to sign the webhook payload")
 	cmd.Flags().BoolVarP(&active, "active", "a", true, "whether the webhook is active")
 	cmd.Flags().StringVarP(&contentType, "content-type", "c", "json", "content type of the webhook payload, can be either `json` or `form`")
@@ -166,6 +176,8 @@ func webhookDeleteCommand() *cobra.Command {
 
 func webhookUpdateCommand() *cobra.Command {
 	var events []string
+	var refs []string
+	var allRefs string
 	var secret string
 	var active string
 	var contentType string
@@ -242,11 +254,29 @@ func webhookUpdateCommand() *cobra.Command {
 				newEvents = evs
 			}
 
-			return be.UpdateWebhook(ctx, repo, id, newURL, newContentType, newSecret, newEvents, newActive)
+			// An empty --refs clears the ref patterns.
+			newRefs := wh.Refs()
+			if cmd.Flags().Changed("refs") {
+				newRefs = refs
+			}
+
+			newAllRefs := wh.AllRefs
+			if allRefs != "" {
+				allRefs, err := strconv.ParseBool(allRefs)
+				if err != nil {
+					return fmt.Errorf("invalid all-refs value: %w", err)
+				}
+
+				newAllRefs = allRefs
+			}
+
+			return be.UpdateWebhook(ctx, repo, id, newURL, newContentType, newSecret, newEvents, newRefs, newAllRefs, newActive)
 		},
 	}
 
 	cmd.Flags().StringSliceVarP(&events, "events", "e", nil, fmt.Sprintf("events to trigger the webhook, available events are (%s)", strings.Join(webhookEvents, ", ")))
+	cmd.Flags().StringSliceVarP(&refs, "refs", "r", nil, "only send push events for refs matching these patterns, empty to send them for all refs")
+	cmd.Flags().StringVar(&allRefs, "all-refs", "", "whether to include all the updated refs in push payloads, not only the matching ones")
 	cmd.Flags().StringVarP(&secret, "secret", "s", "", "secret to sign the webhook payload")
 	cmd.Flags().StringVarP(&active, "active", "a", "", "whether the webhook is active")
 	cmd.Flags().StringVarP(&contentType, "content-type", "c", "", "content type of the webhook payload, can be either `json` or `form`")
@@ -255,6 +285,97 @@ func webhookUpdateCommand() *cobra.Command {
 	return cmd
 }
 
+// refsString returns a short description of the ref patterns of a webhook.
+func refsString(h webhook.Hook) string {
+	refs := h.Refs()
+	if len(refs) == 0 {
+		return "*"
+	}
+
+	s := strings.Join(refs, ",")
+	if h.AllRefs {
+		s += " (all refs)"
+	}
+
+	return s
+}
+
+// lastDeliveryString returns a short description of the last delivery of a
+// webhook.
+func lastDeliveryString(d *models.WebhookLastDelivery) string {
+	if d == nil {
+		return "never"
+	}
+
+	status := "❌"
+	if d.ResponseStatus >= 200 && d.ResponseStatus < 300 {
+		status = "✅"
+	}
+
+	return fmt.Sprintf("%s %d %s %s", status, d.ResponseStatus,
+		time.Duration(d.LatencyMs)*time.Millisecond, humanize.Time(d.UpdatedAt))
+}
+
+func webhookTestCommand() *cobra.Command {
+	var showPayload bool
+	cmd := &cobra.Command{
+		Use:               "test REPOSITORY WEBHOOK_ID",
+		Short:             "Send a test ping event to a repository webhook",
+		Long:              "Send a test ping event to a repository webhook, even if it's inactive, and report the response status and latency.",
+		Args:              cobra.ExactArgs(2),
+		PersistentPreRunE: checkIfAdmin,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			be := backend.FromContext(ctx)
+			repo, err := be.Repository(ctx, args[0])
+			if err != nil {
+				return err
+			}
+
+			id, err := strconv.ParseInt(args[1], 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid webhook ID: %w", err)
+			}
+
+			res, err := be.TestWebhook(ctx, repo, id)
+			if err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			fmt.Fprintf(out, "Delivery: %s\n", res.ID)
+			fmt.Fprintf(out, "Event: %s\n", webhook.EventPing)
+			if res.Signature != "" {
+				fmt.Fprintf(out, "Signature: %s\n", res.Signature)
+			} else {
+				fmt.Fprintf(out, "Signature: none, the webhook has no secret\n")
+			}
+			if res.Error != nil {
+				fmt.Fprintf(out, "Error: %s\n", res.Error)
+			} else {
+				fmt.Fprintf(out, "Status: %d %s\n", res.Status, http.StatusText(res.Status))
+			}
+			fmt.Fprintf(out, "Latency: %s\n", res.Latency.Round(time.Millisecond))
+			if showPayload {
+				fmt.Fprintf(out, "Payload:\n%s", res.Body)
+			}
+
+			switch {
+			case res.Error != nil:
+				return fmt.Errorf("webhook delivery failed: %w", res.Error)
+			case res.Status < 200 || res.Status >= 300:
+				return fmt.Errorf("webhook responded with status %d", res.Status)
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVarP(&showPayload, "payload", "p", false, "print the signed payload, to check signature verification")
+
+	return cmd
+}
+
 func webhookDeliveriesCommand() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:     "deliveries",
@@ -0,0 +1,361 @@
+package ssh
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/keygen"
+	"github.com/charmbracelet/soft-serve/pkg/config"
+	"github.com/charmbracelet/ssh"
+	gossh "golang.org/x/crypto/ssh"
+)
+
+const (
+	// retiredHostKeySep separates a retired host key path from the unix time
+	// it expires at.
+	retiredHostKeySep = ".retired-"
+
+	// hostKeysRequest is the OpenSSH request used to announce all host keys
+	// to a client after authentication.
+	hostKeysRequest = "hostkeys-00@openssh.com"
+
+	// hostKeysProveRequest is the OpenSSH request a client sends to make the
+	// server prove it owns newly announced host keys.
+	hostKeysProveRequest = "hostkeys-prove-00@openssh.com"
+)
+
+// contextKeyHostKeysSent marks a connection that has been sent the host keys
+// announcement.
+var contextKeyHostKeysSent = &struct{ string }{"hostkeys-sent"}
+
+// HostKey is an SSH host key of the server.
+type HostKey struct {
+	// Path is the path to the private key file.
+	Path string
+	// Signer is the host key signer.
+	Signer gossh.Signer
+	// Expires is when a retired host key stops being accepted. It's zero for
+	// the current host key.
+	Expires time.Time
+}
+
+// Retired returns whether the host key has been replaced by a newer key.
+func (k HostKey) Retired() bool {
+	return !k.Expires.IsZero()
+}
+
+// Fingerprint returns the SHA256 fingerprint of the host key.
+func (k HostKey) Fingerprint() string {
+	return gossh.FingerprintSHA256(k.Signer.PublicKey())
+}
+
+// HostKeys returns the current host key followed by the retired host keys that
+// are still within their grace period, newest first.
+func HostKeys(cfg *config.Config) ([]HostKey, error) {
+	if cfg.SSH.KeyPath == "" {
+		return nil, config.ErrEmptySSHKeyPath
+	}
+
+	current, err := readHostKey(cfg.SSH.KeyPath)
+	if err != nil {
+		return nil, err
+	}
+
+	retired, err := retiredHostKeys(cfg.SSH.KeyPath)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := []HostKey{current}
+	now := time.Now()
+	for _, k := range retired {
+		if k.Expires.After(now) {
+			keys = append(keys, k)
+		}
+	}
+
+	return keys, nil
+}
+
+// RotateHostKey generates a new host key and retires the current one. The
+// retired key keeps being accepted for the grace period so clients have time
+// to learn the new key. A zero grace period removes the current key right
+// away. Retired keys that have expired are removed.
+func RotateHostKey(cfg *config.Config, grace time.Duration) (HostKey, error) {
+	path := cfg.SSH.KeyPath
+	if path == "" {
+		return HostKey{}, config.ErrEmptySSHKeyPath
+	}
+
+	retired, err := retiredHostKeys(path)
+	if err != nil {
+		return HostKey{}, err
+	}
+
+	now := time.Now()
+	for _, k := range retired {
+		if !k.Expires.After(now) {
+			if err := removeKeyFiles(k.Path); err != nil {
+				return HostKey{}, err
+			}
+		}
+	}
+
+	if _, err := os.Stat(path); err == nil {
+		if grace > 0 {
+			expires := now.Add(grace).Unix()
+			dst := path + retiredHostKeySep + strconv.FormatInt(expires, 10)
+			if err := os.Rename(path, dst); err != nil {
+				return HostKey{}, fmt.Errorf("retire host key: %w", err)
+			}
+			if err := os.Rename(path+".pub", dst+".pub"); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return HostKey{}, fmt.Errorf("retire host key: %w", err)
+			}
+		} else if err := removeKeyFiles(path); err != nil {
+			return HostKey{}, err
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return HostKey{}, err
+	}
+
+	kp, err := keygen.New(path, keygen.WithKeyType(keygen.Ed25519), keygen.WithWrite())
+	if err != nil {
+		return HostKey{}, fmt.Errorf("generate host key: %w", err)
+	}
+
+	return HostKey{Path: path, Signer: kp.Signer()}, nil
+}
+
+// readHostKey reads an unencrypted host key from path.
+func readHostKey(path string) (HostKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return HostKey{}, err
+	}
+
+	signer, err := gossh.ParsePrivateKey(data)
+	if err != nil {
+		return HostKey{}, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return HostKey{Path: path, Signer: signer}, nil
+}
+
+// retiredHostKeys returns all the retired host keys of path, including
+// expired ones, newest first.
+func retiredHostKeys(path string) ([]HostKey, error) {
+	matches, err := filepath.Glob(path + retiredHostKeySep + "*")
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []HostKey
+	for _, m := range matches {
+		if strings.HasSuffix(m, ".pub") {
+			continue
+		}
+
+		ts, err := strconv.ParseInt(strings.TrimPrefix(m, path+retiredHostKeySep), 10, 64)
+		if err != nil {
+			continue
+		}
+
+		k, err := readHostKey(m)
+		if err != nil {
+			return nil, err
+		}
+
+		k.Expires = time.Unix(ts, 0)
+		keys = append(keys, k)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].Expires.After(keys[j].Expires)
+	})
+
+	return keys, nil
+}
+
+// removeKeyFiles removes a private key and its public key.
+func removeKeyFiles(path string) error {
+	for _, p := range []string{path, path + ".pub"} {
+		if err := os.Remove(p); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// servedHostKeys returns the host keys to present during the key exchange.
+// Clients only know the older keys until they learn the new ones, so the
+// oldest key still accepted of each type is served.
+func servedHostKeys(keys []HostKey, now time.Time) []gossh.Signer {
+	var signers []gossh.Signer
+	seen := map[string]int{}
+	for _, k := range keys {
+		if k.Retired() && !k.Expires.After(now) {
+			continue
+		}
+
+		typ := k.Signer.PublicKey().Type()
+		if i, ok := seen[typ]; ok {
+			signers[i] = k.Signer
+			continue
+		}
+
+		seen[typ] = len(signers)
+		signers = append(signers, k.Signer)
+	}
+
+	return signers
+}
+
+// withHostKeys returns an ssh.Option that sets the served host keys.
+func withHostKeys(keys []HostKey) ssh.Option {
+	return func(srv *ssh.Server) error {
+		for _, signer := range servedHostKeys(keys, time.Now()) {
+			srv.AddHostKey(signer)
+		}
+
+		return nil
+	}
+}
+
+// acceptedHostKeys returns the host keys that haven't expired.
+func (s *SSHServer) acceptedHostKeys() []HostKey {
+	var keys []HostKey
+	now := time.Now()
+	for _, k := range s.hostKeys {
+		if !k.Retired() || k.Expires.After(now) {
+			keys = append(keys, k)
+		}
+	}
+
+	return keys
+}
+
+// refreshHostKeys updates the host keys served by the server. It's called when
+// a retired key expires.
+func (s *SSHServer) refreshHostKeys() {
+	for _, signer := range servedHostKeys(s.hostKeys, time.Now()) {
+		s.srv.AddHostKey(signer)
+	}
+	s.logger.Info("retired host key expired")
+}
+
+// HostKeysMiddleware announces all accepted host keys to the client while a
+// host key rotation is in progress. OpenSSH clients use this to add the new
+// host key to their known hosts before the retired key expires.
+func (s *SSHServer) HostKeysMiddleware(sh ssh.Handler) ssh.Handler {
+	return func(sess ssh.Session) {
+		ctx := sess.Context()
+		keys := s.acceptedHostKeys()
+		if len(keys) > 1 && ctx.Value(contextKeyHostKeysSent) == nil {
+			ctx.SetValue(contextKeyHostKeysSent, true)
+			if conn, ok := ctx.Value(ssh.ContextKeyConn).(gossh.Conn); ok {
+				var payload []byte
+				for _, k := range keys {
+					payload = appendString(payload, k.Signer.PublicKey().Marshal())
+				}
+				if _, _, err := conn.SendRequest(hostKeysRequest, false, payload); err != nil {
+					s.logger.Debug("failed to announce host keys", "err", err)
+				}
+			}
+		}
+
+		sh(sess)
+	}
+}
+
+// HostKeysProveHandler proves ownership of the host keys requested by the
+// client by signing them with the connection session identifier.
+func (s *SSHServer) HostKeysProveHandler(ctx ssh.Context, _ *ssh.Server, req *gossh.Request) (bool, []byte) {
+	sessionID, err := hex.DecodeString(ctx.SessionID())
+	if err != nil {
+		return false, nil
+	}
+
+	resp, err := proveHostKeys(s.acceptedHostKeys(), sessionID, req.Payload)
+	if err != nil {
+		s.logger.Debug("failed to prove host keys", "err", err)
+		return false, nil
+	}
+
+	return true, resp
+}
+
+// proveHostKeys returns the signatures of the host keys in the request
+// payload.
+func proveHostKeys(keys []HostKey, sessionID []byte, payload []byte) ([]byte, error) {
+	var resp []byte
+	for len(payload) > 0 {
+		var blob []byte
+		var ok bool
+		blob, payload, ok = parseString(payload)
+		if !ok {
+			return nil, errors.New("malformed request")
+		}
+
+		var signer gossh.Signer
+		for _, k := range keys {
+			if bytes.Equal(k.Signer.PublicKey().Marshal(), blob) {
+				signer = k.Signer
+				break
+			}
+		}
+		if signer == nil {
+			return nil, errors.New("unknown host key")
+		}
+
+		var data []byte
+		data = appendString(data, []byte(hostKeysProveRequest))
+		data = appendString(data, sessionID)
+		data = appendString(data, blob)
+
+		var sig *gossh.Signature
+		var err error
+		if as, ok := signer.(gossh.AlgorithmSigner); ok && signer.PublicKey().Type() == gossh.KeyAlgoRSA {
+			sig, err = as.SignWithAlgorithm(rand.Reader, data, gossh.KeyAlgoRSASHA512)
+		} else {
+			sig, err = signer.Sign(rand.Reader, data)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		resp = appendString(resp, gossh.Marshal(sig))
+	}
+
+	return resp, nil
+}
+
+// appendString appends an SSH wire format string to b.
+func appendString(b []byte, s []byte) []byte {
+	b = binary.BigEndian.AppendUint32(b, uint32(len(s)))
+	return append(b, s...)
+}
+
+// parseString parses an SSH wire format string from b.
+func parseString(b []byte) (s []byte, rest []byte, ok bool) {
+	if len(b) < 4 {
+		return nil, nil, false
+	}
+
+	n := binary.BigEndian.Uint32(b)
+	if uint32(len(b)-4) < n {
+		return nil, nil, false
+	}
+
+	return b[4 : 4+n], b[4+n:], true
+}
@@ -0,0 +1,93 @@
+package ssh
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/soft-serve/pkg/config"
+	"github.com/matryer/is"
+	gossh "golang.org/x/crypto/ssh"
+)
+
+func TestRotateHostKey(t *testing.T) {
+	is := is.New(t)
+	cfg := config.DefaultConfig()
+	cfg.SSH.KeyPath = filepath.Join(t.TempDir(), "host_ed25519")
+
+	first, err := RotateHostKey(cfg, time.Hour)
+	is.NoErr(err)
+
+	keys, err := HostKeys(cfg)
+	is.NoErr(err)
+	is.Equal(len(keys), 1)
+	is.Equal(keys[0].Fingerprint(), first.Fingerprint())
+	is.True(!keys[0].Retired())
+
+	second, err := RotateHostKey(cfg, time.Hour)
+	is.NoErr(err)
+
+	keys, err = HostKeys(cfg)
+	is.NoErr(err)
+	is.Equal(len(keys), 2)
+	is.Equal(keys[0].Fingerprint(), second.Fingerprint())
+	is.Equal(keys[1].Fingerprint(), first.Fingerprint())
+	is.True(keys[1].Retired())
+
+	// The retired key is served until clients learn the new one.
+	served := servedHostKeys(keys, time.Now())
+	is.Equal(len(served), 1)
+	is.Equal(gossh.FingerprintSHA256(served[0].PublicKey()), first.Fingerprint())
+
+	// Once expired, the current key is served.
+	served = servedHostKeys(keys, time.Now().Add(2*time.Hour))
+	is.Equal(len(served), 1)
+	is.Equal(gossh.FingerprintSHA256(served[0].PublicKey()), second.Fingerprint())
+
+	// Rotating without a grace period drops the current key.
+	third, err := RotateHostKey(cfg, 0)
+	is.NoErr(err)
+
+	keys, err = HostKeys(cfg)
+	is.NoErr(err)
+	is.Equal(len(keys), 2)
+	is.Equal(keys[0].Fingerprint(), third.Fingerprint())
+	is.Equal(keys[1].Fingerprint(), first.Fingerprint())
+}
+
+func TestProveHostKeys(t *testing.T) {
+	is := is.New(t)
+	cfg := config.DefaultConfig()
+	cfg.SSH.KeyPath = filepath.Join(t.TempDir(), "host_ed25519")
+
+	key, err := RotateHostKey(cfg, 0)
+	is.NoErr(err)
+
+	sessionID := []byte("session")
+	blob := key.Signer.PublicKey().Marshal()
+	resp, err := proveHostKeys([]HostKey{key}, sessionID, appendString(nil, blob))
+	is.NoErr(err)
+
+	raw, rest, ok := parseString(resp)
+	is.True(ok)
+	is.Equal(len(rest), 0)
+
+	var sig gossh.Signature
+	is.NoErr(gossh.Unmarshal(raw, &sig))
+
+	var data []byte
+	data = appendString(data, []byte(hostKeysProveRequest))
+	data = appendString(data, sessionID)
+	data = appendString(data, blob)
+	is.NoErr(key.Signer.PublicKey().Verify(data, &sig))
+
+	// Unknown keys can't be proven.
+	other, err := RotateHostKey(cfg, 0)
+	is.NoErr(err)
+	_, err = proveHostKeys([]HostKey{key}, sessionID, appendString(nil, other.Signer.PublicKey().Marshal()))
+	is.True(err != nil)
+
+	// Malformed requests are rejected.
+	_, err = proveHostKeys([]HostKey{key}, sessionID, []byte{0, 0, 0, 9})
+	is.True(err != nil)
+}
@@ -0,0 +1,129 @@
+package ssh
+
+import (
+	"net"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Outcomes of keyboard-interactive authentication attempts.
+const (
+	// interactiveOutcomeInvite means a valid invite code was entered.
+	interactiveOutcomeInvite = "invite"
+	// interactiveOutcomeInvalidInvite means the invite code was rejected.
+	interactiveOutcomeInvalidInvite = "invalid_invite"
+	// interactiveOutcomeKeyless means the client was let in without a key.
+	interactiveOutcomeKeyless = "keyless"
+	// interactiveOutcomeRejected means keyless access isn't allowed.
+	interactiveOutcomeRejected = "rejected"
+	// interactiveOutcomeLockedOut means the address is locked out after
+	// too many failures.
+	interactiveOutcomeLockedOut = "locked_out"
+)
+
+var (
+	keyboardInteractiveDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "soft_serve",
+		Subsystem: "ssh",
+		Name:      "keyboard_interactive_auth_duration_seconds",
+		Help:      "The time keyboard interactive auth requests took, including prompting the client",
+		Buckets:   []float64{0.01, 0.1, 1, 5, 15, 60},
+	}, []string{"outcome"})
+
+	keyboardInteractiveLockoutCounter = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: "soft_serve",
+		Subsystem: "ssh",
+		Name:      "keyboard_interactive_lockouts_total",
+		Help:      "The total number of client addresses locked out after repeated keyboard interactive auth failures",
+	})
+)
+
+// maxInteractiveFailureEntries is the number of tracked addresses above
+// which expired entries are forgotten.
+const maxInteractiveFailureEntries = 1024
+
+// interactiveLockout locks out client IP addresses after repeated
+// keyboard-interactive authentication failures, like wrong invite codes, to
+// prevent brute-forcing them.
+type interactiveLockout struct {
+	mu  sync.Mutex
+	ips map[string]*interactiveFailures
+}
+
+// interactiveFailures are the recent failures of a client IP address.
+type interactiveFailures struct {
+	count int
+	last  time.Time
+	until time.Time
+}
+
+// lockedOut returns whether ip is locked out.
+func (l *interactiveLockout) lockedOut(ip string, now time.Time) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	f, ok := l.ips[ip]
+	return ok && now.Before(f.until)
+}
+
+// fail records a failure of ip. Failures older than the lockout duration are
+// forgotten, and max failures lock the address out for the lockout
+// duration. It returns whether this failure locked the address out.
+func (l *interactiveLockout) fail(ip string, now time.Time, max int, lockout time.Duration) bool {
+	if max <= 0 || lockout <= 0 {
+		return false
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.ips == nil {
+		l.ips = map[string]*interactiveFailures{}
+	}
+
+	if len(l.ips) >= maxInteractiveFailureEntries {
+		for k, f := range l.ips {
+			if now.Sub(f.last) > lockout && !now.Before(f.until) {
+				delete(l.ips, k)
+			}
+		}
+	}
+
+	f, ok := l.ips[ip]
+	if !ok || now.Sub(f.last) > lockout {
+		f = &interactiveFailures{}
+		l.ips[ip] = f
+	}
+
+	f.count++
+	f.last = now
+	if f.count < max {
+		return false
+	}
+
+	f.count = 0
+	f.until = now.Add(lockout)
+	return true
+}
+
+// succeed forgets the failures of ip.
+func (l *interactiveLockout) succeed(ip string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.ips, ip)
+}
+
+// addrIP returns the IP address of a client address.
+func addrIP(addr net.Addr) string {
+	if addr == nil {
+		return ""
+	}
+
+	host, _, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return addr.String()
+	}
+
+	return host
+}
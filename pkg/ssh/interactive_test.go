@@ -0,0 +1,50 @@
+package ssh
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestInteractiveLockout(t *testing.T) {
+	is := is.New(t)
+	var l interactiveLockout
+	now := time.Now()
+	lockout := time.Minute
+
+	// Failures under the limit don't lock out.
+	is.True(!l.fail("10.0.0.1", now, 3, lockout))
+	is.True(!l.fail("10.0.0.1", now, 3, lockout))
+	is.True(!l.lockedOut("10.0.0.1", now))
+
+	// Failures older than the lockout duration are forgotten.
+	later := now.Add(2 * lockout)
+	is.True(!l.fail("10.0.0.1", later, 3, lockout))
+	is.True(!l.fail("10.0.0.1", later, 3, lockout))
+	is.True(!l.lockedOut("10.0.0.1", later))
+
+	// Reaching the limit locks the address out for the lockout duration.
+	is.True(l.fail("10.0.0.1", later, 3, lockout))
+	is.True(l.lockedOut("10.0.0.1", later))
+	is.True(!l.lockedOut("10.0.0.2", later))
+	is.True(!l.lockedOut("10.0.0.1", later.Add(lockout)))
+
+	// Successes forget the failures.
+	is.True(!l.fail("10.0.0.2", now, 2, lockout))
+	l.succeed("10.0.0.2")
+	is.True(!l.fail("10.0.0.2", now, 2, lockout))
+
+	// No limit never locks out.
+	for i := 0; i < 10; i++ {
+		is.True(!l.fail("10.0.0.3", now, 0, lockout))
+	}
+
+	// Expired entries are forgotten once there are many.
+	for i := 0; i < maxInteractiveFailureEntries; i++ {
+		l.fail(fmt.Sprintf("10.1.%d.%d", i/256, i%256), now, 3, lockout)
+	}
+	l.fail("10.0.0.4", later.Add(2*lockout), 3, lockout)
+	is.True(len(l.ips) < maxInteractiveFailureEntries)
+}
@@ -0,0 +1,181 @@
+package ssh
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/soft-serve/pkg/access"
+	"github.com/charmbracelet/soft-serve/pkg/proto"
+	"github.com/charmbracelet/soft-serve/pkg/sshutils"
+	"github.com/charmbracelet/ssh"
+	"github.com/charmbracelet/wish"
+	gossh "golang.org/x/crypto/ssh"
+)
+
+var (
+	// contextKeyInviteKey is the unregistered public key a client offered,
+	// to register with an invite code.
+	contextKeyInviteKey = &struct{ string }{"invite-key"}
+
+	// contextKeyInvitedUser is the username a client registered its key to.
+	contextKeyInvitedUser = &struct{ string }{"invited-user"}
+)
+
+// offerInvite returns whether an unregistered public key should be rejected
+// so the client falls back to keyboard-interactive authentication and can
+// register the key with an invite code. The first key offered is kept.
+func (s *SSHServer) offerInvite(ctx ssh.Context, pk ssh.PublicKey) bool {
+	if !s.cfg.SSH.Invites || s.be.AnonAccess(ctx) != access.NoAccess {
+		return false
+	}
+
+	// Keys granted access to a repository are let in to use it.
+	if s.be.KeyHasRepoAccess(ctx, pk) {
+		return false
+	}
+
+	if _, ok := ctx.Value(contextKeyInviteKey).(ssh.PublicKey); !ok {
+		ctx.SetValue(contextKeyInviteKey, pk)
+	}
+
+	return true
+}
+
+// pendingInviteTimeout is how long a client has to reconnect with its key
+// after entering a valid invite code.
+const pendingInviteTimeout = 10 * time.Minute
+
+// pendingInvites are the valid invite codes entered for unregistered keys,
+// by key fingerprint, waiting for the clients to reconnect and sign in with
+// the keys.
+type pendingInvites struct {
+	mu   sync.Mutex
+	keys map[string]pendingInvite
+}
+
+// pendingInvite is a valid invite code entered for an unregistered key.
+type pendingInvite struct {
+	code     string
+	username string
+	expires  time.Time
+}
+
+// add records the invite code entered for the key fingerprint fp, replacing
+// the previous one.
+func (p *pendingInvites) add(fp string, code string, username string, now time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.keys == nil {
+		p.keys = make(map[string]pendingInvite)
+	}
+	for k, pi := range p.keys {
+		if now.After(pi.expires) {
+			delete(p.keys, k)
+		}
+	}
+	p.keys[fp] = pendingInvite{code: code, username: username, expires: now.Add(pendingInviteTimeout)}
+}
+
+// get returns the invite code entered for the key fingerprint fp, if any.
+func (p *pendingInvites) get(fp string, now time.Time) (pendingInvite, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	pi, ok := p.keys[fp]
+	if !ok || now.After(pi.expires) {
+		return pendingInvite{}, false
+	}
+
+	return pi, true
+}
+
+// take returns and forgets the invite code entered for the key fingerprint
+// fp, if any.
+func (p *pendingInvites) take(fp string, now time.Time) (pendingInvite, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	pi, ok := p.keys[fp]
+	delete(p.keys, fp)
+	if !ok || now.After(pi.expires) {
+		return pendingInvite{}, false
+	}
+
+	return pi, true
+}
+
+// redeemInvite prompts the client for an invite code for the unregistered
+// key it offered. A valid code isn't used yet, since the key wasn't proven to
+// belong to the client: the client is only let in to be told to reconnect
+// with the key, which registers it once the client signed in with it.
+// It returns whether the client authenticated and whether it was prompted.
+func (s *SSHServer) redeemInvite(ctx ssh.Context, challenge gossh.KeyboardInteractiveChallenge) (ok bool, prompted bool) {
+	pk, _ := ctx.Value(contextKeyInviteKey).(ssh.PublicKey)
+	if pk == nil || challenge == nil {
+		return false, false
+	}
+
+	fp := gossh.FingerprintSHA256(pk)
+	answers, err := challenge(ctx.User(),
+		fmt.Sprintf("The key %s isn't registered. Enter an invite code to register it, or leave it empty to skip.", sshutils.KeyFingerprint(pk)),
+		[]string{"Invite code: "}, []bool{true})
+	if err != nil || len(answers) != 1 || strings.TrimSpace(answers[0]) == "" {
+		return false, false
+	}
+
+	invite, err := s.be.CheckInvite(ctx, answers[0])
+	if err != nil {
+		if !errors.Is(err, proto.ErrInvalidInvite) {
+			s.logger.Error("failed to check invite", "fingerprint", fp, "remote-addr", ctx.RemoteAddr(), "err", err)
+		}
+		s.logger.Info("rejecting invite code", "fingerprint", fp, "remote-addr", ctx.RemoteAddr(), "err", err)
+		return false, true
+	}
+
+	s.pendingInvites.add(fp, answers[0], invite.Username, time.Now())
+	ctx.SetValue(contextKeyInvitedUser, invite.Username)
+	return true, true
+}
+
+// InviteMiddleware ends the sessions of clients that entered a valid invite
+// code, asking them to reconnect with their key, and registers the keys of
+// clients reconnecting with them. It must run after the
+// AuthenticationMiddleware, so the session key is the one the client signed
+// in with.
+func (s *SSHServer) InviteMiddleware(sh ssh.Handler) ssh.Handler {
+	return func(sess ssh.Session) {
+		ctx := sess.Context()
+		if username, ok := ctx.Value(contextKeyInvitedUser).(string); ok {
+			wish.Fatalf(sess, "Reconnect with the key to finish registering it to the user %q.\n", username)
+			return
+		}
+
+		pk := sess.PublicKey()
+		if pk == nil {
+			sh(sess)
+			return
+		}
+
+		fp := gossh.FingerprintSHA256(pk)
+		pi, ok := s.pendingInvites.take(fp, time.Now())
+		if !ok {
+			sh(sess)
+			return
+		}
+
+		user, err := s.be.RedeemInvite(ctx, pi.code, pk)
+		if err != nil {
+			if !errors.Is(err, proto.ErrInvalidInvite) {
+				s.logger.Error("failed to redeem invite", "fingerprint", fp, "remote-addr", ctx.RemoteAddr(), "err", err)
+			}
+			wish.Fatalln(sess, err)
+			return
+		}
+
+		wish.Fatalf(sess, "Your key is now registered to the user %q, reconnect to sign in with it.\n", user.Username())
+	}
+}
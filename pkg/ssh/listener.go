@@ -0,0 +1,30 @@
+package ssh
+
+import (
+	"net"
+
+	"github.com/charmbracelet/soft-serve/pkg/access"
+)
+
+// limitedListener is a listener whose connections have a maximum access
+// level.
+type limitedListener struct {
+	net.Listener
+	level access.AccessLevel
+}
+
+// Accept implements net.Listener.
+func (l *limitedListener) Accept() (net.Conn, error) {
+	c, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+
+	return &limitedConn{Conn: c, level: l.level}, nil
+}
+
+// limitedConn is a connection with a maximum access level.
+type limitedConn struct {
+	net.Conn
+	level access.AccessLevel
+}
@@ -0,0 +1,169 @@
+package ssh
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/soft-serve/pkg/proto"
+	"github.com/charmbracelet/soft-serve/pkg/ui/common"
+	"github.com/charmbracelet/ssh"
+	gossh "golang.org/x/crypto/ssh"
+)
+
+// sessionWatchInterval is how often the active sessions are checked against
+// the lockdown and the revoked keys. Both are changed from other processes,
+// `soft admin` for instance, so they're polled.
+const sessionWatchInterval = 2 * time.Second
+
+// sessionRegistry tracks the active SSH sessions and when they started.
+type sessionRegistry struct {
+	mu       sync.Mutex
+	sessions map[ssh.Session]time.Time
+}
+
+func (r *sessionRegistry) add(s ssh.Session) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.sessions == nil {
+		r.sessions = map[ssh.Session]time.Time{}
+	}
+	r.sessions[s] = time.Now()
+}
+
+func (r *sessionRegistry) remove(s ssh.Session) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.sessions, s)
+}
+
+func (r *sessionRegistry) list() []ssh.Session {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	sessions := make([]ssh.Session, 0, len(r.sessions))
+	for s := range r.sessions {
+		sessions = append(sessions, s)
+	}
+	return sessions
+}
+
+// info returns the active sessions as listed in the admin panel of the TUI,
+// oldest first.
+func (r *sessionRegistry) info() []common.Session {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	sessions := make([]common.Session, 0, len(r.sessions))
+	for sess, started := range r.sessions {
+		info := common.Session{
+			Command:    strings.Join(sess.Command(), " "),
+			RemoteAddr: sess.RemoteAddr().String(),
+			Started:    started,
+		}
+		if user := proto.UserFromContext(sess.Context()); user != nil {
+			info.User = user.Username()
+		}
+		if pk := sess.PublicKey(); pk != nil {
+			info.Fingerprint = gossh.FingerprintSHA256(pk)
+		}
+		sessions = append(sessions, info)
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].Started.Before(sessions[j].Started)
+	})
+
+	return sessions
+}
+
+// SessionsMiddleware tracks the active sessions so they can be terminated on
+// lockdown or when their key is revoked, and listed in the admin panel.
+// This middleware must be run after the AuthenticationMiddleware.
+func (s *SSHServer) SessionsMiddleware(sh ssh.Handler) ssh.Handler {
+	return func(sess ssh.Session) {
+		s.sessions.add(sess)
+		sess.Context().SetValue(common.SessionsKey, s.sessions.info)
+		defer s.sessions.remove(sess)
+		sh(sess)
+	}
+}
+
+// watchSessions terminates denied sessions until ctx is done.
+func (s *SSHServer) watchSessions(ctx context.Context) {
+	ticker := time.NewTicker(sessionWatchInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.terminateDeniedSessions(ctx)
+		}
+	}
+}
+
+// terminateDeniedSessions terminates the sessions of revoked keys, and of
+// non-admins in lockdown.
+func (s *SSHServer) terminateDeniedSessions(ctx context.Context) {
+	sessions := s.sessions.list()
+	if len(sessions) == 0 {
+		return
+	}
+
+	revoked := map[string]struct{}{}
+	keys, err := s.be.RevokedKeys(ctx)
+	if err != nil {
+		s.logger.Error("error getting revoked keys", "err", err)
+		return
+	}
+	for _, k := range keys {
+		revoked[k.Fingerprint] = struct{}{}
+	}
+
+	lockdown := s.be.Lockdown(ctx)
+	for _, sess := range sessions {
+		pk := sess.PublicKey()
+		var fp string
+		if pk != nil {
+			fp = gossh.FingerprintSHA256(pk)
+		}
+
+		var reason string
+		if _, ok := revoked[fp]; ok && fp != "" {
+			reason = "your key has been revoked"
+		} else if lockdown && !s.be.IsAdminKey(ctx, pk) {
+			reason = "the server is in lockdown"
+		} else {
+			continue
+		}
+
+		s.terminateSession(ctx, sess, fp, reason)
+	}
+}
+
+// terminateSession closes the connection of a session and records it in the
+// audit log.
+func (s *SSHServer) terminateSession(ctx context.Context, sess ssh.Session, fp string, reason string) {
+	user := proto.UserFromContext(sess.Context())
+	var username string
+	if user != nil {
+		username = user.Username()
+	}
+
+	s.logger.Info("terminating session", "reason", reason, "fingerprint", fp, "user", username, "remote-addr", sess.RemoteAddr())
+	fmt.Fprintf(sess.Stderr(), "\r\nSession terminated: %s.\r\n", reason) // nolint: errcheck
+
+	details := fmt.Sprintf("fingerprint=%s user=%s remote-addr=%s", fp, username, sess.RemoteAddr())
+	if err := s.be.RecordSessionTerminated(ctx, user, details); err != nil {
+		s.logger.Error("error recording terminated session", "err", err)
+	}
+
+	// Close the whole connection, it's authenticated with the denied key.
+	sess.Close() // nolint: errcheck
+	if conn, ok := sess.Context().Value(ssh.ContextKeyConn).(gossh.Conn); ok {
+		conn.Close() // nolint: errcheck
+	}
+	s.sessions.remove(sess)
+}
@@ -1,18 +1,25 @@
 package ssh
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"slices"
+	"strings"
 	"time"
 
+	"github.com/charmbracelet/lipgloss"
 	"github.com/charmbracelet/log"
 	"github.com/charmbracelet/soft-serve/pkg/backend"
 	"github.com/charmbracelet/soft-serve/pkg/config"
 	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/git"
+	logr "github.com/charmbracelet/soft-serve/pkg/log"
 	"github.com/charmbracelet/soft-serve/pkg/proto"
 	"github.com/charmbracelet/soft-serve/pkg/ssh/cmd"
 	"github.com/charmbracelet/soft-serve/pkg/sshutils"
 	"github.com/charmbracelet/soft-serve/pkg/store"
+	"github.com/charmbracelet/soft-serve/pkg/tracing"
 	"github.com/charmbracelet/ssh"
 	"github.com/charmbracelet/wish"
 	bm "github.com/charmbracelet/wish/bubbletea"
@@ -20,6 +27,8 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
 	"github.com/spf13/cobra"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	gossh "golang.org/x/crypto/ssh"
 )
 
@@ -82,15 +91,16 @@ var cliCommandCounter = promauto.NewCounterVec(prometheus.CounterOpts{
 // This middleware must be run after the ContextMiddleware.
 func CommandMiddleware(sh ssh.Handler) ssh.Handler {
 	return func(s ssh.Session) {
+		ctx := s.Context()
+		cfg := config.FromContext(ctx)
+
+		// Pty sessions open the TUI, unless it's disabled.
 		_, _, ptyReq := s.Pty()
-		if ptyReq {
+		if ptyReq && cfg.TUI.Enabled {
 			sh(s)
 			return
 		}
 
-		ctx := s.Context()
-		cfg := config.FromContext(ctx)
-
 		renderer := bm.MakeRenderer(s)
 		if testrun, ok := os.LookupEnv("SOFT_SERVE_NO_COLOR"); ok && testrun == "1" {
 			// Disable colors when running tests.
@@ -98,100 +108,237 @@ func CommandMiddleware(sh ssh.Handler) ssh.Handler {
 		}
 
 		args := s.Command()
+		rootCmd := newRootCommand(cfg, renderer)
+		restrictCommands(rootCmd, cfg.SSH.AllowedCommands)
+		// Sessions without a command are shell requests, there's no shell to
+		// give so they get the list of commands instead.
+		shell := len(args) == 0 && ptyReq
+		switch {
+		case len(args) == 0:
+			if shell {
+				wish.Print(s, "Interactive shell not permitted, the TUI is disabled on this server. Use one of the commands below instead.\n\n")
+			}
+			// Like a message of the day, warn users getting close to their
+			// disk quota when they log in.
+			if warning := backend.FromContext(ctx).QuotaWarning(ctx, proto.UserFromContext(ctx)); warning != "" {
+				wish.Errorf(s, "Warning: %s.\n\n", warning)
+			}
+			// otherwise it'll default to os.Args, which is not what we want.
+			rootCmd.SetArgs([]string{"--help"})
+		case !commandPermitted(rootCmd, args):
+			// Only recognized commands ever run, the others are rejected
+			// here, before cobra sees them.
+			log.FromContext(ctx).Warn("rejected ssh command", "cmd", args[0], "remote-addr", s.RemoteAddr().String())
+			wish.Fatalf(s, "%s command %q not permitted\n", rootCmd.ErrPrefix(), args[0])
+			ctx.SetValue(contextKeyCommandFailed, true)
+			return
+		default:
+			rootCmd.SetArgs(args)
+		}
 		cliCommandCounter.WithLabelValues(cmd.CommandName(args)).Inc()
-		rootCmd := &cobra.Command{
-			Short:        "Soft Serve is a self-hostable Git server for the command line.",
-			SilenceUsage: true,
+		cmdCtx, span := tracing.Start(connContext(ctx), "ssh.command", trace.WithAttributes(
+			attribute.String("command", cmd.CommandName(args)),
+		))
+		rootCmd.SetIn(s)
+		rootCmd.SetOut(s)
+		rootCmd.SetErr(s.Stderr())
+		rootCmd.SetContext(cmdCtx)
+
+		err := rootCmd.ExecuteContext(cmdCtx)
+		tracing.End(span, err)
+		if err != nil {
+			rootCmd.PrintErrln(rootCmd.ErrPrefix(), err.Error())
+			if isAccessDenied(err) {
+				rootCmd.PrintErrln(keyHint(s.PublicKey()))
+			}
+			ctx.SetValue(contextKeyCommandFailed, true)
+			s.Exit(1) // nolint: errcheck
+			return
 		}
-		rootCmd.CompletionOptions.DisableDefaultCmd = true
 
-		rootCmd.SetUsageTemplate(cmd.UsageTemplate)
-		rootCmd.SetUsageFunc(cmd.UsageFunc)
+		if shell {
+			ctx.SetValue(contextKeyCommandFailed, true)
+			s.Exit(1) // nolint: errcheck
+		}
+	}
+}
+
+// newRootCommand returns the command line SSH clients can run.
+func newRootCommand(cfg *config.Config, renderer *lipgloss.Renderer) *cobra.Command {
+	rootCmd := &cobra.Command{
+		Short:        "Soft Serve is a self-hostable Git server for the command line.",
+		SilenceUsage: true,
+		// Errors are printed below to add the key fingerprint to access
+		// denials.
+		SilenceErrors: true,
+	}
+	rootCmd.CompletionOptions.DisableDefaultCmd = true
+
+	rootCmd.SetUsageTemplate(cmd.UsageTemplate)
+	rootCmd.SetUsageFunc(cmd.UsageFunc)
+	rootCmd.AddCommand(
+		cmd.GitUploadPackCommand(),
+		cmd.GitUploadArchiveCommand(),
+		cmd.GitReceivePackCommand(),
+		cmd.ArchiveCommand(),
+		cmd.RepoCommand(renderer),
+		cmd.SettingsCommand(),
+		cmd.UserCommand(),
+		cmd.InfoCommand(),
+		cmd.PubkeyCommand(),
+		cmd.SetUsernameCommand(),
+		cmd.JWTCommand(),
+		cmd.TokenCommand(),
+		cmd.TermsCommand(),
+	)
+
+	if cfg.LFS.Enabled {
 		rootCmd.AddCommand(
-			cmd.GitUploadPackCommand(),
-			cmd.GitUploadArchiveCommand(),
-			cmd.GitReceivePackCommand(),
-			cmd.RepoCommand(renderer),
-			cmd.SettingsCommand(),
-			cmd.UserCommand(),
-			cmd.InfoCommand(),
-			cmd.PubkeyCommand(),
-			cmd.SetUsernameCommand(),
-			cmd.JWTCommand(),
-			cmd.TokenCommand(),
+			cmd.GitLFSAuthenticateCommand(),
 		)
 
-		if cfg.LFS.Enabled {
+		if cfg.LFS.SSHEnabled {
 			rootCmd.AddCommand(
-				cmd.GitLFSAuthenticateCommand(),
+				cmd.GitLFSTransfer(),
 			)
+		}
+	}
 
-			if cfg.LFS.SSHEnabled {
-				rootCmd.AddCommand(
-					cmd.GitLFSTransfer(),
-				)
+	return rootCmd
+}
+
+// restrictCommands removes the commands of rootCmd that aren't allowed. An
+// empty list allows every command.
+func restrictCommands(rootCmd *cobra.Command, allowed []string) {
+	if len(allowed) > 0 {
+		for _, c := range rootCmd.Commands() {
+			if !slices.Contains(allowed, c.Name()) {
+				rootCmd.RemoveCommand(c)
 			}
 		}
+	}
 
-		rootCmd.SetArgs(args)
-		if len(args) == 0 {
-			// otherwise it'll default to os.Args, which is not what we want.
-			rootCmd.SetArgs([]string{"--help"})
-		}
-		rootCmd.SetIn(s)
-		rootCmd.SetOut(s)
-		rootCmd.SetErr(s.Stderr())
-		rootCmd.SetContext(ctx)
+	// Add the help command now, so commandPermitted finds it.
+	rootCmd.InitDefaultHelpCmd()
+}
 
-		if err := rootCmd.ExecuteContext(ctx); err != nil {
-			s.Exit(1) // nolint: errcheck
-			return
+// validateAllowedCommands returns an error if an allowed command doesn't
+// exist, since a typo would reject the clients running it.
+func validateAllowedCommands(cfg *config.Config) error {
+	rootCmd := newRootCommand(cfg, lipgloss.DefaultRenderer())
+	for _, name := range cfg.SSH.AllowedCommands {
+		if c, _, err := rootCmd.Find([]string{name}); err != nil || c.Name() != name {
+			return fmt.Errorf("unknown ssh.allowed_commands entry %q", name)
 		}
 	}
+
+	return nil
 }
 
-// LoggingMiddleware logs the ssh connection and command.
-func LoggingMiddleware(sh ssh.Handler) ssh.Handler {
-	return func(s ssh.Session) {
-		ctx := s.Context()
-		logger := log.FromContext(ctx).WithPrefix("ssh")
-		ct := time.Now()
-		hpk := sshutils.MarshalAuthorizedKey(s.PublicKey())
-		ptyReq, _, isPty := s.Pty()
-		addr := s.RemoteAddr().String()
-		user := proto.UserFromContext(ctx)
-		logArgs := []interface{}{
-			"addr",
-			addr,
-			"cmd",
-			s.Command(),
-		}
+// commandPermitted returns whether args run one of the commands of rootCmd,
+// or only pass it flags, like --help. Anything else is rejected before
+// running.
+func commandPermitted(rootCmd *cobra.Command, args []string) bool {
+	c, _, err := rootCmd.Find(args)
+	if err != nil {
+		return false
+	}
 
-		if user != nil {
-			logArgs = append([]interface{}{
-				"username",
-				user.Username(),
-			}, logArgs...)
-		}
+	return c != rootCmd || strings.HasPrefix(args[0], "-")
+}
+
+// isAccessDenied returns whether err denies the user access.
+func isAccessDenied(err error) bool {
+	return errors.Is(err, proto.ErrUnauthorized) || errors.Is(err, git.ErrNotAuthed)
+}
+
+// keyHint tells the user which key they authenticated with, in the format of
+// `ssh-keygen -l`, so they can tell whether ssh offered the wrong key.
+func keyHint(pk gossh.PublicKey) string {
+	if pk == nil {
+		return "You are not authenticated with a public key."
+	}
 
-		if isPty {
-			logArgs = []interface{}{
-				"term", ptyReq.Term,
-				"width", ptyReq.Window.Width,
-				"height", ptyReq.Window.Height,
+	return fmt.Sprintf("You are authenticated with the key %s.", sshutils.KeyFingerprint(pk))
+}
+
+// contextKeyCommandFailed marks a session whose command failed.
+var contextKeyCommandFailed = &struct{ string }{"command-failed"}
+
+// LoggingMiddleware logs the ssh connection and command. Successful sessions
+// are logged when the sampler keeps them, failed sessions are always logged.
+// Every session is sent to syslog, if configured.
+func LoggingMiddleware(sampler *logr.Sampler, syslog *logr.Syslog) func(ssh.Handler) ssh.Handler {
+	return func(sh ssh.Handler) ssh.Handler {
+		return func(s ssh.Session) {
+			ctx := s.Context()
+			logger := log.FromContext(ctx).WithPrefix("ssh")
+			ct := time.Now()
+			hpk := sshutils.MarshalAuthorizedKey(s.PublicKey())
+			ptyReq, _, isPty := s.Pty()
+			addr := s.RemoteAddr().String()
+			user := proto.UserFromContext(ctx)
+			logArgs := []interface{}{
+				"addr",
+				addr,
+				"cmd",
+				s.Command(),
+			}
+
+			if user != nil {
+				logArgs = append([]interface{}{
+					"username",
+					user.Username(),
+				}, logArgs...)
+			}
+
+			if isPty {
+				logArgs = []interface{}{
+					"term", ptyReq.Term,
+					"width", ptyReq.Window.Width,
+					"height", ptyReq.Window.Height,
+				}
+			}
+
+			if config.IsVerbose() {
+				logArgs = append(logArgs,
+					"key", hpk,
+					"envs", s.Environ(),
+				)
+			}
+
+			msg := fmt.Sprintf("user %q", s.User())
+			sampled := !syslog.Only() && sampler.Sample()
+			if sampled {
+				logger.Debug(msg+" connected", logArgs...)
+			}
+			ctx.SetValue(contextKeyCommandFailed, false)
+			sh(s)
+			failed, _ := ctx.Value(contextKeyCommandFailed).(bool)
+			switch {
+			case syslog.Only():
+			case failed:
+				logger.Debug(msg+" disconnected", append(logArgs, "duration", time.Since(ct), "failed", true)...)
+			case sampled:
+				logger.Debug(msg+" disconnected", append(logArgs, "duration", time.Since(ct))...)
 			}
-		}
 
-		if config.IsVerbose() {
-			logArgs = append(logArgs,
-				"key", hpk,
-				"envs", s.Environ(),
+			severity := logr.SeverityInfo
+			if failed {
+				severity = logr.SeverityWarning
+			}
+			var username string
+			if user != nil {
+				username = user.Username()
+			}
+			syslog.Log(severity, "ssh", msg+" disconnected",
+				"user", s.User(),
+				"username", username,
+				"addr", addr,
+				"cmd", strings.Join(s.Command(), " "),
+				"duration", time.Since(ct).Round(time.Millisecond),
+				"failed", failed,
 			)
 		}
-
-		msg := fmt.Sprintf("user %q", s.User())
-		logger.Debug(msg+" connected", logArgs...)
-		sh(s)
-		logger.Debug(msg+" disconnected", append(logArgs, "duration", time.Since(ct))...)
 	}
 }
@@ -0,0 +1,54 @@
+package ssh
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/soft-serve/pkg/config"
+)
+
+func TestCommandPermitted(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cases := []struct {
+		name    string
+		allowed []string
+		args    []string
+		want    bool
+	}{
+		{"git command", nil, []string{"git-upload-pack", "repo1"}, true},
+		{"cli command", nil, []string{"repo", "list"}, true},
+		{"alias", nil, []string{"repos", "list"}, true},
+		{"help flag", nil, []string{"--help"}, true},
+		{"help command", nil, []string{"help", "repo"}, true},
+		{"unknown command", nil, []string{"sh", "-c", "id"}, false},
+		{"shell", nil, []string{"bash"}, false},
+		{"allowed command", []string{"git-upload-pack"}, []string{"git-upload-pack", "repo1"}, true},
+		{"disallowed command", []string{"git-upload-pack"}, []string{"repo", "list"}, false},
+		{"help when restricted", []string{"git-upload-pack"}, []string{"help"}, true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			rootCmd := newRootCommand(cfg, lipgloss.DefaultRenderer())
+			restrictCommands(rootCmd, c.allowed)
+			if got := commandPermitted(rootCmd, c.args); got != c.want {
+				t.Errorf("commandPermitted(%q) = %t, want %t", c.args, got, c.want)
+			}
+		})
+	}
+}
+
+func TestValidateAllowedCommands(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.SSH.AllowedCommands = []string{"git-upload-pack", "git-receive-pack", "repo"}
+	if err := validateAllowedCommands(cfg); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+
+	for _, name := range []string{"bash", "repos"} {
+		cfg.SSH.AllowedCommands = []string{name}
+		if err := validateAllowedCommands(cfg); err == nil {
+			t.Errorf("expected an error for %q", name)
+		}
+	}
+}
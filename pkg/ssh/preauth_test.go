@@ -0,0 +1,54 @@
+package ssh
+
+import (
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/keygen"
+	"github.com/charmbracelet/log"
+	"github.com/charmbracelet/soft-serve/pkg/config"
+	"github.com/charmbracelet/ssh"
+	"github.com/matryer/is"
+	gossh "golang.org/x/crypto/ssh"
+)
+
+func TestPreAuthTimeout(t *testing.T) {
+	is := is.New(t)
+	cfg := config.DefaultConfig()
+	cfg.SSH.PreAuthTimeout = 1
+	s := &SSHServer{cfg: cfg, logger: log.New(io.Discard)}
+
+	kp, err := keygen.New("", keygen.WithKeyType(keygen.Ed25519))
+	is.NoErr(err)
+
+	srv := &ssh.Server{
+		Handler:      func(ssh.Session) {},
+		ConnCallback: s.ConnCallback,
+		HostSigners:  []ssh.Signer{kp.Signer()},
+	}
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	is.NoErr(err)
+	go srv.Serve(l)                   // nolint: errcheck
+	t.Cleanup(func() { srv.Close() }) // nolint: errcheck
+
+	// A connection that never authenticates is closed.
+	conn, err := net.Dial("tcp", l.Addr().String())
+	is.NoErr(err)
+	defer conn.Close() // nolint: errcheck
+	is.NoErr(conn.SetReadDeadline(time.Now().Add(5 * time.Second)))
+	_, err = io.Copy(io.Discard, conn)
+	is.NoErr(err) // connection should be closed by the server
+
+	// An authenticated connection is kept open.
+	client, err := gossh.Dial("tcp", l.Addr().String(), &gossh.ClientConfig{
+		User:            "user",
+		HostKeyCallback: gossh.InsecureIgnoreHostKey(), // nolint: gosec
+	})
+	is.NoErr(err)
+	defer client.Close() // nolint: errcheck
+	time.Sleep(1500 * time.Millisecond)
+	_, _, err = client.SendRequest("keepalive@openssh.com", true, nil)
+	is.NoErr(err)
+}
@@ -29,7 +29,7 @@ func TestSession(t *testing.T) {
 	is := is.New(t)
 	t.Run("authorized repo access", func(t *testing.T) {
 		t.Log("setting up")
-		s, close := setup(t)
+		s, close := setup(t, nil)
 		s.Stderr = os.Stderr
 		t.Log("requesting pty")
 		err := s.RequestPty("xterm", 80, 40, nil)
@@ -46,9 +46,28 @@ func TestSession(t *testing.T) {
 		t.Log("session exited")
 		is.NoErr(close())
 	})
+	t.Run("idle tui session", func(t *testing.T) {
+		s, close := setup(t, func(cfg *config.Config) {
+			cfg.TUI.IdleTimeout = 1
+		})
+		err := s.RequestPty("xterm", 80, 40, nil)
+		is.NoErr(err)
+		done := make(chan error, 1)
+		go func() {
+			_, err := s.Output("")
+			done <- err
+		}()
+		select {
+		case err := <-done:
+			is.NoErr(err)
+		case <-time.After(10 * time.Second):
+			t.Fatal("idle session wasn't closed")
+		}
+		is.NoErr(close())
+	})
 }
 
-func setup(tb testing.TB) (*gossh.Session, func() error) {
+func setup(tb testing.TB, configure func(*config.Config)) (*gossh.Session, func() error) {
 	tb.Helper()
 	is := is.New(tb)
 	dp := tb.TempDir()
@@ -63,6 +82,9 @@ func setup(tb testing.TB) (*gossh.Session, func() error) {
 	})
 	ctx := context.TODO()
 	cfg := config.DefaultConfig()
+	if configure != nil {
+		configure(cfg)
+	}
 	if err := cfg.Validate(); err != nil {
 		log.Fatal(err)
 	}
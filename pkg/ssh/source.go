@@ -0,0 +1,81 @@
+package ssh
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"sync"
+)
+
+// unknownSource is the source name used for connections that don't match any
+// configured network.
+const unknownSource = "unknown"
+
+type sourceNet struct {
+	name  string
+	ipnet *net.IPNet
+}
+
+// sourceMatcher maps connection addresses to configured source names.
+// It is safe for concurrent use and can be reloaded at runtime.
+type sourceMatcher struct {
+	mu   sync.RWMutex
+	nets []sourceNet
+}
+
+// Set replaces the source mapping with the given CIDR to name mapping.
+func (m *sourceMatcher) Set(sources map[string]string) error {
+	nets := make([]sourceNet, 0, len(sources))
+	for cidr, name := range sources {
+		_, ipnet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return fmt.Errorf("invalid ssh source %q: %w", cidr, err)
+		}
+		nets = append(nets, sourceNet{name: name, ipnet: ipnet})
+	}
+
+	// Most specific networks first so they take precedence.
+	sort.Slice(nets, func(i, j int) bool {
+		oi, _ := nets[i].ipnet.Mask.Size()
+		oj, _ := nets[j].ipnet.Mask.Size()
+		return oi > oj
+	})
+
+	m.mu.Lock()
+	m.nets = nets
+	m.mu.Unlock()
+
+	return nil
+}
+
+// Source returns the source name for the given address.
+func (m *sourceMatcher) Source(addr net.Addr) string {
+	var ip net.IP
+	switch a := addr.(type) {
+	case *net.TCPAddr:
+		ip = a.IP
+	default:
+		if addr == nil {
+			return unknownSource
+		}
+		host, _, err := net.SplitHostPort(addr.String())
+		if err != nil {
+			return unknownSource
+		}
+		ip = net.ParseIP(host)
+	}
+
+	if ip == nil {
+		return unknownSource
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, n := range m.nets {
+		if n.ipnet.Contains(ip) {
+			return n.name
+		}
+	}
+
+	return unknownSource
+}
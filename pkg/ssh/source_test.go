@@ -0,0 +1,33 @@
+package ssh
+
+import (
+	"net"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestSourceMatcher(t *testing.T) {
+	is := is.New(t)
+	var m sourceMatcher
+	is.NoErr(m.Set(map[string]string{
+		"10.0.0.0/8":  "office",
+		"10.1.0.0/16": "vpn",
+	}))
+
+	addr := func(ip string) net.Addr {
+		return &net.TCPAddr{IP: net.ParseIP(ip), Port: 22}
+	}
+
+	is.Equal(m.Source(addr("10.2.3.4")), "office")
+	is.Equal(m.Source(addr("10.1.3.4")), "vpn")
+	is.Equal(m.Source(addr("192.168.1.1")), unknownSource)
+	is.Equal(m.Source(nil), unknownSource)
+
+	// Reloading replaces the previous mapping.
+	is.NoErr(m.Set(map[string]string{"192.168.0.0/16": "home"}))
+	is.Equal(m.Source(addr("192.168.1.1")), "home")
+	is.Equal(m.Source(addr("10.2.3.4")), unknownSource)
+
+	is.True(m.Set(map[string]string{"bad": "x"}) != nil)
+}
@@ -6,16 +6,22 @@ import (
 	"net"
 	"os"
 	"runtime"
+	"slices"
 	"strconv"
 	"time"
 
 	"github.com/charmbracelet/keygen"
 	"github.com/charmbracelet/log"
+	"github.com/charmbracelet/soft-serve/pkg/access"
 	"github.com/charmbracelet/soft-serve/pkg/backend"
 	"github.com/charmbracelet/soft-serve/pkg/config"
 	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/geoblock"
+	logr "github.com/charmbracelet/soft-serve/pkg/log"
 	"github.com/charmbracelet/soft-serve/pkg/proto"
+	"github.com/charmbracelet/soft-serve/pkg/proxyproto"
 	"github.com/charmbracelet/soft-serve/pkg/store"
+	"github.com/charmbracelet/soft-serve/pkg/tracing"
 	"github.com/charmbracelet/soft-serve/pkg/ui/common"
 	"github.com/charmbracelet/ssh"
 	"github.com/charmbracelet/wish"
@@ -23,7 +29,10 @@ import (
 	rm "github.com/charmbracelet/wish/recover"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	gossh "golang.org/x/crypto/ssh"
+	"golang.org/x/sync/errgroup"
 )
 
 var (
@@ -32,23 +41,58 @@ var (
 		Subsystem: "ssh",
 		Name:      "public_key_auth_total",
 		Help:      "The total number of public key auth requests",
-	}, []string{"allowed"})
+	}, []string{"allowed", "source"})
 
 	keyboardInteractiveCounter = promauto.NewCounterVec(prometheus.CounterOpts{
 		Namespace: "soft_serve",
 		Subsystem: "ssh",
 		Name:      "keyboard_interactive_auth_total",
 		Help:      "The total number of keyboard interactive auth requests",
-	}, []string{"allowed"})
+	}, []string{"allowed", "outcome", "source"})
+
+	preAuthTimeoutCounter = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: "soft_serve",
+		Subsystem: "ssh",
+		Name:      "pre_auth_timeout_total",
+		Help:      "The total number of connections closed for not authenticating in time",
+	})
 )
 
 // SSHServer is a SSH server that implements the git protocol.
 type SSHServer struct { // nolint: revive
-	srv    *ssh.Server
-	cfg    *config.Config
-	be     *backend.Backend
-	ctx    context.Context
-	logger *log.Logger
+	srv     *ssh.Server
+	cfg     *config.Config
+	be      *backend.Backend
+	ctx     context.Context
+	logger  *log.Logger
+	sources sourceMatcher
+	geo     *geoblock.Blocker
+
+	// algorithms are the key exchange, cipher, and MAC algorithms offered
+	// to clients.
+	algorithms serverAlgorithms
+
+	// hostKeys are the current host key and the retired ones still
+	// accepted, newest first.
+	hostKeys []HostKey
+
+	// sessions are the active sessions.
+	sessions sessionRegistry
+
+	// interactiveLockout tracks the keyboard-interactive authentication
+	// failures of client addresses.
+	interactiveLockout interactiveLockout
+
+	// maxStartups are the thresholds of the unauthenticated connections
+	// throttling.
+	maxStartups config.MaxStartups
+
+	// startups tracks the unauthenticated connections.
+	startups startupThrottle
+
+	// pendingInvites are the invite codes entered for unregistered keys,
+	// registered once the clients sign in with them.
+	pendingInvites pendingInvites
 }
 
 // NewSSHServer returns a new SSHServer.
@@ -65,19 +109,70 @@ func NewSSHServer(ctx context.Context) (*SSHServer, error) {
 		ctx:    ctx,
 		be:     be,
 		logger: logger,
+		geo:    geoblock.FromContext(ctx),
+	}
+
+	if err := s.sources.Set(cfg.SSH.Sources); err != nil {
+		return nil, err
+	}
+
+	if err := validateAllowedCommands(cfg); err != nil {
+		return nil, err
+	}
+
+	s.maxStartups, err = config.ParseMaxStartups(cfg.SSH.MaxStartups)
+	if err != nil {
+		return nil, err
+	}
+
+	s.algorithms = defaultServerAlgorithms
+	if cfg.SSH.DeprecatedAlgorithmsAction == config.DeprecatedAlgorithmsDeny {
+		s.algorithms = s.algorithms.without(cfg.SSH.DeprecatedAlgorithms)
+		if err := s.algorithms.validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	// Create host ssh key
+	if _, err := os.Stat(cfg.SSH.KeyPath); err != nil && os.IsNotExist(err) {
+		_, err := keygen.New(cfg.SSH.KeyPath, keygen.WithKeyType(keygen.Ed25519), keygen.WithWrite())
+		if err != nil {
+			return nil, fmt.Errorf("host ssh key: %w", err)
+		}
+	}
+
+	s.hostKeys, err = HostKeys(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("host ssh key: %w", err)
+	}
+
+	// BubbleTea middleware, handling the pty sessions the CLI middleware
+	// passes on. It's left out when the TUI is disabled, since the CLI
+	// middleware then handles every session.
+	tui := func(sh ssh.Handler) ssh.Handler { return sh }
+	if cfg.TUI.Enabled {
+		tui = bm.MiddlewareWithProgramHandler(SessionHandler, common.DefaultColorProfile)
 	}
 
 	mw := []wish.Middleware{
 		rm.MiddlewareWithLogger(
 			logger,
 			// BubbleTea middleware.
-			bm.MiddlewareWithProgramHandler(SessionHandler, common.DefaultColorProfile),
+			tui,
 			// CLI middleware.
 			CommandMiddleware,
 			// Logging middleware.
-			LoggingMiddleware,
+			LoggingMiddleware(logr.SamplerFromContext(ctx), logr.SyslogFromContext(ctx)),
 			// Context middleware.
 			ContextMiddleware(cfg, dbx, datastore, be, logger),
+			// Host keys middleware.
+			s.HostKeysMiddleware,
+			// Sessions middleware.
+			s.SessionsMiddleware,
+			// Deprecated algorithms middleware.
+			s.AlgorithmsMiddleware,
+			// Invite middleware, run after the authentication.
+			s.InviteMiddleware,
 			// Authentication middleware.
 			// gossh.PublicKeyHandler doesn't guarantee that the public key
 			// is in fact the one used for authentication, so we need to
@@ -89,8 +184,9 @@ func NewSSHServer(ctx context.Context) (*SSHServer, error) {
 	opts := []ssh.Option{
 		ssh.PublicKeyAuth(s.PublicKeyHandler),
 		ssh.KeyboardInteractiveAuth(s.KeyboardInteractiveHandler),
+		ssh.WrapConn(s.ConnCallback),
 		wish.WithAddress(cfg.SSH.ListenAddr),
-		wish.WithHostKeyPath(cfg.SSH.KeyPath),
+		withHostKeys(s.hostKeys),
 		wish.WithMiddleware(mw...),
 	}
 	if runtime.GOOS == "windows" {
@@ -103,16 +199,20 @@ func NewSSHServer(ctx context.Context) (*SSHServer, error) {
 		return nil, err
 	}
 
-	if config.IsDebug() {
-		s.srv.ServerConfigCallback = func(_ ssh.Context) *gossh.ServerConfig {
-			return &gossh.ServerConfig{
-				AuthLogCallback: func(conn gossh.ConnMetadata, method string, err error) {
-					logger.Debug("authentication", "user", conn.User(), "method", method, "err", err)
-				},
-			}
+	for _, k := range s.hostKeys {
+		if k.Retired() {
+			logger.Info("accepting retired host key", "fingerprint", k.Fingerprint(), "expires", k.Expires)
+			time.AfterFunc(time.Until(k.Expires), s.refreshHostKeys)
 		}
 	}
 
+	if s.srv.RequestHandlers == nil {
+		s.srv.RequestHandlers = map[string]ssh.RequestHandler{}
+	}
+	s.srv.RequestHandlers[hostKeysProveRequest] = s.HostKeysProveHandler
+
+	s.srv.ServerConfigCallback = s.serverConfig
+
 	if cfg.SSH.MaxTimeout > 0 {
 		s.srv.MaxTimeout = time.Duration(cfg.SSH.MaxTimeout) * time.Second
 	}
@@ -121,6 +221,8 @@ func NewSSHServer(ctx context.Context) (*SSHServer, error) {
 		s.srv.IdleTimeout = time.Duration(cfg.SSH.IdleTimeout) * time.Second
 	}
 
+	go s.watchSessions(ctx)
+
 	// Create client ssh key
 	if _, err := os.Stat(cfg.SSH.ClientKeyPath); err != nil && os.IsNotExist(err) {
 		_, err := keygen.New(cfg.SSH.ClientKeyPath, keygen.WithKeyType(keygen.Ed25519), keygen.WithWrite())
@@ -132,9 +234,54 @@ func NewSSHServer(ctx context.Context) (*SSHServer, error) {
 	return s, nil
 }
 
-// ListenAndServe starts the SSH server.
+// ListenAndServe starts the SSH server on the listen address, and the
+// additional listeners limiting the access of their clients.
 func (s *SSHServer) ListenAndServe() error {
-	return s.srv.ListenAndServe()
+	listeners := make([]net.Listener, 0, len(s.cfg.SSH.Listeners)+1)
+	closeAll := func() {
+		for _, l := range listeners {
+			l.Close() // nolint: errcheck
+		}
+	}
+
+	addrs := make([]string, 0, len(s.cfg.SSH.Listeners))
+	for addr := range s.cfg.SSH.Listeners {
+		addrs = append(addrs, addr)
+	}
+	slices.Sort(addrs)
+
+	for _, addr := range append([]string{s.srv.Addr}, addrs...) {
+		l, err := net.Listen("tcp", addr)
+		if err != nil {
+			closeAll()
+			return err
+		}
+
+		if s.cfg.SSH.ProxyProtocol {
+			trusted, err := config.ParseIPNets(s.cfg.SSH.TrustedProxies)
+			if err != nil {
+				l.Close() // nolint: errcheck
+				closeAll()
+				return err
+			}
+			l = proxyproto.NewListener(l, trusted)
+		}
+		if level, ok := s.cfg.SSH.Listeners[addr]; ok {
+			l = &limitedListener{Listener: l, level: access.ParseAccessLevel(level)}
+			s.logger.Info("listening with limited access", "addr", addr, "access", level)
+		}
+		listeners = append(listeners, l)
+	}
+
+	var errg errgroup.Group
+	for _, l := range listeners {
+		l := l
+		errg.Go(func() error {
+			return s.srv.Serve(l)
+		})
+	}
+
+	return errg.Wait()
 }
 
 // Serve starts the SSH server on the given net.Listener.
@@ -142,6 +289,12 @@ func (s *SSHServer) Serve(l net.Listener) error {
 	return s.srv.Serve(l)
 }
 
+// SetSources replaces the CIDR to source name mapping used to label
+// authentication metrics.
+func (s *SSHServer) SetSources(sources map[string]string) error {
+	return s.sources.Set(sources)
+}
+
 // Close closes the SSH server.
 func (s *SSHServer) Close() error {
 	return s.srv.Close()
@@ -152,6 +305,58 @@ func (s *SSHServer) Shutdown(ctx context.Context) error {
 	return s.srv.Shutdown(ctx)
 }
 
+// ConnCallback closes connections that don't complete the handshake and
+// authentication within the pre-auth timeout. This is separate from the idle
+// timeout, a client sending data slowly is never idle. It also checks the
+// algorithms negotiated by the client, closes connections from denied
+// regions, throttles new connections when too many haven't authenticated
+// yet, and limits the access of the clients of limited listeners.
+func (s *SSHServer) ConnCallback(ctx ssh.Context, conn net.Conn) net.Conn {
+	if !s.geo.Allow(ctx, conn.RemoteAddr().String(), "ssh") {
+		return nil
+	}
+
+	if !s.startups.admit(s.maxStartups) {
+		startupsDroppedCounter.Inc()
+		s.logger.Debug("dropping connection, too many unauthenticated connections", "remote-addr", conn.RemoteAddr())
+		return nil
+	}
+
+	startConnSpan(ctx, conn.RemoteAddr())
+
+	// Clients of a limited listener have its access level at most.
+	if lc, ok := conn.(*limitedConn); ok {
+		ctx.SetValue(access.MaxContextKey, lc.level)
+	}
+
+	conn = s.checkAlgorithmsConn(ctx, conn)
+
+	// The startup slot is released once the connection authenticates, or
+	// when it's closed before.
+	release := s.startups.release()
+	ctx.SetValue(contextKeyStartup, release)
+	conn = &startupConn{Conn: conn, release: release}
+
+	timeout := time.Duration(s.cfg.SSH.PreAuthTimeout) * time.Second
+	if timeout <= 0 {
+		return conn
+	}
+
+	time.AfterFunc(timeout, func() {
+		// The connection is set in the context once authenticated, and the
+		// context is canceled once the connection is closed.
+		if ctx.Err() != nil || ctx.Value(ssh.ContextKeyConn) != nil {
+			return
+		}
+
+		preAuthTimeoutCounter.Inc()
+		s.logger.Debug("closing connection that didn't authenticate in time", "remote-addr", conn.RemoteAddr(), "timeout", timeout)
+		conn.Close() // nolint: errcheck
+	})
+
+	return conn
+}
+
 func initializePermissions(ctx ssh.Context) {
 	perms := ctx.Permissions()
 	if perms == nil || perms.Permissions == nil {
@@ -171,12 +376,52 @@ func (s *SSHServer) PublicKeyHandler(ctx ssh.Context, pk ssh.PublicKey) (allowed
 		return false
 	}
 
+	_, span := tracing.Start(connContext(ctx), "ssh.auth", trace.WithAttributes(
+		attribute.String("method", "publickey"),
+		attribute.String("fingerprint", gossh.FingerprintSHA256(pk)),
+	))
+
 	allowed = true
 	defer func(allowed *bool) {
-		publicKeyCounter.WithLabelValues(strconv.FormatBool(*allowed)).Inc()
+		publicKeyCounter.WithLabelValues(
+			strconv.FormatBool(*allowed),
+			s.sources.Source(ctx.RemoteAddr()),
+		).Inc()
+		if user := proto.UserFromContext(ctx); user != nil && *allowed {
+			span.SetAttributes(attribute.String("user", user.Username()))
+		}
+		span.SetAttributes(attribute.Bool("allowed", *allowed))
+		tracing.End(span, nil)
 	}(&allowed)
 
+	if s.be.KeyDenied(ctx, pk) {
+		s.logger.Debug("rejecting denied ssh key", "fingerprint", gossh.FingerprintSHA256(pk), "remote-addr", ctx.RemoteAddr())
+		allowed = false
+		return
+	}
+
 	user, _ := s.be.UserByPublicKey(ctx, pk)
+
+	// Unregistered keys with a pending invite code are let in to prove the
+	// client holds them, the InviteMiddleware registers them.
+	pending, hasPending := s.pendingInvites.get(gossh.FingerprintSHA256(pk), time.Now())
+	if user == nil && !hasPending && s.offerInvite(ctx, pk) {
+		s.logger.Debug("offering invite for unregistered ssh key", "fingerprint", gossh.FingerprintSHA256(pk), "remote-addr", ctx.RemoteAddr())
+		allowed = false
+		return
+	}
+
+	var username string
+	if user != nil {
+		username = user.Username()
+	} else if hasPending {
+		username = pending.username
+	}
+	if !s.usernameAllowed(ctx.User(), username) {
+		s.logger.Debug("rejecting ssh username", "user", ctx.User(), "username", username, "remote-addr", ctx.RemoteAddr())
+		allowed = false
+		return
+	}
 	if user != nil {
 		ctx.SetValue(proto.ContextKeyUser, user)
 	}
@@ -193,11 +438,69 @@ func (s *SSHServer) PublicKeyHandler(ctx ssh.Context, pk ssh.PublicKey) (allowed
 	return
 }
 
+// usernameAllowed returns whether a client can connect with the SSH username
+// sshUser. username is the username of the user the client authenticated as,
+// empty for anonymous clients. Any SSH username is allowed unless strict
+// usernames are enabled.
+func (s *SSHServer) usernameAllowed(sshUser string, username string) bool {
+	if !s.cfg.SSH.StrictUsernames {
+		return true
+	}
+
+	if username != "" && sshUser == username {
+		return true
+	}
+
+	return slices.Contains(s.cfg.SSH.AllowedUsernames, sshUser)
+}
+
 // KeyboardInteractiveHandler handles keyboard interactive authentication.
 // This is used after all public key authentication has failed.
-func (s *SSHServer) KeyboardInteractiveHandler(ctx ssh.Context, _ gossh.KeyboardInteractiveChallenge) bool {
-	ac := s.be.AllowKeyless(ctx)
-	keyboardInteractiveCounter.WithLabelValues(strconv.FormatBool(ac)).Inc()
+func (s *SSHServer) KeyboardInteractiveHandler(ctx ssh.Context, challenge gossh.KeyboardInteractiveChallenge) bool {
+	start := time.Now()
+	ip := addrIP(ctx.RemoteAddr())
+
+	_, span := tracing.Start(connContext(ctx), "ssh.auth", trace.WithAttributes(
+		attribute.String("method", "keyboard-interactive"),
+	))
+
+	var ac bool
+	var outcome string
+	defer func() {
+		keyboardInteractiveCounter.WithLabelValues(strconv.FormatBool(ac), outcome, s.sources.Source(ctx.RemoteAddr())).Inc()
+		keyboardInteractiveDuration.WithLabelValues(outcome).Observe(time.Since(start).Seconds())
+		span.SetAttributes(attribute.Bool("allowed", ac), attribute.String("outcome", outcome))
+		tracing.End(span, nil)
+	}()
+
+	// Addresses failing too often aren't prompted anymore, so they can't
+	// brute-force invite codes.
+	if s.interactiveLockout.lockedOut(ip, start) {
+		s.logger.Debug("rejecting locked out keyboard interactive client", "remote-addr", ctx.RemoteAddr())
+		outcome = interactiveOutcomeLockedOut
+		return false
+	}
+
+	// Clients with an unregistered key are asked for an invite code first.
+	ac, prompted := s.redeemInvite(ctx, challenge)
+	switch {
+	case prompted && ac:
+		outcome = interactiveOutcomeInvite
+		s.interactiveLockout.succeed(ip)
+	case prompted:
+		outcome = interactiveOutcomeInvalidInvite
+		lockout := time.Duration(s.cfg.SSH.InteractiveLockout) * time.Second
+		if s.interactiveLockout.fail(ip, time.Now(), s.cfg.SSH.InteractiveMaxFailures, lockout) {
+			s.logger.Warn("locking out client after repeated keyboard interactive failures", "remote-addr", ctx.RemoteAddr(), "duration", lockout)
+			keyboardInteractiveLockoutCounter.Inc()
+		}
+	default:
+		ac = s.be.AllowKeyless(ctx) && s.usernameAllowed(ctx.User(), "") && !s.be.KeyDenied(ctx, nil)
+		outcome = interactiveOutcomeRejected
+		if ac {
+			outcome = interactiveOutcomeKeyless
+		}
+	}
 
 	// If we're allowing keyless access, reset the public key fingerprint
 	if ac {
@@ -0,0 +1,97 @@
+package ssh
+
+import (
+	"math/rand"
+	"net"
+	"sync"
+
+	"github.com/charmbracelet/soft-serve/pkg/config"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	unauthenticatedGauge = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: "soft_serve",
+		Subsystem: "ssh",
+		Name:      "unauthenticated_connections",
+		Help:      "The number of connections that haven't authenticated yet",
+	})
+
+	startupsDroppedCounter = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: "soft_serve",
+		Subsystem: "ssh",
+		Name:      "startups_dropped_total",
+		Help:      "The total number of new connections dropped because of too many unauthenticated connections",
+	})
+)
+
+// contextKeyStartup holds the function releasing the startup slot of a
+// connection once it authenticates.
+var contextKeyStartup = &struct{ string }{"startup"}
+
+// startupThrottle throttles the connections that haven't authenticated yet,
+// like OpenSSH's MaxStartups, so that connection floods can't exhaust the
+// authentication path.
+type startupThrottle struct {
+	mu    sync.Mutex
+	count int
+	// intn returns a random number in [0, n), it's rand.Intn unless set.
+	intn func(n int) int
+}
+
+// admit returns whether a new unauthenticated connection is accepted given
+// the thresholds, and counts it if so. Zero thresholds accept every
+// connection. Accepted connections must be released once they authenticate
+// or close.
+func (t *startupThrottle) admit(m config.MaxStartups) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if m.Full > 0 && t.count >= m.Start {
+		if t.count >= m.Full {
+			return false
+		}
+
+		// The drop rate grows linearly from rate at start to 100 at full.
+		p := m.Rate + (100-m.Rate)*(t.count-m.Start)/(m.Full-m.Start)
+		intn := t.intn
+		if intn == nil {
+			intn = rand.Intn
+		}
+		if intn(100) < p {
+			return false
+		}
+	}
+
+	t.count++
+	unauthenticatedGauge.Inc()
+	return true
+}
+
+// release returns a function releasing an admitted connection. It can be
+// called any number of times, the connection is only released once.
+func (t *startupThrottle) release() func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.count--
+			unauthenticatedGauge.Dec()
+		})
+	}
+}
+
+// startupConn is an admitted connection releasing its startup slot when
+// closed before authenticating.
+type startupConn struct {
+	net.Conn
+	release func()
+}
+
+// Close implements net.Conn.
+func (c *startupConn) Close() error {
+	c.release()
+	return c.Conn.Close()
+}
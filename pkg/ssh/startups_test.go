@@ -0,0 +1,107 @@
+package ssh
+
+import (
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/keygen"
+	"github.com/charmbracelet/log"
+	"github.com/charmbracelet/soft-serve/pkg/config"
+	"github.com/charmbracelet/ssh"
+	"github.com/matryer/is"
+	gossh "golang.org/x/crypto/ssh"
+)
+
+func TestStartupThrottleAdmit(t *testing.T) {
+	is := is.New(t)
+	m := config.MaxStartups{Start: 2, Rate: 50, Full: 4}
+
+	var roll int
+	th := startupThrottle{intn: func(int) int { return roll }}
+	is.True(th.admit(m))
+	is.True(th.admit(m))
+
+	// At start, rate percent of the connections are dropped.
+	roll = 49
+	is.True(!th.admit(m))
+	roll = 50
+	is.True(th.admit(m))
+
+	// Halfway to full, 75 percent are.
+	roll = 74
+	is.True(!th.admit(m))
+	roll = 75
+	is.True(th.admit(m))
+
+	// At full, every one is, until a connection is released.
+	roll = 99
+	is.True(!th.admit(m))
+	release := th.release()
+	release()
+	release()
+	is.Equal(th.count, 3)
+	is.True(th.admit(m))
+
+	// Zero thresholds admit everything.
+	for i := 0; i < 10; i++ {
+		is.True(th.admit(config.MaxStartups{}))
+	}
+}
+
+func TestMaxStartups(t *testing.T) {
+	is := is.New(t)
+	cfg := config.DefaultConfig()
+	s := &SSHServer{cfg: cfg, logger: log.New(io.Discard), maxStartups: config.MaxStartups{Start: 1, Rate: 100, Full: 1}}
+
+	kp, err := keygen.New("", keygen.WithKeyType(keygen.Ed25519))
+	is.NoErr(err)
+
+	srv := &ssh.Server{
+		Handler:              func(ssh.Session) {},
+		ConnCallback:         s.ConnCallback,
+		HostSigners:          []ssh.Signer{kp.Signer()},
+		ServerConfigCallback: s.serverConfig,
+	}
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	is.NoErr(err)
+	go srv.Serve(l)                   // nolint: errcheck
+	t.Cleanup(func() { srv.Close() }) // nolint: errcheck
+
+	// An unauthenticated connection takes the only startup slot.
+	conn, err := net.Dial("tcp", l.Addr().String())
+	is.NoErr(err)
+	is.NoErr(conn.SetReadDeadline(time.Now().Add(5 * time.Second)))
+	_, err = conn.Read(make([]byte, 1))
+	is.NoErr(err) // the server sends its version
+
+	dial := func() (*gossh.Client, error) {
+		return gossh.Dial("tcp", l.Addr().String(), &gossh.ClientConfig{
+			User:            "user",
+			HostKeyCallback: gossh.InsecureIgnoreHostKey(), // nolint: gosec
+			Timeout:         5 * time.Second,
+		})
+	}
+
+	// So new connections are dropped.
+	_, err = dial()
+	is.True(err != nil)
+
+	// Until it's closed.
+	is.NoErr(conn.Close())
+	var client *gossh.Client
+	for i := 0; i < 50; i++ {
+		if client, err = dial(); err == nil {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	is.NoErr(err)
+	defer client.Close() // nolint: errcheck
+
+	// Authenticated connections don't count.
+	client2, err := dial()
+	is.NoErr(err)
+	client2.Close() // nolint: errcheck
+}
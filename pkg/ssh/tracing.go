@@ -0,0 +1,47 @@
+package ssh
+
+import (
+	"context"
+	"net"
+
+	"github.com/charmbracelet/soft-serve/pkg/tracing"
+	"github.com/charmbracelet/ssh"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// contextKeySpan holds the span of a connection.
+var contextKeySpan = &struct{ string }{"span"}
+
+// startConnSpan starts the span of a connection, ended once the connection
+// is closed. The authentication and the sessions of the connection are
+// traced as its children.
+func startConnSpan(ctx ssh.Context, addr net.Addr) {
+	_, span := tracing.Start(ctx, "ssh.connection",
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(attribute.String("remote_addr", addr.String())),
+	)
+	if !span.IsRecording() {
+		return
+	}
+
+	ctx.SetValue(contextKeySpan, span)
+	go func() {
+		// The context is canceled once the connection is closed.
+		<-ctx.Done()
+		if user := ctx.User(); user != "" {
+			span.SetAttributes(attribute.String("ssh_user", user))
+		}
+		tracing.End(span, nil)
+	}()
+}
+
+// connContext returns a context holding the span of a connection, the
+// parent of the spans of the connection.
+func connContext(ctx ssh.Context) context.Context {
+	if span, ok := ctx.Value(contextKeySpan).(trace.Span); ok {
+		return trace.ContextWithSpan(ctx, span)
+	}
+
+	return ctx
+}
@@ -2,6 +2,7 @@ package ssh
 
 import (
 	"errors"
+	"time"
 
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
@@ -44,8 +45,12 @@ type UI struct {
 	footer      *footer.Footer
 	showFooter  bool
 	error       error
+	lastInput   time.Time
 }
 
+// idleMsg checks whether the session has been idle for too long.
+type idleMsg struct{}
+
 // NewUI returns a new UI model.
 func NewUI(c common.Common, initialRepo string) *UI {
 	serverName := c.Config().Name
@@ -140,6 +145,8 @@ func (ui *UI) Init() tea.Cmd {
 		repo.NewLog(ui.common),
 		repo.NewRefs(ui.common, git.RefsHeads),
 		repo.NewRefs(ui.common, git.RefsTags),
+		repo.NewReleases(ui.common),
+		repo.NewIssues(ui.common),
 	)
 	ui.SetSize(ui.common.Width, ui.common.Height)
 	cmds := make([]tea.Cmd, 0)
@@ -150,6 +157,8 @@ func (ui *UI) Init() tea.Cmd {
 	if ui.initialRepo != "" {
 		cmds = append(cmds, ui.initialRepoCmd(ui.initialRepo))
 	}
+	ui.lastInput = time.Now()
+	cmds = append(cmds, ui.idleCmd(ui.idleTimeout()))
 	ui.state = readyState
 	ui.SetSize(ui.common.Width, ui.common.Height)
 	return tea.Batch(cmds...)
@@ -179,7 +188,22 @@ func (ui *UI) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				cmds = append(cmds, cmd)
 			}
 		}
+	case idleMsg:
+		timeout := ui.idleTimeout()
+		idle := time.Since(ui.lastInput)
+		if idle < timeout {
+			return ui, ui.idleCmd(timeout - idle)
+		}
+		ui.common.Logger.Debugf("ui: closing idle session after %s", idle)
+		ui.common.Zone.Close()
+		return ui, tea.Quit
+	case selection.ItemsMsg:
+		// Repositories keep loading on the repository page.
+		m, cmd := ui.pages[selectionPage].Update(msg)
+		ui.pages[selectionPage] = m.(common.Component)
+		return ui, cmd
 	case tea.KeyMsg, tea.MouseMsg:
+		ui.lastInput = time.Now()
 		switch msg := msg.(type) {
 		case tea.KeyMsg:
 			switch {
@@ -303,17 +327,33 @@ func (ui *UI) openRepo(rn string) (proto.Repository, error) {
 
 	ctx := ui.common.Context()
 	be := ui.common.Backend()
-	repos, err := be.Repositories(ctx)
-	if err != nil {
-		ui.common.Logger.Debugf("ui: failed to list repos: %v", err)
+	r, err := be.Repository(ctx, rn)
+	if errors.Is(err, proto.ErrRepoNotFound) {
+		return nil, common.ErrMissingRepo
+	} else if err != nil {
+		ui.common.Logger.Debugf("ui: failed to open repo: %v", err)
 		return nil, err
 	}
-	for _, r := range repos {
-		if r.Name() == rn {
-			return r, nil
-		}
+	return r, nil
+}
+
+// idleTimeout returns the configured TUI idle timeout.
+func (ui *UI) idleTimeout() time.Duration {
+	cfg := ui.common.Config()
+	if cfg == nil {
+		return 0
+	}
+	return time.Duration(cfg.TUI.IdleTimeout) * time.Second
+}
+
+// idleCmd checks for idleness after d, if an idle timeout is set.
+func (ui *UI) idleCmd(d time.Duration) tea.Cmd {
+	if ui.idleTimeout() <= 0 {
+		return nil
 	}
-	return nil, common.ErrMissingRepo
+	return tea.Tick(d, func(time.Time) tea.Msg {
+		return idleMsg{}
+	})
 }
 
 func (ui *UI) setRepoCmd(rn string) tea.Cmd {
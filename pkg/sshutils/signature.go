@@ -0,0 +1,110 @@
+package sshutils
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"hash"
+
+	gossh "golang.org/x/crypto/ssh"
+)
+
+// sshsigMagic is the preamble of SSH signatures as defined in
+// https://github.com/openssh/openssh-portable/blob/master/PROTOCOL.sshsig
+const sshsigMagic = "SSHSIG"
+
+// sshsigPEMType is the PEM block type of armored SSH signatures.
+const sshsigPEMType = "SSH SIGNATURE"
+
+// ErrInvalidSignature is returned when an SSH signature can't be verified.
+var ErrInvalidSignature = errors.New("invalid signature")
+
+// sshsig is the SSH signature blob, without the magic preamble.
+type sshsig struct {
+	Version       uint32
+	PublicKey     []byte
+	Namespace     string
+	Reserved      string
+	HashAlgorithm string
+	Signature     []byte
+}
+
+// sshsigSignedData is the data signed by an SSH signature, without the magic
+// preamble.
+type sshsigSignedData struct {
+	Namespace     string
+	Reserved      string
+	HashAlgorithm string
+	Hash          []byte
+}
+
+// VerifySignature verifies an armored SSH signature of message in the given
+// namespace, as produced by `ssh-keygen -Y sign -n namespace`. It returns the
+// public key that made the signature. It's up to the caller to check whether
+// the key is trusted.
+func VerifySignature(message, armored []byte, namespace string) (gossh.PublicKey, error) {
+	block, _ := pem.Decode(armored)
+	if block == nil || block.Type != sshsigPEMType {
+		return nil, fmt.Errorf("%w: not an SSH signature", ErrInvalidSignature)
+	}
+
+	blob := block.Bytes
+	if !bytes.HasPrefix(blob, []byte(sshsigMagic)) {
+		return nil, fmt.Errorf("%w: missing preamble", ErrInvalidSignature)
+	}
+
+	var sig sshsig
+	if err := gossh.Unmarshal(blob[len(sshsigMagic):], &sig); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSignature, err)
+	}
+
+	if sig.Version != 1 {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrInvalidSignature, sig.Version)
+	}
+
+	if sig.Namespace != namespace {
+		return nil, fmt.Errorf("%w: namespace %q, expected %q", ErrInvalidSignature, sig.Namespace, namespace)
+	}
+
+	var h hash.Hash
+	switch sig.HashAlgorithm {
+	case "sha256":
+		h = sha256.New()
+	case "sha512":
+		h = sha512.New()
+	default:
+		return nil, fmt.Errorf("%w: unsupported hash algorithm %q", ErrInvalidSignature, sig.HashAlgorithm)
+	}
+
+	pk, err := gossh.ParsePublicKey(sig.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSignature, err)
+	}
+
+	var s gossh.Signature
+	if err := gossh.Unmarshal(sig.Signature, &s); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSignature, err)
+	}
+
+	// SHA-1 RSA signatures are not allowed in SSH signatures.
+	if s.Format == gossh.KeyAlgoRSA {
+		return nil, fmt.Errorf("%w: unsupported signature algorithm %q", ErrInvalidSignature, s.Format)
+	}
+
+	h.Write(message) // nolint: errcheck
+	signed := append([]byte(sshsigMagic), gossh.Marshal(sshsigSignedData{
+		Namespace:     sig.Namespace,
+		Reserved:      sig.Reserved,
+		HashAlgorithm: sig.HashAlgorithm,
+		Hash:          h.Sum(nil),
+	})...)
+
+	if err := pk.Verify(signed, &s); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSignature, err)
+	}
+
+	return pk, nil
+}
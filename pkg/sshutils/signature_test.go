@@ -0,0 +1,59 @@
+package sshutils
+
+import (
+	"errors"
+	"testing"
+)
+
+// Generated with `ssh-keygen -Y sign -n git -f key msg`.
+const (
+	testSignatureKey     = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIH4iD/ujBEkS8FABQ2Mt/sUyghnP1cib4wyFyr2lRTM5"
+	testSignatureMessage = "hello world\n"
+	testSignature        = `-----BEGIN SSH SIGNATURE-----
+U1NIU0lHAAAAAQAAADMAAAALc3NoLWVkMjU1MTkAAAAgfiIP+6MESRLwUAFDYy3+xTKCGc
+/VyJvjDIXKvaVFMzkAAAADZ2l0AAAAAAAAAAZzaGE1MTIAAABTAAAAC3NzaC1lZDI1NTE5
+AAAAQAwYTuZtUWZmDl559ym/5qcyYwA3KsTwasZTXMFoEbDjzuWL/PA11LT8nHr/lQOOjU
+p/PgKS7GrdeYtH+Sg/1wU=
+-----END SSH SIGNATURE-----
+`
+)
+
+func TestVerifySignature(t *testing.T) {
+	want, _, err := ParseAuthorizedKey(testSignatureKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name      string
+		message   string
+		signature string
+		namespace string
+		valid     bool
+	}{
+		{"valid", testSignatureMessage, testSignature, "git", true},
+		{"tampered message", "hello world!\n", testSignature, "git", false},
+		{"wrong namespace", testSignatureMessage, testSignature, "file", false},
+		{"not a signature", testSignatureMessage, "foobar", "git", false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			pk, err := VerifySignature([]byte(c.message), []byte(c.signature), c.namespace)
+			if !c.valid {
+				if !errors.Is(err, ErrInvalidSignature) {
+					t.Errorf("expected invalid signature, got %v", err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("expected valid signature, got %v", err)
+			}
+
+			if !KeysEqual(pk, want) {
+				t.Errorf("expected key %s, got %s", testSignatureKey, MarshalAuthorizedKey(pk))
+			}
+		})
+	}
+}
@@ -3,6 +3,10 @@ package sshutils
 import (
 	"bytes"
 	"context"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"fmt"
+	"strings"
 
 	"github.com/charmbracelet/ssh"
 	gossh "golang.org/x/crypto/ssh"
@@ -26,6 +30,46 @@ func MarshalAuthorizedKey(pk gossh.PublicKey) string {
 	return string(bytes.TrimSuffix(gossh.MarshalAuthorizedKey(pk), []byte("\n")))
 }
 
+// KeyFingerprint returns the SHA256 fingerprint of a public key in the format
+// of `ssh-keygen -l`, without the key comment, e.g.
+// "256 SHA256:Ld9wPyXzXz0W9i7Ptg6KoAldDsOQ33ILtUR8mIqUfQI (ED25519)".
+// It returns an empty string if pk is nil.
+func KeyFingerprint(pk gossh.PublicKey) string {
+	if pk == nil {
+		return ""
+	}
+
+	var bits int
+	if cpk, ok := pk.(gossh.CryptoPublicKey); ok {
+		switch k := cpk.CryptoPublicKey().(type) {
+		case *rsa.PublicKey:
+			bits = k.N.BitLen()
+		case *ecdsa.PublicKey:
+			bits = k.Curve.Params().BitSize
+		}
+	}
+
+	var typ string
+	switch t := pk.Type(); t {
+	case gossh.KeyAlgoED25519:
+		bits, typ = 256, "ED25519"
+	case gossh.KeyAlgoSKED25519:
+		bits, typ = 256, "ED25519-SK"
+	case gossh.KeyAlgoSKECDSA256:
+		bits, typ = 256, "ECDSA-SK"
+	case gossh.KeyAlgoRSA:
+		typ = "RSA"
+	case gossh.KeyAlgoDSA:
+		bits, typ = 1024, "DSA"
+	case gossh.KeyAlgoECDSA256, gossh.KeyAlgoECDSA384, gossh.KeyAlgoECDSA521:
+		typ = "ECDSA"
+	default:
+		typ = strings.ToUpper(t)
+	}
+
+	return fmt.Sprintf("%d %s (%s)", bits, gossh.FingerprintSHA256(pk), typ)
+}
+
 // KeysEqual returns whether the two public keys are equal.
 func KeysEqual(a, b gossh.PublicKey) bool {
 	return ssh.KeysEqual(a, b)
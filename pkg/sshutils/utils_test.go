@@ -1,6 +1,7 @@
 package sshutils
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/charmbracelet/keygen"
@@ -122,3 +123,35 @@ func TestKeysEqual(t *testing.T) {
 		}
 	}
 }
+
+func TestKeyFingerprint(t *testing.T) {
+	goodKey1, goodKey2 := generateKeys(t)
+	ecdsaKey, err := keygen.New("", keygen.WithKeyType(keygen.ECDSA))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		in     *keygen.SSHKeyPair
+		prefix string
+		suffix string
+	}{
+		{goodKey1, "256 SHA256:", " (ED25519)"},
+		{goodKey2, "4096 SHA256:", " (RSA)"},
+		{ecdsaKey, "384 SHA256:", " (ECDSA)"},
+	}
+	for _, c := range cases {
+		pk := c.in.PublicKey()
+		fp := KeyFingerprint(pk)
+		if !strings.HasPrefix(fp, c.prefix) || !strings.HasSuffix(fp, c.suffix) {
+			t.Errorf("KeyFingerprint() = %q, want %q...%q", fp, c.prefix, c.suffix)
+		}
+		if !strings.Contains(fp, ssh.FingerprintSHA256(pk)) {
+			t.Errorf("KeyFingerprint() = %q, missing %q", fp, ssh.FingerprintSHA256(pk))
+		}
+	}
+
+	if fp := KeyFingerprint(nil); fp != "" {
+		t.Errorf("KeyFingerprint(nil) = %q, want empty", fp)
+	}
+}
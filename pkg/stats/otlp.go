@@ -0,0 +1,58 @@
+package stats
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/charmbracelet/soft-serve/pkg/config"
+	"github.com/charmbracelet/soft-serve/pkg/version"
+	"github.com/prometheus/client_golang/prometheus"
+	promotel "go.opentelemetry.io/contrib/bridges/prometheus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+)
+
+// newOTLPExporter returns a meter provider periodically pushing the
+// Prometheus metrics to an OpenTelemetry collector. The metrics are read
+// from the gatherer, so they're defined once for both exporters.
+func newOTLPExporter(ctx context.Context, cfg config.OTLPConfig, g prometheus.Gatherer) (*sdkmetric.MeterProvider, error) {
+	exporter, err := newOTLPMetricExporter(ctx, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("create otlp exporter: %w", err)
+	}
+
+	reader := sdkmetric.NewPeriodicReader(exporter,
+		sdkmetric.WithInterval(time.Duration(cfg.Interval)*time.Second),
+		sdkmetric.WithProducer(promotel.NewMetricProducer(promotel.WithGatherer(g))),
+	)
+
+	return sdkmetric.NewMeterProvider(
+		sdkmetric.WithReader(reader),
+		sdkmetric.WithResource(resource.NewSchemaless(
+			attribute.String("service.name", "soft-serve"),
+			attribute.String("service.version", version.Version),
+		)),
+	), nil
+}
+
+// newOTLPMetricExporter returns an OTLP metric exporter for the configured
+// protocol.
+func newOTLPMetricExporter(ctx context.Context, cfg config.OTLPConfig) (sdkmetric.Exporter, error) {
+	switch cfg.Protocol {
+	case config.OTLPProtocolGRPC:
+		return otlpmetricgrpc.New(ctx, otlpmetricgrpc.WithEndpointURL(cfg.Endpoint))
+	default:
+		opts := []otlpmetrichttp.Option{otlpmetrichttp.WithEndpointURL(cfg.Endpoint)}
+		// Collectors receive metrics on /v1/metrics unless the endpoint has
+		// another path.
+		if u, err := url.Parse(cfg.Endpoint); err == nil && (u.Path == "" || u.Path == "/") {
+			opts = append(opts, otlpmetrichttp.WithURLPath("/v1/metrics"))
+		}
+		return otlpmetrichttp.New(ctx, opts...)
+	}
+}
@@ -0,0 +1,49 @@
+package stats
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/charmbracelet/soft-serve/pkg/config"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestOTLPExporter(t *testing.T) {
+	requests := make(chan *http.Request, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case requests <- r:
+		default:
+		}
+	}))
+	defer srv.Close()
+
+	reg := prometheus.NewRegistry()
+	counter := prometheus.NewCounter(prometheus.CounterOpts{Name: "test_total", Help: "A test counter"})
+	reg.MustRegister(counter)
+	counter.Inc()
+
+	ctx := context.Background()
+	cfg := config.OTLPConfig{Endpoint: srv.URL, Protocol: config.OTLPProtocolHTTP, Interval: 60}
+	provider, err := newOTLPExporter(ctx, cfg, roleGatherer{Gatherer: reg, role: "primary"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := provider.Shutdown(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case r := <-requests:
+		if r.URL.Path != "/v1/metrics" {
+			t.Errorf("expected metrics on /v1/metrics, got %q", r.URL.Path)
+		}
+		if ct := r.Header.Get("Content-Type"); ct != "application/x-protobuf" {
+			t.Errorf("expected protobuf metrics, got %q", ct)
+		}
+	default:
+		t.Fatal("expected the metrics to be exported on shutdown")
+	}
+}
@@ -2,50 +2,120 @@ package stats
 
 import (
 	"context"
+	"errors"
 	"net/http"
+	"sort"
 	"time"
 
 	"github.com/charmbracelet/soft-serve/pkg/config"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	dto "github.com/prometheus/client_model/go"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
 )
 
-// StatsServer is a server for collecting and reporting statistics.
+// StatsServer is a server for collecting and reporting statistics. It serves
+// the metrics to Prometheus, pushes them to an OpenTelemetry collector, or
+// both.
 type StatsServer struct { //nolint:revive
-	ctx    context.Context
-	cfg    *config.Config
-	server *http.Server
+	ctx      context.Context
+	cfg      *config.Config
+	server   *http.Server
+	provider *sdkmetric.MeterProvider
 }
 
 // NewStatsServer returns a new StatsServer.
 func NewStatsServer(ctx context.Context) (*StatsServer, error) {
 	cfg := config.FromContext(ctx)
-	mux := http.NewServeMux()
-	mux.Handle("/metrics", promhttp.Handler())
-	return &StatsServer{
+	g := roleGatherer{
+		Gatherer: prometheus.DefaultGatherer,
+		role:     cfg.Replication.Role,
+	}
+	s := &StatsServer{
 		ctx: ctx,
 		cfg: cfg,
-		server: &http.Server{
+	}
+
+	if cfg.Stats.HasExporter(config.StatsExporterPrometheus) {
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", promhttp.InstrumentMetricHandler(
+			prometheus.DefaultRegisterer,
+			promhttp.HandlerFor(g, promhttp.HandlerOpts{}),
+		))
+		s.server = &http.Server{
 			Addr:              cfg.Stats.ListenAddr,
 			Handler:           mux,
 			ReadHeaderTimeout: time.Second * 10,
 			ReadTimeout:       time.Second * 10,
 			WriteTimeout:      time.Second * 10,
 			MaxHeaderBytes:    http.DefaultMaxHeaderBytes,
-		},
-	}, nil
+		}
+	}
+
+	if cfg.Stats.HasExporter(config.StatsExporterOTLP) {
+		provider, err := newOTLPExporter(ctx, cfg.Stats.OTLP, g)
+		if err != nil {
+			return nil, err
+		}
+		s.provider = provider
+	}
+
+	return s, nil
 }
 
-// ListenAndServe starts the StatsServer.
+// ListenAndServe starts the StatsServer. It returns right away when metrics
+// aren't served to Prometheus.
 func (s *StatsServer) ListenAndServe() error {
+	if s.server == nil {
+		return nil
+	}
 	return s.server.ListenAndServe()
 }
 
-// Shutdown gracefully shuts down the StatsServer.
+// Shutdown gracefully shuts down the StatsServer, pushing the last metrics to
+// the OpenTelemetry collector.
 func (s *StatsServer) Shutdown(ctx context.Context) error {
-	return s.server.Shutdown(ctx)
+	var err error
+	if s.provider != nil {
+		err = s.provider.Shutdown(ctx)
+	}
+	if s.server != nil {
+		err = errors.Join(err, s.server.Shutdown(ctx))
+	}
+	return err
 }
 
 // Close closes the StatsServer.
 func (s *StatsServer) Close() error {
-	return s.server.Close()
+	var err error
+	if s.provider != nil {
+		err = s.provider.Shutdown(context.Background())
+	}
+	if s.server != nil {
+		err = errors.Join(err, s.server.Close())
+	}
+	return err
+}
+
+// roleGatherer adds the server role label to all metrics, so metrics of
+// primary and replica servers can be told apart.
+type roleGatherer struct {
+	prometheus.Gatherer
+	role string
+}
+
+// Gather implements prometheus.Gatherer.
+func (g roleGatherer) Gather() ([]*dto.MetricFamily, error) {
+	mfs, err := g.Gatherer.Gather()
+	name := "role"
+	for _, mf := range mfs {
+		for _, m := range mf.GetMetric() {
+			m.Label = append(m.Label, &dto.LabelPair{Name: &name, Value: &g.role})
+			sort.Slice(m.Label, func(i, j int) bool {
+				return m.Label[i].GetName() < m.Label[j].GetName()
+			})
+		}
+	}
+
+	return mfs, err
 }
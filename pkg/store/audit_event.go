@@ -0,0 +1,59 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/db/models"
+)
+
+// AuditEventStore is an interface for managing the audit log.
+type AuditEventStore interface {
+	// CreateAuditEvent records an audit event. A zero repo ID or user ID
+	// means the event isn't related to a repository or user.
+	CreateAuditEvent(ctx context.Context, h db.Handler, event models.AuditEvent) error
+	// LockAuditChain locks the head of the audit log hash chain until the
+	// end of the transaction, so events are appended to it one at a time,
+	// across processes sharing the database. It must be the first statement
+	// of the transaction.
+	LockAuditChain(ctx context.Context, h db.Handler) error
+	// GetLastAuditEventHash returns the hash of the latest chained audit
+	// event, empty if there is none.
+	GetLastAuditEventHash(ctx context.Context, h db.Handler) (string, error)
+	// GetAuditEventsByRepoID returns the latest audit events of a repository
+	// with the given action, newest first.
+	GetAuditEventsByRepoID(ctx context.Context, h db.Handler, repoID int64, action string, limit int) ([]models.AuditEvent, error)
+	// GetAuditEvents returns the latest audit events of the server, newest
+	// first.
+	GetAuditEvents(ctx context.Context, h db.Handler, limit int) ([]models.AuditEvent, error)
+	// FindAuditEvents returns the audit events by an actor, with an action,
+	// on a target repository, and recorded in a time range, oldest first.
+	// Empty values and zero times match every event.
+	FindAuditEvents(ctx context.Context, h db.Handler, actor string, action string, target string, since time.Time, until time.Time) ([]models.AuditEvent, error)
+	// GetAuditEventsUpTo returns the audit events up to an ID, oldest first.
+	// A zero ID returns all of them.
+	GetAuditEventsUpTo(ctx context.Context, h db.Handler, id int64) ([]models.AuditEvent, error)
+	// GetLastAuditEventIDBefore returns the ID of the latest audit event
+	// recorded before the given time, 0 if there is none.
+	GetLastAuditEventIDBefore(ctx context.Context, h db.Handler, before time.Time) (int64, error)
+	// GetLastAuditEventIDBeyond returns the ID of the latest audit event
+	// that isn't one of the keep latest ones, 0 if there is none.
+	GetLastAuditEventIDBeyond(ctx context.Context, h db.Handler, keep int) (int64, error)
+	// DeleteAuditEventsUpTo deletes the audit events up to an ID and returns
+	// the number of deleted events.
+	DeleteAuditEventsUpTo(ctx context.Context, h db.Handler, id int64) (int64, error)
+}
+
+// AuditSegmentStore is an interface for managing the audit events rotated out
+// of the database.
+type AuditSegmentStore interface {
+	// CreateAuditSegment records a new segment.
+	CreateAuditSegment(ctx context.Context, h db.Handler, segment models.AuditSegment) error
+	// GetAuditSegments returns the segments, oldest first.
+	GetAuditSegments(ctx context.Context, h db.Handler) ([]models.AuditSegment, error)
+	// SetAuditSegmentShipped marks a segment as shipped to external storage.
+	SetAuditSegmentShipped(ctx context.Context, h db.Handler, id int64) error
+	// DeleteAuditSegment deletes a segment.
+	DeleteAuditSegment(ctx context.Context, h db.Handler, id int64) error
+}
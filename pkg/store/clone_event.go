@@ -0,0 +1,24 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/db/models"
+)
+
+// CloneEventStore is an interface for managing repository clone events.
+type CloneEventStore interface {
+	// CreateCloneEvent records a clone event. A zero userID means an
+	// anonymous clone.
+	CreateCloneEvent(ctx context.Context, h db.Handler, repoID int64, userID int64, protocol string) error
+	// GetCloneCountByRepoID returns the number of clone events of a repository.
+	GetCloneCountByRepoID(ctx context.Context, h db.Handler, repoID int64) (int64, error)
+	// GetRecentCloneEventsByRepoID returns the latest clone events of a
+	// repository, newest first.
+	GetRecentCloneEventsByRepoID(ctx context.Context, h db.Handler, repoID int64, limit int) ([]models.CloneEvent, error)
+	// DeleteCloneEventsBefore deletes the clone events recorded before the
+	// given time and returns the number of deleted events.
+	DeleteCloneEventsBefore(ctx context.Context, h db.Handler, before time.Time) (int64, error)
+}
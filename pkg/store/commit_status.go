@@ -0,0 +1,18 @@
+package store
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/db/models"
+)
+
+// CommitStatusStore is an interface for managing commit statuses.
+type CommitStatusStore interface {
+	// SetCommitStatus creates or replaces the status of a commit for the
+	// status context. A zero userID means no user.
+	SetCommitStatus(ctx context.Context, h db.Handler, repoID int64, userID int64, status models.CommitStatus) error
+	// GetCommitStatuses returns the statuses of a commit, one per context,
+	// latest first.
+	GetCommitStatuses(ctx context.Context, h db.Handler, repoID int64, sha string) ([]models.CommitStatus, error)
+}
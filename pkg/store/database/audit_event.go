@@ -0,0 +1,163 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/db/models"
+	"github.com/charmbracelet/soft-serve/pkg/store"
+)
+
+type auditEventStore struct{}
+
+var _ store.AuditEventStore = (*auditEventStore)(nil)
+
+// CreateAuditEvent implements store.AuditEventStore.
+func (*auditEventStore) CreateAuditEvent(ctx context.Context, h db.Handler, event models.AuditEvent) error {
+	rid := sql.NullInt64{Int64: event.RepoID.Int64, Valid: event.RepoID.Int64 > 0}
+	uid := sql.NullInt64{Int64: event.UserID.Int64, Valid: event.UserID.Int64 > 0}
+	query := h.Rebind(`INSERT INTO audit_events (action, repo_id, user_id, details, actor, target, prev_hash, hash, created_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?);`)
+	_, err := h.ExecContext(ctx, query, event.Action, rid, uid, event.Details,
+		event.Actor, event.Target, event.PrevHash, event.Hash, event.CreatedAt.UTC().Format(time.DateTime))
+	return db.WrapError(err)
+}
+
+// LockAuditChain implements store.AuditEventStore. Writing the row takes the
+// database write lock on SQLite, and the row lock on PostgreSQL.
+func (*auditEventStore) LockAuditChain(ctx context.Context, h db.Handler) error {
+	query := h.Rebind(`INSERT INTO audit_chain (id, updated_at) VALUES (1, CURRENT_TIMESTAMP)
+			ON CONFLICT (id) DO UPDATE SET updated_at = excluded.updated_at;`)
+	_, err := h.ExecContext(ctx, query)
+	return db.WrapError(err)
+}
+
+// GetLastAuditEventHash implements store.AuditEventStore.
+func (*auditEventStore) GetLastAuditEventHash(ctx context.Context, h db.Handler) (string, error) {
+	var hash string
+	query := h.Rebind(`SELECT hash FROM audit_events WHERE hash <> '' ORDER BY id DESC LIMIT 1;`)
+	err := h.GetContext(ctx, &hash, query)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	return hash, db.WrapError(err)
+}
+
+// GetAuditEventsByRepoID implements store.AuditEventStore.
+func (*auditEventStore) GetAuditEventsByRepoID(ctx context.Context, h db.Handler, repoID int64, action string, limit int) ([]models.AuditEvent, error) {
+	var m []models.AuditEvent
+	query := h.Rebind(`SELECT audit_events.*, users.username
+			FROM audit_events
+			LEFT JOIN users ON users.id = audit_events.user_id
+			WHERE audit_events.repo_id = ? AND audit_events.action = ?
+			ORDER BY audit_events.created_at DESC, audit_events.id DESC
+			LIMIT ?;`)
+	err := h.SelectContext(ctx, &m, query, repoID, action, limit)
+	return m, db.WrapError(err)
+}
+
+// GetAuditEvents implements store.AuditEventStore.
+func (*auditEventStore) GetAuditEvents(ctx context.Context, h db.Handler, limit int) ([]models.AuditEvent, error) {
+	var m []models.AuditEvent
+	query := h.Rebind(`SELECT audit_events.*, users.username, repos.name AS repo_name
+			FROM audit_events
+			LEFT JOIN users ON users.id = audit_events.user_id
+			LEFT JOIN repos ON repos.id = audit_events.repo_id
+			ORDER BY audit_events.created_at DESC, audit_events.id DESC
+			LIMIT ?;`)
+	err := h.SelectContext(ctx, &m, query, limit)
+	return m, db.WrapError(err)
+}
+
+// FindAuditEvents implements store.AuditEventStore.
+func (*auditEventStore) FindAuditEvents(ctx context.Context, h db.Handler, actor string, action string, target string, since time.Time, until time.Time) ([]models.AuditEvent, error) {
+	var m []models.AuditEvent
+	var where []string
+	var args []interface{}
+	// Events recorded before the audit log was chained have no actor and
+	// target, match them by the current names.
+	if actor != "" {
+		where = append(where, "(audit_events.actor = ? OR (audit_events.actor = '' AND users.username = ?))")
+		args = append(args, actor, actor)
+	}
+	if action != "" {
+		where = append(where, "audit_events.action = ?")
+		args = append(args, action)
+	}
+	if target != "" {
+		where = append(where, "(audit_events.target = ? OR (audit_events.target = '' AND repos.name = ?))")
+		args = append(args, target, target)
+	}
+	// Timestamps are stored in UTC by CURRENT_TIMESTAMP.
+	if !since.IsZero() {
+		where = append(where, "audit_events.created_at >= ?")
+		args = append(args, since.UTC().Format(time.DateTime))
+	}
+	if !until.IsZero() {
+		where = append(where, "audit_events.created_at < ?")
+		args = append(args, until.UTC().Format(time.DateTime))
+	}
+
+	query := `SELECT audit_events.*, users.username, repos.name AS repo_name
+			FROM audit_events
+			LEFT JOIN users ON users.id = audit_events.user_id
+			LEFT JOIN repos ON repos.id = audit_events.repo_id`
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	query += " ORDER BY audit_events.id;"
+	err := h.SelectContext(ctx, &m, h.Rebind(query), args...)
+	return m, db.WrapError(err)
+}
+
+// GetAuditEventsUpTo implements store.AuditEventStore.
+func (*auditEventStore) GetAuditEventsUpTo(ctx context.Context, h db.Handler, id int64) ([]models.AuditEvent, error) {
+	var m []models.AuditEvent
+	query := `SELECT audit_events.*, users.username, repos.name AS repo_name
+			FROM audit_events
+			LEFT JOIN users ON users.id = audit_events.user_id
+			LEFT JOIN repos ON repos.id = audit_events.repo_id`
+	var args []interface{}
+	if id > 0 {
+		query += " WHERE audit_events.id <= ?"
+		args = append(args, id)
+	}
+	query += " ORDER BY audit_events.id;"
+	err := h.SelectContext(ctx, &m, h.Rebind(query), args...)
+	return m, db.WrapError(err)
+}
+
+// GetLastAuditEventIDBefore implements store.AuditEventStore.
+func (*auditEventStore) GetLastAuditEventIDBefore(ctx context.Context, h db.Handler, before time.Time) (int64, error) {
+	var id sql.NullInt64
+	// Timestamps are stored in UTC by CURRENT_TIMESTAMP.
+	query := h.Rebind(`SELECT MAX(id) FROM audit_events WHERE created_at < ?;`)
+	err := h.GetContext(ctx, &id, query, before.UTC().Format(time.DateTime))
+	return id.Int64, db.WrapError(err)
+}
+
+// GetLastAuditEventIDBeyond implements store.AuditEventStore.
+func (*auditEventStore) GetLastAuditEventIDBeyond(ctx context.Context, h db.Handler, keep int) (int64, error) {
+	var id int64
+	query := h.Rebind(`SELECT id FROM audit_events ORDER BY id DESC LIMIT 1 OFFSET ?;`)
+	err := h.GetContext(ctx, &id, query, keep)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	return id, db.WrapError(err)
+}
+
+// DeleteAuditEventsUpTo implements store.AuditEventStore.
+func (*auditEventStore) DeleteAuditEventsUpTo(ctx context.Context, h db.Handler, id int64) (int64, error) {
+	query := h.Rebind(`DELETE FROM audit_events WHERE id <= ?;`)
+	res, err := h.ExecContext(ctx, query, id)
+	if err != nil {
+		return 0, db.WrapError(err)
+	}
+
+	return res.RowsAffected()
+}
@@ -0,0 +1,46 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/db/models"
+	"github.com/charmbracelet/soft-serve/pkg/store"
+)
+
+type auditSegmentStore struct{}
+
+var _ store.AuditSegmentStore = (*auditSegmentStore)(nil)
+
+// CreateAuditSegment implements store.AuditSegmentStore.
+func (*auditSegmentStore) CreateAuditSegment(ctx context.Context, h db.Handler, segment models.AuditSegment) error {
+	query := h.Rebind(`INSERT INTO audit_segments (name, first_id, last_id, first_at, last_at, events, prev_hash, hash, checksum)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?);`)
+	_, err := h.ExecContext(ctx, query, segment.Name, segment.FirstID, segment.LastID,
+		segment.FirstAt.UTC().Format(time.DateTime), segment.LastAt.UTC().Format(time.DateTime),
+		segment.Events, segment.PrevHash, segment.Hash, segment.Checksum)
+	return db.WrapError(err)
+}
+
+// GetAuditSegments implements store.AuditSegmentStore.
+func (*auditSegmentStore) GetAuditSegments(ctx context.Context, h db.Handler) ([]models.AuditSegment, error) {
+	var m []models.AuditSegment
+	query := h.Rebind(`SELECT * FROM audit_segments ORDER BY first_id;`)
+	err := h.SelectContext(ctx, &m, query)
+	return m, db.WrapError(err)
+}
+
+// SetAuditSegmentShipped implements store.AuditSegmentStore.
+func (*auditSegmentStore) SetAuditSegmentShipped(ctx context.Context, h db.Handler, id int64) error {
+	query := h.Rebind(`UPDATE audit_segments SET shipped_at = CURRENT_TIMESTAMP WHERE id = ?;`)
+	_, err := h.ExecContext(ctx, query, id)
+	return db.WrapError(err)
+}
+
+// DeleteAuditSegment implements store.AuditSegmentStore.
+func (*auditSegmentStore) DeleteAuditSegment(ctx context.Context, h db.Handler, id int64) error {
+	query := h.Rebind(`DELETE FROM audit_segments WHERE id = ?;`)
+	_, err := h.ExecContext(ctx, query, id)
+	return db.WrapError(err)
+}
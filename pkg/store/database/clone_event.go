@@ -0,0 +1,56 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/db/models"
+	"github.com/charmbracelet/soft-serve/pkg/store"
+)
+
+type cloneEventStore struct{}
+
+var _ store.CloneEventStore = (*cloneEventStore)(nil)
+
+// CreateCloneEvent implements store.CloneEventStore.
+func (*cloneEventStore) CreateCloneEvent(ctx context.Context, h db.Handler, repoID int64, userID int64, protocol string) error {
+	uid := sql.NullInt64{Int64: userID, Valid: userID > 0}
+	query := h.Rebind(`INSERT INTO clone_events (repo_id, user_id, protocol) VALUES (?, ?, ?);`)
+	_, err := h.ExecContext(ctx, query, repoID, uid, protocol)
+	return db.WrapError(err)
+}
+
+// GetCloneCountByRepoID implements store.CloneEventStore.
+func (*cloneEventStore) GetCloneCountByRepoID(ctx context.Context, h db.Handler, repoID int64) (int64, error) {
+	var count int64
+	query := h.Rebind(`SELECT COUNT(*) FROM clone_events WHERE repo_id = ?;`)
+	err := h.GetContext(ctx, &count, query, repoID)
+	return count, db.WrapError(err)
+}
+
+// GetRecentCloneEventsByRepoID implements store.CloneEventStore.
+func (*cloneEventStore) GetRecentCloneEventsByRepoID(ctx context.Context, h db.Handler, repoID int64, limit int) ([]models.CloneEvent, error) {
+	var m []models.CloneEvent
+	query := h.Rebind(`SELECT clone_events.*, users.username
+			FROM clone_events
+			LEFT JOIN users ON users.id = clone_events.user_id
+			WHERE clone_events.repo_id = ?
+			ORDER BY clone_events.created_at DESC, clone_events.id DESC
+			LIMIT ?;`)
+	err := h.SelectContext(ctx, &m, query, repoID, limit)
+	return m, db.WrapError(err)
+}
+
+// DeleteCloneEventsBefore implements store.CloneEventStore.
+func (*cloneEventStore) DeleteCloneEventsBefore(ctx context.Context, h db.Handler, before time.Time) (int64, error) {
+	// Timestamps are stored in UTC by CURRENT_TIMESTAMP.
+	query := h.Rebind(`DELETE FROM clone_events WHERE created_at < ?;`)
+	res, err := h.ExecContext(ctx, query, before.UTC().Format(time.DateTime))
+	if err != nil {
+		return 0, db.WrapError(err)
+	}
+
+	return res.RowsAffected()
+}
@@ -103,6 +103,25 @@ func (*collabStore) ListCollabsByRepoAsUsers(ctx context.Context, tx db.Handler,
 	return m, err
 }
 
+// ListCollabsByUserID implements store.CollaboratorStore.
+func (*collabStore) ListCollabsByUserID(ctx context.Context, tx db.Handler, userID int64) ([]models.Collab, error) {
+	var m []models.Collab
+
+	query := tx.Rebind(`
+		SELECT
+			collabs.*
+		FROM
+			collabs
+		WHERE
+			collabs.user_id = ?
+		ORDER BY
+			collabs.repo_id
+	`)
+
+	err := tx.SelectContext(ctx, &m, query, userID)
+	return m, err
+}
+
 // RemoveCollabByUsernameAndRepo implements store.CollaboratorStore.
 func (*collabStore) RemoveCollabByUsernameAndRepo(ctx context.Context, tx db.Handler, username string, repo string) error {
 	username = strings.ToLower(username)
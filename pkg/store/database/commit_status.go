@@ -0,0 +1,42 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/db/models"
+	"github.com/charmbracelet/soft-serve/pkg/store"
+)
+
+type commitStatusStore struct{}
+
+var _ store.CommitStatusStore = (*commitStatusStore)(nil)
+
+// SetCommitStatus implements store.CommitStatusStore.
+func (*commitStatusStore) SetCommitStatus(ctx context.Context, h db.Handler, repoID int64, userID int64, status models.CommitStatus) error {
+	uid := sql.NullInt64{Int64: userID, Valid: userID > 0}
+	query := h.Rebind(`INSERT INTO commit_statuses (repo_id, commit_sha, context, state, description, target_url, user_id, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
+			ON CONFLICT (repo_id, commit_sha, context) DO UPDATE SET
+				state = excluded.state,
+				description = excluded.description,
+				target_url = excluded.target_url,
+				user_id = excluded.user_id,
+				updated_at = CURRENT_TIMESTAMP;`)
+	_, err := h.ExecContext(ctx, query, repoID, status.CommitSHA, status.Context, status.State,
+		status.Description, status.TargetURL, uid)
+	return db.WrapError(err)
+}
+
+// GetCommitStatuses implements store.CommitStatusStore.
+func (*commitStatusStore) GetCommitStatuses(ctx context.Context, h db.Handler, repoID int64, sha string) ([]models.CommitStatus, error) {
+	var m []models.CommitStatus
+	query := h.Rebind(`SELECT commit_statuses.*, users.username
+			FROM commit_statuses
+			LEFT JOIN users ON users.id = commit_statuses.user_id
+			WHERE commit_statuses.repo_id = ? AND commit_statuses.commit_sha = ?
+			ORDER BY commit_statuses.updated_at DESC, commit_statuses.id DESC;`)
+	err := h.SelectContext(ctx, &m, query, repoID, sha)
+	return m, db.WrapError(err)
+}
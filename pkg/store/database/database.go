@@ -22,6 +22,25 @@ type datastore struct {
 	*lfsStore
 	*accessTokenStore
 	*webhookStore
+	*pushMirrorStore
+	*repoSettingStore
+	*cloneEventStore
+	*auditEventStore
+	*auditSegmentStore
+	*revokedKeyStore
+	*commitStatusStore
+	*repoAliasStore
+	*termsStore
+	*repoRenameStore
+	*mirrorSyncStore
+	*inviteStore
+	*repoKeyStore
+	*deployTokenStore
+	*releaseStore
+	*repoSizeStore
+	*issueStore
+	*reviewStore
+	*freezeWindowStore
 }
 
 // New returns a new store.Store database.
@@ -35,12 +54,32 @@ func New(ctx context.Context, db *db.DB) store.Store {
 		db:     db,
 		logger: logger,
 
-		settingsStore:    &settingsStore{},
-		repoStore:        &repoStore{},
-		userStore:        &userStore{},
-		collabStore:      &collabStore{},
-		lfsStore:         &lfsStore{},
-		accessTokenStore: &accessTokenStore{},
+		settingsStore:     &settingsStore{},
+		repoStore:         &repoStore{},
+		userStore:         &userStore{},
+		collabStore:       &collabStore{},
+		lfsStore:          &lfsStore{},
+		accessTokenStore:  &accessTokenStore{},
+		webhookStore:      &webhookStore{},
+		pushMirrorStore:   &pushMirrorStore{},
+		repoSettingStore:  &repoSettingStore{},
+		cloneEventStore:   &cloneEventStore{},
+		auditEventStore:   &auditEventStore{},
+		auditSegmentStore: &auditSegmentStore{},
+		revokedKeyStore:   &revokedKeyStore{},
+		commitStatusStore: &commitStatusStore{},
+		repoAliasStore:    &repoAliasStore{},
+		termsStore:        &termsStore{},
+		repoRenameStore:   &repoRenameStore{},
+		mirrorSyncStore:   &mirrorSyncStore{},
+		inviteStore:       &inviteStore{},
+		repoKeyStore:      &repoKeyStore{},
+		deployTokenStore:  &deployTokenStore{},
+		releaseStore:      &releaseStore{},
+		repoSizeStore:     &repoSizeStore{},
+		issueStore:        &issueStore{},
+		reviewStore:       &reviewStore{},
+		freezeWindowStore: &freezeWindowStore{},
 	}
 
 	return s
@@ -0,0 +1,101 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/charmbracelet/soft-serve/pkg/access"
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/db/models"
+	"github.com/charmbracelet/soft-serve/pkg/store"
+	"github.com/charmbracelet/soft-serve/pkg/utils"
+)
+
+type deployTokenStore struct{}
+
+var _ store.DeployTokenStore = (*deployTokenStore)(nil)
+
+// CreateDeployToken implements store.DeployTokenStore.
+func (*deployTokenStore) CreateDeployToken(ctx context.Context, h db.Handler, repo string, name string, token string, level access.AccessLevel, fingerprint string, publicKey string, expiresAt time.Time) error {
+	repo = utils.SanitizeRepo(repo)
+	var expires interface{}
+	if !expiresAt.IsZero() {
+		expires = expiresAt.UTC()
+	}
+
+	query := h.Rebind(`INSERT INTO deploy_tokens (repo_id, name, token, access_level, fingerprint, public_key, expires_at, updated_at)
+			VALUES (
+				(
+					SELECT id FROM repos WHERE name = ?
+				),
+				?,
+				?,
+				?,
+				?,
+				?,
+				?,
+				CURRENT_TIMESTAMP
+			);`)
+	_, err := h.ExecContext(ctx, query, repo, name, token, level, fingerprint, publicKey, expires)
+	return db.WrapError(err)
+}
+
+// DeleteDeployToken implements store.DeployTokenStore.
+func (*deployTokenStore) DeleteDeployToken(ctx context.Context, h db.Handler, repo string, name string) error {
+	repo = utils.SanitizeRepo(repo)
+	query := h.Rebind(`DELETE FROM deploy_tokens
+			WHERE name = ? AND repo_id = (
+				SELECT id FROM repos WHERE name = ?
+			);`)
+	res, err := h.ExecContext(ctx, query, name, repo)
+	if err != nil {
+		return db.WrapError(err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return db.WrapError(err)
+	}
+	if n == 0 {
+		return db.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// GetDeployTokens implements store.DeployTokenStore.
+func (*deployTokenStore) GetDeployTokens(ctx context.Context, h db.Handler, repo string) ([]models.DeployToken, error) {
+	var m []models.DeployToken
+	repo = utils.SanitizeRepo(repo)
+	query := h.Rebind(`SELECT deploy_tokens.*
+			FROM deploy_tokens
+			INNER JOIN repos ON repos.id = deploy_tokens.repo_id
+			WHERE repos.name = ?
+			ORDER BY deploy_tokens.created_at, deploy_tokens.id;`)
+	err := h.SelectContext(ctx, &m, query, repo)
+	return m, db.WrapError(err)
+}
+
+// GetDeployTokenByToken implements store.DeployTokenStore.
+func (*deployTokenStore) GetDeployTokenByToken(ctx context.Context, h db.Handler, token string) (models.DeployToken, error) {
+	var m models.DeployToken
+	query := h.Rebind(`SELECT * FROM deploy_tokens WHERE token = ?;`)
+	err := h.GetContext(ctx, &m, query, token)
+	return m, db.WrapError(err)
+}
+
+// GetDeployTokensByFingerprint implements store.DeployTokenStore.
+func (*deployTokenStore) GetDeployTokensByFingerprint(ctx context.Context, h db.Handler, fingerprint string) ([]models.DeployToken, error) {
+	var m []models.DeployToken
+	query := h.Rebind(`SELECT * FROM deploy_tokens WHERE fingerprint = ? ORDER BY created_at, id;`)
+	err := h.SelectContext(ctx, &m, query, fingerprint)
+	return m, db.WrapError(err)
+}
+
+// TouchDeployToken implements store.DeployTokenStore.
+func (*deployTokenStore) TouchDeployToken(ctx context.Context, h db.Handler, id int64, usedAt time.Time, since time.Time) error {
+	query := h.Rebind(`UPDATE deploy_tokens SET last_used_at = ?
+			WHERE id = ? AND (last_used_at IS NULL OR last_used_at < ?);`)
+	_, err := h.ExecContext(ctx, query, usedAt.UTC(), id, since.UTC())
+	return db.WrapError(err)
+}
@@ -0,0 +1,48 @@
+package database
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/db/models"
+	"github.com/charmbracelet/soft-serve/pkg/store"
+)
+
+type freezeWindowStore struct{}
+
+var _ store.FreezeWindowStore = (*freezeWindowStore)(nil)
+
+// GetFreezeWindowsByRepoID implements store.FreezeWindowStore.
+func (*freezeWindowStore) GetFreezeWindowsByRepoID(ctx context.Context, h db.Handler, repoID int64) ([]models.FreezeWindow, error) {
+	var m []models.FreezeWindow
+	query := h.Rebind(`SELECT * FROM freeze_windows WHERE repo_id = ? ORDER BY name;`)
+	err := h.SelectContext(ctx, &m, query, repoID)
+	return m, db.WrapError(err)
+}
+
+// CreateFreezeWindow implements store.FreezeWindowStore.
+func (*freezeWindowStore) CreateFreezeWindow(ctx context.Context, h db.Handler, repoID int64, name string, refs string, schedule string, duration int64, warnOnly bool) error {
+	query := h.Rebind(`INSERT INTO freeze_windows (repo_id, name, refs, schedule, duration, warn_only, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP);`)
+	_, err := h.ExecContext(ctx, query, repoID, name, refs, schedule, duration, warnOnly)
+	return db.WrapError(err)
+}
+
+// DeleteFreezeWindowByName implements store.FreezeWindowStore.
+func (*freezeWindowStore) DeleteFreezeWindowByName(ctx context.Context, h db.Handler, repoID int64, name string) error {
+	query := h.Rebind(`DELETE FROM freeze_windows WHERE repo_id = ? AND name = ?;`)
+	res, err := h.ExecContext(ctx, query, repoID, name)
+	if err != nil {
+		return db.WrapError(err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return db.WrapError(err)
+	}
+	if n == 0 {
+		return db.ErrRecordNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,87 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/db/models"
+	"github.com/charmbracelet/soft-serve/pkg/store"
+)
+
+type inviteStore struct{}
+
+var _ store.InviteStore = (*inviteStore)(nil)
+
+// CreateInvite implements store.InviteStore.
+func (*inviteStore) CreateInvite(ctx context.Context, h db.Handler, codeHash string, username string, admin bool, createdByID int64, expiresAt time.Time) error {
+	uid := sql.NullInt64{Int64: createdByID, Valid: createdByID > 0}
+	query := h.Rebind(`INSERT INTO invites (code_hash, username, admin, created_by_id, expires_at)
+			VALUES (?, ?, ?, ?, ?);`)
+	_, err := h.ExecContext(ctx, query, codeHash, username, admin, uid, expiresAt)
+	return db.WrapError(err)
+}
+
+// GetInviteByID implements store.InviteStore.
+func (*inviteStore) GetInviteByID(ctx context.Context, h db.Handler, id int64) (models.Invite, error) {
+	var m models.Invite
+	query := h.Rebind(`SELECT * FROM invites WHERE id = ?;`)
+	err := h.GetContext(ctx, &m, query, id)
+	return m, db.WrapError(err)
+}
+
+// GetInviteByCodeHash implements store.InviteStore.
+func (*inviteStore) GetInviteByCodeHash(ctx context.Context, h db.Handler, codeHash string) (models.Invite, error) {
+	var m models.Invite
+	query := h.Rebind(`SELECT * FROM invites WHERE code_hash = ?;`)
+	err := h.GetContext(ctx, &m, query, codeHash)
+	return m, db.WrapError(err)
+}
+
+// GetInvites implements store.InviteStore.
+func (*inviteStore) GetInvites(ctx context.Context, h db.Handler) ([]models.Invite, error) {
+	var m []models.Invite
+	query := h.Rebind(`SELECT * FROM invites ORDER BY created_at DESC, id DESC;`)
+	err := h.SelectContext(ctx, &m, query)
+	return m, db.WrapError(err)
+}
+
+// UseInvite implements store.InviteStore.
+func (*inviteStore) UseInvite(ctx context.Context, h db.Handler, id int64, fingerprint string) error {
+	query := h.Rebind(`UPDATE invites SET used_at = CURRENT_TIMESTAMP, used_key = ?
+			WHERE id = ? AND used_at IS NULL;`)
+	res, err := h.ExecContext(ctx, query, fingerprint, id)
+	if err != nil {
+		return db.WrapError(err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return db.WrapError(err)
+	}
+	if n == 0 {
+		return db.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// DeleteInvite implements store.InviteStore.
+func (*inviteStore) DeleteInvite(ctx context.Context, h db.Handler, id int64) error {
+	query := h.Rebind(`DELETE FROM invites WHERE id = ?;`)
+	res, err := h.ExecContext(ctx, query, id)
+	if err != nil {
+		return db.WrapError(err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return db.WrapError(err)
+	}
+	if n == 0 {
+		return db.ErrRecordNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,89 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/db/models"
+	"github.com/charmbracelet/soft-serve/pkg/store"
+)
+
+type issueStore struct{}
+
+var _ store.IssueStore = (*issueStore)(nil)
+
+// issueColumns selects the issues with their usernames and comment counts.
+const issueColumns = `issues.*, users.username,
+			(SELECT COUNT(*) FROM issue_comments WHERE issue_comments.issue_id = issues.id) AS comments`
+
+// CreateIssue implements store.IssueStore.
+func (*issueStore) CreateIssue(ctx context.Context, h db.Handler, repoID int64, userID int64, title string, body string) (int64, error) {
+	var number int64
+	query := h.Rebind(`SELECT COALESCE(MAX(number), 0) + 1 FROM issues WHERE repo_id = ?;`)
+	if err := h.GetContext(ctx, &number, query, repoID); err != nil {
+		return 0, db.WrapError(err)
+	}
+
+	uid := sql.NullInt64{Int64: userID, Valid: userID > 0}
+	query = h.Rebind(`INSERT INTO issues (repo_id, number, title, body, user_id, updated_at)
+			VALUES (?, ?, ?, ?, ?, CURRENT_TIMESTAMP);`)
+	_, err := h.ExecContext(ctx, query, repoID, number, title, body, uid)
+	return number, db.WrapError(err)
+}
+
+// SetIssueState implements store.IssueStore.
+func (*issueStore) SetIssueState(ctx context.Context, h db.Handler, id int64, state string) error {
+	query := h.Rebind(`UPDATE issues SET state = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?;`)
+	_, err := h.ExecContext(ctx, query, state, id)
+	return db.WrapError(err)
+}
+
+// GetIssue implements store.IssueStore.
+func (*issueStore) GetIssue(ctx context.Context, h db.Handler, repoID int64, number int64) (models.Issue, error) {
+	var m models.Issue
+	query := h.Rebind(`SELECT ` + issueColumns + `
+			FROM issues
+			LEFT JOIN users ON users.id = issues.user_id
+			WHERE issues.repo_id = ? AND issues.number = ?;`)
+	err := h.GetContext(ctx, &m, query, repoID, number)
+	return m, db.WrapError(err)
+}
+
+// GetIssues implements store.IssueStore.
+func (*issueStore) GetIssues(ctx context.Context, h db.Handler, repoID int64, state string) ([]models.Issue, error) {
+	var m []models.Issue
+	query := h.Rebind(`SELECT ` + issueColumns + `
+			FROM issues
+			LEFT JOIN users ON users.id = issues.user_id
+			WHERE issues.repo_id = ? AND (? = '' OR issues.state = ?)
+			ORDER BY issues.number DESC;`)
+	err := h.SelectContext(ctx, &m, query, repoID, state, state)
+	return m, db.WrapError(err)
+}
+
+// CreateIssueComment implements store.IssueStore.
+func (*issueStore) CreateIssueComment(ctx context.Context, h db.Handler, issueID int64, userID int64, body string) error {
+	uid := sql.NullInt64{Int64: userID, Valid: userID > 0}
+	query := h.Rebind(`INSERT INTO issue_comments (issue_id, body, user_id, updated_at)
+			VALUES (?, ?, ?, CURRENT_TIMESTAMP);`)
+	if _, err := h.ExecContext(ctx, query, issueID, body, uid); err != nil {
+		return db.WrapError(err)
+	}
+
+	query = h.Rebind(`UPDATE issues SET updated_at = CURRENT_TIMESTAMP WHERE id = ?;`)
+	_, err := h.ExecContext(ctx, query, issueID)
+	return db.WrapError(err)
+}
+
+// GetIssueComments implements store.IssueStore.
+func (*issueStore) GetIssueComments(ctx context.Context, h db.Handler, issueID int64) ([]models.IssueComment, error) {
+	var m []models.IssueComment
+	query := h.Rebind(`SELECT issue_comments.*, users.username
+			FROM issue_comments
+			LEFT JOIN users ON users.id = issue_comments.user_id
+			WHERE issue_comments.issue_id = ?
+			ORDER BY issue_comments.created_at, issue_comments.id;`)
+	err := h.SelectContext(ctx, &m, query, issueID)
+	return m, db.WrapError(err)
+}
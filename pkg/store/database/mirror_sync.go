@@ -0,0 +1,46 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/db/models"
+	"github.com/charmbracelet/soft-serve/pkg/store"
+)
+
+type mirrorSyncStore struct{}
+
+var _ store.MirrorSyncStore = (*mirrorSyncStore)(nil)
+
+// CreateMirrorSync implements store.MirrorSyncStore.
+func (*mirrorSyncStore) CreateMirrorSync(ctx context.Context, h db.Handler, sync models.MirrorSync) error {
+	query := h.Rebind(`INSERT INTO mirror_syncs (repo_id, status, error, new_objects, updated_refs, fetched_bytes, duration_ms)
+			VALUES (?, ?, ?, ?, ?, ?, ?);`)
+	_, err := h.ExecContext(ctx, query, sync.RepoID, sync.Status, sync.Error,
+		sync.NewObjects, sync.UpdatedRefs, sync.FetchedBytes, sync.DurationMS)
+	return db.WrapError(err)
+}
+
+// GetRecentMirrorSyncsByRepoID implements store.MirrorSyncStore.
+func (*mirrorSyncStore) GetRecentMirrorSyncsByRepoID(ctx context.Context, h db.Handler, repoID int64, limit int) ([]models.MirrorSync, error) {
+	var m []models.MirrorSync
+	query := h.Rebind(`SELECT * FROM mirror_syncs
+			WHERE repo_id = ?
+			ORDER BY created_at DESC, id DESC
+			LIMIT ?;`)
+	err := h.SelectContext(ctx, &m, query, repoID, limit)
+	return m, db.WrapError(err)
+}
+
+// DeleteMirrorSyncsBefore implements store.MirrorSyncStore.
+func (*mirrorSyncStore) DeleteMirrorSyncsBefore(ctx context.Context, h db.Handler, before time.Time) (int64, error) {
+	// Timestamps are stored in UTC by CURRENT_TIMESTAMP.
+	query := h.Rebind(`DELETE FROM mirror_syncs WHERE created_at < ?;`)
+	res, err := h.ExecContext(ctx, query, before.UTC().Format(time.DateTime))
+	if err != nil {
+		return 0, db.WrapError(err)
+	}
+
+	return res.RowsAffected()
+}
@@ -0,0 +1,69 @@
+package database
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/db/models"
+	"github.com/charmbracelet/soft-serve/pkg/store"
+)
+
+type pushMirrorStore struct{}
+
+var _ store.PushMirrorStore = (*pushMirrorStore)(nil)
+
+// CreatePushMirror implements store.PushMirrorStore.
+func (*pushMirrorStore) CreatePushMirror(ctx context.Context, h db.Handler, repoID int64, name string, remoteURL string) error {
+	query := h.Rebind(`INSERT INTO push_mirrors (repo_id, name, remote_url, updated_at)
+			VALUES (?, ?, ?, CURRENT_TIMESTAMP);`)
+	_, err := h.ExecContext(ctx, query, repoID, name, remoteURL)
+	return err
+}
+
+// DeletePushMirrorByName implements store.PushMirrorStore.
+func (*pushMirrorStore) DeletePushMirrorByName(ctx context.Context, h db.Handler, repoID int64, name string) error {
+	query := h.Rebind(`DELETE FROM push_mirrors WHERE repo_id = ? AND name = ?;`)
+	_, err := h.ExecContext(ctx, query, repoID, name)
+	return err
+}
+
+// GetPushMirrorsByRepoID implements store.PushMirrorStore.
+func (*pushMirrorStore) GetPushMirrorsByRepoID(ctx context.Context, h db.Handler, repoID int64) ([]models.PushMirror, error) {
+	var m []models.PushMirror
+	query := h.Rebind(`SELECT * FROM push_mirrors WHERE repo_id = ? ORDER BY name;`)
+	err := h.SelectContext(ctx, &m, query, repoID)
+	return m, err
+}
+
+// GetPushMirrorsByStatus implements store.PushMirrorStore.
+func (*pushMirrorStore) GetPushMirrorsByStatus(ctx context.Context, h db.Handler, status string) ([]models.PushMirror, error) {
+	var m []models.PushMirror
+	query := h.Rebind(`SELECT * FROM push_mirrors WHERE status = ? ORDER BY id;`)
+	err := h.SelectContext(ctx, &m, query, status)
+	return m, err
+}
+
+// SetPushMirrorsStatusByRepoID implements store.PushMirrorStore.
+func (*pushMirrorStore) SetPushMirrorsStatusByRepoID(ctx context.Context, h db.Handler, repoID int64, status string) error {
+	query := h.Rebind(`UPDATE push_mirrors SET status = ?, attempts = 0, updated_at = CURRENT_TIMESTAMP
+			WHERE repo_id = ?;`)
+	_, err := h.ExecContext(ctx, query, status, repoID)
+	return err
+}
+
+// UpdatePushMirrorResult implements store.PushMirrorStore.
+func (*pushMirrorStore) UpdatePushMirrorResult(ctx context.Context, h db.Handler, id int64, status string, attempts int, lastErr error) error {
+	if lastErr == nil {
+		query := h.Rebind(`UPDATE push_mirrors SET status = ?, attempts = ?, last_error = NULL,
+				last_pushed_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+				WHERE id = ?;`)
+		_, err := h.ExecContext(ctx, query, status, attempts, id)
+		return err
+	}
+
+	query := h.Rebind(`UPDATE push_mirrors SET status = ?, attempts = ?, last_error = ?,
+			updated_at = CURRENT_TIMESTAMP
+			WHERE id = ?;`)
+	_, err := h.ExecContext(ctx, query, status, attempts, lastErr.Error(), id)
+	return err
+}
@@ -0,0 +1,107 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/db/models"
+	"github.com/charmbracelet/soft-serve/pkg/store"
+)
+
+type releaseStore struct{}
+
+var _ store.ReleaseStore = (*releaseStore)(nil)
+
+// CreateRelease implements store.ReleaseStore.
+func (*releaseStore) CreateRelease(ctx context.Context, h db.Handler, repoID int64, userID int64, tag string, title string, notes string) error {
+	uid := sql.NullInt64{Int64: userID, Valid: userID > 0}
+	query := h.Rebind(`INSERT INTO releases (repo_id, tag, title, notes, user_id, updated_at)
+			VALUES (?, ?, ?, ?, ?, CURRENT_TIMESTAMP);`)
+	_, err := h.ExecContext(ctx, query, repoID, tag, title, notes, uid)
+	return db.WrapError(err)
+}
+
+// UpdateRelease implements store.ReleaseStore.
+func (*releaseStore) UpdateRelease(ctx context.Context, h db.Handler, id int64, title string, notes string) error {
+	query := h.Rebind(`UPDATE releases SET title = ?, notes = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?;`)
+	_, err := h.ExecContext(ctx, query, title, notes, id)
+	return db.WrapError(err)
+}
+
+// DeleteRelease implements store.ReleaseStore.
+func (*releaseStore) DeleteRelease(ctx context.Context, h db.Handler, id int64) error {
+	query := h.Rebind(`DELETE FROM releases WHERE id = ?;`)
+	_, err := h.ExecContext(ctx, query, id)
+	return db.WrapError(err)
+}
+
+// GetRelease implements store.ReleaseStore.
+func (*releaseStore) GetRelease(ctx context.Context, h db.Handler, repoID int64, tag string) (models.Release, error) {
+	var m models.Release
+	query := h.Rebind(`SELECT releases.*, users.username
+			FROM releases
+			LEFT JOIN users ON users.id = releases.user_id
+			WHERE releases.repo_id = ? AND releases.tag = ?;`)
+	err := h.GetContext(ctx, &m, query, repoID, tag)
+	return m, db.WrapError(err)
+}
+
+// GetReleases implements store.ReleaseStore.
+func (*releaseStore) GetReleases(ctx context.Context, h db.Handler, repoID int64) ([]models.Release, error) {
+	var m []models.Release
+	query := h.Rebind(`SELECT releases.*, users.username
+			FROM releases
+			LEFT JOIN users ON users.id = releases.user_id
+			WHERE releases.repo_id = ?
+			ORDER BY releases.created_at DESC, releases.id DESC;`)
+	err := h.SelectContext(ctx, &m, query, repoID)
+	return m, db.WrapError(err)
+}
+
+// SetReleaseAsset implements store.ReleaseStore.
+func (*releaseStore) SetReleaseAsset(ctx context.Context, h db.Handler, releaseID int64, name string, size int64, sha256 string) error {
+	query := h.Rebind(`INSERT INTO release_assets (release_id, name, size, sha256, updated_at)
+			VALUES (?, ?, ?, ?, CURRENT_TIMESTAMP)
+			ON CONFLICT (release_id, name) DO UPDATE SET
+				size = excluded.size,
+				sha256 = excluded.sha256,
+				updated_at = CURRENT_TIMESTAMP;`)
+	_, err := h.ExecContext(ctx, query, releaseID, name, size, sha256)
+	return db.WrapError(err)
+}
+
+// DeleteReleaseAsset implements store.ReleaseStore.
+func (*releaseStore) DeleteReleaseAsset(ctx context.Context, h db.Handler, releaseID int64, name string) error {
+	query := h.Rebind(`DELETE FROM release_assets WHERE release_id = ? AND name = ?;`)
+	res, err := h.ExecContext(ctx, query, releaseID, name)
+	if err != nil {
+		return db.WrapError(err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return db.WrapError(err)
+	}
+	if n == 0 {
+		return db.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// GetReleaseAsset implements store.ReleaseStore.
+func (*releaseStore) GetReleaseAsset(ctx context.Context, h db.Handler, releaseID int64, name string) (models.ReleaseAsset, error) {
+	var m models.ReleaseAsset
+	query := h.Rebind(`SELECT * FROM release_assets WHERE release_id = ? AND name = ?;`)
+	err := h.GetContext(ctx, &m, query, releaseID, name)
+	return m, db.WrapError(err)
+}
+
+// GetReleaseAssets implements store.ReleaseStore.
+func (*releaseStore) GetReleaseAssets(ctx context.Context, h db.Handler, releaseID int64) ([]models.ReleaseAsset, error) {
+	var m []models.ReleaseAsset
+	query := h.Rebind(`SELECT * FROM release_assets WHERE release_id = ? ORDER BY name;`)
+	err := h.SelectContext(ctx, &m, query, releaseID)
+	return m, db.WrapError(err)
+}
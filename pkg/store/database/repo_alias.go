@@ -0,0 +1,54 @@
+package database
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/db/models"
+	"github.com/charmbracelet/soft-serve/pkg/store"
+	"github.com/charmbracelet/soft-serve/pkg/utils"
+)
+
+type repoAliasStore struct{}
+
+var _ store.RepoAliasStore = (*repoAliasStore)(nil)
+
+// CreateRepoAlias implements store.RepoAliasStore.
+func (*repoAliasStore) CreateRepoAlias(ctx context.Context, h db.Handler, repoID int64, alias string, message string) error {
+	alias = utils.SanitizeRepo(alias)
+	query := h.Rebind(`INSERT INTO repo_aliases (repo_id, alias, message) VALUES (?, ?, ?);`)
+	_, err := h.ExecContext(ctx, query, repoID, alias, message)
+	return db.WrapError(err)
+}
+
+// DeleteRepoAlias implements store.RepoAliasStore.
+func (*repoAliasStore) DeleteRepoAlias(ctx context.Context, h db.Handler, alias string) error {
+	alias = utils.SanitizeRepo(alias)
+	query := h.Rebind(`DELETE FROM repo_aliases WHERE alias = ?;`)
+	_, err := h.ExecContext(ctx, query, alias)
+	return db.WrapError(err)
+}
+
+// GetRepoAlias implements store.RepoAliasStore.
+func (*repoAliasStore) GetRepoAlias(ctx context.Context, h db.Handler, alias string) (models.RepoAlias, error) {
+	var m models.RepoAlias
+	alias = utils.SanitizeRepo(alias)
+	query := h.Rebind(`SELECT repo_aliases.*, repos.name AS repo_name
+			FROM repo_aliases
+			INNER JOIN repos ON repos.id = repo_aliases.repo_id
+			WHERE repo_aliases.alias = ?;`)
+	err := h.GetContext(ctx, &m, query, alias)
+	return m, db.WrapError(err)
+}
+
+// GetRepoAliasesByRepoID implements store.RepoAliasStore.
+func (*repoAliasStore) GetRepoAliasesByRepoID(ctx context.Context, h db.Handler, repoID int64) ([]models.RepoAlias, error) {
+	var m []models.RepoAlias
+	query := h.Rebind(`SELECT repo_aliases.*, repos.name AS repo_name
+			FROM repo_aliases
+			INNER JOIN repos ON repos.id = repo_aliases.repo_id
+			WHERE repo_aliases.repo_id = ?
+			ORDER BY repo_aliases.alias;`)
+	err := h.SelectContext(ctx, &m, query, repoID)
+	return m, db.WrapError(err)
+}
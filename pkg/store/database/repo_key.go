@@ -0,0 +1,87 @@
+package database
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/pkg/access"
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/db/models"
+	"github.com/charmbracelet/soft-serve/pkg/store"
+	"github.com/charmbracelet/soft-serve/pkg/utils"
+)
+
+type repoKeyStore struct{}
+
+var _ store.RepoKeyStore = (*repoKeyStore)(nil)
+
+// SetRepoKey implements store.RepoKeyStore.
+func (*repoKeyStore) SetRepoKey(ctx context.Context, h db.Handler, repo string, fingerprint string, publicKey string, level access.AccessLevel) error {
+	repo = utils.SanitizeRepo(repo)
+	query := h.Rebind(`INSERT INTO repo_keys (repo_id, fingerprint, public_key, access_level, updated_at)
+			VALUES (
+				(
+					SELECT id FROM repos WHERE name = ?
+				),
+				?,
+				?,
+				?,
+				CURRENT_TIMESTAMP
+			)
+			ON CONFLICT (repo_id, fingerprint) DO UPDATE SET
+				access_level = excluded.access_level,
+				updated_at = CURRENT_TIMESTAMP;`)
+	_, err := h.ExecContext(ctx, query, repo, fingerprint, publicKey, level)
+	return db.WrapError(err)
+}
+
+// DeleteRepoKey implements store.RepoKeyStore.
+func (*repoKeyStore) DeleteRepoKey(ctx context.Context, h db.Handler, repo string, fingerprint string) error {
+	repo = utils.SanitizeRepo(repo)
+	query := h.Rebind(`DELETE FROM repo_keys
+			WHERE fingerprint = ? AND repo_id = (
+				SELECT id FROM repos WHERE name = ?
+			);`)
+	res, err := h.ExecContext(ctx, query, fingerprint, repo)
+	if err != nil {
+		return db.WrapError(err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return db.WrapError(err)
+	}
+	if n == 0 {
+		return db.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// GetRepoKeys implements store.RepoKeyStore.
+func (*repoKeyStore) GetRepoKeys(ctx context.Context, h db.Handler, repo string) ([]models.RepoKey, error) {
+	var m []models.RepoKey
+	repo = utils.SanitizeRepo(repo)
+	query := h.Rebind(`SELECT repo_keys.*
+			FROM repo_keys
+			INNER JOIN repos ON repos.id = repo_keys.repo_id
+			WHERE repos.name = ?
+			ORDER BY repo_keys.created_at, repo_keys.id;`)
+	err := h.SelectContext(ctx, &m, query, repo)
+	return m, db.WrapError(err)
+}
+
+// GetRepoKeysByFingerprint implements store.RepoKeyStore.
+func (*repoKeyStore) GetRepoKeysByFingerprint(ctx context.Context, h db.Handler, fingerprint string) ([]models.RepoKey, error) {
+	var m []models.RepoKey
+	query := h.Rebind(`SELECT * FROM repo_keys WHERE fingerprint = ? ORDER BY created_at, id;`)
+	err := h.SelectContext(ctx, &m, query, fingerprint)
+	return m, db.WrapError(err)
+}
+
+// GetAllRepoKeys implements store.RepoKeyStore.
+func (*repoKeyStore) GetAllRepoKeys(ctx context.Context, h db.Handler) ([]models.RepoKey, error) {
+	var m []models.RepoKey
+	query := h.Rebind(`SELECT * FROM repo_keys ORDER BY fingerprint, repo_id;`)
+	err := h.SelectContext(ctx, &m, query)
+	return m, db.WrapError(err)
+}
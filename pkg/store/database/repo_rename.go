@@ -0,0 +1,55 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/db/models"
+	"github.com/charmbracelet/soft-serve/pkg/store"
+	"github.com/charmbracelet/soft-serve/pkg/utils"
+)
+
+type repoRenameStore struct{}
+
+var _ store.RepoRenameStore = (*repoRenameStore)(nil)
+
+// CreateRepoRename implements store.RepoRenameStore.
+func (*repoRenameStore) CreateRepoRename(ctx context.Context, h db.Handler, repoID int64, oldName string) error {
+	oldName = utils.SanitizeRepo(oldName)
+	query := h.Rebind(`INSERT INTO repo_renames (repo_id, old_name) VALUES (?, ?);`)
+	_, err := h.ExecContext(ctx, query, repoID, oldName)
+	return db.WrapError(err)
+}
+
+// DeleteRepoRename implements store.RepoRenameStore.
+func (*repoRenameStore) DeleteRepoRename(ctx context.Context, h db.Handler, oldName string) error {
+	oldName = utils.SanitizeRepo(oldName)
+	query := h.Rebind(`DELETE FROM repo_renames WHERE old_name = ?;`)
+	_, err := h.ExecContext(ctx, query, oldName)
+	return db.WrapError(err)
+}
+
+// GetRepoRename implements store.RepoRenameStore.
+func (*repoRenameStore) GetRepoRename(ctx context.Context, h db.Handler, oldName string) (models.RepoRename, error) {
+	var m models.RepoRename
+	oldName = utils.SanitizeRepo(oldName)
+	query := h.Rebind(`SELECT repo_renames.*, repos.name AS repo_name
+			FROM repo_renames
+			INNER JOIN repos ON repos.id = repo_renames.repo_id
+			WHERE repo_renames.old_name = ?;`)
+	err := h.GetContext(ctx, &m, query, oldName)
+	return m, db.WrapError(err)
+}
+
+// DeleteRepoRenamesBefore implements store.RepoRenameStore.
+func (*repoRenameStore) DeleteRepoRenamesBefore(ctx context.Context, h db.Handler, before time.Time) (int64, error) {
+	// Timestamps are stored in UTC by CURRENT_TIMESTAMP.
+	query := h.Rebind(`DELETE FROM repo_renames WHERE created_at < ?;`)
+	res, err := h.ExecContext(ctx, query, before.UTC().Format(time.DateTime))
+	if err != nil {
+		return 0, db.WrapError(err)
+	}
+
+	return res.RowsAffected()
+}
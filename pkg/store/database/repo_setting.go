@@ -0,0 +1,37 @@
+package database
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/db/models"
+	"github.com/charmbracelet/soft-serve/pkg/store"
+)
+
+type repoSettingStore struct{}
+
+var _ store.RepoSettingStore = (*repoSettingStore)(nil)
+
+// GetRepoSettingsByRepoID implements store.RepoSettingStore.
+func (*repoSettingStore) GetRepoSettingsByRepoID(ctx context.Context, h db.Handler, repoID int64) ([]models.RepoSetting, error) {
+	var m []models.RepoSetting
+	query := h.Rebind(`SELECT * FROM repo_settings WHERE repo_id = ? ORDER BY "key";`)
+	err := h.SelectContext(ctx, &m, query, repoID)
+	return m, db.WrapError(err)
+}
+
+// SetRepoSetting implements store.RepoSettingStore.
+func (*repoSettingStore) SetRepoSetting(ctx context.Context, h db.Handler, repoID int64, key string, value string) error {
+	query := h.Rebind(`INSERT INTO repo_settings (repo_id, "key", value, updated_at)
+			VALUES (?, ?, ?, CURRENT_TIMESTAMP)
+			ON CONFLICT (repo_id, "key") DO UPDATE SET value = excluded.value, updated_at = CURRENT_TIMESTAMP;`)
+	_, err := h.ExecContext(ctx, query, repoID, key, value)
+	return db.WrapError(err)
+}
+
+// DeleteRepoSetting implements store.RepoSettingStore.
+func (*repoSettingStore) DeleteRepoSetting(ctx context.Context, h db.Handler, repoID int64, key string) error {
+	query := h.Rebind(`DELETE FROM repo_settings WHERE repo_id = ? AND "key" = ?;`)
+	_, err := h.ExecContext(ctx, query, repoID, key)
+	return db.WrapError(err)
+}
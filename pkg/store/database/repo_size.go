@@ -0,0 +1,55 @@
+package database
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/db/models"
+	"github.com/charmbracelet/soft-serve/pkg/store"
+)
+
+type repoSizeStore struct{}
+
+var _ store.RepoSizeStore = (*repoSizeStore)(nil)
+
+// GetRepoSize implements store.RepoSizeStore.
+func (*repoSizeStore) GetRepoSize(ctx context.Context, h db.Handler, repoID int64) (models.RepoSize, error) {
+	var m models.RepoSize
+	query := h.Rebind(`SELECT repo_sizes.*, repos.name
+			FROM repo_sizes
+			INNER JOIN repos ON repos.id = repo_sizes.repo_id
+			WHERE repo_sizes.repo_id = ?;`)
+	err := h.GetContext(ctx, &m, query, repoID)
+	return m, db.WrapError(err)
+}
+
+// GetRepoSizes implements store.RepoSizeStore.
+func (*repoSizeStore) GetRepoSizes(ctx context.Context, h db.Handler) ([]models.RepoSize, error) {
+	var m []models.RepoSize
+	query := h.Rebind(`SELECT repo_sizes.*, repos.name
+			FROM repo_sizes
+			INNER JOIN repos ON repos.id = repo_sizes.repo_id
+			ORDER BY repos.name;`)
+	err := h.SelectContext(ctx, &m, query)
+	return m, db.WrapError(err)
+}
+
+// GetUserRepoSizeTotal implements store.RepoSizeStore.
+func (*repoSizeStore) GetUserRepoSizeTotal(ctx context.Context, h db.Handler, userID int64) (int64, error) {
+	var total int64
+	query := h.Rebind(`SELECT COALESCE(SUM(repo_sizes.size), 0)
+			FROM repo_sizes
+			INNER JOIN repos ON repos.id = repo_sizes.repo_id
+			WHERE repos.user_id = ?;`)
+	err := h.GetContext(ctx, &total, query, userID)
+	return total, db.WrapError(err)
+}
+
+// SetRepoSize implements store.RepoSizeStore.
+func (*repoSizeStore) SetRepoSize(ctx context.Context, h db.Handler, repoID int64, size int64) error {
+	query := h.Rebind(`INSERT INTO repo_sizes (repo_id, size, updated_at)
+			VALUES (?, ?, CURRENT_TIMESTAMP)
+			ON CONFLICT (repo_id) DO UPDATE SET size = excluded.size, updated_at = CURRENT_TIMESTAMP;`)
+	_, err := h.ExecContext(ctx, query, repoID, size)
+	return db.WrapError(err)
+}
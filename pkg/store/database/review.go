@@ -0,0 +1,41 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/db/models"
+	"github.com/charmbracelet/soft-serve/pkg/store"
+)
+
+type reviewStore struct{}
+
+var _ store.ReviewStore = (*reviewStore)(nil)
+
+// CreateReview implements store.ReviewStore.
+func (*reviewStore) CreateReview(ctx context.Context, h db.Handler, repoID int64, userID int64, targetBranch string, baseSHA string, commitSHA string) (int64, error) {
+	var number int64
+	query := h.Rebind(`SELECT COALESCE(MAX(number), 0) + 1 FROM reviews WHERE repo_id = ?;`)
+	if err := h.GetContext(ctx, &number, query, repoID); err != nil {
+		return 0, db.WrapError(err)
+	}
+
+	uid := sql.NullInt64{Int64: userID, Valid: userID > 0}
+	query = h.Rebind(`INSERT INTO reviews (repo_id, number, target_branch, base_sha, commit_sha, user_id)
+			VALUES (?, ?, ?, ?, ?, ?);`)
+	_, err := h.ExecContext(ctx, query, repoID, number, targetBranch, baseSHA, commitSHA, uid)
+	return number, db.WrapError(err)
+}
+
+// GetReviews implements store.ReviewStore.
+func (*reviewStore) GetReviews(ctx context.Context, h db.Handler, repoID int64) ([]models.Review, error) {
+	var m []models.Review
+	query := h.Rebind(`SELECT reviews.*, users.username
+			FROM reviews
+			LEFT JOIN users ON users.id = reviews.user_id
+			WHERE reviews.repo_id = ?
+			ORDER BY reviews.number DESC;`)
+	err := h.SelectContext(ctx, &m, query, repoID)
+	return m, db.WrapError(err)
+}
@@ -0,0 +1,45 @@
+package database
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/db/models"
+	"github.com/charmbracelet/soft-serve/pkg/store"
+)
+
+type revokedKeyStore struct{}
+
+var _ store.RevokedKeyStore = (*revokedKeyStore)(nil)
+
+// CreateRevokedKey implements store.RevokedKeyStore.
+func (*revokedKeyStore) CreateRevokedKey(ctx context.Context, h db.Handler, fingerprint string) error {
+	query := h.Rebind(`INSERT INTO revoked_keys (fingerprint) VALUES (?);`)
+	_, err := h.ExecContext(ctx, query, fingerprint)
+	return db.WrapError(err)
+}
+
+// DeleteRevokedKey implements store.RevokedKeyStore.
+func (*revokedKeyStore) DeleteRevokedKey(ctx context.Context, h db.Handler, fingerprint string) error {
+	query := h.Rebind(`DELETE FROM revoked_keys WHERE fingerprint = ?;`)
+	_, err := h.ExecContext(ctx, query, fingerprint)
+	return db.WrapError(err)
+}
+
+// GetRevokedKeys implements store.RevokedKeyStore.
+func (*revokedKeyStore) GetRevokedKeys(ctx context.Context, h db.Handler) ([]models.RevokedKey, error) {
+	var m []models.RevokedKey
+	query := h.Rebind(`SELECT * FROM revoked_keys ORDER BY created_at, id;`)
+	err := h.SelectContext(ctx, &m, query)
+	return m, db.WrapError(err)
+}
+
+// IsKeyRevoked implements store.RevokedKeyStore.
+func (*revokedKeyStore) IsKeyRevoked(ctx context.Context, h db.Handler, fingerprint string) (bool, error) {
+	var count int
+	query := h.Rebind(`SELECT COUNT(*) FROM revoked_keys WHERE fingerprint = ?;`)
+	if err := h.GetContext(ctx, &count, query, fingerprint); err != nil {
+		return false, db.WrapError(err)
+	}
+	return count > 0, nil
+}
@@ -45,3 +45,53 @@ func (*settingsStore) SetAnonAccess(ctx context.Context, tx db.Handler, level ac
 	_, err := tx.ExecContext(ctx, query, level.String())
 	return db.WrapError(err)
 }
+
+// GetLockdown implements store.SettingStore.
+func (*settingsStore) GetLockdown(ctx context.Context, tx db.Handler) (bool, error) {
+	var enabled bool
+	query := tx.Rebind(`SELECT value FROM settings WHERE "key" = 'lockdown'`)
+	if err := tx.GetContext(ctx, &enabled, query); err != nil {
+		return false, db.WrapError(err)
+	}
+	return enabled, nil
+}
+
+// SetLockdown implements store.SettingStore.
+func (*settingsStore) SetLockdown(ctx context.Context, tx db.Handler, enabled bool) error {
+	query := tx.Rebind(`UPDATE settings SET value = ?, updated_at = CURRENT_TIMESTAMP WHERE "key" = 'lockdown'`)
+	_, err := tx.ExecContext(ctx, query, enabled)
+	return db.WrapError(err)
+}
+
+// GetTerms implements store.SettingStore.
+func (*settingsStore) GetTerms(ctx context.Context, tx db.Handler) (string, string, error) {
+	var settings []struct {
+		Key   string `db:"key"`
+		Value string `db:"value"`
+	}
+	query := tx.Rebind(`SELECT "key", value FROM settings WHERE "key" IN ('terms_version', 'terms_text')`)
+	if err := tx.SelectContext(ctx, &settings, query); err != nil {
+		return "", "", db.WrapError(err)
+	}
+
+	var version, text string
+	for _, s := range settings {
+		switch s.Key {
+		case "terms_version":
+			version = s.Value
+		case "terms_text":
+			text = s.Value
+		}
+	}
+	return version, text, nil
+}
+
+// SetTerms implements store.SettingStore.
+func (*settingsStore) SetTerms(ctx context.Context, tx db.Handler, version string, text string) error {
+	query := tx.Rebind(`UPDATE settings SET value = ?, updated_at = CURRENT_TIMESTAMP WHERE "key" = ?`)
+	if _, err := tx.ExecContext(ctx, query, version, "terms_version"); err != nil {
+		return db.WrapError(err)
+	}
+	_, err := tx.ExecContext(ctx, query, text, "terms_text")
+	return db.WrapError(err)
+}
@@ -0,0 +1,44 @@
+package database
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/db/models"
+	"github.com/charmbracelet/soft-serve/pkg/store"
+)
+
+type termsStore struct{}
+
+var _ store.TermsStore = (*termsStore)(nil)
+
+// CreateTermsAcceptance implements store.TermsStore.
+func (*termsStore) CreateTermsAcceptance(ctx context.Context, h db.Handler, userID int64, version string) error {
+	query := h.Rebind(`INSERT INTO terms_acceptances (user_id, version) VALUES (?, ?)
+			ON CONFLICT (user_id, version) DO NOTHING;`)
+	_, err := h.ExecContext(ctx, query, userID, version)
+	return db.WrapError(err)
+}
+
+// GetTermsAcceptance implements store.TermsStore.
+func (*termsStore) GetTermsAcceptance(ctx context.Context, h db.Handler, userID int64, version string) (models.TermsAcceptance, error) {
+	var m models.TermsAcceptance
+	query := h.Rebind(`SELECT terms_acceptances.*, users.username
+			FROM terms_acceptances
+			INNER JOIN users ON users.id = terms_acceptances.user_id
+			WHERE terms_acceptances.user_id = ? AND terms_acceptances.version = ?;`)
+	err := h.GetContext(ctx, &m, query, userID, version)
+	return m, db.WrapError(err)
+}
+
+// GetTermsAcceptances implements store.TermsStore.
+func (*termsStore) GetTermsAcceptances(ctx context.Context, h db.Handler, version string) ([]models.TermsAcceptance, error) {
+	var m []models.TermsAcceptance
+	query := h.Rebind(`SELECT terms_acceptances.*, users.username
+			FROM terms_acceptances
+			INNER JOIN users ON users.id = terms_acceptances.user_id
+			WHERE terms_acceptances.version = ?
+			ORDER BY terms_acceptances.created_at, terms_acceptances.id;`)
+	err := h.SelectContext(ctx, &m, query, version)
+	return m, db.WrapError(err)
+}
@@ -2,6 +2,7 @@ package database
 
 import (
 	"context"
+	"database/sql"
 
 	"github.com/charmbracelet/soft-serve/pkg/db"
 	"github.com/charmbracelet/soft-serve/pkg/db/models"
@@ -15,11 +16,11 @@ type webhookStore struct{}
 var _ store.WebhookStore = (*webhookStore)(nil)
 
 // CreateWebhook implements store.WebhookStore.
-func (*webhookStore) CreateWebhook(ctx context.Context, h db.Handler, repoID int64, url string, secret string, contentType int, active bool) (int64, error) {
+func (*webhookStore) CreateWebhook(ctx context.Context, h db.Handler, repoID int64, url string, secret string, contentType int, refPatterns string, allRefs bool, active bool) (int64, error) {
 	var id int64
-	query := h.Rebind(`INSERT INTO webhooks (repo_id, url, secret, content_type, active, updated_at)
-			VALUES (?, ?, ?, ?, ?, CURRENT_TIMESTAMP) RETURNING id;`)
-	err := h.GetContext(ctx, &id, query, repoID, url, secret, contentType, active)
+	query := h.Rebind(`INSERT INTO webhooks (repo_id, url, secret, content_type, ref_patterns, all_refs, active, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP) RETURNING id;`)
+	err := h.GetContext(ctx, &id, query, repoID, url, secret, contentType, refPatterns, allRefs, active)
 	if err != nil {
 		return 0, err
 	}
@@ -75,6 +76,10 @@ func (*webhookStore) DeleteWebhookDeliveryByID(ctx context.Context, h db.Handler
 
 // DeleteWebhookEventsByWebhookID implements store.WebhookStore.
 func (*webhookStore) DeleteWebhookEventsByID(ctx context.Context, h db.Handler, ids []int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
 	query, args, err := sqlx.In(`DELETE FROM webhook_events WHERE id IN (?);`, ids)
 	if err != nil {
 		return err
@@ -158,8 +163,35 @@ func (*webhookStore) ListWebhookDeliveriesByWebhookID(ctx context.Context, h db.
 }
 
 // UpdateWebhookByID implements store.WebhookStore.
-func (*webhookStore) UpdateWebhookByID(ctx context.Context, h db.Handler, repoID int64, id int64, url string, secret string, contentType int, active bool) error {
-	query := h.Rebind(`UPDATE webhooks SET url = ?, secret = ?, content_type = ?, active = ?, updated_at = CURRENT_TIMESTAMP WHERE repo_id = ? AND id = ?;`)
-	_, err := h.ExecContext(ctx, query, url, secret, contentType, active, repoID, id)
+func (*webhookStore) UpdateWebhookByID(ctx context.Context, h db.Handler, repoID int64, id int64, url string, secret string, contentType int, refPatterns string, allRefs bool, active bool) error {
+	query := h.Rebind(`UPDATE webhooks SET url = ?, secret = ?, content_type = ?, ref_patterns = ?, all_refs = ?, active = ?, updated_at = CURRENT_TIMESTAMP WHERE repo_id = ? AND id = ?;`)
+	_, err := h.ExecContext(ctx, query, url, secret, contentType, refPatterns, allRefs, active, repoID, id)
+	return err
+}
+
+// GetWebhookLastDelivery implements store.WebhookStore.
+func (*webhookStore) GetWebhookLastDelivery(ctx context.Context, h db.Handler, webhookID int64) (models.WebhookLastDelivery, error) {
+	query := h.Rebind(`SELECT * FROM webhook_last_deliveries WHERE webhook_id = ?;`)
+	var d models.WebhookLastDelivery
+	err := h.GetContext(ctx, &d, query, webhookID)
+	return d, err
+}
+
+// SetWebhookLastDelivery implements store.WebhookStore.
+func (*webhookStore) SetWebhookLastDelivery(ctx context.Context, h db.Handler, webhookID int64, deliveryID uuid.UUID, event int, responseStatus int, requestError error, latencyMs int64) error {
+	query := h.Rebind(`INSERT INTO webhook_last_deliveries (webhook_id, delivery_id, event, response_status, request_error, latency_ms, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
+			ON CONFLICT (webhook_id) DO UPDATE SET
+				delivery_id = excluded.delivery_id,
+				event = excluded.event,
+				response_status = excluded.response_status,
+				request_error = excluded.request_error,
+				latency_ms = excluded.latency_ms,
+				updated_at = CURRENT_TIMESTAMP;`)
+	var reqErr sql.NullString
+	if requestError != nil {
+		reqErr = sql.NullString{String: requestError.Error(), Valid: true}
+	}
+	_, err := h.ExecContext(ctx, query, webhookID, deliveryID, event, responseStatus, reqErr, latencyMs)
 	return err
 }
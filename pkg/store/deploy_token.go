@@ -0,0 +1,32 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/charmbracelet/soft-serve/pkg/access"
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/db/models"
+)
+
+// DeployTokenStore is an interface for managing repository deploy tokens.
+type DeployTokenStore interface {
+	// CreateDeployToken creates a deploy token for a repository. The token
+	// is the hash of the token, and the fingerprint and public key are empty
+	// for tokens not mapped to an SSH key.
+	CreateDeployToken(ctx context.Context, h db.Handler, repo string, name string, token string, level access.AccessLevel, fingerprint string, publicKey string, expiresAt time.Time) error
+	// DeleteDeployToken deletes the named deploy token of a repository. It
+	// returns db.ErrRecordNotFound if there's no such token.
+	DeleteDeployToken(ctx context.Context, h db.Handler, repo string, name string) error
+	// GetDeployTokens returns the deploy tokens of a repository, oldest
+	// first.
+	GetDeployTokens(ctx context.Context, h db.Handler, repo string) ([]models.DeployToken, error)
+	// GetDeployTokenByToken returns the deploy token with the given hash.
+	GetDeployTokenByToken(ctx context.Context, h db.Handler, token string) (models.DeployToken, error)
+	// GetDeployTokensByFingerprint returns the deploy tokens mapped to the
+	// SSH key with the given SHA256 fingerprint.
+	GetDeployTokensByFingerprint(ctx context.Context, h db.Handler, fingerprint string) ([]models.DeployToken, error)
+	// TouchDeployToken sets the last time a deploy token was used, if it
+	// wasn't used since the given time.
+	TouchDeployToken(ctx context.Context, h db.Handler, id int64, usedAt time.Time, since time.Time) error
+}
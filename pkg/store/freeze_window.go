@@ -0,0 +1,20 @@
+package store
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/db/models"
+)
+
+// FreezeWindowStore is an interface for managing repository freeze windows.
+type FreezeWindowStore interface {
+	// GetFreezeWindowsByRepoID returns the freeze windows of a repository, by
+	// name.
+	GetFreezeWindowsByRepoID(ctx context.Context, h db.Handler, repoID int64) ([]models.FreezeWindow, error)
+	// CreateFreezeWindow creates a freeze window for a repository.
+	CreateFreezeWindow(ctx context.Context, h db.Handler, repoID int64, name string, refs string, schedule string, duration int64, warnOnly bool) error
+	// DeleteFreezeWindowByName deletes a freeze window of a repository by its
+	// name. It returns db.ErrRecordNotFound if the window doesn't exist.
+	DeleteFreezeWindowByName(ctx context.Context, h db.Handler, repoID int64, name string) error
+}
@@ -0,0 +1,28 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/db/models"
+)
+
+// InviteStore is an interface for managing key registration invites.
+type InviteStore interface {
+	// CreateInvite creates an invite for a username. A zero createdByID
+	// means the invite wasn't created by a user.
+	CreateInvite(ctx context.Context, h db.Handler, codeHash string, username string, admin bool, createdByID int64, expiresAt time.Time) error
+	// GetInviteByID returns the invite with the given ID.
+	GetInviteByID(ctx context.Context, h db.Handler, id int64) (models.Invite, error)
+	// GetInviteByCodeHash returns the invite with the given code hash.
+	GetInviteByCodeHash(ctx context.Context, h db.Handler, codeHash string) (models.Invite, error)
+	// GetInvites returns all invites, newest first.
+	GetInvites(ctx context.Context, h db.Handler) ([]models.Invite, error)
+	// UseInvite marks an unused invite as used by the key with the given
+	// fingerprint. It returns db.ErrRecordNotFound if the invite doesn't
+	// exist or was already used.
+	UseInvite(ctx context.Context, h db.Handler, id int64, fingerprint string) error
+	// DeleteInvite deletes an invite.
+	DeleteInvite(ctx context.Context, h db.Handler, id int64) error
+}
@@ -0,0 +1,28 @@
+package store
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/db/models"
+)
+
+// IssueStore is an interface for managing issues and their comments.
+type IssueStore interface {
+	// CreateIssue creates an open issue of a repository and returns its
+	// number, the next one in the repository. A zero userID means no user.
+	CreateIssue(ctx context.Context, h db.Handler, repoID int64, userID int64, title string, body string) (int64, error)
+	// SetIssueState sets the state of an issue.
+	SetIssueState(ctx context.Context, h db.Handler, id int64, state string) error
+	// GetIssue returns the issue of a repository with a number.
+	GetIssue(ctx context.Context, h db.Handler, repoID int64, number int64) (models.Issue, error)
+	// GetIssues returns the issues of a repository in a state, newest first.
+	// An empty state returns all the issues.
+	GetIssues(ctx context.Context, h db.Handler, repoID int64, state string) ([]models.Issue, error)
+
+	// CreateIssueComment adds a comment to an issue. A zero userID means no
+	// user.
+	CreateIssueComment(ctx context.Context, h db.Handler, issueID int64, userID int64, body string) error
+	// GetIssueComments returns the comments of an issue, oldest first.
+	GetIssueComments(ctx context.Context, h db.Handler, issueID int64) ([]models.IssueComment, error)
+}
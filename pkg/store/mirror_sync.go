@@ -0,0 +1,22 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/db/models"
+)
+
+// MirrorSyncStore is an interface for managing the sync history of pull
+// mirrors.
+type MirrorSyncStore interface {
+	// CreateMirrorSync records the result of a pull mirror sync.
+	CreateMirrorSync(ctx context.Context, h db.Handler, sync models.MirrorSync) error
+	// GetRecentMirrorSyncsByRepoID returns the latest syncs of a repository,
+	// newest first.
+	GetRecentMirrorSyncsByRepoID(ctx context.Context, h db.Handler, repoID int64, limit int) ([]models.MirrorSync, error)
+	// DeleteMirrorSyncsBefore deletes the syncs recorded before the given time
+	// and returns the number of deleted syncs.
+	DeleteMirrorSyncsBefore(ctx context.Context, h db.Handler, before time.Time) (int64, error)
+}
@@ -0,0 +1,25 @@
+package store
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/db/models"
+)
+
+// PushMirrorStore is an interface for managing repository push mirrors.
+type PushMirrorStore interface {
+	// GetPushMirrorsByRepoID returns all push mirrors for a repository.
+	GetPushMirrorsByRepoID(ctx context.Context, h db.Handler, repoID int64) ([]models.PushMirror, error)
+	// GetPushMirrorsByStatus returns all push mirrors with the given status.
+	GetPushMirrorsByStatus(ctx context.Context, h db.Handler, status string) ([]models.PushMirror, error)
+	// CreatePushMirror creates a push mirror for a repository.
+	CreatePushMirror(ctx context.Context, h db.Handler, repoID int64, name string, remoteURL string) error
+	// DeletePushMirrorByName deletes a push mirror for a repository by its name.
+	DeletePushMirrorByName(ctx context.Context, h db.Handler, repoID int64, name string) error
+	// SetPushMirrorsStatusByRepoID sets the status of all push mirrors for a
+	// repository and resets their attempts.
+	SetPushMirrorsStatusByRepoID(ctx context.Context, h db.Handler, repoID int64, status string) error
+	// UpdatePushMirrorResult records the result of a push mirror attempt.
+	UpdatePushMirrorResult(ctx context.Context, h db.Handler, id int64, status string, attempts int, lastErr error) error
+}
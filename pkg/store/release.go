@@ -0,0 +1,32 @@
+package store
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/db/models"
+)
+
+// ReleaseStore is an interface for managing releases and their assets.
+type ReleaseStore interface {
+	// CreateRelease creates a release of a repository for a tag. A zero
+	// userID means no user.
+	CreateRelease(ctx context.Context, h db.Handler, repoID int64, userID int64, tag string, title string, notes string) error
+	// UpdateRelease updates the title and the notes of a release.
+	UpdateRelease(ctx context.Context, h db.Handler, id int64, title string, notes string) error
+	// DeleteRelease deletes a release and its assets.
+	DeleteRelease(ctx context.Context, h db.Handler, id int64) error
+	// GetRelease returns the release of a repository for a tag.
+	GetRelease(ctx context.Context, h db.Handler, repoID int64, tag string) (models.Release, error)
+	// GetReleases returns the releases of a repository, newest first.
+	GetReleases(ctx context.Context, h db.Handler, repoID int64) ([]models.Release, error)
+
+	// SetReleaseAsset creates or replaces an asset of a release.
+	SetReleaseAsset(ctx context.Context, h db.Handler, releaseID int64, name string, size int64, sha256 string) error
+	// DeleteReleaseAsset deletes an asset of a release.
+	DeleteReleaseAsset(ctx context.Context, h db.Handler, releaseID int64, name string) error
+	// GetReleaseAsset returns an asset of a release.
+	GetReleaseAsset(ctx context.Context, h db.Handler, releaseID int64, name string) (models.ReleaseAsset, error)
+	// GetReleaseAssets returns the assets of a release, by name.
+	GetReleaseAssets(ctx context.Context, h db.Handler, releaseID int64) ([]models.ReleaseAsset, error)
+}
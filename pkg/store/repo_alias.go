@@ -0,0 +1,21 @@
+package store
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/db/models"
+)
+
+// RepoAliasStore is an interface for managing repository aliases.
+type RepoAliasStore interface {
+	// CreateRepoAlias adds an alias to a repository.
+	CreateRepoAlias(ctx context.Context, h db.Handler, repoID int64, alias string, message string) error
+	// DeleteRepoAlias removes an alias.
+	DeleteRepoAlias(ctx context.Context, h db.Handler, alias string) error
+	// GetRepoAlias returns an alias and the name of its repository.
+	GetRepoAlias(ctx context.Context, h db.Handler, alias string) (models.RepoAlias, error)
+	// GetRepoAliasesByRepoID returns the aliases of a repository, sorted by
+	// alias.
+	GetRepoAliasesByRepoID(ctx context.Context, h db.Handler, repoID int64) ([]models.RepoAlias, error)
+}
@@ -0,0 +1,30 @@
+package store
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/pkg/access"
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/db/models"
+)
+
+// RepoKeyStore is an interface for managing the repository access of SSH
+// public keys.
+type RepoKeyStore interface {
+	// SetRepoKey grants the key with the given SHA256 fingerprint an access
+	// level to a repository, replacing the level it already has.
+	SetRepoKey(ctx context.Context, h db.Handler, repo string, fingerprint string, publicKey string, level access.AccessLevel) error
+	// DeleteRepoKey removes the access of the key with the given SHA256
+	// fingerprint to a repository. It returns db.ErrRecordNotFound if the
+	// key has no access to the repository.
+	DeleteRepoKey(ctx context.Context, h db.Handler, repo string, fingerprint string) error
+	// GetRepoKeys returns the keys granted access to a repository, oldest
+	// first.
+	GetRepoKeys(ctx context.Context, h db.Handler, repo string) ([]models.RepoKey, error)
+	// GetRepoKeysByFingerprint returns the repository grants of the key with
+	// the given SHA256 fingerprint.
+	GetRepoKeysByFingerprint(ctx context.Context, h db.Handler, fingerprint string) ([]models.RepoKey, error)
+	// GetAllRepoKeys returns the repository grants of all keys, ordered by
+	// fingerprint.
+	GetAllRepoKeys(ctx context.Context, h db.Handler) ([]models.RepoKey, error)
+}
@@ -0,0 +1,24 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/db/models"
+)
+
+// RepoRenameStore is an interface for managing the rename history of
+// repositories.
+type RepoRenameStore interface {
+	// CreateRepoRename records that a repository was renamed from oldName.
+	CreateRepoRename(ctx context.Context, h db.Handler, repoID int64, oldName string) error
+	// DeleteRepoRename forgets a past repository name.
+	DeleteRepoRename(ctx context.Context, h db.Handler, oldName string) error
+	// GetRepoRename returns a past repository name and the current name of
+	// its repository.
+	GetRepoRename(ctx context.Context, h db.Handler, oldName string) (models.RepoRename, error)
+	// DeleteRepoRenamesBefore deletes the renames recorded before the given
+	// time and returns the number of deleted renames.
+	DeleteRepoRenamesBefore(ctx context.Context, h db.Handler, before time.Time) (int64, error)
+}
@@ -0,0 +1,18 @@
+package store
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/db/models"
+)
+
+// RepoSettingStore is an interface for managing repository settings.
+type RepoSettingStore interface {
+	// GetRepoSettingsByRepoID returns all the settings of a repository.
+	GetRepoSettingsByRepoID(ctx context.Context, h db.Handler, repoID int64) ([]models.RepoSetting, error)
+	// SetRepoSetting creates or updates a repository setting.
+	SetRepoSetting(ctx context.Context, h db.Handler, repoID int64, key string, value string) error
+	// DeleteRepoSetting deletes a repository setting.
+	DeleteRepoSetting(ctx context.Context, h db.Handler, repoID int64, key string) error
+}
@@ -0,0 +1,21 @@
+package store
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/db/models"
+)
+
+// RepoSizeStore is an interface for managing the cached repository sizes.
+type RepoSizeStore interface {
+	// GetRepoSize returns the cached size of a repository.
+	GetRepoSize(ctx context.Context, h db.Handler, repoID int64) (models.RepoSize, error)
+	// GetRepoSizes returns the cached sizes of all repositories, by name.
+	GetRepoSizes(ctx context.Context, h db.Handler) ([]models.RepoSize, error)
+	// GetUserRepoSizeTotal returns the total cached size of the repositories
+	// owned by a user.
+	GetUserRepoSizeTotal(ctx context.Context, h db.Handler, userID int64) (int64, error)
+	// SetRepoSize creates or updates the cached size of a repository.
+	SetRepoSize(ctx context.Context, h db.Handler, repoID int64, size int64) error
+}
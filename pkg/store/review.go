@@ -0,0 +1,18 @@
+package store
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/db/models"
+)
+
+// ReviewStore is an interface for managing the changes proposed for review.
+type ReviewStore interface {
+	// CreateReview records a change proposed to a branch of a repository and
+	// returns its number, the next one in the repository. A zero userID
+	// means no user.
+	CreateReview(ctx context.Context, h db.Handler, repoID int64, userID int64, targetBranch string, baseSHA string, commitSHA string) (int64, error)
+	// GetReviews returns the reviews of a repository, newest first.
+	GetReviews(ctx context.Context, h db.Handler, repoID int64) ([]models.Review, error)
+}
@@ -0,0 +1,21 @@
+package store
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/db/models"
+)
+
+// RevokedKeyStore is an interface for managing revoked SSH public keys.
+type RevokedKeyStore interface {
+	// CreateRevokedKey revokes the key with the given SHA256 fingerprint.
+	CreateRevokedKey(ctx context.Context, h db.Handler, fingerprint string) error
+	// DeleteRevokedKey restores the key with the given SHA256 fingerprint.
+	DeleteRevokedKey(ctx context.Context, h db.Handler, fingerprint string) error
+	// GetRevokedKeys returns the revoked keys, oldest first.
+	GetRevokedKeys(ctx context.Context, h db.Handler) ([]models.RevokedKey, error)
+	// IsKeyRevoked returns whether the key with the given SHA256 fingerprint
+	// is revoked.
+	IsKeyRevoked(ctx context.Context, h db.Handler, fingerprint string) (bool, error)
+}
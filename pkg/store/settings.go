@@ -13,4 +13,8 @@ type SettingStore interface {
 	SetAnonAccess(ctx context.Context, h db.Handler, level access.AccessLevel) error
 	GetAllowKeylessAccess(ctx context.Context, h db.Handler) (bool, error)
 	SetAllowKeylessAccess(ctx context.Context, h db.Handler, allow bool) error
+	GetLockdown(ctx context.Context, h db.Handler) (bool, error)
+	SetLockdown(ctx context.Context, h db.Handler, enabled bool) error
+	GetTerms(ctx context.Context, h db.Handler) (version string, text string, err error)
+	SetTerms(ctx context.Context, h db.Handler, version string, text string) error
 }
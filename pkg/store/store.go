@@ -9,4 +9,23 @@ type Store interface {
 	LFSStore
 	AccessTokenStore
 	WebhookStore
+	PushMirrorStore
+	RepoSettingStore
+	CloneEventStore
+	AuditEventStore
+	AuditSegmentStore
+	RevokedKeyStore
+	CommitStatusStore
+	RepoAliasStore
+	TermsStore
+	RepoRenameStore
+	MirrorSyncStore
+	InviteStore
+	RepoKeyStore
+	DeployTokenStore
+	ReleaseStore
+	RepoSizeStore
+	IssueStore
+	ReviewStore
+	FreezeWindowStore
 }
@@ -0,0 +1,22 @@
+package store
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/db/models"
+)
+
+// TermsStore is an interface for managing the acceptances of the server
+// terms.
+type TermsStore interface {
+	// CreateTermsAcceptance records that a user accepted a version of the
+	// terms. Accepting a version twice keeps the first acceptance.
+	CreateTermsAcceptance(ctx context.Context, h db.Handler, userID int64, version string) error
+	// GetTermsAcceptance returns the acceptance of a version of the terms by
+	// a user.
+	GetTermsAcceptance(ctx context.Context, h db.Handler, userID int64, version string) (models.TermsAcceptance, error)
+	// GetTermsAcceptances returns the acceptances of a version of the terms,
+	// oldest first.
+	GetTermsAcceptances(ctx context.Context, h db.Handler, version string) ([]models.TermsAcceptance, error)
+}
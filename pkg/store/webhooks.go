@@ -17,9 +17,9 @@ type WebhookStore interface {
 	// GetWebhooksByRepoIDWhereEvent returns all webhooks for a repository where event is in the events.
 	GetWebhooksByRepoIDWhereEvent(ctx context.Context, h db.Handler, repoID int64, events []int) ([]models.Webhook, error)
 	// CreateWebhook creates a webhook.
-	CreateWebhook(ctx context.Context, h db.Handler, repoID int64, url string, secret string, contentType int, active bool) (int64, error)
+	CreateWebhook(ctx context.Context, h db.Handler, repoID int64, url string, secret string, contentType int, refPatterns string, allRefs bool, active bool) (int64, error)
 	// UpdateWebhookByID updates a webhook by its ID.
-	UpdateWebhookByID(ctx context.Context, h db.Handler, repoID int64, id int64, url string, secret string, contentType int, active bool) error
+	UpdateWebhookByID(ctx context.Context, h db.Handler, repoID int64, id int64, url string, secret string, contentType int, refPatterns string, allRefs bool, active bool) error
 	// DeleteWebhookByID deletes a webhook by its ID.
 	DeleteWebhookByID(ctx context.Context, h db.Handler, id int64) error
 	// DeleteWebhookForRepoByID deletes a webhook for a repository by its ID.
@@ -45,4 +45,9 @@ type WebhookStore interface {
 	CreateWebhookDelivery(ctx context.Context, h db.Handler, id uuid.UUID, webhookID int64, event int, url string, method string, requestError error, requestHeaders string, requestBody string, responseStatus int, responseHeaders string, responseBody string) error
 	// DeleteWebhookDeliveryByID deletes a webhook delivery by its ID.
 	DeleteWebhookDeliveryByID(ctx context.Context, h db.Handler, webhookID int64, id uuid.UUID) error
+
+	// GetWebhookLastDelivery returns the last delivery result of a webhook.
+	GetWebhookLastDelivery(ctx context.Context, h db.Handler, webhookID int64) (models.WebhookLastDelivery, error)
+	// SetWebhookLastDelivery sets the last delivery result of a webhook.
+	SetWebhookLastDelivery(ctx context.Context, h db.Handler, webhookID int64, deliveryID uuid.UUID, event int, responseStatus int, requestError error, latencyMs int64) error
 }
@@ -0,0 +1,95 @@
+// Package tracing traces SSH connections, git operations, hooks, and backend
+// calls with OpenTelemetry.
+//
+// Spans are started through the global tracer provider, which is a no-op
+// unless tracing is enabled, so spans cost next to nothing by default.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/charmbracelet/soft-serve/pkg/config"
+	"github.com/charmbracelet/soft-serve/pkg/version"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName is the name of the Soft Serve tracer.
+const tracerName = "github.com/charmbracelet/soft-serve"
+
+// Init sets up the global tracer provider to export spans over OTLP. It
+// returns a function flushing the pending spans and shutting the provider
+// down. It's a no-op when tracing is disabled.
+func Init(ctx context.Context, cfg config.TracingConfig) (func(context.Context) error, error) {
+	if !cfg.Enabled {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exporter, err := newExporter(ctx, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("create otlp trace exporter: %w", err)
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+		sdktrace.WithResource(resource.NewSchemaless(
+			attribute.String("service.name", "soft-serve"),
+			attribute.String("service.version", version.Version),
+		)),
+	)
+	otel.SetTracerProvider(tp)
+
+	return tp.Shutdown, nil
+}
+
+// newExporter returns an OTLP span exporter for the configured protocol.
+func newExporter(ctx context.Context, cfg config.TracingConfig) (sdktrace.SpanExporter, error) {
+	switch cfg.Protocol {
+	case config.OTLPProtocolGRPC:
+		return otlptracegrpc.New(ctx, otlptracegrpc.WithEndpointURL(cfg.Endpoint))
+	default:
+		opts := []otlptracehttp.Option{otlptracehttp.WithEndpointURL(cfg.Endpoint)}
+		// Collectors receive traces on /v1/traces unless the endpoint has
+		// another path.
+		if u, err := url.Parse(cfg.Endpoint); err == nil && (u.Path == "" || u.Path == "/") {
+			opts = append(opts, otlptracehttp.WithURLPath("/v1/traces"))
+		}
+		return otlptracehttp.New(ctx, opts...)
+	}
+}
+
+// Start starts a span and returns a context holding it.
+func Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, name, opts...)
+}
+
+// End ends a span. A non-nil error is recorded and marks the span as failed.
+func End(span trace.Span, err error, opts ...trace.SpanEndOption) {
+	if !span.IsRecording() {
+		return
+	}
+
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	} else {
+		span.SetStatus(codes.Ok, "")
+	}
+	span.End(opts...)
+}
+
+// SetAttributes sets attributes on the span of a context, if any.
+func SetAttributes(ctx context.Context, attrs ...attribute.KeyValue) {
+	if span := trace.SpanFromContext(ctx); span.IsRecording() {
+		span.SetAttributes(attrs...)
+	}
+}
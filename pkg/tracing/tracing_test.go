@@ -0,0 +1,60 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/charmbracelet/soft-serve/pkg/config"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestSpans(t *testing.T) {
+	sr := tracetest.NewSpanRecorder()
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr)))
+	t.Cleanup(func() { otel.SetTracerProvider(prev) })
+
+	ctx, parent := Start(context.Background(), "parent")
+	SetAttributes(ctx, attribute.String("repo", "repo1"))
+	_, child := Start(ctx, "child")
+	End(child, errors.New("boom"))
+	End(parent, nil)
+
+	spans := sr.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("expected 2 spans, got %d", len(spans))
+	}
+
+	c, p := spans[0], spans[1]
+	if c.Parent().SpanID() != p.SpanContext().SpanID() {
+		t.Errorf("expected child span to be a child of the parent span")
+	}
+	if c.Status().Code != codes.Error || len(c.Events()) != 1 {
+		t.Errorf("expected child span to record the error, got %v", c.Status())
+	}
+	if p.Status().Code != codes.Ok {
+		t.Errorf("expected parent span to be ok, got %v", p.Status())
+	}
+	if attrs := p.Attributes(); len(attrs) != 1 || attrs[0] != attribute.String("repo", "repo1") {
+		t.Errorf("unexpected parent span attributes: %v", attrs)
+	}
+}
+
+func TestInitDisabled(t *testing.T) {
+	prev := otel.GetTracerProvider()
+	shutdown, err := Init(context.Background(), config.TracingConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if otel.GetTracerProvider() != prev {
+		t.Errorf("expected the tracer provider to be unchanged")
+	}
+}
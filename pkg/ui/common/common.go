@@ -3,6 +3,7 @@ package common
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/alecthomas/chroma/v2/lexers"
 	"github.com/charmbracelet/lipgloss"
@@ -23,10 +24,21 @@ type contextKey struct {
 
 // Keys to use for context.Context.
 var (
-	ConfigKey = &contextKey{"config"}
-	RepoKey   = &contextKey{"repo"}
+	ConfigKey   = &contextKey{"config"}
+	RepoKey     = &contextKey{"repo"}
+	SessionsKey = &contextKey{"sessions"}
 )
 
+// Session is an active session of the server.
+type Session struct {
+	User        string
+	Fingerprint string
+	RemoteAddr  string
+	// Command is the command of the session, empty for the TUI.
+	Command string
+	Started time.Time
+}
+
 // Common is a struct all components should embed.
 type Common struct {
 	ctx           context.Context
@@ -102,6 +114,15 @@ func (c *Common) PublicKey() ssh.PublicKey {
 	return nil
 }
 
+// Sessions returns the active sessions of the server, or nil if they aren't
+// tracked.
+func (c *Common) Sessions() []Session {
+	if f, ok := c.ctx.Value(SessionsKey).(func() []Session); ok {
+		return f()
+	}
+	return nil
+}
+
 // CloneCmd returns the clone command string.
 func (c *Common) CloneCmd(publicURL, name string) string {
 	if c.HideCloneCmd {
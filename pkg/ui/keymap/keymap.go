@@ -22,7 +22,17 @@ type KeyMap struct {
 	SelectItem key.Binding
 	BackItem   key.Binding
 
-	Copy key.Binding
+	Copy        key.Binding
+	CopySSHURL  key.Binding
+	CopyHTTPURL key.Binding
+
+	AccessFilter key.Binding
+	Compare      key.Binding
+
+	Refresh        key.Binding
+	ToggleLockdown key.Binding
+	RevokeKey      key.Binding
+	Confirm        key.Binding
 }
 
 // DefaultKeyMap returns the default key map.
@@ -226,5 +236,85 @@ func DefaultKeyMap() *KeyMap {
 		),
 	)
 
+	km.CopySSHURL = key.NewBinding(
+		key.WithKeys(
+			"y",
+		),
+		key.WithHelp(
+			"y",
+			"copy ssh url",
+		),
+	)
+
+	km.CopyHTTPURL = key.NewBinding(
+		key.WithKeys(
+			"Y",
+		),
+		key.WithHelp(
+			"Y",
+			"copy http url",
+		),
+	)
+
+	km.AccessFilter = key.NewBinding(
+		key.WithKeys(
+			"a",
+		),
+		key.WithHelp(
+			"a",
+			"filter access",
+		),
+	)
+
+	km.Compare = key.NewBinding(
+		key.WithKeys(
+			"d",
+		),
+		key.WithHelp(
+			"d",
+			"compare",
+		),
+	)
+
+	km.Refresh = key.NewBinding(
+		key.WithKeys(
+			"r",
+		),
+		key.WithHelp(
+			"r",
+			"refresh",
+		),
+	)
+
+	km.ToggleLockdown = key.NewBinding(
+		key.WithKeys(
+			"m",
+		),
+		key.WithHelp(
+			"m",
+			"toggle lockdown",
+		),
+	)
+
+	km.RevokeKey = key.NewBinding(
+		key.WithKeys(
+			"x",
+		),
+		key.WithHelp(
+			"x",
+			"revoke key",
+		),
+	)
+
+	km.Confirm = key.NewBinding(
+		key.WithKeys(
+			"y",
+		),
+		key.WithHelp(
+			"y",
+			"confirm",
+		),
+	)
+
 	return km
 }
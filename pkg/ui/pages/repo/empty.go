@@ -2,12 +2,24 @@ package repo
 
 import (
 	"fmt"
+	"strings"
 
-	"github.com/charmbracelet/soft-serve/pkg/config"
 	"github.com/charmbracelet/soft-serve/pkg/ui/common"
 )
 
-func defaultEmptyRepoMsg(cfg *config.Config, repo string) string {
+func defaultEmptyRepoMsg(c common.Common, repo string) string {
+	url := common.RepoURL(c.Config().SSH.PublicURL, repo)
+	clone := "git clone " + url
+	if be := c.Backend(); be != nil {
+		if ci, err := be.CloneInstructions(c.Context(), repo); err == nil && len(ci.URLs) > 0 {
+			cmds := make([]string, 0, len(ci.URLs))
+			for _, u := range ci.URLs {
+				cmds = append(cmds, fmt.Sprintf("# over %s\n%s", u.Protocol, u.Command))
+			}
+			clone = strings.Join(cmds, "\n\n")
+		}
+	}
+
 	return fmt.Sprintf(`# Quick Start
 
 Get started by cloning this repository, add your files, commit, and push.
@@ -15,7 +27,7 @@ Get started by cloning this repository, add your files, commit, and push.
 ## Clone this repository.
 
 `+"```"+`sh
-git clone %[1]s
+%[2]s
 `+"```"+`
 
 ## Creating a new repository on the command line
@@ -36,5 +48,5 @@ git push -u origin main
 git remote add origin %[1]s
 git push -u origin main
 `+"```"+`
-`, common.RepoURL(cfg.SSH.PublicURL, repo))
+`, url, clone)
 }
@@ -0,0 +1,288 @@
+package repo
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/soft-serve/pkg/backend"
+	"github.com/charmbracelet/soft-serve/pkg/proto"
+	"github.com/charmbracelet/soft-serve/pkg/ui/common"
+	"github.com/charmbracelet/soft-serve/pkg/ui/components/code"
+	"github.com/charmbracelet/soft-serve/pkg/ui/components/selector"
+	"github.com/dustin/go-humanize"
+)
+
+type issuesState int
+
+const (
+	issuesStateLoading issuesState = iota
+	issuesStateList
+	issuesStateView
+)
+
+// IssueListMsg is a message sent when the issues are loaded.
+type IssueListMsg []backend.Issue
+
+// IssueMsg is a message sent when an issue and its comments are loaded.
+type IssueMsg backend.Issue
+
+// Issues is the issues component page.
+type Issues struct {
+	common  common.Common
+	code    *code.Code
+	repo    proto.Repository
+	spinner spinner.Model
+	list    *selector.Selector
+	state   issuesState
+}
+
+// NewIssues creates a new issues model.
+func NewIssues(common common.Common) *Issues {
+	code := code.New(common, "", "")
+	code.UseGlamour = true
+	s := spinner.New(spinner.WithSpinner(spinner.Dot),
+		spinner.WithStyle(common.Styles.Spinner))
+	selector := selector.New(common, []selector.IdentifiableItem{}, IssueItemDelegate{&common})
+	selector.SetShowFilter(false)
+	selector.SetShowHelp(false)
+	selector.SetShowPagination(false)
+	selector.SetShowStatusBar(false)
+	selector.SetShowTitle(false)
+	selector.SetFilteringEnabled(false)
+	selector.DisableQuitKeybindings()
+	selector.KeyMap.NextPage = common.KeyMap.NextPage
+	selector.KeyMap.PrevPage = common.KeyMap.PrevPage
+	return &Issues{
+		code:    code,
+		common:  common,
+		spinner: s,
+		list:    selector,
+	}
+}
+
+// Path implements common.TabComponent.
+func (r *Issues) Path() string {
+	return ""
+}
+
+// TabName returns the name of the tab.
+func (r *Issues) TabName() string {
+	return "Issues"
+}
+
+// SetSize implements common.Component.
+func (r *Issues) SetSize(width, height int) {
+	r.common.SetSize(width, height)
+	r.code.SetSize(width, height)
+	r.list.SetSize(width, height)
+}
+
+// ShortHelp implements help.KeyMap.
+func (r *Issues) ShortHelp() []key.Binding {
+	return []key.Binding{
+		r.common.KeyMap.Select,
+		r.common.KeyMap.Back,
+		r.common.KeyMap.UpDown,
+	}
+}
+
+// FullHelp implements help.KeyMap.
+func (r *Issues) FullHelp() [][]key.Binding {
+	b := [][]key.Binding{
+		{
+			r.common.KeyMap.Select,
+			r.common.KeyMap.Back,
+			r.common.KeyMap.Copy,
+		},
+		{
+			r.code.KeyMap.Down,
+			r.code.KeyMap.Up,
+			r.common.KeyMap.GotoTop,
+			r.common.KeyMap.GotoBottom,
+		},
+	}
+	return b
+}
+
+// StatusBarValue implements common.Component.
+func (r *Issues) StatusBarValue() string {
+	item, ok := r.list.SelectedItem().(IssueItem)
+	if !ok {
+		return " "
+	}
+	return item.Title()
+}
+
+// StatusBarInfo implements common.Component.
+func (r *Issues) StatusBarInfo() string {
+	switch r.state {
+	case issuesStateList:
+		totalPages := r.list.TotalPages()
+		if totalPages <= 1 {
+			return "p. 1/1"
+		}
+		return fmt.Sprintf("p. %d/%d", r.list.Page()+1, totalPages)
+	case issuesStateView:
+		return fmt.Sprintf("☰ %d%%", r.code.ScrollPosition())
+	default:
+		return ""
+	}
+}
+
+// SpinnerID implements common.Component.
+func (r *Issues) SpinnerID() int {
+	return r.spinner.ID()
+}
+
+// Init initializes the model.
+func (r *Issues) Init() tea.Cmd {
+	r.state = issuesStateLoading
+	return tea.Batch(r.spinner.Tick, r.fetchIssues)
+}
+
+// Update updates the model.
+func (r *Issues) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	cmds := make([]tea.Cmd, 0)
+	switch msg := msg.(type) {
+	case RepoMsg:
+		r.repo = msg
+	case RefMsg, EmptyRepoMsg:
+		r.list.Select(0)
+		cmds = append(cmds, r.Init())
+	case tea.WindowSizeMsg:
+		r.SetSize(msg.Width, msg.Height)
+	case spinner.TickMsg:
+		if r.state == issuesStateLoading && r.spinner.ID() == msg.ID {
+			sp, cmd := r.spinner.Update(msg)
+			r.spinner = sp
+			if cmd != nil {
+				cmds = append(cmds, cmd)
+			}
+		}
+	case tea.KeyMsg:
+		switch r.state {
+		case issuesStateList, issuesStateView:
+			switch {
+			case key.Matches(msg, r.common.KeyMap.BackItem):
+				cmds = append(cmds, goBackCmd)
+			case key.Matches(msg, r.common.KeyMap.Copy):
+				if item, ok := r.list.SelectedItem().(IssueItem); ok {
+					cmds = append(cmds, copyCmd(item.ID(), "Issue number copied to clipboard"))
+				}
+			}
+		}
+	case IssueListMsg:
+		r.state = issuesStateList
+		items := make([]selector.IdentifiableItem, len(msg))
+		for i, issue := range msg {
+			items[i] = IssueItem{issue}
+		}
+		cmds = append(cmds, r.list.SetItems(items))
+	case IssueMsg:
+		r.state = issuesStateView
+		cmds = append(cmds, r.code.SetContent(issueMarkdown(backend.Issue(msg)), ".md"))
+		r.code.GotoTop()
+	case selector.SelectMsg:
+		switch item := msg.IdentifiableItem.(type) {
+		case IssueItem:
+			cmds = append(cmds, r.fetchIssue(item.Number))
+		}
+	case GoBackMsg:
+		if r.state == issuesStateList {
+			r.list.Select(0)
+		}
+		if r.state != issuesStateLoading {
+			r.state = issuesStateList
+		}
+	}
+	switch r.state {
+	case issuesStateList:
+		l, cmd := r.list.Update(msg)
+		r.list = l.(*selector.Selector)
+		if cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+	case issuesStateView:
+		c, cmd := r.code.Update(msg)
+		r.code = c.(*code.Code)
+		if cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+	}
+	return r, tea.Batch(cmds...)
+}
+
+// View returns the view.
+func (r *Issues) View() string {
+	switch r.state {
+	case issuesStateLoading:
+		return renderLoading(r.common, r.spinner)
+	case issuesStateList:
+		if len(r.list.Items()) == 0 {
+			return r.common.Styles.NoContent.Render("No issues found.")
+		}
+		return r.list.View()
+	case issuesStateView:
+		return r.code.View()
+	}
+	return ""
+}
+
+// issueMarkdown renders an issue and its comments as markdown.
+func issueMarkdown(issue backend.Issue) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# %s #%d\n\n", issue.Title, issue.Number)
+
+	info := fmt.Sprintf("**%s**, opened %s", issue.State, humanize.Time(issue.CreatedAt))
+	if issue.Username != "" {
+		info += " by " + issue.Username
+	}
+	fmt.Fprintf(&sb, "%s\n\n", info)
+
+	if body := strings.TrimSpace(issue.Body); body != "" {
+		fmt.Fprintf(&sb, "%s\n\n", body)
+	}
+
+	for _, c := range issue.Comments {
+		by := c.Username
+		if by == "" {
+			by = "Someone"
+		}
+		fmt.Fprintf(&sb, "---\n\n**%s** commented %s\n\n%s\n\n", by, humanize.Time(c.CreatedAt), strings.TrimSpace(c.Body))
+	}
+
+	return sb.String()
+}
+
+func (r *Issues) fetchIssues() tea.Msg {
+	be := r.common.Backend()
+	if r.repo == nil || be == nil {
+		return IssueListMsg(nil)
+	}
+
+	issues, err := be.Issues(r.common.Context(), r.repo.Name(), "")
+	if err != nil {
+		return common.ErrorMsg(err)
+	}
+
+	return IssueListMsg(issues)
+}
+
+func (r *Issues) fetchIssue(number int64) tea.Cmd {
+	return func() tea.Msg {
+		be := r.common.Backend()
+		if r.repo == nil || be == nil {
+			return nil
+		}
+
+		issue, err := be.Issue(r.common.Context(), r.repo.Name(), number)
+		if err != nil {
+			return common.ErrorMsg(err)
+		}
+
+		return IssueMsg(issue)
+	}
+}
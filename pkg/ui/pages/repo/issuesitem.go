@@ -0,0 +1,105 @@
+package repo
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/soft-serve/pkg/backend"
+	"github.com/charmbracelet/soft-serve/pkg/ui/common"
+	"github.com/dustin/go-humanize"
+	"github.com/dustin/go-humanize/english"
+)
+
+// IssueItem represents an issue item.
+type IssueItem struct{ backend.Issue }
+
+// ID returns the ID of the issue item.
+func (i IssueItem) ID() string {
+	return strconv.FormatInt(i.Number, 10)
+}
+
+// Title returns the title of the issue item.
+func (i IssueItem) Title() string {
+	return i.Issue.Title
+}
+
+// Description returns the description of the issue item.
+func (i IssueItem) Description() string {
+	return ""
+}
+
+// FilterValue implements list.Item.
+func (i IssueItem) FilterValue() string { return i.Issue.Title }
+
+// IssueItemDelegate is a delegate for issue items.
+type IssueItemDelegate struct {
+	common *common.Common
+}
+
+// Height returns the height of the issue item list. Implements list.ItemDelegate.
+func (d IssueItemDelegate) Height() int { return 1 }
+
+// Spacing implements list.ItemDelegate.
+func (d IssueItemDelegate) Spacing() int { return 0 }
+
+// Update implements list.ItemDelegate.
+func (d IssueItemDelegate) Update(msg tea.Msg, m *list.Model) tea.Cmd {
+	item, ok := m.SelectedItem().(IssueItem)
+	if !ok {
+		return nil
+	}
+
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, d.common.KeyMap.Copy):
+			return copyCmd(item.ID(), fmt.Sprintf("Issue number %d copied to clipboard", item.Number))
+		}
+	}
+
+	return nil
+}
+
+// Render implements list.ItemDelegate.
+func (d IssueItemDelegate) Render(w io.Writer, m list.Model, index int, listItem list.Item) {
+	item, ok := listItem.(IssueItem)
+	if !ok {
+		return
+	}
+
+	s := d.common.Styles.Issue
+
+	st := s.Normal.Title
+	selector := " "
+	if index == m.Index() {
+		selector = "> "
+		st = s.Active.Title
+	}
+
+	selector = s.Selector.Render(selector)
+	number := s.Number.Render(fmt.Sprintf("#%d", item.Number))
+	title := st.Render(item.Issue.Title)
+	if item.State == backend.IssueStateClosed {
+		title += s.Closed.Render(item.State)
+	}
+	info := s.Info.Render(fmt.Sprintf("%s, %s",
+		english.Plural(int(item.CommentCount), "comment", ""),
+		humanize.Time(item.CreatedAt),
+	))
+	fmt.Fprint(w, d.common.Zone.Mark(
+		item.ID(),
+		common.TruncateString(fmt.Sprintf("%s%s%s%s",
+			selector,
+			number,
+			title,
+			info,
+		), m.Width()-
+			s.Selector.GetWidth()-
+			st.GetHorizontalFrameSize(),
+		),
+	))
+}
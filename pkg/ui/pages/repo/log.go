@@ -11,6 +11,7 @@ import (
 	gansi "github.com/charmbracelet/glamour/ansi"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/charmbracelet/soft-serve/git"
+	"github.com/charmbracelet/soft-serve/pkg/backend"
 	"github.com/charmbracelet/soft-serve/pkg/proto"
 	"github.com/charmbracelet/soft-serve/pkg/ui/common"
 	"github.com/charmbracelet/soft-serve/pkg/ui/components/footer"
@@ -490,15 +491,45 @@ func (l *Log) renderCommit(c *git.Commit) string {
 	// FIXME: lipgloss prints empty lines when CRLF is used
 	// sanitize commit message from CRLF
 	msg := strings.ReplaceAll(c.Message, "\r\n", "\n")
-	s.WriteString(fmt.Sprintf("%s\n%s\n%s\n%s\n",
+	s.WriteString(fmt.Sprintf("%s\n%s\n%s\n%s%s\n",
 		l.common.Styles.Log.CommitHash.Render("commit "+c.ID.String()),
 		l.common.Styles.Log.CommitAuthor.Render(fmt.Sprintf("Author: %s <%s>", c.Author.Name, c.Author.Email)),
 		l.common.Styles.Log.CommitDate.Render("Date:   "+c.Committer.When.Format(time.UnixDate)),
+		l.renderStatuses(c),
 		l.common.Styles.Log.CommitBody.Render(msg),
 	))
 	return wrap.String(s.String(), l.common.Width-2)
 }
 
+// renderStatuses renders the statuses reported for a commit, if any.
+func (l *Log) renderStatuses(c *git.Commit) string {
+	be := l.common.Backend()
+	if be == nil || l.repo == nil {
+		return ""
+	}
+
+	statuses, err := be.CommitStatuses(l.common.Context(), l.repo.Name(), c.ID.String())
+	if err != nil {
+		l.common.Logger.Debugf("ui: error loading commit statuses: %v", err)
+		return ""
+	}
+
+	if len(statuses) == 0 {
+		return ""
+	}
+
+	s := strings.Builder{}
+	s.WriteString(l.common.Styles.Log.CommitDate.Render("Status: "+backend.CombinedCommitState(statuses)) + "\n")
+	for _, st := range statuses {
+		line := fmt.Sprintf("  %s: %s", st.Context, st.State)
+		if st.Description != "" {
+			line += " - " + st.Description
+		}
+		s.WriteString(l.common.Styles.Log.CommitDate.Render(line) + "\n")
+	}
+	return s.String()
+}
+
 func renderSummary(diff *git.Diff, styles *styles.Styles, width int) string {
 	stats := strings.Split(diff.Stats().String(), "\n")
 	for i, line := range stats {
@@ -107,8 +107,9 @@ func (r *Readme) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.WindowSizeMsg:
 		r.SetSize(msg.Width, msg.Height)
 	case EmptyRepoMsg:
+		r.isLoading = false
 		cmds = append(cmds,
-			r.code.SetContent(defaultEmptyRepoMsg(r.common.Config(),
+			r.code.SetContent(defaultEmptyRepoMsg(r.common,
 				r.repo.Name()), ".md"),
 		)
 	case ReadmeMsg:
@@ -8,9 +8,12 @@ import (
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
 	"github.com/charmbracelet/soft-serve/git"
+	"github.com/charmbracelet/soft-serve/pkg/backend"
 	"github.com/charmbracelet/soft-serve/pkg/proto"
 	"github.com/charmbracelet/soft-serve/pkg/ui/common"
+	"github.com/charmbracelet/soft-serve/pkg/ui/components/code"
 	"github.com/charmbracelet/soft-serve/pkg/ui/components/selector"
 )
 
@@ -23,16 +26,26 @@ type RefItemsMsg struct {
 	items  []selector.IdentifiableItem
 }
 
+// RefCompareMsg is a message that contains the diff between the current
+// reference and a reference of the list.
+type RefCompareMsg struct {
+	prefix string
+	title  string
+	backend.Comparison
+}
+
 // Refs is a component that displays a list of references.
 type Refs struct {
 	common    common.Common
 	selector  *selector.Selector
+	code      *code.Code
 	repo      proto.Repository
 	ref       *git.Reference
 	activeRef *git.Reference
 	refPrefix string
 	spinner   spinner.Model
 	isLoading bool
+	compare   *RefCompareMsg
 }
 
 // NewRefs creates a new Refs component.
@@ -51,6 +64,7 @@ func NewRefs(common common.Common, refPrefix string) *Refs {
 	s.SetFilteringEnabled(false)
 	s.DisableQuitKeybindings()
 	r.selector = s
+	r.code = code.New(common, "", "")
 	sp := spinner.New(spinner.WithSpinner(spinner.Dot),
 		spinner.WithStyle(common.Styles.Spinner))
 	r.spinner = sp
@@ -76,10 +90,19 @@ func (r *Refs) TabName() string {
 func (r *Refs) SetSize(width, height int) {
 	r.common.SetSize(width, height)
 	r.selector.SetSize(width, height)
+	r.code.SetSize(width, height)
 }
 
 // ShortHelp implements help.KeyMap.
 func (r *Refs) ShortHelp() []key.Binding {
+	if r.compare != nil {
+		return []key.Binding{
+			r.common.KeyMap.UpDown,
+			r.common.KeyMap.BackItem,
+			r.common.KeyMap.GotoTop,
+			r.common.KeyMap.GotoBottom,
+		}
+	}
 	copyKey := r.common.KeyMap.Copy
 	copyKey.SetHelp("c", "copy ref")
 	k := r.selector.KeyMap
@@ -88,16 +111,36 @@ func (r *Refs) ShortHelp() []key.Binding {
 		k.CursorUp,
 		k.CursorDown,
 		copyKey,
+		r.common.KeyMap.Compare,
 	}
 }
 
 // FullHelp implements help.KeyMap.
 func (r *Refs) FullHelp() [][]key.Binding {
+	if r.compare != nil {
+		copyKey := r.common.KeyMap.Copy
+		copyKey.SetHelp("c", "copy patch")
+		return [][]key.Binding{
+			{
+				r.common.KeyMap.BackItem,
+				copyKey,
+			},
+			{
+				r.code.KeyMap.Down,
+				r.code.KeyMap.Up,
+				r.common.KeyMap.GotoTop,
+				r.common.KeyMap.GotoBottom,
+			},
+		}
+	}
 	copyKey := r.common.KeyMap.Copy
 	copyKey.SetHelp("c", "copy ref")
 	k := r.selector.KeyMap
 	return [][]key.Binding{
-		{r.common.KeyMap.SelectItem},
+		{
+			r.common.KeyMap.SelectItem,
+			r.common.KeyMap.Compare,
+		},
 		{
 			k.CursorUp,
 			k.CursorDown,
@@ -125,8 +168,10 @@ func (r *Refs) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case RepoMsg:
 		r.selector.Select(0)
 		r.repo = msg
+		r.compare = nil
 	case RefMsg:
 		r.ref = msg
+		r.compare = nil
 		cmds = append(cmds, r.Init())
 	case tea.WindowSizeMsg:
 		r.SetSize(msg.Width, msg.Height)
@@ -154,9 +199,42 @@ func (r *Refs) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 	case tea.KeyMsg:
 		switch {
+		case r.compare != nil:
+			switch {
+			case key.Matches(msg, r.common.KeyMap.BackItem):
+				cmds = append(cmds, goBackCmd)
+			case key.Matches(msg, r.common.KeyMap.Copy):
+				cmds = append(cmds, copyCmd(r.compare.Diff.Patch(), "Patch copied to clipboard"))
+			}
 		case key.Matches(msg, r.common.KeyMap.SelectItem):
 			cmds = append(cmds, r.selector.SelectItemCmd)
+		case key.Matches(msg, r.common.KeyMap.Compare):
+			if r.ref != nil && r.activeRef != nil {
+				r.isLoading = true
+				cmds = append(cmds, r.spinner.Tick, r.compareCmd(r.ref, r.activeRef))
+			}
+		}
+	case RefCompareMsg:
+		if r.refPrefix == msg.prefix {
+			r.isLoading = false
+			r.compare = &msg
+			content := lipgloss.JoinVertical(lipgloss.Top,
+				r.common.Styles.Log.CommitHash.Render(msg.title),
+				"",
+				renderSummary(msg.Diff, r.common.Styles, r.common.Width),
+				renderDiff(msg.Diff, r.common.Width),
+			)
+			if msg.Diff.IsIncomplete() {
+				content += "\n" + r.common.Styles.Log.CommitDate.Render(
+					fmt.Sprintf("This diff is too large, only the first %d files are shown.", len(msg.Diff.Files)))
+			}
+			cmds = append(cmds, r.code.SetContent(content, ".diff"))
+			r.code.GotoTop()
 		}
+	case GoBackMsg:
+		r.compare = nil
+	case common.ErrorMsg:
+		r.isLoading = false
 	case EmptyRepoMsg:
 		r.ref = nil
 		cmds = append(cmds, r.setItems([]selector.IdentifiableItem{}))
@@ -169,10 +247,18 @@ func (r *Refs) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			r.spinner = s
 		}
 	}
-	m, cmd := r.selector.Update(msg)
-	r.selector = m.(*selector.Selector)
-	if cmd != nil {
-		cmds = append(cmds, cmd)
+	if r.compare != nil {
+		c, cmd := r.code.Update(msg)
+		r.code = c.(*code.Code)
+		if cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+	} else {
+		m, cmd := r.selector.Update(msg)
+		r.selector = m.(*selector.Selector)
+		if cmd != nil {
+			cmds = append(cmds, cmd)
+		}
 	}
 	return r, tea.Batch(cmds...)
 }
@@ -182,6 +268,9 @@ func (r *Refs) View() string {
 	if r.isLoading {
 		return renderLoading(r.common, r.spinner)
 	}
+	if r.compare != nil {
+		return r.code.View()
+	}
 	return r.selector.View()
 }
 
@@ -192,6 +281,9 @@ func (r *Refs) SpinnerID() int {
 
 // StatusBarValue implements statusbar.StatusBar.
 func (r *Refs) StatusBarValue() string {
+	if r.compare != nil {
+		return r.compare.title
+	}
 	if r.activeRef == nil {
 		return ""
 	}
@@ -200,6 +292,9 @@ func (r *Refs) StatusBarValue() string {
 
 // StatusBarInfo implements statusbar.StatusBar.
 func (r *Refs) StatusBarInfo() string {
+	if r.compare != nil {
+		return fmt.Sprintf("☰ %d%%", r.code.ScrollPosition())
+	}
 	totalPages := r.selector.TotalPages()
 	if totalPages <= 1 {
 		return "p. 1/1"
@@ -255,6 +350,29 @@ func (r *Refs) setItems(items []selector.IdentifiableItem) tea.Cmd {
 	}
 }
 
+// compareCmd compares the head reference to the base reference from their
+// merge base, showing what merging the head would change.
+func (r *Refs) compareCmd(base, head *git.Reference) tea.Cmd {
+	return func() tea.Msg {
+		be := r.common.Backend()
+		if be == nil || r.repo == nil {
+			return nil
+		}
+
+		cmp, err := be.Compare(r.common.Context(), r.repo.Name(), base.ID, head.ID, true)
+		if err != nil {
+			r.common.Logger.Debugf("ui: error comparing references: %v", err)
+			return common.ErrorMsg(err)
+		}
+
+		return RefCompareMsg{
+			prefix:     r.refPrefix,
+			title:      base.Name().Short() + "..." + head.Name().Short(),
+			Comparison: cmp,
+		}
+	}
+}
+
 func switchRefCmd(ref *git.Reference) tea.Cmd {
 	return func() tea.Msg {
 		return RefMsg(ref)
@@ -0,0 +1,273 @@
+package repo
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/soft-serve/pkg/backend"
+	"github.com/charmbracelet/soft-serve/pkg/proto"
+	"github.com/charmbracelet/soft-serve/pkg/ui/common"
+	"github.com/charmbracelet/soft-serve/pkg/ui/components/code"
+	"github.com/charmbracelet/soft-serve/pkg/ui/components/selector"
+	"github.com/dustin/go-humanize"
+)
+
+type releasesState int
+
+const (
+	releasesStateLoading releasesState = iota
+	releasesStateList
+	releasesStateView
+)
+
+// ReleaseListMsg is a message sent when the releases are loaded.
+type ReleaseListMsg []backend.Release
+
+// Releases is the releases component page.
+type Releases struct {
+	common  common.Common
+	code    *code.Code
+	repo    proto.Repository
+	spinner spinner.Model
+	list    *selector.Selector
+	state   releasesState
+}
+
+// NewReleases creates a new releases model.
+func NewReleases(common common.Common) *Releases {
+	code := code.New(common, "", "")
+	code.UseGlamour = true
+	s := spinner.New(spinner.WithSpinner(spinner.Dot),
+		spinner.WithStyle(common.Styles.Spinner))
+	selector := selector.New(common, []selector.IdentifiableItem{}, ReleaseItemDelegate{&common})
+	selector.SetShowFilter(false)
+	selector.SetShowHelp(false)
+	selector.SetShowPagination(false)
+	selector.SetShowStatusBar(false)
+	selector.SetShowTitle(false)
+	selector.SetFilteringEnabled(false)
+	selector.DisableQuitKeybindings()
+	selector.KeyMap.NextPage = common.KeyMap.NextPage
+	selector.KeyMap.PrevPage = common.KeyMap.PrevPage
+	return &Releases{
+		code:    code,
+		common:  common,
+		spinner: s,
+		list:    selector,
+	}
+}
+
+// Path implements common.TabComponent.
+func (r *Releases) Path() string {
+	return ""
+}
+
+// TabName returns the name of the tab.
+func (r *Releases) TabName() string {
+	return "Releases"
+}
+
+// SetSize implements common.Component.
+func (r *Releases) SetSize(width, height int) {
+	r.common.SetSize(width, height)
+	r.code.SetSize(width, height)
+	r.list.SetSize(width, height)
+}
+
+// ShortHelp implements help.KeyMap.
+func (r *Releases) ShortHelp() []key.Binding {
+	return []key.Binding{
+		r.common.KeyMap.Select,
+		r.common.KeyMap.Back,
+		r.common.KeyMap.UpDown,
+	}
+}
+
+// FullHelp implements help.KeyMap.
+func (r *Releases) FullHelp() [][]key.Binding {
+	b := [][]key.Binding{
+		{
+			r.common.KeyMap.Select,
+			r.common.KeyMap.Back,
+			r.common.KeyMap.Copy,
+		},
+		{
+			r.code.KeyMap.Down,
+			r.code.KeyMap.Up,
+			r.common.KeyMap.GotoTop,
+			r.common.KeyMap.GotoBottom,
+		},
+	}
+	return b
+}
+
+// StatusBarValue implements common.Component.
+func (r *Releases) StatusBarValue() string {
+	item, ok := r.list.SelectedItem().(ReleaseItem)
+	if !ok {
+		return " "
+	}
+	return item.Title()
+}
+
+// StatusBarInfo implements common.Component.
+func (r *Releases) StatusBarInfo() string {
+	switch r.state {
+	case releasesStateList:
+		totalPages := r.list.TotalPages()
+		if totalPages <= 1 {
+			return "p. 1/1"
+		}
+		return fmt.Sprintf("p. %d/%d", r.list.Page()+1, totalPages)
+	case releasesStateView:
+		return fmt.Sprintf("☰ %d%%", r.code.ScrollPosition())
+	default:
+		return ""
+	}
+}
+
+// SpinnerID implements common.Component.
+func (r *Releases) SpinnerID() int {
+	return r.spinner.ID()
+}
+
+// Init initializes the model.
+func (r *Releases) Init() tea.Cmd {
+	r.state = releasesStateLoading
+	return tea.Batch(r.spinner.Tick, r.fetchReleases)
+}
+
+// Update updates the model.
+func (r *Releases) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	cmds := make([]tea.Cmd, 0)
+	switch msg := msg.(type) {
+	case RepoMsg:
+		r.repo = msg
+	case RefMsg, EmptyRepoMsg:
+		r.list.Select(0)
+		cmds = append(cmds, r.Init())
+	case tea.WindowSizeMsg:
+		r.SetSize(msg.Width, msg.Height)
+	case spinner.TickMsg:
+		if r.state == releasesStateLoading && r.spinner.ID() == msg.ID {
+			sp, cmd := r.spinner.Update(msg)
+			r.spinner = sp
+			if cmd != nil {
+				cmds = append(cmds, cmd)
+			}
+		}
+	case tea.KeyMsg:
+		switch r.state {
+		case releasesStateList, releasesStateView:
+			switch {
+			case key.Matches(msg, r.common.KeyMap.BackItem):
+				cmds = append(cmds, goBackCmd)
+			case key.Matches(msg, r.common.KeyMap.Copy):
+				if item, ok := r.list.SelectedItem().(ReleaseItem); ok {
+					cmds = append(cmds, copyCmd(item.Tag, "Release tag copied to clipboard"))
+				}
+			}
+		}
+	case ReleaseListMsg:
+		r.state = releasesStateList
+		items := make([]selector.IdentifiableItem, len(msg))
+		for i, rel := range msg {
+			items[i] = ReleaseItem{rel}
+		}
+		cmds = append(cmds, r.list.SetItems(items))
+	case selector.SelectMsg:
+		switch item := msg.IdentifiableItem.(type) {
+		case ReleaseItem:
+			r.state = releasesStateView
+			cmds = append(cmds, r.code.SetContent(r.releaseMarkdown(item.Release), ".md"))
+			r.code.GotoTop()
+		}
+	case GoBackMsg:
+		if r.state == releasesStateList {
+			r.list.Select(0)
+		}
+		if r.state != releasesStateLoading {
+			r.state = releasesStateList
+		}
+	}
+	switch r.state {
+	case releasesStateList:
+		l, cmd := r.list.Update(msg)
+		r.list = l.(*selector.Selector)
+		if cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+	case releasesStateView:
+		c, cmd := r.code.Update(msg)
+		r.code = c.(*code.Code)
+		if cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+	}
+	return r, tea.Batch(cmds...)
+}
+
+// View returns the view.
+func (r *Releases) View() string {
+	switch r.state {
+	case releasesStateLoading:
+		return renderLoading(r.common, r.spinner)
+	case releasesStateList:
+		if len(r.list.Items()) == 0 {
+			return r.common.Styles.NoContent.Render("No releases found.")
+		}
+		return r.list.View()
+	case releasesStateView:
+		return r.code.View()
+	}
+	return ""
+}
+
+// releaseMarkdown renders a release, its notes, and its assets as markdown.
+func (r *Releases) releaseMarkdown(rel backend.Release) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# %s\n\n", rel.Title)
+
+	info := fmt.Sprintf("`%s` created %s", rel.Tag, humanize.Time(rel.CreatedAt))
+	if rel.Username != "" {
+		info += " by " + rel.Username
+	}
+	fmt.Fprintf(&sb, "%s\n\n", info)
+
+	if notes := strings.TrimSpace(rel.Notes); notes != "" {
+		fmt.Fprintf(&sb, "%s\n\n", notes)
+	}
+
+	if len(rel.Assets) > 0 {
+		sb.WriteString("## Assets\n\n")
+		be := r.common.Backend()
+		for _, a := range rel.Assets {
+			fmt.Fprintf(&sb, "- **%s** (%s)", a.Name, humanize.IBytes(uint64(a.Size)))
+			if be != nil && r.repo != nil {
+				if url := be.ReleaseAssetURL(r.repo.Name(), rel.Tag, a.Name); url != "" {
+					fmt.Fprintf(&sb, "\n  %s", url)
+				}
+			}
+			fmt.Fprintf(&sb, "\n  `sha256:%s`\n", a.SHA256)
+		}
+	}
+
+	return sb.String()
+}
+
+func (r *Releases) fetchReleases() tea.Msg {
+	be := r.common.Backend()
+	if r.repo == nil || be == nil {
+		return ReleaseListMsg(nil)
+	}
+
+	releases, err := be.Releases(r.common.Context(), r.repo.Name())
+	if err != nil {
+		return common.ErrorMsg(err)
+	}
+
+	return ReleaseListMsg(releases)
+}
@@ -0,0 +1,102 @@
+package repo
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/soft-serve/pkg/backend"
+	"github.com/charmbracelet/soft-serve/pkg/ui/common"
+	"github.com/dustin/go-humanize"
+	"github.com/dustin/go-humanize/english"
+)
+
+// ReleaseItem represents a release item.
+type ReleaseItem struct{ backend.Release }
+
+// ID returns the ID of the release item.
+func (i ReleaseItem) ID() string {
+	return i.Tag
+}
+
+// Title returns the title of the release item.
+func (i ReleaseItem) Title() string {
+	return i.Release.Title
+}
+
+// Description returns the description of the release item.
+func (i ReleaseItem) Description() string {
+	return ""
+}
+
+// FilterValue implements list.Item.
+func (i ReleaseItem) FilterValue() string { return i.Tag }
+
+// ReleaseItemDelegate is a delegate for release items.
+type ReleaseItemDelegate struct {
+	common *common.Common
+}
+
+// Height returns the height of the release item list. Implements list.ItemDelegate.
+func (d ReleaseItemDelegate) Height() int { return 1 }
+
+// Spacing implements list.ItemDelegate.
+func (d ReleaseItemDelegate) Spacing() int { return 0 }
+
+// Update implements list.ItemDelegate.
+func (d ReleaseItemDelegate) Update(msg tea.Msg, m *list.Model) tea.Cmd {
+	item, ok := m.SelectedItem().(ReleaseItem)
+	if !ok {
+		return nil
+	}
+
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, d.common.KeyMap.Copy):
+			return copyCmd(item.Tag, fmt.Sprintf("Release tag %q copied to clipboard", item.Tag))
+		}
+	}
+
+	return nil
+}
+
+// Render implements list.ItemDelegate.
+func (d ReleaseItemDelegate) Render(w io.Writer, m list.Model, index int, listItem list.Item) {
+	item, ok := listItem.(ReleaseItem)
+	if !ok {
+		return
+	}
+
+	s := d.common.Styles.Release
+
+	st := s.Normal.Tag
+	selector := " "
+	if index == m.Index() {
+		selector = "> "
+		st = s.Active.Tag
+	}
+
+	selector = s.Selector.Render(selector)
+	title := st.Render(item.Tag)
+	if item.Release.Title != item.Tag {
+		title += st.Render(item.Release.Title)
+	}
+	info := s.Info.Render(fmt.Sprintf("%s, %s",
+		english.Plural(len(item.Assets), "asset", ""),
+		humanize.Time(item.CreatedAt),
+	))
+	fmt.Fprint(w, d.common.Zone.Mark(
+		item.ID(),
+		common.TruncateString(fmt.Sprintf("%s%s%s",
+			selector,
+			title,
+			info,
+		), m.Width()-
+			s.Selector.GetWidth()-
+			st.GetHorizontalFrameSize(),
+		),
+	))
+}
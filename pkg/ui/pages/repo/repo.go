@@ -10,12 +10,16 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/charmbracelet/soft-serve/git"
+	"github.com/charmbracelet/soft-serve/pkg/access"
+	"github.com/charmbracelet/soft-serve/pkg/backend"
 	"github.com/charmbracelet/soft-serve/pkg/proto"
 	"github.com/charmbracelet/soft-serve/pkg/ui/common"
 	"github.com/charmbracelet/soft-serve/pkg/ui/components/footer"
 	"github.com/charmbracelet/soft-serve/pkg/ui/components/selector"
 	"github.com/charmbracelet/soft-serve/pkg/ui/components/statusbar"
 	"github.com/charmbracelet/soft-serve/pkg/ui/components/tabs"
+	"github.com/dustin/go-humanize"
+	"github.com/dustin/go-humanize/english"
 )
 
 type state int
@@ -58,6 +62,9 @@ type Repo struct {
 	state        state
 	spinner      spinner.Model
 	panesReady   []bool
+	clones       string
+	stats        string
+	clone        backend.CloneInstructions
 }
 
 // New returns a new Repo.
@@ -117,6 +124,12 @@ func (r *Repo) commonHelp() []key.Binding {
 	tab.SetHelp("tab", "switch tab")
 	b = append(b, back)
 	b = append(b, tab)
+	if !r.common.HideCloneCmd {
+		b = append(b, r.common.KeyMap.CopySSHURL)
+		if cfg := r.common.Config(); cfg != nil && cfg.HTTP.PublicURL != "" {
+			b = append(b, r.common.KeyMap.CopyHTTPURL)
+		}
+	}
 	return b
 }
 
@@ -153,6 +166,9 @@ func (r *Repo) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case RepoMsg:
 		// Set the state to loading when we get a new repository.
 		r.selectedRepo = msg
+		r.clones = r.cloneSummary()
+		r.stats = r.statsSummary()
+		r.clone = r.cloneInstructions()
 		cmds = append(cmds,
 			r.Init(),
 			// This will set the selected repo in each pane's model.
@@ -179,7 +195,7 @@ func (r *Repo) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		if r.selectedRepo != nil {
 			urlID := fmt.Sprintf("%s-url", r.selectedRepo.Name())
-			cmd := r.common.CloneCmd(r.common.Config().SSH.PublicURL, r.selectedRepo.Name())
+			cmd := r.cloneCmd()
 			if msg, ok := msg.(tea.MouseMsg); ok && r.common.Zone.Get(urlID).InBounds(msg) {
 				cmds = append(cmds, copyCmd(cmd, "Command copied to clipboard"))
 			}
@@ -207,6 +223,14 @@ func (r *Repo) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			switch {
 			case key.Matches(msg, r.common.KeyMap.Back):
 				cmds = append(cmds, goBackCmd)
+			case key.Matches(msg, r.common.KeyMap.CopySSHURL):
+				if cfg := r.common.Config(); cfg != nil {
+					cmds = append(cmds, r.copyURLCmd(cfg.SSH.PublicURL))
+				}
+			case key.Matches(msg, r.common.KeyMap.CopyHTTPURL):
+				if cfg := r.common.Config(); cfg != nil {
+					cmds = append(cmds, r.copyURLCmd(cfg.HTTP.PublicURL))
+				}
 			}
 		}
 	case CopyMsg:
@@ -223,8 +247,14 @@ func (r *Repo) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		cmds = append(cmds, r.updateTabComponent(&Log{}, msg))
 	case RefItemsMsg:
 		cmds = append(cmds, r.updateTabComponent(&Refs{refPrefix: msg.prefix}, msg))
+	case RefCompareMsg:
+		cmds = append(cmds, r.updateTabComponent(&Refs{refPrefix: msg.prefix}, msg))
 	case StashListMsg, StashPatchMsg:
 		cmds = append(cmds, r.updateTabComponent(&Stash{}, msg))
+	case ReleaseListMsg:
+		cmds = append(cmds, r.updateTabComponent(&Releases{}, msg))
+	case IssueListMsg, IssueMsg:
+		cmds = append(cmds, r.updateTabComponent(&Issues{}, msg))
 	// We have two spinners, one is used to when loading the repository and the
 	// other is used when loading the log.
 	// Check if the spinner ID matches the spinner model.
@@ -277,7 +307,8 @@ func (r *Repo) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case RepoMsg, RefMsg, tabs.ActiveTabMsg, tea.KeyMsg, tea.MouseMsg,
 		FileItemsMsg, FileContentMsg, FileBlameMsg, selector.ActiveMsg,
 		LogItemsMsg, GoBackMsg, LogDiffMsg, EmptyRepoMsg,
-		StashListMsg, StashPatchMsg:
+		StashListMsg, StashPatchMsg, RefCompareMsg, ReleaseListMsg,
+		IssueListMsg, IssueMsg:
 		r.setStatusBarInfo()
 	}
 
@@ -339,13 +370,22 @@ func (r *Repo) headerView() string {
 			r.common.Styles.Repo.HeaderDesc.Render(desc),
 		)
 	}
+	if r.stats != "" {
+		header = lipgloss.JoinVertical(lipgloss.Top,
+			header,
+			r.common.Styles.Repo.HeaderDesc.Render(r.stats),
+		)
+	}
+	if r.clones != "" {
+		header = lipgloss.JoinVertical(lipgloss.Top,
+			header,
+			r.common.Styles.Repo.HeaderDesc.Render(r.clones),
+		)
+	}
 	urlStyle := r.common.Styles.URLStyle.
 		Width(r.common.Width - lipgloss.Width(desc) - 1).
 		Align(lipgloss.Right)
-	var url string
-	if cfg := r.common.Config(); cfg != nil {
-		url = r.common.CloneCmd(cfg.SSH.PublicURL, r.selectedRepo.Name())
-	}
+	url := r.cloneCmd()
 	url = common.TruncateString(url, r.common.Width-lipgloss.Width(desc)-1)
 	url = r.common.Zone.Mark(
 		fmt.Sprintf("%s-url", r.selectedRepo.Name()),
@@ -360,6 +400,110 @@ func (r *Repo) headerView() string {
 	)
 }
 
+// cloneInstructions returns how to clone the selected repository.
+func (r *Repo) cloneInstructions() backend.CloneInstructions {
+	be := r.common.Backend()
+	if r.selectedRepo == nil || be == nil {
+		return backend.CloneInstructions{}
+	}
+
+	ci, err := be.CloneInstructions(r.common.Context(), r.selectedRepo.Name())
+	if err != nil {
+		r.common.Logger.Debugf("failed to get clone instructions: %v", err)
+	}
+
+	return ci
+}
+
+// cloneCmd returns the command to clone the selected repository over its
+// default clone protocol.
+func (r *Repo) cloneCmd() string {
+	if r.common.HideCloneCmd || r.selectedRepo == nil {
+		return ""
+	}
+	if len(r.clone.URLs) > 0 {
+		return r.clone.URLs[0].Command
+	}
+	if cfg := r.common.Config(); cfg != nil {
+		return r.common.CloneCmd(cfg.SSH.PublicURL, r.selectedRepo.Name())
+	}
+
+	return ""
+}
+
+// cloneSummary returns the clone count and the recent cloners of the selected
+// repository. Clone statistics are only shown to repository admins when clone
+// tracking is enabled.
+func (r *Repo) cloneSummary() string {
+	if r.selectedRepo == nil {
+		return ""
+	}
+
+	ctx := r.common.Context()
+	be := r.common.Backend()
+	pk := r.common.PublicKey()
+	if be == nil || pk == nil {
+		return ""
+	}
+
+	name := r.selectedRepo.Name()
+	if be.AccessLevelByPublicKey(ctx, name, pk) < access.AdminAccess {
+		return ""
+	}
+
+	if enabled, err := be.CloneTracking(ctx, name); err != nil || !enabled {
+		return ""
+	}
+
+	stats, err := be.CloneStats(ctx, name, 3)
+	if err != nil {
+		r.common.Logger.Debugf("failed to get clone stats: %v", err)
+		return ""
+	}
+
+	summary := fmt.Sprintf("%d clones", stats.Count)
+	if len(stats.Recent) > 0 {
+		cloners := make([]string, 0, len(stats.Recent))
+		for _, e := range stats.Recent {
+			username := "anonymous"
+			if e.Username.Valid {
+				username = e.Username.String
+			}
+			cloners = append(cloners, fmt.Sprintf("%s (%s)", username, humanize.Time(e.CreatedAt)))
+		}
+		summary += ", recently by " + strings.Join(cloners, ", ")
+	}
+
+	return summary
+}
+
+// statsSummary returns the commit, contributor, branch, and tag counts and the
+// size of the selected repository.
+func (r *Repo) statsSummary() string {
+	if r.selectedRepo == nil {
+		return ""
+	}
+
+	be := r.common.Backend()
+	if be == nil {
+		return ""
+	}
+
+	stats, err := be.RepoStats(r.common.Context(), r.selectedRepo.Name())
+	if err != nil {
+		r.common.Logger.Debugf("failed to get repository stats: %v", err)
+		return ""
+	}
+
+	return fmt.Sprintf("%s, %s, %s, %s, %s",
+		english.Plural(stats.Commits, "commit", ""),
+		english.Plural(stats.Contributors, "contributor", ""),
+		english.Plural(stats.Branches, "branch", "branches"),
+		english.Plural(stats.Tags, "tag", ""),
+		humanize.Bytes(uint64(stats.Size)),
+	)
+}
+
 func (r *Repo) setStatusBarInfo() {
 	if r.selectedRepo == nil {
 		return
@@ -413,6 +557,16 @@ func copyCmd(text, msg string) tea.Cmd {
 	}
 }
 
+// copyURLCmd copies the clone URL of the selected repository. The URL is also
+// shown in the status bar for terminals that don't support OSC 52.
+func (r *Repo) copyURLCmd(publicURL string) tea.Cmd {
+	if r.selectedRepo == nil || r.common.HideCloneCmd || publicURL == "" {
+		return nil
+	}
+	url := common.RepoURL(publicURL, r.selectedRepo.Name())
+	return copyCmd(url, fmt.Sprintf("Copied %s", url))
+}
+
 func goBackCmd() tea.Msg {
 	return GoBackMsg{}
 }
@@ -0,0 +1,333 @@
+package selection
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/soft-serve/pkg/access"
+	"github.com/charmbracelet/soft-serve/pkg/db/models"
+	"github.com/charmbracelet/soft-serve/pkg/proto"
+	"github.com/charmbracelet/soft-serve/pkg/ui/common"
+	"github.com/dustin/go-humanize"
+	gossh "golang.org/x/crypto/ssh"
+)
+
+// adminAuditEvents is the number of audit events shown in the admin panel.
+const adminAuditEvents = 10
+
+// adminAction is an admin panel action waiting for confirmation.
+type adminAction int
+
+const (
+	noAdminAction adminAction = iota
+	lockdownAdminAction
+	revokeAdminAction
+)
+
+// adminDataMsg is the data shown in the admin panel.
+type adminDataMsg struct {
+	repos    int
+	users    int
+	lockdown bool
+	sessions []common.Session
+	events   []models.AuditEvent
+	err      error
+}
+
+// adminStatusMsg is the result of an admin panel action.
+type adminStatusMsg struct {
+	status string
+	err    error
+}
+
+// isAdmin returns whether the user of the session is a server admin.
+func isAdmin(c common.Common) bool {
+	be := c.Backend()
+	pk := c.PublicKey()
+	if be == nil || pk == nil {
+		return false
+	}
+
+	return be.AccessLevelByPublicKey(c.Context(), "", pk) == access.AdminAccess
+}
+
+// admin is the server administration panel. It's only shown to admins, and
+// every action checks the access again.
+type admin struct {
+	common  common.Common
+	data    adminDataMsg
+	cursor  int
+	confirm adminAction
+	status  string
+	err     error
+}
+
+func newAdmin(c common.Common) *admin {
+	return &admin{common: c}
+}
+
+// SetSize implements common.Component.
+func (a *admin) SetSize(width, height int) {
+	a.common.SetSize(width, height)
+}
+
+// ShortHelp returns the key bindings of the panel.
+func (a *admin) ShortHelp() []key.Binding {
+	km := a.common.KeyMap
+	if a.confirm != noAdminAction {
+		cancel := km.Back
+		cancel.SetHelp("esc", "cancel")
+		return []key.Binding{km.Confirm, cancel}
+	}
+
+	return []key.Binding{km.UpDown, km.ToggleLockdown, km.RevokeKey, km.Refresh}
+}
+
+// Init implements tea.Model.
+func (a *admin) Init() tea.Cmd {
+	return a.refreshCmd()
+}
+
+// Update implements tea.Model.
+func (a *admin) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	km := a.common.KeyMap
+	switch msg := msg.(type) {
+	case adminDataMsg:
+		a.data = msg
+		if a.cursor >= len(a.data.sessions) {
+			a.cursor = max(len(a.data.sessions)-1, 0)
+		}
+	case adminStatusMsg:
+		a.status, a.err = msg.status, msg.err
+		return a, a.refreshCmd()
+	case tea.KeyMsg:
+		if a.confirm != noAdminAction {
+			action := a.confirm
+			a.confirm = noAdminAction
+			a.status, a.err = "", nil
+			if key.Matches(msg, km.Confirm) {
+				return a, a.actionCmd(action)
+			}
+			return a, nil
+		}
+
+		switch {
+		case key.Matches(msg, km.Up):
+			if a.cursor > 0 {
+				a.cursor--
+			}
+		case key.Matches(msg, km.Down):
+			if a.cursor < len(a.data.sessions)-1 {
+				a.cursor++
+			}
+		case key.Matches(msg, km.Refresh):
+			a.status, a.err = "", nil
+			return a, a.refreshCmd()
+		case key.Matches(msg, km.ToggleLockdown):
+			a.confirm = lockdownAdminAction
+			if a.data.lockdown {
+				a.status = "Lift the lockdown?"
+			} else {
+				a.status = "Enter lockdown? Sessions of non-admins will be terminated."
+			}
+		case key.Matches(msg, km.RevokeKey):
+			sess, ok := a.selected()
+			switch {
+			case !ok || sess.Fingerprint == "":
+				a.status, a.err = "", fmt.Errorf("the selected session has no key")
+			case a.isOwnKey(sess.Fingerprint):
+				a.status, a.err = "", fmt.Errorf("you can't revoke your own key")
+			default:
+				a.confirm = revokeAdminAction
+				a.status = fmt.Sprintf("Revoke %s? Its sessions will be terminated.", sess.Fingerprint)
+			}
+		}
+	}
+
+	return a, nil
+}
+
+// selected returns the selected session.
+func (a *admin) selected() (common.Session, bool) {
+	if a.cursor < 0 || a.cursor >= len(a.data.sessions) {
+		return common.Session{}, false
+	}
+
+	return a.data.sessions[a.cursor], true
+}
+
+// isOwnKey returns whether fp is the fingerprint of the key of the session.
+func (a *admin) isOwnKey(fp string) bool {
+	pk := a.common.PublicKey()
+	return pk != nil && gossh.FingerprintSHA256(pk) == fp
+}
+
+// refreshCmd loads the data of the panel.
+func (a *admin) refreshCmd() tea.Cmd {
+	c := a.common
+	return func() tea.Msg {
+		return loadAdminData(c)
+	}
+}
+
+// loadAdminData loads the data of the admin panel.
+func loadAdminData(c common.Common) tea.Msg {
+	if !isAdmin(c) {
+		return adminDataMsg{err: proto.ErrUnauthorized}
+	}
+
+	ctx := c.Context()
+	be := c.Backend()
+	msg := adminDataMsg{
+		lockdown: be.Lockdown(ctx),
+		sessions: c.Sessions(),
+	}
+
+	repos, err := be.Repositories(ctx)
+	if err != nil {
+		return adminDataMsg{err: err}
+	}
+	msg.repos = len(repos)
+
+	users, err := be.Users(ctx)
+	if err != nil {
+		return adminDataMsg{err: err}
+	}
+	msg.users = len(users)
+
+	msg.events, err = be.RecentAuditEvents(ctx, adminAuditEvents)
+	if err != nil {
+		return adminDataMsg{err: err}
+	}
+
+	return msg
+}
+
+// actionCmd runs a confirmed action. The backend records it in the audit log
+// with the user of the session.
+func (a *admin) actionCmd(action adminAction) tea.Cmd {
+	c := a.common
+	lockdown := !a.data.lockdown
+	sess, _ := a.selected()
+	return func() tea.Msg {
+		if !isAdmin(c) {
+			return adminStatusMsg{err: proto.ErrUnauthorized}
+		}
+
+		ctx := c.Context()
+		be := c.Backend()
+		switch action {
+		case lockdownAdminAction:
+			if err := be.SetLockdown(ctx, lockdown); err != nil {
+				return adminStatusMsg{err: err}
+			}
+			if lockdown {
+				return adminStatusMsg{status: "Lockdown enabled."}
+			}
+			return adminStatusMsg{status: "Lockdown lifted."}
+		case revokeAdminAction:
+			if err := be.RevokeKeys(ctx, sess.Fingerprint); err != nil {
+				return adminStatusMsg{err: err}
+			}
+			return adminStatusMsg{status: fmt.Sprintf("Revoked %s.", sess.Fingerprint)}
+		}
+		return nil
+	}
+}
+
+// View implements tea.Model.
+func (a *admin) View() string {
+	st := a.common.Styles
+	title := st.RepoSelector.Normal.Title
+	dim := st.RepoSelector.Normal.Desc
+	if a.data.err != nil {
+		return st.NoContent.Render(a.data.err.Error())
+	}
+
+	lockdown := "off"
+	if a.data.lockdown {
+		lockdown = "on"
+	}
+
+	var sb strings.Builder
+	sb.WriteString(title.Render("Server") + "\n")
+	for _, row := range [][2]string{
+		{"Repositories", fmt.Sprint(a.data.repos)},
+		{"Users", fmt.Sprint(a.data.users)},
+		{"Sessions", fmt.Sprint(len(a.data.sessions))},
+		{"Lockdown", lockdown},
+	} {
+		fmt.Fprintf(&sb, "  %-14s%s\n", row[0], row[1])
+	}
+
+	sb.WriteString("\n" + title.Render("Active sessions") + "\n")
+	if len(a.data.sessions) == 0 {
+		sb.WriteString(dim.Render("  No active sessions.") + "\n")
+	}
+	for i, sess := range a.data.sessions {
+		user := sess.User
+		if user == "" {
+			user = "-"
+		}
+		if a.isOwnKey(sess.Fingerprint) {
+			user += " (you)"
+		}
+		command := sess.Command
+		if command == "" {
+			command = "tui"
+		}
+		fp := sess.Fingerprint
+		if fp == "" {
+			fp = "-"
+		}
+
+		// The fingerprint comes last, it's the first to be cut off on
+		// narrow terminals.
+		line := fmt.Sprintf("%-16s %-22s %-8s %-16s %s",
+			user, sess.RemoteAddr, command,
+			humanize.Time(sess.Started), dim.Render(fp))
+		if i == a.cursor {
+			sb.WriteString(st.Ref.ItemSelector.String() + st.Ref.Active.Item.Render(line) + "\n")
+		} else {
+			sb.WriteString("  " + line + "\n")
+		}
+	}
+
+	sb.WriteString("\n" + title.Render("Recent audit events") + "\n")
+	if len(a.data.events) == 0 {
+		sb.WriteString(dim.Render("  No audit events.") + "\n")
+	}
+	for _, e := range a.data.events {
+		fields := []string{dim.Render(e.CreatedAt.Local().Format(time.DateTime)), e.Action}
+		if e.RepoName.Valid {
+			fields = append(fields, e.RepoName.String)
+		}
+		if e.Username.Valid {
+			fields = append(fields, "by "+e.Username.String)
+		}
+		if e.Details != "" {
+			details, _, _ := strings.Cut(e.Details, "\n")
+			fields = append(fields, dim.Render(details))
+		}
+		sb.WriteString("  " + strings.Join(fields, "  ") + "\n")
+	}
+
+	if a.err != nil {
+		sb.WriteString("\n" + st.RepoSelector.Active.Command.Render("Error: "+a.err.Error()))
+	} else if a.status != "" {
+		status := a.status
+		if a.confirm != noAdminAction {
+			status += " (y/N)"
+		}
+		sb.WriteString("\n" + st.RepoSelector.Active.Title.Render(status))
+	}
+
+	return a.common.Renderer.NewStyle().
+		MaxWidth(a.common.Width).
+		MaxHeight(a.common.Height).
+		PaddingLeft(1).
+		Render(sb.String())
+}
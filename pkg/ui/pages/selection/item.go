@@ -11,6 +11,7 @@ import (
 	"github.com/charmbracelet/bubbles/list"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/soft-serve/pkg/access"
 	"github.com/charmbracelet/soft-serve/pkg/proto"
 	"github.com/charmbracelet/soft-serve/pkg/ui/common"
 	"github.com/dustin/go-humanize"
@@ -47,13 +48,15 @@ func (it Items) Swap(i int, j int) {
 
 // Item represents a single item in the selector.
 type Item struct {
-	repo       proto.Repository
-	lastUpdate *time.Time
-	cmd        string
+	repo        proto.Repository
+	lastUpdate  *time.Time
+	cmd         string
+	accessLevel access.AccessLevel
 }
 
-// New creates a new Item.
-func NewItem(c common.Common, repo proto.Repository) (Item, error) {
+// New creates a new Item. accessLevel is the access level of the current user
+// to the repository.
+func NewItem(c common.Common, repo proto.Repository, accessLevel access.AccessLevel) (Item, error) {
 	var lastUpdate *time.Time
 	lu := repo.UpdatedAt()
 	if !lu.IsZero() {
@@ -64,9 +67,10 @@ func NewItem(c common.Common, repo proto.Repository) (Item, error) {
 		cmd = c.CloneCmd(cfg.SSH.PublicURL, repo.Name())
 	}
 	return Item{
-		repo:       repo,
-		lastUpdate: lastUpdate,
-		cmd:        cmd,
+		repo:        repo,
+		lastUpdate:  lastUpdate,
+		cmd:         cmd,
+		accessLevel: accessLevel,
 	}, nil
 }
 
@@ -91,6 +95,12 @@ func (i Item) Description() string { return strings.TrimSpace(i.repo.Description
 // FilterValue implements list.Item.
 func (i Item) FilterValue() string { return i.Title() }
 
+// AccessLevel returns the access level of the current user to the item
+// repository.
+func (i Item) AccessLevel() access.AccessLevel {
+	return i.accessLevel
+}
+
 // Command returns the item Command view.
 func (i Item) Command() string {
 	return i.cmd
@@ -194,9 +204,17 @@ func (d *ItemDelegate) Render(w io.Writer, m list.Model, index int, listItem lis
 		title = lipgloss.StyleRunes(title, matchedRunes, matched, unmatched)
 	}
 	title = styles.Title.Render(title)
+	accessStr := " " + i.accessLevel.String()
+	if m.Width()-styles.Base.GetHorizontalFrameSize()-lipgloss.Width(accessStr) <= 0 {
+		accessStr = ""
+	}
 	desc := i.Description()
-	desc = common.TruncateString(desc, m.Width()-styles.Base.GetHorizontalFrameSize())
+	desc = common.TruncateString(desc, m.Width()-styles.Base.GetHorizontalFrameSize()-lipgloss.Width(accessStr))
 	desc = styles.Desc.Render(desc)
+	accessStyle := styles.Updated.
+		Align(lipgloss.Right).
+		Width(m.Width() - styles.Base.GetHorizontalFrameSize() - lipgloss.Width(desc))
+	desc = lipgloss.JoinHorizontal(lipgloss.Bottom, desc, accessStyle.Render(accessStr))
 
 	s.WriteString(lipgloss.JoinHorizontal(lipgloss.Bottom, title, updated))
 	s.WriteRune('\n')
@@ -2,6 +2,8 @@ package selection
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
 	"sort"
 
 	"github.com/charmbracelet/bubbles/key"
@@ -17,7 +19,7 @@ import (
 )
 
 const (
-	defaultNoContent = "No readme found.\n\nCreate a `.soft-serve` repository and add a `README.md` file to display readme."
+	defaultNoContent = "No readme found.\n\nCreate a `.soft-serve` repository and add a `README.md` file to display readme, or configure a homepage in the `tui` section of the server config."
 )
 
 type pane int
@@ -25,6 +27,7 @@ type pane int
 const (
 	selectorPane pane = iota
 	readmePane
+	adminPane
 	lastPane
 )
 
@@ -32,22 +35,59 @@ func (p pane) String() string {
 	return []string{
 		"Repositories",
 		"About",
+		"Admin",
 	}[p]
 }
 
+// accessFilter limits the repository list to repositories the user has at
+// least a given access level to.
+type accessFilter int
+
+const (
+	accessFilterAll accessFilter = iota
+	accessFilterWritable
+	accessFilterAdmin
+	lastAccessFilter
+)
+
+func (f accessFilter) String() string {
+	return []string{
+		"all",
+		"writable",
+		"admin",
+	}[f]
+}
+
+// AccessLevel returns the minimum access level of the filter.
+func (f accessFilter) AccessLevel() access.AccessLevel {
+	return []access.AccessLevel{
+		access.ReadOnlyAccess,
+		access.ReadWriteAccess,
+		access.AdminAccess,
+	}[f]
+}
+
 // Selection is the model for the selection screen/page.
 type Selection struct {
-	common     common.Common
-	readme     *code.Code
-	selector   *selector.Selector
-	activePane pane
-	tabs       *tabs.Tabs
+	common       common.Common
+	readme       *code.Code
+	selector     *selector.Selector
+	admin        *admin // nil for non-admins
+	activePane   pane
+	tabs         *tabs.Tabs
+	items        Items
+	pending      Items // not loaded yet
+	accessFilter accessFilter
 }
 
 // New creates a new selection model.
 func New(c common.Common) *Selection {
-	ts := make([]string, lastPane)
-	for i, b := range []pane{selectorPane, readmePane} {
+	panes := []pane{selectorPane, readmePane}
+	if isAdmin(c) {
+		panes = append(panes, adminPane)
+	}
+	ts := make([]string, len(panes))
+	for i, b := range panes {
 		ts[i] = b.String()
 	}
 	t := tabs.New(c, ts)
@@ -74,6 +114,9 @@ func New(c common.Common) *Selection {
 	selector.DisableQuitKeybindings()
 	sel.selector = selector
 	sel.readme = readme
+	if len(panes) > int(adminPane) {
+		sel.admin = newAdmin(c)
+	}
 	return sel
 }
 
@@ -100,6 +143,9 @@ func (s *Selection) SetSize(width, height int) {
 	s.tabs.SetSize(width, height-hm)
 	s.selector.SetSize(width-wm, height-hm)
 	s.readme.SetSize(width-wm, height-hm-1) // -1 for readme status line
+	if s.admin != nil {
+		s.admin.SetSize(width-wm, height-hm)
+	}
 }
 
 // IsFiltering returns true if the selector is currently filtering.
@@ -123,8 +169,12 @@ func (s *Selection) ShortHelp() []key.Binding {
 			k.Filter,
 			k.ClearFilter,
 			copyKey,
+			s.common.KeyMap.AccessFilter,
 		)
 	}
+	if s.activePane == adminPane && s.admin != nil {
+		kb = append(kb, s.admin.ShortHelp()...)
+	}
 	return kb
 }
 
@@ -136,6 +186,10 @@ func (s *Selection) FullHelp() [][]key.Binding {
 		},
 	}
 	switch s.activePane {
+	case adminPane:
+		if s.admin != nil {
+			b = append(b, s.admin.ShortHelp())
+		}
 	case readmePane:
 		k := s.readme.KeyMap
 		b = append(b, []key.Binding{
@@ -158,6 +212,7 @@ func (s *Selection) FullHelp() [][]key.Binding {
 			b[0] = append(b[0],
 				s.common.KeyMap.Select,
 				copyKey,
+				s.common.KeyMap.AccessFilter,
 			)
 		}
 		b = append(b, []key.Binding{
@@ -199,42 +254,143 @@ func (s *Selection) Init() tea.Cmd {
 	if err != nil {
 		return common.ErrorCmd(err)
 	}
-	sortedItems := make(Items, 0)
-	for _, r := range repos {
-		if r.Name() == ".soft-serve" {
-			readme, path, err := backend.Readme(r, nil)
-			if err != nil {
-				continue
-			}
-
-			readmeCmd = s.readme.SetContent(readme, path)
+	if readme, path, ok := s.homepage(); ok {
+		readmeCmd = s.readme.SetContent(readme, path)
+		if cfg.TUI.HasHomepage() {
+			// Land on the homepage before the repository list.
+			s.activePane = readmePane
+			t, _ := s.tabs.Update(tabs.SelectTabMsg(readmePane))
+			s.tabs = t.(*tabs.Tabs)
 		}
+	}
 
+	// Sort the repositories the way the items are sorted, so the first ones
+	// loaded are the first ones shown.
+	candidates := make(Items, 0, len(repos))
+	for _, r := range repos {
 		if r.IsHidden() {
 			continue
 		}
-		al := be.AccessLevelByPublicKey(ctx, r.Name(), pk)
-		if al >= access.ReadOnlyAccess {
-			item, err := NewItem(s.common, r)
-			if err != nil {
-				s.common.Logger.Debugf("ui: failed to create item for %s: %v", r.Name(), err)
-				continue
-			}
-			sortedItems = append(sortedItems, item)
+		item, err := NewItem(s.common, r, access.NoAccess)
+		if err != nil {
+			s.common.Logger.Debugf("ui: failed to create item for %s: %v", r.Name(), err)
+			continue
 		}
+		candidates = append(candidates, item)
 	}
-	sort.Sort(sortedItems)
-	items := make([]selector.IdentifiableItem, len(sortedItems))
-	for i, it := range sortedItems {
-		items[i] = it
+	sort.Sort(candidates)
+	msg := s.loadItems(candidates)
+	s.items = msg.items
+	s.pending = msg.pending
+	var adminCmd tea.Cmd
+	if s.admin != nil {
+		adminCmd = s.admin.Init()
 	}
 	return tea.Batch(
 		s.selector.Init(),
-		s.selector.SetItems(items),
+		s.setItems(),
+		s.loadItemsCmd(),
 		readmeCmd,
+		adminCmd,
 	)
 }
 
+// ItemsMsg is a batch of repository items loaded in the background.
+type ItemsMsg struct {
+	items   Items
+	pending Items
+}
+
+// loadItems computes the access level of the user to the candidate items
+// until the configured number of readable items is loaded. The access level
+// is computed once per repository here and kept on the item, so changing the
+// access filter doesn't hit the backend.
+func (s *Selection) loadItems(candidates Items) ItemsMsg {
+	ctx := s.common.Context()
+	be := s.common.Backend()
+	pk := s.common.PublicKey()
+	limit := 0
+	if cfg := s.common.Config(); cfg != nil {
+		limit = cfg.TUI.MaxRepos
+	}
+
+	var msg ItemsMsg
+	for i, item := range candidates {
+		if limit > 0 && len(msg.items) >= limit {
+			msg.pending = candidates[i:]
+			break
+		}
+
+		item.accessLevel = be.AccessLevelByPublicKey(ctx, item.repo.Name(), pk)
+		if item.accessLevel >= access.ReadOnlyAccess {
+			msg.items = append(msg.items, item)
+		}
+	}
+
+	return msg
+}
+
+// loadItemsCmd loads the next batch of pending items.
+func (s *Selection) loadItemsCmd() tea.Cmd {
+	if len(s.pending) == 0 {
+		return nil
+	}
+
+	pending := s.pending
+	return func() tea.Msg {
+		return s.loadItems(pending)
+	}
+}
+
+// homepage returns the server homepage content and its path. It's the
+// configured homepage file or repository README, falling back to the README
+// of the ".soft-serve" repository.
+func (s *Selection) homepage() (string, string, bool) {
+	cfg := s.common.Config()
+	if cfg.TUI.HomepageFile != "" {
+		content, err := os.ReadFile(cfg.TUI.HomepageFile)
+		if err != nil {
+			s.common.Logger.Errorf("ui: failed to read homepage file: %v", err)
+			return "", "", false
+		}
+
+		return string(content), filepath.Base(cfg.TUI.HomepageFile), true
+	}
+
+	name := cfg.TUI.HomepageRepo
+	if name == "" {
+		name = ".soft-serve"
+	}
+
+	r, err := s.common.Backend().Repository(s.common.Context(), name)
+	if err != nil {
+		if cfg.TUI.HomepageRepo != "" {
+			s.common.Logger.Errorf("ui: failed to find homepage repository %q: %v", name, err)
+		}
+		return "", "", false
+	}
+
+	readme, path, err := backend.Readme(r, nil)
+	if err != nil {
+		return "", "", false
+	}
+
+	return readme, path, true
+}
+
+// setItems sets the selector items to the repositories matching the access
+// filter.
+func (s *Selection) setItems() tea.Cmd {
+	al := s.accessFilter.AccessLevel()
+	items := make([]selector.IdentifiableItem, 0, len(s.items))
+	for _, it := range s.items {
+		if it.AccessLevel() >= al {
+			items = append(items, it)
+		}
+	}
+	return s.selector.SetItems(items)
+}
+
 // Update implements tea.Model.
 func (s *Selection) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	cmds := make([]tea.Cmd, 0)
@@ -256,6 +412,10 @@ func (s *Selection) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			switch {
 			case key.Matches(msg, s.common.KeyMap.Back):
 				cmds = append(cmds, s.selector.Init())
+			case key.Matches(msg, s.common.KeyMap.AccessFilter) &&
+				s.activePane == selectorPane && !s.IsFiltering():
+				s.accessFilter = (s.accessFilter + 1) % lastAccessFilter
+				cmds = append(cmds, s.setItems())
 			}
 		}
 		t, cmd := s.tabs.Update(msg)
@@ -265,8 +425,31 @@ func (s *Selection) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 	case tabs.ActiveTabMsg:
 		s.activePane = pane(msg)
+		if s.activePane == adminPane && s.admin != nil {
+			// Refresh the panel when switching to it.
+			cmds = append(cmds, s.admin.Init())
+		}
+	case ItemsMsg:
+		// The items are sorted, the batch goes after the loaded ones.
+		s.items = append(s.items, msg.items...)
+		s.pending = msg.pending
+		cmds = append(cmds, s.setItems(), s.loadItemsCmd())
+		return s, tea.Batch(cmds...)
+	case adminDataMsg, adminStatusMsg:
+		if s.admin != nil {
+			_, cmd := s.admin.Update(msg)
+			cmds = append(cmds, cmd)
+		}
+		return s, tea.Batch(cmds...)
 	}
 	switch s.activePane {
+	case adminPane:
+		if s.admin != nil {
+			_, cmd := s.admin.Update(msg)
+			if cmd != nil {
+				cmds = append(cmds, cmd)
+			}
+		}
 	case readmePane:
 		r, cmd := s.readme.Update(msg)
 		s.readme = r.(*code.Code)
@@ -306,9 +489,28 @@ func (s *Selection) View() string {
 			s.readme.View(),
 			readmeStatus,
 		))
+	case adminPane:
+		if s.admin != nil {
+			view = s.common.Renderer.NewStyle().
+				Height(s.common.Height - hm).
+				Render(s.admin.View())
+		}
 	}
 	if s.activePane != selectorPane || s.FilterState() != list.Filtering {
-		tabs := s.common.Styles.Tabs.Render(s.tabs.View())
+		tabs := s.tabs.View()
+		if s.activePane == selectorPane && s.accessFilter != accessFilterAll {
+			filter := s.common.Renderer.NewStyle().
+				Foreground(s.common.Styles.InactiveBorderColor).
+				Render(fmt.Sprintf("  Access: %s", s.accessFilter))
+			tabs = lipgloss.JoinHorizontal(lipgloss.Top, tabs, filter)
+		}
+		if s.activePane == selectorPane && len(s.pending) > 0 {
+			loading := s.common.Renderer.NewStyle().
+				Foreground(s.common.Styles.InactiveBorderColor).
+				Render("  Loading more…")
+			tabs = lipgloss.JoinHorizontal(lipgloss.Top, tabs, loading)
+		}
+		tabs = s.common.Styles.Tabs.Render(tabs)
 		view = lipgloss.JoinVertical(lipgloss.Left,
 			tabs,
 			view,
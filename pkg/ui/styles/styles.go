@@ -141,6 +141,30 @@ type Styles struct {
 		Selector lipgloss.Style
 	}
 
+	Release struct {
+		Normal struct {
+			Tag lipgloss.Style
+		}
+		Active struct {
+			Tag lipgloss.Style
+		}
+		Info     lipgloss.Style
+		Selector lipgloss.Style
+	}
+
+	Issue struct {
+		Normal struct {
+			Title lipgloss.Style
+		}
+		Active struct {
+			Title lipgloss.Style
+		}
+		Number   lipgloss.Style
+		Closed   lipgloss.Style
+		Info     lipgloss.Style
+		Selector lipgloss.Style
+	}
+
 	Spinner          lipgloss.Style
 	SpinnerContainer lipgloss.Style
 
@@ -521,5 +545,37 @@ func DefaultStyles(r *lipgloss.Renderer) *Styles {
 		Width(1).
 		Foreground(selectorColor)
 
+	s.Release.Normal.Tag = r.NewStyle().MarginLeft(1)
+
+	s.Release.Active.Tag = s.Release.Normal.Tag.Foreground(selectorColor)
+
+	s.Release.Info = r.NewStyle().
+		MarginLeft(1).
+		Foreground(lipgloss.Color("243"))
+
+	s.Release.Selector = r.NewStyle().
+		Width(1).
+		Foreground(selectorColor)
+
+	s.Issue.Normal.Title = r.NewStyle().MarginLeft(1)
+
+	s.Issue.Active.Title = s.Issue.Normal.Title.Foreground(selectorColor)
+
+	s.Issue.Number = r.NewStyle().
+		MarginLeft(1).
+		Foreground(hashColor)
+
+	s.Issue.Closed = r.NewStyle().
+		MarginLeft(1).
+		Foreground(lipgloss.Color("203"))
+
+	s.Issue.Info = r.NewStyle().
+		MarginLeft(1).
+		Foreground(lipgloss.Color("243"))
+
+	s.Issue.Selector = r.NewStyle().
+		Width(1).
+		Foreground(selectorColor)
+
 	return s
 }
@@ -0,0 +1,1362 @@
+package web
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/charmbracelet/soft-serve/git"
+	"github.com/charmbracelet/soft-serve/pkg/access"
+	"github.com/charmbracelet/soft-serve/pkg/backend"
+	"github.com/charmbracelet/soft-serve/pkg/config"
+	"github.com/charmbracelet/soft-serve/pkg/db/models"
+	"github.com/charmbracelet/soft-serve/pkg/proto"
+	"github.com/charmbracelet/soft-serve/pkg/utils"
+	"github.com/gorilla/mux"
+)
+
+// APIController is a router for the HTTP API.
+func APIController(_ context.Context, r *mux.Router) {
+	r.Handle("/api/repos", withAdmin(http.HandlerFunc(createRepo))).Methods(http.MethodPost)
+	r.Handle("/api/repos/{repo:.+?}/raw/{rest:.+}", http.HandlerFunc(getRepoRaw)).Methods(http.MethodGet, http.MethodHead)
+	r.Handle("/api/repos/{repo:.+?}/archive/{rev:.+}", http.HandlerFunc(getRepoArchive)).Methods(http.MethodGet)
+	r.Handle("/api/repos/{repo:.+}/clones", http.HandlerFunc(getRepoClones)).Methods(http.MethodGet)
+	r.Handle("/api/repos/{repo:.+}/clone", http.HandlerFunc(getCloneInstructions)).Methods(http.MethodGet)
+	r.Handle("/api/repos/{repo:.+?}/statuses/{rev:.+}", http.HandlerFunc(getCommitStatuses)).Methods(http.MethodGet)
+	r.Handle("/api/repos/{repo:.+?}/statuses/{rev:.+}", http.HandlerFunc(createCommitStatus)).Methods(http.MethodPost)
+	r.Handle("/api/repos/{repo:.+?}/compare/{spec:.+}", http.HandlerFunc(getCompare)).Methods(http.MethodGet)
+	r.Handle("/api/repos/{repo:.+?}/releases/{tag:.+?}/assets/{name}", http.HandlerFunc(getReleaseAsset)).Methods(http.MethodGet, http.MethodHead)
+	r.Handle("/api/repos/{repo:.+?}/releases/{tag:.+}", http.HandlerFunc(getRelease)).Methods(http.MethodGet)
+	r.Handle("/api/repos/{repo:.+}/releases", http.HandlerFunc(getReleases)).Methods(http.MethodGet)
+	r.Handle("/api/repos/{repo:.+?}/issues/{number:[0-9]+}/comments", http.HandlerFunc(createIssueComment)).Methods(http.MethodPost)
+	r.Handle("/api/repos/{repo:.+?}/issues/{number:[0-9]+}/close", http.HandlerFunc(closeIssue)).Methods(http.MethodPost)
+	r.Handle("/api/repos/{repo:.+?}/issues/{number:[0-9]+}", http.HandlerFunc(getIssue)).Methods(http.MethodGet)
+	r.Handle("/api/repos/{repo:.+}/issues/export", http.HandlerFunc(exportIssues)).Methods(http.MethodGet)
+	r.Handle("/api/repos/{repo:.+}/issues", http.HandlerFunc(getIssues)).Methods(http.MethodGet)
+	r.Handle("/api/repos/{repo:.+}/issues", http.HandlerFunc(createIssue)).Methods(http.MethodPost)
+	r.Handle("/api/repos/{repo:.+}/reviews", http.HandlerFunc(getReviews)).Methods(http.MethodGet)
+}
+
+// apiError is an HTTP API error response.
+type apiError struct {
+	Message string `json:"message"`
+	Help    string `json:"help,omitempty"`
+}
+
+// createRepoRequest is the request body of POST /api/repos.
+type createRepoRequest struct {
+	Name        string `json:"name"`
+	ProjectName string `json:"project_name"`
+	Description string `json:"description"`
+	// Visibility is one of "public", "private", or "hidden". If empty, the
+	// namespace default visibility applies.
+	Visibility    string `json:"visibility"`
+	DefaultBranch string `json:"default_branch"`
+	Template      string `json:"template"`
+	// Readme is whether to commit a generated README to the new repository.
+	// If null, the auto_readme default applies.
+	Readme *bool `json:"readme"`
+	// ObjectFormat is "sha1" or "sha256". If empty, sha1 is used.
+	ObjectFormat string `json:"object_format"`
+}
+
+// repoResponse is the API representation of a repository.
+type repoResponse struct {
+	ID            int64     `json:"id"`
+	Name          string    `json:"name"`
+	ProjectName   string    `json:"project_name"`
+	Description   string    `json:"description"`
+	Private       bool      `json:"private"`
+	Hidden        bool      `json:"hidden"`
+	Mirror        bool      `json:"mirror"`
+	DefaultBranch string    `json:"default_branch"`
+	SSHURL        string    `json:"ssh_url"`
+	HTTPURL       string    `json:"http_url"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// cloneStatsResponse is the API representation of repository clone
+// statistics.
+type cloneStatsResponse struct {
+	Enabled bool                 `json:"enabled"`
+	Count   int64                `json:"count"`
+	Recent  []cloneEventResponse `json:"recent"`
+}
+
+// cloneEventResponse is the API representation of a clone event. An empty
+// username means an anonymous clone.
+type cloneEventResponse struct {
+	Username  string    `json:"username"`
+	Protocol  string    `json:"protocol"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// commitStatusRequest is the request body of POST
+// /api/repos/{repo}/statuses/{rev}.
+type commitStatusRequest struct {
+	// Context defaults to "default".
+	Context     string `json:"context"`
+	State       string `json:"state"`
+	Description string `json:"description"`
+	TargetURL   string `json:"target_url"`
+}
+
+// commitStatusResponse is the API representation of a commit status. An
+// empty username means the status wasn't reported by a user.
+type commitStatusResponse struct {
+	Context     string    `json:"context"`
+	State       string    `json:"state"`
+	Description string    `json:"description"`
+	TargetURL   string    `json:"target_url"`
+	Username    string    `json:"username"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// combinedStatusResponse is the API representation of the statuses of a
+// commit. State is the combined state of the statuses, empty if there are
+// none.
+type combinedStatusResponse struct {
+	SHA      string                 `json:"sha"`
+	State    string                 `json:"state"`
+	Statuses []commitStatusResponse `json:"statuses"`
+}
+
+// compareResponse is the API representation of the diff between two
+// revisions. MergeBase is empty when comparing "base..head", and Truncated
+// means files were left out of a large diff.
+type compareResponse struct {
+	Base      string                `json:"base"`
+	Head      string                `json:"head"`
+	MergeBase string                `json:"merge_base"`
+	Files     []compareFileResponse `json:"files"`
+	Additions int                   `json:"additions"`
+	Deletions int                   `json:"deletions"`
+	Truncated bool                  `json:"truncated"`
+}
+
+// compareFileResponse is a changed file of a compareResponse. Status is one
+// of "added", "modified", "deleted", or "renamed", OldName is only set for
+// renamed files, binary files have no hunks, and Truncated means lines were
+// left out of a large file diff.
+type compareFileResponse struct {
+	Name      string                `json:"name"`
+	OldName   string                `json:"old_name"`
+	Status    string                `json:"status"`
+	Binary    bool                  `json:"binary"`
+	Additions int                   `json:"additions"`
+	Deletions int                   `json:"deletions"`
+	Truncated bool                  `json:"truncated"`
+	Hunks     []compareHunkResponse `json:"hunks"`
+}
+
+// compareHunkResponse is a hunk of a changed file, with its "@@" header.
+type compareHunkResponse struct {
+	Header string                `json:"header"`
+	Lines  []compareLineResponse `json:"lines"`
+}
+
+// compareLineResponse is a line of a hunk. Type is one of "context", "add",
+// or "delete", and a zero line number means the line isn't on that side.
+type compareLineResponse struct {
+	Type    string `json:"type"`
+	Content string `json:"content"`
+	OldLine int    `json:"old_line"`
+	NewLine int    `json:"new_line"`
+}
+
+// releaseResponse is the API representation of a release. An empty username
+// means the release wasn't created by a user.
+type releaseResponse struct {
+	Tag       string                 `json:"tag"`
+	Title     string                 `json:"title"`
+	Notes     string                 `json:"notes"`
+	Username  string                 `json:"username"`
+	Assets    []releaseAssetResponse `json:"assets"`
+	CreatedAt time.Time              `json:"created_at"`
+}
+
+// releaseAssetResponse is the API representation of a release asset, with
+// its download URL.
+type releaseAssetResponse struct {
+	Name      string    `json:"name"`
+	Size      int64     `json:"size"`
+	SHA256    string    `json:"sha256"`
+	URL       string    `json:"url"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// createIssueRequest is the request body of POST /api/repos/{repo}/issues.
+type createIssueRequest struct {
+	Title string `json:"title"`
+	Body  string `json:"body"`
+}
+
+// issueCommentRequest is the request body of POST
+// /api/repos/{repo}/issues/{number}/comments.
+type issueCommentRequest struct {
+	Body string `json:"body"`
+}
+
+// issueResponse is the API representation of an issue. Comments are only
+// included for a single issue and in exports. An empty username means the
+// user was deleted.
+type issueResponse struct {
+	Number       int64                  `json:"number"`
+	Title        string                 `json:"title"`
+	Body         string                 `json:"body"`
+	Username     string                 `json:"username"`
+	State        string                 `json:"state"`
+	CommentCount int64                  `json:"comment_count"`
+	Comments     []issueCommentResponse `json:"comments,omitempty"`
+	CreatedAt    time.Time              `json:"created_at"`
+	UpdatedAt    time.Time              `json:"updated_at"`
+}
+
+// issueCommentResponse is the API representation of an issue comment.
+type issueCommentResponse struct {
+	Username  string    `json:"username"`
+	Body      string    `json:"body"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// reviewResponse is the API representation of a change proposed for review.
+// Ref is the ref the change can be fetched from, and an empty username means
+// the user was deleted.
+type reviewResponse struct {
+	Number       int64     `json:"number"`
+	TargetBranch string    `json:"target_branch"`
+	BaseSHA      string    `json:"base_sha"`
+	CommitSHA    string    `json:"commit_sha"`
+	Ref          string    `json:"ref"`
+	Username     string    `json:"username"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// withAdmin only allows requests authenticated as an admin user.
+func withAdmin(next http.Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		logger := log.FromContext(ctx)
+
+		user, err := authenticate(r)
+		if err != nil || user == nil {
+			if !errors.Is(err, proto.ErrUserNotFound) {
+				logger.Error("failed to authenticate", "err", err)
+			}
+			renderAPIError(w, http.StatusUnauthorized, "unauthorized")
+			return
+		}
+
+		if !user.IsAdmin() {
+			renderAPIError(w, http.StatusForbidden, "forbidden")
+			return
+		}
+
+		ctx = proto.WithUserContext(ctx, user)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	}
+}
+
+// POST /api/repos
+func createRepo(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := log.FromContext(ctx)
+	be := backend.FromContext(ctx)
+	user := proto.UserFromContext(ctx)
+
+	var req createRepoRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		renderAPIError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	name := utils.SanitizeRepo(req.Name)
+	if err := utils.ValidateRepo(name); err != nil {
+		renderAPIError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	opts := proto.RepositoryOptions{
+		ProjectName:   req.ProjectName,
+		Description:   req.Description,
+		DefaultBranch: req.DefaultBranch,
+		Template:      req.Template,
+		Readme:        config.FromContext(ctx).AutoReadme.Enabled,
+		ObjectFormat:  req.ObjectFormat,
+	}
+	if req.Readme != nil {
+		opts.Readme = *req.Readme
+	}
+	switch strings.ToLower(req.Visibility) {
+	case "":
+	case config.VisibilityPublic:
+		opts.ExplicitVisibility = true
+	case config.VisibilityPrivate:
+		opts.Private = true
+		opts.ExplicitVisibility = true
+	case config.VisibilityHidden:
+		opts.Hidden = true
+		opts.ExplicitVisibility = true
+	default:
+		renderAPIError(w, http.StatusBadRequest, "invalid visibility")
+		return
+	}
+
+	if _, err := be.Repository(ctx, name); err == nil {
+		renderAPIError(w, http.StatusConflict, proto.ErrRepoExist.Error())
+		return
+	}
+
+	repo, err := be.CreateRepository(ctx, name, user, opts)
+	if err != nil {
+		switch {
+		case errors.Is(err, proto.ErrRepoExist):
+			renderAPIError(w, http.StatusConflict, err.Error())
+		case errors.Is(err, proto.ErrInvalidBranch), errors.Is(err, proto.ErrInvalidObjectFormat):
+			renderAPIError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, proto.ErrObjectFormatMismatch):
+			renderAPIError(w, http.StatusUnprocessableEntity, err.Error())
+		case errors.Is(err, proto.ErrRepoCreateLimit):
+			renderAPIError(w, http.StatusTooManyRequests, err.Error())
+		case errors.Is(err, proto.ErrQuotaExceeded):
+			renderAPIError(w, http.StatusInsufficientStorage, err.Error())
+		case errors.Is(err, proto.ErrRepoNotFound):
+			// The template repository doesn't exist.
+			renderAPIError(w, http.StatusUnprocessableEntity, err.Error())
+		default:
+			logger.Error("failed to create repository", "repo", name, "err", err)
+			renderAPIError(w, http.StatusInternalServerError, "failed to create repository")
+		}
+		return
+	}
+
+	logger.Info("repository created", "repo", repo.Name(), "user", user.Username())
+	renderAPIJSON(w, http.StatusCreated, newRepoResponse(ctx, repo))
+}
+
+// GET /api/repos/{repo}/clone
+func getCloneInstructions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := log.FromContext(ctx)
+	be := backend.FromContext(ctx)
+	name := utils.SanitizeRepo(mux.Vars(r)["repo"])
+
+	if !authorizeRead(w, r, name) {
+		return
+	}
+
+	ci, err := be.CloneInstructions(ctx, name)
+	if err != nil {
+		if errors.Is(err, proto.ErrRepoNotFound) {
+			renderAPIError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		logger.Error("failed to get clone instructions", "repo", name, "err", err)
+		renderAPIError(w, http.StatusInternalServerError, "failed to get clone instructions")
+		return
+	}
+
+	renderAPIJSON(w, http.StatusOK, ci)
+}
+
+// GET /api/repos/{repo}/clones
+func getRepoClones(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := log.FromContext(ctx)
+	be := backend.FromContext(ctx)
+	name := utils.SanitizeRepo(mux.Vars(r)["repo"])
+
+	user, err := authenticate(r)
+	if err != nil && !errors.Is(err, proto.ErrUserNotFound) {
+		logger.Error("failed to authenticate", "err", err)
+		renderAPIError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	// Clone statistics reveal who accessed a repository, so only repository
+	// admins can see them.
+	accessLevel := be.AccessLevelForUser(ctx, name, user)
+	switch {
+	case accessLevel < access.ReadOnlyAccess:
+		renderAPIError(w, http.StatusNotFound, proto.ErrRepoNotFound.Error())
+		return
+	case accessLevel < access.AdminAccess:
+		renderAPIError(w, http.StatusForbidden, "forbidden")
+		return
+	}
+
+	if !checkHTTPRead(w, r, name) {
+		return
+	}
+
+	limit := 10
+	if v := r.URL.Query().Get("limit"); v != "" {
+		limit, err = strconv.Atoi(v)
+		if err != nil || limit < 0 {
+			renderAPIError(w, http.StatusBadRequest, "invalid limit")
+			return
+		}
+	}
+
+	enabled, err := be.CloneTracking(ctx, name)
+	if err != nil {
+		if errors.Is(err, proto.ErrRepoNotFound) {
+			renderAPIError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		logger.Error("failed to get clone tracking", "repo", name, "err", err)
+		renderAPIError(w, http.StatusInternalServerError, "failed to get clone statistics")
+		return
+	}
+
+	stats, err := be.CloneStats(ctx, name, limit)
+	if err != nil {
+		logger.Error("failed to get clone statistics", "repo", name, "err", err)
+		renderAPIError(w, http.StatusInternalServerError, "failed to get clone statistics")
+		return
+	}
+
+	resp := cloneStatsResponse{
+		Enabled: enabled,
+		Count:   stats.Count,
+		Recent:  make([]cloneEventResponse, 0, len(stats.Recent)),
+	}
+	for _, e := range stats.Recent {
+		resp.Recent = append(resp.Recent, cloneEventResponse{
+			Username:  e.Username.String,
+			Protocol:  e.Protocol,
+			CreatedAt: e.CreatedAt,
+		})
+	}
+
+	renderAPIJSON(w, http.StatusOK, resp)
+}
+
+// GET /api/repos/{repo}/statuses/{rev}
+func getCommitStatuses(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := log.FromContext(ctx)
+	be := backend.FromContext(ctx)
+	vars := mux.Vars(r)
+	name := utils.SanitizeRepo(vars["repo"])
+
+	if !authorizeRead(w, r, name) {
+		return
+	}
+
+	sha, err := be.ResolveCommit(ctx, name, vars["rev"])
+	if err != nil {
+		renderCommitStatusError(w, logger, name, err)
+		return
+	}
+
+	statuses, err := be.CommitStatuses(ctx, name, sha)
+	if err != nil {
+		logger.Error("failed to get commit statuses", "repo", name, "err", err)
+		renderAPIError(w, http.StatusInternalServerError, "failed to get commit statuses")
+		return
+	}
+
+	resp := combinedStatusResponse{
+		SHA:      sha,
+		State:    backend.CombinedCommitState(statuses),
+		Statuses: make([]commitStatusResponse, 0, len(statuses)),
+	}
+	for _, s := range statuses {
+		resp.Statuses = append(resp.Statuses, newCommitStatusResponse(s))
+	}
+
+	renderAPIJSON(w, http.StatusOK, resp)
+}
+
+// POST /api/repos/{repo}/statuses/{rev}
+func createCommitStatus(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	cfg := config.FromContext(ctx)
+	logger := log.FromContext(ctx)
+	be := backend.FromContext(ctx)
+	vars := mux.Vars(r)
+	name := utils.SanitizeRepo(vars["repo"])
+
+	user, err := authenticate(r)
+	if err != nil || user == nil {
+		if !errors.Is(err, proto.ErrUserNotFound) {
+			logger.Error("failed to authenticate", "err", err)
+		}
+		renderAPIError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	switch accessLevel := be.AccessLevelForUser(ctx, name, user); {
+	case accessLevel < access.ReadOnlyAccess:
+		renderAPIError(w, http.StatusNotFound, proto.ErrRepoNotFound.Error())
+		return
+	case accessLevel < cfg.Access.CapabilityLevel(access.CapabilityPostStatuses):
+		renderAPIError(w, http.StatusForbidden, "forbidden")
+		return
+	}
+
+	if !checkHTTPRead(w, r, name) {
+		return
+	}
+
+	var req commitStatusRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		renderAPIError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if req.Context == "" {
+		req.Context = "default"
+	}
+
+	sha, err := be.SetCommitStatus(ctx, name, user, vars["rev"], backend.CommitStatus{
+		Context:     req.Context,
+		State:       req.State,
+		Description: req.Description,
+		TargetURL:   req.TargetURL,
+	})
+	if err != nil {
+		renderCommitStatusError(w, logger, name, err)
+		return
+	}
+
+	statuses, err := be.CommitStatuses(ctx, name, sha)
+	if err != nil {
+		logger.Error("failed to get commit statuses", "repo", name, "err", err)
+		renderAPIError(w, http.StatusInternalServerError, "failed to get commit statuses")
+		return
+	}
+
+	for _, s := range statuses {
+		if s.Context == req.Context {
+			logger.Debug("commit status reported", "repo", name, "sha", sha, "context", s.Context, "state", s.State, "user", user.Username())
+			renderAPIJSON(w, http.StatusCreated, newCommitStatusResponse(s))
+			return
+		}
+	}
+
+	renderAPIError(w, http.StatusInternalServerError, "failed to get commit statuses")
+}
+
+func renderCommitStatusError(w http.ResponseWriter, logger *log.Logger, repo string, err error) {
+	switch {
+	case errors.Is(err, proto.ErrRepoNotFound), errors.Is(err, proto.ErrCommitNotFound):
+		renderAPIError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, backend.ErrInvalidCommitStatus):
+		renderAPIError(w, http.StatusUnprocessableEntity, err.Error())
+	default:
+		logger.Error("commit status error", "repo", repo, "err", err)
+		renderAPIError(w, http.StatusInternalServerError, "internal server error")
+	}
+}
+
+// GET /api/repos/{repo}/compare/{base}...{head}
+// GET /api/repos/{repo}/compare/{base}..{head}
+func getCompare(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := log.FromContext(ctx)
+	be := backend.FromContext(ctx)
+	vars := mux.Vars(r)
+	name := utils.SanitizeRepo(vars["repo"])
+
+	if !authorizeRead(w, r, name) {
+		return
+	}
+
+	base, head, fromMergeBase, ok := parseCompareSpec(vars["spec"])
+	if !ok {
+		renderAPIError(w, http.StatusBadRequest, "expected {base}...{head} or {base}..{head}")
+		return
+	}
+
+	cmp, err := be.Compare(ctx, name, base, head, fromMergeBase)
+	switch {
+	case errors.Is(err, proto.ErrRepoNotFound), errors.Is(err, proto.ErrCommitNotFound):
+		renderAPIError(w, http.StatusNotFound, err.Error())
+		return
+	case errors.Is(err, proto.ErrNoMergeBase):
+		renderAPIError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	case errors.Is(err, proto.ErrDeniedPath):
+		renderAPIError(w, http.StatusForbidden, err.Error())
+		return
+	case err != nil:
+		logger.Error("failed to compare revisions", "repo", name, "base", base, "head", head, "err", err)
+		renderAPIError(w, http.StatusInternalServerError, "failed to compare revisions")
+		return
+	}
+
+	renderAPIJSON(w, http.StatusOK, newCompareResponse(cmp))
+}
+
+// parseCompareSpec splits "base...head" or "base..head" into its revisions.
+// The three dots form compares from the merge base of both revisions.
+func parseCompareSpec(spec string) (base string, head string, fromMergeBase bool, ok bool) {
+	if base, head, ok = strings.Cut(spec, "..."); ok {
+		fromMergeBase = true
+	} else if base, head, ok = strings.Cut(spec, ".."); !ok {
+		return "", "", false, false
+	}
+
+	return base, head, fromMergeBase, base != "" && head != ""
+}
+
+// GET /api/repos/{repo}/releases
+func getReleases(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := log.FromContext(ctx)
+	be := backend.FromContext(ctx)
+	name := utils.SanitizeRepo(mux.Vars(r)["repo"])
+
+	if !authorizeRead(w, r, name) {
+		return
+	}
+
+	releases, err := be.Releases(ctx, name)
+	if err != nil {
+		renderReleaseError(w, logger, name, err)
+		return
+	}
+
+	resp := make([]releaseResponse, 0, len(releases))
+	for _, rel := range releases {
+		resp = append(resp, newReleaseResponse(be, name, rel))
+	}
+
+	renderAPIJSON(w, http.StatusOK, resp)
+}
+
+// GET /api/repos/{repo}/releases/{tag}
+func getRelease(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := log.FromContext(ctx)
+	be := backend.FromContext(ctx)
+	vars := mux.Vars(r)
+	name := utils.SanitizeRepo(vars["repo"])
+
+	if !authorizeRead(w, r, name) {
+		return
+	}
+
+	rel, err := be.Release(ctx, name, vars["tag"])
+	if err != nil {
+		renderReleaseError(w, logger, name, err)
+		return
+	}
+
+	renderAPIJSON(w, http.StatusOK, newReleaseResponse(be, name, rel))
+}
+
+// GET /api/repos/{repo}/releases/{tag}/assets/{name}
+func getReleaseAsset(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := log.FromContext(ctx)
+	be := backend.FromContext(ctx)
+	vars := mux.Vars(r)
+	name := utils.SanitizeRepo(vars["repo"])
+
+	if !authorizeRead(w, r, name) {
+		return
+	}
+
+	obj, asset, err := be.OpenReleaseAsset(ctx, name, vars["tag"], vars["name"])
+	if err != nil {
+		renderReleaseError(w, logger, name, err)
+		return
+	}
+	defer obj.Close() // nolint: errcheck
+
+	// The digest identifies the content, so it makes a strong ETag and lets
+	// http.ServeContent answer conditional and range requests.
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": asset.Name}))
+	w.Header().Set("ETag", `"`+asset.SHA256+`"`)
+	http.ServeContent(w, r, asset.Name, asset.UpdatedAt, obj)
+}
+
+// authorizeRead renders an error and returns false unless the request can
+// read a repository, over HTTP.
+func authorizeRead(w http.ResponseWriter, r *http.Request, repo string) bool {
+	ctx := r.Context()
+	logger := log.FromContext(ctx)
+	be := backend.FromContext(ctx)
+
+	user, err := authenticate(r)
+	if err != nil && !errors.Is(err, proto.ErrUserNotFound) {
+		logger.Error("failed to authenticate", "err", err)
+		renderAPIError(w, http.StatusUnauthorized, "unauthorized")
+		return false
+	}
+
+	if be.AccessLevelForUser(ctx, repo, user) < access.ReadOnlyAccess {
+		renderAPIError(w, http.StatusNotFound, proto.ErrRepoNotFound.Error())
+		return false
+	}
+
+	return checkHTTPRead(w, r, repo)
+}
+
+func renderReleaseError(w http.ResponseWriter, logger *log.Logger, repo string, err error) {
+	switch {
+	case errors.Is(err, proto.ErrRepoNotFound), errors.Is(err, proto.ErrReleaseNotFound),
+		errors.Is(err, proto.ErrReleaseAssetNotFound):
+		renderAPIError(w, http.StatusNotFound, err.Error())
+	default:
+		logger.Error("release error", "repo", repo, "err", err)
+		renderAPIError(w, http.StatusInternalServerError, "internal server error")
+	}
+}
+
+// GET /api/repos/{repo}/issues?state={open,closed,all}
+func getIssues(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := log.FromContext(ctx)
+	be := backend.FromContext(ctx)
+	name := utils.SanitizeRepo(mux.Vars(r)["repo"])
+
+	if !authorizeRead(w, r, name) {
+		return
+	}
+
+	state := r.URL.Query().Get("state")
+	switch state {
+	case "":
+		state = backend.IssueStateOpen
+	case "all":
+		state = ""
+	}
+
+	issues, err := be.Issues(ctx, name, state)
+	if err != nil {
+		renderIssueError(w, logger, name, err)
+		return
+	}
+
+	renderAPIJSON(w, http.StatusOK, newIssueResponses(issues))
+}
+
+// GET /api/repos/{repo}/issues/export
+func exportIssues(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := log.FromContext(ctx)
+	be := backend.FromContext(ctx)
+	name := utils.SanitizeRepo(mux.Vars(r)["repo"])
+
+	if !authorizeRead(w, r, name) {
+		return
+	}
+
+	issues, err := be.ExportIssues(ctx, name)
+	if err != nil {
+		renderIssueError(w, logger, name, err)
+		return
+	}
+
+	renderAPIJSON(w, http.StatusOK, newIssueResponses(issues))
+}
+
+// GET /api/repos/{repo}/issues/{number}
+func getIssue(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := log.FromContext(ctx)
+	be := backend.FromContext(ctx)
+	vars := mux.Vars(r)
+	name := utils.SanitizeRepo(vars["repo"])
+
+	if !authorizeRead(w, r, name) {
+		return
+	}
+
+	number, _ := strconv.ParseInt(vars["number"], 10, 64)
+	issue, err := be.Issue(ctx, name, number)
+	if err != nil {
+		renderIssueError(w, logger, name, err)
+		return
+	}
+
+	renderAPIJSON(w, http.StatusOK, newIssueResponse(issue))
+}
+
+// POST /api/repos/{repo}/issues
+func createIssue(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := log.FromContext(ctx)
+	be := backend.FromContext(ctx)
+	name := utils.SanitizeRepo(mux.Vars(r)["repo"])
+
+	user, ok := authorizeIssueUser(w, r, name)
+	if !ok {
+		return
+	}
+
+	var req createIssueRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		renderAPIError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	issue, err := be.CreateIssue(ctx, name, user, req.Title, req.Body)
+	if err != nil {
+		renderIssueError(w, logger, name, err)
+		return
+	}
+
+	renderAPIJSON(w, http.StatusCreated, newIssueResponse(issue))
+}
+
+// POST /api/repos/{repo}/issues/{number}/comments
+func createIssueComment(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := log.FromContext(ctx)
+	be := backend.FromContext(ctx)
+	vars := mux.Vars(r)
+	name := utils.SanitizeRepo(vars["repo"])
+
+	user, ok := authorizeIssueUser(w, r, name)
+	if !ok {
+		return
+	}
+
+	var req issueCommentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		renderAPIError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	number, _ := strconv.ParseInt(vars["number"], 10, 64)
+	if err := be.CommentIssue(ctx, name, user, number, req.Body); err != nil {
+		renderIssueError(w, logger, name, err)
+		return
+	}
+
+	issue, err := be.Issue(ctx, name, number)
+	if err != nil {
+		renderIssueError(w, logger, name, err)
+		return
+	}
+
+	renderAPIJSON(w, http.StatusCreated, newIssueResponse(issue))
+}
+
+// POST /api/repos/{repo}/issues/{number}/close
+func closeIssue(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := log.FromContext(ctx)
+	be := backend.FromContext(ctx)
+	vars := mux.Vars(r)
+	name := utils.SanitizeRepo(vars["repo"])
+
+	user, ok := authorizeIssueUser(w, r, name)
+	if !ok {
+		return
+	}
+
+	number, _ := strconv.ParseInt(vars["number"], 10, 64)
+	if err := be.CloseIssue(ctx, name, user, number); err != nil {
+		renderIssueError(w, logger, name, err)
+		return
+	}
+
+	issue, err := be.Issue(ctx, name, number)
+	if err != nil {
+		renderIssueError(w, logger, name, err)
+		return
+	}
+
+	renderAPIJSON(w, http.StatusOK, newIssueResponse(issue))
+}
+
+// authorizeIssueUser renders an error and returns false unless the request
+// is authenticated as a user that can read a repository over HTTP. Anonymous
+// users can read issues, but not open or comment on them.
+func authorizeIssueUser(w http.ResponseWriter, r *http.Request, repo string) (proto.User, bool) {
+	ctx := r.Context()
+	logger := log.FromContext(ctx)
+	be := backend.FromContext(ctx)
+
+	user, err := authenticate(r)
+	if err != nil || user == nil {
+		if !errors.Is(err, proto.ErrUserNotFound) {
+			logger.Error("failed to authenticate", "err", err)
+		}
+		renderAPIError(w, http.StatusUnauthorized, "unauthorized")
+		return nil, false
+	}
+
+	if be.AccessLevelForUser(ctx, repo, user) < access.ReadOnlyAccess {
+		renderAPIError(w, http.StatusNotFound, proto.ErrRepoNotFound.Error())
+		return nil, false
+	}
+
+	if !checkHTTPRead(w, r, repo) {
+		return nil, false
+	}
+
+	return user, true
+}
+
+// GET /api/repos/{repo}/reviews
+func getReviews(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := log.FromContext(ctx)
+	be := backend.FromContext(ctx)
+	name := utils.SanitizeRepo(mux.Vars(r)["repo"])
+
+	if !authorizeRead(w, r, name) {
+		return
+	}
+
+	reviews, err := be.Reviews(ctx, name)
+	if err != nil {
+		if errors.Is(err, proto.ErrRepoNotFound) {
+			renderAPIError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		logger.Error("failed to get reviews", "repo", name, "err", err)
+		renderAPIError(w, http.StatusInternalServerError, "failed to get reviews")
+		return
+	}
+
+	resp := make([]reviewResponse, 0, len(reviews))
+	for _, rv := range reviews {
+		resp = append(resp, reviewResponse{
+			Number:       rv.Number,
+			TargetBranch: rv.TargetBranch,
+			BaseSHA:      rv.BaseSHA,
+			CommitSHA:    rv.CommitSHA,
+			Ref:          rv.Ref,
+			Username:     rv.Username,
+			CreatedAt:    rv.CreatedAt,
+		})
+	}
+
+	renderAPIJSON(w, http.StatusOK, resp)
+}
+
+func renderIssueError(w http.ResponseWriter, logger *log.Logger, repo string, err error) {
+	switch {
+	case errors.Is(err, proto.ErrRepoNotFound), errors.Is(err, proto.ErrIssueNotFound):
+		renderAPIError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, backend.ErrInvalidIssue):
+		renderAPIError(w, http.StatusUnprocessableEntity, err.Error())
+	case errors.Is(err, proto.ErrUnauthorized), errors.Is(err, proto.ErrReadOnlyReplica):
+		renderAPIError(w, http.StatusForbidden, err.Error())
+	default:
+		logger.Error("issue error", "repo", repo, "err", err)
+		renderAPIError(w, http.StatusInternalServerError, "internal server error")
+	}
+}
+
+// GET /api/repos/{repo}/archive/{rev}?path={path}
+func getRepoArchive(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := log.FromContext(ctx)
+	be := backend.FromContext(ctx)
+	vars := mux.Vars(r)
+	name := utils.SanitizeRepo(vars["repo"])
+
+	if !authorizeRead(w, r, name) {
+		return
+	}
+
+	a, err := be.TreeArchive(ctx, name, vars["rev"], r.URL.Query().Get("path"))
+	switch {
+	case err == nil:
+	case errors.Is(err, proto.ErrRepoNotFound), errors.Is(err, proto.ErrCommitNotFound),
+		errors.Is(err, proto.ErrFileNotFound):
+		renderAPIError(w, http.StatusNotFound, err.Error())
+		return
+	case errors.Is(err, proto.ErrDeniedPath):
+		renderAPIError(w, http.StatusForbidden, err.Error())
+		return
+	default:
+		logger.Error("failed to resolve archive", "repo", name, "err", err)
+		renderAPIError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	release, err := be.AcquireUploadArchive(ctx, r.RemoteAddr)
+	if err != nil {
+		w.Header().Set("Retry-After", "10")
+		renderAPIError(w, http.StatusTooManyRequests, err.Error())
+		return
+	}
+	defer release()
+
+	filename := path.Base(name) + "-" + a.Commit[:7] + ".tar"
+	w.Header().Set("Content-Type", "application/x-tar")
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(http.StatusOK)
+	if err := a.Stream(ctx, w); err != nil {
+		logger.Error("failed to stream archive", "repo", name, "err", err)
+	}
+}
+
+// GET /api/repos/{repo}/raw/{ref}/{path}
+func getRepoRaw(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := log.FromContext(ctx)
+	be := backend.FromContext(ctx)
+	name := utils.SanitizeRepo(mux.Vars(r)["repo"])
+
+	if !authorizeRead(w, r, name) {
+		return
+	}
+
+	repo, err := be.Repository(ctx, name)
+	if err != nil {
+		if errors.Is(err, proto.ErrRepoNotFound) {
+			renderAPIError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		logger.Error("failed to get repository", "repo", name, "err", err)
+		renderAPIError(w, http.StatusInternalServerError, "failed to get file")
+		return
+	}
+
+	rr, err := repo.Open()
+	if err != nil {
+		logger.Error("failed to open repository", "repo", name, "err", err)
+		renderAPIError(w, http.StatusInternalServerError, "failed to get file")
+		return
+	}
+
+	if err := be.CheckDeniedPaths(ctx, name); err != nil {
+		if errors.Is(err, proto.ErrDeniedPath) {
+			renderAPIError(w, http.StatusForbidden, err.Error())
+			return
+		}
+		logger.Error("failed to check denied paths", "repo", name, "err", err)
+		renderAPIError(w, http.StatusInternalServerError, "failed to get file")
+		return
+	}
+
+	blob, err := resolveRawBlob(ctx, rr.Path, mux.Vars(r)["rest"])
+	if err != nil {
+		if errors.Is(err, proto.ErrFileNotFound) {
+			renderAPIError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		logger.Error("failed to resolve file", "repo", name, "err", err)
+		renderAPIError(w, http.StatusInternalServerError, "failed to get file")
+		return
+	}
+
+	denied, err := be.DeniedPaths(ctx, name)
+	if err != nil {
+		logger.Error("failed to get denied paths", "repo", name, "err", err)
+		renderAPIError(w, http.StatusInternalServerError, "failed to get file")
+		return
+	}
+	if backend.IsDeniedPath(denied, blob.path) {
+		renderAPIError(w, http.StatusForbidden, "file is a denied path")
+		return
+	}
+
+	// Blobs are content addressed, their ID is a strong validator.
+	etag := `"` + blob.id + `"`
+	w.Header().Set("ETag", etag)
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	// Stream the blob, only the first bytes are buffered to guess the
+	// content type when the extension is unknown.
+	pr, pw := io.Pipe()
+	defer pr.Close() // nolint: errcheck
+	go func() {
+		err := git.NewCommand("cat-file", "blob", blob.id).
+			WithContext(ctx).
+			WithTimeout(-1).
+			RunInDirWithOptions(rr.Path, git.RunInDirOptions{Stdout: pw})
+		pw.CloseWithError(err) // nolint: errcheck
+	}()
+
+	br := bufio.NewReader(pr)
+	ctype := mime.TypeByExtension(path.Ext(blob.path))
+	if ctype == "" {
+		head, _ := br.Peek(512)
+		ctype = http.DetectContentType(head)
+	}
+
+	// Repository content is untrusted, don't let browsers run it.
+	w.Header().Set("Content-Type", ctype)
+	w.Header().Set("Content-Length", strconv.FormatInt(blob.size, 10))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Content-Security-Policy", "default-src 'none'; sandbox")
+	w.WriteHeader(http.StatusOK)
+	if r.Method == http.MethodHead {
+		return
+	}
+
+	if _, err := io.Copy(w, br); err != nil {
+		logger.Debug("failed to write file", "repo", name, "path", blob.path, "err", err)
+	}
+}
+
+// checkHTTPRead renders an error and returns false if the repository can't
+// be read over HTTP.
+func checkHTTPRead(w http.ResponseWriter, r *http.Request, repo string) bool {
+	ctx := r.Context()
+	err := backend.FromContext(ctx).CheckProtocol(ctx, repo, backend.ProtocolHTTP, false)
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, proto.ErrProtocolNotAllowed):
+		renderAPIError(w, http.StatusForbidden, err.Error())
+	default:
+		log.FromContext(ctx).Error("failed to check protocol", "repo", repo, "err", err)
+		renderAPIError(w, http.StatusInternalServerError, "internal server error")
+	}
+
+	return false
+}
+
+// rawBlob is a blob resolved from a raw file request.
+type rawBlob struct {
+	id   string
+	path string
+	size int64
+}
+
+// resolveRawBlob resolves a "{ref}/{path}" string to a blob. Refs can contain
+// slashes, so every split is tried and the shortest ref that has a blob at
+// the remaining path wins.
+func resolveRawBlob(ctx context.Context, repoPath string, rest string) (rawBlob, error) {
+	parts := strings.Split(strings.Trim(rest, "/"), "/")
+	if len(parts) < 2 || strings.ContainsAny(rest, "\n\r") {
+		return rawBlob{}, proto.ErrFileNotFound
+	}
+
+	var in strings.Builder
+	for i := 1; i < len(parts); i++ {
+		fmt.Fprintf(&in, "%s:%s\n", strings.Join(parts[:i], "/"), strings.Join(parts[i:], "/"))
+	}
+
+	var out bytes.Buffer
+	if err := git.NewCommand("cat-file", "--batch-check").
+		WithContext(ctx).
+		RunInDirWithOptions(repoPath, git.RunInDirOptions{
+			Stdin:  strings.NewReader(in.String()),
+			Stdout: &out,
+		}); err != nil {
+		return rawBlob{}, err
+	}
+
+	// Each line is either "<id> <type> <size>" or "<object> missing".
+	for i, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 3 || fields[1] != "blob" || i+1 >= len(parts) {
+			continue
+		}
+
+		size, err := strconv.ParseInt(fields[2], 10, 64)
+		if err != nil {
+			continue
+		}
+
+		return rawBlob{
+			id:   fields[0],
+			path: strings.Join(parts[i+1:], "/"),
+			size: size,
+		}, nil
+	}
+
+	return rawBlob{}, proto.ErrFileNotFound
+}
+
+// etagMatches returns whether an If-None-Match header value matches etag.
+func etagMatches(header string, etag string) bool {
+	for _, v := range strings.Split(header, ",") {
+		v = strings.TrimPrefix(strings.TrimSpace(v), "W/")
+		if v == etag || v == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+func newRepoResponse(ctx context.Context, repo proto.Repository) repoResponse {
+	cfg := config.FromContext(ctx)
+
+	// Empty repositories have no HEAD commit yet, so read the default branch
+	// from the symbolic ref instead.
+	var branch string
+	if r, err := repo.Open(); err == nil {
+		if ref, err := r.SymbolicRef(git.HEAD, ""); err == nil {
+			branch = strings.TrimPrefix(ref, git.RefsHeads)
+		}
+	}
+
+	return repoResponse{
+		ID:            repo.ID(),
+		Name:          repo.Name(),
+		ProjectName:   repo.ProjectName(),
+		Description:   repo.Description(),
+		Private:       repo.IsPrivate(),
+		Hidden:        repo.IsHidden(),
+		Mirror:        repo.IsMirror(),
+		DefaultBranch: branch,
+		SSHURL:        fmt.Sprintf("%s/%s.git", cfg.SSH.PublicURL, repo.Name()),
+		HTTPURL:       fmt.Sprintf("%s/%s.git", cfg.HTTP.PublicURL, repo.Name()),
+		CreatedAt:     repo.CreatedAt(),
+		UpdatedAt:     repo.UpdatedAt(),
+	}
+}
+
+func newReleaseResponse(be *backend.Backend, repo string, rel backend.Release) releaseResponse {
+	resp := releaseResponse{
+		Tag:       rel.Tag,
+		Title:     rel.Title,
+		Notes:     rel.Notes,
+		Username:  rel.Username,
+		Assets:    make([]releaseAssetResponse, 0, len(rel.Assets)),
+		CreatedAt: rel.CreatedAt,
+	}
+	for _, a := range rel.Assets {
+		resp.Assets = append(resp.Assets, releaseAssetResponse{
+			Name:      a.Name,
+			Size:      a.Size,
+			SHA256:    a.SHA256,
+			URL:       be.ReleaseAssetURL(repo, rel.Tag, a.Name),
+			UpdatedAt: a.UpdatedAt,
+		})
+	}
+
+	return resp
+}
+
+func newIssueResponse(issue backend.Issue) issueResponse {
+	resp := issueResponse{
+		Number:       issue.Number,
+		Title:        issue.Title,
+		Body:         issue.Body,
+		Username:     issue.Username,
+		State:        issue.State,
+		CommentCount: issue.CommentCount,
+		CreatedAt:    issue.CreatedAt,
+		UpdatedAt:    issue.UpdatedAt,
+	}
+	for _, c := range issue.Comments {
+		resp.Comments = append(resp.Comments, issueCommentResponse{
+			Username:  c.Username,
+			Body:      c.Body,
+			CreatedAt: c.CreatedAt,
+		})
+	}
+
+	return resp
+}
+
+func newIssueResponses(issues []backend.Issue) []issueResponse {
+	resp := make([]issueResponse, 0, len(issues))
+	for _, issue := range issues {
+		resp = append(resp, newIssueResponse(issue))
+	}
+
+	return resp
+}
+
+func newCommitStatusResponse(s models.CommitStatus) commitStatusResponse {
+	return commitStatusResponse{
+		Context:     s.Context,
+		State:       s.State,
+		Description: s.Description,
+		TargetURL:   s.TargetURL,
+		Username:    s.Username.String,
+		CreatedAt:   s.CreatedAt,
+		UpdatedAt:   s.UpdatedAt,
+	}
+}
+
+func newCompareResponse(cmp backend.Comparison) compareResponse {
+	resp := compareResponse{
+		Base:      cmp.Base,
+		Head:      cmp.Head,
+		MergeBase: cmp.MergeBase,
+		Files:     make([]compareFileResponse, 0, len(cmp.Diff.Files)),
+		Truncated: cmp.Diff.IsIncomplete(),
+	}
+	for _, f := range cmp.Diff.Files {
+		file := compareFileResponse{
+			Name:      f.Name,
+			Status:    "modified",
+			Binary:    f.IsBinary(),
+			Additions: f.NumAdditions(),
+			Deletions: f.NumDeletions(),
+			Truncated: f.IsIncomplete(),
+			Hunks:     []compareHunkResponse{},
+		}
+		switch {
+		case f.IsCreated():
+			file.Status = "added"
+		case f.IsDeleted():
+			file.Status = "deleted"
+		case f.IsRenamed():
+			file.Status = "renamed"
+			file.OldName = f.OldName()
+		}
+		for _, s := range f.Sections {
+			hunk := compareHunkResponse{Lines: []compareLineResponse{}}
+			for _, l := range s.Lines {
+				line := compareLineResponse{
+					Content: l.Content,
+					OldLine: l.LeftLine,
+					NewLine: l.RightLine,
+				}
+				switch l.Type {
+				case git.DiffLineSection:
+					hunk.Header = l.Content
+					continue
+				case git.DiffLineAdd:
+					line.Type = "add"
+				case git.DiffLineDelete:
+					line.Type = "delete"
+				default:
+					line.Type = "context"
+				}
+				// Drop the diff sign, the type already tells it.
+				if len(line.Content) > 0 {
+					line.Content = line.Content[1:]
+				}
+				hunk.Lines = append(hunk.Lines, line)
+			}
+			file.Hunks = append(file.Hunks, hunk)
+		}
+		// The totals only count the files of the comparison, files at
+		// denied paths are left out.
+		resp.Additions += file.Additions
+		resp.Deletions += file.Deletions
+		resp.Files = append(resp.Files, file)
+	}
+
+	return resp
+}
+
+func renderAPIJSON(w http.ResponseWriter, statusCode int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Error("error encoding json", "err", err)
+	}
+}
+
+func renderAPIError(w http.ResponseWriter, statusCode int, msg string) {
+	renderAPIJSON(w, statusCode, apiError{Message: msg})
+}
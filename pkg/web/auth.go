@@ -28,6 +28,23 @@ func authenticate(r *http.Request) (proto.User, error) {
 	return user, nil
 }
 
+// deployToken returns the deploy token of the request, sent as the password,
+// or the username, of HTTP Basic authentication.
+func deployToken(r *http.Request) (string, bool) {
+	username, password, ok := r.BasicAuth()
+	if !ok {
+		return "", false
+	}
+
+	for _, t := range []string{password, username} {
+		if backend.IsDeployToken(t) {
+			return t, true
+		}
+	}
+
+	return "", false
+}
+
 // ErrInvalidPassword is returned when the password is invalid.
 var ErrInvalidPassword = errors.New("invalid password")
 
@@ -0,0 +1,806 @@
+package web
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"html/template"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alecthomas/chroma/v2"
+	chtml "github.com/alecthomas/chroma/v2/formatters/html"
+	"github.com/alecthomas/chroma/v2/lexers"
+	"github.com/alecthomas/chroma/v2/styles"
+	"github.com/charmbracelet/log"
+	"github.com/charmbracelet/soft-serve/git"
+	"github.com/charmbracelet/soft-serve/pkg/access"
+	"github.com/charmbracelet/soft-serve/pkg/backend"
+	"github.com/charmbracelet/soft-serve/pkg/config"
+	"github.com/charmbracelet/soft-serve/pkg/proto"
+	"github.com/charmbracelet/soft-serve/pkg/utils"
+	"github.com/dustin/go-humanize"
+	"github.com/gorilla/mux"
+)
+
+const (
+	// browsePrefix is the path of the HTML interface.
+	browsePrefix = "/browse"
+	// browseCommitsPerPage is the number of commits of a log page.
+	browseCommitsPerPage = 30
+	// browseMaxFileSize is the size of the largest file shown, larger files
+	// are only linked to.
+	browseMaxFileSize = 1 << 20
+)
+
+// BrowseController is a router for the read-only HTML interface. It's only
+// served when http.browse is enabled.
+func BrowseController(ctx context.Context, r *mux.Router) {
+	cfg := config.FromContext(ctx)
+	if cfg == nil || !cfg.HTTP.Browse {
+		return
+	}
+
+	get := []string{http.MethodGet, http.MethodHead}
+	r.Handle(browsePrefix, http.HandlerFunc(browseRepos)).Methods(get...)
+	r.Handle(browsePrefix+"/{repo:.+?}/-/tree", http.HandlerFunc(browseTree)).Methods(get...)
+	r.Handle(browsePrefix+"/{repo:.+?}/-/blob", http.HandlerFunc(browseBlob)).Methods(get...)
+	r.Handle(browsePrefix+"/{repo:.+?}/-/log", http.HandlerFunc(browseLog)).Methods(get...)
+	r.Handle(browsePrefix+"/{repo:.+?}/-/commit/{rev}", http.HandlerFunc(browseCommit)).Methods(get...)
+	r.Handle(browsePrefix+"/{repo:.+}", http.HandlerFunc(browseTree)).Methods(get...)
+}
+
+// browseStyle is the syntax highlighting style of files and diffs.
+var browseStyle = styles.Get("github")
+
+var browseFuncs = template.FuncMap{
+	"treeURL":   browseTreeURL,
+	"blobURL":   browseBlobURL,
+	"logURL":    browseLogURL,
+	"commitURL": browseCommitURL,
+	"rawURL":    browseRawURL,
+	"repoURL": func(repo string) string {
+		return browsePrefix + "/" + escapeRepo(repo)
+	},
+	"shortHash": func(h string) string {
+		if len(h) > 7 {
+			return h[:7]
+		}
+		return h
+	},
+	"ago": func(t time.Time) string {
+		return humanize.Time(t)
+	},
+	"bytes": func(n int64) string {
+		return humanize.Bytes(uint64(n))
+	},
+	"firstLine": func(s string) string {
+		line, _, _ := strings.Cut(strings.TrimSpace(s), "\n")
+		return line
+	},
+}
+
+var browseTpl = template.Must(template.New("browse").Funcs(browseFuncs).Parse(`
+{{- define "header" -}}
+<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta http-equiv="Content-Type" content="text/html; charset=utf-8"/>
+    <meta name="viewport" content="width=device-width, initial-scale=1"/>
+    <title>{{ if .Repo }}{{ .Repo }} - {{ end }}{{ .ServerName }}</title>
+    <style>
+        body { font-family: sans-serif; max-width: 72em; margin: 2em auto; padding: 0 1em; color: #333; }
+        a { color: #0969da; text-decoration: none; }
+        a:hover { text-decoration: underline; }
+        header { border-bottom: 1px solid #ddd; margin-bottom: 1em; padding-bottom: 0.5em; }
+        header h1 { font-size: 1.4em; margin: 0 0 0.3em; }
+        nav a { margin-right: 1em; }
+        table { border-collapse: collapse; width: 100%; }
+        td, th { padding: 0.3em 0.6em; text-align: left; border-bottom: 1px solid #eee; vertical-align: top; }
+        code, pre { font-family: monospace; font-size: 0.9em; }
+        pre { overflow-x: auto; }
+        .muted { color: #777; }
+        .right { text-align: right; }
+        .message { white-space: pre-wrap; background: #f6f8fa; padding: 0.6em; }
+    </style>
+</head>
+<body>
+<header>
+    <h1><a href="/browse">{{ .ServerName }}</a>{{ if .Repo }} / <a href="{{ repoURL .Repo }}">{{ .Repo }}</a>{{ end }}</h1>
+    {{- if .Repo }}
+    <nav>
+        <a href="{{ treeURL .Repo .Ref "" }}">Files</a>
+        <a href="{{ logURL .Repo .Ref 1 }}">Log</a>
+        {{- if .Ref }}<span class="muted">{{ .Ref }}</span>{{ end }}
+    </nav>
+    {{- end }}
+</header>
+{{- end }}
+
+{{- define "footer" }}
+</body>
+</html>
+{{ end }}
+
+{{- define "repos" -}}
+{{ template "header" . }}
+{{- if .Repos }}
+<table>
+    <tr><th>Repository</th><th>Description</th><th class="right">Updated</th></tr>
+    {{- range .Repos }}
+    <tr>
+        <td><a href="{{ repoURL .Name }}">{{ .Name }}</a>{{ if .Private }} <span class="muted">(private)</span>{{ end }}</td>
+        <td>{{ .Description }}</td>
+        <td class="right muted">{{ ago .UpdatedAt }}</td>
+    </tr>
+    {{- end }}
+</table>
+{{- else }}
+<p>There are no repositories you can browse.</p>
+{{- end }}
+{{- if not .User }}
+<p class="muted"><a href="/browse?login=1">Sign in</a> with your username and an access token to browse private repositories.</p>
+{{- end }}
+{{ template "footer" . }}
+{{- end }}
+
+{{- define "tree" -}}
+{{ template "header" . }}
+{{- if .Description }}<p>{{ .Description }}</p>{{ end }}
+{{- if .Empty }}
+<p>This repository is empty. Clone it with <code>git clone {{ .CloneURL }}</code></p>
+{{- else }}
+<p class="muted">
+    {{- if .Path }}<a href="{{ treeURL .Repo .Ref "" }}">{{ .Repo }}</a> / {{ .Path }}{{ else }}Clone with <code>git clone {{ .CloneURL }}</code>{{ end -}}
+</p>
+<table>
+    {{- if .Path }}
+    <tr><td><a href="{{ treeURL .Repo .Ref .Parent }}">..</a></td><td></td></tr>
+    {{- end }}
+    {{- range .Entries }}
+    <tr>
+        {{- if .Dir }}
+        <td><a href="{{ treeURL $.Repo $.Ref .Path }}">{{ .Name }}/</a></td><td></td>
+        {{- else if .Submodule }}
+        <td>{{ .Name }} <span class="muted">(submodule)</span></td><td></td>
+        {{- else }}
+        <td><a href="{{ blobURL $.Repo $.Ref .Path }}">{{ .Name }}</a></td><td class="right muted">{{ bytes .Size }}</td>
+        {{- end }}
+    </tr>
+    {{- end }}
+</table>
+{{- end }}
+{{ template "footer" . }}
+{{- end }}
+
+{{- define "blob" -}}
+{{ template "header" . }}
+<p class="muted"><a href="{{ treeURL .Repo .Ref "" }}">{{ .Repo }}</a> / {{ .Path }} ({{ bytes .Size }}) <a href="{{ rawURL .Repo .Ref .Path }}">raw</a></p>
+{{- if .Binary }}
+<p>This is a binary file.</p>
+{{- else if .TooLarge }}
+<p>This file is too large to show.</p>
+{{- else }}
+{{ .Code }}
+{{- end }}
+{{ template "footer" . }}
+{{- end }}
+
+{{- define "log" -}}
+{{ template "header" . }}
+<table>
+    {{- range .Commits }}
+    <tr>
+        <td><a href="{{ commitURL $.Repo .ID.String }}"><code>{{ shortHash .ID.String }}</code></a></td>
+        <td>{{ firstLine .Message }}</td>
+        <td class="muted">{{ .Author.Name }}</td>
+        <td class="right muted">{{ ago .Author.When }}</td>
+    </tr>
+    {{- end }}
+</table>
+<p>
+    {{- if gt .Page 1 }}<a href="{{ logURL .Repo .Ref .PrevPage }}">Newer</a>{{ end }}
+    {{- if .More }} <a href="{{ logURL .Repo .Ref .NextPage }}">Older</a>{{ end -}}
+</p>
+{{ template "footer" . }}
+{{- end }}
+
+{{- define "commit" -}}
+{{ template "header" . }}
+<table>
+    <tr><th>Commit</th><td><code>{{ .Commit.ID.String }}</code> <a href="{{ treeURL .Repo .Commit.ID.String "" }}">browse files</a></td></tr>
+    <tr><th>Author</th><td>{{ .Commit.Author.Name }} &lt;{{ .Commit.Author.Email }}&gt; <span class="muted">{{ ago .Commit.Author.When }}</span></td></tr>
+    {{- range .Parents }}
+    <tr><th>Parent</th><td><a href="{{ commitURL $.Repo . }}"><code>{{ . }}</code></a></td></tr>
+    {{- end }}
+</table>
+<p class="message">{{ .Commit.Message }}</p>
+<table>
+    {{- range .Files }}
+    <tr><td>{{ .Name }}</td><td class="right"><span style="color: #1a7f37">+{{ .Additions }}</span> <span style="color: #cf222e">-{{ .Deletions }}</span></td></tr>
+    {{- end }}
+</table>
+{{ .Code }}
+{{ template "footer" . }}
+{{- end }}
+`))
+
+// browsePage is the data common to the HTML interface pages.
+type browsePage struct {
+	ServerName string
+	User       proto.User
+	Repo       string
+	Ref        string
+}
+
+// browseRepo is a repository of the repository list.
+type browseRepo struct {
+	Name        string
+	Description string
+	Private     bool
+	UpdatedAt   time.Time
+}
+
+// browseEntry is a tree entry.
+type browseEntry struct {
+	Name      string
+	Path      string
+	Dir       bool
+	Submodule bool
+	Size      int64
+}
+
+// browseFile is a file changed by a commit.
+type browseFile struct {
+	Name      string
+	Additions int
+	Deletions int
+}
+
+// GET /browse
+func browseRepos(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := log.FromContext(ctx)
+	be := backend.FromContext(ctx)
+
+	user, ok := browseUser(w, r)
+	if !ok {
+		return
+	}
+
+	// Let browsers ask for credentials.
+	if user == nil && r.URL.Query().Get("login") != "" {
+		browseUnauthorized(w, r)
+		return
+	}
+
+	repos, err := be.Repositories(ctx)
+	if err != nil {
+		logger.Error("failed to list repositories", "err", err)
+		renderInternalServerError(w, r)
+		return
+	}
+
+	list := make([]browseRepo, 0, len(repos))
+	for _, repo := range repos {
+		if repo.IsHidden() || be.AccessLevelForUser(ctx, repo.Name(), user) < access.ReadOnlyAccess {
+			continue
+		}
+
+		list = append(list, browseRepo{
+			Name:        repo.Name(),
+			Description: repo.Description(),
+			Private:     repo.IsPrivate(),
+			UpdatedAt:   repo.UpdatedAt(),
+		})
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name < list[j].Name
+	})
+
+	renderBrowse(w, r, "repos", struct {
+		browsePage
+		Repos []browseRepo
+	}{
+		browsePage: newBrowsePage(r, user, "", ""),
+		Repos:      list,
+	})
+}
+
+// GET /browse/{repo} and /browse/{repo}/-/tree?ref={ref}&path={path}
+func browseTree(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := log.FromContext(ctx)
+	cfg := config.FromContext(ctx)
+
+	repo, rr, user, ok := browseOpen(w, r)
+	if !ok {
+		return
+	}
+
+	denied, ok := browseDeniedPaths(w, r, repo.Name())
+	if !ok {
+		return
+	}
+
+	p := cleanBrowsePath(r.URL.Query().Get("path"))
+	if p != "" && backend.IsDeniedPath(denied, p) {
+		renderNotFound(w, r)
+		return
+	}
+
+	data := struct {
+		browsePage
+		Description string
+		CloneURL    string
+		Empty       bool
+		Path        string
+		Parent      string
+		Entries     []browseEntry
+	}{
+		browsePage:  newBrowsePage(r, user, repo.Name(), r.URL.Query().Get("ref")),
+		Description: repo.Description(),
+		CloneURL:    strings.TrimSuffix(cfg.HTTP.PublicURL, "/") + "/" + repo.Name() + ".git",
+		Path:        p,
+		Parent:      cleanBrowsePath(path.Dir(p)),
+	}
+
+	sha, ok := browseResolve(w, r, repo.Name(), rr, data.Ref)
+	if !ok {
+		return
+	}
+	if sha == "" {
+		data.Empty = true
+		renderBrowse(w, r, "tree", data)
+		return
+	}
+
+	tree, err := rr.LsTree(sha)
+	if err == nil && p != "" {
+		tree, err = tree.SubTree(p)
+	}
+	if err != nil {
+		renderNotFound(w, r)
+		return
+	}
+
+	entries, err := tree.Entries()
+	if err != nil {
+		logger.Error("failed to list tree entries", "repo", repo.Name(), "path", p, "err", err)
+		renderInternalServerError(w, r)
+		return
+	}
+	entries.Sort()
+
+	for _, e := range entries {
+		if backend.IsDeniedPath(denied, path.Join(p, e.Name())) {
+			continue
+		}
+
+		entry := browseEntry{
+			Name:      e.Name(),
+			Path:      path.Join(p, e.Name()),
+			Dir:       e.IsTree(),
+			Submodule: e.IsCommit(),
+		}
+		if !entry.Dir && !entry.Submodule {
+			entry.Size = e.Size()
+		}
+		data.Entries = append(data.Entries, entry)
+	}
+
+	renderBrowse(w, r, "tree", data)
+}
+
+// GET /browse/{repo}/-/blob?ref={ref}&path={path}
+func browseBlob(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := log.FromContext(ctx)
+
+	repo, rr, user, ok := browseOpen(w, r)
+	if !ok {
+		return
+	}
+
+	data := struct {
+		browsePage
+		Path     string
+		Size     int64
+		Binary   bool
+		TooLarge bool
+		Code     template.HTML
+	}{
+		browsePage: newBrowsePage(r, user, repo.Name(), r.URL.Query().Get("ref")),
+		Path:       cleanBrowsePath(r.URL.Query().Get("path")),
+	}
+
+	sha, ok := browseResolve(w, r, repo.Name(), rr, data.Ref)
+	if !ok {
+		return
+	}
+
+	tree, err := rr.LsTree(sha)
+	if sha == "" || data.Path == "" || err != nil {
+		renderNotFound(w, r)
+		return
+	}
+
+	denied, ok := browseDeniedPaths(w, r, repo.Name())
+	if !ok {
+		return
+	}
+
+	entry, err := tree.TreeEntry(data.Path)
+	if err != nil || !entry.IsBlob() || backend.IsDeniedPath(denied, data.Path) {
+		renderNotFound(w, r)
+		return
+	}
+
+	data.Size = entry.Size()
+	data.TooLarge = data.Size > browseMaxFileSize
+	if !data.TooLarge {
+		content, err := entry.Contents()
+		if err != nil {
+			logger.Error("failed to read file", "repo", repo.Name(), "path", data.Path, "err", err)
+			renderInternalServerError(w, r)
+			return
+		}
+
+		data.Binary, _ = git.IsBinary(bytes.NewReader(content))
+		if !data.Binary {
+			lexer := lexers.Match(data.Path)
+			if lexer == nil {
+				lexer = lexers.Analyse(string(content))
+			}
+			data.Code = highlight(logger, lexer, string(content), true)
+		}
+	}
+
+	renderBrowse(w, r, "blob", data)
+}
+
+// GET /browse/{repo}/-/log?ref={ref}&page={page}
+func browseLog(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := log.FromContext(ctx)
+
+	repo, rr, user, ok := browseOpen(w, r)
+	if !ok {
+		return
+	}
+
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	data := struct {
+		browsePage
+		Commits  git.Commits
+		Page     int
+		PrevPage int
+		NextPage int
+		More     bool
+	}{
+		browsePage: newBrowsePage(r, user, repo.Name(), r.URL.Query().Get("ref")),
+		Page:       page,
+		PrevPage:   page - 1,
+		NextPage:   page + 1,
+	}
+
+	sha, ok := browseResolve(w, r, repo.Name(), rr, data.Ref)
+	if !ok {
+		return
+	}
+
+	if sha != "" {
+		// Ask for one more commit to know whether there's a next page.
+		commits, err := rr.Repository.CommitsByPage(sha, page, browseCommitsPerPage+1)
+		if err != nil {
+			logger.Error("failed to list commits", "repo", repo.Name(), "err", err)
+			renderInternalServerError(w, r)
+			return
+		}
+
+		data.More = len(commits) > browseCommitsPerPage
+		if data.More {
+			commits = commits[:browseCommitsPerPage]
+		}
+		data.Commits = commits
+	}
+
+	renderBrowse(w, r, "log", data)
+}
+
+// GET /browse/{repo}/-/commit/{rev}
+func browseCommit(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := log.FromContext(ctx)
+
+	repo, rr, user, ok := browseOpen(w, r)
+	if !ok {
+		return
+	}
+
+	sha, ok := browseResolve(w, r, repo.Name(), rr, mux.Vars(r)["rev"])
+	if !ok {
+		return
+	}
+
+	commit, err := rr.CommitByRevision(sha)
+	if sha == "" || err != nil {
+		renderNotFound(w, r)
+		return
+	}
+
+	diff, err := rr.Diff(commit)
+	if err != nil {
+		logger.Error("failed to get commit diff", "repo", repo.Name(), "commit", sha, "err", err)
+		renderInternalServerError(w, r)
+		return
+	}
+
+	// Commits no ref points to anymore can still touch a denied path.
+	denied, ok := browseDeniedPaths(w, r, repo.Name())
+	if !ok {
+		return
+	}
+	for _, f := range diff.Files {
+		if backend.IsDeniedPath(denied, f.Name) || backend.IsDeniedPath(denied, f.OldName()) {
+			renderError(w, r, http.StatusForbidden, "commit touches a denied path")
+			return
+		}
+	}
+
+	data := struct {
+		browsePage
+		Commit  *git.Commit
+		Parents []string
+		Files   []browseFile
+		Code    template.HTML
+	}{
+		browsePage: newBrowsePage(r, user, repo.Name(), ""),
+		Commit:     commit,
+		Code:       highlight(logger, lexers.Get("diff"), diff.Patch(), false),
+	}
+	for i := 0; i < commit.ParentsCount(); i++ {
+		if id, err := commit.ParentID(i); err == nil {
+			data.Parents = append(data.Parents, id.String())
+		}
+	}
+	for _, f := range diff.Files {
+		data.Files = append(data.Files, browseFile{
+			Name:      f.Name,
+			Additions: f.NumAdditions(),
+			Deletions: f.NumDeletions(),
+		})
+	}
+
+	renderBrowse(w, r, "commit", data)
+}
+
+// browseUser authenticates a request of the HTML interface. Invalid
+// credentials ask the browser for new ones, and no credentials is an
+// anonymous user.
+func browseUser(w http.ResponseWriter, r *http.Request) (proto.User, bool) {
+	user, err := authenticate(r)
+	if err != nil && !errors.Is(err, proto.ErrUserNotFound) {
+		browseUnauthorized(w, r)
+		return nil, false
+	}
+
+	return user, true
+}
+
+// browseOpen authorizes a request of the HTML interface to read the
+// repository of the request, and opens it. Anonymous users are asked for
+// credentials, since the repository may be private.
+func browseOpen(w http.ResponseWriter, r *http.Request) (proto.Repository, *git.Repository, proto.User, bool) {
+	ctx := r.Context()
+	logger := log.FromContext(ctx)
+	be := backend.FromContext(ctx)
+	name := utils.SanitizeRepo(mux.Vars(r)["repo"])
+
+	user, ok := browseUser(w, r)
+	if !ok {
+		return nil, nil, nil, false
+	}
+
+	if be.AccessLevelForUser(ctx, name, user) < access.ReadOnlyAccess {
+		if user == nil {
+			browseUnauthorized(w, r)
+		} else {
+			renderNotFound(w, r)
+		}
+		return nil, nil, nil, false
+	}
+
+	repo, err := be.Repository(ctx, name)
+	if err != nil {
+		renderNotFound(w, r)
+		return nil, nil, nil, false
+	}
+
+	// The browser reads repositories over HTTP, like clones, and serves
+	// their history.
+	err = be.CheckProtocol(ctx, name, backend.ProtocolHTTP, false)
+	if err == nil {
+		err = be.CheckDeniedPaths(ctx, name)
+	}
+	switch {
+	case err == nil:
+	case errors.Is(err, proto.ErrProtocolNotAllowed), errors.Is(err, proto.ErrDeniedPath):
+		renderError(w, r, http.StatusForbidden, err.Error())
+		return nil, nil, nil, false
+	default:
+		logger.Error("failed to check repository access", "repo", name, "err", err)
+		renderInternalServerError(w, r)
+		return nil, nil, nil, false
+	}
+
+	rr, err := repo.Open()
+	if err != nil {
+		logger.Error("failed to open repository", "repo", name, "err", err)
+		renderInternalServerError(w, r)
+		return nil, nil, nil, false
+	}
+
+	return repo, rr, user, true
+}
+
+// browseDeniedPaths returns the denied paths of a repository, which the
+// browser never shows, even from commits no ref points to anymore.
+func browseDeniedPaths(w http.ResponseWriter, r *http.Request, repo string) ([]string, bool) {
+	ctx := r.Context()
+	denied, err := backend.FromContext(ctx).DeniedPaths(ctx, repo)
+	if err != nil {
+		log.FromContext(ctx).Error("failed to get denied paths", "repo", repo, "err", err)
+		renderInternalServerError(w, r)
+		return nil, false
+	}
+
+	return denied, true
+}
+
+// browseResolve resolves a revision to a commit hash. An empty revision is
+// the default branch, and resolves to an empty hash in an empty repository.
+func browseResolve(w http.ResponseWriter, r *http.Request, repo string, rr *git.Repository, rev string) (string, bool) {
+	if rev == "" {
+		head, err := rr.HEAD()
+		if err != nil {
+			return "", true
+		}
+		rev = head.ID
+	}
+
+	sha, err := backend.FromContext(r.Context()).ResolveCommit(r.Context(), repo, rev)
+	if err != nil {
+		renderNotFound(w, r)
+		return "", false
+	}
+
+	return sha, true
+}
+
+// browseUnauthorized asks the browser for credentials.
+func browseUnauthorized(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("WWW-Authenticate", `Basic realm="Soft Serve" charset="UTF-8"`)
+	renderUnauthorized(w, r)
+}
+
+func newBrowsePage(r *http.Request, user proto.User, repo string, ref string) browsePage {
+	return browsePage{
+		ServerName: config.FromContext(r.Context()).Name,
+		User:       user,
+		Repo:       repo,
+		Ref:        ref,
+	}
+}
+
+// renderBrowse renders a page of the HTML interface.
+func renderBrowse(w http.ResponseWriter, r *http.Request, name string, data interface{}) {
+	var buf bytes.Buffer
+	if err := browseTpl.ExecuteTemplate(&buf, name, data); err != nil {
+		log.FromContext(r.Context()).Error("failed to render page", "page", name, "err", err)
+		renderInternalServerError(w, r)
+		return
+	}
+
+	// Pages only have inline styles, and never run scripts.
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Content-Security-Policy", "default-src 'none'; style-src 'unsafe-inline'")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(http.StatusOK)
+	if r.Method != http.MethodHead {
+		w.Write(buf.Bytes()) // nolint: errcheck
+	}
+}
+
+// highlight renders source code as syntax highlighted HTML.
+func highlight(logger *log.Logger, lexer chroma.Lexer, src string, lineNumbers bool) template.HTML {
+	if lexer == nil {
+		lexer = lexers.Fallback
+	}
+
+	it, err := chroma.Coalesce(lexer).Tokenise(nil, src)
+	if err == nil {
+		var buf bytes.Buffer
+		f := chtml.New(chtml.WithLineNumbers(lineNumbers), chtml.WithLinkableLineNumbers(lineNumbers, "L"))
+		if err = f.Format(&buf, browseStyle, it); err == nil {
+			return template.HTML(buf.String()) // nolint: gosec
+		}
+	}
+
+	logger.Error("failed to highlight code", "err", err)
+	return template.HTML("<pre>" + template.HTMLEscapeString(src) + "</pre>") // nolint: gosec
+}
+
+// cleanBrowsePath cleans a repository path, the root is empty.
+func cleanBrowsePath(p string) string {
+	p = strings.Trim(path.Clean("/"+p), "/")
+	if p == "." {
+		return ""
+	}
+
+	return p
+}
+
+// escapeRepo escapes a repository name for a URL path, keeping its slashes.
+func escapeRepo(repo string) string {
+	parts := strings.Split(repo, "/")
+	for i, p := range parts {
+		parts[i] = url.PathEscape(p)
+	}
+
+	return strings.Join(parts, "/")
+}
+
+func browseQueryURL(repo string, page string, q url.Values) string {
+	u := browsePrefix + "/" + escapeRepo(repo) + "/-/" + page
+	for k, v := range q {
+		if len(v) == 0 || v[0] == "" {
+			delete(q, k)
+		}
+	}
+	if len(q) > 0 {
+		u += "?" + q.Encode()
+	}
+
+	return u
+}
+
+func browseTreeURL(repo string, ref string, p string) string {
+	return browseQueryURL(repo, "tree", url.Values{"ref": {ref}, "path": {p}})
+}
+
+func browseBlobURL(repo string, ref string, p string) string {
+	return browseQueryURL(repo, "blob", url.Values{"ref": {ref}, "path": {p}})
+}
+
+func browseLogURL(repo string, ref string, page int) string {
+	q := url.Values{"ref": {ref}}
+	if page > 1 {
+		q.Set("page", strconv.Itoa(page))
+	}
+
+	return browseQueryURL(repo, "log", q)
+}
+
+func browseCommitURL(repo string, rev string) string {
+	return browsePrefix + "/" + escapeRepo(repo) + "/-/commit/" + url.PathEscape(rev)
+}
+
+// browseRawURL returns the raw file API URL of a file. The ref defaults to
+// HEAD.
+func browseRawURL(repo string, ref string, p string) string {
+	if ref == "" {
+		ref = "HEAD"
+	}
+
+	return "/api/repos/" + escapeRepo(repo) + "/raw/" + escapeRepo(ref) + "/" + escapeRepo(p)
+}
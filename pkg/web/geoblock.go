@@ -0,0 +1,28 @@
+package web
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/charmbracelet/soft-serve/pkg/geoblock"
+)
+
+// NewGeoblockHandler returns a new middleware denying requests from clients
+// in denied regions.
+func NewGeoblockHandler(ctx context.Context) func(http.Handler) http.Handler {
+	geo := geoblock.FromContext(ctx)
+	return func(next http.Handler) http.Handler {
+		if geo == nil {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !geo.Allow(r.Context(), r.RemoteAddr, "http") {
+				renderForbidden(w, r)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -62,7 +62,7 @@ var (
 		Subsystem: "http",
 		Name:      "git_receive_pack_total",
 		Help:      "The total number of git push requests",
-	}, []string{"repo"})
+	}, []string{"repo", "anonymous"})
 
 	//nolint:revive
 	gitHttpUploadCounter = promauto.NewCounterVec(prometheus.CounterOpts{
@@ -70,7 +70,7 @@ var (
 		Subsystem: "http",
 		Name:      "git_upload_pack_total",
 		Help:      "The total number of git fetch/pull requests",
-	}, []string{"repo", "file"})
+	}, []string{"repo", "file", "anonymous"})
 )
 
 func withParams(next http.Handler) http.Handler {
@@ -92,6 +92,10 @@ func withParams(next http.Handler) http.Handler {
 		}
 
 		repo = utils.SanitizeRepo(repo)
+		// Aliases are served their canonical repository.
+		if alias, ok := backend.FromContext(ctx).ResolveRepoAlias(ctx, repo); ok {
+			repo = alias.RepoName
+		}
 		vars["repo"] = repo
 		vars["dir"] = filepath.Join(cfg.DataPath, "repos", repo+".git")
 
@@ -144,17 +148,17 @@ var gitRoutes = []GitRoute{
 	{
 		method:  []string{http.MethodGet},
 		handler: getLooseObject,
-		path:    "/objects/{_:[0-9a-f]{2}/[0-9a-f]{38}$}",
+		path:    "/objects/{_:[0-9a-f]{2}/(?:[0-9a-f]{38}|[0-9a-f]{62})$}",
 	},
 	{
 		method:  []string{http.MethodGet},
 		handler: getPackFile,
-		path:    "/objects/pack/{_:pack-[0-9a-f]{40}\\.pack$}",
+		path:    "/objects/pack/{_:pack-(?:[0-9a-f]{40}|[0-9a-f]{64})\\.pack$}",
 	},
 	{
 		method:  []string{http.MethodGet},
 		handler: getIdxFile,
-		path:    "/objects/pack/{_:pack-[0-9a-f]{40}\\.idx$}",
+		path:    "/objects/pack/{_:pack-(?:[0-9a-f]{40}|[0-9a-f]{64})\\.idx$}",
 	},
 	// Git LFS
 	{
@@ -191,6 +195,51 @@ var gitRoutes = []GitRoute{
 	},
 }
 
+// redirectToPrimary redirects a push to a replica to the primary server. Git
+// follows the redirect of the initial ref advertisement request and sends the
+// push to the primary.
+func redirectToPrimary(w http.ResponseWriter, r *http.Request) {
+	cfg := config.FromContext(r.Context())
+	if cfg.Replication.PrimaryHTTPURL == "" {
+		renderError(w, r, http.StatusForbidden, proto.ErrReadOnlyReplica.Error())
+		return
+	}
+
+	url := strings.TrimSuffix(cfg.Replication.PrimaryHTTPURL, "/") + "/" +
+		strings.TrimPrefix(r.URL.RequestURI(), "/")
+	http.Redirect(w, r, url, http.StatusTemporaryRedirect)
+}
+
+// checkProtocol writes an error and returns false if the repository can't be
+// pushed to, or read when push is false, over HTTP.
+func checkProtocol(w http.ResponseWriter, r *http.Request, repo string, push bool) bool {
+	ctx := r.Context()
+	err := backend.FromContext(ctx).CheckProtocol(ctx, repo, backend.ProtocolHTTP, push)
+	if errors.Is(err, proto.ErrProtocolNotAllowed) {
+		renderError(w, r, http.StatusForbidden, err.Error())
+		return false
+	} else if err != nil {
+		log.FromContext(ctx).Error("failed to check protocol", "err", err, "repo", repo)
+		renderInternalServerError(w, r)
+		return false
+	}
+
+	return true
+}
+
+// redirectToRenamed permanently redirects a request for the old name of a
+// renamed repository to its new name. Git follows the redirect of the initial
+// ref advertisement request, warns about it, and sends the rest of the
+// requests to the new name.
+func redirectToRenamed(w http.ResponseWriter, r *http.Request, newName string) {
+	cfg := config.FromContext(r.Context())
+	url := strings.TrimSuffix(cfg.HTTP.PublicURL, "/") + "/" + newName + ".git/" + mux.Vars(r)["file"]
+	if r.URL.RawQuery != "" {
+		url += "?" + r.URL.RawQuery
+	}
+	http.Redirect(w, r, url, http.StatusMovedPermanently)
+}
+
 func askCredentials(w http.ResponseWriter, _ *http.Request) {
 	w.Header().Set("WWW-Authenticate", `Basic realm="Git" charset="UTF-8", Token, Bearer`)
 	w.Header().Set("LFS-Authenticate", `Basic realm="Git LFS" charset="UTF-8", Token, Bearer`)
@@ -212,17 +261,35 @@ func withAccess(next http.Handler) http.HandlerFunc {
 		ctx = proto.WithRepositoryContext(ctx, repo)
 		r = r.WithContext(ctx)
 
-		user, err := authenticate(r)
-		if err != nil {
+		// Deploy tokens grant access to their repository only, without a
+		// user.
+		var user proto.User
+		var err error
+		deployLevel := access.NoAccess
+		token, isDeploy := deployToken(r)
+		if isDeploy {
+			deployLevel, err = be.DeployTokenAccessLevel(ctx, repoName, token)
 			switch {
-			case errors.Is(err, ErrInvalidToken):
-			case errors.Is(err, proto.ErrUserNotFound):
-			default:
-				logger.Error("failed to authenticate", "err", err)
+			case errors.Is(err, proto.ErrTokenNotFound), errors.Is(err, proto.ErrTokenExpired):
+				logger.Debug("invalid deploy token", "repo", repoName, "err", err)
+				err = ErrInvalidToken
+			case err != nil:
+				logger.Error("failed to authenticate deploy token", "repo", repoName, "err", err)
+				deployLevel = access.NoAccess
+			}
+		} else {
+			user, err = authenticate(r)
+			if err != nil {
+				switch {
+				case errors.Is(err, ErrInvalidToken):
+				case errors.Is(err, proto.ErrUserNotFound):
+				default:
+					logger.Error("failed to authenticate", "err", err)
+				}
 			}
 		}
 
-		if user == nil && !be.AllowKeyless(ctx) {
+		if user == nil && !isDeploy && !be.AllowKeyless(ctx) {
 			askCredentials(w, r)
 			renderUnauthorized(w, r)
 			return
@@ -243,9 +310,21 @@ func withAccess(next http.Handler) http.HandlerFunc {
 		}
 
 		accessLevel := be.AccessLevelForUser(ctx, repoName, user)
+		if isDeploy {
+			accessLevel = deployLevel
+		}
 		ctx = access.WithContext(ctx, accessLevel)
 		r = r.WithContext(ctx)
 
+		// Clients using the old name of a renamed repository are redirected
+		// to the new one, instead of creating a new repository by pushing.
+		if repo == nil {
+			if newName, ok := be.RenamedRepository(ctx, repoName); ok && be.AccessLevelForUser(ctx, newName, user) >= access.ReadOnlyAccess {
+				redirectToRenamed(w, r, newName)
+				return
+			}
+		}
+
 		file := mux.Vars(r)["file"]
 
 		// We only allow these services to proceed any other services should return 403
@@ -260,10 +339,25 @@ func withAccess(next http.Handler) http.HandlerFunc {
 				return
 			}
 
+			if cfg.Replication.IsReplica() {
+				redirectToPrimary(w, r)
+				return
+			}
+
+			if !checkProtocol(w, r, repoName, true) {
+				return
+			}
+
 			// Create the repo if it doesn't exist.
 			if repo == nil {
 				repo, err = be.CreateRepository(ctx, repoName, user, proto.RepositoryOptions{})
-				if err != nil {
+				if errors.Is(err, proto.ErrRepoCreateLimit) {
+					renderError(w, r, http.StatusTooManyRequests, err.Error())
+					return
+				} else if errors.Is(err, proto.ErrQuotaExceeded) {
+					renderError(w, r, http.StatusInsufficientStorage, err.Error())
+					return
+				} else if err != nil {
 					logger.Error("failed to create repository", "repo", repoName, "err", err)
 					renderInternalServerError(w, r)
 					return
@@ -289,6 +383,20 @@ func withAccess(next http.Handler) http.HandlerFunc {
 				return
 			}
 
+			// Pushes fall through here and were checked above.
+			if service == git.UploadPackService && !checkProtocol(w, r, repoName, false) {
+				return
+			}
+
+			if err := be.CheckDeniedPaths(ctx, repoName); errors.Is(err, proto.ErrDeniedPath) {
+				renderError(w, r, http.StatusForbidden, err.Error())
+				return
+			} else if err != nil {
+				logger.Error("failed to check denied paths", "err", err, "repo", repoName)
+				renderInternalServerError(w, r)
+				return
+			}
+
 		case strings.HasPrefix(file, "info/lfs"):
 			if !cfg.LFS.Enabled {
 				logger.Debug("LFS is not enabled, skipping")
@@ -322,6 +430,12 @@ func withAccess(next http.Handler) http.HandlerFunc {
 						})
 						return
 					}
+					if err := be.CheckProtocol(ctx, repoName, backend.ProtocolHTTP, true); errors.Is(err, proto.ErrProtocolNotAllowed) {
+						renderJSON(w, http.StatusForbidden, lfs.ErrorResponse{
+							Message: err.Error(),
+						})
+						return
+					}
 				case http.MethodGet:
 					// Basic download
 				case http.MethodPost:
@@ -379,7 +493,28 @@ func serviceRpc(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if service == git.ReceivePackService {
-		gitHttpReceiveCounter.WithLabelValues(repoName)
+		anonymous := cfg.Stats.AnonymousLabel(proto.UserFromContext(ctx) == nil)
+		gitHttpReceiveCounter.WithLabelValues(repoName, anonymous).Inc()
+	}
+
+	if service == git.UploadPackService {
+		release, err := backend.FromContext(ctx).AcquireUploadPack(ctx, r.RemoteAddr)
+		if err != nil {
+			w.Header().Set("Retry-After", "10")
+			renderError(w, r, http.StatusTooManyRequests, err.Error())
+			return
+		}
+		defer release()
+	}
+
+	if service == git.ReceivePackService {
+		unlock, err := backend.FromContext(ctx).LockRepositoryForPush(ctx, repoName)
+		if err != nil {
+			w.Header().Set("Retry-After", "10")
+			renderError(w, r, http.StatusServiceUnavailable, err.Error())
+			return
+		}
+		defer unlock()
 	}
 
 	w.Header().Set("Content-Type", fmt.Sprintf("application/x-%s-result", service))
@@ -397,6 +532,7 @@ func serviceRpc(w http.ResponseWriter, r *http.Request) {
 		Args:   []string{"--stateless-rpc"},
 	}
 
+	be := backend.FromContext(ctx)
 	user := proto.UserFromContext(ctx)
 	cmd.Env = cfg.Environ()
 	cmd.Env = append(cmd.Env, []string{
@@ -409,6 +545,13 @@ func serviceRpc(w http.ResponseWriter, r *http.Request) {
 			"SOFT_SERVE_USERNAME=" + user.Username(),
 		}...)
 	}
+	// Requests without a user, like the ones using deploy tokens, have the
+	// access level they were authorized with.
+	level := access.FromContext(ctx)
+	if user != nil {
+		level = be.AccessLevelForUser(ctx, repoName, user)
+	}
+	cmd.Env = append(cmd.Env, be.HookEnv(ctx, repoName, user, level)...)
 	if len(version) != 0 {
 		cmd.Env = append(cmd.Env, []string{
 			fmt.Sprintf("GIT_PROTOCOL=%s", version),
@@ -488,7 +631,8 @@ func getInfoRefs(w http.ResponseWriter, r *http.Request) {
 	service := getServiceType(r)
 	protocol := r.Header.Get("Git-Protocol")
 
-	gitHttpUploadCounter.WithLabelValues(repoName, file).Inc()
+	anonymous := cfg.Stats.AnonymousLabel(proto.UserFromContext(ctx) == nil)
+	gitHttpUploadCounter.WithLabelValues(repoName, file, anonymous).Inc()
 
 	if service != "" && (service == git.UploadPackService || service == git.ReceivePackService) {
 		// Smart HTTP
@@ -536,6 +680,15 @@ func getInfoRefs(w http.ResponseWriter, r *http.Request) {
 			git.WritePktline(w, "# service="+service.String()) // nolint: errcheck
 		}
 		w.Write(refs.Bytes()) // nolint: errcheck
+
+		// Each clone or fetch starts with a single ref advertisement request,
+		// while the following upload-pack requests may be repeated.
+		if service == git.UploadPackService {
+			be := backend.FromContext(ctx)
+			if err := be.RecordClone(ctx, repoName, user, "http"); err != nil {
+				log.FromContext(ctx).Error("failed to record clone", "err", err, "repo", repoName)
+			}
+		}
 	} else {
 		// Dumb HTTP
 		updateServerInfo(ctx, dir) // nolint: errcheck
@@ -187,6 +187,13 @@ func serviceLfsBatch(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
+		if config.FromContext(ctx).Replication.IsReplica() {
+			renderJSON(w, http.StatusForbidden, lfs.ErrorResponse{
+				Message: proto.ErrReadOnlyReplica.Error(),
+			})
+			return
+		}
+
 		// Object upload logic happens in the "basic" API route
 		for _, o := range batchRequest.Objects {
 			if !o.IsValid() {
@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"net/url"
 	"path"
+	"strings"
 	"text/template"
 
 	"github.com/charmbracelet/log"
@@ -91,5 +92,8 @@ func GoGetHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	renderNotFound(w, r)
+	// There are no web pages for repositories, but tell whoever pasted a
+	// clone URL in their browser how to use it.
+	cloneURL := strings.TrimSuffix(cfg.HTTP.PublicURL, "/") + "/" + utils.SanitizeRepo(repo) + ".git"
+	renderError(w, r, http.StatusNotFound, "This is a Git repository, clone it with: git clone "+cloneURL)
 }
@@ -2,11 +2,13 @@ package web
 
 import (
 	"context"
+	"net"
 	"net/http"
 	"time"
 
 	"github.com/charmbracelet/log"
 	"github.com/charmbracelet/soft-serve/pkg/config"
+	"github.com/charmbracelet/soft-serve/pkg/proxyproto"
 )
 
 // HTTPServer is an http server.
@@ -44,7 +46,26 @@ func (s *HTTPServer) Close() error {
 
 // ListenAndServe starts the HTTP server.
 func (s *HTTPServer) ListenAndServe() error {
-	if s.cfg.HTTP.TLSKeyPath != "" && s.cfg.HTTP.TLSCertPath != "" {
+	tls := s.cfg.HTTP.TLSKeyPath != "" && s.cfg.HTTP.TLSCertPath != ""
+	if s.cfg.HTTP.ProxyProtocol {
+		trusted, err := config.ParseIPNets(s.cfg.HTTP.TrustedProxies)
+		if err != nil {
+			return err
+		}
+
+		l, err := net.Listen("tcp", s.Server.Addr)
+		if err != nil {
+			return err
+		}
+
+		pl := proxyproto.NewListener(l, trusted)
+		if tls {
+			return s.Server.ServeTLS(pl, s.cfg.HTTP.TLSCertPath, s.cfg.HTTP.TLSKeyPath)
+		}
+		return s.Server.Serve(pl)
+	}
+
+	if tls {
 		return s.Server.ListenAndServeTLS(s.cfg.HTTP.TLSCertPath, s.cfg.HTTP.TLSKeyPath)
 	}
 	return s.Server.ListenAndServe()
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/charmbracelet/log"
+	logr "github.com/charmbracelet/soft-serve/pkg/log"
 	"github.com/dustin/go-humanize"
 )
 
@@ -68,20 +69,50 @@ func (r *logWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
 	return nil, nil, fmt.Errorf("http.Hijacker not implemented")
 }
 
-// NewLoggingMiddleware returns a new logging middleware.
-func NewLoggingMiddleware(next http.Handler, logger *log.Logger) http.Handler {
+// NewLoggingMiddleware returns a new logging middleware. Successful requests
+// are logged when the sampler keeps them, server errors are always logged.
+// Every request is sent to syslog, if configured.
+func NewLoggingMiddleware(next http.Handler, logger *log.Logger, sampler *logr.Sampler, syslog *logr.Syslog) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
 		writer := &logWriter{code: http.StatusOK, ResponseWriter: w}
-		logger.Debug("request",
-			"method", r.Method,
-			"path", r.URL,
-			"addr", r.RemoteAddr)
+		sampled := !syslog.Only() && sampler.Sample()
+		if sampled {
+			logger.Debug("request",
+				"method", r.Method,
+				"path", r.URL,
+				"addr", r.RemoteAddr)
+		}
 		next.ServeHTTP(writer, r)
 		elapsed := time.Since(start)
-		logger.Debug("response",
+		logArgs := []interface{}{
 			"status", fmt.Sprintf("%d %s", writer.code, http.StatusText(writer.code)),
 			"bytes", humanize.Bytes(uint64(writer.bytes)),
-			"time", elapsed)
+			"time", elapsed,
+		}
+		failed := writer.code >= http.StatusInternalServerError
+		if failed && !sampled {
+			// The request wasn't logged, add its details to the response.
+			logArgs = append(logArgs,
+				"method", r.Method,
+				"path", r.URL,
+				"addr", r.RemoteAddr)
+		}
+		if sampled || (failed && !syslog.Only()) {
+			logger.Debug("response", logArgs...)
+		}
+
+		severity := logr.SeverityInfo
+		if failed {
+			severity = logr.SeverityWarning
+		}
+		syslog.Log(severity, "http", "response",
+			"method", r.Method,
+			"path", r.URL.Path,
+			"addr", r.RemoteAddr,
+			"status", writer.code,
+			"bytes", writer.bytes,
+			"duration", elapsed.Round(time.Millisecond),
+		)
 	})
 }
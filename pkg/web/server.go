@@ -5,6 +5,7 @@ import (
 	"net/http"
 
 	"github.com/charmbracelet/log"
+	logr "github.com/charmbracelet/soft-serve/pkg/log"
 	"github.com/gorilla/handlers"
 	"github.com/gorilla/mux"
 )
@@ -14,6 +15,12 @@ func NewRouter(ctx context.Context) http.Handler {
 	logger := log.FromContext(ctx).WithPrefix("http")
 	router := mux.NewRouter()
 
+	// API routes
+	APIController(ctx, router)
+
+	// HTML interface routes
+	BrowseController(ctx, router)
+
 	// Git routes
 	GitController(ctx, router)
 
@@ -21,8 +28,9 @@ func NewRouter(ctx context.Context) http.Handler {
 
 	// Context handler
 	// Adds context to the request
-	h := NewLoggingMiddleware(router, logger)
+	h := NewLoggingMiddleware(router, logger, logr.SamplerFromContext(ctx), logr.SyslogFromContext(ctx))
 	h = NewContextHandler(ctx)(h)
+	h = NewGeoblockHandler(ctx)(h)
 	h = handlers.CompressHandler(h)
 	h = handlers.RecoveryHandler()(h)
 
@@ -2,13 +2,164 @@ package web
 
 import (
 	"fmt"
+	"html/template"
 	"io"
+	"mime"
 	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/log"
+	"github.com/charmbracelet/soft-serve/pkg/config"
+	"github.com/charmbracelet/soft-serve/pkg/lfs"
+)
+
+// errorFormat is the format of an error response.
+type errorFormat int
+
+const (
+	errorFormatText errorFormat = iota
+	errorFormatHTML
+	errorFormatJSON
 )
 
+// errorPage is the data of the error page template.
+type errorPage struct {
+	Code    int
+	Status  string
+	Message string
+	Help    string
+}
+
+var errorPageTpl = template.Must(template.New("error").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta http-equiv="Content-Type" content="text/html; charset=utf-8"/>
+    <meta name="viewport" content="width=device-width, initial-scale=1"/>
+    <title>{{ .Code }} {{ .Status }}</title>
+    <style>
+        body { font-family: sans-serif; max-width: 40em; margin: 4em auto; padding: 0 1em; color: #333; }
+        h1 { font-size: 1.5em; }
+        code { background: #f3f3f3; padding: 0.1em 0.3em; }
+    </style>
+</head>
+<body>
+    <h1>{{ .Code }} {{ .Status }}</h1>
+    {{- if ne .Message .Status }}
+    <p>{{ .Message }}</p>
+    {{- end }}
+    {{- if .Help }}
+    <p>{{ .Help }}</p>
+    {{- end }}
+</body>
+</html>
+`))
+
 func renderStatus(code int) http.HandlerFunc {
-	return func(w http.ResponseWriter, _ *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		renderError(w, r, code, http.StatusText(code))
+	}
+}
+
+// renderError writes an error response in the format the client accepts,
+// HTML for browsers, JSON for API clients, and plain text for git and
+// everything else. A nil request gets plain text.
+func renderError(w http.ResponseWriter, r *http.Request, code int, msg string) {
+	page := errorPage{Code: code, Status: http.StatusText(code), Message: msg}
+	var format errorFormat
+	if r != nil {
+		if cfg := config.FromContext(r.Context()); cfg != nil {
+			page.Help = cfg.HTTP.ErrorHelp
+		}
+		format = errorFormatFor(r)
+	}
+
+	switch format {
+	case errorFormatJSON:
+		renderAPIJSON(w, code, apiError{Message: msg, Help: page.Help})
+	case errorFormatHTML:
+		tpl := errorPageTemplate(r)
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
 		w.WriteHeader(code)
-		io.WriteString(w, fmt.Sprintf("%d %s", code, http.StatusText(code))) // nolint: errcheck
+		if err := tpl.Execute(w, page); err != nil {
+			log.FromContext(r.Context()).Error("failed to render error page", "err", err)
+		}
+	default:
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		w.WriteHeader(code)
+		body := fmt.Sprintf("%d %s", code, msg)
+		if page.Help != "" {
+			body += "\n" + page.Help
+		}
+		io.WriteString(w, body) // nolint: errcheck
+	}
+}
+
+// errorPageTemplate returns the configured error page template, or the
+// built-in one if there's none or it's invalid.
+func errorPageTemplate(r *http.Request) *template.Template {
+	cfg := config.FromContext(r.Context())
+	if cfg == nil || cfg.HTTP.ErrorPage == "" {
+		return errorPageTpl
+	}
+
+	tpl, err := template.ParseFiles(cfg.HTTP.ErrorPage)
+	if err != nil {
+		log.FromContext(r.Context()).Error("failed to parse error page template", "path", cfg.HTTP.ErrorPage, "err", err)
+		return errorPageTpl
+	}
+
+	return tpl
+}
+
+// errorFormatFor returns the error response format for a request. Git and
+// Git LFS clients always get plain text they can parse, API requests get
+// JSON, and other clients get the format they prefer in their Accept header.
+func errorFormatFor(r *http.Request) errorFormat {
+	ua := r.UserAgent()
+	if strings.HasPrefix(ua, "git/") || strings.HasPrefix(ua, "git-lfs/") ||
+		strings.HasPrefix(ua, "JGit/") || r.Header.Get("Git-Protocol") != "" ||
+		strings.HasPrefix(r.Header.Get("Accept"), lfs.MediaType) {
+		return errorFormatText
 	}
+
+	if strings.HasPrefix(r.URL.Path, "/api/") {
+		return errorFormatJSON
+	}
+
+	format, best := errorFormatText, 0.0
+	for _, a := range strings.Split(r.Header.Get("Accept"), ",") {
+		mt, params, err := mime.ParseMediaType(strings.TrimSpace(a))
+		if err != nil {
+			continue
+		}
+
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			q, err = strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+		}
+
+		var f errorFormat
+		switch mt {
+		case "text/html", "application/xhtml+xml":
+			f = errorFormatHTML
+		case "application/json":
+			f = errorFormatJSON
+		case "text/plain":
+			f = errorFormatText
+		default:
+			continue
+		}
+
+		// The first of the formats with the same quality wins.
+		if q > best {
+			format, best = f, q
+		}
+	}
+
+	return format
 }
@@ -55,13 +55,18 @@ func NewBranchTagEvent(ctx context.Context, user proto.User, repo proto.Reposito
 				CreatedAt:   repo.CreatedAt(),
 				UpdatedAt:   repo.UpdatedAt(),
 			},
-			Sender: User{
-				ID:       user.ID(),
-				Username: user.Username(),
-			},
 		},
 	}
 
+	// Pushes with keys that aren't registered to a user, like deploy keys,
+	// have no sender.
+	if user != nil {
+		payload.Sender = User{
+			ID:       user.ID(),
+			Username: user.Username(),
+		}
+	}
+
 	cfg := config.FromContext(ctx)
 	payload.Repository.HTTPURL = repoURL(cfg.HTTP.PublicURL, repo.Name())
 	payload.Repository.SSHURL = repoURL(cfg.SSH.PublicURL, repo.Name())
@@ -26,9 +26,16 @@ const (
 
 	// EventRepositoryVisibilityChange is a repository visibility change event.
 	EventRepositoryVisibilityChange Event = 6
+
+	// EventHookError is a git hook that failed to run or timed out.
+	EventHookError Event = 7
+
+	// EventPing is a test event sent on demand to check a webhook works.
+	// Webhooks don't subscribe to it.
+	EventPing Event = 8
 )
 
-// Events return all events.
+// Events return all events webhooks can subscribe to.
 func Events() []Event {
 	return []Event{
 		EventBranchTagCreate,
@@ -37,6 +44,7 @@ func Events() []Event {
 		EventPush,
 		EventRepository,
 		EventRepositoryVisibilityChange,
+		EventHookError,
 	}
 }
 
@@ -47,6 +55,8 @@ var eventStrings = map[Event]string{
 	EventPush:                       "push",
 	EventRepository:                 "repository",
 	EventRepositoryVisibilityChange: "repository_visibility_change",
+	EventHookError:                  "hook_error",
+	EventPing:                       "ping",
 }
 
 // String returns the string representation of the event.
@@ -61,6 +71,8 @@ var stringEvent = map[string]Event{
 	"push":                         EventPush,
 	"repository":                   EventRepository,
 	"repository_visibility_change": EventRepositoryVisibilityChange,
+	"hook_error":                   EventHookError,
+	"ping":                         EventPing,
 }
 
 // ErrInvalidEvent is returned when the event is invalid.
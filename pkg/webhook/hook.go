@@ -0,0 +1,71 @@
+package webhook
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/pkg/config"
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/proto"
+	"github.com/charmbracelet/soft-serve/pkg/store"
+)
+
+// HookErrorEvent is a git hook error event. It's sent when a hook fails to
+// run or times out, not when it rejects a push on purpose.
+type HookErrorEvent struct {
+	Common
+
+	// Hook is the name of the hook, e.g. "pre-receive".
+	Hook string `json:"hook" url:"hook"`
+	// Error is the error of the hook.
+	Error string `json:"error" url:"error"`
+	// TimedOut is whether the hook was killed for running too long.
+	TimedOut bool `json:"timed_out" url:"timed_out"`
+}
+
+// NewHookErrorEvent returns a new hook error event. The user is the user who
+// pushed, if any.
+func NewHookErrorEvent(ctx context.Context, user proto.User, repo proto.Repository, hook string, hookErr string, timedOut bool) (HookErrorEvent, error) {
+	payload := HookErrorEvent{
+		Hook:     hook,
+		Error:    hookErr,
+		TimedOut: timedOut,
+		Common: Common{
+			EventType: EventHookError,
+			Repository: Repository{
+				ID:          repo.ID(),
+				Name:        repo.Name(),
+				Description: repo.Description(),
+				ProjectName: repo.ProjectName(),
+				Private:     repo.IsPrivate(),
+				CreatedAt:   repo.CreatedAt(),
+				UpdatedAt:   repo.UpdatedAt(),
+			},
+		},
+	}
+
+	if user != nil {
+		payload.Sender = User{
+			ID:       user.ID(),
+			Username: user.Username(),
+		}
+	}
+
+	cfg := config.FromContext(ctx)
+	payload.Repository.HTTPURL = repoURL(cfg.HTTP.PublicURL, repo.Name())
+	payload.Repository.SSHURL = repoURL(cfg.SSH.PublicURL, repo.Name())
+	payload.Repository.GitURL = repoURL(cfg.Git.PublicURL, repo.Name())
+
+	// Find repo owner.
+	dbx := db.FromContext(ctx)
+	datastore := store.FromContext(ctx)
+	owner, err := datastore.GetUserByID(ctx, dbx, repo.UserID())
+	if err != nil {
+		return HookErrorEvent{}, db.WrapError(err)
+	}
+
+	payload.Repository.Owner.ID = owner.ID
+	payload.Repository.Owner.Username = owner.Username
+	payload.Repository.DefaultBranch, _ = getDefaultBranch(repo)
+
+	return payload, nil
+}
@@ -0,0 +1,65 @@
+package webhook
+
+import (
+	"context"
+
+	"github.com/charmbracelet/soft-serve/pkg/config"
+	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/proto"
+	"github.com/charmbracelet/soft-serve/pkg/store"
+)
+
+// PingEvent is a test event. It's sent to a single webhook to check it's
+// reachable and verifies signatures, without making a real change.
+type PingEvent struct {
+	Common
+
+	// HookID is the ID of the tested webhook.
+	HookID int64 `json:"hook_id" url:"hook_id"`
+}
+
+// NewPingEvent returns a new ping event. The user is the user who requested
+// the test, if any.
+func NewPingEvent(ctx context.Context, user proto.User, repo proto.Repository, hookID int64) (PingEvent, error) {
+	payload := PingEvent{
+		HookID: hookID,
+		Common: Common{
+			EventType: EventPing,
+			Repository: Repository{
+				ID:          repo.ID(),
+				Name:        repo.Name(),
+				Description: repo.Description(),
+				ProjectName: repo.ProjectName(),
+				Private:     repo.IsPrivate(),
+				CreatedAt:   repo.CreatedAt(),
+				UpdatedAt:   repo.UpdatedAt(),
+			},
+		},
+	}
+
+	if user != nil {
+		payload.Sender = User{
+			ID:       user.ID(),
+			Username: user.Username(),
+		}
+	}
+
+	cfg := config.FromContext(ctx)
+	payload.Repository.HTTPURL = repoURL(cfg.HTTP.PublicURL, repo.Name())
+	payload.Repository.SSHURL = repoURL(cfg.SSH.PublicURL, repo.Name())
+	payload.Repository.GitURL = repoURL(cfg.Git.PublicURL, repo.Name())
+
+	// Find repo owner.
+	dbx := db.FromContext(ctx)
+	datastore := store.FromContext(ctx)
+	owner, err := datastore.GetUserByID(ctx, dbx, repo.UserID())
+	if err != nil {
+		return PingEvent{}, db.WrapError(err)
+	}
+
+	payload.Repository.Owner.ID = owner.ID
+	payload.Repository.Owner.Username = owner.Username
+	payload.Repository.DefaultBranch, _ = getDefaultBranch(repo)
+
+	return payload, nil
+}
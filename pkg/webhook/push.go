@@ -4,10 +4,10 @@ import (
 	"context"
 	"fmt"
 
-	gitm "github.com/aymanbagabas/git-module"
 	"github.com/charmbracelet/soft-serve/git"
 	"github.com/charmbracelet/soft-serve/pkg/config"
 	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/db/models"
 	"github.com/charmbracelet/soft-serve/pkg/proto"
 	"github.com/charmbracelet/soft-serve/pkg/store"
 )
@@ -22,18 +22,26 @@ type PushEvent struct {
 	Before string `json:"before" url:"before"`
 	// After is the current commit SHA.
 	After string `json:"after" url:"after"`
+	// Forced is whether the update isn't a fast-forward, rewriting the
+	// history of the ref.
+	Forced bool `json:"forced" url:"forced"`
 	// Commits is the list of commits.
 	Commits []Commit `json:"commits" url:"commits"`
+	// Refs is the list of refs updated by the push. Webhooks filtered by ref
+	// pattern only get the matching refs, unless configured to get all refs.
+	Refs []RefUpdate `json:"refs" url:"refs"`
 }
 
-// NewPushEvent sends a push event.
-func NewPushEvent(ctx context.Context, user proto.User, repo proto.Repository, ref, before, after string) (PushEvent, error) {
+// NewPushEvent sends a push event. The refs are all the refs updated by the
+// push.
+func NewPushEvent(ctx context.Context, user proto.User, repo proto.Repository, ref, before, after string, refs []RefUpdate) (PushEvent, error) {
 	event := EventPush
 
 	payload := PushEvent{
 		Ref:    ref,
 		Before: before,
 		After:  after,
+		Refs:   refs,
 		Common: Common{
 			EventType: event,
 			Repository: Repository{
@@ -45,13 +53,18 @@ func NewPushEvent(ctx context.Context, user proto.User, repo proto.Repository, r
 				CreatedAt:   repo.CreatedAt(),
 				UpdatedAt:   repo.UpdatedAt(),
 			},
-			Sender: User{
-				ID:       user.ID(),
-				Username: user.Username(),
-			},
 		},
 	}
 
+	// Pushes with keys that aren't registered to a user, like deploy keys,
+	// have no sender.
+	if user != nil {
+		payload.Sender = User{
+			ID:       user.ID(),
+			Username: user.Username(),
+		}
+	}
+
 	cfg := config.FromContext(ctx)
 	payload.Repository.HTTPURL = repoURL(cfg.HTTP.PublicURL, repo.Name())
 	payload.Repository.SSHURL = repoURL(cfg.SSH.PublicURL, repo.Name())
@@ -81,11 +94,9 @@ func NewPushEvent(ctx context.Context, user proto.User, repo proto.Repository, r
 		rev = fmt.Sprintf("%s..%s", before, after)
 	}
 
-	commits, err := r.Log(rev, gitm.LogOptions{
-		// XXX: limit to 20 commits for now
-		// TODO: implement a commits api
-		MaxCount: 20,
-	})
+	// XXX: limit to 20 commits for now
+	// TODO: implement a commits api
+	commits, err := r.CommitInfos(rev, 20)
 	if err != nil {
 		return PushEvent{}, err
 	}
@@ -93,7 +104,7 @@ func NewPushEvent(ctx context.Context, user proto.User, repo proto.Repository, r
 	payload.Commits = make([]Commit, len(commits))
 	for i, c := range commits {
 		payload.Commits[i] = Commit{
-			ID:      c.ID.String(),
+			ID:      c.ID,
 			Message: c.Message,
 			Title:   c.Summary(),
 			Author: Author{
@@ -112,3 +123,17 @@ func NewPushEvent(ctx context.Context, user proto.User, repo proto.Repository, r
 
 	return payload, nil
 }
+
+// forWebhook returns the payload to send to a webhook, and whether the
+// webhook should get it at all, according to the webhook ref patterns.
+func (p PushEvent) forWebhook(w models.Webhook) (EventPayload, bool) {
+	patterns := ParseRefPatterns(w.RefPatterns)
+	if !MatchRef(patterns, p.Ref) {
+		return nil, false
+	}
+	if !w.AllRefs {
+		p.Refs = filterRefs(patterns, p.Refs)
+	}
+
+	return p, true
+}
@@ -0,0 +1,79 @@
+package webhook
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// ErrInvalidRefPattern is returned when a webhook ref pattern is invalid.
+var ErrInvalidRefPattern = errors.New("invalid ref pattern")
+
+// RefUpdate is a ref updated by a push.
+type RefUpdate struct {
+	// Ref is the full ref name, e.g. "refs/heads/main".
+	Ref string `json:"ref" url:"ref"`
+	// Before is the previous commit SHA.
+	Before string `json:"before" url:"before"`
+	// After is the current commit SHA.
+	After string `json:"after" url:"after"`
+	// Forced is whether the update isn't a fast-forward.
+	Forced bool `json:"forced" url:"forced"`
+}
+
+// ValidateRefPattern validates a webhook ref pattern. Patterns match full ref
+// names using path.Match syntax, e.g. "refs/heads/main" or "refs/tags/*".
+func ValidateRefPattern(pattern string) error {
+	if pattern == "" || strings.TrimSpace(pattern) != pattern {
+		return fmt.Errorf("%w: %q", ErrInvalidRefPattern, pattern)
+	}
+	if !strings.HasPrefix(pattern, "refs/") {
+		return fmt.Errorf("%w: %q must start with refs/", ErrInvalidRefPattern, pattern)
+	}
+	if _, err := path.Match(pattern, ""); err != nil {
+		return fmt.Errorf("%w: %q: %w", ErrInvalidRefPattern, pattern, err)
+	}
+
+	return nil
+}
+
+// ParseRefPatterns parses a comma separated list of ref patterns as stored in
+// the database.
+func ParseRefPatterns(s string) []string {
+	var patterns []string
+	for _, p := range strings.Split(s, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			patterns = append(patterns, p)
+		}
+	}
+
+	return patterns
+}
+
+// MatchRef reports whether a ref matches any of the patterns. A ref matches
+// when there are no patterns.
+func MatchRef(patterns []string, ref string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, ref); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// filterRefs returns the ref updates that match the patterns.
+func filterRefs(patterns []string, refs []RefUpdate) []RefUpdate {
+	matched := make([]RefUpdate, 0, len(refs))
+	for _, r := range refs {
+		if MatchRef(patterns, r.Ref) {
+			matched = append(matched, r)
+		}
+	}
+
+	return matched
+}
@@ -11,6 +11,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"time"
 
 	"github.com/charmbracelet/soft-serve/git"
 	"github.com/charmbracelet/soft-serve/pkg/db"
@@ -28,6 +29,14 @@ type Hook struct {
 	models.Webhook
 	ContentType ContentType
 	Events      []Event
+	// LastDelivery is the result of the last delivery, nil if the webhook was
+	// never delivered.
+	LastDelivery *models.WebhookLastDelivery
+}
+
+// Refs returns the ref patterns push events are filtered by.
+func (h Hook) Refs() []string {
+	return ParseRefPatterns(h.RefPatterns)
 }
 
 // Delivery is a webhook delivery.
@@ -53,8 +62,41 @@ func do(ctx context.Context, url string, method string, headers http.Header, bod
 	return res, nil
 }
 
+// Result is the result of a webhook delivery.
+type Result struct {
+	// ID is the delivery ID.
+	ID uuid.UUID
+	// Status is the response status code, 0 if the request failed.
+	Status int
+	// Latency is the time it took to get the response.
+	Latency time.Duration
+	// Signature is the X-SoftServe-Signature header, empty if the webhook
+	// has no secret.
+	Signature string
+	// Body is the request body.
+	Body string
+	// Error is the request error, if any.
+	Error error
+}
+
+// Signature returns the X-SoftServe-Signature header of a request body, that
+// is "sha256=" followed by the hex encoded HMAC-SHA256 of the body keyed with
+// the webhook secret.
+func Signature(secret string, body []byte) string {
+	sig := hmac.New(sha256.New, []byte(secret))
+	sig.Write(body) // nolint: errcheck
+	return "sha256=" + hex.EncodeToString(sig.Sum(nil))
+}
+
 // SendWebhook sends a webhook event.
 func SendWebhook(ctx context.Context, w models.Webhook, event Event, payload interface{}) error {
+	_, err := Deliver(ctx, w, event, payload)
+	return err
+}
+
+// Deliver sends a webhook event and returns the result of the delivery. The
+// delivery is recorded, and becomes the last delivery of the webhook.
+func Deliver(ctx context.Context, w models.Webhook, event Event, payload interface{}) (Result, error) {
 	var buf bytes.Buffer
 	dbx := db.FromContext(ctx)
 	datastore := store.FromContext(ctx)
@@ -63,16 +105,16 @@ func SendWebhook(ctx context.Context, w models.Webhook, event Event, payload int
 	switch contentType {
 	case ContentTypeJSON:
 		if err := json.NewEncoder(&buf).Encode(payload); err != nil {
-			return err
+			return Result{}, err
 		}
 	case ContentTypeForm:
 		v, err := query.Values(payload)
 		if err != nil {
-			return err
+			return Result{}, err
 		}
 		buf.WriteString(v.Encode()) // nolint: errcheck
 	default:
-		return ErrInvalidContentType
+		return Result{}, ErrInvalidContentType
 	}
 
 	headers := http.Header{}
@@ -82,19 +124,22 @@ func SendWebhook(ctx context.Context, w models.Webhook, event Event, payload int
 
 	id, err := uuid.NewUUID()
 	if err != nil {
-		return err
+		return Result{}, err
 	}
 
 	headers.Add("X-SoftServe-Delivery", id.String())
 
 	reqBody := buf.String()
+	result := Result{ID: id, Body: reqBody}
 	if w.Secret != "" {
-		sig := hmac.New(sha256.New, []byte(w.Secret))
-		sig.Write([]byte(reqBody)) // nolint: errcheck
-		headers.Add("X-SoftServe-Signature", "sha256="+hex.EncodeToString(sig.Sum(nil)))
+		result.Signature = Signature(w.Secret, buf.Bytes())
+		headers.Add("X-SoftServe-Signature", result.Signature)
 	}
 
+	start := time.Now()
 	res, reqErr := do(ctx, w.URL, http.MethodPost, headers, &buf)
+	result.Latency = time.Since(start)
+	result.Error = reqErr
 	var reqHeaders string
 	for k, v := range headers {
 		reqHeaders += k + ": " + v[0] + "\n"
@@ -114,14 +159,31 @@ func SendWebhook(ctx context.Context, w models.Webhook, event Event, payload int
 			defer res.Body.Close() // nolint: errcheck
 			b, err := io.ReadAll(res.Body)
 			if err != nil {
-				return err
+				return Result{}, err
 			}
 
 			resBody = string(b)
 		}
 	}
+	result.Status = resStatus
 
-	return db.WrapError(datastore.CreateWebhookDelivery(ctx, dbx, id, w.ID, int(event), w.URL, http.MethodPost, reqErr, reqHeaders, reqBody, resStatus, resHeaders, resBody))
+	if err := dbx.TransactionContext(ctx, func(tx *db.Tx) error {
+		if err := datastore.CreateWebhookDelivery(ctx, tx, id, w.ID, int(event), w.URL, http.MethodPost, reqErr, reqHeaders, reqBody, resStatus, resHeaders, resBody); err != nil {
+			return err
+		}
+
+		return datastore.SetWebhookLastDelivery(ctx, tx, w.ID, id, int(event), resStatus, reqErr, result.Latency.Milliseconds())
+	}); err != nil {
+		return Result{}, db.WrapError(err)
+	}
+
+	return result, nil
+}
+
+// webhookPayload is implemented by event payloads that depend on the webhook
+// they're sent to.
+type webhookPayload interface {
+	forWebhook(w models.Webhook) (EventPayload, bool)
 }
 
 // SendEvent sends a webhook event.
@@ -134,6 +196,14 @@ func SendEvent(ctx context.Context, payload EventPayload) error {
 	}
 
 	for _, w := range webhooks {
+		payload := payload
+		if p, ok := payload.(webhookPayload); ok {
+			wp, send := p.forWebhook(w)
+			if !send {
+				continue
+			}
+			payload = wp
+		}
 		if err := SendWebhook(ctx, w, payload.Event(), payload); err != nil {
 			return err
 		}
@@ -0,0 +1,66 @@
+package webhook
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/soft-serve/pkg/db/models"
+)
+
+func TestSignature(t *testing.T) {
+	sig := Signature("key", []byte("The quick brown fox jumps over the lazy dog"))
+	expected := "sha256=f7bc83f430538424b13298e6aa6fb143ef4d59a14946175997479dbc2d1a3cd8"
+	if sig != expected {
+		t.Errorf("Signature() = %q, want %q", sig, expected)
+	}
+}
+
+func TestValidateRefPattern(t *testing.T) {
+	for _, p := range []string{"refs/heads/main", "refs/tags/*", "refs/heads/release-[0-9]*"} {
+		if err := ValidateRefPattern(p); err != nil {
+			t.Errorf("ValidateRefPattern(%q) = %v, want nil", p, err)
+		}
+	}
+	for _, p := range []string{"", " refs/heads/main", "main", "refs/heads/[", "refs/heads/\\"} {
+		if err := ValidateRefPattern(p); err == nil {
+			t.Errorf("ValidateRefPattern(%q) = nil, want an error", p)
+		}
+	}
+}
+
+func TestPushEventForWebhook(t *testing.T) {
+	refs := []RefUpdate{
+		{Ref: "refs/heads/main", Before: "a", After: "b"},
+		{Ref: "refs/heads/dev", Before: "c", After: "d"},
+		{Ref: "refs/tags/v1.0.0", Before: "e", After: "f"},
+	}
+	cases := []struct {
+		name     string
+		ref      string
+		patterns string
+		allRefs  bool
+		send     bool
+		refs     int
+	}{
+		{"no patterns", "refs/heads/dev", "", false, true, 3},
+		{"matching ref", "refs/heads/main", "refs/heads/main,refs/tags/*", false, true, 2},
+		{"matching ref with all refs", "refs/tags/v1.0.0", "refs/heads/main,refs/tags/*", true, true, 3},
+		{"other ref", "refs/heads/dev", "refs/heads/main,refs/tags/*", false, false, 0},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			e := PushEvent{Ref: c.ref, Refs: refs}
+			w := models.Webhook{RefPatterns: c.patterns, AllRefs: c.allRefs}
+			p, send := e.forWebhook(w)
+			if send != c.send {
+				t.Fatalf("forWebhook() send = %t, want %t", send, c.send)
+			}
+			if !send {
+				return
+			}
+			if got := len(p.(PushEvent).Refs); got != c.refs {
+				t.Errorf("forWebhook() refs = %d, want %d", got, c.refs)
+			}
+		})
+	}
+}
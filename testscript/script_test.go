@@ -23,6 +23,7 @@ import (
 	"github.com/charmbracelet/keygen"
 	"github.com/charmbracelet/soft-serve/pkg/config"
 	"github.com/charmbracelet/soft-serve/pkg/db"
+	"github.com/charmbracelet/soft-serve/pkg/sshutils"
 	"github.com/charmbracelet/soft-serve/pkg/test"
 	"github.com/rogpeppe/go-internal/testscript"
 	"github.com/spf13/cobra"
@@ -73,6 +74,7 @@ func TestScript(t *testing.T) {
 	admin1Key, admin1 := mkkey("admin1")
 	_, admin2 := mkkey("admin2")
 	user1Key, user1 := mkkey("user1")
+	_, user2 := mkkey("user2")
 
 	testscript.Run(t, testscript.Params{
 		Dir:                 "./testdata/",
@@ -81,6 +83,8 @@ func TestScript(t *testing.T) {
 		Cmds: map[string]func(ts *testscript.TestScript, neg bool, args []string){
 			"soft":          cmdSoft("admin", admin1.Signer()),
 			"usoft":         cmdSoft("user1", user1.Signer()),
+			"u2soft":        cmdSoft("user2", user2.Signer()),
+			"invite":        cmdInvite("user2", user2.Signer()),
 			"git":           cmdGit(admin1Key),
 			"ugit":          cmdGit(user1Key),
 			"curl":          cmdCurl,
@@ -91,6 +95,7 @@ func TestScript(t *testing.T) {
 			"new-webhook":   cmdNewWebhook,
 			"waitforserver": cmdWaitforserver,
 			"stopserver":    cmdStopserver,
+			"sleep":         cmdSleep,
 			"ui":            cmdUI(admin1.Signer()),
 			"uui":           cmdUI(user1.Signer()),
 		},
@@ -101,20 +106,34 @@ func TestScript(t *testing.T) {
 			data := t.TempDir()
 			sshPort := test.RandomPort()
 			sshListen := fmt.Sprintf("localhost:%d", sshPort)
+			// An additional SSH listener port, for tests of multiple
+			// listeners.
+			sshExtraPort := test.RandomPort()
 			gitPort := test.RandomPort()
 			gitListen := fmt.Sprintf("localhost:%d", gitPort)
 			httpPort := test.RandomPort()
 			httpListen := fmt.Sprintf("localhost:%d", httpPort)
 			statsPort := test.RandomPort()
 			statsListen := fmt.Sprintf("localhost:%d", statsPort)
+			profilingPort := test.RandomPort()
+			profilingListen := fmt.Sprintf("localhost:%d", profilingPort)
 			serverName := "Test Soft Serve"
 
 			e.Setenv("DATA_PATH", data)
 			e.Setenv("SSH_PORT", fmt.Sprintf("%d", sshPort))
+			e.Setenv("SSH_EXTRA_PORT", fmt.Sprintf("%d", sshExtraPort))
+			e.Setenv("GIT_PORT", fmt.Sprintf("%d", gitPort))
 			e.Setenv("HTTP_PORT", fmt.Sprintf("%d", httpPort))
+			e.Setenv("STATS_PORT", fmt.Sprintf("%d", statsPort))
+			e.Setenv("PROFILING_PORT", fmt.Sprintf("%d", profilingPort))
 			e.Setenv("ADMIN1_AUTHORIZED_KEY", admin1.AuthorizedKey())
 			e.Setenv("ADMIN2_AUTHORIZED_KEY", admin2.AuthorizedKey())
 			e.Setenv("USER1_AUTHORIZED_KEY", user1.AuthorizedKey())
+			e.Setenv("USER2_AUTHORIZED_KEY", user2.AuthorizedKey())
+			e.Setenv("ADMIN1_KEY_FINGERPRINT", sshutils.KeyFingerprint(admin1.PublicKey()))
+			e.Setenv("USER1_KEY_FINGERPRINT", sshutils.KeyFingerprint(user1.PublicKey()))
+			e.Setenv("ADMIN1_KEY_PATH", filepath.ToSlash(admin1Key))
+			e.Setenv("USER1_KEY_PATH", filepath.ToSlash(user1Key))
 			e.Setenv("SSH_KNOWN_HOSTS_FILE", filepath.Join(t.TempDir(), "known_hosts"))
 			e.Setenv("SSH_KNOWN_CONFIG_FILE", filepath.Join(t.TempDir(), "config"))
 
@@ -145,6 +164,7 @@ func TestScript(t *testing.T) {
 			cfg.HTTP.ListenAddr = httpListen
 			cfg.HTTP.PublicURL = "http://" + httpListen
 			cfg.Stats.ListenAddr = statsListen
+			cfg.Profiling.ListenAddr = profilingListen
 			cfg.LFS.Enabled = true
 
 			// Parse os SOFT_SERVE environment variables
@@ -188,7 +208,12 @@ func cmdSoft(user string, key ssh.Signer) func(ts *testscript.TestScript, neg bo
 				HostKeyCallback: ssh.InsecureIgnoreHostKey(),
 			},
 		)
-		ts.Check(err)
+		if err != nil {
+			// The server can deny the key during the handshake.
+			fmt.Fprintln(ts.Stderr(), err)
+			check(ts, err, neg)
+			return
+		}
 		defer cli.Close()
 
 		sess, err := cli.NewSession()
@@ -198,10 +223,65 @@ func cmdSoft(user string, key ssh.Signer) func(ts *testscript.TestScript, neg bo
 		sess.Stdout = ts.Stdout()
 		sess.Stderr = ts.Stderr()
 
+		// "< FILE" at the end of the arguments sends the file to the stdin of
+		// the command.
+		if n := len(args); n >= 2 && args[n-2] == "<" {
+			sess.Stdin = strings.NewReader(ts.ReadFile(args[n-1]))
+			args = args[:n-2]
+		}
+
 		check(ts, sess.Run(strings.Join(args, " ")), neg)
 	}
 }
 
+// cmdInvite connects with a key offering the invite code given as the
+// first argument over keyboard-interactive authentication, then runs the
+// rest of the arguments as a command.
+func cmdInvite(user string, key ssh.Signer) func(ts *testscript.TestScript, neg bool, args []string) {
+	return func(ts *testscript.TestScript, neg bool, args []string) {
+		if len(args) < 1 {
+			ts.Fatalf("usage: invite CODE [COMMAND]")
+			return
+		}
+
+		code := args[0]
+		cli, err := ssh.Dial(
+			"tcp",
+			net.JoinHostPort("localhost", ts.Getenv("SSH_PORT")),
+			&ssh.ClientConfig{
+				User: user,
+				Auth: []ssh.AuthMethod{
+					ssh.PublicKeys(key),
+					ssh.KeyboardInteractive(func(_, instruction string, questions []string, _ []bool) ([]string, error) {
+						fmt.Fprintln(ts.Stdout(), instruction)
+						answers := make([]string, len(questions))
+						for i := range answers {
+							answers[i] = code
+						}
+						return answers, nil
+					}),
+				},
+				HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+			},
+		)
+		if err != nil {
+			fmt.Fprintln(ts.Stderr(), err)
+			check(ts, err, neg)
+			return
+		}
+		defer cli.Close()
+
+		sess, err := cli.NewSession()
+		ts.Check(err)
+		defer sess.Close()
+
+		sess.Stdout = ts.Stdout()
+		sess.Stderr = ts.Stderr()
+
+		check(ts, sess.Run(strings.Join(args[1:], " ")), neg)
+	}
+}
+
 func cmdUI(key ssh.Signer) func(ts *testscript.TestScript, neg bool, args []string) {
 	return func(ts *testscript.TestScript, neg bool, args []string) {
 		if len(args) < 1 {
@@ -218,11 +298,11 @@ func cmdUI(key ssh.Signer) func(ts *testscript.TestScript, neg bool, args []stri
 				HostKeyCallback: ssh.InsecureIgnoreHostKey(),
 			},
 		)
-		check(ts, err, neg)
+		ts.Check(err)
 		defer cli.Close()
 
 		sess, err := cli.NewSession()
-		check(ts, err, neg)
+		ts.Check(err)
 		defer sess.Close()
 
 		// XXX: this is a hack to make the UI tests work
@@ -234,14 +314,14 @@ func cmdUI(key ssh.Signer) func(ts *testscript.TestScript, neg bool, args []stri
 		sess.Stderr = ts.Stderr()
 
 		stdin, err := sess.StdinPipe()
-		check(ts, err, neg)
+		ts.Check(err)
 
 		err = sess.RequestPty("dumb", 40, 80, ssh.TerminalModes{})
-		check(ts, err, neg)
-		check(ts, sess.Start(""), neg)
+		ts.Check(err)
+		ts.Check(sess.Start(""))
 
 		in, err := strconv.Unquote(args[0])
-		check(ts, err, neg)
+		ts.Check(err)
 		reader := strings.NewReader(in)
 		go func() {
 			defer stdin.Close()
@@ -250,7 +330,7 @@ func cmdUI(key ssh.Signer) func(ts *testscript.TestScript, neg bool, args []stri
 				if err == io.EOF {
 					break
 				}
-				check(ts, err, neg)
+				ts.Check(err)
 				stdin.Write([]byte(string(r))) // nolint: errcheck
 
 				// Wait for the UI to process the input
@@ -486,6 +566,15 @@ func cmdWaitforserver(ts *testscript.TestScript, neg bool, args []string) {
 	}
 }
 
+func cmdSleep(ts *testscript.TestScript, neg bool, args []string) {
+	if len(args) != 1 {
+		ts.Fatalf("usage: sleep duration")
+	}
+	d, err := time.ParseDuration(args[0])
+	ts.Check(err)
+	time.Sleep(d)
+}
+
 func cmdStopserver(ts *testscript.TestScript, neg bool, args []string) {
 	// stop the server
 	resp, err := http.DefaultClient.Head(fmt.Sprintf("%s/__stop", ts.Getenv("SOFT_SERVE_HTTP_PUBLIC_URL")))